@@ -27,6 +27,7 @@ type BlockIndexerConfig struct {
 	CommitBatchSize       *int               `json:"commitBatchSize"`
 	CommitBatchTimeout    *string            `json:"commitBatchTimeout"`
 	RequiredConfirmations *int               `json:"requiredConfirmations"`
+	MaxReorgDepth         *int               `json:"maxReorgDepth"` // the indexer stops if a chain re-org replaces more indexed blocks than this
 	ChainHeadCacheLen     *int               `json:"chainHeadCacheLen"`
 	BlockPollingInterval  *string            `json:"blockPollingInterval"`
 	EventStreams          EventStreamsConfig `json:"eventStreams"`
@@ -48,6 +49,7 @@ var BlockIndexerDefaults = &BlockIndexerConfig{
 	CommitBatchSize:       confutil.P(50),
 	CommitBatchTimeout:    confutil.P("100ms"),
 	RequiredConfirmations: confutil.P(0),
+	MaxReorgDepth:         confutil.P(256),
 	ChainHeadCacheLen:     confutil.P(50),
 	BlockPollingInterval:  confutil.P("10s"),
 }
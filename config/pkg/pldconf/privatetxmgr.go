@@ -17,22 +17,9 @@ package pldconf
 import "github.com/kaleido-io/paladin/config/pkg/confutil"
 
 type PrivateTxManagerConfig struct {
-	Writer                         FlushWriterConfig               `json:"writer"`
-	Sequencer                      PrivateTxManagerSequencerConfig `json:"sequencer"`
-	StateDistributer               DistributerConfig               `json:"stateDistributer"`
-	PreparedTransactionDistributer DistributerConfig               `json:"preparedTransactionDistributer"`
-	RequestTimeout                 *string                         `json:"requestTimeout"`
-}
-
-type DistributerConfig struct {
-	AcknowledgementWriter FlushWriterConfig `json:"acknowledgementWriter"`
-	ReceivedObjectWriter  FlushWriterConfig `json:"receivedStateWriter"`
-}
-
-var DistributerWriterConfigDefaults = FlushWriterConfig{
-	WorkerCount:  confutil.P(10),
-	BatchTimeout: confutil.P("25ms"),
-	BatchMaxSize: confutil.P(100),
+	Writer         FlushWriterConfig               `json:"writer"`
+	Sequencer      PrivateTxManagerSequencerConfig `json:"sequencer"`
+	RequestTimeout *string                         `json:"requestTimeout"`
 }
 
 var PrivateTxManagerDefaults = &PrivateTxManagerConfig{
@@ -44,4 +44,44 @@ type RPCServerConfigWS struct {
 type RPCServerConfig struct {
 	HTTP RPCServerConfigHTTP `json:"http,omitempty"`
 	WS   RPCServerConfigWS   `json:"ws,omitempty"`
+	Auth RPCAuthConfig       `json:"auth,omitempty"`
+}
+
+var RPCAuthJWTDefaults = RPCAuthJWTConfig{
+	PrincipalClaim: confutil.P("sub"),
+	ClockSkew:      confutil.P("30s"),
+}
+
+// Authentication is enabled for both HTTP and WebSocket when any of tokens, basic users,
+// or a JWKS file are configured. Authorization policies are optional - when none are
+// configured every authenticated principal can call every method, with any key.
+type RPCAuthConfig struct {
+	Tokens   []RPCAuthTokenConfig  `json:"tokens,omitempty"`   // static bearer tokens
+	Basic    []RPCAuthBasicConfig  `json:"basic,omitempty"`    // basic auth users
+	JWT      RPCAuthJWTConfig      `json:"jwt,omitempty"`      // JWT bearer tokens verified against a local JWKS file
+	Policies []RPCAuthPolicyConfig `json:"policies,omitempty"` // maps principals to the methods and keys they are allowed to use
+}
+
+type RPCAuthTokenConfig struct {
+	Principal string `json:"principal"`
+	Token     string `json:"token"`
+}
+
+type RPCAuthBasicConfig struct {
+	Username     string `json:"username"`     // the username is the principal
+	PasswordHash string `json:"passwordHash"` // bcrypt hash of the password
+}
+
+type RPCAuthJWTConfig struct {
+	JWKSFile       string  `json:"jwksFile,omitempty"` // JSON Web Key Set containing the RSA (2048 bits or more) or EC public keys used to verify tokens. Tokens must have a "kid" header if there is more than one key
+	Issuer         string  `json:"issuer,omitempty"`   // if set, the "iss" claim must match
+	Audience       string  `json:"audience,omitempty"` // if set, the "aud" claim must contain this value
+	PrincipalClaim *string `json:"principalClaim,omitempty"`
+	ClockSkew      *string `json:"clockSkew,omitempty"`
+}
+
+type RPCAuthPolicyConfig struct {
+	Principals []string `json:"principals"`     // principal names, or "*" for any authenticated principal
+	Methods    []string `json:"methods"`        // method groups with a trailing underscore (such as "ptx_"), full method names, or "*"
+	Keys       []string `json:"keys,omitempty"` // regular expressions for the key identifiers that can be used with these methods (any key if empty)
 }
@@ -22,6 +22,7 @@ import (
 const (
 	KeyStoreTypeFilesystem = "filesystem" // keystorev3 based filesystem storage
 	KeyStoreTypeStatic     = "static"     // unencrypted keys in-line in the config
+	KeyStoreTypeDatabase   = "database"   // envelope encrypted keys in the Paladin database (only available in the embedded signing module)
 )
 
 // Config can be directly embedded to provide ExtensibleConfig implementation
@@ -40,6 +41,7 @@ type KeyStoreConfig struct {
 	KeyStoreSigning   bool                     `json:"keyStoreSigning"` // if HD Wallet or ZKP based signing is required, in-memory keys are required (so this needs to be false)
 	FileSystem        FileSystemKeyStoreConfig `json:"filesystem"`
 	Static            StaticKeyStoreConfig     `json:"static"`
+	Database          DatabaseKeyStoreConfig   `json:"database"`
 }
 
 type KeyDerivationType string
@@ -113,3 +115,42 @@ var FileSystemDefaults = &FileSystemKeyStoreConfig{
 		Capacity: confutil.P(100),
 	},
 }
+
+type DatabaseKeyStoreConfig struct {
+	MasterKey          MasterKeyConfig   `json:"masterKey"`          // encrypts the data encryption key of each stored key
+	PreviousMasterKeys []MasterKeyConfig `json:"previousMasterKeys"` // keys encrypted with these are re-encrypted with the master key on startup
+	Cache              CacheConfig       `json:"cache"`
+}
+
+// MasterKeyConfig loads a hex encoded 32 byte AES-256 key from a file or environment variable
+type MasterKeyConfig struct {
+	File string `json:"file,omitempty"`
+	Env  string `json:"env,omitempty"`
+}
+
+var DatabaseKeyStoreDefaults = &DatabaseKeyStoreConfig{
+	Cache: CacheConfig{
+		Capacity: confutil.P(100),
+	},
+}
+
+// RemoteSignerConfig configures the client for a signing module running outside of
+// the Paladin process, connected over JSON/RPC (HTTP). Use the TLS settings on the
+// HTTP client to configure mTLS.
+type RemoteSignerConfig struct {
+	HTTPClientConfig `json:",inline"`
+	RequestTimeout   *string            `json:"requestTimeout"`
+	Retry            RetryConfigWithMax `json:"retry"`
+}
+
+var RemoteSignerDefaults = &RemoteSignerConfig{
+	RequestTimeout: confutil.P("30s"),
+	Retry: RetryConfigWithMax{
+		RetryConfig: RetryConfig{
+			InitialDelay: confutil.P("250ms"),
+			MaxDelay:     confutil.P("5s"),
+			Factor:       confutil.P(2.0),
+		},
+		MaxAttempts: confutil.P(5),
+	},
+}
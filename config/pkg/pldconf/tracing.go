@@ -0,0 +1,72 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pldconf
+
+import "github.com/kaleido-io/paladin/config/pkg/confutil"
+
+const (
+	TracingExporterOTLP = "otlp" // OTLP over HTTP to a collector
+	TracingExporterFile = "file" // newline delimited JSON spans to a local file
+)
+
+type TracingConfig struct {
+	// enables emitting OpenTelemetry spans for the transaction lifecycle
+	Enabled *bool `json:"enabled"`
+	// the service name recorded on all spans (defaults to "paladin")
+	ServiceName *string `json:"serviceName"`
+	// the exporter to use ('otlp', 'file')
+	Exporter *string `json:"exporter"`
+	// the fraction of new traces to sample, between 0 and 1 (child spans follow the parent decision)
+	SampleRatio *float64 `json:"sampleRatio"`
+	// configure the OTLP exporter
+	OTLP TracingOTLPConfig `json:"otlp"`
+	// configure the file exporter
+	File TracingFileConfig `json:"file"`
+}
+
+type TracingOTLPConfig struct {
+	// the host:port of the collector
+	Endpoint *string `json:"endpoint"`
+	// the URL path for trace export on the collector
+	URLPath *string `json:"urlPath"`
+	// send over plain HTTP rather than HTTPS
+	Insecure bool `json:"insecure"`
+	// additional headers to send on each export, such as authentication
+	Headers map[string]string `json:"headers"`
+	// timeout for each export request
+	Timeout *string `json:"timeout"`
+}
+
+type TracingFileConfig struct {
+	// the file spans are appended to
+	Path *string `json:"path"`
+}
+
+var TracingDefaults = &TracingConfig{
+	Enabled:     confutil.P(false),
+	ServiceName: confutil.P("paladin"),
+	Exporter:    confutil.P(TracingExporterOTLP),
+	SampleRatio: confutil.P(1.0),
+	OTLP: TracingOTLPConfig{
+		Endpoint: confutil.P("localhost:4318"),
+		URLPath:  confutil.P("/v1/traces"),
+		Timeout:  confutil.P("10s"),
+	},
+	File: TracingFileConfig{
+		Path: confutil.P("paladin-traces.json"),
+	},
+}
@@ -14,9 +14,32 @@
  */
 package pldconf
 
+import "github.com/kaleido-io/paladin/config/pkg/confutil"
+
 type TransportManagerConfig struct {
-	NodeName   string                      `json:"nodeName"`
-	Transports map[string]*TransportConfig `json:"transports"`
+	NodeName          string                      `json:"nodeName"`
+	Transports        map[string]*TransportConfig `json:"transports"`
+	ReliableMessaging ReliableMessagingConfig     `json:"reliableMessaging"`
+}
+
+type ReliableMessagingConfig struct {
+	MaxInFlight       *int        `json:"maxInFlight"`       // messages sent to each peer before waiting for acks
+	ResendInterval    *string     `json:"resendInterval"`    // time to wait for an ack before resending a message
+	MaxResendInterval *string     `json:"maxResendInterval"` // the wait before resending doubles each time a message is resent, up to this maximum
+	Retention         *string     `json:"retention"`         // time acknowledged messages, and the record of received messages, are kept - must be longer than a peer might take to resend
+	PruneInterval     *string     `json:"pruneInterval"`     // how often messages older than the retention are removed
+	Retry             RetryConfig `json:"retry"`             // backoff when a message cannot be sent to a peer
+}
+
+var TransportManagerDefaults = &TransportManagerConfig{
+	ReliableMessaging: ReliableMessagingConfig{
+		MaxInFlight:       confutil.P(100),
+		ResendInterval:    confutil.P("30s"),
+		MaxResendInterval: confutil.P("10m"),
+		Retention:         confutil.P("168h"),
+		PruneInterval:     confutil.P("10m"),
+		Retry:             GenericRetryDefaults.RetryConfig,
+	},
 }
 
 type TransportInitConfig struct {
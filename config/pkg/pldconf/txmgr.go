@@ -20,8 +20,9 @@ import (
 )
 
 type TxManagerConfig struct {
-	ABI          ABIConfig          `json:"abi"`
-	Transactions TransactionsConfig `json:"transactions"`
+	ABI              ABIConfig              `json:"abi"`
+	Transactions     TransactionsConfig     `json:"transactions"`
+	ReceiptListeners ReceiptListenersConfig `json:"receiptListeners"`
 }
 
 type ABIConfig struct {
@@ -32,6 +33,12 @@ type TransactionsConfig struct {
 	Cache CacheConfig `json:"cache"`
 }
 
+type ReceiptListenersConfig struct {
+	ReadPageSize *int        `json:"readPageSize"`
+	PollInterval *string     `json:"pollInterval"` // safety net in case of missed notifications
+	Retry        RetryConfig `json:"retry"`
+}
+
 var TxManagerDefaults = &TxManagerConfig{
 	ABI: ABIConfig{
 		Cache: CacheConfig{
@@ -43,4 +50,9 @@ var TxManagerDefaults = &TxManagerConfig{
 			Capacity: confutil.P(100),
 		},
 	},
+	ReceiptListeners: ReceiptListenersConfig{
+		ReadPageSize: confutil.P(100),
+		PollInterval: confutil.P("1s"),
+		Retry:        GenericRetryDefaults.RetryConfig,
+	},
 }
@@ -88,12 +88,13 @@ type UTXOTransfer_Event struct {
 	Signature tktypes.HexBytes  `json:"signature"`
 }
 
-func parseStatesFromEvent(txID tktypes.Bytes32, states []tktypes.Bytes32) []*prototk.StateUpdate {
+func parseStatesFromEvent(txID tktypes.Bytes32, states []tktypes.Bytes32, location *prototk.OnChainEventLocation) []*prototk.StateUpdate {
 	refs := make([]*prototk.StateUpdate, len(states))
 	for i, state := range states {
 		refs[i] = &prototk.StateUpdate{
 			Id:            state.String(),
 			TransactionId: txID.String(),
+			Location:      location,
 		}
 	}
 	return refs
@@ -1027,8 +1028,8 @@ func SimpleTokenDomain(t *testing.T, ctx context.Context) plugintk.PluginBase {
 								TransactionId: transfer.TX.String(),
 								Location:      ev.Location,
 							})
-							res.SpentStates = append(res.SpentStates, parseStatesFromEvent(transfer.TX, transfer.Inputs)...)
-							res.ConfirmedStates = append(res.ConfirmedStates, parseStatesFromEvent(transfer.TX, transfer.Outputs)...)
+							res.SpentStates = append(res.SpentStates, parseStatesFromEvent(transfer.TX, transfer.Inputs, ev.Location)...)
+							res.ConfirmedStates = append(res.ConfirmedStates, parseStatesFromEvent(transfer.TX, transfer.Outputs, ev.Location)...)
 						}
 					}
 				}
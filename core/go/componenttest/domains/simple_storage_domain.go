@@ -725,8 +725,8 @@ func SimpleStorageDomain(t *testing.T, ctx context.Context) plugintk.PluginBase
 								TransactionId: transfer.TX.String(),
 								Location:      ev.Location,
 							})
-							res.SpentStates = append(res.SpentStates, parseStatesFromEvent(transfer.TX, transfer.Inputs)...)
-							res.ConfirmedStates = append(res.ConfirmedStates, parseStatesFromEvent(transfer.TX, transfer.Outputs)...)
+							res.SpentStates = append(res.SpentStates, parseStatesFromEvent(transfer.TX, transfer.Inputs, ev.Location)...)
+							res.ConfirmedStates = append(res.ConfirmedStates, parseStatesFromEvent(transfer.TX, transfer.Outputs, ev.Location)...)
 						}
 					}
 				}
@@ -48,6 +48,7 @@ import (
 	"github.com/kaleido-io/paladin/toolkit/pkg/rpcclient"
 	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
 	"github.com/kaleido-io/paladin/transports/grpc/pkg/grpc"
+	"github.com/kaleido-io/paladin/transports/https/pkg/https"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -145,11 +146,34 @@ func deployDomainRegistry(t *testing.T) *tktypes.EthAddress {
 }
 
 type nodeConfiguration struct {
-	address string
-	port    int
-	cert    string
-	key     string
-	name    string
+	address   string
+	port      int
+	cert      string
+	key       string
+	name      string
+	transport string
+}
+
+// The component tests run over the gRPC transport by default, and can be run over
+// the HTTPS transport instead by setting COMPONENT_TEST_TRANSPORT=https
+func componentTestTransport() string {
+	if transport := os.Getenv("COMPONENT_TEST_TRANSPORT"); transport != "" {
+		return transport
+	}
+	return "grpc"
+}
+
+func (nc *nodeConfiguration) transportDetails() tktypes.RawJSON {
+	if nc.transport == "https" {
+		return tktypes.JSONString(https.PublishedTransportDetails{
+			Endpoint: fmt.Sprintf("https://%s:%d", nc.address, nc.port),
+			Issuers:  nc.cert,
+		})
+	}
+	return tktypes.JSONString(grpc.PublishedTransportDetails{
+		Endpoint: fmt.Sprintf("dns:///%s:%d", nc.address, nc.port),
+		Issuers:  nc.cert,
+	})
 }
 
 func newNodeConfiguration(t *testing.T, nodeName string) *nodeConfiguration {
@@ -157,11 +181,12 @@ func newNodeConfiguration(t *testing.T, nodeName string) *nodeConfiguration {
 	require.NoError(t, err)
 	cert, key := buildTestCertificate(t, pkix.Name{CommonName: nodeName}, nil, nil)
 	return &nodeConfiguration{
-		address: "localhost",
-		port:    port,
-		cert:    cert,
-		key:     key,
-		name:    nodeName,
+		address:   "localhost",
+		port:      port,
+		cert:      cert,
+		key:       key,
+		name:      nodeName,
+		transport: componentTestTransport(),
 	}
 }
 
@@ -229,7 +254,7 @@ func newInstanceForComponentTesting(t *testing.T, domainRegistryAddress *tktypes
 
 	i.conf.NodeName = binding.name
 	i.conf.Transports = map[string]*pldconf.TransportConfig{
-		"grpc": {
+		binding.transport: {
 			Plugin: pldconf.PluginConfig{
 				Type:    string(tktypes.LibraryTypeCShared),
 				Library: "loaded/via/unit/test/loader",
@@ -252,12 +277,7 @@ func newInstanceForComponentTesting(t *testing.T, domainRegistryAddress *tktypes
 	for _, peerNode := range peerNodes {
 		nodesConfig[peerNode.name] = &static.StaticEntry{
 			Properties: map[string]tktypes.RawJSON{
-				"transport.grpc": tktypes.JSONString(
-					grpc.PublishedTransportDetails{
-						Endpoint: fmt.Sprintf("dns:///%s:%d", peerNode.address, peerNode.port),
-						Issuers:  peerNode.cert,
-					},
-				),
+				"transport." + peerNode.transport: peerNode.transportDetails(),
 			},
 		}
 	}
@@ -300,6 +320,7 @@ func newInstanceForComponentTesting(t *testing.T, domainRegistryAddress *tktypes
 		"domain1":             domains.SimpleTokenDomain(t, i.ctx),
 		"simpleStorageDomain": domains.SimpleStorageDomain(t, i.ctx),
 		"grpc":                grpc.NewPlugin(i.ctx),
+		"https":               https.NewPlugin(i.ctx),
 		"registry1":           static.NewPlugin(i.ctx),
 	}
 	pc := i.cm.PluginManager()
@@ -0,0 +1,6 @@
+BEGIN;
+DROP TABLE receipt_listener_checkpoints;
+DROP TABLE receipt_listeners;
+DROP INDEX transaction_receipts_sequence;
+ALTER TABLE transaction_receipts DROP COLUMN "sequence";
+COMMIT;
@@ -0,0 +1,25 @@
+BEGIN;
+
+-- The sequence gives a total order to receipts as they are written, which listeners use for checkpointing
+ALTER TABLE transaction_receipts ADD COLUMN "sequence" BIGINT GENERATED ALWAYS AS IDENTITY;
+CREATE UNIQUE INDEX transaction_receipts_sequence ON transaction_receipts("sequence");
+
+CREATE TABLE receipt_listeners (
+  "name"                      VARCHAR         NOT NULL,
+  "created"                   BIGINT          NOT NULL,
+  "started"                   BOOLEAN         NOT NULL,
+  "filters"                   VARCHAR         NOT NULL,
+  "options"                   VARCHAR         NOT NULL,
+  PRIMARY KEY ("name")
+);
+CREATE INDEX receipt_listeners_created ON receipt_listeners("created");
+
+CREATE TABLE receipt_listener_checkpoints (
+  "listener"                  VARCHAR         NOT NULL,
+  "sequence"                  BIGINT          NOT NULL,
+  "time"                      BIGINT          NOT NULL,
+  PRIMARY KEY ("listener"),
+  FOREIGN KEY ("listener") REFERENCES receipt_listeners ("name") ON DELETE CASCADE
+);
+
+COMMIT;
@@ -0,0 +1,5 @@
+BEGIN;
+
+ALTER TABLE event_streams DROP COLUMN "started";
+
+COMMIT;
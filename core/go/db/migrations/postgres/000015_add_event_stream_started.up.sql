@@ -0,0 +1,6 @@
+BEGIN;
+
+-- Only external event streams can be stopped, so NULL is treated as started
+ALTER TABLE event_streams ADD COLUMN "started" BOOLEAN;
+
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP INDEX public_submissions_pub_txn_id;
+CREATE UNIQUE INDEX public_submissions_pub_txn_id ON public_submissions("pub_txn_id");
+
+COMMIT;
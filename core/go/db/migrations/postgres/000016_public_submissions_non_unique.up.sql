@@ -0,0 +1,8 @@
+BEGIN;
+
+-- Multiple submissions are recorded for a public transaction when it is resubmitted with a new gas price,
+-- or replaced by the user with an update/cancel, so this index must not be unique
+DROP INDEX public_submissions_pub_txn_id;
+CREATE INDEX public_submissions_pub_txn_id ON public_submissions("pub_txn_id");
+
+COMMIT;
@@ -0,0 +1,15 @@
+BEGIN;
+
+CREATE TABLE key_store (
+    "wallet"             VARCHAR         NOT NULL,
+    "key_handle"         VARCHAR         NOT NULL,
+    "master_key_id"      VARCHAR         NOT NULL,
+    "encrypted_dek"      VARCHAR         NOT NULL,
+    "encrypted_key"      VARCHAR         NOT NULL,
+    "created"            BIGINT          NOT NULL,
+    PRIMARY KEY ("wallet", "key_handle")
+);
+
+CREATE INDEX key_store_master_key_id ON key_store ("wallet", "master_key_id");
+
+COMMIT;
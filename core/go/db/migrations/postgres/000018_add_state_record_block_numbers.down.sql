@@ -0,0 +1,8 @@
+BEGIN;
+
+ALTER TABLE state_confirm_records DROP COLUMN "block_number";
+ALTER TABLE state_spend_records DROP COLUMN "block_number";
+ALTER TABLE state_read_records DROP COLUMN "block_number";
+ALTER TABLE state_info_records DROP COLUMN "block_number";
+
+COMMIT;
@@ -0,0 +1,7 @@
+BEGIN;
+
+DROP TABLE reliable_msgs_received;
+DROP TABLE reliable_msg_acks;
+DROP TABLE reliable_msgs;
+
+COMMIT;
@@ -0,0 +1,36 @@
+BEGIN;
+
+-- Messages queued for reliable delivery to a peer node, in the order they were written
+CREATE TABLE reliable_msgs (
+  "sequence"                  BIGINT          GENERATED ALWAYS AS IDENTITY,
+  "id"                        UUID            NOT NULL,
+  "created"                   BIGINT          NOT NULL,
+  "node"                      VARCHAR         NOT NULL,
+  "component"                 VARCHAR         NOT NULL,
+  "reply_to"                  VARCHAR         NOT NULL,
+  "msg_type"                  VARCHAR         NOT NULL,
+  "correl_id"                 UUID,
+  "payload"                   VARCHAR         NOT NULL,
+  PRIMARY KEY ("sequence")
+);
+CREATE UNIQUE INDEX reliable_msgs_id ON reliable_msgs ("id");
+CREATE INDEX reliable_msgs_node ON reliable_msgs ("node", "sequence");
+
+CREATE TABLE reliable_msg_acks (
+  "id"                        UUID            NOT NULL,
+  "time"                      BIGINT          NOT NULL,
+  PRIMARY KEY ("id"),
+  FOREIGN KEY ("id") REFERENCES reliable_msgs ("id") ON DELETE CASCADE
+);
+CREATE INDEX reliable_msg_acks_time ON reliable_msg_acks ("time");
+
+-- Messages received from peer nodes, so that redelivered messages are not processed twice
+CREATE TABLE reliable_msgs_received (
+  "id"                        UUID            NOT NULL,
+  "node"                      VARCHAR         NOT NULL,
+  "time"                      BIGINT          NOT NULL,
+  PRIMARY KEY ("id")
+);
+CREATE INDEX reliable_msgs_received_time ON reliable_msgs_received ("time");
+
+COMMIT;
@@ -0,0 +1,7 @@
+BEGIN;
+
+DELETE FROM reliable_msgs_received a USING reliable_msgs_received b WHERE a."id" = b."id" AND a."node" > b."node";
+ALTER TABLE reliable_msgs_received DROP CONSTRAINT reliable_msgs_received_pkey;
+ALTER TABLE reliable_msgs_received ADD PRIMARY KEY ("id");
+
+COMMIT;
@@ -0,0 +1,8 @@
+BEGIN;
+
+-- Received messages are de-duplicated per sending node, so one node cannot suppress another's messages by
+-- re-using their IDs
+ALTER TABLE reliable_msgs_received DROP CONSTRAINT reliable_msgs_received_pkey;
+ALTER TABLE reliable_msgs_received ADD PRIMARY KEY ("node", "id");
+
+COMMIT;
@@ -0,0 +1,3 @@
+DROP TABLE receipt_listener_checkpoints;
+DROP TABLE receipt_listeners;
+-- The sequence column is left in place on transaction_receipts, as SQLite cannot drop a primary key column
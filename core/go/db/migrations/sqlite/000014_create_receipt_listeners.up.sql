@@ -0,0 +1,42 @@
+-- SQLite cannot add an auto-incrementing column to an existing table, so we re-create the receipts table
+CREATE TABLE transaction_receipts_new (
+  "sequence"                  INTEGER         PRIMARY KEY AUTOINCREMENT,
+  "transaction"               UUID            NOT NULL,
+  "domain"                    TEXT            NOT NULL,
+  "indexed"                   BIGINT          NOT NULL,
+  "success"                   BOOLEAN         NOT NULL,
+  "failure_message"           TEXT,
+  "revert_data"               TEXT,
+  "tx_hash"                   TEXT,
+  "tx_index"                  INT,
+  "log_index"                 INT,
+  "source"                    TEXT,
+  "block_number"              BIGINT,
+  "contract_address"          TEXT
+);
+INSERT INTO transaction_receipts_new ("transaction", "domain", "indexed", "success", "failure_message", "revert_data", "tx_hash", "tx_index", "log_index", "source", "block_number", "contract_address")
+  SELECT "transaction", "domain", "indexed", "success", "failure_message", "revert_data", "tx_hash", "tx_index", "log_index", "source", "block_number", "contract_address"
+  FROM transaction_receipts ORDER BY "indexed";
+DROP TABLE transaction_receipts;
+ALTER TABLE transaction_receipts_new RENAME TO transaction_receipts;
+CREATE UNIQUE INDEX transaction_receipts_transaction ON transaction_receipts("transaction");
+CREATE INDEX transaction_receipts_tx_hash ON transaction_receipts("tx_hash");
+CREATE INDEX transaction_receipts_source ON transaction_receipts ("source");
+
+CREATE TABLE receipt_listeners (
+  "name"                      TEXT            NOT NULL,
+  "created"                   BIGINT          NOT NULL,
+  "started"                   BOOLEAN         NOT NULL,
+  "filters"                   TEXT            NOT NULL,
+  "options"                   TEXT            NOT NULL,
+  PRIMARY KEY ("name")
+);
+CREATE INDEX receipt_listeners_created ON receipt_listeners("created");
+
+CREATE TABLE receipt_listener_checkpoints (
+  "listener"                  TEXT            NOT NULL,
+  "sequence"                  BIGINT          NOT NULL,
+  "time"                      BIGINT          NOT NULL,
+  PRIMARY KEY ("listener"),
+  FOREIGN KEY ("listener") REFERENCES receipt_listeners ("name") ON DELETE CASCADE
+);
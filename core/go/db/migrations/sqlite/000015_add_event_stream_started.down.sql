@@ -0,0 +1 @@
+ALTER TABLE event_streams DROP COLUMN "started";
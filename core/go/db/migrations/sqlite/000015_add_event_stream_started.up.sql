@@ -0,0 +1,2 @@
+-- Only external event streams can be stopped, so NULL is treated as started
+ALTER TABLE event_streams ADD COLUMN "started" BOOLEAN;
@@ -0,0 +1 @@
+-- The public_submissions_pub_txn_id index was already created as non-unique for SQLite in migration 13
@@ -0,0 +1,11 @@
+CREATE TABLE key_store (
+    "wallet"             TEXT            NOT NULL,
+    "key_handle"         TEXT            NOT NULL,
+    "master_key_id"      TEXT            NOT NULL,
+    "encrypted_dek"      TEXT            NOT NULL,
+    "encrypted_key"      TEXT            NOT NULL,
+    "created"            BIGINT          NOT NULL,
+    PRIMARY KEY ("wallet", "key_handle")
+);
+
+CREATE INDEX key_store_master_key_id ON key_store ("wallet", "master_key_id");
@@ -0,0 +1,5 @@
+-- Block numbers allow finalizations to be reverted when a chain re-org rewinds the block indexer
+ALTER TABLE state_confirm_records ADD COLUMN "block_number" BIGINT;
+ALTER TABLE state_spend_records ADD COLUMN "block_number" BIGINT;
+ALTER TABLE state_read_records ADD COLUMN "block_number" BIGINT;
+ALTER TABLE state_info_records ADD COLUMN "block_number" BIGINT;
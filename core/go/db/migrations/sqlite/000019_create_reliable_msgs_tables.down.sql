@@ -0,0 +1,3 @@
+DROP TABLE reliable_msgs_received;
+DROP TABLE reliable_msg_acks;
+DROP TABLE reliable_msgs;
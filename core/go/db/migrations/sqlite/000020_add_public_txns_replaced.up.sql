@@ -0,0 +1,3 @@
+-- The time a user update or cancel was persisted, so a replacement that has not been submitted
+-- since can be re-applied with its gas pricing when the transaction is reloaded
+ALTER TABLE public_txns ADD COLUMN "replaced" BIGINT;
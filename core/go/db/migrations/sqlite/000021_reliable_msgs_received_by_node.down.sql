@@ -0,0 +1,11 @@
+CREATE TABLE reliable_msgs_received_old (
+  "id"                        UUID            NOT NULL,
+  "node"                      TEXT            NOT NULL,
+  "time"                      BIGINT          NOT NULL,
+  PRIMARY KEY ("id")
+);
+INSERT OR IGNORE INTO reliable_msgs_received_old ("id", "node", "time")
+  SELECT "id", "node", "time" FROM reliable_msgs_received;
+DROP TABLE reliable_msgs_received;
+ALTER TABLE reliable_msgs_received_old RENAME TO reliable_msgs_received;
+CREATE INDEX reliable_msgs_received_time ON reliable_msgs_received ("time");
@@ -0,0 +1,13 @@
+-- Received messages are de-duplicated per sending node, so one node cannot suppress another's messages by
+-- re-using their IDs. SQLite cannot change the primary key of an existing table, so we re-create it.
+CREATE TABLE reliable_msgs_received_new (
+  "id"                        UUID            NOT NULL,
+  "node"                      TEXT            NOT NULL,
+  "time"                      BIGINT          NOT NULL,
+  PRIMARY KEY ("node", "id")
+);
+INSERT INTO reliable_msgs_received_new ("id", "node", "time")
+  SELECT "id", "node", "time" FROM reliable_msgs_received;
+DROP TABLE reliable_msgs_received;
+ALTER TABLE reliable_msgs_received_new RENAME TO reliable_msgs_received;
+CREATE INDEX reliable_msgs_received_time ON reliable_msgs_received ("time");
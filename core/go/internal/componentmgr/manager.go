@@ -25,14 +25,17 @@ import (
 	"github.com/kaleido-io/paladin/config/pkg/pldconf"
 	"github.com/kaleido-io/paladin/core/internal/components"
 	"github.com/kaleido-io/paladin/core/internal/domainmgr"
+	"github.com/kaleido-io/paladin/core/internal/flushwriter"
 	"github.com/kaleido-io/paladin/core/internal/identityresolver"
 	"github.com/kaleido-io/paladin/core/internal/keymanager"
+	"github.com/kaleido-io/paladin/core/internal/metrics"
 	"github.com/kaleido-io/paladin/core/internal/msgs"
 	"github.com/kaleido-io/paladin/core/internal/plugins"
 	"github.com/kaleido-io/paladin/core/internal/privatetxnmgr"
 	"github.com/kaleido-io/paladin/core/internal/publictxmgr"
 	"github.com/kaleido-io/paladin/core/internal/registrymgr"
 	"github.com/kaleido-io/paladin/core/internal/statemgr"
+	"github.com/kaleido-io/paladin/core/internal/tracing"
 	"github.com/kaleido-io/paladin/core/internal/transportmgr"
 	"github.com/kaleido-io/paladin/core/internal/txmgr"
 	"github.com/kaleido-io/paladin/core/pkg/blockindexer"
@@ -63,6 +66,7 @@ type componentManager struct {
 	// debug server
 	debugServer httpserver.Server
 	// pre-init
+	metricsManager   metrics.Metrics
 	keyManager       components.KeyManager
 	ethClientFactory ethclient.EthClientFactory
 	persistence      persistence.Persistence
@@ -130,14 +134,26 @@ func (cm *componentManager) startDebugServer() (httpserver.Server, error) {
 	server, err := httpserver.NewDebugServer(cm.bgCtx, &cm.conf.DebugServer.HTTPServerConfig)
 	if err == nil {
 		server.Router().PathPrefix("/debug/javadump").HandlerFunc(http.HandlerFunc(cm.javaDump))
+		server.Router().Path("/metrics").Handler(cm.metricsManager.HTTPHandler())
 		err = server.Start()
 	}
 	return server, err
 }
 
 func (cm *componentManager) Init() (err error) {
+	// metrics are registered by each component as it initializes, and served by the debug server
+	cm.metricsManager = metrics.NewMetricsManager(cm.bgCtx)
+	err = cm.wrapIfErr(flushwriter.RegisterMetrics(cm.metricsManager.Registry()), msgs.MsgComponentMetricsInitError)
+
+	// the tracer provider is installed globally before any component creates spans
+	if err == nil {
+		var tracingManager tracing.Tracing
+		tracingManager, err = tracing.NewTracing(cm.bgCtx, &cm.conf.Tracing)
+		err = cm.addIfOpened("tracing", tracingManager, err, msgs.MsgComponentTracingInitError)
+	}
+
 	// start the debug server as early as possible
-	if confutil.Bool(cm.conf.DebugServer.Enabled, *pldconf.DebugServerDefaults.Enabled) {
+	if err == nil && confutil.Bool(cm.conf.DebugServer.Enabled, *pldconf.DebugServerDefaults.Enabled) {
 		cm.debugServer, err = cm.startDebugServer()
 		err = cm.addIfStarted("debugServer", cm.debugServer, err, msgs.MsgComponentDebugServerStartError)
 	}
@@ -152,7 +168,7 @@ func (cm *componentManager) Init() (err error) {
 		err = cm.addIfOpened("database", cm.persistence, err, msgs.MsgComponentDBInitError)
 	}
 	if err == nil {
-		cm.blockIndexer, err = blockindexer.NewBlockIndexer(cm.bgCtx, &cm.conf.BlockIndexer, &cm.conf.Blockchain.WS, cm.persistence)
+		cm.blockIndexer, err = blockindexer.NewBlockIndexer(cm.bgCtx, &cm.conf.BlockIndexer, &cm.conf.Blockchain.WS, cm.persistence, cm.metricsManager.Registry())
 		err = cm.wrapIfErr(err, msgs.MsgComponentBlockIndexerInitError)
 	}
 	if err == nil {
@@ -437,6 +453,7 @@ func (cm *componentManager) buildInternalEventStreams() ([]*blockindexer.Interna
 			streams = append(streams, &blockindexer.InternalEventStream{
 				Type:             blockindexer.IESTypePreCommitHandler,
 				PreCommitHandler: initResult.PreCommitHandler,
+				RewindHandler:    initResult.RewindHandler,
 			})
 		}
 	}
@@ -492,6 +509,10 @@ func (cm *componentManager) RPCServer() rpcserver.RPCServer {
 	return cm.rpcServer
 }
 
+func (cm *componentManager) MetricsManager() metrics.Metrics {
+	return cm.metricsManager
+}
+
 func (cm *componentManager) BlockIndexer() blockindexer.BlockIndexer {
 	return cm.blockIndexer
 }
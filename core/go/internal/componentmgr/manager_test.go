@@ -19,6 +19,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"net"
 	"net/http"
 	"os"
@@ -128,12 +129,23 @@ func TestInitOK(t *testing.T) {
 	assert.NotNil(t, cm.PublicTxManager())
 	assert.NotNil(t, cm.TxManager())
 	assert.NotNil(t, cm.IdentityResolver())
+	assert.NotNil(t, cm.MetricsManager())
 
 	// Check we can send a request for a javadump - even just after init (not start)
 	resp, err := http.Get(fmt.Sprintf("http://localhost:%d/debug/javadump", debugPort))
 	require.NoError(t, err)
 	assert.Equal(t, http.StatusAccepted, resp.StatusCode)
 
+	// Check the metrics registered by the managers are served (labelled metrics only appear once used)
+	resp, err = http.Get(fmt.Sprintf("http://localhost:%d/metrics", debugPort))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	metricsText, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Contains(t, string(metricsText), "paladin_blockindexer_lag_blocks")
+	assert.Contains(t, string(metricsText), "paladin_public_tx_orchestrators_free")
+
 	cm.Stop()
 
 }
@@ -68,6 +68,12 @@ type PublicTxManager interface {
 	// Convenience function that does ValidateTransaction+WriteNewTransactions for a single Tx
 	SingleTransactionSubmit(ctx context.Context, transaction *PublicTxSubmission) (*pldapi.PublicTx, error)
 
+	// Submit a replacement for a pending transaction with the same nonce - either updated with the supplied changes, or
+	// cancelled by replacing it with a zero value transfer to the signing address. The binding to the Paladin transaction remains.
+	UpdateTransaction(ctx context.Context, from tktypes.EthAddress, nonce uint64, update *pldapi.PublicTxUpdate) error
+	CancelTransaction(ctx context.Context, from tktypes.EthAddress, nonce uint64) error
+
 	MatchUpdateConfirmedTransactions(ctx context.Context, dbTX *gorm.DB, itxs []*blockindexer.IndexedTransactionNotify) ([]*PublicTxMatch, error)
 	NotifyConfirmPersisted(ctx context.Context, confirms []*PublicTxMatch)
+	RewindConfirmedTransactions(ctx context.Context, dbTX *gorm.DB, forkBlock int64) (postCommit func(), err error)
 }
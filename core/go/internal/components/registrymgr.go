@@ -55,4 +55,6 @@ type Registry interface {
 	QueryEntries(ctx context.Context, dbTX *gorm.DB, fActive pldapi.ActiveFilter, jq *query.QueryJSON) ([]*pldapi.RegistryEntry, error)
 	QueryEntriesWithProps(ctx context.Context, dbTX *gorm.DB, fActive pldapi.ActiveFilter, jq *query.QueryJSON) ([]*pldapi.RegistryEntryWithProperties, error)
 	GetEntryProperties(ctx context.Context, dbTX *gorm.DB, fActive pldapi.ActiveFilter, entityIDs ...tktypes.HexBytes) ([]*pldapi.RegistryProperty, error)
+	RegisterIdentity(ctx context.Context, identity *pldapi.RegistryIdentityInput) (*uuid.UUID, error)
+	SetProperty(ctx context.Context, property *pldapi.RegistryPropertyInput) (*uuid.UUID, error)
 }
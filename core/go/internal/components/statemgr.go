@@ -45,6 +45,10 @@ type StateManager interface {
 	// State finalizations are written on the DB context of the block indexer, by the domain manager.
 	WriteStateFinalizations(ctx context.Context, dbTX *gorm.DB, spends []*pldapi.StateSpendRecord, reads []*pldapi.StateReadRecord, confirms []*pldapi.StateConfirmRecord, infoRecords []*pldapi.StateInfoRecord) (err error)
 
+	// Removes the finalizations written for a domain from blocks after the fork point of a chain re-org,
+	// on the DB context of the block indexer rollback.
+	RewindStateFinalizations(ctx context.Context, dbTX *gorm.DB, domainName string, forkBlock int64) error
+
 	// MUST NOT be called for states received over a network from another node.
 	// Writes a batch of states that have been pre-verified BY THIS NODE so can bypass domain hash verification.
 	WritePreVerifiedStates(ctx context.Context, dbTX *gorm.DB, domainName string, states []*StateUpsertOutsideContext) ([]*pldapi.State, error)
@@ -22,6 +22,7 @@ import (
 
 	"github.com/kaleido-io/paladin/config/pkg/pldconf"
 	"github.com/kaleido-io/paladin/toolkit/pkg/plugintk"
+	"gorm.io/gorm"
 )
 
 type TransportMessage struct {
@@ -30,8 +31,10 @@ type TransportMessage struct {
 	Component     string // The name of the component to route the message to once it arrives at the destination node
 	Node          string // The node id to send the message to
 	ReplyTo       string // The node id to send replies to
+	FromNode      string // The node the transport authenticated as the sender, if it supports authentication (received messages only)
 	MessageType   string
 	Payload       []byte
+	TraceContext  map[string]string // W3C trace context of the sender, so spans on the receiving node join the same trace
 }
 
 type TransportManagerToTransport interface {
@@ -50,7 +53,8 @@ type TransportClient interface {
 	// - SHOULD NOT perform any processing within the function call itself beyond routing
 	//
 	// There is no ack to the messages. They are at-most-once delivery. So there is no error return.
-	// Use it or lose it.
+	// Use it or lose it. Components that need delivery to survive failures and restarts should use
+	// SendReliable, and implement ReliableTransportClient.
 	//
 	// The design assumption of the transport manager is that the engine is entirely responsible
 	// for determining what thread-of-control to dispatch any given message to.
@@ -73,6 +77,24 @@ type TransportClient interface {
 	ReceiveTransportMessage(context.Context, *TransportMessage)
 }
 
+// ReliableTransportClient is an extension of TransportClient, that must be implemented by components
+// that receive messages sent with SendReliable.
+//
+// Reliable messages for a destination that does not implement this interface are not acknowledged,
+// so they are resent by the sending node until the destination is upgraded to support them.
+//
+// Delivery is NOT ordered, even for messages from the same node. The sender has a window of messages
+// in flight to each peer, and each is resent independently until acknowledged, so a later message can
+// be received before an earlier one. Implementations must process each message on its own merits.
+type ReliableTransportClient interface {
+	TransportClient
+	// Called inside the DB transaction that records the receipt of the message, so that anything the
+	// client writes in that transaction is committed exactly once for each message.
+	// Returning an error rolls back the transaction, and the sending node will redeliver the message.
+	// The postCommit function, if returned, is called once the transaction commits.
+	ReceiveReliableTransportMessage(ctx context.Context, dbTX *gorm.DB, msg *TransportMessage) (postCommit func(), err error)
+}
+
 type TransportManager interface {
 	ManagerLifecycle
 	ConfiguredTransports() map[string]*pldconf.PluginConfig
@@ -91,6 +113,27 @@ type TransportManager interface {
 	// e.g. at-most-once delivery semantics
 	Send(ctx context.Context, message *TransportMessage) error
 
+	// SendReliable writes messages to a persistent queue for each destination node, in the supplied
+	// DB transaction. Messages are first sent to the node in the order they were queued, and each is
+	// resent with a backoff until the transport manager on the receiving node acknowledges it has been
+	// processed.
+	//
+	// Delivery is at-least-once on the wire, with de-duplication on the receiving node. Ordering is
+	// NOT guaranteed, even to a single node: up to the configured maximum number of messages are in
+	// flight to each node at once, and any of them might be lost and resent after later ones are processed.
+	//
+	// The postCommit function must be called once the DB transaction commits, to start sending.
+	// The component on the receiving node must implement ReliableTransportClient.
+	//
+	// Acknowledged messages, and the record of received messages used to remove duplicates, are
+	// pruned after the configured retention.
+	SendReliable(ctx context.Context, dbTX *gorm.DB, messages ...*TransportMessage) (postCommit func(), err error)
+
+	// TransportDetailsChanged informs each transport that the transport details published by nodes
+	// in the registry might have changed, so it can re-verify any connections it has with them.
+	// Failures are logged, rather than returned, as the registry update has already committed.
+	TransportDetailsChanged(ctx context.Context)
+
 	// RegisterClient registers a client to receive messages from the transport manager
 	// messages are routed to the client based on the Destination field of the message matching the value returned from Destination() function of the TransportClient
 	RegisterClient(ctx context.Context, client TransportClient) error
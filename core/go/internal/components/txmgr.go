@@ -73,12 +73,23 @@ type ResolvedFunction struct {
 	Signature    string           `json:"signature"`
 }
 
+// A receiver is attached to a receipt listener to have batches of receipts pushed to it.
+// DeliverReceiptBatch must block until the batch has been processed (acknowledged), and
+// return an error if it was not - in which case the same receipts will be redelivered.
+type ReceiptReceiver interface {
+	DeliverReceiptBatch(ctx context.Context, batchID uint64, receipts []*pldapi.TransactionReceiptFull) error
+}
+
+type ReceiverCloser interface {
+	Close()
+}
+
 type TXManager interface {
 	ManagerLifecycle
 
 	// These are the general purpose functions exposed also as JSON/RPC APIs on the TX Manager
 
-	FinalizeTransactions(ctx context.Context, dbTX *gorm.DB, info []*ReceiptInput) error // requires all transactions to be known
+	FinalizeTransactions(ctx context.Context, dbTX *gorm.DB, info []*ReceiptInput) (postCommit func(), err error) // requires all transactions to be known
 	CalculateRevertError(ctx context.Context, dbTX *gorm.DB, revertData tktypes.HexBytes) error
 	DecodeRevertError(ctx context.Context, dbTX *gorm.DB, revertData tktypes.HexBytes, dataFormat tktypes.JSONFormatOptions) (*pldapi.ABIDecodedData, error)
 	DecodeCall(ctx context.Context, dbTX *gorm.DB, callData tktypes.HexBytes, dataFormat tktypes.JSONFormatOptions) (*pldapi.ABIDecodedData, error)
@@ -103,6 +114,13 @@ type TXManager interface {
 	QueryPreparedTransactions(ctx context.Context, dbTX *gorm.DB, jq *query.QueryJSON) ([]*pldapi.PreparedTransaction, error)
 	CallTransaction(ctx context.Context, result any, tx *pldapi.TransactionCall) (err error)
 	UpsertABI(ctx context.Context, dbTX *gorm.DB, a abi.ABI) (func(), *pldapi.StoredABI, error)
+	CreateReceiptListener(ctx context.Context, spec *pldapi.TransactionReceiptListener) error
+	GetReceiptListener(ctx context.Context, name string) *pldapi.TransactionReceiptListener
+	QueryReceiptListeners(ctx context.Context, dbTX *gorm.DB, jq *query.QueryJSON) ([]*pldapi.TransactionReceiptListener, error)
+	StartReceiptListener(ctx context.Context, name string) error
+	StopReceiptListener(ctx context.Context, name string) error
+	DeleteReceiptListener(ctx context.Context, name string) error
+	AddReceiptReceiver(ctx context.Context, name string, r ReceiptReceiver) (ReceiverCloser, error)
 
 	// These functions for use of the private TX manager for chaining private transactions.
 
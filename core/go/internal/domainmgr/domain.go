@@ -19,6 +19,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"sync"
 	"sync/atomic"
 
@@ -64,6 +65,8 @@ type domain struct {
 	config             *prototk.DomainConfig
 	schemasBySignature map[string]components.Schema
 	schemasByID        map[string]components.Schema
+	eventsABI          abi.ABI
+	rpcMethods         map[string]*prototk.DomainRPCMethod
 	eventStream        *blockindexer.EventStream
 
 	initError atomic.Pointer[error]
@@ -117,6 +120,15 @@ func (d *domain) processDomainConfig(confRes *prototk.ConfigureDomainResponse) (
 		}
 	}
 
+	// Methods are served under domain_<name>_<method>, so the method name cannot contain the separator
+	d.rpcMethods = make(map[string]*prototk.DomainRPCMethod, len(d.config.RpcMethods))
+	for _, m := range d.config.RpcMethods {
+		if m.Name == "" || strings.Contains(m.Name, "_") {
+			return nil, i18n.NewError(d.ctx, msgs.MsgDomainInvalidRPCMethod, m.Name)
+		}
+		d.rpcMethods[m.Name] = m
+	}
+
 	// Ensure all the schemas are recorded to the DB
 	var schemas []components.Schema
 	if len(abiSchemas) > 0 {
@@ -153,6 +165,7 @@ func (d *domain) processDomainConfig(confRes *prototk.ConfigureDomainResponse) (
 			return nil, i18n.WrapError(d.ctx, err, msgs.MsgDomainInvalidEvents)
 		}
 		stream.Sources = append(stream.Sources, blockindexer.EventStreamSource{ABI: eventsABI})
+		d.eventsABI = eventsABI
 
 		postCommit, _, err := d.dm.txManager.UpsertABI(d.ctx, d.dm.persistence.DB(), eventsABI)
 		if err != nil {
@@ -171,8 +184,9 @@ func (d *domain) processDomainConfig(confRes *prototk.ConfigureDomainResponse) (
 
 	// Create the event stream
 	d.eventStream, err = d.dm.blockIndexer.AddEventStream(d.ctx, &blockindexer.InternalEventStream{
-		Definition: stream,
-		Handler:    d.handleEventBatch,
+		Definition:    stream,
+		Handler:       d.handleEventBatch,
+		RewindHandler: d.handleRewind,
 	})
 	if err != nil {
 		return nil, err
@@ -556,6 +570,83 @@ func (d *domain) RecoverSigner(ctx context.Context, recoverRequest *prototk.Reco
 	}
 }
 
+// Domain callback to read back the events a contract has emitted, from those the block indexer has indexed.
+// The block indexer does not store the address or data of an event, so each transaction in the page is
+// decoded from its receipt, and the events from other contracts are dropped.
+func (d *domain) QueryContractEvents(ctx context.Context, req *prototk.QueryContractEventsRequest) (*prototk.QueryContractEventsResponse, error) {
+	c, err := d.checkInFlight(ctx, req.StateQueryContext)
+	if err != nil {
+		return nil, err
+	}
+	if req.Limit <= 0 {
+		return nil, i18n.NewError(ctx, msgs.MsgDomainQueryEventsLimitRequired)
+	}
+	res := &prototk.QueryContractEventsResponse{Events: []*prototk.OnChainEvent{}}
+	signatures := make([]any, 0, len(d.eventsABI))
+	for _, e := range d.eventsABI {
+		if e.Type == abi.Event {
+			signatures = append(signatures, e.SignatureHashBytes())
+		}
+	}
+	if len(signatures) == 0 {
+		return res, nil
+	}
+
+	qb := query.NewQueryBuilder().
+		Limit(int(req.Limit)).
+		Sort("blockNumber", "transactionIndex", "logIndex").
+		In("signature", signatures)
+	if req.After != nil {
+		qb = qb.Or(
+			query.NewQueryBuilder().GreaterThan("blockNumber", req.After.BlockNumber),
+			query.NewQueryBuilder().Equal("blockNumber", req.After.BlockNumber).GreaterThan("transactionIndex", req.After.TransactionIndex),
+			query.NewQueryBuilder().Equal("blockNumber", req.After.BlockNumber).Equal("transactionIndex", req.After.TransactionIndex).GreaterThan("logIndex", req.After.LogIndex),
+		)
+	}
+	indexed, err := d.dm.blockIndexer.QueryIndexedEvents(ctx, qb.Query())
+	if err != nil {
+		return nil, err
+	}
+
+	contractAddr := c.dCtx.Info().ContractAddress
+	for i := 0; i < len(indexed); {
+		// Decode all the events of the transaction in one go
+		txHash := indexed[i].TransactionHash
+		decoded, err := d.dm.blockIndexer.DecodeTransactionEvents(ctx, txHash, d.eventsABI, "")
+		if err != nil {
+			return nil, err
+		}
+		for ; i < len(indexed) && indexed[i].TransactionHash == txHash; i++ {
+			for _, ev := range decoded {
+				if ev.LogIndex == indexed[i].LogIndex && ev.Address == contractAddr && ev.SoliditySignature != "" {
+					res.Events = append(res.Events, &prototk.OnChainEvent{
+						Location: &prototk.OnChainEventLocation{
+							TransactionHash:  ev.TransactionHash.String(),
+							BlockNumber:      ev.BlockNumber,
+							TransactionIndex: ev.TransactionIndex,
+							LogIndex:         ev.LogIndex,
+						},
+						Signature:         ev.Signature.String(),
+						SoliditySignature: ev.SoliditySignature,
+						DataJson:          ev.Data.String(),
+					})
+				}
+			}
+		}
+	}
+
+	if len(indexed) == int(req.Limit) {
+		last := indexed[len(indexed)-1]
+		res.Next = &prototk.OnChainEventLocation{
+			TransactionHash:  last.TransactionHash.String(),
+			BlockNumber:      last.BlockNumber,
+			TransactionIndex: last.TransactionIndex,
+			LogIndex:         last.LogIndex,
+		}
+	}
+	return res, nil
+}
+
 func (d *domain) InitDeploy(ctx context.Context, tx *components.PrivateContractDeploy) error {
 	if tx.Inputs == nil {
 		return i18n.NewError(ctx, msgs.MsgDomainTXIncompleteInitDeploy)
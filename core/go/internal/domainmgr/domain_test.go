@@ -34,6 +34,7 @@ import (
 	"github.com/kaleido-io/paladin/toolkit/pkg/pldapi"
 	"github.com/kaleido-io/paladin/toolkit/pkg/plugintk"
 	"github.com/kaleido-io/paladin/toolkit/pkg/prototk"
+	"github.com/kaleido-io/paladin/toolkit/pkg/query"
 	"github.com/kaleido-io/paladin/toolkit/pkg/signpayloads"
 	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
 	"github.com/kaleido-io/paladin/toolkit/pkg/verifiers"
@@ -1022,6 +1023,112 @@ func TestRecoverSignerFailCases(t *testing.T) {
 	assert.Regexp(t, "PD011638", err)
 }
 
+func TestQueryContractEventsOK(t *testing.T) {
+	var mc *mockComponents
+	domainConf := goodDomainConf()
+	domainConf.AbiEventsJson = fakeCoinEventsABI
+	td, done := newTestDomain(t, true, domainConf, mockUpsertABIOk, func(_mc *mockComponents) { mc = _mc })
+	defer done()
+
+	tx1 := tktypes.Bytes32(tktypes.RandBytes(32))
+	tx2 := tktypes.Bytes32(tktypes.RandBytes(32))
+	sig := tktypes.Bytes32(td.d.eventsABI[0].SignatureHashBytes())
+	mc.blockIndexer.On("QueryIndexedEvents", mock.Anything, mock.MatchedBy(func(jq *query.QueryJSON) bool {
+		return *jq.Limit == 3 && len(jq.Or) == 3
+	})).Return([]*pldapi.IndexedEvent{
+		{BlockNumber: 10, TransactionIndex: 0, LogIndex: 1, TransactionHash: tx1, Signature: sig},
+		{BlockNumber: 10, TransactionIndex: 0, LogIndex: 2, TransactionHash: tx1, Signature: sig},
+		{BlockNumber: 11, TransactionIndex: 3, LogIndex: 0, TransactionHash: tx2, Signature: sig},
+	}, nil)
+	mc.blockIndexer.On("DecodeTransactionEvents", mock.Anything, tx1, td.d.eventsABI, tktypes.JSONFormatOptions("")).Return([]*pldapi.EventWithData{
+		{
+			IndexedEvent:      &pldapi.IndexedEvent{BlockNumber: 10, TransactionIndex: 0, LogIndex: 1, TransactionHash: tx1, Signature: sig},
+			SoliditySignature: "event Transfer(bytes32[] inputs, bytes32[] outputs, bytes data)",
+			Address:           td.contractAddress,
+			Data:              tktypes.RawJSON(`{"data":"0x01"}`),
+		},
+		{
+			IndexedEvent:      &pldapi.IndexedEvent{BlockNumber: 10, TransactionIndex: 0, LogIndex: 2, TransactionHash: tx1, Signature: sig},
+			SoliditySignature: "event Transfer(bytes32[] inputs, bytes32[] outputs, bytes data)",
+			Address:           *tktypes.RandAddress(),
+			Data:              tktypes.RawJSON(`{"data":"0x02"}`),
+		},
+	}, nil)
+	mc.blockIndexer.On("DecodeTransactionEvents", mock.Anything, tx2, td.d.eventsABI, tktypes.JSONFormatOptions("")).Return([]*pldapi.EventWithData{
+		{
+			IndexedEvent:      &pldapi.IndexedEvent{BlockNumber: 11, TransactionIndex: 3, LogIndex: 0, TransactionHash: tx2, Signature: sig},
+			SoliditySignature: "event Transfer(bytes32[] inputs, bytes32[] outputs, bytes data)",
+			Address:           td.contractAddress,
+			Data:              tktypes.RawJSON(`{"data":"0x03"}`),
+		},
+	}, nil)
+
+	res, err := td.d.QueryContractEvents(td.ctx, &prototk.QueryContractEventsRequest{
+		StateQueryContext: td.c.id,
+		After:             &prototk.OnChainEventLocation{BlockNumber: 9},
+		Limit:             3,
+	})
+	require.NoError(t, err)
+	require.Len(t, res.Events, 2)
+	assert.Equal(t, tx1.String(), res.Events[0].Location.TransactionHash)
+	assert.JSONEq(t, `{"data":"0x01"}`, res.Events[0].DataJson)
+	assert.Equal(t, int64(11), res.Events[1].Location.BlockNumber)
+	assert.JSONEq(t, `{"data":"0x03"}`, res.Events[1].DataJson)
+	require.NotNil(t, res.Next)
+	assert.Equal(t, int64(11), res.Next.BlockNumber)
+	assert.Equal(t, int64(3), res.Next.TransactionIndex)
+}
+
+func TestQueryContractEventsNoEvents(t *testing.T) {
+	td, done := newTestDomain(t, false, goodDomainConf(), mockSchemas())
+	defer done()
+
+	res, err := td.d.QueryContractEvents(td.ctx, &prototk.QueryContractEventsRequest{
+		StateQueryContext: td.c.id,
+		Limit:             10,
+	})
+	require.NoError(t, err)
+	assert.Empty(t, res.Events)
+	assert.Nil(t, res.Next)
+}
+
+func TestQueryContractEventsFailCases(t *testing.T) {
+	var mc *mockComponents
+	domainConf := goodDomainConf()
+	domainConf.AbiEventsJson = fakeCoinEventsABI
+	td, done := newTestDomain(t, false, domainConf, mockSchemas(), mockUpsertABIOk, func(_mc *mockComponents) { mc = _mc })
+	defer done()
+
+	_, err := td.d.QueryContractEvents(td.ctx, &prototk.QueryContractEventsRequest{
+		StateQueryContext: "unknown",
+		Limit:             10,
+	})
+	assert.Regexp(t, "PD011649", err)
+
+	_, err = td.d.QueryContractEvents(td.ctx, &prototk.QueryContractEventsRequest{
+		StateQueryContext: td.c.id,
+	})
+	assert.Regexp(t, "PD011663", err)
+
+	txHash := tktypes.Bytes32(tktypes.RandBytes(32))
+	mc.blockIndexer.On("QueryIndexedEvents", mock.Anything, mock.Anything).Return(nil, fmt.Errorf("pop")).Once()
+	_, err = td.d.QueryContractEvents(td.ctx, &prototk.QueryContractEventsRequest{
+		StateQueryContext: td.c.id,
+		Limit:             10,
+	})
+	assert.Regexp(t, "pop", err)
+
+	mc.blockIndexer.On("QueryIndexedEvents", mock.Anything, mock.Anything).Return([]*pldapi.IndexedEvent{
+		{TransactionHash: txHash},
+	}, nil)
+	mc.blockIndexer.On("DecodeTransactionEvents", mock.Anything, txHash, mock.Anything, mock.Anything).Return(nil, fmt.Errorf("snap"))
+	_, err = td.d.QueryContractEvents(td.ctx, &prototk.QueryContractEventsRequest{
+		StateQueryContext: td.c.id,
+		Limit:             10,
+	})
+	assert.Regexp(t, "snap", err)
+}
+
 func TestMapStateLockType(t *testing.T) {
 	for _, pldType := range pldapi.StateLockType("").Options() {
 		assert.NotNil(t, mapStateLockType(pldapi.StateLockType(pldType)))
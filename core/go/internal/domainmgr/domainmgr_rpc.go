@@ -0,0 +1,184 @@
+/*
+ * Copyright © 2024 Kaleido, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package domainmgr
+
+import (
+	"context"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/kaleido-io/paladin/core/internal/msgs"
+	"github.com/kaleido-io/paladin/toolkit/pkg/log"
+	"github.com/kaleido-io/paladin/toolkit/pkg/pldapi"
+	"github.com/kaleido-io/paladin/toolkit/pkg/prototk"
+	"github.com/kaleido-io/paladin/toolkit/pkg/query"
+	"github.com/kaleido-io/paladin/toolkit/pkg/rpcclient"
+	"github.com/kaleido-io/paladin/toolkit/pkg/rpcserver"
+	"github.com/kaleido-io/paladin/toolkit/pkg/tkmsgs"
+	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
+	"gorm.io/gorm"
+)
+
+func (dm *domainManager) initRPC() {
+	dm.rpcModule = rpcserver.NewRPCModule("domain").
+		Add("domain_listDomains", dm.rpcListDomains()).
+		Add("domain_querySmartContracts", dm.rpcQuerySmartContracts()).
+		AddGroupHandler(rpcserver.HandlerFunc(dm.rpcDomainMethod))
+}
+
+func (dm *domainManager) RPCModule() *rpcserver.RPCModule {
+	return dm.rpcModule
+}
+
+func (dm *domainManager) rpcListDomains() rpcserver.RPCHandler {
+	return rpcserver.RPCMethod0(func(ctx context.Context,
+	) ([]*pldapi.Domain, error) {
+		return dm.listDomains(), nil
+	})
+}
+
+func (dm *domainManager) rpcQuerySmartContracts() rpcserver.RPCHandler {
+	return rpcserver.RPCMethod1(func(ctx context.Context,
+		jq query.QueryJSON,
+	) ([]*pldapi.DomainSmartContract, error) {
+		return dm.querySmartContracts(ctx, dm.persistence.DB(), &jq)
+	})
+}
+
+// Methods registered by domains are served as domain_<name>_<method>, where the domain name
+// is everything up to the last separator (as method names cannot contain one).
+func (dm *domainManager) rpcDomainMethod(ctx context.Context, req *rpcclient.RPCRequest) *rpcclient.RPCResponse {
+	d, method := dm.lookupDomainRPCMethod(ctx, req.Method)
+	if method == nil {
+		err := i18n.NewError(ctx, tkmsgs.MsgJSONRPCUnsupportedMethod, req.Method)
+		return rpcclient.NewRPCErrorResponse(err, req.ID, rpcclient.RPCCodeInvalidRequest)
+	}
+
+	params := req.Params
+	var contractAddr *tktypes.EthAddress
+	if method.Contract {
+		if len(params) > 0 {
+			contractAddr, _ = tktypes.ParseEthAddress(params[0].AsString())
+		}
+		if contractAddr == nil {
+			err := i18n.NewError(ctx, msgs.MsgDomainRPCContractAddressRequired, req.Method)
+			return rpcclient.NewRPCErrorResponse(err, req.ID, rpcclient.RPCCodeInvalidRequest)
+		}
+		params = params[1:]
+	}
+
+	result, err := d.handleRPC(ctx, method, contractAddr, params)
+	if err != nil {
+		return rpcclient.NewRPCErrorResponse(err, req.ID, rpcclient.RPCCodeInternalError)
+	}
+	return &rpcclient.RPCResponse{
+		JSONRpc: "2.0",
+		ID:      req.ID,
+		Result:  fftypes.JSONAnyPtrBytes(result),
+	}
+}
+
+func (dm *domainManager) lookupDomainRPCMethod(ctx context.Context, fullMethod string) (*domain, *prototk.DomainRPCMethod) {
+	domainAndMethod := strings.TrimPrefix(fullMethod, "domain_")
+	sep := strings.LastIndex(domainAndMethod, "_")
+	if sep <= 0 {
+		return nil, nil
+	}
+	d, err := dm.getDomainByName(ctx, domainAndMethod[0:sep])
+	if err != nil || d.checkInit(ctx) != nil {
+		return nil, nil
+	}
+	return d, d.rpcMethods[domainAndMethod[sep+1:]]
+}
+
+func (d *domain) handleRPC(ctx context.Context, method *prototk.DomainRPCMethod, contractAddr *tktypes.EthAddress, params []*fftypes.JSONAny) (result tktypes.RawJSON, err error) {
+	if params == nil {
+		params = []*fftypes.JSONAny{}
+	}
+	req := &prototk.HandleRPCRequest{
+		Method:     method.Name,
+		ParamsJson: tktypes.JSONString(params).String(),
+	}
+
+	var res *prototk.HandleRPCResponse
+	if contractAddr == nil {
+		res, err = d.api.HandleRPC(ctx, req)
+	} else {
+		// Contract methods run in a DB transaction, so that any states returned by the domain
+		// are written atomically in the same way as those returned from event processing.
+		err = d.dm.persistence.DB().Transaction(func(dbTX *gorm.DB) (err error) {
+			res, err = d.handleContractRPC(ctx, dbTX, *contractAddr, req)
+			return err
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+	if res.ResultJson == "" {
+		return tktypes.RawJSON("null"), nil
+	}
+	return tktypes.RawJSON(res.ResultJson), nil
+}
+
+func (d *domain) handleContractRPC(ctx context.Context, dbTX *gorm.DB, addr tktypes.EthAddress, req *prototk.HandleRPCRequest) (*prototk.HandleRPCResponse, error) {
+	psc, err := d.dm.GetSmartContractByAddress(ctx, dbTX, addr)
+	if err != nil {
+		return nil, err
+	}
+	if psc.Domain().Name() != d.name {
+		return nil, i18n.NewError(ctx, msgs.MsgDomainContractNotInDomain, addr, d.name)
+	}
+
+	// As with event processing, the domain context is only used for queries and is never flushed
+	dCtx := d.dm.stateStore.NewDomainContext(ctx, d, addr)
+	defer dCtx.Close()
+	c := d.newInFlightDomainRequest(dbTX, dCtx)
+	defer c.close()
+
+	req.StateQueryContext = &c.id
+	req.ContractInfo = &prototk.ContractInfo{
+		ContractAddress:    addr.String(),
+		ContractConfigJson: psc.ContractConfig().ContractConfigJson,
+	}
+	res, err := d.api.HandleRPC(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(res.NewStates) > 0 {
+		// The domain derived these states from the chain as indexed up to the current confirmed
+		// block, so they are recorded against that block and reverted by any re-org below it
+		confirmedBlock, err := d.dm.blockIndexer.GetConfirmedBlockHeight(ctx)
+		if err != nil {
+			return nil, err
+		}
+		blockNumber := int64(confirmedBlock)
+		newStates, stateConfirms, err := d.prepareNewConfirmedStates(ctx, addr, res.NewStates, func(uuid.UUID, *prototk.OnChainEventLocation) *int64 { return &blockNumber })
+		if err != nil {
+			return nil, err
+		}
+		if _, err := d.dm.stateStore.WritePreVerifiedStates(ctx, dbTX, d.name, newStates); err != nil {
+			return nil, err
+		}
+		if err := d.dm.stateStore.WriteStateFinalizations(ctx, dbTX, nil, nil, stateConfirms, nil); err != nil {
+			return nil, err
+		}
+		log.L(ctx).Infof("Domain %s wrote %d states for contract %s from RPC method %s", d.name, len(newStates), addr, req.Method)
+	}
+	return res, nil
+}
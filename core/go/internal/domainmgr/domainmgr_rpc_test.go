@@ -0,0 +1,394 @@
+/*
+ * Copyright © 2024 Kaleido, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package domainmgr
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-resty/resty/v2"
+	"github.com/google/uuid"
+	"github.com/kaleido-io/paladin/config/pkg/confutil"
+	"github.com/kaleido-io/paladin/config/pkg/pldconf"
+	"github.com/kaleido-io/paladin/core/internal/components"
+	"github.com/kaleido-io/paladin/toolkit/pkg/pldapi"
+	"github.com/kaleido-io/paladin/toolkit/pkg/prototk"
+	"github.com/kaleido-io/paladin/toolkit/pkg/query"
+	"github.com/kaleido-io/paladin/toolkit/pkg/rpcclient"
+	"github.com/kaleido-io/paladin/toolkit/pkg/rpcserver"
+	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRPCServer(t *testing.T, ctx context.Context, dm *domainManager) (rpcclient.Client, func()) {
+
+	s, err := rpcserver.NewRPCServer(ctx, &pldconf.RPCServerConfig{
+		HTTP: pldconf.RPCServerConfigHTTP{
+			HTTPServerConfig: pldconf.HTTPServerConfig{Address: confutil.P("127.0.0.1"), Port: confutil.P(0)},
+		},
+		WS: pldconf.RPCServerConfigWS{Disabled: true},
+	})
+	require.NoError(t, err)
+	err = s.Start()
+	require.NoError(t, err)
+
+	s.Register(dm.RPCModule())
+
+	c := rpcclient.WrapRestyClient(resty.New().SetBaseURL(fmt.Sprintf("http://%s", s.HTTPAddr())))
+
+	return c, s.Stop
+
+}
+
+func rpcDomainConf() *prototk.DomainConfig {
+	conf := goodDomainConf()
+	conf.RpcMethods = []*prototk.DomainRPCMethod{
+		{Name: "getInfo"},
+		{Name: "contractInfo", Contract: true},
+	}
+	return conf
+}
+
+func TestRPCDomainMethod(t *testing.T) {
+	td, done := newTestDomain(t, false, rpcDomainConf(), mockSchemas())
+	defer done()
+
+	rpc, rpcDone := newTestRPCServer(t, td.ctx, td.dm)
+	defer rpcDone()
+
+	td.tp.Functions.HandleRPC = func(ctx context.Context, req *prototk.HandleRPCRequest) (*prototk.HandleRPCResponse, error) {
+		assert.Equal(t, "getInfo", req.Method)
+		assert.JSONEq(t, `["param1"]`, req.ParamsJson)
+		assert.Nil(t, req.StateQueryContext)
+		assert.Nil(t, req.ContractInfo)
+		return &prototk.HandleRPCResponse{ResultJson: `{"some":"info"}`}, nil
+	}
+
+	var result tktypes.RawJSON
+	err := rpc.CallRPC(td.ctx, &result, "domain_test1_getInfo", "param1")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"some":"info"}`, result.String())
+
+	td.tp.Functions.HandleRPC = func(ctx context.Context, req *prototk.HandleRPCRequest) (*prototk.HandleRPCResponse, error) {
+		assert.JSONEq(t, `[]`, req.ParamsJson)
+		return &prototk.HandleRPCResponse{}, nil
+	}
+
+	result, handleErr := td.d.handleRPC(td.ctx, td.d.rpcMethods["getInfo"], nil, nil)
+	require.NoError(t, handleErr)
+	assert.Equal(t, "null", result.String())
+}
+
+func TestRPCDomainContractMethod(t *testing.T) {
+	txID := uuid.New()
+	schemaID := tktypes.Bytes32(tktypes.RandBytes(32))
+	stateID := tktypes.RandHex(32)
+	var contractAddr tktypes.EthAddress
+	td, done := newTestDomain(t, false, rpcDomainConf(), mockSchemas(), func(mc *mockComponents) {
+		mc.db.ExpectBegin()
+		mc.db.ExpectCommit()
+		mc.blockIndexer.On("GetConfirmedBlockHeight", mock.Anything).Return(tktypes.HexUint64(12345), nil)
+		mc.stateStore.On("WritePreVerifiedStates", mock.Anything, mock.Anything, "test1", mock.MatchedBy(func(states []*components.StateUpsertOutsideContext) bool {
+			return len(states) == 1 &&
+				states[0].ID.String() == tktypes.MustParseHexBytes(stateID).String() &&
+				states[0].SchemaID == schemaID &&
+				states[0].ContractAddress == contractAddr &&
+				states[0].Data.String() == `{"some":"state"}`
+		})).Return(nil, nil)
+		mc.stateStore.On("WriteStateFinalizations", mock.Anything, mock.Anything,
+			[]*pldapi.StateSpendRecord(nil), []*pldapi.StateReadRecord(nil), []*pldapi.StateConfirmRecord{
+				{DomainName: "test1", State: tktypes.MustParseHexBytes(stateID), Transaction: txID, BlockNumber: confutil.P(int64(12345))},
+			}, []*pldapi.StateInfoRecord(nil)).Return(nil)
+	})
+	defer done()
+
+	psc := goodPSC(t, td)
+	contractAddr = psc.Address()
+
+	rpc, rpcDone := newTestRPCServer(t, td.ctx, td.dm)
+	defer rpcDone()
+
+	td.tp.Functions.HandleRPC = func(ctx context.Context, req *prototk.HandleRPCRequest) (*prototk.HandleRPCResponse, error) {
+		assert.Equal(t, "contractInfo", req.Method)
+		assert.JSONEq(t, `[{"some":"param"}]`, req.ParamsJson)
+		assert.Equal(t, contractAddr.String(), req.ContractInfo.ContractAddress)
+		assert.Equal(t, `{}`, req.ContractInfo.ContractConfigJson)
+		_, err := td.d.checkInFlight(ctx, *req.StateQueryContext)
+		assert.NoError(t, err)
+		return &prototk.HandleRPCResponse{
+			ResultJson: `{"some":"result"}`,
+			NewStates: []*prototk.NewConfirmedState{
+				{
+					Id:            &stateID,
+					SchemaId:      schemaID.String(),
+					StateDataJson: `{"some":"state"}`,
+					TransactionId: tktypes.Bytes32UUIDFirst16(txID).String(),
+				},
+			},
+		}, nil
+	}
+
+	var result tktypes.RawJSON
+	err := rpc.CallRPC(td.ctx, &result, "domain_test1_contractInfo", contractAddr, map[string]string{"some": "param"})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"some":"result"}`, result.String())
+}
+
+func TestRPCDomainContractMethodFail(t *testing.T) {
+	td, done := newTestDomain(t, false, rpcDomainConf(), mockSchemas(), func(mc *mockComponents) {
+		mc.db.ExpectBegin()
+		mc.db.ExpectRollback()
+		mc.blockIndexer.On("GetConfirmedBlockHeight", mock.Anything).Return(tktypes.HexUint64(12345), nil)
+	})
+	defer done()
+
+	psc := goodPSC(t, td)
+
+	rpc, rpcDone := newTestRPCServer(t, td.ctx, td.dm)
+	defer rpcDone()
+
+	td.tp.Functions.HandleRPC = func(ctx context.Context, req *prototk.HandleRPCRequest) (*prototk.HandleRPCResponse, error) {
+		return &prototk.HandleRPCResponse{
+			NewStates: []*prototk.NewConfirmedState{
+				{SchemaId: "wrong", TransactionId: tktypes.Bytes32UUIDFirst16(uuid.New()).String()},
+			},
+		}, nil
+	}
+
+	var result tktypes.RawJSON
+	err := rpc.CallRPC(td.ctx, &result, "domain_test1_contractInfo", psc.Address())
+	assert.Regexp(t, "PD011641", err)
+}
+
+func TestRPCDomainContractMethodBlockHeightFail(t *testing.T) {
+	td, done := newTestDomain(t, false, rpcDomainConf(), mockSchemas(), func(mc *mockComponents) {
+		mc.db.ExpectBegin()
+		mc.db.ExpectRollback()
+		mc.blockIndexer.On("GetConfirmedBlockHeight", mock.Anything).Return(tktypes.HexUint64(0), fmt.Errorf("pop"))
+	})
+	defer done()
+
+	psc := goodPSC(t, td)
+
+	rpc, rpcDone := newTestRPCServer(t, td.ctx, td.dm)
+	defer rpcDone()
+
+	td.tp.Functions.HandleRPC = func(ctx context.Context, req *prototk.HandleRPCRequest) (*prototk.HandleRPCResponse, error) {
+		return &prototk.HandleRPCResponse{
+			NewStates: []*prototk.NewConfirmedState{{}},
+		}, nil
+	}
+
+	var result tktypes.RawJSON
+	err := rpc.CallRPC(td.ctx, &result, "domain_test1_contractInfo", psc.Address())
+	assert.Regexp(t, "pop", err)
+}
+
+func TestRPCDomainContractMethodWrongDomain(t *testing.T) {
+	td, done := newTestDomain(t, false, rpcDomainConf(), mockSchemas(), func(mc *mockComponents) {
+		mc.db.ExpectBegin()
+		mc.db.ExpectRollback()
+	})
+	defer done()
+
+	psc := goodPSC(t, td)
+	psc.d = &domain{name: "test2"}
+
+	rpc, rpcDone := newTestRPCServer(t, td.ctx, td.dm)
+	defer rpcDone()
+
+	var result tktypes.RawJSON
+	err := rpc.CallRPC(td.ctx, &result, "domain_test1_contractInfo", psc.Address())
+	assert.Regexp(t, "PD011666", err)
+}
+
+func TestRPCDomainContractMethodBadAddress(t *testing.T) {
+	td, done := newTestDomain(t, false, rpcDomainConf(), mockSchemas())
+	defer done()
+
+	rpc, rpcDone := newTestRPCServer(t, td.ctx, td.dm)
+	defer rpcDone()
+
+	var result tktypes.RawJSON
+	err := rpc.CallRPC(td.ctx, &result, "domain_test1_contractInfo")
+	assert.Regexp(t, "PD011665", err)
+
+	err = rpc.CallRPC(td.ctx, &result, "domain_test1_contractInfo", "not an address")
+	assert.Regexp(t, "PD011665", err)
+}
+
+func TestRPCDomainMethodUnknown(t *testing.T) {
+	td, done := newTestDomain(t, false, rpcDomainConf(), mockSchemas())
+	defer done()
+
+	rpc, rpcDone := newTestRPCServer(t, td.ctx, td.dm)
+	defer rpcDone()
+
+	for _, method := range []string{
+		"domain_test1_unknown",
+		"domain_test2_getInfo",
+		"domain_getInfo",
+		"domain__getInfo",
+	} {
+		var result tktypes.RawJSON
+		err := rpc.CallRPC(td.ctx, &result, method)
+		assert.Regexp(t, "PD020702", err)
+	}
+}
+
+func TestRPCDomainMethodError(t *testing.T) {
+	td, done := newTestDomain(t, false, rpcDomainConf(), mockSchemas())
+	defer done()
+
+	rpc, rpcDone := newTestRPCServer(t, td.ctx, td.dm)
+	defer rpcDone()
+
+	td.tp.Functions.HandleRPC = func(ctx context.Context, req *prototk.HandleRPCRequest) (*prototk.HandleRPCResponse, error) {
+		return nil, fmt.Errorf("pop")
+	}
+
+	var result tktypes.RawJSON
+	err := rpc.CallRPC(td.ctx, &result, "domain_test1_getInfo")
+	assert.Regexp(t, "pop", err)
+}
+
+func TestDomainInitBadRPCMethod(t *testing.T) {
+	td, done := newTestDomain(t, false, &prototk.DomainConfig{
+		RpcMethods: []*prototk.DomainRPCMethod{
+			{Name: "bad_name"},
+		},
+	})
+	defer done()
+	assert.Regexp(t, "PD011664", *td.d.initError.Load())
+	assert.False(t, td.tp.initialized.Load())
+}
+
+func TestRPCListDomains(t *testing.T) {
+	td, done := newTestDomain(t, false, goodDomainConf(), mockSchemas())
+	defer done()
+
+	rpc, rpcDone := newTestRPCServer(t, td.ctx, td.dm)
+	defer rpcDone()
+
+	var domains []*pldapi.Domain
+	err := rpc.CallRPC(td.ctx, &domains, "domain_listDomains")
+	require.NoError(t, err)
+	require.Len(t, domains, 1)
+	assert.Equal(t, "test1", domains[0].Name)
+	assert.Equal(t, td.d.RegistryAddress(), domains[0].RegistryAddress)
+}
+
+func TestRPCQuerySmartContracts(t *testing.T) {
+	td, done := newTestDomain(t, true, goodDomainConf())
+	defer done()
+
+	td.tp.Functions.InitContract = func(ctx context.Context, icr *prototk.InitContractRequest) (*prototk.InitContractResponse, error) {
+		return &prototk.InitContractResponse{
+			Valid: true,
+			ContractConfig: &prototk.ContractConfig{
+				ContractConfigJson:   `{"some":"config"}`,
+				CoordinatorSelection: prototk.ContractConfig_COORDINATOR_STATIC,
+				StaticCoordinator:    confutil.P("node1"),
+				SubmitterSelection:   prototk.ContractConfig_SUBMITTER_COORDINATOR,
+			},
+		}, nil
+	}
+
+	known := &PrivateSmartContract{
+		DeployTX:        uuid.New(),
+		RegistryAddress: *td.d.RegistryAddress(),
+		Address:         *tktypes.RandAddress(),
+		ConfigBytes:     tktypes.HexBytes{0xfe, 0xed},
+	}
+	unknown := &PrivateSmartContract{
+		DeployTX:        uuid.New(),
+		RegistryAddress: *tktypes.RandAddress(),
+		Address:         *tktypes.RandAddress(),
+		ConfigBytes:     tktypes.HexBytes{0xbe, 0xef},
+	}
+	err := td.dm.persistence.DB().Table("private_smart_contracts").Create([]*PrivateSmartContract{known, unknown}).Error
+	require.NoError(t, err)
+
+	rpc, rpcDone := newTestRPCServer(t, td.ctx, td.dm)
+	defer rpcDone()
+
+	var contracts []*pldapi.DomainSmartContract
+	err = rpc.CallRPC(td.ctx, &contracts, "domain_querySmartContracts",
+		query.NewQueryBuilder().Equal("domainAddress", td.d.RegistryAddress()).Limit(10).Query())
+	require.NoError(t, err)
+	require.Len(t, contracts, 1)
+	assert.Equal(t, "test1", contracts[0].DomainName)
+	assert.Equal(t, known.Address, contracts[0].Address)
+	assert.Equal(t, known.DeployTX, contracts[0].DeployTransaction)
+	assert.JSONEq(t, `{"some":"config"}`, contracts[0].Config.ContractConfigJSON.String())
+	assert.Equal(t, "COORDINATOR_STATIC", contracts[0].Config.CoordinatorSelection)
+	assert.Equal(t, "node1", *contracts[0].Config.StaticCoordinator)
+	assert.Equal(t, "SUBMITTER_COORDINATOR", contracts[0].Config.SubmitterSelection)
+
+	contracts = nil
+	err = rpc.CallRPC(td.ctx, &contracts, "domain_querySmartContracts",
+		query.NewQueryBuilder().Equal("deployTransaction", unknown.DeployTX).Limit(10).Query())
+	require.NoError(t, err)
+	require.Len(t, contracts, 1)
+	assert.Empty(t, contracts[0].DomainName)
+	assert.Equal(t, unknown.RegistryAddress, contracts[0].DomainAddress)
+	assert.Equal(t, unknown.Address, contracts[0].Address)
+	assert.Nil(t, contracts[0].Config)
+
+	contracts = nil
+	err = rpc.CallRPC(td.ctx, &contracts, "domain_querySmartContracts", query.NewQueryBuilder().Limit(10).Query())
+	require.NoError(t, err)
+	assert.Len(t, contracts, 2)
+}
+
+func TestQuerySmartContractsLimitRequired(t *testing.T) {
+	td, done := newTestDomain(t, false, goodDomainConf(), mockSchemas())
+	defer done()
+
+	_, err := td.dm.querySmartContracts(td.ctx, td.dm.persistence.DB(), &query.QueryJSON{})
+	assert.Regexp(t, "PD011667", err)
+}
+
+func TestQuerySmartContractsDBFail(t *testing.T) {
+	td, done := newTestDomain(t, false, goodDomainConf(), mockSchemas(), func(mc *mockComponents) {
+		mc.db.ExpectQuery("SELECT.*private_smart_contracts").WillReturnError(fmt.Errorf("pop"))
+	})
+	defer done()
+
+	_, err := td.dm.querySmartContracts(td.ctx, td.dm.persistence.DB(), query.NewQueryBuilder().Limit(1).Query())
+	assert.Regexp(t, "pop", err)
+}
+
+func TestQuerySmartContractsInitFail(t *testing.T) {
+	var mc *mockComponents
+	td, done := newTestDomain(t, false, goodDomainConf(), mockSchemas(), func(_mc *mockComponents) { mc = _mc })
+	defer done()
+
+	mc.db.ExpectQuery("SELECT.*private_smart_contracts").WillReturnRows(sqlmock.NewRows(
+		[]string{"address", "domain_address"},
+	).AddRow(tktypes.RandAddress(), td.d.RegistryAddress()))
+
+	td.tp.Functions.InitContract = func(ctx context.Context, icr *prototk.InitContractRequest) (*prototk.InitContractResponse, error) {
+		return nil, fmt.Errorf("pop")
+	}
+
+	_, err := td.dm.querySmartContracts(td.ctx, td.dm.persistence.DB(), query.NewQueryBuilder().Limit(1).Query())
+	assert.Regexp(t, "pop", err)
+}
@@ -24,11 +24,13 @@ import (
 	"github.com/hyperledger/firefly-common/pkg/i18n"
 	"github.com/kaleido-io/paladin/core/internal/components"
 	"github.com/kaleido-io/paladin/core/internal/msgs"
+	"github.com/kaleido-io/paladin/core/internal/tracing"
 	"github.com/kaleido-io/paladin/core/pkg/blockindexer"
 	"github.com/kaleido-io/paladin/toolkit/pkg/log"
 	"github.com/kaleido-io/paladin/toolkit/pkg/pldapi"
 	"github.com/kaleido-io/paladin/toolkit/pkg/prototk"
 	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
+	"go.opentelemetry.io/otel/attribute"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 )
@@ -183,7 +185,14 @@ func (d *domain) batchEventsByAddress(ctx context.Context, tx *gorm.DB, batchID
 	return batches, nil
 }
 
-func (d *domain) handleEventBatch(ctx context.Context, dbTX *gorm.DB, batch *blockindexer.EventDeliveryBatch) (blockindexer.PostCommit, error) {
+func (d *domain) handleEventBatch(ctx context.Context, dbTX *gorm.DB, batch *blockindexer.EventDeliveryBatch) (_ blockindexer.PostCommit, err error) {
+	ctx, span := tracer.Start(ctx, "domain.handle_event_batch")
+	span.SetAttributes(
+		attribute.String("paladin.domain", d.name),
+		attribute.String("paladin.batch_id", batch.BatchID.String()),
+		attribute.Int("paladin.event_count", len(batch.Events)),
+	)
+	defer func() { tracing.EndSpan(span, err) }()
 
 	// First index any domain contract deployments
 	nonDeployEvents, txCompletions, err := d.dm.registrationIndexer(ctx, dbTX, batch)
@@ -231,6 +240,7 @@ func (d *domain) handleEventBatch(ctx context.Context, dbTX *gorm.DB, batch *blo
 		}
 	}
 
+	finalizeCommit := func() {}
 	if len(txCompletions) > 0 {
 		// Ensure we are sorted in block order, as the above processing extracted the array in two
 		// phases (contract deployments, then transactions) so the list will be out of order.
@@ -250,12 +260,13 @@ func (d *domain) handleEventBatch(ctx context.Context, dbTX *gorm.DB, batch *blo
 		// for ALL private transactions (not just those where we're the sender) as there
 		// might be in-memory coordination activities that need to re-process now these
 		// transactions have been finalized.
-		if err := d.dm.txManager.FinalizeTransactions(ctx, dbTX, receipts); err != nil {
+		if finalizeCommit, err = d.dm.txManager.FinalizeTransactions(ctx, dbTX, receipts); err != nil {
 			return nil, err
 		}
 	}
 
 	return func() {
+		finalizeCommit()
 		d.dm.notifyTransactions(txCompletions)
 	}, nil
 }
@@ -269,7 +280,10 @@ func (d *domain) recoverTransactionID(ctx context.Context, txIDString string) (*
 	return &txUUID, nil
 }
 
-func (d *domain) handleEventBatchForContract(ctx context.Context, dbTX *gorm.DB, addr tktypes.EthAddress, batch *pscEventBatch) (*prototk.HandleEventBatchResponse, error) {
+func (d *domain) handleEventBatchForContract(ctx context.Context, dbTX *gorm.DB, addr tktypes.EthAddress, batch *pscEventBatch) (_ *prototk.HandleEventBatchResponse, err error) {
+	ctx, span := tracer.Start(ctx, "domain.handle_contract_events")
+	span.SetAttributes(attribute.String("paladin.contract_address", addr.String()))
+	defer func() { tracing.EndSpan(span, err) }()
 
 	// We have a domain context for queries, but we never flush it to DB - as the only updates
 	// we allow in this function are those performed within our dbTX.
@@ -278,19 +292,23 @@ func (d *domain) handleEventBatchForContract(ctx context.Context, dbTX *gorm.DB,
 
 	batch.StateQueryContext = c.id
 
-	var res *prototk.HandleEventBatchResponse
 	res, err := d.api.HandleEventBatch(ctx, &batch.HandleEventBatchRequest)
 	if err != nil {
 		return nil, err
 	}
 
+	blockNumberFor, err := d.finalizationBlockNumbers(ctx, batch, res)
+	if err != nil {
+		return nil, err
+	}
+
 	stateSpends := make([]*pldapi.StateSpendRecord, len(res.SpentStates))
 	for i, state := range res.SpentStates {
 		txUUID, stateID, err := d.prepareIndexRecord(ctx, state.TransactionId, state.Id)
 		if err != nil {
 			return nil, err
 		}
-		stateSpends[i] = &pldapi.StateSpendRecord{DomainName: d.name, State: stateID, Transaction: txUUID}
+		stateSpends[i] = &pldapi.StateSpendRecord{DomainName: d.name, State: stateID, Transaction: txUUID, BlockNumber: blockNumberFor(txUUID, state.Location)}
 	}
 
 	stateReads := make([]*pldapi.StateReadRecord, len(res.ReadStates))
@@ -299,7 +317,7 @@ func (d *domain) handleEventBatchForContract(ctx context.Context, dbTX *gorm.DB,
 		if err != nil {
 			return nil, err
 		}
-		stateReads[i] = &pldapi.StateReadRecord{DomainName: d.name, State: stateID, Transaction: txUUID}
+		stateReads[i] = &pldapi.StateReadRecord{DomainName: d.name, State: stateID, Transaction: txUUID, BlockNumber: blockNumberFor(txUUID, state.Location)}
 	}
 
 	stateConfirms := make([]*pldapi.StateConfirmRecord, len(res.ConfirmedStates))
@@ -308,7 +326,7 @@ func (d *domain) handleEventBatchForContract(ctx context.Context, dbTX *gorm.DB,
 		if err != nil {
 			return nil, err
 		}
-		stateConfirms[i] = &pldapi.StateConfirmRecord{DomainName: d.name, State: stateID, Transaction: txUUID}
+		stateConfirms[i] = &pldapi.StateConfirmRecord{DomainName: d.name, State: stateID, Transaction: txUUID, BlockNumber: blockNumberFor(txUUID, state.Location)}
 	}
 
 	stateInfoRecords := make([]*pldapi.StateInfoRecord, len(res.InfoStates))
@@ -317,25 +335,83 @@ func (d *domain) handleEventBatchForContract(ctx context.Context, dbTX *gorm.DB,
 		if err != nil {
 			return nil, err
 		}
-		stateInfoRecords[i] = &pldapi.StateInfoRecord{DomainName: d.name, State: stateID, Transaction: txUUID}
+		stateInfoRecords[i] = &pldapi.StateInfoRecord{DomainName: d.name, State: stateID, Transaction: txUUID, BlockNumber: blockNumberFor(txUUID, state.Location)}
+	}
+
+	newStates, newStateConfirms, err := d.prepareNewConfirmedStates(ctx, addr, res.NewStates, blockNumberFor)
+	if err != nil {
+		return nil, err
+	}
+	stateConfirms = append(stateConfirms, newStateConfirms...)
+
+	// Write any new states first
+	if len(newStates) > 0 {
+		// These states are trusted as they come from the domain on our local node (no need to go back round VerifyStateHashes for customer hash functions)
+		if _, err := d.dm.stateStore.WritePreVerifiedStates(ctx, dbTX, d.name, newStates); err != nil {
+			return nil, err
+		}
+	}
+
+	// Then any finalizations of those states
+	if len(stateSpends) > 0 || len(stateReads) > 0 || len(stateConfirms) > 0 {
+		if err := d.dm.stateStore.WriteStateFinalizations(ctx, dbTX, stateSpends, stateReads, stateConfirms, stateInfoRecords); err != nil {
+			return nil, err
+		}
+	}
+	return res, err
+}
+
+// State finalizations are recorded against the block of the event that produced them, so they can be
+// reverted if that block is removed by a chain re-org. The domain should supply the location of the event
+// with each record, and we fall back to the location of the completion of the transaction.
+// If neither is available we use the first block in the batch, so we never revert records for blocks
+// that remain on the chain (as the events for those blocks will not be re-delivered).
+func (d *domain) finalizationBlockNumbers(ctx context.Context, batch *pscEventBatch, res *prototk.HandleEventBatchResponse) (func(uuid.UUID, *prototk.OnChainEventLocation) *int64, error) {
+	var firstBlock *int64
+	for _, ev := range batch.Events {
+		if firstBlock == nil || ev.Location.BlockNumber < *firstBlock {
+			firstBlock = &ev.Location.BlockNumber
+		}
+	}
+	txBlocks := make(map[uuid.UUID]*int64)
+	for _, txCompletionEvent := range res.TransactionsComplete {
+		txID, err := d.recoverTransactionID(ctx, txCompletionEvent.TransactionId)
+		if err != nil {
+			return nil, err
+		}
+		txBlocks[*txID] = &txCompletionEvent.Location.BlockNumber
 	}
+	return func(txID uuid.UUID, location *prototk.OnChainEventLocation) *int64 {
+		if location != nil {
+			return &location.BlockNumber
+		}
+		if blockNumber, ok := txBlocks[txID]; ok {
+			return blockNumber
+		}
+		return firstBlock
+	}, nil
+}
 
-	newStates := make([]*components.StateUpsertOutsideContext, 0)
-	for _, state := range res.NewStates {
+// New states supplied by the domain are confirmed by the transaction they are supplied with
+func (d *domain) prepareNewConfirmedStates(ctx context.Context, addr tktypes.EthAddress, states []*prototk.NewConfirmedState, blockNumberFor func(uuid.UUID, *prototk.OnChainEventLocation) *int64) ([]*components.StateUpsertOutsideContext, []*pldapi.StateConfirmRecord, error) {
+	newStates := make([]*components.StateUpsertOutsideContext, 0, len(states))
+	stateConfirms := make([]*pldapi.StateConfirmRecord, 0, len(states))
+	for _, state := range states {
 		var id tktypes.HexBytes
 		if state.Id != nil {
+			var err error
 			id, err = tktypes.ParseHexBytes(ctx, *state.Id)
 			if err != nil {
-				return nil, i18n.NewError(ctx, msgs.MsgDomainInvalidStateID, *state.Id)
+				return nil, nil, i18n.NewError(ctx, msgs.MsgDomainInvalidStateID, *state.Id)
 			}
 		}
 		txUUID, err := d.recoverTransactionID(ctx, state.TransactionId)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		schemaID, err := tktypes.ParseBytes32(state.SchemaId)
 		if err != nil {
-			return nil, i18n.NewError(ctx, msgs.MsgDomainInvalidSchemaID, state.SchemaId)
+			return nil, nil, i18n.NewError(ctx, msgs.MsgDomainInvalidSchemaID, state.SchemaId)
 		}
 		newStates = append(newStates, &components.StateUpsertOutsideContext{
 			ID:              id,
@@ -343,26 +419,17 @@ func (d *domain) handleEventBatchForContract(ctx context.Context, dbTX *gorm.DB,
 			ContractAddress: addr,
 			Data:            tktypes.RawJSON(state.StateDataJson),
 		})
-
-		// These have implicit confirmations
-		stateConfirms = append(stateConfirms, &pldapi.StateConfirmRecord{DomainName: d.name, State: id, Transaction: *txUUID})
-	}
-
-	// Write any new states first
-	if len(newStates) > 0 {
-		// These states are trusted as they come from the domain on our local node (no need to go back round VerifyStateHashes for customer hash functions)
-		if _, err := d.dm.stateStore.WritePreVerifiedStates(ctx, dbTX, d.name, newStates); err != nil {
-			return nil, err
-		}
+		stateConfirms = append(stateConfirms, &pldapi.StateConfirmRecord{DomainName: d.name, State: id, Transaction: *txUUID, BlockNumber: blockNumberFor(*txUUID, state.Location)})
 	}
+	return newStates, stateConfirms, nil
+}
 
-	// Then any finalizations of those states
-	if len(stateSpends) > 0 || len(stateReads) > 0 || len(stateConfirms) > 0 {
-		if err := d.dm.stateStore.WriteStateFinalizations(ctx, dbTX, stateSpends, stateReads, stateConfirms, stateInfoRecords); err != nil {
-			return nil, err
-		}
+func (d *domain) handleRewind(ctx context.Context, dbTX *gorm.DB, forkBlock int64) (blockindexer.PostCommit, error) {
+	log.L(ctx).Warnf("Rewinding state finalizations for domain %s after block %d", d.name, forkBlock)
+	if err := d.dm.stateStore.RewindStateFinalizations(ctx, dbTX, d.name, forkBlock); err != nil {
+		return nil, err
 	}
-	return res, err
+	return nil, nil
 }
 
 func (d *domain) prepareIndexRecord(ctx context.Context, txIDStr, stateIDStr string) (uuid.UUID, tktypes.HexBytes, error) {
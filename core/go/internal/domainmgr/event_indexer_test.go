@@ -234,14 +234,14 @@ func TestHandleEventBatch(t *testing.T) {
 	td, done := newTestDomain(t, false, goodDomainConf(), mockSchemas(), func(mc *mockComponents) {
 
 		mc.stateStore.On("WriteStateFinalizations", mock.Anything, mock.Anything, []*pldapi.StateSpendRecord{
-			{DomainName: "test1", State: tktypes.MustParseHexBytes(stateSpent), Transaction: txID}, // the SpentStates StateUpdate
+			{DomainName: "test1", State: tktypes.MustParseHexBytes(stateSpent), Transaction: txID, BlockNumber: &event2.BlockNumber}, // the SpentStates StateUpdate
 		}, []*pldapi.StateReadRecord{
-			{DomainName: "test1", State: tktypes.MustParseHexBytes(stateRead), Transaction: txID}, // the ReadStates StateUpdate
+			{DomainName: "test1", State: tktypes.MustParseHexBytes(stateRead), Transaction: txID, BlockNumber: &event2.BlockNumber}, // the ReadStates StateUpdate
 		}, []*pldapi.StateConfirmRecord{
-			{DomainName: "test1", State: tktypes.MustParseHexBytes(stateConfirmed), Transaction: txID}, // the ConfirmedStates StateUpdate
-			{DomainName: "test1", State: tktypes.MustParseHexBytes(fakeHash1), Transaction: txID},      // the implicit confirm from the NewConfirmedState
+			{DomainName: "test1", State: tktypes.MustParseHexBytes(stateConfirmed), Transaction: txID, BlockNumber: &event2.BlockNumber}, // the ConfirmedStates StateUpdate
+			{DomainName: "test1", State: tktypes.MustParseHexBytes(fakeHash1), Transaction: txID, BlockNumber: &event2.BlockNumber},      // the implicit confirm from the NewConfirmedState
 		}, []*pldapi.StateInfoRecord{
-			{DomainName: "test1", State: tktypes.MustParseHexBytes(stateInfo), Transaction: txID}, // the InfoStates StateUpdate
+			{DomainName: "test1", State: tktypes.MustParseHexBytes(stateInfo), Transaction: txID, BlockNumber: &event2.BlockNumber}, // the InfoStates StateUpdate
 		}).Return(nil, nil)
 
 		mc.stateStore.On("WritePreVerifiedStates", mock.Anything, mock.Anything, "test1", []*components.StateUpsertOutsideContext{
@@ -265,7 +265,7 @@ func TestHandleEventBatch(t *testing.T) {
 			assert.Equal(t, expectedEvent.TransactionIndex, r.OnChain.TransactionIndex)
 			assert.Equal(t, expectedEvent.LogIndex, r.OnChain.LogIndex)
 			return true
-		})).Return(nil)
+		})).Return(func() {}, nil)
 
 		mc.privateTxManager.On("PrivateTransactionConfirmed", mock.Anything, mock.Anything).Return()
 	})
@@ -351,7 +351,7 @@ func TestHandleEventBatchFinalizeFail(t *testing.T) {
 	td, done := newTestDomain(t, false, goodDomainConf(), mockSchemas(), func(mc *mockComponents) {
 		mc.db.ExpectExec(`INSERT.*private_smart_contracts`).WillReturnResult(driver.ResultNoRows)
 
-		mc.txManager.On("FinalizeTransactions", mock.Anything, mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+		mc.txManager.On("FinalizeTransactions", mock.Anything, mock.Anything, mock.Anything).Return(nil, fmt.Errorf("pop"))
 	})
 	defer done()
 
@@ -1052,3 +1052,151 @@ func TestReceiptSorting(t *testing.T) {
 		{ReceiptInput: components.ReceiptInput{OnChain: tktypes.OnChainLocation{Type: tktypes.OnChainEvent, BlockNumber: 1100}}},
 	}, receiptList)
 }
+
+func TestFinalizationBlockNumbers(t *testing.T) {
+	td, done := newTestDomain(t, false, goodDomainConf(), mockSchemas())
+	defer done()
+
+	completedTx := uuid.New()
+	otherTx := uuid.New()
+	batch := &pscEventBatch{
+		HandleEventBatchRequest: prototk.HandleEventBatchRequest{
+			Events: []*prototk.OnChainEvent{
+				{Location: &prototk.OnChainEventLocation{BlockNumber: 1001}},
+				{Location: &prototk.OnChainEventLocation{BlockNumber: 1000}},
+				{Location: &prototk.OnChainEventLocation{BlockNumber: 1002}},
+			},
+		},
+	}
+	blockNumberFor, err := td.d.finalizationBlockNumbers(td.ctx, batch, &prototk.HandleEventBatchResponse{
+		TransactionsComplete: []*prototk.CompletedTransaction{
+			{
+				TransactionId: tktypes.Bytes32UUIDFirst16(completedTx).String(),
+				Location:      batch.Events[2].Location,
+			},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1002), *blockNumberFor(completedTx, nil))
+	// The location of the event that produced the record takes precedence
+	assert.Equal(t, int64(1001), *blockNumberFor(completedTx, batch.Events[0].Location))
+	assert.Equal(t, int64(1002), *blockNumberFor(otherTx, batch.Events[2].Location))
+	// Transactions without a completion or location in this batch use the first block
+	assert.Equal(t, int64(1000), *blockNumberFor(otherTx, nil))
+}
+
+func TestHandleEventBatchRewindLaterBlock(t *testing.T) {
+	td, done := newTestDomain(t, true /* real DB */, goodDomainConf())
+	defer done()
+	ctx := td.ctx
+	tp := td.tp
+	dm := td.dm
+	d := td.d
+
+	contractAddr := tktypes.EthAddress(tktypes.RandBytes(20))
+	err := dm.persistence.DB().Transaction(func(tx *gorm.DB) (err error) {
+		_, _, err = dm.registrationIndexer(ctx, tx, &blockindexer.EventDeliveryBatch{
+			StreamID: uuid.New(),
+			BatchID:  uuid.New(),
+			Events: []*pldapi.EventWithData{
+				{
+					SoliditySignature: eventSolSig_PaladinRegisterSmartContract_V0,
+					Address:           (tktypes.EthAddress)(*tp.d.RegistryAddress()),
+					IndexedEvent: &pldapi.IndexedEvent{
+						BlockNumber:     900,
+						TransactionHash: tktypes.NewBytes32FromSlice(tktypes.RandBytes(32)),
+						Signature:       eventSig_PaladinRegisterSmartContract_V0,
+					},
+					Data: tktypes.RawJSON(`{
+						"txId": "` + tktypes.Bytes32UUIDFirst16(uuid.New()).String() + `",
+						"instance": "` + contractAddr.String() + `",
+						"config": "0xfeedbeef"
+					}`),
+				},
+			},
+		})
+		return err
+	})
+	require.NoError(t, err)
+
+	tp.Functions.InitContract = func(ctx context.Context, icr *prototk.InitContractRequest) (*prototk.InitContractResponse, error) {
+		return &prototk.InitContractResponse{Valid: true, ContractConfig: &prototk.ContractConfig{}}, nil
+	}
+
+	// Two in-flight transactions that do not complete in this batch, each spending
+	// a state in the event from a different block
+	tx1 := uuid.New()
+	tx2 := uuid.New()
+	state1 := tktypes.RandHex(32)
+	state2 := tktypes.RandHex(32)
+	newEvent := func(blockNumber int64) *pldapi.EventWithData {
+		return &pldapi.EventWithData{
+			Address: contractAddr,
+			IndexedEvent: &pldapi.IndexedEvent{
+				BlockNumber:     blockNumber,
+				TransactionHash: tktypes.NewBytes32FromSlice(tktypes.RandBytes(32)),
+				Signature:       tktypes.NewBytes32FromSlice(tktypes.RandBytes(32)),
+			},
+			SoliditySignature: "some event signature",
+			Data:              tktypes.RawJSON(`{}`),
+		}
+	}
+	tp.Functions.HandleEventBatch = func(ctx context.Context, req *prototk.HandleEventBatchRequest) (*prototk.HandleEventBatchResponse, error) {
+		require.Len(t, req.Events, 2)
+		return &prototk.HandleEventBatchResponse{
+			SpentStates: []*prototk.StateUpdate{
+				{Id: state1, TransactionId: tktypes.Bytes32UUIDFirst16(tx1).String(), Location: req.Events[0].Location},
+				{Id: state2, TransactionId: tktypes.Bytes32UUIDFirst16(tx2).String(), Location: req.Events[1].Location},
+			},
+		}, nil
+	}
+
+	err = dm.persistence.DB().Transaction(func(tx *gorm.DB) (err error) {
+		_, err = d.handleEventBatch(ctx, tx, &blockindexer.EventDeliveryBatch{
+			BatchID: uuid.New(),
+			Events:  []*pldapi.EventWithData{newEvent(1000), newEvent(1002)},
+		})
+		return err
+	})
+	require.NoError(t, err)
+
+	var records []*pldapi.StateSpendRecord
+	err = dm.persistence.DB().Table("state_spend_records").Order("block_number").Find(&records).Error
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, tx1, records[0].Transaction)
+	assert.Equal(t, int64(1000), *records[0].BlockNumber)
+	assert.Equal(t, tx2, records[1].Transaction)
+	assert.Equal(t, int64(1002), *records[1].BlockNumber)
+
+	// A re-org that forks after block 1001 must remove only the record from the later block
+	err = dm.persistence.DB().Transaction(func(tx *gorm.DB) (err error) {
+		_, err = d.handleRewind(ctx, tx, 1001)
+		return err
+	})
+	require.NoError(t, err)
+
+	records = nil
+	err = dm.persistence.DB().Table("state_spend_records").Find(&records).Error
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, tx1, records[0].Transaction)
+}
+
+func TestHandleRewind(t *testing.T) {
+	td, done := newTestDomain(t, false, goodDomainConf(), mockSchemas(), func(mc *mockComponents) {
+		mc.stateStore.On("RewindStateFinalizations", mock.Anything, mock.Anything, "test1", int64(1000)).Return(nil).Once()
+		mc.stateStore.On("RewindStateFinalizations", mock.Anything, mock.Anything, "test1", int64(1000)).Return(fmt.Errorf("pop"))
+	})
+	defer done()
+
+	mp, err := mockpersistence.NewSQLMockProvider()
+	require.NoError(t, err)
+
+	postCommit, err := td.d.handleRewind(td.ctx, mp.P.DB(), 1000)
+	require.NoError(t, err)
+	assert.Nil(t, postCommit)
+
+	_, err = td.d.handleRewind(td.ctx, mp.P.DB(), 1000)
+	assert.EqualError(t, err, "pop")
+}
@@ -18,6 +18,7 @@ package domainmgr
 import (
 	"context"
 	"encoding/json"
+	"sort"
 	"sync"
 
 	_ "embed"
@@ -27,7 +28,9 @@ import (
 	"github.com/hyperledger/firefly-signer/pkg/abi"
 	"github.com/kaleido-io/paladin/config/pkg/pldconf"
 	"github.com/kaleido-io/paladin/core/internal/components"
+	"github.com/kaleido-io/paladin/core/internal/filters"
 	"github.com/kaleido-io/paladin/core/internal/msgs"
+	"github.com/kaleido-io/paladin/core/internal/tracing"
 	"github.com/kaleido-io/paladin/core/pkg/blockindexer"
 
 	"github.com/kaleido-io/paladin/core/pkg/ethclient"
@@ -35,7 +38,10 @@ import (
 	"github.com/kaleido-io/paladin/toolkit/pkg/cache"
 	"github.com/kaleido-io/paladin/toolkit/pkg/inflight"
 	"github.com/kaleido-io/paladin/toolkit/pkg/log"
+	"github.com/kaleido-io/paladin/toolkit/pkg/pldapi"
 	"github.com/kaleido-io/paladin/toolkit/pkg/plugintk"
+	"github.com/kaleido-io/paladin/toolkit/pkg/query"
+	"github.com/kaleido-io/paladin/toolkit/pkg/rpcserver"
 	"github.com/kaleido-io/paladin/toolkit/pkg/signerapi"
 	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
 	"gorm.io/gorm"
@@ -49,6 +55,8 @@ var iPaladinContractRegistryABI = mustParseEmbeddedBuildABI(iPaladinContractRegi
 var eventSig_PaladinRegisterSmartContract_V0 = mustParseEventSignatureHash(iPaladinContractRegistryABI, "PaladinRegisterSmartContract_V0")
 var eventSolSig_PaladinRegisterSmartContract_V0 = mustParseEventSoliditySignature(iPaladinContractRegistryABI, "PaladinRegisterSmartContract_V0")
 
+var tracer = tracing.Tracer("domainmgr")
+
 // var eventSig_PaladinPrivateTransaction_V0 = mustParseEventSignature(iPaladinContractABI, "PaladinPrivateTransaction_V0")
 
 func NewDomainManager(bgCtx context.Context, conf *pldconf.DomainManagerConfig) components.DomainManager {
@@ -81,6 +89,7 @@ type domainManager struct {
 	keyManager       components.KeyManager
 	ethClientFactory ethclient.EthClientFactory
 	domainSigner     *domainSigner
+	rpcModule        *rpcserver.RPCModule
 
 	domainsByName    map[string]*domain
 	domainsByAddress map[tktypes.EthAddress]*domain
@@ -97,7 +106,10 @@ type event_PaladinRegisterSmartContract_V0 struct {
 }
 
 func (dm *domainManager) PreInit(pic components.PreInitComponents) (*components.ManagerInitResult, error) {
-	return &components.ManagerInitResult{}, nil
+	dm.initRPC()
+	return &components.ManagerInitResult{
+		RPCModules: []*rpcserver.RPCModule{dm.rpcModule},
+	}, nil
 }
 
 func (dm *domainManager) PostInit(c components.AllComponents) error {
@@ -337,6 +349,80 @@ func (dm *domainManager) enrichContractWithDomain(ctx context.Context, contract
 	return d.initSmartContract(ctx, contract)
 }
 
+func (dm *domainManager) listDomains() []*pldapi.Domain {
+	dm.mux.Lock()
+	defer dm.mux.Unlock()
+	domains := make([]*pldapi.Domain, 0, len(dm.domainsByName))
+	for _, d := range dm.domainsByName {
+		domains = append(domains, &pldapi.Domain{
+			Name:            d.name,
+			RegistryAddress: d.RegistryAddress(),
+		})
+	}
+	sort.Slice(domains, func(i, j int) bool { return domains[i].Name < domains[j].Name })
+	return domains
+}
+
+var smartContractFilters = filters.FieldMap{
+	"address":           filters.HexBytesField("address"),
+	"domainAddress":     filters.HexBytesField("domain_address"),
+	"deployTransaction": filters.UUIDField("deploy_tx"),
+}
+
+func (dm *domainManager) querySmartContracts(ctx context.Context, dbTX *gorm.DB, jq *query.QueryJSON) ([]*pldapi.DomainSmartContract, error) {
+	if jq.Limit == nil || *jq.Limit <= 0 {
+		return nil, i18n.NewError(ctx, msgs.MsgDomainQueryLimitRequired)
+	}
+	if len(jq.Sort) == 0 {
+		jq.Sort = []string{"address"}
+	}
+
+	var contracts []*PrivateSmartContract
+	err := filters.BuildGORM(ctx, jq, dbTX.Table("private_smart_contracts").WithContext(ctx), smartContractFilters).
+		Find(&contracts).
+		Error
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*pldapi.DomainSmartContract, len(contracts))
+	for i, psc := range contracts {
+		if results[i], err = dm.buildDomainSmartContract(ctx, psc); err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
+}
+
+func (dm *domainManager) buildDomainSmartContract(ctx context.Context, psc *PrivateSmartContract) (*pldapi.DomainSmartContract, error) {
+	result := &pldapi.DomainSmartContract{
+		DomainAddress:     psc.RegistryAddress,
+		Address:           psc.Address,
+		DeployTransaction: psc.DeployTX,
+	}
+
+	// Use the cached contract if we have one, to avoid a round-trip to the domain to decode the config
+	dc, isCached := dm.contractCache.Get(psc.Address)
+	if !isCached {
+		var err error
+		if _, dc, err = dm.enrichContractWithDomain(ctx, psc); err != nil {
+			return nil, err
+		}
+	}
+	if d := dm.getDomainByAddressOrNil(&psc.RegistryAddress); d != nil {
+		result.DomainName = d.name
+	}
+	if dc != nil {
+		result.Config = &pldapi.DomainContractConfig{
+			ContractConfigJSON:   tktypes.RawJSON(dc.config.ContractConfigJson),
+			CoordinatorSelection: dc.config.CoordinatorSelection.String(),
+			StaticCoordinator:    dc.config.StaticCoordinator,
+			SubmitterSelection:   dc.config.SubmitterSelection.String(),
+		}
+	}
+	return result, nil
+}
+
 // If an embedded ABI is broken, we don't even run the tests / start the runtime
 func mustParseEmbeddedBuildABI(abiJSON []byte) abi.ABI {
 	type buildABI struct {
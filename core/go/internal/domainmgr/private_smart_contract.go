@@ -26,6 +26,8 @@ import (
 	"github.com/hyperledger/firefly-signer/pkg/abi"
 	"github.com/kaleido-io/paladin/core/internal/components"
 	"github.com/kaleido-io/paladin/core/internal/msgs"
+	"github.com/kaleido-io/paladin/core/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
 	"gorm.io/gorm"
 
 	"github.com/kaleido-io/paladin/toolkit/pkg/log"
@@ -135,7 +137,13 @@ func (dc *domainContract) ContractConfig() *prototk.ContractConfig {
 	return dc.config
 }
 
-func (dc *domainContract) InitTransaction(ctx context.Context, tx *components.PrivateTransaction, localTx *components.ResolvedTransaction) error {
+func (dc *domainContract) InitTransaction(ctx context.Context, tx *components.PrivateTransaction, localTx *components.ResolvedTransaction) (err error) {
+	ctx, span := tracer.Start(ctx, "domain.init_transaction")
+	span.SetAttributes(
+		attribute.String("paladin.domain", dc.d.name),
+		attribute.String("paladin.contract_address", dc.info.Address.String()),
+	)
+	defer func() { tracing.EndSpan(span, err) }()
 
 	txSpec, err := dc.buildTransactionSpecification(ctx, localTx, tx.Intent)
 	if err != nil {
@@ -92,6 +92,7 @@ type writer[T Writeable[R], R any] struct {
 	cancelCtx    context.CancelFunc
 	p            persistence.Persistence
 	handler      BatchHandler[T, R]
+	name         string
 	writerId     string
 	batchTimeout time.Duration
 	batchMaxSize int
@@ -110,6 +111,7 @@ type batch[T Writeable[R], R any] struct {
 
 func NewWriter[T Writeable[R], R any](
 	bgCtx context.Context,
+	name string,
 	handler BatchHandler[T, R],
 	p persistence.Persistence,
 	conf *pldconf.FlushWriterConfig,
@@ -120,6 +122,7 @@ func NewWriter[T Writeable[R], R any](
 	batchTimeout := confutil.DurationMin(conf.BatchTimeout, 0, *defaults.BatchTimeout)
 	w := &writer[T, R]{
 		p:            p,
+		name:         name,
 		writerId:     tktypes.ShortID(), // so logs distinguish these writers from any others
 		handler:      handler,
 		workerCount:  workerCount,
@@ -131,7 +134,7 @@ func NewWriter[T Writeable[R], R any](
 }
 
 func (w *writer[T, R]) Start() {
-	log.L(w.bgCtx).Debugf("Starting %d workers for writer %s", w.workerCount, w.writerId)
+	log.L(w.bgCtx).Debugf("Starting %d workers for writer %s (%s)", w.workerCount, w.name, w.writerId)
 	w.workersDone = make([]chan struct{}, w.workerCount)
 	w.workQueues = make([]chan *op[T, R], w.workerCount)
 	for i := 0; i < w.workerCount; i++ {
@@ -269,6 +272,8 @@ func (w *writer[T, R]) runBatch(ctx context.Context, b *batch[T, R]) {
 		keys[i] = op.writeKey
 	}
 	log.L(ctx).Debugf("Writing batch count=%d keys=%v", len(keys), keys)
+	batchSizeHistogram.WithLabelValues(w.name).Observe(float64(len(b.ops)))
+	batchStart := time.Now()
 
 	// We promise to call any registered result callback with the DB Transaction result on all paths.
 	var txErr error
@@ -285,6 +290,11 @@ func (w *writer[T, R]) runBatch(ctx context.Context, b *batch[T, R]) {
 		return err
 	})
 	err := txErr
+	batchResult := "success"
+	if err != nil {
+		batchResult = "fail"
+	}
+	batchDurationHistogram.WithLabelValues(w.name, batchResult).Observe(time.Since(batchStart).Seconds())
 	if err != nil {
 		log.L(ctx).Errorf("Write batch failed: %s", err)
 	} else if len(results) != len(values) {
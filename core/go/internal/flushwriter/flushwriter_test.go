@@ -28,6 +28,8 @@ import (
 	"github.com/kaleido-io/paladin/config/pkg/pldconf"
 
 	"github.com/kaleido-io/paladin/core/pkg/persistence/mockpersistence"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"gorm.io/gorm"
@@ -55,7 +57,7 @@ func newTestWriter(t *testing.T, conf *pldconf.FlushWriterConfig, handler BatchH
 	ctx, cancelCtx := context.WithCancel(context.Background())
 	p, err := mockpersistence.NewSQLMockProvider()
 	require.NoError(t, err)
-	w := NewWriter(ctx, handler, p.P, conf, testDefaults)
+	w := NewWriter(ctx, "test", handler, p.P, conf, testDefaults)
 	w.Start()
 	return ctx, w.(*writer[*testWritable, *testResult]), p.Mock, func() {
 		panicked := recover()
@@ -108,6 +110,8 @@ func TestSuccessfulWriteBatch(t *testing.T) {
 	}
 
 	require.Nil(t, <-dbTXResult)
+
+	assert.Equal(t, 1, testutil.CollectAndCount(batchSizeHistogram.MustCurryWith(prometheus.Labels{"writer": "test"})))
 }
 
 func TestBatchTimeout(t *testing.T) {
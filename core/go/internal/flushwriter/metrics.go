@@ -0,0 +1,50 @@
+/*
+ * Copyright © 2024 Kaleido, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package flushwriter
+
+import (
+	"github.com/kaleido-io/paladin/core/internal/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Writers are created in many places deep within the managers, so the collectors are
+// shared across all writers and labelled by the name of the writer.
+var (
+	batchSizeHistogram = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "flush_writer",
+		Name:      "batch_size",
+		Help:      "Number of operations written in each DB batch",
+		Buckets:   prometheus.ExponentialBuckets(1, 2, 10),
+	}, []string{"writer"})
+	batchDurationHistogram = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "flush_writer",
+		Name:      "batch_duration_seconds",
+		Help:      "Time taken to write each DB batch",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"writer", "result"})
+)
+
+// RegisterMetrics registers the collectors shared by all flush writers
+func RegisterMetrics(r prometheus.Registerer) error {
+	for _, c := range []prometheus.Collector{batchSizeHistogram, batchDurationHistogram} {
+		if err := r.Register(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
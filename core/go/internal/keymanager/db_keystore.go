@@ -0,0 +1,347 @@
+/*
+ * Copyright © 2024 Kaleido, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package keymanager
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/kaleido-io/paladin/config/pkg/pldconf"
+	"github.com/kaleido-io/paladin/core/internal/msgs"
+	"github.com/kaleido-io/paladin/core/pkg/persistence"
+	"github.com/kaleido-io/paladin/toolkit/pkg/cache"
+	"github.com/kaleido-io/paladin/toolkit/pkg/log"
+	"github.com/kaleido-io/paladin/toolkit/pkg/signerapi"
+	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const dbKeyStoreRotationBatchSize = 100
+
+type masterKey struct {
+	id  string
+	aes cipher.AEAD
+}
+
+type dbKeyStoreFactory struct {
+	p      persistence.Persistence
+	wallet string
+}
+
+// The database key store uses envelope encryption. Each key is encrypted with its own random
+// data encryption key (DEK), and the DEK is encrypted with the master key. So rotating the
+// master key only requires the DEKs to be re-encrypted.
+type dbKeyStore struct {
+	p          persistence.Persistence
+	wallet     string
+	cache      cache.Cache[string, []byte]
+	masterKey  *masterKey
+	masterKeys map[string]*masterKey // including previous master keys
+}
+
+func newDBKeyStoreFactory(p persistence.Persistence, wallet string) signerapi.KeyStoreFactory[*signerapi.ConfigNoExt] {
+	return &dbKeyStoreFactory{p: p, wallet: wallet}
+}
+
+func (f *dbKeyStoreFactory) NewKeyStore(ctx context.Context, eConf *signerapi.ConfigNoExt) (signerapi.KeyStore, error) {
+	conf := &eConf.KeyStoreConfig().Database
+
+	ks := &dbKeyStore{
+		p:          f.p,
+		wallet:     f.wallet,
+		cache:      cache.NewCache[string, []byte](&conf.Cache, &pldconf.DatabaseKeyStoreDefaults.Cache),
+		masterKeys: map[string]*masterKey{},
+	}
+
+	var err error
+	ks.masterKey, err = loadMasterKey(ctx, &conf.MasterKey)
+	if err != nil {
+		return nil, err
+	}
+	ks.masterKeys[ks.masterKey.id] = ks.masterKey
+
+	var previousKeys []*masterKey
+	for _, mkConf := range conf.PreviousMasterKeys {
+		mk, err := loadMasterKey(ctx, &mkConf)
+		if err != nil {
+			return nil, err
+		}
+		if ks.masterKeys[mk.id] == nil {
+			ks.masterKeys[mk.id] = mk
+			previousKeys = append(previousKeys, mk)
+		}
+	}
+
+	for _, mk := range previousKeys {
+		if err := ks.rotateMasterKey(ctx, mk); err != nil {
+			return nil, err
+		}
+	}
+	return ks, nil
+}
+
+func loadMasterKey(ctx context.Context, conf *pldconf.MasterKeyConfig) (*masterKey, error) {
+	var keyString string
+	switch {
+	case conf.File != "":
+		b, err := os.ReadFile(conf.File)
+		if err != nil {
+			return nil, i18n.WrapError(ctx, err, msgs.MsgKeyManagerDBKeyStoreMasterKeyLoad, conf.File)
+		}
+		keyString = string(b)
+	case conf.Env != "":
+		keyString = os.Getenv(conf.Env)
+	}
+	if keyString == "" {
+		return nil, i18n.NewError(ctx, msgs.MsgKeyManagerDBKeyStoreNoMasterKey)
+	}
+	keyBytes, err := hex.DecodeString(strings.TrimPrefix(strings.TrimSpace(keyString), "0x"))
+	if err != nil || len(keyBytes) != 32 {
+		return nil, i18n.NewError(ctx, msgs.MsgKeyManagerDBKeyStoreBadMasterKey)
+	}
+	// The ID of the master key is a truncated hash, so we can tell which master key encrypted each entry
+	keyHash := sha256.Sum256(keyBytes)
+	aesGCM, err := newAESGCM(keyBytes)
+	if err != nil {
+		return nil, err
+	}
+	return &masterKey{
+		id:  hex.EncodeToString(keyHash[0:8]),
+		aes: aesGCM,
+	}, nil
+}
+
+func newAESGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// The nonce is prepended to the cipher text, and the wallet and key handle are
+// included as additional data so that entries cannot be swapped in the DB
+func (ks *dbKeyStore) encrypt(aesGCM cipher.AEAD, keyHandle string, plaintext []byte) ([]byte, error) {
+	nonce := make([]byte, aesGCM.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aesGCM.Seal(nonce, nonce, plaintext, []byte(ks.wallet+"/"+keyHandle)), nil
+}
+
+func (ks *dbKeyStore) decrypt(ctx context.Context, aesGCM cipher.AEAD, keyHandle string, ciphertext []byte) ([]byte, error) {
+	nonceSize := aesGCM.NonceSize()
+	if len(ciphertext) < nonceSize {
+		return nil, i18n.NewError(ctx, msgs.MsgKeyManagerDBKeyStoreDecryptFailed, keyHandle, ks.wallet)
+	}
+	plaintext, err := aesGCM.Open(nil, ciphertext[:nonceSize], ciphertext[nonceSize:], []byte(ks.wallet+"/"+keyHandle))
+	if err != nil {
+		return nil, i18n.WrapError(ctx, err, msgs.MsgKeyManagerDBKeyStoreDecryptFailed, keyHandle, ks.wallet)
+	}
+	return plaintext, nil
+}
+
+func (ks *dbKeyStore) unwrapDEK(ctx context.Context, entry *DBKeyStoreEntry) ([]byte, error) {
+	mk := ks.masterKeys[entry.MasterKeyID]
+	if mk == nil {
+		return nil, i18n.NewError(ctx, msgs.MsgKeyManagerDBKeyStoreUnknownMasterKey, entry.KeyHandle, ks.wallet, entry.MasterKeyID)
+	}
+	return ks.decrypt(ctx, mk.aes, entry.KeyHandle, entry.EncryptedDEK)
+}
+
+func (ks *dbKeyStore) decryptEntry(ctx context.Context, entry *DBKeyStoreEntry) ([]byte, error) {
+	dek, err := ks.unwrapDEK(ctx, entry)
+	if err != nil {
+		return nil, err
+	}
+	dekGCM, err := newAESGCM(dek)
+	if err != nil {
+		return nil, err
+	}
+	return ks.decrypt(ctx, dekGCM, entry.KeyHandle, entry.EncryptedKey)
+}
+
+func (ks *dbKeyStore) newEntry(keyHandle string, keyMaterial []byte) (*DBKeyStoreEntry, error) {
+	dek := make([]byte, 32)
+	_, err := rand.Read(dek)
+	var dekGCM cipher.AEAD
+	if err == nil {
+		dekGCM, err = newAESGCM(dek)
+	}
+	var encryptedKey, encryptedDEK []byte
+	if err == nil {
+		encryptedKey, err = ks.encrypt(dekGCM, keyHandle, keyMaterial)
+	}
+	if err == nil {
+		encryptedDEK, err = ks.encrypt(ks.masterKey.aes, keyHandle, dek)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &DBKeyStoreEntry{
+		Wallet:       ks.wallet,
+		KeyHandle:    keyHandle,
+		MasterKeyID:  ks.masterKey.id,
+		EncryptedDEK: encryptedDEK,
+		EncryptedKey: encryptedKey,
+	}, nil
+}
+
+func (ks *dbKeyStore) rotateMasterKey(ctx context.Context, previous *masterKey) error {
+	rotated := 0
+	for {
+		var entries []*DBKeyStoreEntry
+		err := ks.p.DB().
+			WithContext(ctx).
+			Where("wallet = ?", ks.wallet).
+			Where("master_key_id = ?", previous.id).
+			Order("key_handle").
+			Limit(dbKeyStoreRotationBatchSize).
+			Find(&entries).
+			Error
+		if err != nil {
+			return err
+		}
+		if len(entries) == 0 {
+			break
+		}
+		err = ks.p.DB().WithContext(ctx).Transaction(func(dbTX *gorm.DB) error {
+			for _, entry := range entries {
+				dek, err := ks.decrypt(ctx, previous.aes, entry.KeyHandle, entry.EncryptedDEK)
+				if err != nil {
+					return err
+				}
+				encryptedDEK, err := ks.encrypt(ks.masterKey.aes, entry.KeyHandle, dek)
+				if err != nil {
+					return err
+				}
+				err = dbTX.
+					Model(&DBKeyStoreEntry{}).
+					Where("wallet = ?", ks.wallet).
+					Where("key_handle = ?", entry.KeyHandle).
+					Updates(map[string]any{
+						"master_key_id": ks.masterKey.id,
+						"encrypted_dek": tktypes.HexBytes(encryptedDEK),
+					}).
+					Error
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		rotated += len(entries)
+	}
+	log.L(ctx).Infof("Rotated %d keys in wallet '%s' from master key %s to %s", rotated, ks.wallet, previous.id, ks.masterKey.id)
+	return nil
+}
+
+func (ks *dbKeyStore) getEntry(ctx context.Context, keyHandle string) (*DBKeyStoreEntry, error) {
+	var entries []*DBKeyStoreEntry
+	err := ks.p.DB().
+		WithContext(ctx).
+		Where("wallet = ?", ks.wallet).
+		Where("key_handle = ?", keyHandle).
+		Limit(1).
+		Find(&entries).
+		Error
+	if err != nil || len(entries) == 0 {
+		return nil, err
+	}
+	return entries[0], nil
+}
+
+func (ks *dbKeyStore) FindOrCreateLoadableKey(ctx context.Context, req *signerapi.ResolveKeyRequest, newKeyMaterial func() ([]byte, error)) (keyMaterial []byte, keyHandle string, err error) {
+	for _, segment := range req.Path {
+		if len(segment.Name) == 0 {
+			return nil, "", i18n.NewError(ctx, msgs.MsgKeyManagerDBKeyStoreBadKeyHandle, keyHandle)
+		}
+		keyHandle += url.PathEscape(segment.Name)
+		keyHandle += "/"
+	}
+	if len(req.Name) == 0 {
+		return nil, "", i18n.NewError(ctx, msgs.MsgKeyManagerDBKeyStoreBadKeyHandle, keyHandle)
+	}
+	keyHandle += url.PathEscape(req.Name)
+
+	if keyMaterial, _ = ks.cache.Get(keyHandle); keyMaterial != nil {
+		return keyMaterial, keyHandle, nil
+	}
+
+	entry, err := ks.getEntry(ctx, keyHandle)
+	if err == nil && entry == nil {
+		keyMaterial, err = newKeyMaterial()
+		if err == nil {
+			entry, err = ks.newEntry(keyHandle, keyMaterial)
+		}
+		if err == nil {
+			// Another thread might have created the key concurrently, so we do nothing on
+			// conflict and then read back whichever entry was stored.
+			err = ks.p.DB().
+				WithContext(ctx).
+				Clauses(clause.OnConflict{
+					Columns:   []clause.Column{{Name: "wallet"}, {Name: "key_handle"}},
+					DoNothing: true,
+				}).
+				Create(entry).
+				Error
+		}
+		if err == nil {
+			entry, err = ks.getEntry(ctx, keyHandle)
+		}
+	}
+	if err == nil {
+		keyMaterial, err = ks.decryptEntry(ctx, entry)
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	ks.cache.Set(keyHandle, keyMaterial)
+	return keyMaterial, keyHandle, nil
+}
+
+func (ks *dbKeyStore) LoadKeyMaterial(ctx context.Context, keyHandle string) ([]byte, error) {
+	if keyMaterial, _ := ks.cache.Get(keyHandle); keyMaterial != nil {
+		return keyMaterial, nil
+	}
+	entry, err := ks.getEntry(ctx, keyHandle)
+	if err == nil && entry == nil {
+		err = i18n.NewError(ctx, msgs.MsgKeyManagerDBKeyStoreKeyNotFound, keyHandle, ks.wallet)
+	}
+	var keyMaterial []byte
+	if err == nil {
+		keyMaterial, err = ks.decryptEntry(ctx, entry)
+	}
+	if err != nil {
+		return nil, err
+	}
+	ks.cache.Set(keyHandle, keyMaterial)
+	return keyMaterial, nil
+}
+
+func (ks *dbKeyStore) Close() {}
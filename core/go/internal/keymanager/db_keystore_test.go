@@ -0,0 +1,270 @@
+/*
+ * Copyright © 2024 Kaleido, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package keymanager
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/kaleido-io/paladin/config/pkg/pldconf"
+	"github.com/kaleido-io/paladin/toolkit/pkg/algorithms"
+	"github.com/kaleido-io/paladin/toolkit/pkg/signerapi"
+	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
+	"github.com/kaleido-io/paladin/toolkit/pkg/verifiers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func dbKeyStoreConfig(masterKeyEnv string, previousMasterKeyEnvs ...string) *signerapi.ConfigNoExt {
+	conf := &signerapi.ConfigNoExt{
+		KeyStore: pldconf.KeyStoreConfig{
+			Type: pldconf.KeyStoreTypeDatabase,
+			Database: pldconf.DatabaseKeyStoreConfig{
+				MasterKey: pldconf.MasterKeyConfig{Env: masterKeyEnv},
+			},
+		},
+	}
+	for _, env := range previousMasterKeyEnvs {
+		conf.KeyStore.Database.PreviousMasterKeys = append(conf.KeyStore.Database.PreviousMasterKeys, pldconf.MasterKeyConfig{Env: env})
+	}
+	return conf
+}
+
+func TestDBKeyStoreCreateLoadRealDB(t *testing.T) {
+	ctx, km, _, done := newTestDBKeyManagerWithWallets(t)
+	defer done()
+
+	t.Setenv("TEST_MASTER_KEY", tktypes.RandHex(32))
+
+	f := newDBKeyStoreFactory(km.p, "wallet1")
+	ks, err := f.NewKeyStore(ctx, dbKeyStoreConfig("TEST_MASTER_KEY"))
+	require.NoError(t, err)
+	defer ks.Close()
+
+	newKey := tktypes.RandBytes(32)
+	keyMaterial, keyHandle, err := ks.FindOrCreateLoadableKey(ctx, &signerapi.ResolveKeyRequest{
+		Path: []*signerapi.ResolveKeyPathSegment{{Name: "bob"}, {Name: "a/b"}},
+		Name: "key1",
+	}, func() ([]byte, error) { return newKey, nil })
+	require.NoError(t, err)
+	assert.Equal(t, newKey, keyMaterial)
+	assert.Equal(t, "bob/a%2Fb/key1", keyHandle)
+
+	// The key is stored encrypted
+	var entries []*DBKeyStoreEntry
+	err = km.p.DB().Find(&entries).Error
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "wallet1", entries[0].Wallet)
+	assert.NotContains(t, entries[0].EncryptedKey.String(), tktypes.HexBytes(newKey).HexString())
+
+	// Load from the DB after the cache is cleared, or with a new key store
+	ks.(*dbKeyStore).cache.Clear()
+	keyMaterial, err = ks.LoadKeyMaterial(ctx, keyHandle)
+	require.NoError(t, err)
+	assert.Equal(t, newKey, keyMaterial)
+	keyMaterial, err = ks.LoadKeyMaterial(ctx, keyHandle)
+	require.NoError(t, err)
+	assert.Equal(t, newKey, keyMaterial)
+
+	ks2, err := f.NewKeyStore(ctx, dbKeyStoreConfig("TEST_MASTER_KEY"))
+	require.NoError(t, err)
+	keyMaterial, keyHandle2, err := ks2.FindOrCreateLoadableKey(ctx, &signerapi.ResolveKeyRequest{
+		Path: []*signerapi.ResolveKeyPathSegment{{Name: "bob"}, {Name: "a/b"}},
+		Name: "key1",
+	}, func() ([]byte, error) { panic("should not be called") })
+	require.NoError(t, err)
+	assert.Equal(t, newKey, keyMaterial)
+	assert.Equal(t, keyHandle, keyHandle2)
+
+	// Keys are scoped to the wallet
+	_, err = newTestDBKeyStore(t, ctx, newDBKeyStoreFactory(km.p, "wallet2"), "TEST_MASTER_KEY").LoadKeyMaterial(ctx, keyHandle)
+	assert.Regexp(t, "PD010520", err)
+
+	// Tampering with the entry is detected
+	err = km.p.DB().Model(&DBKeyStoreEntry{}).Where("key_handle = ?", keyHandle).Update("wallet", "wallet2").Error
+	require.NoError(t, err)
+	_, err = newTestDBKeyStore(t, ctx, newDBKeyStoreFactory(km.p, "wallet2"), "TEST_MASTER_KEY").LoadKeyMaterial(ctx, keyHandle)
+	assert.Regexp(t, "PD010522", err)
+}
+
+func newTestDBKeyStore(t *testing.T, ctx context.Context, f signerapi.KeyStoreFactory[*signerapi.ConfigNoExt], masterKeyEnv string, previousMasterKeyEnvs ...string) signerapi.KeyStore {
+	ks, err := f.NewKeyStore(ctx, dbKeyStoreConfig(masterKeyEnv, previousMasterKeyEnvs...))
+	require.NoError(t, err)
+	return ks
+}
+
+func TestDBKeyStoreMasterKeyRotationRealDB(t *testing.T) {
+	ctx, km, _, done := newTestDBKeyManagerWithWallets(t)
+	defer done()
+
+	t.Setenv("TEST_MASTER_KEY_1", tktypes.RandHex(32))
+	t.Setenv("TEST_MASTER_KEY_2", "0x"+tktypes.RandHex(32))
+
+	f := newDBKeyStoreFactory(km.p, "wallet1")
+	ks1 := newTestDBKeyStore(t, ctx, f, "TEST_MASTER_KEY_1")
+	keys := map[string][]byte{}
+	for i := 0; i < dbKeyStoreRotationBatchSize+5; i++ {
+		keyMaterial, keyHandle, err := ks1.FindOrCreateLoadableKey(ctx, &signerapi.ResolveKeyRequest{
+			Name: fmt.Sprintf("key%d", i),
+		}, func() ([]byte, error) { return tktypes.RandBytes(32), nil })
+		require.NoError(t, err)
+		keys[keyHandle] = keyMaterial
+	}
+
+	// Start with a new master key, and the old one as a previous key
+	ks2 := newTestDBKeyStore(t, ctx, f, "TEST_MASTER_KEY_2", "TEST_MASTER_KEY_1", "TEST_MASTER_KEY_2")
+	newMasterKeyID := ks2.(*dbKeyStore).masterKey.id
+
+	var entries []*DBKeyStoreEntry
+	err := km.p.DB().Find(&entries).Error
+	require.NoError(t, err)
+	require.Len(t, entries, len(keys))
+	for _, e := range entries {
+		assert.Equal(t, newMasterKeyID, e.MasterKeyID)
+	}
+
+	// Everything can be loaded with just the new master key
+	ks3 := newTestDBKeyStore(t, ctx, f, "TEST_MASTER_KEY_2")
+	for keyHandle, keyMaterial := range keys {
+		loaded, err := ks3.LoadKeyMaterial(ctx, keyHandle)
+		require.NoError(t, err)
+		assert.Equal(t, keyMaterial, loaded)
+	}
+
+	// But not with the old one
+	_, err = newTestDBKeyStore(t, ctx, f, "TEST_MASTER_KEY_1").LoadKeyMaterial(ctx, "key0")
+	assert.Regexp(t, "PD010521", err)
+}
+
+func TestDBKeyStoreBIP32WalletRealDB(t *testing.T) {
+	t.Setenv("TEST_MASTER_KEY", tktypes.RandHex(32))
+
+	walletConf := &pldconf.WalletConfig{
+		Name: "wallet1",
+		Signer: &pldconf.SignerConfig{
+			KeyDerivation: pldconf.KeyDerivationConfig{
+				Type: pldconf.KeyDerivationTypeBIP32,
+			},
+			KeyStore: dbKeyStoreConfig("TEST_MASTER_KEY").KeyStore,
+		},
+	}
+	ctx, km, _, done := newTestDBKeyManagerWithWallets(t, walletConf)
+	defer done()
+
+	resolved, err := km.ResolveKeyNewDatabaseTX(ctx, "bob", algorithms.ECDSA_SECP256K1, verifiers.ETH_ADDRESS)
+	require.NoError(t, err)
+	assert.Equal(t, "m/44'/60'/1'", resolved.KeyHandle)
+
+	// Only the seed is stored
+	var entries []*DBKeyStoreEntry
+	err = km.p.DB().Find(&entries).Error
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "seed", entries[0].KeyHandle)
+
+	// A new wallet on the same DB derives the same key from the stored seed
+	w, err := km.newWallet(ctx, walletConf)
+	require.NoError(t, err)
+	res, err := w.resolveKeyAndVerifier(ctx, resolved.KeyMappingWithPath, algorithms.ECDSA_SECP256K1, verifiers.ETH_ADDRESS)
+	require.NoError(t, err)
+	assert.Equal(t, resolved.Verifier.Verifier, res.Verifier.Verifier)
+}
+
+func TestDBKeyStoreMasterKeyErrors(t *testing.T) {
+	ctx := context.Background()
+	f := newDBKeyStoreFactory(nil, "wallet1")
+
+	_, err := f.NewKeyStore(ctx, dbKeyStoreConfig(""))
+	assert.Regexp(t, "PD010516", err)
+
+	t.Setenv("TEST_MASTER_KEY", "not hex")
+	_, err = f.NewKeyStore(ctx, dbKeyStoreConfig("TEST_MASTER_KEY"))
+	assert.Regexp(t, "PD010517", err)
+
+	t.Setenv("TEST_MASTER_KEY", tktypes.RandHex(16))
+	_, err = f.NewKeyStore(ctx, dbKeyStoreConfig("TEST_MASTER_KEY"))
+	assert.Regexp(t, "PD010517", err)
+
+	conf := dbKeyStoreConfig("")
+	conf.KeyStore.Database.MasterKey.File = path.Join(t.TempDir(), "missing")
+	_, err = f.NewKeyStore(ctx, conf)
+	assert.Regexp(t, "PD010518", err)
+
+	t.Setenv("TEST_MASTER_KEY", tktypes.RandHex(32))
+	t.Setenv("TEST_MASTER_KEY_BAD", "")
+	_, err = f.NewKeyStore(ctx, dbKeyStoreConfig("TEST_MASTER_KEY", "TEST_MASTER_KEY_BAD"))
+	assert.Regexp(t, "PD010516", err)
+
+	keyFile := path.Join(t.TempDir(), "master.key")
+	err = os.WriteFile(keyFile, []byte(tktypes.RandHex(32)+"\n"), 0600)
+	require.NoError(t, err)
+	conf = dbKeyStoreConfig("")
+	conf.KeyStore.Database.MasterKey.File = keyFile
+	_, err = f.NewKeyStore(ctx, conf)
+	require.NoError(t, err)
+}
+
+func TestDBKeyStoreBadKeyHandle(t *testing.T) {
+	ctx := context.Background()
+	t.Setenv("TEST_MASTER_KEY", tktypes.RandHex(32))
+	ks, err := newDBKeyStoreFactory(nil, "wallet1").NewKeyStore(ctx, dbKeyStoreConfig("TEST_MASTER_KEY"))
+	require.NoError(t, err)
+
+	_, _, err = ks.FindOrCreateLoadableKey(ctx, &signerapi.ResolveKeyRequest{
+		Path: []*signerapi.ResolveKeyPathSegment{{Name: ""}},
+		Name: "key1",
+	}, nil)
+	assert.Regexp(t, "PD010519", err)
+
+	_, _, err = ks.FindOrCreateLoadableKey(ctx, &signerapi.ResolveKeyRequest{}, nil)
+	assert.Regexp(t, "PD010519", err)
+}
+
+func TestDBKeyStoreDBErrors(t *testing.T) {
+	ctx, km, mc, done := newTestKeyManager(t, false, &pldconf.KeyManagerConfig{})
+	defer done()
+
+	t.Setenv("TEST_MASTER_KEY_1", tktypes.RandHex(32))
+	t.Setenv("TEST_MASTER_KEY_2", tktypes.RandHex(32))
+	f := newDBKeyStoreFactory(km.p, "wallet1")
+
+	mc.db.ExpectQuery("SELECT.*key_store").WillReturnError(fmt.Errorf("pop"))
+	_, err := f.NewKeyStore(ctx, dbKeyStoreConfig("TEST_MASTER_KEY_2", "TEST_MASTER_KEY_1"))
+	assert.Regexp(t, "pop", err)
+
+	ks, err := f.NewKeyStore(ctx, dbKeyStoreConfig("TEST_MASTER_KEY_1"))
+	require.NoError(t, err)
+
+	mc.db.ExpectQuery("SELECT.*key_store").WillReturnError(fmt.Errorf("pop"))
+	_, err = ks.LoadKeyMaterial(ctx, "key1")
+	assert.Regexp(t, "pop", err)
+
+	mc.db.ExpectQuery("SELECT.*key_store").WillReturnRows(sqlmock.NewRows([]string{}))
+	_, _, err = ks.FindOrCreateLoadableKey(ctx, &signerapi.ResolveKeyRequest{Name: "key1"},
+		func() ([]byte, error) { return nil, fmt.Errorf("pop") })
+	assert.Regexp(t, "pop", err)
+
+	// Entry that cannot be decrypted
+	mc.db.ExpectQuery("SELECT.*key_store").WillReturnRows(sqlmock.NewRows([]string{"wallet", "key_handle", "master_key_id", "encrypted_dek"}).
+		AddRow("wallet1", "key1", ks.(*dbKeyStore).masterKey.id, "0x00"))
+	_, err = ks.LoadKeyMaterial(ctx, "key1")
+	assert.Regexp(t, "PD010522", err)
+}
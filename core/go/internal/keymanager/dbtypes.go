@@ -15,6 +15,8 @@
 
 package keymanager
 
+import "github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
+
 type DBKeyPath struct {
 	Parent string `gorm:"column:parent;primaryKey"`
 	Index  int64  `gorm:"column:index;primaryKey"`
@@ -45,3 +47,16 @@ type DBKeyVerifier struct {
 func (t DBKeyVerifier) TableName() string {
 	return "key_verifiers"
 }
+
+type DBKeyStoreEntry struct {
+	Wallet       string            `gorm:"column:wallet;primaryKey"`
+	KeyHandle    string            `gorm:"column:key_handle;primaryKey"`
+	MasterKeyID  string            `gorm:"column:master_key_id"`
+	EncryptedDEK tktypes.HexBytes  `gorm:"column:encrypted_dek"`
+	EncryptedKey tktypes.HexBytes  `gorm:"column:encrypted_key"`
+	Created      tktypes.Timestamp `gorm:"column:created;autoCreateTime:nano"`
+}
+
+func (t DBKeyStoreEntry) TableName() string {
+	return "key_store"
+}
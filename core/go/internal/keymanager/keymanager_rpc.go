@@ -49,6 +49,9 @@ func (km *keyManager) rpcResolveKey() rpcserver.RPCHandler {
 		algorithm string,
 		verifierType string,
 	) (*pldapi.KeyMappingAndVerifier, error) {
+		if err := rpcserver.AuthorizeKey(ctx, identifier); err != nil {
+			return nil, err
+		}
 		return km.ResolveKeyNewDatabaseTX(ctx, identifier, algorithm, verifierType)
 	})
 }
@@ -57,6 +60,9 @@ func (km *keyManager) rpcResolveEthAddress() rpcserver.RPCHandler {
 	return rpcserver.RPCMethod1(func(ctx context.Context,
 		identifier string,
 	) (*tktypes.EthAddress, error) {
+		if err := rpcserver.AuthorizeKey(ctx, identifier); err != nil {
+			return nil, err
+		}
 		return km.ResolveEthAddressNewDatabaseTX(ctx, identifier)
 	})
 }
@@ -52,16 +52,29 @@ func (km *keyManager) newWallet(ctx context.Context, walletConf *pldconf.WalletC
 	}
 
 	signerType := confutil.StringNotEmpty(&walletConf.SignerType, pldconf.WalletDefaults.SignerType)
-	if signerType != pldconf.WalletSignerTypeEmbedded {
-		// Until we support remoting of the signer API over HTTP/WebSockets etc.
+	switch signerType {
+	case pldconf.WalletSignerTypeEmbedded:
+		w.signingModule, err = signer.NewSigningModule(ctx, (*signerapi.ConfigNoExt)(walletConf.Signer),
+			&signerapi.Extensions[*signerapi.ConfigNoExt]{
+				KeyStoreFactories: map[string]signerapi.KeyStoreFactory[*signerapi.ConfigNoExt]{
+					pldconf.KeyStoreTypeDatabase: newDBKeyStoreFactory(km.p, w.name),
+				},
+			})
+		if err != nil {
+			return nil, i18n.WrapError(ctx, err, msgs.MsgKeyManagerEmbeddedSignerFailInit, w.name)
+		}
+	case pldconf.WalletSignerTypeRemote:
+		if walletConf.Remote == nil {
+			return nil, i18n.NewError(ctx, msgs.MsgKeyManagerRemoteSignerFailInit, w.name)
+		}
+		w.signingModule, err = signer.NewRemoteSigningModule(ctx, walletConf.Remote)
+		if err != nil {
+			return nil, i18n.WrapError(ctx, err, msgs.MsgKeyManagerRemoteSignerFailInit, w.name)
+		}
+	default:
 		return nil, i18n.NewError(ctx, msgs.MsgKeyManagerInvalidWalletSignerType, signerType, w.name)
 	}
 
-	w.signingModule, err = signer.NewSigningModule(ctx, (*signerapi.ConfigNoExt)(walletConf.Signer))
-	if err != nil {
-		return nil, i18n.WrapError(ctx, err, msgs.MsgKeyManagerEmbeddedSignerFailInit, w.name)
-	}
-
 	return w, nil
 
 }
@@ -16,14 +16,19 @@
 package keymanager
 
 import (
+	"context"
 	"fmt"
 	"testing"
 
+	"github.com/hyperledger/firefly-signer/pkg/secp256k1"
+	"github.com/kaleido-io/paladin/config/pkg/confutil"
 	"github.com/kaleido-io/paladin/config/pkg/pldconf"
 	"github.com/kaleido-io/paladin/core/mocks/signermocks"
 	"github.com/kaleido-io/paladin/toolkit/pkg/algorithms"
 	"github.com/kaleido-io/paladin/toolkit/pkg/pldapi"
+	"github.com/kaleido-io/paladin/toolkit/pkg/signer"
 	"github.com/kaleido-io/paladin/toolkit/pkg/signerapi"
+	"github.com/kaleido-io/paladin/toolkit/pkg/signpayloads"
 	"github.com/kaleido-io/paladin/toolkit/pkg/verifiers"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -56,6 +61,21 @@ func TestNewWalletConfigErrors(t *testing.T) {
 	})
 	assert.Regexp(t, "PD010507", err)
 
+	_, err = km.newWallet(ctx, &pldconf.WalletConfig{
+		Name:       "wallet1",
+		SignerType: pldconf.WalletSignerTypeRemote,
+	})
+	assert.Regexp(t, "PD010515", err)
+
+	_, err = km.newWallet(ctx, &pldconf.WalletConfig{
+		Name:       "wallet1",
+		SignerType: pldconf.WalletSignerTypeRemote,
+		Remote: &pldconf.RemoteSignerConfig{
+			HTTPClientConfig: pldconf.HTTPClientConfig{URL: "wrong://"},
+		},
+	})
+	assert.Regexp(t, "PD010515.*PD020501", err)
+
 	_, err = km.selectWallet(ctx, "anything")
 	assert.Regexp(t, "PD010501", err)
 }
@@ -129,3 +149,42 @@ func TestSignError(t *testing.T) {
 	}, "any", []byte("payload"))
 	assert.Regexp(t, "pop", err)
 }
+
+func TestRemoteWalletResolveAndSign(t *testing.T) {
+
+	ctx := context.Background()
+	sm, err := signer.NewSigningModule(ctx, (*signerapi.ConfigNoExt)(hdWalletConfig("remote1", "").Signer))
+	require.NoError(t, err)
+	server, err := signer.NewRemoteSigningServer(ctx, &pldconf.HTTPServerConfig{
+		Address: confutil.P("127.0.0.1"),
+		Port:    confutil.P(0),
+	}, sm)
+	require.NoError(t, err)
+	err = server.Start()
+	require.NoError(t, err)
+	defer server.Stop()
+
+	ctx, km, _, done := newTestDBKeyManagerWithWallets(t, &pldconf.WalletConfig{
+		Name:       "remote1",
+		SignerType: pldconf.WalletSignerTypeRemote,
+		Remote: &pldconf.RemoteSignerConfig{
+			HTTPClientConfig: pldconf.HTTPClientConfig{
+				URL: fmt.Sprintf("http://%s", server.Addr()),
+			},
+		},
+	})
+	defer done()
+
+	resolved, err := km.ResolveKeyNewDatabaseTX(ctx, "bob.keys.blue.42", algorithms.ECDSA_SECP256K1, verifiers.ETH_ADDRESS)
+	require.NoError(t, err)
+	assert.Equal(t, "m/44'/60'/1'/0/0/0", resolved.KeyHandle)
+
+	payload := []byte("some data")
+	signature, err := km.Sign(ctx, resolved, signpayloads.OPAQUE_TO_RSV, payload)
+	require.NoError(t, err)
+	sig, err := secp256k1.DecodeCompactRSV(ctx, signature)
+	require.NoError(t, err)
+	addr, err := sig.RecoverDirect(payload, 0)
+	require.NoError(t, err)
+	assert.Equal(t, addr.String(), resolved.Verifier.Verifier)
+}
@@ -0,0 +1,58 @@
+/*
+ * Copyright © 2024 Kaleido, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package metrics
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/kaleido-io/paladin/toolkit/pkg/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Namespace is the common prefix for all metrics exported by a Paladin node
+const Namespace = "paladin"
+
+// Metrics holds the Prometheus registry for the node. Each manager registers its own
+// collectors during PreInit, and the registry is served on the debug server.
+type Metrics interface {
+	Registry() prometheus.Registerer
+	HTTPHandler() http.Handler
+}
+
+type metricsManager struct {
+	registry *prometheus.Registry
+}
+
+func NewMetricsManager(ctx context.Context) Metrics {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	log.L(ctx).Debugf("Metrics registry initialized")
+	return &metricsManager{registry: registry}
+}
+
+func (mm *metricsManager) Registry() prometheus.Registerer {
+	return mm.registry
+}
+
+func (mm *metricsManager) HTTPHandler() http.Handler {
+	return promhttp.HandlerFor(mm.registry, promhttp.HandlerOpts{})
+}
@@ -74,6 +74,8 @@ var (
 	MsgComponentAdditionalMgrInitError     = ffe("PD010031", "Error initializing %s manager")
 	MsgComponentAdditionalMgrStartError    = ffe("PD010032", "Error initializing %s manager")
 	MsgComponentDebugServerStartError      = ffe("PD010033", "Error starting debug server")
+	MsgComponentMetricsInitError           = ffe("PD010034", "Error initializing metrics")
+	MsgComponentTracingInitError           = ffe("PD010035", "Error initializing tracing")
 
 	// States PD0101XX
 	MsgStateInvalidLength             = ffe("PD010101", "Invalid hash len expected=%d actual=%d")
@@ -147,6 +149,14 @@ var (
 	MsgKeyManagerIdentifierPathNotFound     = ffe("PD010512", "Identifier path segment '%s' not found in database")
 	MsgKeyManagerExistingIdentifierNotFound = ffe("PD010513", "Identifier '%s' not found in database")
 	MsgKeyManagerMissingDatabaseTxn         = ffe("PD010514", "Missing database transaction context")
+	MsgKeyManagerRemoteSignerFailInit       = ffe("PD010515", "Initialization of remote signer for wallet '%s' failed")
+	MsgKeyManagerDBKeyStoreNoMasterKey      = ffe("PD010516", "Master key for database key store must be loaded from a file or environment variable")
+	MsgKeyManagerDBKeyStoreBadMasterKey     = ffe("PD010517", "Master key for database key store must be a hex encoded 32 byte value")
+	MsgKeyManagerDBKeyStoreMasterKeyLoad    = ffe("PD010518", "Failed to load master key for database key store from file '%s'")
+	MsgKeyManagerDBKeyStoreBadKeyHandle     = ffe("PD010519", "Invalid key handle for database key store: '%s'")
+	MsgKeyManagerDBKeyStoreKeyNotFound      = ffe("PD010520", "Key '%s' not found in database key store for wallet '%s'")
+	MsgKeyManagerDBKeyStoreUnknownMasterKey = ffe("PD010521", "Key '%s' in wallet '%s' is encrypted with master key '%s' which is not configured")
+	MsgKeyManagerDBKeyStoreDecryptFailed    = ffe("PD010522", "Failed to decrypt key '%s' in wallet '%s'")
 
 	// Comms bus PD0106XX
 	MsgDestinationNotFound     = ffe("PD010600", "Destination not found: %s")
@@ -197,6 +207,10 @@ var (
 	MsgBlockIndexerTransactionReverted      = ffe("PD011309", "Transaction reverted: %s")
 	MsgBlockIndexerConfirmedBlockNotFound   = ffe("PD011310", "Block %s (%d) not found on retrieval after detection and requested number of confirmations")
 	MsgBlockIndexerLimitRequired            = ffe("PD011311", "limit is required on all queries")
+	MsgBlockIndexerESNotFound               = ffe("PD011312", "Event stream '%s' not found")
+	MsgBlockIndexerESExists                 = ffe("PD011313", "Event stream '%s' already exists")
+	MsgBlockIndexerESNoEvents               = ffe("PD011314", "At least one event must be supplied in the ABI of the event stream sources")
+	MsgBlockIndexerReorgTooDeep             = ffe("PD011315", "Chain re-org is deeper than the maximum re-org depth of %d blocks (indexed blocks from %d onwards are no longer on the canonical chain)")
 
 	// EthClient module PD0115XX
 	MsgEthClientInvalidInput            = ffe("PD011500", "Unable to convert to ABI function input (func=%s)")
@@ -278,6 +292,11 @@ var (
 	MsgDomainNullifierForPartyOutsideDistro   = ffe("PD011660", "A nullifier was requested for a party that is not in the distribution list")
 	MsgDomainInvalidFromAddress               = ffe("PD011661", "Invalid from identity in transaction")
 	MsgDomainInvalidCoordinatorSelection      = ffe("PD011662", "Invalid coordinator selection of '%s' configured. valid options are: COORDINATOR_SENDER, COORDINATOR_STATIC, COORDINATOR_ENDORSER")
+	MsgDomainQueryEventsLimitRequired         = ffe("PD011663", "A limit greater than zero must be supplied to query contract events")
+	MsgDomainInvalidRPCMethod                 = ffe("PD011664", "Domain RPC method name '%s' is invalid")
+	MsgDomainRPCContractAddressRequired       = ffe("PD011665", "The first parameter of method %s must be the address of a private smart contract")
+	MsgDomainContractNotInDomain              = ffe("PD011666", "Smart contract %s does not belong to domain '%s'")
+	MsgDomainQueryLimitRequired               = ffe("PD011667", "A limit greater than zero must be supplied to query smart contracts")
 
 	// Entrypoint PD0117XX
 	MsgEntrypointUnknownRunMode = ffe("PD011700", "Unknown run mode '%s'")
@@ -322,6 +341,12 @@ var (
 	MsgPrivateTxMgrFunctionNotProvided           = ffe("PD011836", "Function abi not provided in transaction input")
 	MsgPrivateTxMgrAssembleRequestInvalid        = ffe("PD011837", "Assemble request is invalid for transaction %s")
 	MsgPrivateTxMgrAssembleTxnNotFound           = ffe("PD011838", "Transaction %s not found in local node")
+	MsgPrivateTxMgrSequencerNotActive            = ffe("PD011839", "No active sequencer for contract %s")
+	MsgPrivateTxMgrTxNotInFlight                 = ffe("PD011840", "Transaction %s is not in flight in the sequencer for contract %s")
+	MsgPrivateTxMgrTxAlreadyDispatched           = ffe("PD011841", "Transaction %s has already been dispatched to the base ledger")
+	MsgPrivateTxMgrAdminReasonRequired           = ffe("PD011842", "A reason must be supplied")
+	MsgPrivateTxMgrFailedByAdmin                 = ffe("PD011843", "Transaction failed by administrator: %s")
+	MsgPrivateTxMgrSequencerEvicted              = ffe("PD011844", "Sequencer evicted by administrator: %s")
 
 	// Public Transaction Manager PD0119XX
 	MsgInsufficientBalance             = ffe("PD011900", "Balance %s of fueling source address %s is below the required amount %s")
@@ -352,6 +377,10 @@ var (
 	MsgInvalidAutoFuelSource           = ffe("PD011934", "Invalid auto-fueling source '%s'")
 	MsgInvalidStateMissingTXHash       = ffe("PD011935", "Invalid state - missing transaction hash from previous sign stage")
 	MsgInvalidTXMissingFromAddr        = ffe("PD011936", "From address missing for transaction")
+	MsgPublicTxNotFoundForNonce        = ffe("PD011937", "Public transaction not found for %s:%d")
+	MsgPublicTxAlreadyCompleted        = ffe("PD011938", "Public transaction %s:%d has already completed")
+	MsgPublicTxNotInFlight             = ffe("PD011939", "Public transaction %s:%d has been submitted, but is not currently in-flight. Retry once it has been loaded for processing")
+	MsgPublicTxReplacementGasPriceMax  = ffe("PD011940", "Replacing public transaction %s:%d requires a gas price of at least %s, which exceeds the configured maximum gas price %s")
 
 	// TransportManager module PD0120XX
 	MsgTransportInvalidMessage                = ffe("PD012000", "Invalid message")
@@ -367,6 +396,11 @@ var (
 	MsgTransportClientAlreadyRegistered       = ffe("PD012010", "Client '%s' already registered")
 	MsgTransportDestinationNotFound           = ffe("PD012011", "Destination '%s' not found")
 	MsgTransportClientRegisterAfterStartup    = ffe("PD012012", "Client '%s' attempted registration after startup")
+	MsgTransportUnknownControlMessage         = ffe("PD012013", "Unsupported transport manager message type '%s'")
+	MsgTransportInvalidPeerInfo               = ffe("PD012014", "Transport '%s' returned invalid JSON peer information")
+	MsgTransportReliableNotSupported          = ffe("PD012015", "Destination '%s' does not support reliable messages")
+	MsgTransportSenderNotAuthenticated        = ffe("PD012016", "Reliable message '%s' was not received from a node authenticated by the transport")
+	MsgTransportSenderMismatch                = ffe("PD012017", "Message from node '%s' was received from authenticated node '%s'")
 
 	// RegistryManager module PD0121XX
 	MsgRegistryNodeEntiresNotFound     = ffe("PD012100", "No entries found for node '%s'")
@@ -379,6 +413,10 @@ var (
 	MsgRegistryQueryLimitRequired      = ffe("PD012107", "Limit is required on all queries")
 	MsgRegistryTransportPropertyRegexp = ffe("PD012108", "transports.propertyRegexp for registry '%s' is invalid")
 	MsgRegistryDollarPrefixReserved    = ffe("PD012109", "Name '%s' is invalid. Dollar ('$') prefix is allowed only for reserved properties, and then is required (pluginReserved=%t)")
+	MsgRegistryTxInvalidContract       = ffe("PD012110", "Transaction requested by registry '%s' has an invalid contract address '%s'")
+	MsgRegistryTxInvalidFunctionABI    = ffe("PD012111", "Transaction requested by registry '%s' has an invalid function ABI")
+	MsgRegistryInvalidTransactionID    = ffe("PD012112", "Registry '%s' returned an invalid transaction ID '%s'")
+	MsgRegistryTxContractNotAllowed    = ffe("PD012113", "Registry '%s' cannot submit a transaction to '%s' as it is not one of the registry's contract addresses")
 
 	// TxMgr module PD0122XX
 	MsgTxMgrQueryLimitRequired           = ffe("PD012200", "limit is required on all queries")
@@ -411,6 +449,9 @@ var (
 	MsgTxMgrPublicSenderNotValidLocal    = ffe("PD012230", "The from identity '%s' must be a valid identity local to the node")
 	MsgTxMgrDomainMismatch               = ffe("PD012231", "The domain '%s' specified on the transaction does not match the domain '%s' for contract %s")
 	MsgTxMgrDomainMissingForDeploy       = ffe("PD012232", "A domain must be specified for a private smart contract deployment transaction")
+	MsgTxMgrReceiptListenerNotFound      = ffe("PD012233", "Receipt listener '%s' not found")
+	MsgTxMgrReceiptListenerExists        = ffe("PD012234", "Receipt listener '%s' already exists")
+	MsgTxMgrReceiptListenerDomainFilter  = ffe("PD012235", "Receipt listener domain filter can only be used with private transactions")
 
 	// FlushWriter module PD0123XX
 	MsgFlushWriterQuiescing      = ffe("PD012300", "Writer shutting down")
@@ -420,4 +461,9 @@ var (
 	// State distributor PD0124XX
 	MsgStateDistributorNullifierNotLocal = ffe("PD012400", "Request to generate a nullifier with an identity that is not fully qualified for the local node")
 	MsgStateDistributorNullifierFail     = ffe("PD012401", "Failed to generate nullifier for state %s")
+
+	// Tracing PD0125XX
+	MsgTracingInvalidExporter = ffe("PD012500", "Invalid tracing exporter '%s'")
+	MsgTracingFileOpenFailed  = ffe("PD012501", "Failed to open trace file '%s'")
+	MsgTracingExporterInit    = ffe("PD012502", "Failed to initialize tracing exporter")
 )
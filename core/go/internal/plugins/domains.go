@@ -98,6 +98,15 @@ func (br *domainBridge) RequestReply(ctx context.Context, reqMsg plugintk.Plugin
 				}
 			},
 		)
+	case *prototk.DomainMessage_QueryContractEvents:
+		return callManagerImpl(ctx, req.QueryContractEvents,
+			br.manager.QueryContractEvents,
+			func(resMsg *prototk.DomainMessage, res *prototk.QueryContractEventsResponse) {
+				resMsg.ResponseToDomain = &prototk.DomainMessage_QueryContractEventsRes{
+					QueryContractEventsRes: res,
+				}
+			},
+		)
 	default:
 		return nil, i18n.NewError(ctx, msgs.MsgPluginBadRequestBody, req)
 	}
@@ -342,3 +351,18 @@ func (br *domainBridge) BuildReceipt(ctx context.Context, req *prototk.BuildRece
 	)
 	return
 }
+
+func (br *domainBridge) HandleRPC(ctx context.Context, req *prototk.HandleRPCRequest) (res *prototk.HandleRPCResponse, err error) {
+	err = br.toPlugin.RequestReply(ctx,
+		func(dm plugintk.PluginMessage[prototk.DomainMessage]) {
+			dm.Message().RequestToDomain = &prototk.DomainMessage_HandleRpc{HandleRpc: req}
+		},
+		func(dm plugintk.PluginMessage[prototk.DomainMessage]) bool {
+			if r, ok := dm.Message().ResponseFromDomain.(*prototk.DomainMessage_HandleRpcRes); ok {
+				res = r.HandleRpcRes
+			}
+			return res != nil
+		},
+	)
+	return
+}
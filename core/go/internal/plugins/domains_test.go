@@ -44,6 +44,7 @@ type testDomainManager struct {
 	encodeData          func(context.Context, *prototk.EncodeDataRequest) (*prototk.EncodeDataResponse, error)
 	decodeData          func(context.Context, *prototk.DecodeDataRequest) (*prototk.DecodeDataResponse, error)
 	recoverSigner       func(context.Context, *prototk.RecoverSignerRequest) (*prototk.RecoverSignerResponse, error)
+	queryContractEvents func(context.Context, *prototk.QueryContractEventsRequest) (*prototk.QueryContractEventsResponse, error)
 }
 
 func (tp *testDomainManager) FindAvailableStates(ctx context.Context, req *prototk.FindAvailableStatesRequest) (*prototk.FindAvailableStatesResponse, error) {
@@ -62,6 +63,10 @@ func (tp *testDomainManager) RecoverSigner(ctx context.Context, req *prototk.Rec
 	return tp.recoverSigner(ctx, req)
 }
 
+func (tp *testDomainManager) QueryContractEvents(ctx context.Context, req *prototk.QueryContractEventsRequest) (*prototk.QueryContractEventsResponse, error) {
+	return tp.queryContractEvents(ctx, req)
+}
+
 func domainConnectFactory(ctx context.Context, client prototk.PluginControllerClient) (grpc.BidiStreamingClient[prototk.DomainMessage, prototk.DomainMessage], error) {
 	return client.ConnectDomain(context.Background())
 }
@@ -229,6 +234,12 @@ func TestDomainRequestsOK(t *testing.T) {
 				ReceiptJson: `{"receipt":"data"}`,
 			}, nil
 		},
+		HandleRPC: func(ctx context.Context, hrr *prototk.HandleRPCRequest) (*prototk.HandleRPCResponse, error) {
+			assert.Equal(t, "method1", hrr.Method)
+			return &prototk.HandleRPCResponse{
+				ResultJson: `{"rpc":"result"}`,
+			}, nil
+		},
 	}
 
 	tdm := &testDomainManager{
@@ -275,6 +286,13 @@ func TestDomainRequestsOK(t *testing.T) {
 		}, nil
 	}
 
+	tdm.queryContractEvents = func(ctx context.Context, qcr *prototk.QueryContractEventsRequest) (*prototk.QueryContractEventsResponse, error) {
+		assert.Equal(t, "context1", qcr.StateQueryContext)
+		return &prototk.QueryContractEventsResponse{
+			Events: []*prototk.OnChainEvent{{Signature: "some signature"}},
+		}, nil
+	}
+
 	ctx, pc, done := newTestDomainPluginManager(t, &testManagers{
 		testDomainManager: tdm,
 	})
@@ -392,6 +410,12 @@ func TestDomainRequestsOK(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, `{"receipt":"data"}`, brr.ReceiptJson)
 
+	hrr, err := domainAPI.HandleRPC(ctx, &prototk.HandleRPCRequest{
+		Method: "method1",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, `{"rpc":"result"}`, hrr.ResultJson)
+
 	callbacks := <-waitForCallbacks
 
 	fas, err := callbacks.FindAvailableStates(ctx, &prototk.FindAvailableStatesRequest{
@@ -417,6 +441,12 @@ func TestDomainRequestsOK(t *testing.T) {
 	})
 	require.NoError(t, err)
 	assert.Equal(t, "some verifier", string(rsr.Verifier))
+
+	qcr, err := callbacks.QueryContractEvents(ctx, &prototk.QueryContractEventsRequest{
+		StateQueryContext: "context1",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "some signature", qcr.Events[0].Signature)
 }
 
 func TestDomainRegisterFail(t *testing.T) {
@@ -0,0 +1,66 @@
+/*
+ * Copyright © 2024 Kaleido, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package plugins
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kaleido-io/paladin/core/internal/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type pluginMetrics struct {
+	requestDuration *prometheus.HistogramVec
+}
+
+func newPluginMetrics() *pluginMetrics {
+	return &pluginMetrics{
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: "plugin",
+			Name:      "request_duration_seconds",
+			Help:      "Latency of requests sent over gRPC from Paladin to plugins",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"type", "plugin", "request", "result"}),
+	}
+}
+
+func (m *pluginMetrics) register(r prometheus.Registerer) error {
+	return r.Register(m.requestDuration)
+}
+
+func (m *pluginMetrics) recordRequest(pluginType, pluginName, requestType string, startTime time.Time, err error) {
+	result := "success"
+	if err != nil {
+		result = "error"
+	}
+	m.requestDuration.WithLabelValues(pluginType, pluginName, requestType, result).Observe(time.Since(startTime).Seconds())
+}
+
+// The request types are the protobuf oneof wrappers, such as "*prototk.DomainMessage_ConfigureDomain",
+// from which we use the part after the message name (ConfigureDomain) as the label
+func pluginRequestType(req any) string {
+	typeName := fmt.Sprintf("%T", req)
+	if i := strings.LastIndex(typeName, "."); i >= 0 {
+		typeName = typeName[i+1:]
+	}
+	if _, requestType, ok := strings.Cut(typeName, "_"); ok {
+		return requestType
+	}
+	return typeName
+}
@@ -0,0 +1,48 @@
+/*
+ * Copyright © 2024 Kaleido, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package plugins
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/kaleido-io/paladin/toolkit/pkg/prototk"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPluginRequestType(t *testing.T) {
+	assert.Equal(t, "ConfigureDomain", pluginRequestType(&prototk.DomainMessage_ConfigureDomain{}))
+	assert.Equal(t, "SendMessage", pluginRequestType(&prototk.TransportMessage_SendMessage{}))
+	assert.Equal(t, "string", pluginRequestType("any"))
+}
+
+func TestPluginMetrics(t *testing.T) {
+	m := newPluginMetrics()
+	r := prometheus.NewRegistry()
+	err := m.register(r)
+	require.NoError(t, err)
+
+	m.recordRequest("DOMAIN", "domain1", "ConfigureDomain", time.Now(), nil)
+	m.recordRequest("DOMAIN", "domain1", "ConfigureDomain", time.Now(), fmt.Errorf("pop"))
+	assert.Equal(t, 2, testutil.CollectAndCount(m.requestDuration))
+
+	err = m.register(r)
+	assert.Error(t, err)
+}
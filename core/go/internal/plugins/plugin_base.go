@@ -268,7 +268,7 @@ func (ph *pluginHandler[M]) handleRequestFromPlugin(ctx context.Context, pi *plu
 	ph.send(res)
 }
 
-func (ph *pluginHandler[M]) RequestReply(ctx context.Context, reqFn func(plugintk.PluginMessage[M]), resFn func(plugintk.PluginMessage[M]) (ok bool)) error {
+func (ph *pluginHandler[M]) RequestReply(ctx context.Context, reqFn func(plugintk.PluginMessage[M]), resFn func(plugintk.PluginMessage[M]) (ok bool)) (err error) {
 	// Log under our context so we get the plugin ID
 	reqID := uuid.New()
 	l := log.L(ph.ctx)
@@ -286,6 +286,12 @@ func (ph *pluginHandler[M]) RequestReply(ctx context.Context, reqFn func(plugint
 	header.MessageType = prototk.Header_REQUEST_TO_PLUGIN
 	header.ErrorMessage = nil
 
+	startTime := time.Now()
+	requestType := pluginRequestType(req.RequestToPlugin())
+	defer func() {
+		ph.pc.metrics.recordRequest(pi.pluginType, pi.name, requestType, startTime, err)
+	}()
+
 	// Create the in-flight record - under the request context (inflight manager will be cancelled if we end)
 	inflight := ph.inflight.AddInflight(ctx, reqID)
 	defer inflight.Cancel()
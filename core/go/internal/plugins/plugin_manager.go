@@ -69,6 +69,8 @@ type pluginManager struct {
 	pluginLoaderDone     chan struct{}
 	loadingProgressed    chan *prototk.PluginLoadFailed
 	serverDone           chan error
+
+	metrics *pluginMetrics
 }
 
 func NewPluginManager(bgCtx context.Context,
@@ -91,6 +93,8 @@ func NewPluginManager(bgCtx context.Context,
 		notifyPluginsUpdated: make(chan bool, 1),
 		notifySystemCommand:  make(chan prototk.PluginLoad_SysCommand, 1),
 		loadingProgressed:    make(chan *prototk.PluginLoadFailed, 1),
+
+		metrics: newPluginMetrics(),
 	}
 	return pc
 }
@@ -131,6 +135,9 @@ func (pm *pluginManager) parseGRPCAddress(ctx context.Context, serverAddr string
 }
 
 func (pm *pluginManager) PreInit(pic components.PreInitComponents) (*components.ManagerInitResult, error) {
+	if err := pm.metrics.register(pic.MetricsManager().Registry()); err != nil {
+		return nil, err
+	}
 	return &components.ManagerInitResult{}, nil
 }
 
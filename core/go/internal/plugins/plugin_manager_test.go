@@ -23,6 +23,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/kaleido-io/paladin/config/pkg/confutil"
 	"github.com/kaleido-io/paladin/config/pkg/pldconf"
+	"github.com/kaleido-io/paladin/core/internal/metrics"
 	"github.com/kaleido-io/paladin/core/mocks/componentmocks"
 
 	"github.com/kaleido-io/paladin/toolkit/pkg/plugintk"
@@ -79,6 +80,7 @@ func (tm *testManagers) componentMocks(t *testing.T) *componentmocks.AllComponen
 		tm.testRegistryManager = &testRegistryManager{}
 	}
 	mc.On("RegistryManager").Return(tm.testRegistryManager.mock(t)).Maybe()
+	mc.On("MetricsManager").Return(metrics.NewMetricsManager(context.Background())).Maybe()
 	return mc
 }
 
@@ -116,6 +118,16 @@ func newTestPluginManager(t *testing.T, setup *testManagers) *pluginManager {
 	return pc.(*pluginManager)
 }
 
+func TestPreInitMetricsError(t *testing.T) {
+	mc := (&testManagers{}).componentMocks(t)
+	err := newPluginMetrics().register(mc.MetricsManager().Registry())
+	require.NoError(t, err)
+
+	pc := NewPluginManager(context.Background(), tempUDS(t), uuid.New(), &pldconf.PluginManagerConfig{})
+	_, err = pc.PreInit(mc)
+	assert.Regexp(t, "duplicate metrics collector registration", err)
+}
+
 func TestControllerStartGracefulShutdownNoConns(t *testing.T) {
 	pc := newTestPluginManager(t, &testManagers{})
 	pc.Stop()
@@ -71,6 +71,15 @@ func (br *RegistryBridge) RequestReply(ctx context.Context, reqMsg plugintk.Plug
 				}
 			},
 		)
+	case *prototk.RegistryMessage_SendTransaction:
+		return callManagerImpl(ctx, req.SendTransaction,
+			br.manager.SendTransaction,
+			func(resMsg *prototk.RegistryMessage, res *prototk.SendTransactionResponse) {
+				resMsg.ResponseToRegistry = &prototk.RegistryMessage_SendTransactionRes{
+					SendTransactionRes: res,
+				}
+			},
+		)
 	default:
 		return nil, i18n.NewError(ctx, msgs.MsgPluginBadRequestBody, req)
 	}
@@ -105,3 +114,33 @@ func (br *RegistryBridge) HandleRegistryEvents(ctx context.Context, req *prototk
 	)
 	return
 }
+
+func (br *RegistryBridge) RegisterIdentity(ctx context.Context, req *prototk.RegisterIdentityRequest) (res *prototk.RegisterIdentityResponse, err error) {
+	err = br.toPlugin.RequestReply(ctx,
+		func(dm plugintk.PluginMessage[prototk.RegistryMessage]) {
+			dm.Message().RequestToRegistry = &prototk.RegistryMessage_RegisterIdentity{RegisterIdentity: req}
+		},
+		func(dm plugintk.PluginMessage[prototk.RegistryMessage]) bool {
+			if r, ok := dm.Message().ResponseFromRegistry.(*prototk.RegistryMessage_RegisterIdentityRes); ok {
+				res = r.RegisterIdentityRes
+			}
+			return res != nil
+		},
+	)
+	return
+}
+
+func (br *RegistryBridge) SetProperty(ctx context.Context, req *prototk.SetPropertyRequest) (res *prototk.SetPropertyResponse, err error) {
+	err = br.toPlugin.RequestReply(ctx,
+		func(dm plugintk.PluginMessage[prototk.RegistryMessage]) {
+			dm.Message().RequestToRegistry = &prototk.RegistryMessage_SetProperty{SetProperty: req}
+		},
+		func(dm plugintk.PluginMessage[prototk.RegistryMessage]) bool {
+			if r, ok := dm.Message().ResponseFromRegistry.(*prototk.RegistryMessage_SetPropertyRes); ok {
+				res = r.SetPropertyRes
+			}
+			return res != nil
+		},
+	)
+	return
+}
@@ -40,6 +40,7 @@ type testRegistryManager struct {
 	registryRegistered func(name string, id uuid.UUID, toRegistry components.RegistryManagerToRegistry) (fromRegistry plugintk.RegistryCallbacks, err error)
 
 	upsertRegistryRecords func(ctx context.Context, req *prototk.UpsertRegistryRecordsRequest) (*prototk.UpsertRegistryRecordsResponse, error)
+	sendTransaction       func(ctx context.Context, req *prototk.SendTransactionRequest) (*prototk.SendTransactionResponse, error)
 }
 
 func registryConnectFactory(ctx context.Context, client prototk.PluginControllerClient) (grpc.BidiStreamingClient[prototk.RegistryMessage, prototk.RegistryMessage], error) {
@@ -75,6 +76,10 @@ func (tdm *testRegistryManager) UpsertRegistryRecords(ctx context.Context, req *
 	return tdm.upsertRegistryRecords(ctx, req)
 }
 
+func (tdm *testRegistryManager) SendTransaction(ctx context.Context, req *prototk.SendTransactionRequest) (*prototk.SendTransactionResponse, error) {
+	return tdm.sendTransaction(ctx, req)
+}
+
 func newTestRegistryPluginManager(t *testing.T, setup *testManagers) (context.Context, *pluginManager, func()) {
 	ctx, cancelCtx := context.WithCancel(context.Background())
 
@@ -118,6 +123,14 @@ func TestRegistryRequestsOK(t *testing.T) {
 				Entries: []*prototk.RegistryEntry{{Name: "node1"}},
 			}, nil
 		},
+		RegisterIdentity: func(ctx context.Context, rir *prototk.RegisterIdentityRequest) (*prototk.RegisterIdentityResponse, error) {
+			assert.Equal(t, "node1", rir.Name)
+			return &prototk.RegisterIdentityResponse{TransactionId: "tx1"}, nil
+		},
+		SetProperty: func(ctx context.Context, spr *prototk.SetPropertyRequest) (*prototk.SetPropertyResponse, error) {
+			assert.Equal(t, "transport.grpc", spr.Name)
+			return &prototk.SetPropertyResponse{TransactionId: "tx2"}, nil
+		},
 	}
 
 	trm := &testRegistryManager{
@@ -131,6 +144,10 @@ func TestRegistryRequestsOK(t *testing.T) {
 			assert.Equal(t, "node1", req.Entries[0].Name)
 			return &prototk.UpsertRegistryRecordsResponse{}, nil
 		},
+		sendTransaction: func(ctx context.Context, req *prototk.SendTransactionRequest) (*prototk.SendTransactionResponse, error) {
+			assert.Equal(t, "key1", req.From)
+			return &prototk.SendTransactionResponse{TransactionId: "tx3"}, nil
+		},
 	}
 	trm.registryRegistered = func(name string, id uuid.UUID, toRegistry components.RegistryManagerToRegistry) (plugintk.RegistryCallbacks, error) {
 		assert.Equal(t, "registry1", name)
@@ -154,6 +171,18 @@ func TestRegistryRequestsOK(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, "node1", rebr.Entries[0].Name)
 
+	rir, err := registryAPI.RegisterIdentity(ctx, &prototk.RegisterIdentityRequest{
+		Name: "node1",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "tx1", rir.TransactionId)
+
+	spr, err := registryAPI.SetProperty(ctx, &prototk.SetPropertyRequest{
+		Name: "transport.grpc",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "tx2", spr.TransactionId)
+
 	// This is the point the registry manager would call us to say the registry is initialized
 	// (once it's happy it's updated its internal state)
 	registryAPI.Initialized()
@@ -167,6 +196,12 @@ func TestRegistryRequestsOK(t *testing.T) {
 	require.NoError(t, err)
 	assert.NotNil(t, utr)
 
+	str, err := callbacks.SendTransaction(ctx, &prototk.SendTransactionRequest{
+		From: "key1",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "tx3", str.TransactionId)
+
 }
 
 func TestRegistryRegisterFail(t *testing.T) {
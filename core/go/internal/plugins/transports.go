@@ -129,3 +129,33 @@ func (br *TransportBridge) GetLocalDetails(ctx context.Context, req *prototk.Get
 	)
 	return
 }
+
+func (br *TransportBridge) GetPeerInfo(ctx context.Context, req *prototk.GetPeerInfoRequest) (res *prototk.GetPeerInfoResponse, err error) {
+	err = br.toPlugin.RequestReply(ctx,
+		func(dm plugintk.PluginMessage[prototk.TransportMessage]) {
+			dm.Message().RequestToTransport = &prototk.TransportMessage_GetPeerInfo{GetPeerInfo: req}
+		},
+		func(dm plugintk.PluginMessage[prototk.TransportMessage]) bool {
+			if r, ok := dm.Message().ResponseFromTransport.(*prototk.TransportMessage_GetPeerInfoRes); ok {
+				res = r.GetPeerInfoRes
+			}
+			return res != nil
+		},
+	)
+	return
+}
+
+func (br *TransportBridge) TransportDetailsChanged(ctx context.Context, req *prototk.TransportDetailsChangedRequest) (res *prototk.TransportDetailsChangedResponse, err error) {
+	err = br.toPlugin.RequestReply(ctx,
+		func(dm plugintk.PluginMessage[prototk.TransportMessage]) {
+			dm.Message().RequestToTransport = &prototk.TransportMessage_TransportDetailsChanged{TransportDetailsChanged: req}
+		},
+		func(dm plugintk.PluginMessage[prototk.TransportMessage]) bool {
+			if r, ok := dm.Message().ResponseFromTransport.(*prototk.TransportMessage_TransportDetailsChangedRes); ok {
+				res = r.TransportDetailsChangedRes
+			}
+			return res != nil
+		},
+	)
+	return
+}
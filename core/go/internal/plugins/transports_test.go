@@ -128,6 +128,13 @@ func TestTransportRequestsOK(t *testing.T) {
 		GetLocalDetails: func(ctx context.Context, gldr *prototk.GetLocalDetailsRequest) (*prototk.GetLocalDetailsResponse, error) {
 			return &prototk.GetLocalDetailsResponse{TransportDetails: "endpoint stuff"}, nil
 		},
+		GetPeerInfo: func(ctx context.Context, gpir *prototk.GetPeerInfoRequest) (*prototk.GetPeerInfoResponse, error) {
+			assert.Equal(t, "node1", gpir.Node)
+			return &prototk.GetPeerInfoResponse{PeerInfoJson: `{"peer":"stuff"}`}, nil
+		},
+		TransportDetailsChanged: func(ctx context.Context, tdcr *prototk.TransportDetailsChangedRequest) (*prototk.TransportDetailsChangedResponse, error) {
+			return &prototk.TransportDetailsChangedResponse{}, nil
+		},
 	}
 
 	ttm := &testTransportManager{
@@ -176,6 +183,14 @@ func TestTransportRequestsOK(t *testing.T) {
 	assert.NotNil(t, smr)
 	assert.Equal(t, "endpoint stuff", gldr.TransportDetails)
 
+	gpir, err := transportAPI.GetPeerInfo(ctx, &prototk.GetPeerInfoRequest{Node: "node1"})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"peer":"stuff"}`, gpir.PeerInfoJson)
+
+	tdcr, err := transportAPI.TransportDetailsChanged(ctx, &prototk.TransportDetailsChangedRequest{})
+	require.NoError(t, err)
+	assert.NotNil(t, tdcr)
+
 	// This is the point the transport manager would call us to say the transport is initialized
 	// (once it's happy it's updated its internal state)
 	transportAPI.Initialized()
@@ -18,7 +18,6 @@ package preparedtxdistribution
 import (
 	"context"
 	"encoding/json"
-	"time"
 
 	"github.com/google/uuid"
 	"github.com/kaleido-io/paladin/config/pkg/pldconf"
@@ -27,28 +26,24 @@ import (
 	"github.com/kaleido-io/paladin/toolkit/pkg/log"
 	"github.com/kaleido-io/paladin/toolkit/pkg/retry"
 	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
-const RETRY_TIMEOUT = 5 * time.Second
+// The page size for handing over distributions that were not acknowledged before an upgrade
+const recoveryPageSize = 100
 
-func NewPreparedTransactionDistributer(ctx context.Context, nodeID string, transportManager components.TransportManager, txMgr components.TXManager, persistence persistence.Persistence, conf *pldconf.DistributerConfig) PreparedTransactionDistributer {
-	sd := &preparedTransactionDistributer{
+func NewPreparedTransactionDistributer(ctx context.Context, nodeID string, transportManager components.TransportManager, txMgr components.TXManager, persistence persistence.Persistence) PreparedTransactionDistributer {
+	return &preparedTransactionDistributer{
 		persistence:      persistence,
-		inputChan:        make(chan *PreparedTxnDistribution),
-		retryChan:        make(chan string),
-		acknowledgedChan: make(chan string),
-		pendingMap:       make(map[string]*PreparedTxnDistribution),
 		txMgr:            txMgr,
 		transportManager: transportManager,
 		nodeID:           nodeID,
 		retry:            retry.NewRetryIndefinite(&pldconf.RetryConfig{}, &pldconf.GenericRetryDefaults.RetryConfig),
 	}
-	sd.acknowledgementWriter = NewAcknowledgementWriter(ctx, sd.persistence, &conf.AcknowledgementWriter)
-	sd.receivedPreparedTransactionWriter = NewReceivedPreparedTransactionWriter(ctx, txMgr, persistence, &conf.ReceivedObjectWriter)
-
-	return sd
 }
 
+// Distributions recorded by earlier versions, which retried each one until it was acknowledged
 type PreparedTxnDistributionPersisted struct {
 	Created         tktypes.Timestamp `json:"created" gorm:"column:created;autoCreateTime:nano"`
 	ID              string            `json:"id"`
@@ -58,6 +53,11 @@ type PreparedTxnDistributionPersisted struct {
 	ContractAddress string            `json:"contractAddress"`
 }
 
+type preparedTxnDistributionAcknowledgement struct {
+	PreparedTxnDistribution string `json:"preparedTxnDistribution" gorm:"column:prepared_txn_distribution"`
+	ID                      string `json:"id"                      gorm:"column:id"`
+}
+
 // A PreparedTxnDistribution is an intent to send a prepared transaction to a remote party
 type PreparedTxnDistribution struct {
 	ID                      string
@@ -71,31 +71,27 @@ type PreparedTxnDistribution struct {
 /*
 PreparedTransactionDistributer is a component that is responsible for distributing prepared transactions to remote parties
 
-	it runs in its own goroutine and periodically sends prepared transactions to the intended recipients
-	until each recipient has acknowledged receipt of the prepared transaction.
-
-	This operates on in-memory data but will initialize from persistent storage on startup
+	the prepared transactions are sent as reliable messages by the transport manager, which persists them
+	in the DB transaction that records the prepared transaction, and resends them until the receiving node
+	has written them to its own DB.
 */
 type PreparedTransactionDistributer interface {
 	Start(ctx context.Context) error
 	Stop(ctx context.Context)
-	DistributePreparedTransactions(ctx context.Context, preparedTxnDistributions []*PreparedTxnDistribution)
+	// SendPreparedTransactions queues the prepared transactions for delivery to the node of each recipient,
+	// within the supplied DB transaction. The postCommit function must be called once it commits.
+	SendPreparedTransactions(ctx context.Context, dbTX *gorm.DB, preparedTxnDistributions []*PreparedTxnDistribution) (postCommit func(), err error)
 }
 
 type preparedTransactionDistributer struct {
-	runCtx                            context.Context
-	stopRunCtx                        context.CancelFunc
-	persistence                       persistence.Persistence
-	txMgr                             components.TXManager
-	inputChan                         chan *PreparedTxnDistribution
-	retryChan                         chan string
-	acknowledgedChan                  chan string
-	pendingMap                        map[string]*PreparedTxnDistribution
-	acknowledgementWriter             *acknowledgementWriter
-	receivedPreparedTransactionWriter *receivedPreparedTransactionWriter
-	transportManager                  components.TransportManager
-	nodeID                            string
-	retry                             *retry.Retry
+	runCtx           context.Context
+	stopRunCtx       context.CancelFunc
+	persistence      persistence.Persistence
+	txMgr            components.TXManager
+	transportManager components.TransportManager
+	nodeID           string
+	retry            *retry.Retry
+	recoveryDone     chan struct{}
 }
 
 func (sd *preparedTransactionDistributer) Start(bgCtx context.Context) error {
@@ -109,123 +105,113 @@ func (sd *preparedTransactionDistributer) Start(bgCtx context.Context) error {
 		return err
 	}
 
-	sd.acknowledgementWriter.Start()
-	sd.receivedPreparedTransactionWriter.Start()
-
-	// TODO: This needs to be a worker per-peer - probably a whole distributor per peer that can be swapped in/out.
-	// Currently it only runs on startup, and pushes all prepared transaction distributions from before the startup time into the distributor.
-	startTime := tktypes.TimestampNow()
-	go func() {
-		page := 0
-		dispatched := 0
-		var lastEntry *PreparedTxnDistributionPersisted
-		finished := false
-		for !finished {
-			err := sd.retry.Do(ctx, func(attempt int) (retryable bool, err error) {
-				page++
-				var preparedTxnDistributions []*PreparedTxnDistributionPersisted
-				query := sd.persistence.DB().Table("prepared_txn_distributions").
-					Select("prepared_txn_distributions.*").
-					Joins("LEFT JOIN prepared_txn_distribution_acknowledgments ON prepared_txn_distributions.id = prepared_txn_distribution_acknowledgments.prepared_txn_distribution").
-					Where("prepared_txn_distribution_acknowledgments.id IS NULL").
-					Where("created < ?", startTime).
-					Order("created").
-					Limit(100)
-				if lastEntry != nil {
-					query = query.Where("created > ?", lastEntry.Created)
-				}
-				err = query.Find(&preparedTxnDistributions).Error
-
-				if err != nil {
-					log.L(ctx).Errorf("Error getting prepared transaction distributions: %s", err)
-					return true, err
-				}
-
-				log.L(ctx).Infof("preparedTransactionDistributer loaded %d prepared transaction distributions on startup (page=%d)", len(preparedTxnDistributions), page)
-
-				for _, preparedTxnDistribution := range preparedTxnDistributions {
-					preparedTxnID, err := uuid.Parse(preparedTxnDistribution.PreparedTxnID)
-					if err != nil {
-						log.L(ctx).Errorf("Error parsing prepared transaction ID: %s", err)
-						continue
-					}
-					preparedTransaction, err := sd.txMgr.GetPreparedTransactionByID(ctx, sd.persistence.DB() /* no TX for now */, preparedTxnID)
-					if err != nil {
-						log.L(ctx).Errorf("Error getting prepared transaction: %s", err)
-						continue
-					}
-
-					preparedTransactionJSON, err := json.Marshal(preparedTransaction)
-					if err != nil {
-						log.L(ctx).Errorf("Error marshalling prepared transaction: %s", err)
-						continue
-					}
-
-					sd.inputChan <- &PreparedTxnDistribution{
-						ID:                      preparedTxnDistribution.ID,
-						PreparedTxnID:           preparedTxnDistribution.PreparedTxnID,
-						IdentityLocator:         preparedTxnDistribution.IdentityLocator,
-						Domain:                  preparedTxnDistribution.DomainName,
-						ContractAddress:         preparedTxnDistribution.ContractAddress,
-						PreparedTransactionJSON: preparedTransactionJSON,
-					}
-
-					dispatched++
-					lastEntry = preparedTxnDistribution
-				}
-				finished = (len(preparedTxnDistributions) == 0)
-				return false, nil
+	sd.recoveryDone = make(chan struct{})
+	go sd.recoverUnacknowledged(ctx)
+	return nil
+}
+
+func (sd *preparedTransactionDistributer) Stop(ctx context.Context) {
+	sd.stopRunCtx()
+	<-sd.recoveryDone
+}
+
+// Distributions recorded by earlier versions that were not acknowledged are handed over to the
+// transport manager, and marked as acknowledged in the same DB transaction.
+func (sd *preparedTransactionDistributer) recoverUnacknowledged(ctx context.Context) {
+	defer close(sd.recoveryDone)
+
+	recovered := 0
+	for {
+		var pageSize int
+		err := sd.retry.Do(ctx, func(attempt int) (retryable bool, err error) {
+			var postCommit func()
+			err = sd.persistence.DB().Transaction(func(dbTX *gorm.DB) (err error) {
+				pageSize, postCommit, err = sd.recoverPage(ctx, dbTX)
+				return err
 			})
 			if err != nil {
-				log.L(ctx).Warnf("exiting before sending all recovered prepared transaction distributions")
+				log.L(ctx).Errorf("Error recovering prepared transaction distributions: %s", err)
+				return true, err
 			}
+			postCommit()
+			return false, nil
+		})
+		if err != nil {
+			log.L(ctx).Warnf("exiting before sending all recovered prepared transaction distributions")
+			return
 		}
-		log.L(ctx).Infof("preparedTransactionDistributer finished startup recovery after dispatching %d distributions", dispatched)
-	}()
-
-	go func() {
-		log.L(ctx).Info("preparedTransactionDistributer:Loop starting loop")
-		for {
-			log.L(ctx).Debug("preparedTransactionDistributer:Loop waiting for next event")
-
-			select {
-			case <-ctx.Done():
-				return
-			case preparedTxnDistributionID := <-sd.acknowledgedChan:
-				_, stillPending := sd.pendingMap[preparedTxnDistributionID]
-				if stillPending {
-					log.L(ctx).Debugf("preparedTransactionDistributer:Loop processing acknowledgment %s", preparedTxnDistributionID)
-
-					delete(sd.pendingMap, preparedTxnDistributionID)
-				} else {
-					log.L(ctx).Debugf("preparedTransactionDistributer:Loop already received acknowledgment %s", preparedTxnDistributionID)
-
-				}
-				//if we didn't find it in the map, it was already acknowledged
-
-			case preparedTxnDistributionID := <-sd.retryChan:
-
-				pendingDistribution, stillPending := sd.pendingMap[preparedTxnDistributionID]
-				if stillPending {
-					log.L(ctx).Debugf("preparedTransactionDistributer:Loop retrying %s", preparedTxnDistributionID)
-					sd.sendPreparedTransaction(ctx, pendingDistribution)
-				}
-				//if we didn't find it in the map, it was already acknowledged
-
-			case preparedTxnDistribution := <-sd.inputChan:
-				log.L(ctx).Debugf("preparedTransactionDistributer:Loop new distribution %s", preparedTxnDistribution.ID)
+		if pageSize == 0 {
+			break
+		}
+		recovered += pageSize
+	}
+	log.L(ctx).Infof("preparedTransactionDistributer finished startup recovery after handing over %d distributions", recovered)
+}
 
-				sd.pendingMap[preparedTxnDistribution.ID] = preparedTxnDistribution
-				sd.sendPreparedTransaction(ctx, preparedTxnDistribution)
+func (sd *preparedTransactionDistributer) recoverPage(ctx context.Context, dbTX *gorm.DB) (int, func(), error) {
+	var persisted []*PreparedTxnDistributionPersisted
+	err := dbTX.
+		WithContext(ctx).
+		Table("prepared_txn_distributions").
+		Select("prepared_txn_distributions.*").
+		Joins("LEFT JOIN prepared_txn_distribution_acknowledgments ON prepared_txn_distributions.id = prepared_txn_distribution_acknowledgments.prepared_txn_distribution").
+		Where("prepared_txn_distribution_acknowledgments.id IS NULL").
+		Order("created").
+		Limit(recoveryPageSize).
+		Find(&persisted).
+		Error
+	if err != nil || len(persisted) == 0 {
+		return 0, func() {}, err
+	}
 
-			}
+	distributions := make([]*PreparedTxnDistribution, 0, len(persisted))
+	acks := make([]*preparedTxnDistributionAcknowledgement, len(persisted))
+	for i, ptd := range persisted {
+		acks[i] = &preparedTxnDistributionAcknowledgement{
+			PreparedTxnDistribution: ptd.ID,
+			ID:                      uuid.New().String(),
 		}
-	}()
-	return nil
-}
+		// Any we cannot load are acknowledged without being sent, so they are not retried forever
+		preparedTxnID, err := uuid.Parse(ptd.PreparedTxnID)
+		if err != nil {
+			log.L(ctx).Errorf("Error parsing prepared transaction ID: %s", err)
+			continue
+		}
+		preparedTransaction, err := sd.txMgr.GetPreparedTransactionByID(ctx, dbTX, preparedTxnID)
+		if err != nil {
+			return 0, nil, err
+		}
+		if preparedTransaction == nil {
+			log.L(ctx).Errorf("Prepared transaction %s not found for distribution %s", preparedTxnID, ptd.ID)
+			continue
+		}
+		preparedTransactionJSON, err := json.Marshal(preparedTransaction)
+		if err != nil {
+			return 0, nil, err
+		}
+		distributions = append(distributions, &PreparedTxnDistribution{
+			ID:                      ptd.ID,
+			PreparedTxnID:           ptd.PreparedTxnID,
+			IdentityLocator:         ptd.IdentityLocator,
+			Domain:                  ptd.DomainName,
+			ContractAddress:         ptd.ContractAddress,
+			PreparedTransactionJSON: preparedTransactionJSON,
+		})
+	}
 
-func (sd *preparedTransactionDistributer) Stop(ctx context.Context) {
-	sd.stopRunCtx()
-	sd.acknowledgementWriter.Stop()
-	sd.receivedPreparedTransactionWriter.Stop()
+	postCommit, err := sd.SendPreparedTransactions(ctx, dbTX, distributions)
+	if err == nil {
+		err = dbTX.
+			WithContext(ctx).
+			Table("prepared_txn_distribution_acknowledgments").
+			Clauses(clause.OnConflict{
+				DoNothing: true, // immutable
+			}).
+			Create(acks).
+			Error
+	}
+	if err != nil {
+		return 0, nil, err
+	}
+	return len(persisted), postCommit, nil
 }
@@ -0,0 +1,224 @@
+/*
+ * Copyright © 2024 Kaleido, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package preparedtxdistribution
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/kaleido-io/paladin/core/internal/components"
+	"github.com/kaleido-io/paladin/core/mocks/componentmocks"
+	"github.com/kaleido-io/paladin/core/pkg/persistence"
+	pb "github.com/kaleido-io/paladin/core/pkg/proto/engine"
+	"github.com/kaleido-io/paladin/toolkit/pkg/pldapi"
+	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"gorm.io/gorm"
+)
+
+type mockComponents struct {
+	p                persistence.Persistence
+	txMgr            *componentmocks.TXManager
+	transportManager *componentmocks.TransportManager
+}
+
+func newTestPreparedTransactionDistributer(t *testing.T) (context.Context, *mockComponents, *preparedTransactionDistributer) {
+	ctx := context.Background()
+
+	p, pDone, err := persistence.NewUnitTestPersistence(ctx, "preparedtxdistribution")
+	require.NoError(t, err)
+	t.Cleanup(pDone)
+
+	mc := &mockComponents{
+		p:                p,
+		txMgr:            componentmocks.NewTXManager(t),
+		transportManager: componentmocks.NewTransportManager(t),
+	}
+
+	sd := NewPreparedTransactionDistributer(ctx, "node1", mc.transportManager, mc.txMgr, mc.p)
+	return ctx, mc, sd.(*preparedTransactionDistributer)
+}
+
+func TestSendPreparedTransactions(t *testing.T) {
+	ctx, mc, sd := newTestPreparedTransactionDistributer(t)
+
+	postCommit, err := sd.SendPreparedTransactions(ctx, mc.p.DB(), nil)
+	require.NoError(t, err)
+	postCommit()
+
+	distID := uuid.New()
+	committed := false
+	mc.transportManager.On("SendReliable", ctx, mock.Anything, mock.Anything).Return(func() { committed = true }, nil).Run(func(args mock.Arguments) {
+		msg := args[2].(*components.TransportMessage)
+		assert.Equal(t, distID, msg.MessageID)
+		assert.Equal(t, "node2", msg.Node)
+		assert.Equal(t, "node1", msg.ReplyTo)
+		assert.Equal(t, PREPARED_TRANSACTION_DISTRIBUTER_DESTINATION, msg.Component)
+		var ptm pb.PreparedTransactionMessage
+		require.NoError(t, proto.Unmarshal(msg.Payload, &ptm))
+		assert.Equal(t, "bob@node2", ptm.Party)
+		assert.Equal(t, distID.String(), ptm.DistributionId)
+	})
+
+	postCommit, err = sd.SendPreparedTransactions(ctx, mc.p.DB(), []*PreparedTxnDistribution{{
+		ID:              distID.String(),
+		PreparedTxnID:   uuid.NewString(),
+		IdentityLocator: "bob@node2",
+		Domain:          "domain1",
+		ContractAddress: tktypes.RandAddress().String(),
+	}})
+	require.NoError(t, err)
+	postCommit()
+	assert.True(t, committed)
+}
+
+func TestSendPreparedTransactionsBadDistribution(t *testing.T) {
+	ctx, mc, sd := newTestPreparedTransactionDistributer(t)
+
+	_, err := sd.SendPreparedTransactions(ctx, mc.p.DB(), []*PreparedTxnDistribution{{
+		ID:              "not a uuid",
+		IdentityLocator: "bob@node2",
+	}})
+	assert.Error(t, err)
+
+	_, err = sd.SendPreparedTransactions(ctx, mc.p.DB(), []*PreparedTxnDistribution{{
+		ID:              uuid.NewString(),
+		IdentityLocator: "bob",
+	}})
+	assert.Error(t, err)
+}
+
+func TestReceiveReliableTransportMessage(t *testing.T) {
+	ctx, mc, sd := newTestPreparedTransactionDistributer(t)
+
+	txID := uuid.New()
+	preparedTxJSON, err := json.Marshal(&components.PrepareTransactionWithRefs{ID: txID, Domain: "domain1"})
+	require.NoError(t, err)
+	payload, err := proto.Marshal(&pb.PreparedTransactionMessage{
+		PreparedTxnId:           txID.String(),
+		PreparedTransactionJson: preparedTxJSON,
+	})
+	require.NoError(t, err)
+
+	written := false
+	mc.txMgr.On("WritePreparedTransactions", ctx, mock.Anything, mock.Anything).Return(func() { written = true }, nil).Run(func(args mock.Arguments) {
+		prepared := args[2].([]*components.PrepareTransactionWithRefs)
+		require.Len(t, prepared, 1)
+		assert.Equal(t, txID, prepared[0].ID)
+	}).Once()
+
+	postCommit, err := sd.ReceiveReliableTransportMessage(ctx, mc.p.DB(), &components.TransportMessage{
+		MessageType: "PreparedTransactionMessage",
+		Payload:     payload,
+		ReplyTo:     "node2",
+	})
+	require.NoError(t, err)
+	postCommit()
+	assert.True(t, written)
+
+	mc.txMgr.On("WritePreparedTransactions", ctx, mock.Anything, mock.Anything).Return(nil, assert.AnError).Once()
+	_, err = sd.ReceiveReliableTransportMessage(ctx, mc.p.DB(), &components.TransportMessage{
+		MessageType: "PreparedTransactionMessage",
+		Payload:     payload,
+	})
+	assert.Equal(t, assert.AnError, err)
+}
+
+func TestReceiveReliableTransportMessageDiscarded(t *testing.T) {
+	ctx, mc, sd := newTestPreparedTransactionDistributer(t)
+
+	badJSON, err := proto.Marshal(&pb.PreparedTransactionMessage{PreparedTransactionJson: []byte("!json")})
+	require.NoError(t, err)
+
+	for _, msg := range []*components.TransportMessage{
+		{MessageType: "WrongType"},
+		{MessageType: "PreparedTransactionMessage", Payload: []byte("!protobuf")},
+		{MessageType: "PreparedTransactionMessage", Payload: badJSON},
+	} {
+		postCommit, err := sd.ReceiveReliableTransportMessage(ctx, mc.p.DB(), msg)
+		require.NoError(t, err)
+		assert.Nil(t, postCommit)
+	}
+
+	// unreliable messages are ignored
+	sd.ReceiveTransportMessage(ctx, &components.TransportMessage{MessageType: "PreparedTransactionMessage"})
+}
+
+func TestRecoverUnacknowledged(t *testing.T) {
+	ctx, mc, sd := newTestPreparedTransactionDistributer(t)
+
+	// Distributions recorded before the upgrade, one of which was acknowledged
+	txIDs := []uuid.UUID{uuid.New(), uuid.New(), uuid.New()}
+	distIDs := []string{uuid.NewString(), uuid.NewString(), uuid.NewString()}
+	err := mc.p.DB().Transaction(func(dbTX *gorm.DB) error {
+		for i, txID := range txIDs {
+			err := dbTX.Exec(`INSERT INTO prepared_txns ("id", "created", "domain", "transaction") VALUES (?, ?, ?, ?)`,
+				txID, tktypes.TimestampNow(), "domain1", "{}").Error
+			if err == nil {
+				err = dbTX.Table("prepared_txn_distributions").Create(&PreparedTxnDistributionPersisted{
+					ID:              distIDs[i],
+					PreparedTxnID:   txID.String(),
+					IdentityLocator: "bob@node2",
+					DomainName:      "domain1",
+					ContractAddress: tktypes.RandAddress().String(),
+				}).Error
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return dbTX.Table("prepared_txn_distribution_acknowledgments").Create(&preparedTxnDistributionAcknowledgement{
+			PreparedTxnDistribution: distIDs[0],
+			ID:                      uuid.NewString(),
+		}).Error
+	})
+	require.NoError(t, err)
+
+	// The second can no longer be loaded, so is acknowledged without being sent
+	mc.txMgr.On("GetPreparedTransactionByID", mock.Anything, mock.Anything, txIDs[1]).Return(nil, nil)
+	mc.txMgr.On("GetPreparedTransactionByID", mock.Anything, mock.Anything, txIDs[2]).Return(&pldapi.PreparedTransaction{ID: txIDs[2]}, nil)
+	sent := make(chan uuid.UUID, 1)
+	mc.transportManager.On("RegisterClient", mock.Anything, sd).Return(nil)
+	mc.transportManager.On("SendReliable", mock.Anything, mock.Anything, mock.Anything).Return(func() {}, nil).Run(func(args mock.Arguments) {
+		require.Len(t, args, 3)
+		sent <- args[2].(*components.TransportMessage).MessageID
+	}).Once()
+
+	err = sd.Start(ctx)
+	require.NoError(t, err)
+	<-sd.recoveryDone
+	sd.Stop(ctx)
+
+	assert.Equal(t, distIDs[2], (<-sent).String())
+
+	var ackCount int64
+	err = mc.p.DB().Table("prepared_txn_distribution_acknowledgments").Count(&ackCount).Error
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), ackCount)
+}
+
+func TestStartRegisterFail(t *testing.T) {
+	ctx, mc, sd := newTestPreparedTransactionDistributer(t)
+
+	mc.transportManager.On("RegisterClient", mock.Anything, sd).Return(assert.AnError)
+	err := sd.Start(ctx)
+	assert.Equal(t, assert.AnError, err)
+}
@@ -17,30 +17,52 @@ package preparedtxdistribution
 
 import (
 	"context"
-	"time"
 
+	"github.com/google/uuid"
 	"github.com/kaleido-io/paladin/core/internal/components"
 	pb "github.com/kaleido-io/paladin/core/pkg/proto/engine"
 	"github.com/kaleido-io/paladin/toolkit/pkg/log"
 	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
 	"google.golang.org/protobuf/proto"
+	"gorm.io/gorm"
 )
 
-func (sd *preparedTransactionDistributer) DistributePreparedTransactions(ctx context.Context, preparedTxnDistributions []*PreparedTxnDistribution) {
-	log.L(ctx).Debugf("preparedTransactionDistributer:DistributePreparedTransactions %d prepared transaction distributions", len(preparedTxnDistributions))
-	for _, preparedTxnDistribution := range preparedTxnDistributions {
-		sd.inputChan <- preparedTxnDistribution
+func (sd *preparedTransactionDistributer) SendPreparedTransactions(ctx context.Context, dbTX *gorm.DB, preparedTxnDistributions []*PreparedTxnDistribution) (postCommit func(), err error) {
+	log.L(ctx).Debugf("preparedTransactionDistributer:SendPreparedTransactions %d prepared transaction distributions", len(preparedTxnDistributions))
+	if len(preparedTxnDistributions) == 0 {
+		return func() {}, nil
 	}
+
+	messages := make([]*components.TransportMessage, len(preparedTxnDistributions))
+	for i, preparedTxnDistribution := range preparedTxnDistributions {
+		if messages[i], err = sd.buildPreparedTransactionMessage(ctx, preparedTxnDistribution); err != nil {
+			return nil, err
+		}
+	}
+	return sd.transportManager.SendReliable(ctx, dbTX, messages...)
 }
 
-func (sd *preparedTransactionDistributer) sendPreparedTransaction(ctx context.Context, preparedTxnDistribution *PreparedTxnDistribution) {
-	log.L(ctx).Debugf("preparedTransactionDistributer:sendPreparedTransaction Domain: %s, ContractAddress: %s, PreparedTxnID: %s, IdentityLocator: %s, ID: %s",
+func (sd *preparedTransactionDistributer) buildPreparedTransactionMessage(ctx context.Context, preparedTxnDistribution *PreparedTxnDistribution) (*components.TransportMessage, error) {
+	log.L(ctx).Debugf("preparedTransactionDistributer:buildPreparedTransactionMessage Domain: %s, ContractAddress: %s, PreparedTxnID: %s, IdentityLocator: %s, ID: %s",
 		preparedTxnDistribution.Domain,
 		preparedTxnDistribution.ContractAddress,
 		preparedTxnDistribution.PreparedTxnID,
 		preparedTxnDistribution.IdentityLocator,
 		preparedTxnDistribution.ID)
 
+	// The distribution ID is used as the message ID, so the receiving node removes any duplicates
+	messageID, err := uuid.Parse(preparedTxnDistribution.ID)
+	if err != nil {
+		log.L(ctx).Errorf("Error parsing distribution ID %s: %s", preparedTxnDistribution.ID, err)
+		return nil, err
+	}
+
+	targetNode, err := tktypes.PrivateIdentityLocator(preparedTxnDistribution.IdentityLocator).Node(ctx, false)
+	if err != nil {
+		log.L(ctx).Errorf("Error getting node for party %s", preparedTxnDistribution.IdentityLocator)
+		return nil, err
+	}
+
 	preparedTransactionMessage := &pb.PreparedTransactionMessage{
 		DomainName:              preparedTxnDistribution.Domain,
 		ContractAddress:         preparedTxnDistribution.ContractAddress,
@@ -51,31 +73,16 @@ func (sd *preparedTransactionDistributer) sendPreparedTransaction(ctx context.Co
 	}
 	preparedTransactionMessageBytes, err := proto.Marshal(preparedTransactionMessage)
 	if err != nil {
-		log.L(ctx).Errorf("Error marshalling delegate transaction message: %s", err)
-		return
+		log.L(ctx).Errorf("Error marshalling prepared transaction message: %s", err)
+		return nil, err
 	}
 
-	targetNode, err := tktypes.PrivateIdentityLocator(preparedTxnDistribution.IdentityLocator).Node(ctx, false)
-	if err != nil {
-		log.L(ctx).Errorf("Error getting node for party %s", preparedTxnDistribution.IdentityLocator)
-		return
-	}
-
-	err = sd.transportManager.Send(ctx, &components.TransportMessage{
+	return &components.TransportMessage{
+		MessageID:   messageID,
 		MessageType: "PreparedTransactionMessage",
 		Payload:     preparedTransactionMessageBytes,
 		Node:        targetNode,
 		Component:   PREPARED_TRANSACTION_DISTRIBUTER_DESTINATION,
 		ReplyTo:     sd.nodeID,
-	})
-	if err != nil {
-		log.L(ctx).Errorf("Error sending prepared transaction produced event: %s", err)
-		return
-	}
-
-	go func() {
-		time.Sleep(RETRY_TIMEOUT)
-		sd.retryChan <- preparedTxnDistribution.ID
-	}()
-
+	}, nil
 }
@@ -22,8 +22,8 @@ import (
 	"github.com/kaleido-io/paladin/core/internal/components"
 	pb "github.com/kaleido-io/paladin/core/pkg/proto/engine"
 	"github.com/kaleido-io/paladin/toolkit/pkg/log"
-	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
 	"google.golang.org/protobuf/proto"
+	"gorm.io/gorm"
 )
 
 const PREPARED_TRANSACTION_DISTRIBUTER_DESTINATION = "prepared-transaction-distributer"
@@ -33,76 +33,37 @@ func (sd *preparedTransactionDistributer) Destination() string {
 }
 
 func (sd *preparedTransactionDistributer) ReceiveTransportMessage(ctx context.Context, message *components.TransportMessage) {
-	log.L(ctx).Debugf("preparedTransactionDistributer:ReceiveTransportMessage")
-	messagePayload := message.Payload
+	// Prepared transactions are only exchanged as reliable messages
+	log.L(ctx).Errorf("Unexpected unreliable message type %s from %s", message.MessageType, message.ReplyTo)
+}
+
+// Writes the received prepared transaction in the DB transaction that records the receipt of the message,
+// so it is written exactly once, and the sending node stops resending it once it is committed.
+func (sd *preparedTransactionDistributer) ReceiveReliableTransportMessage(ctx context.Context, dbTX *gorm.DB, message *components.TransportMessage) (func(), error) {
+	log.L(ctx).Debugf("preparedTransactionDistributer:ReceiveReliableTransportMessage")
 
-	switch message.MessageType {
-	case "PreparedTransactionMessage":
-		distributingNode := message.ReplyTo
-		go sd.handlePreparedTransactionMessage(ctx, messagePayload, distributingNode)
-	case "PreparedTransactionAcknowledgedMessage":
-		go sd.handlePreparedTransactionAcknowledgedMessage(ctx, message.Payload)
-	default:
+	// Messages we cannot parse are accepted and discarded, as they would fail in the same way when resent
+	if message.MessageType != "PreparedTransactionMessage" {
 		log.L(ctx).Errorf("Unknown message type: %s", message.MessageType)
+		return nil, nil
 	}
-}
-
-func (sd *preparedTransactionDistributer) handlePreparedTransactionMessage(ctx context.Context, messagePayload []byte, distributingNode string) {
-	log.L(ctx).Debugf("preparedTransactionDistributer:handlePreparedTransactionMessage")
 	preparedTransactionMessage := &pb.PreparedTransactionMessage{}
-	err := proto.Unmarshal(messagePayload, preparedTransactionMessage)
+	err := proto.Unmarshal(message.Payload, preparedTransactionMessage)
 	if err != nil {
 		log.L(ctx).Errorf("Failed to unmarshal PreparedTransactionMessage: %s", err)
-		return
+		return nil, nil
 	}
-
 	receivedTransaction := new(components.PrepareTransactionWithRefs)
 	err = json.Unmarshal(preparedTransactionMessage.PreparedTransactionJson, receivedTransaction)
 	if err != nil {
 		log.L(ctx).Errorf("Error unmarshalling prepared transaction json: %s", err)
+		return nil, nil
 	}
 
-	err = sd.receivedPreparedTransactionWriter.QueueAndWait(
-		ctx,
-		preparedTransactionMessage.DomainName,
-		*tktypes.MustEthAddress(preparedTransactionMessage.ContractAddress),
-		receivedTransaction,
-	)
-	if err != nil {
-		log.L(ctx).Errorf("Error writing prepared transaction: %s", err)
-		//don't send the acknowledgement, with a bit of luck, the sender will retry and we will get it next time
-		return
-	}
-
-	// No error means either this is the first time we have received this prepared transaction or we already have it an onConflict ignore means we idempotently accept it
-	// If the latter, then the sender probably didn't get our previous acknowledgement so either way, we send an acknowledgement
-
-	err = sd.sendPreparedTransactionAcknowledgement(
-		ctx,
-		preparedTransactionMessage.DomainName,
-		preparedTransactionMessage.ContractAddress,
-		preparedTransactionMessage.PreparedTxnId,
-		preparedTransactionMessage.Party,
-		distributingNode,
-		preparedTransactionMessage.DistributionId)
+	postCommit, err := sd.txMgr.WritePreparedTransactions(ctx, dbTX, []*components.PrepareTransactionWithRefs{receivedTransaction})
 	if err != nil {
-		log.L(ctx).Errorf("Error sending prepared transaction acknowledgement: %s", err)
-		//not much more we can do here.  The sender will inevitably retry and we will hopefully send the ack next time
+		log.L(ctx).Errorf("Error writing prepared transaction %s from %s: %s", preparedTransactionMessage.PreparedTxnId, message.ReplyTo, err)
+		return nil, err
 	}
-}
-
-func (sd *preparedTransactionDistributer) handlePreparedTransactionAcknowledgedMessage(ctx context.Context, messagePayload []byte) {
-	log.L(ctx).Debugf("preparedTransactionDistributer:handlePreparedTransactionAcknowledgedMessage")
-	preparedTransactionAcknowledgedMessage := &pb.PreparedTransactionAcknowledgedMessage{}
-	err := proto.Unmarshal(messagePayload, preparedTransactionAcknowledgedMessage)
-	if err != nil {
-		log.L(ctx).Errorf("Failed to unmarshal PreparedTransactionAcknowledgedMessage: %s", err)
-		return
-	}
-	sd.acknowledgementWriter.Queue(ctx, preparedTransactionAcknowledgedMessage.DistributionId)
-	// no need to wait for the flush to complete, we can just stop the in memory loop from retrying
-	// worst case scenario, we crash before this is written to the DB, we do some redundant retries after a restart
-	// but waiting for the flush here is not going to prevent that
-	sd.acknowledgedChan <- preparedTransactionAcknowledgedMessage.DistributionId
-
+	return postCommit, nil
 }
@@ -23,9 +23,11 @@ import (
 	"github.com/hyperledger/firefly-common/pkg/i18n"
 	"github.com/kaleido-io/paladin/core/internal/components"
 	"github.com/kaleido-io/paladin/core/internal/msgs"
+	"github.com/kaleido-io/paladin/core/internal/tracing"
 	"github.com/kaleido-io/paladin/toolkit/pkg/log"
 	"github.com/kaleido-io/paladin/toolkit/pkg/prototk"
 	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // assemble a transaction that we are not coordinating, using the provided state locks
@@ -87,7 +89,11 @@ func (s *Sequencer) resolveLocalTransaction(ctx context.Context, transactionID u
 	return locallyResolvedTx, err
 }
 
-func (s *Sequencer) assembleAndSign(ctx context.Context, transactionID uuid.UUID, preAssembly *components.TransactionPreAssembly, domainContext components.DomainContext) (*components.TransactionPostAssembly, error) {
+func (s *Sequencer) assembleAndSign(ctx context.Context, transactionID uuid.UUID, preAssembly *components.TransactionPreAssembly, domainContext components.DomainContext) (_ *components.TransactionPostAssembly, err error) {
+	ctx, span := tracer.Start(ctx, "privatetx.assemble_and_sign")
+	span.SetAttributes(attribute.String("paladin.transaction_id", transactionID.String()))
+	defer func() { tracing.EndSpan(span, err) }()
+
 	//Assembles the transaction and synchronously fulfills any local signature attestation requests
 	// Given that the coordinator is single threading calls to assemble, there may be benefits to performance if we were to fulfill the signature request async
 	// but that would introduce levels of complexity that may not be justified so this is open as a potential for future optimization where we would need to think about
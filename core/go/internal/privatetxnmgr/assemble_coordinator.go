@@ -73,7 +73,9 @@ func NewAssembleCoordinator(ctx context.Context, nodeName string, maxPendingRequ
 func (ac *assembleCoordinator) Complete(requestID string, stateDistributions []*components.StateDistribution) {
 
 	log.L(ac.ctx).Debugf("AssembleCoordinator:Commit %s", requestID)
-	ac.stateDistributer.DistributeStates(ac.ctx, stateDistributions)
+	if err := ac.stateDistributer.DistributeStates(ac.ctx, stateDistributions); err != nil {
+		log.L(ac.ctx).Errorf("Failed to distribute states for assemble request %s: %s", requestID, err)
+	}
 	ac.commit <- requestID
 
 }
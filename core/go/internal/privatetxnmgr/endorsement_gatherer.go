@@ -24,10 +24,12 @@ import (
 	"github.com/kaleido-io/paladin/core/internal/components"
 	"github.com/kaleido-io/paladin/core/internal/msgs"
 	"github.com/kaleido-io/paladin/core/internal/privatetxnmgr/ptmgrtypes"
+	"github.com/kaleido-io/paladin/core/internal/tracing"
 	"github.com/kaleido-io/paladin/core/pkg/persistence"
 	"github.com/kaleido-io/paladin/toolkit/pkg/log"
 	"github.com/kaleido-io/paladin/toolkit/pkg/prototk"
 	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 func NewEndorsementGatherer(p persistence.Persistence, psc components.DomainSmartContract, dCtx components.DomainContext, keyMgr components.KeyManager) ptmgrtypes.EndorsementGatherer {
@@ -50,7 +52,13 @@ func (e *endorsementGatherer) DomainContext() components.DomainContext {
 	return e.dCtx
 }
 
-func (e *endorsementGatherer) GatherEndorsement(ctx context.Context, transactionSpecification *prototk.TransactionSpecification, verifiers []*prototk.ResolvedVerifier, signatures []*prototk.AttestationResult, inputStates []*prototk.EndorsableState, readStates []*prototk.EndorsableState, outputStates []*prototk.EndorsableState, infoStates []*prototk.EndorsableState, partyName string, endorsementRequest *prototk.AttestationRequest) (*prototk.AttestationResult, *string, error) {
+func (e *endorsementGatherer) GatherEndorsement(ctx context.Context, transactionSpecification *prototk.TransactionSpecification, verifiers []*prototk.ResolvedVerifier, signatures []*prototk.AttestationResult, inputStates []*prototk.EndorsableState, readStates []*prototk.EndorsableState, outputStates []*prototk.EndorsableState, infoStates []*prototk.EndorsableState, partyName string, endorsementRequest *prototk.AttestationRequest) (_ *prototk.AttestationResult, _ *string, err error) {
+	ctx, span := tracer.Start(ctx, "privatetx.endorse")
+	span.SetAttributes(
+		attribute.String("paladin.transaction_id", transactionSpecification.TransactionId),
+		attribute.String("paladin.party", partyName),
+	)
+	defer func() { tracing.EndSpan(span, err) }()
 
 	unqualifiedLookup, err := tktypes.PrivateIdentityLocator(partyName).Identity(ctx)
 	if err != nil {
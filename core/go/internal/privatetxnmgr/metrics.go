@@ -0,0 +1,103 @@
+/*
+ * Copyright © 2024 Kaleido, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package privatetxnmgr
+
+import (
+	"time"
+
+	"github.com/kaleido-io/paladin/core/internal/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const metricsSubsystem = "private_tx"
+
+type privateTxManagerMetrics struct {
+	stageTotal          *prometheus.CounterVec
+	stageLatency        *prometheus.HistogramVec
+	endorsementDuration *prometheus.HistogramVec
+}
+
+func newPrivateTxManagerMetrics() *privateTxManagerMetrics {
+	return &privateTxManagerMetrics{
+		stageTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: metricsSubsystem,
+			Name:      "stage_total",
+			Help:      "Number of private transactions that have reached each stage",
+		}, []string{"stage"}),
+		stageLatency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: metricsSubsystem,
+			Name:      "stage_latency_seconds",
+			Help:      "Time from a private transaction being loaded into a sequencer until it reaches each stage",
+			Buckets:   prometheus.ExponentialBuckets(0.01, 2, 14),
+		}, []string{"stage"}),
+		endorsementDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: metricsSubsystem,
+			Name:      "endorsement_round_trip_seconds",
+			Help:      "Time from sending an endorsement request to receiving the response",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"result"}),
+	}
+}
+
+func (m *privateTxManagerMetrics) register(r prometheus.Registerer, p *privateTxManager) error {
+	for _, c := range []prometheus.Collector{
+		m.stageTotal,
+		m.stageLatency,
+		m.endorsementDuration,
+		&sequencerQueueCollector{p: p},
+	} {
+		if err := r.Register(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *privateTxManagerMetrics) recordStage(stage string, since time.Time) {
+	m.stageTotal.WithLabelValues(stage).Inc()
+	m.stageLatency.WithLabelValues(stage).Observe(time.Since(since).Seconds())
+}
+
+func (m *privateTxManagerMetrics) recordEndorsement(result string, duration time.Duration) {
+	m.endorsementDuration.WithLabelValues(result).Observe(duration.Seconds())
+}
+
+var sequencerQueueDepthDesc = prometheus.NewDesc(
+	prometheus.BuildFQName(metrics.Namespace, metricsSubsystem, "sequencer_queue_depth"),
+	"Number of incomplete transactions held in memory by the sequencer for each contract",
+	[]string{"contract"}, nil,
+)
+
+// The sequencers come and go as contracts become active and idle, so the queue depth
+// is read from the active sequencers at scrape time rather than being tracked in a gauge
+type sequencerQueueCollector struct {
+	p *privateTxManager
+}
+
+func (c *sequencerQueueCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- sequencerQueueDepthDesc
+}
+
+func (c *sequencerQueueCollector) Collect(ch chan<- prometheus.Metric) {
+	c.p.sequencersLock.RLock()
+	defer c.p.sequencersLock.RUnlock()
+	for contractAddr, s := range c.p.sequencers {
+		ch <- prometheus.MustNewConstMetric(sequencerQueueDepthDesc, prometheus.GaugeValue, float64(s.incompleteTxCount()), contractAddr)
+	}
+}
@@ -0,0 +1,64 @@
+/*
+ * Copyright © 2024 Kaleido, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package privatetxnmgr
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kaleido-io/paladin/config/pkg/pldconf"
+	"github.com/kaleido-io/paladin/core/internal/metrics"
+	"github.com/kaleido-io/paladin/core/internal/privatetxnmgr/ptmgrtypes"
+	"github.com/kaleido-io/paladin/core/mocks/componentmocks"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrivateTxManagerMetrics(t *testing.T) {
+	ctx := context.Background()
+	mm := metrics.NewMetricsManager(ctx)
+	p := NewPrivateTransactionMgr(ctx, &pldconf.PrivateTxManagerConfig{}).(*privateTxManager)
+
+	mpic := componentmocks.NewPreInitComponents(t)
+	mpic.On("MetricsManager").Return(mm)
+	_, err := p.PreInit(mpic)
+	require.NoError(t, err)
+
+	p.sequencers["0x1111"] = &Sequencer{
+		incompleteTxSProcessMap: map[string]ptmgrtypes.TransactionFlow{
+			"tx1": nil,
+			"tx2": nil,
+		},
+	}
+	p.metrics.recordStage("assembled", time.Now())
+	p.metrics.recordEndorsement("endorsed", 10*time.Millisecond)
+
+	err = testutil.CollectAndCompare(&sequencerQueueCollector{p: p}, strings.NewReader(`
+# HELP paladin_private_tx_sequencer_queue_depth Number of incomplete transactions held in memory by the sequencer for each contract
+# TYPE paladin_private_tx_sequencer_queue_depth gauge
+paladin_private_tx_sequencer_queue_depth{contract="0x1111"} 2
+`))
+	require.NoError(t, err)
+	assert.Equal(t, float64(1), testutil.ToFloat64(p.metrics.stageTotal.WithLabelValues("assembled")))
+	assert.Equal(t, 1, testutil.CollectAndCount(p.metrics.endorsementDuration))
+
+	// Second registration into the same registry fails
+	_, err = p.PreInit(mpic)
+	assert.Error(t, err)
+}
@@ -28,6 +28,9 @@ import (
 	"github.com/kaleido-io/paladin/core/internal/privatetxnmgr/ptmgrtypes"
 	"github.com/kaleido-io/paladin/core/internal/privatetxnmgr/syncpoints"
 	"github.com/kaleido-io/paladin/core/internal/statedistribution"
+	"github.com/kaleido-io/paladin/core/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"gorm.io/gorm"
 
 	"github.com/kaleido-io/paladin/core/internal/msgs"
@@ -38,6 +41,7 @@ import (
 	"github.com/kaleido-io/paladin/config/pkg/confutil"
 	"github.com/kaleido-io/paladin/config/pkg/pldconf"
 	"github.com/kaleido-io/paladin/toolkit/pkg/pldapi"
+	"github.com/kaleido-io/paladin/toolkit/pkg/rpcserver"
 	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/anypb"
@@ -46,6 +50,8 @@ import (
 	"github.com/kaleido-io/paladin/toolkit/pkg/prototk"
 )
 
+var tracer = tracing.Tracer("privatetxnmgr")
+
 type privateTxManager struct {
 	ctx                            context.Context
 	ctxCancel                      func()
@@ -61,11 +67,18 @@ type privateTxManager struct {
 	stateDistributer               statedistribution.StateDistributer
 	preparedTransactionDistributer preparedtxdistribution.PreparedTransactionDistributer
 	blockHeight                    int64
+	metrics                        *privateTxManagerMetrics
+	rpcModule                      *rpcserver.RPCModule
 }
 
 // Init implements Engine.
 func (p *privateTxManager) PreInit(c components.PreInitComponents) (*components.ManagerInitResult, error) {
+	if err := p.metrics.register(c.MetricsManager().Registry(), p); err != nil {
+		return nil, err
+	}
+	p.initRPC()
 	return &components.ManagerInitResult{
+		RPCModules: []*rpcserver.RPCModule{p.rpcModule},
 		PreCommitHandler: func(ctx context.Context, _ *gorm.DB, blocks []*pldapi.IndexedBlock, transactions []*blockindexer.IndexedTransactionNotify) (blockindexer.PostCommit, error) {
 			log.L(ctx).Debug("PrivateTxManager PreCommitHandler")
 			latestBlockNumber := blocks[len(blocks)-1].Number
@@ -80,21 +93,20 @@ func (p *privateTxManager) PreInit(c components.PreInitComponents) (*components.
 func (p *privateTxManager) PostInit(c components.AllComponents) error {
 	p.components = c
 	p.nodeName = p.components.TransportManager().LocalNodeName()
-	p.syncPoints = syncpoints.NewSyncPoints(p.ctx, &p.config.Writer, c.Persistence(), c.TxManager(), c.PublicTxManager())
-	p.stateDistributer = statedistribution.NewStateDistributer(
-		p.ctx,
-		p.components.TransportManager(),
-		p.components.StateManager(),
-		p.components.KeyManager(),
-		p.components.Persistence(),
-		&p.config.StateDistributer)
 	p.preparedTransactionDistributer = preparedtxdistribution.NewPreparedTransactionDistributer(
 		p.ctx,
 		p.nodeName,
 		p.components.TransportManager(),
 		p.components.TxManager(),
+		p.components.Persistence())
+	p.stateDistributer = statedistribution.NewStateDistributer(
+		p.ctx,
+		p.components.TransportManager(),
+		p.components.StateManager(),
+		p.components.KeyManager(),
 		p.components.Persistence(),
-		&p.config.PreparedTransactionDistributer)
+		p.handleStateReceived)
+	p.syncPoints = syncpoints.NewSyncPoints(p.ctx, &p.config.Writer, c.Persistence(), c.TxManager(), c.PublicTxManager(), p.stateDistributer, p.preparedTransactionDistributer)
 
 	err := p.stateDistributer.Start(p.ctx)
 	if err != nil {
@@ -114,7 +126,7 @@ func (p *privateTxManager) Start() error {
 
 func (p *privateTxManager) Stop() {
 	p.stateDistributer.Stop(p.ctx)
-
+	p.preparedTransactionDistributer.Stop(p.ctx)
 }
 
 func NewPrivateTransactionMgr(ctx context.Context, config *pldconf.PrivateTxManagerConfig) components.PrivateTxManager {
@@ -123,6 +135,7 @@ func NewPrivateTransactionMgr(ctx context.Context, config *pldconf.PrivateTxMana
 		sequencers:           make(map[string]*Sequencer),
 		endorsementGatherers: make(map[string]ptmgrtypes.EndorsementGatherer),
 		subscribers:          make([]components.PrivateTxEventSubscriber, 0),
+		metrics:              newPrivateTxManagerMetrics(),
 	}
 	p.ctx, p.ctxCancel = context.WithCancel(ctx)
 	return p
@@ -185,10 +198,10 @@ func (p *privateTxManager) getSequencerForContract(ctx context.Context, dbTX *go
 				p.syncPoints,
 				p.components.IdentityResolver(),
 				p.stateDistributer,
-				p.preparedTransactionDistributer,
 				transportWriter,
 				confutil.DurationMin(p.config.RequestTimeout, 0, *pldconf.PrivateTxManagerDefaults.RequestTimeout),
 				p.blockHeight,
+				p.metrics,
 			)
 			if err != nil {
 				log.L(ctx).Errorf("Failed to create sequencer for contract %s: %s", contractAddr.String(), err)
@@ -565,6 +578,14 @@ func (p *privateTxManager) handleEndorsementRequest(ctx context.Context, message
 		return
 	}
 
+	// the context carries the span of the requesting node, so this endorsement joins its trace
+	ctx, span := tracer.Start(ctx, "privatetx.endorse_remote", trace.WithSpanKind(trace.SpanKindServer), trace.WithAttributes(
+		attribute.String("paladin.transaction_id", endorsementRequest.TransactionId),
+		attribute.String("paladin.party", endorsementRequest.Party),
+		attribute.String("paladin.reply_to", replyTo),
+	))
+	defer func() { tracing.EndSpan(span, err) }()
+
 	endorsementGatherer, err := p.getEndorsementGathererForContract(ctx, p.components.Persistence().DB(), *contractAddress)
 	if err != nil {
 		log.L(ctx).Errorf("Failed to get endorsement gatherer for contract address %s: %s", contractAddressString, err)
@@ -988,7 +1009,7 @@ func (p *privateTxManager) publishToSubscribers(ctx context.Context, event compo
 	}
 }
 
-func (p *privateTxManager) NotifyFailedPublicTx(ctx context.Context, dbTX *gorm.DB, failures []*components.PublicTxMatch) error {
+func (p *privateTxManager) NotifyFailedPublicTx(ctx context.Context, dbTX *gorm.DB, failures []*components.PublicTxMatch) (func(), error) {
 	// TODO: We have processing we need to do here to resubmit
 	// For now, we directly raise a failure receipt for them back with the main transaction manager
 	privateFailureReceipts := make([]*components.ReceiptInput, len(failures))
@@ -1024,18 +1045,9 @@ func (p *privateTxManager) PrivateTransactionConfirmed(ctx context.Context, rece
 	}
 }
 
-func (p *privateTxManager) handleStateProducedEvent(ctx context.Context, messagePayload []byte, replyToDestination string) {
-
-	//in the meantime, we share with the sequencer in memory in case that state is needed to assemble in flight transactions
-	stateProducedEvent := &pbEngine.StateProducedEvent{}
-	err := proto.Unmarshal(messagePayload, stateProducedEvent)
-	if err != nil {
-		log.L(ctx).Errorf("Failed to unmarshal StateProducedEvent: %s", err)
-		return
-	}
-
-	//State distributer deals with the reliable delivery e.g. sending acks etc
-	go p.stateDistributer.HandleStateProducedEvent(ctx, stateProducedEvent, replyToDestination)
+// The state distributer notifies us once a state from another node has been written, so we can
+// share it with the sequencer in memory in case that state is needed to assemble in flight transactions
+func (p *privateTxManager) handleStateReceived(ctx context.Context, stateProducedEvent *pbEngine.StateProducedEvent) {
 
 	contractAddressString := stateProducedEvent.ContractAddress
 	contractAddress := tktypes.MustEthAddress(contractAddressString)
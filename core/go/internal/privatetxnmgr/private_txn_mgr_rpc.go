@@ -0,0 +1,111 @@
+/*
+ * Copyright © 2024 Kaleido, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package privatetxnmgr
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/kaleido-io/paladin/core/internal/msgs"
+	"github.com/kaleido-io/paladin/toolkit/pkg/rpcserver"
+	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
+)
+
+func (p *privateTxManager) RPCModule() *rpcserver.RPCModule {
+	return p.rpcModule
+}
+
+func (p *privateTxManager) initRPC() {
+	p.rpcModule = rpcserver.NewRPCModule("admin").
+		Add("admin_failPrivateTransaction", p.rpcFailPrivateTransaction()).
+		Add("admin_reassemblePrivateTransaction", p.rpcReassemblePrivateTransaction()).
+		Add("admin_evictSequencer", p.rpcEvictSequencer())
+}
+
+func (p *privateTxManager) rpcFailPrivateTransaction() rpcserver.RPCHandler {
+	return rpcserver.RPCMethod3(func(ctx context.Context,
+		contractAddress tktypes.EthAddress,
+		txID uuid.UUID,
+		reason string,
+	) (bool, error) {
+		return true, p.FailTransaction(ctx, contractAddress, txID, reason)
+	})
+}
+
+func (p *privateTxManager) rpcReassemblePrivateTransaction() rpcserver.RPCHandler {
+	return rpcserver.RPCMethod2(func(ctx context.Context,
+		contractAddress tktypes.EthAddress,
+		txID uuid.UUID,
+	) (bool, error) {
+		return true, p.ReassembleTransaction(ctx, contractAddress, txID)
+	})
+}
+
+func (p *privateTxManager) rpcEvictSequencer() rpcserver.RPCHandler {
+	return rpcserver.RPCMethod2(func(ctx context.Context,
+		contractAddress tktypes.EthAddress,
+		reason string,
+	) ([]uuid.UUID, error) {
+		return p.EvictSequencer(ctx, contractAddress, reason)
+	})
+}
+
+func (p *privateTxManager) getActiveSequencer(ctx context.Context, contractAddress tktypes.EthAddress) (*Sequencer, error) {
+	p.sequencersLock.RLock()
+	defer p.sequencersLock.RUnlock()
+	s := p.sequencers[contractAddress.String()]
+	if s == nil {
+		return nil, i18n.NewError(ctx, msgs.MsgPrivateTxMgrSequencerNotActive, contractAddress)
+	}
+	return s, nil
+}
+
+// FailTransaction fails a transaction that is in flight, but has not been dispatched to the base ledger.
+// A failure receipt is written, and any states locked by the transaction are released.
+func (p *privateTxManager) FailTransaction(ctx context.Context, contractAddress tktypes.EthAddress, txID uuid.UUID, reason string) error {
+	if reason == "" {
+		return i18n.NewError(ctx, msgs.MsgPrivateTxMgrAdminReasonRequired)
+	}
+	s, err := p.getActiveSequencer(ctx, contractAddress)
+	if err != nil {
+		return err
+	}
+	return s.FailTransaction(ctx, txID, i18n.ExpandWithCode(ctx, i18n.MessageKey(msgs.MsgPrivateTxMgrFailedByAdmin), reason))
+}
+
+// ReassembleTransaction discards the current assembly of an undispatched transaction, releasing its
+// state locks, so that it is assembled again on the next sequencer evaluation.
+func (p *privateTxManager) ReassembleTransaction(ctx context.Context, contractAddress tktypes.EthAddress, txID uuid.UUID) error {
+	s, err := p.getActiveSequencer(ctx, contractAddress)
+	if err != nil {
+		return err
+	}
+	return s.ReassembleTransaction(ctx, txID)
+}
+
+// EvictSequencer stops the sequencer for a contract, failing all of its undispatched transactions.
+// A new sequencer is started when the next transaction arrives for the contract.
+func (p *privateTxManager) EvictSequencer(ctx context.Context, contractAddress tktypes.EthAddress, reason string) ([]uuid.UUID, error) {
+	if reason == "" {
+		return nil, i18n.NewError(ctx, msgs.MsgPrivateTxMgrAdminReasonRequired)
+	}
+	s, err := p.getActiveSequencer(ctx, contractAddress)
+	if err != nil {
+		return nil, err
+	}
+	return s.Evict(ctx, i18n.ExpandWithCode(ctx, i18n.MessageKey(msgs.MsgPrivateTxMgrSequencerEvicted), reason))
+}
@@ -0,0 +1,115 @@
+/*
+ * Copyright © 2024 Kaleido, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package privatetxnmgr
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/google/uuid"
+	"github.com/kaleido-io/paladin/config/pkg/confutil"
+	"github.com/kaleido-io/paladin/config/pkg/pldconf"
+	"github.com/kaleido-io/paladin/toolkit/pkg/rpcclient"
+	"github.com/kaleido-io/paladin/toolkit/pkg/rpcserver"
+	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRPCServer(t *testing.T, ctx context.Context, p *privateTxManager) (rpcclient.Client, func()) {
+
+	s, err := rpcserver.NewRPCServer(ctx, &pldconf.RPCServerConfig{
+		HTTP: pldconf.RPCServerConfigHTTP{
+			HTTPServerConfig: pldconf.HTTPServerConfig{Address: confutil.P("127.0.0.1"), Port: confutil.P(0)},
+		},
+		WS: pldconf.RPCServerConfigWS{Disabled: true},
+	})
+	require.NoError(t, err)
+	err = s.Start()
+	require.NoError(t, err)
+
+	p.initRPC()
+	s.Register(p.RPCModule())
+
+	c := rpcclient.WrapRestyClient(resty.New().SetBaseURL(fmt.Sprintf("http://%s", s.HTTPAddr())))
+
+	return c, s.Stop
+
+}
+
+func TestRPCAdminNoActiveSequencer(t *testing.T) {
+	ctx := context.Background()
+	p := NewPrivateTransactionMgr(ctx, &pldconf.PrivateTxManagerConfig{}).(*privateTxManager)
+
+	rpc, rpcDone := newTestRPCServer(t, ctx, p)
+	defer rpcDone()
+
+	contractAddr := tktypes.RandAddress()
+	var ok bool
+	err := rpc.CallRPC(ctx, &ok, "admin_failPrivateTransaction", contractAddr, uuid.New(), "stuck")
+	assert.Regexp(t, "PD011839", err)
+
+	err = rpc.CallRPC(ctx, &ok, "admin_reassemblePrivateTransaction", contractAddr, uuid.New())
+	assert.Regexp(t, "PD011839", err)
+
+	var failed []uuid.UUID
+	err = rpc.CallRPC(ctx, &failed, "admin_evictSequencer", contractAddr, "stuck")
+	assert.Regexp(t, "PD011839", err)
+}
+
+func TestRPCAdminReasonRequired(t *testing.T) {
+	ctx := context.Background()
+	p := NewPrivateTransactionMgr(ctx, &pldconf.PrivateTxManagerConfig{}).(*privateTxManager)
+
+	rpc, rpcDone := newTestRPCServer(t, ctx, p)
+	defer rpcDone()
+
+	var ok bool
+	err := rpc.CallRPC(ctx, &ok, "admin_failPrivateTransaction", tktypes.RandAddress(), uuid.New(), "")
+	assert.Regexp(t, "PD011842", err)
+
+	var failed []uuid.UUID
+	err = rpc.CallRPC(ctx, &failed, "admin_evictSequencer", tktypes.RandAddress(), "")
+	assert.Regexp(t, "PD011842", err)
+}
+
+func TestRPCAdminEvictSequencer(t *testing.T) {
+	ctx := context.Background()
+	p := NewPrivateTransactionMgr(ctx, &pldconf.PrivateTxManagerConfig{}).(*privateTxManager)
+
+	contractAddr := tktypes.RandAddress()
+	s, mocks, sDone := newSequencerForTesting(t, ctx, contractAddr)
+	defer sDone()
+	p.sequencers[contractAddr.String()] = s
+
+	mocks.txManager.On("FinalizeTransactions", mock.Anything, mock.Anything, mock.Anything).Return(func() {}, nil)
+	mocks.domainContext.On("Close").Return()
+
+	rpc, rpcDone := newTestRPCServer(t, ctx, p)
+	defer rpcDone()
+
+	var ok bool
+	err := rpc.CallRPC(ctx, &ok, "admin_failPrivateTransaction", contractAddr, uuid.New(), "stuck")
+	assert.Regexp(t, "PD011840", err)
+
+	var failed []uuid.UUID
+	err = rpc.CallRPC(ctx, &failed, "admin_evictSequencer", contractAddr, "stuck")
+	require.NoError(t, err)
+	assert.Empty(t, failed)
+}
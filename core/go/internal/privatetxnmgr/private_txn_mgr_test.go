@@ -42,6 +42,7 @@ import (
 	"gorm.io/gorm"
 
 	"github.com/kaleido-io/paladin/core/internal/components"
+	"github.com/kaleido-io/paladin/core/internal/metrics"
 	"github.com/kaleido-io/paladin/core/mocks/componentmocks"
 	"github.com/kaleido-io/paladin/core/pkg/blockindexer"
 	"github.com/kaleido-io/paladin/core/pkg/persistence"
@@ -2107,7 +2108,7 @@ func TestPrivateTxManagerDeploy(t *testing.T) {
 
 	dispatched := mockWritePublicTxsOk(mocks)
 
-	mocks.txManager.On("FinalizeTransactions", mock.Anything, mock.Anything, mock.Anything).Return(nil).Panic("did not expect transaction to be reverted").Maybe()
+	mocks.txManager.On("FinalizeTransactions", mock.Anything, mock.Anything, mock.Anything).Return(func() {}, nil).Panic("did not expect transaction to be reverted").Maybe()
 
 	err = privateTxManager.Start()
 	require.NoError(t, err)
@@ -2251,7 +2252,7 @@ func TestPrivateTxManagerDeployFailPrepare(t *testing.T) {
 				reverted <- args.Get(2).([]*components.ReceiptInput)
 			},
 		).
-		Return(nil)
+		Return(func() {}, nil)
 
 	err := privateTxManager.Start()
 	require.NoError(t, err)
@@ -2337,7 +2338,7 @@ func TestPrivateTxManagerFailSignerResolve(t *testing.T) {
 				reverted <- args.Get(2).([]*components.ReceiptInput)
 			},
 		).
-		Return(nil)
+		Return(func() {}, nil)
 
 	err = privateTxManager.Start()
 	require.NoError(t, err)
@@ -2412,7 +2413,7 @@ func TestPrivateTxManagerDeployFailNoInvokeOrDeploy(t *testing.T) {
 				reverted <- args.Get(2).([]*components.ReceiptInput)
 			},
 		).
-		Return(nil)
+		Return(func() {}, nil)
 
 	err := privateTxManager.Start()
 	require.NoError(t, err)
@@ -2854,6 +2855,7 @@ func NewPrivateTransactionMgrForPackageTesting(t *testing.T, nodeName string) (p
 	mocks.transportManager.On("RegisterClient", mock.Anything, mock.Anything).Return(nil).Maybe()
 	//It is not valid to reference LateBound components before PostInit
 	mocks.allComponents.On("IdentityResolver").Return(mocks.identityResolver).Maybe()
+	mocks.preInitComponents.On("MetricsManager").Return(metrics.NewMetricsManager(ctx))
 	preInitResult, err := e.PreInit(mocks.preInitComponents)
 	assert.NoError(t, err)
 	postCommitHandler, err := preInitResult.PreCommitHandler(
@@ -168,6 +168,17 @@ type TransactionNudgeEvent struct {
 	PrivateTransactionEventBase
 }
 
+// an administrator has requested the transaction be failed, without waiting for it to complete
+type TransactionForceFailedEvent struct {
+	PrivateTransactionEventBase
+	FailureMessage string
+}
+
+// an administrator has requested the current assembly be discarded, and the transaction re-assembled
+type TransactionReassembleEvent struct {
+	PrivateTransactionEventBase
+}
+
 type TransactionFinalizeError struct {
 	PrivateTransactionEventBase
 	RevertReason string // reason we were trying to finalize the transaction
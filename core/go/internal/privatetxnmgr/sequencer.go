@@ -27,7 +27,6 @@ import (
 	"github.com/kaleido-io/paladin/config/pkg/pldconf"
 	"github.com/kaleido-io/paladin/core/internal/components"
 	"github.com/kaleido-io/paladin/core/internal/msgs"
-	"github.com/kaleido-io/paladin/core/internal/preparedtxdistribution"
 	"github.com/kaleido-io/paladin/core/internal/privatetxnmgr/ptmgrtypes"
 	"github.com/kaleido-io/paladin/core/internal/privatetxnmgr/syncpoints"
 	"github.com/kaleido-io/paladin/core/internal/statedistribution"
@@ -109,26 +108,26 @@ type Sequencer struct {
 
 	pendingTransactionEvents chan ptmgrtypes.PrivateTransactionEvent
 
-	contractAddress                tktypes.EthAddress // the contract address managed by the current sequencer
-	defaultSigner                  string
-	nodeName                       string
-	domainAPI                      components.DomainSmartContract
-	coordinatorDomainContext       components.DomainContext
-	delegateDomainContext          components.DomainContext
-	components                     components.AllComponents
-	endorsementGatherer            ptmgrtypes.EndorsementGatherer
-	publisher                      ptmgrtypes.Publisher
-	identityResolver               components.IdentityResolver
-	syncPoints                     syncpoints.SyncPoints
-	stateDistributer               statedistribution.StateDistributer
-	preparedTransactionDistributer preparedtxdistribution.PreparedTransactionDistributer
-	transportWriter                ptmgrtypes.TransportWriter
-	graph                          Graph
-	requestTimeout                 time.Duration
-	coordinatorSelector            ptmgrtypes.CoordinatorSelector
-	newBlockEvents                 chan int64
-	assembleCoordinator            ptmgrtypes.AssembleCoordinator
-	environment                    *sequencerEnvironment
+	contractAddress          tktypes.EthAddress // the contract address managed by the current sequencer
+	defaultSigner            string
+	nodeName                 string
+	domainAPI                components.DomainSmartContract
+	coordinatorDomainContext components.DomainContext
+	delegateDomainContext    components.DomainContext
+	components               components.AllComponents
+	endorsementGatherer      ptmgrtypes.EndorsementGatherer
+	publisher                ptmgrtypes.Publisher
+	identityResolver         components.IdentityResolver
+	syncPoints               syncpoints.SyncPoints
+	stateDistributer         statedistribution.StateDistributer
+	transportWriter          ptmgrtypes.TransportWriter
+	graph                    Graph
+	requestTimeout           time.Duration
+	coordinatorSelector      ptmgrtypes.CoordinatorSelector
+	newBlockEvents           chan int64
+	assembleCoordinator      ptmgrtypes.AssembleCoordinator
+	environment              *sequencerEnvironment
+	metrics                  *privateTxManagerMetrics
 }
 
 func NewSequencer(
@@ -144,10 +143,10 @@ func NewSequencer(
 	syncPoints syncpoints.SyncPoints,
 	identityResolver components.IdentityResolver,
 	stateDistributer statedistribution.StateDistributer,
-	preparedTransactionDistributer preparedtxdistribution.PreparedTransactionDistributer,
 	transportWriter ptmgrtypes.TransportWriter,
 	requestTimeout time.Duration,
 	blockHeight int64,
+	metrics *privateTxManagerMetrics,
 
 ) (*Sequencer, error) {
 
@@ -164,23 +163,23 @@ func NewSequencer(
 		incompleteTxSProcessMap: make(map[string]ptmgrtypes.TransactionFlow),
 		persistenceRetryTimeout: confutil.DurationMin(sequencerConfig.PersistenceRetryTimeout, 1*time.Millisecond, *pldconf.PrivateTxManagerDefaults.Sequencer.PersistenceRetryTimeout),
 
-		staleTimeout:                   confutil.DurationMin(sequencerConfig.StaleTimeout, 1*time.Millisecond, *pldconf.PrivateTxManagerDefaults.Sequencer.StaleTimeout),
-		processedTxIDs:                 make(map[string]bool),
-		orchestrationEvalRequestChan:   make(chan bool, 1),
-		stopProcess:                    make(chan bool, 1),
-		pendingTransactionEvents:       make(chan ptmgrtypes.PrivateTransactionEvent, *pldconf.PrivateTxManagerDefaults.Sequencer.MaxPendingEvents),
-		nodeName:                       nodeName,
-		domainAPI:                      domainAPI,
-		components:                     allComponents,
-		endorsementGatherer:            endorsementGatherer,
-		publisher:                      publisher,
-		syncPoints:                     syncPoints,
-		identityResolver:               identityResolver,
-		stateDistributer:               stateDistributer,
-		preparedTransactionDistributer: preparedTransactionDistributer,
-		transportWriter:                transportWriter,
-		graph:                          NewGraph(),
-		requestTimeout:                 requestTimeout,
+		staleTimeout:                 confutil.DurationMin(sequencerConfig.StaleTimeout, 1*time.Millisecond, *pldconf.PrivateTxManagerDefaults.Sequencer.StaleTimeout),
+		processedTxIDs:               make(map[string]bool),
+		orchestrationEvalRequestChan: make(chan bool, 1),
+		stopProcess:                  make(chan bool, 1),
+		pendingTransactionEvents:     make(chan ptmgrtypes.PrivateTransactionEvent, *pldconf.PrivateTxManagerDefaults.Sequencer.MaxPendingEvents),
+		nodeName:                     nodeName,
+		domainAPI:                    domainAPI,
+		components:                   allComponents,
+		endorsementGatherer:          endorsementGatherer,
+		publisher:                    publisher,
+		syncPoints:                   syncPoints,
+		identityResolver:             identityResolver,
+		stateDistributer:             stateDistributer,
+		transportWriter:              transportWriter,
+		graph:                        NewGraph(),
+		requestTimeout:               requestTimeout,
+		metrics:                      metrics,
 		environment: &sequencerEnvironment{
 			blockHeight: blockHeight,
 		},
@@ -254,6 +253,12 @@ func (s *Sequencer) removeTransactionProcessor(txID string) {
 	delete(s.incompleteTxSProcessMap, txID)
 }
 
+func (s *Sequencer) incompleteTxCount() int {
+	s.incompleteTxProcessMapMutex.Lock()
+	defer s.incompleteTxProcessMapMutex.Unlock()
+	return len(s.incompleteTxSProcessMap)
+}
+
 func (s *Sequencer) OnNewBlockHeight(ctx context.Context, blockHeight int64) {
 	log.L(ctx).Debugf("Sequencer OnNewBlockHeight %d", blockHeight)
 	s.environment.blockHeight = blockHeight
@@ -269,7 +274,7 @@ func (s *Sequencer) ProcessNewTransaction(ctx context.Context, tx *components.Pr
 			// tx processing pool is full, queue the item
 			return true
 		} else {
-			s.incompleteTxSProcessMap[tx.ID.String()] = NewTransactionFlow(ctx, tx, s.nodeName, s.components, s.domainAPI, s.coordinatorDomainContext, s.publisher, s.endorsementGatherer, s.identityResolver, s.syncPoints, s.transportWriter, s.requestTimeout, s.coordinatorSelector, s.assembleCoordinator, s.environment)
+			s.incompleteTxSProcessMap[tx.ID.String()] = NewTransactionFlow(ctx, tx, s.nodeName, s.components, s.domainAPI, s.coordinatorDomainContext, s.publisher, s.endorsementGatherer, s.identityResolver, s.syncPoints, s.transportWriter, s.requestTimeout, s.coordinatorSelector, s.assembleCoordinator, s.environment, s.metrics)
 		}
 		s.pendingTransactionEvents <- &ptmgrtypes.TransactionSubmittedEvent{
 			PrivateTransactionEventBase: ptmgrtypes.PrivateTransactionEventBase{TransactionID: tx.ID.String()},
@@ -304,7 +309,7 @@ func (s *Sequencer) ProcessInFlightTransaction(ctx context.Context, tx *componen
 			// tx processing pool is full, queue the item
 			return true
 		} else {
-			s.incompleteTxSProcessMap[tx.ID.String()] = NewTransactionFlow(ctx, tx, s.nodeName, s.components, s.domainAPI, s.coordinatorDomainContext, s.publisher, s.endorsementGatherer, s.identityResolver, s.syncPoints, s.transportWriter, s.requestTimeout, s.coordinatorSelector, s.assembleCoordinator, s.environment)
+			s.incompleteTxSProcessMap[tx.ID.String()] = NewTransactionFlow(ctx, tx, s.nodeName, s.components, s.domainAPI, s.coordinatorDomainContext, s.publisher, s.endorsementGatherer, s.identityResolver, s.syncPoints, s.transportWriter, s.requestTimeout, s.coordinatorSelector, s.assembleCoordinator, s.environment, s.metrics)
 		}
 		s.pendingTransactionEvents <- &ptmgrtypes.TransactionSwappedInEvent{
 			PrivateTransactionEventBase: ptmgrtypes.PrivateTransactionEventBase{TransactionID: tx.ID.String()},
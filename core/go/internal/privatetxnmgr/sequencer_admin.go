@@ -0,0 +1,113 @@
+/*
+ * Copyright © 2024 Kaleido, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package privatetxnmgr
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/kaleido-io/paladin/core/internal/components"
+	"github.com/kaleido-io/paladin/core/internal/msgs"
+	"github.com/kaleido-io/paladin/core/internal/privatetxnmgr/ptmgrtypes"
+	"github.com/kaleido-io/paladin/toolkit/pkg/log"
+	"gorm.io/gorm"
+)
+
+/*
+ * Administrative actions on a sequencer, for resolving transactions that are stuck in flight.
+ * Actions on individual transactions are delivered as events, so they are applied on the event loop.
+ */
+
+func (s *Sequencer) getUndispatchedTransaction(ctx context.Context, txID uuid.UUID) (ptmgrtypes.TransactionFlow, error) {
+	txProc := s.getTransactionProcessor(txID.String())
+	if txProc == nil {
+		return nil, i18n.NewError(ctx, msgs.MsgPrivateTxMgrTxNotInFlight, txID, s.contractAddress)
+	}
+	if txProc.Dispatched(ctx) {
+		return nil, i18n.NewError(ctx, msgs.MsgPrivateTxMgrTxAlreadyDispatched, txID)
+	}
+	return txProc, nil
+}
+
+func (s *Sequencer) FailTransaction(ctx context.Context, txID uuid.UUID, failureMessage string) error {
+	if _, err := s.getUndispatchedTransaction(ctx, txID); err != nil {
+		return err
+	}
+	s.HandleEvent(ctx, &ptmgrtypes.TransactionForceFailedEvent{
+		PrivateTransactionEventBase: ptmgrtypes.PrivateTransactionEventBase{
+			TransactionID:   txID.String(),
+			ContractAddress: s.contractAddress.String(),
+		},
+		FailureMessage: failureMessage,
+	})
+	return nil
+}
+
+func (s *Sequencer) ReassembleTransaction(ctx context.Context, txID uuid.UUID) error {
+	if _, err := s.getUndispatchedTransaction(ctx, txID); err != nil {
+		return err
+	}
+	s.HandleEvent(ctx, &ptmgrtypes.TransactionReassembleEvent{
+		PrivateTransactionEventBase: ptmgrtypes.PrivateTransactionEventBase{
+			TransactionID:   txID.String(),
+			ContractAddress: s.contractAddress.String(),
+		},
+	})
+	return nil
+}
+
+// Evict stops the sequencer and fails every transaction it holds in memory that has not been dispatched
+// to the base ledger, writing a failure receipt for each. The domain contexts are closed, releasing all
+// state locks. Dispatched transactions are left to complete on the base ledger.
+func (s *Sequencer) Evict(ctx context.Context, failureMessage string) ([]uuid.UUID, error) {
+	s.Stop()
+	<-s.sequencerLoopDone
+
+	s.incompleteTxProcessMapMutex.Lock()
+	failedTxIDs := make([]uuid.UUID, 0, len(s.incompleteTxSProcessMap))
+	for _, txProc := range s.incompleteTxSProcessMap {
+		if !txProc.Dispatched(ctx) && !txProc.IsComplete(ctx) {
+			failedTxIDs = append(failedTxIDs, txProc.ID(ctx))
+		}
+	}
+	s.incompleteTxSProcessMap = make(map[string]ptmgrtypes.TransactionFlow)
+	s.incompleteTxProcessMapMutex.Unlock()
+
+	receipts := make([]*components.ReceiptInput, len(failedTxIDs))
+	for i, txID := range failedTxIDs {
+		receipts[i] = &components.ReceiptInput{
+			ReceiptType:    components.RT_FailedWithMessage,
+			Domain:         s.domainAPI.Domain().Name(),
+			TransactionID:  txID,
+			FailureMessage: failureMessage,
+		}
+	}
+	var postCommit func()
+	err := s.components.Persistence().DB().Transaction(func(dbTX *gorm.DB) (err error) {
+		postCommit, err = s.components.TxManager().FinalizeTransactions(ctx, dbTX, receipts)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	postCommit()
+
+	s.coordinatorDomainContext.Close()
+	s.delegateDomainContext.Close()
+	log.L(ctx).Warnf("Sequencer for contract %s evicted, failing %d transactions: %s", s.contractAddress, len(failedTxIDs), failureMessage)
+	return failedTxIDs, nil
+}
@@ -27,15 +27,24 @@ import (
 	"github.com/kaleido-io/paladin/core/internal/preparedtxdistribution"
 	"github.com/kaleido-io/paladin/core/internal/privatetxnmgr/ptmgrtypes"
 	"github.com/kaleido-io/paladin/core/internal/privatetxnmgr/syncpoints"
+	"github.com/kaleido-io/paladin/core/internal/tracing"
 
 	"github.com/kaleido-io/paladin/toolkit/pkg/log"
 	"github.com/kaleido-io/paladin/toolkit/pkg/pldapi"
 	"github.com/kaleido-io/paladin/toolkit/pkg/prototk"
 	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // synchronously prepare and dispatch all given transactions to their associated signing address / or deliver prepared transaction to their custodian
-func (s *Sequencer) DispatchTransactions(ctx context.Context, dispatchableTransactions ptmgrtypes.DispatchableTransactions) error {
+func (s *Sequencer) DispatchTransactions(ctx context.Context, dispatchableTransactions ptmgrtypes.DispatchableTransactions) (err error) {
+	ctx, span := tracer.Start(ctx, "privatetx.dispatch")
+	span.SetAttributes(
+		attribute.String("paladin.contract_address", s.contractAddress.String()),
+		attribute.StringSlice("paladin.transaction_ids", dispatchableTransactions.IDs(ctx)),
+	)
+	defer func() { tracing.EndSpan(span, err) }()
+
 	log.L(ctx).Debug("DispatchTransactions")
 	//prepare all transactions then dispatch them
 
@@ -200,11 +209,6 @@ func (s *Sequencer) DispatchTransactions(ctx context.Context, dispatchableTransa
 	for _, preparedTransaction := range dispatchBatch.PreparedTransactions {
 		s.publisher.PublishTransactionPreparedEvent(ctx, preparedTransaction.ID.String())
 	}
-	//now that the DB write has been persisted, we can trigger the in-memory distribution of the prepared transactions and states
-	s.stateDistributer.DistributeStates(ctx, stateDistributions)
-
-	s.preparedTransactionDistributer.DistributePreparedTransactions(ctx, preparedTxnDistributions)
-
 	// We also need to trigger ourselves for any private TX we chained
 	for _, tx := range dispatchBatch.PrivateDispatches {
 		if err := s.privateTxManager.HandleNewTx(ctx, s.components.Persistence().DB(), tx); err != nil {
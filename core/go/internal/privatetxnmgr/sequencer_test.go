@@ -24,6 +24,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/kaleido-io/paladin/config/pkg/pldconf"
 	"github.com/kaleido-io/paladin/core/internal/components"
+	"github.com/kaleido-io/paladin/core/internal/privatetxnmgr/ptmgrtypes"
 	"github.com/kaleido-io/paladin/core/internal/privatetxnmgr/syncpoints"
 	"github.com/kaleido-io/paladin/core/mocks/componentmocks"
 	"github.com/kaleido-io/paladin/core/mocks/preparedtxdistributionmocks"
@@ -103,8 +104,8 @@ func newSequencerForTesting(t *testing.T, ctx context.Context, domainAddress *tk
 	mocks.stateStore.On("NewDomainContext", mock.Anything, mocks.domain, *domainAddress, mock.Anything).Return(mocks.domainContext).Maybe()
 	//mocks.domain.On("Configuration").Return(&prototk.DomainConfig{}).Maybe()
 
-	syncPoints := syncpoints.NewSyncPoints(ctx, &pldconf.FlushWriterConfig{}, p, mocks.txManager, mocks.pubTxManager)
-	o, err := NewSequencer(ctx, mocks.privateTxManager, tktypes.RandHex(16), *domainAddress, &pldconf.PrivateTxManagerSequencerConfig{}, mocks.allComponents, mocks.domainSmartContract, mocks.endorsementGatherer, mocks.publisher, syncPoints, mocks.identityResolver, mocks.stateDistributer, mocks.preparedTransactionDistributer, mocks.transportWriter, 30*time.Second, 0)
+	syncPoints := syncpoints.NewSyncPoints(ctx, &pldconf.FlushWriterConfig{}, p, mocks.txManager, mocks.pubTxManager, mocks.stateDistributer, mocks.preparedTransactionDistributer)
+	o, err := NewSequencer(ctx, mocks.privateTxManager, tktypes.RandHex(16), *domainAddress, &pldconf.PrivateTxManagerSequencerConfig{}, mocks.allComponents, mocks.domainSmartContract, mocks.endorsementGatherer, mocks.publisher, syncPoints, mocks.identityResolver, mocks.stateDistributer, mocks.transportWriter, 30*time.Second, 0, newPrivateTxManagerMetrics())
 	require.NoError(t, err)
 	ocDone, err := o.Start(ctx)
 	require.NoError(t, err)
@@ -209,3 +210,88 @@ func TestSequencerPollingLoopCancelContext(t *testing.T) {
 
 	cancel()
 }
+
+func TestSequencerEvict(t *testing.T) {
+
+	ctx := context.Background()
+	testOc, dependencyMocks, ocDone := newSequencerForTesting(t, ctx, nil)
+	defer ocDone()
+
+	undispatchedTxID := uuid.New()
+	undispatched := ptmgrtypes.NewMockTransactionFlow(t)
+	undispatched.On("Dispatched", mock.Anything).Return(false)
+	undispatched.On("IsComplete", mock.Anything).Return(false)
+	undispatched.On("ID", mock.Anything).Return(undispatchedTxID)
+	dispatched := ptmgrtypes.NewMockTransactionFlow(t)
+	dispatched.On("Dispatched", mock.Anything).Return(true)
+	testOc.incompleteTxSProcessMap[undispatchedTxID.String()] = undispatched
+	testOc.incompleteTxSProcessMap[uuid.New().String()] = dispatched
+
+	dependencyMocks.domain.On("Name").Return("domain1")
+	dependencyMocks.txManager.On("FinalizeTransactions", mock.Anything, mock.Anything, mock.MatchedBy(func(receipts []*components.ReceiptInput) bool {
+		return len(receipts) == 1 &&
+			receipts[0].TransactionID == undispatchedTxID &&
+			receipts[0].ReceiptType == components.RT_FailedWithMessage &&
+			receipts[0].Domain == "domain1" &&
+			receipts[0].FailureMessage == "evicted"
+	})).Return(func() {}, nil)
+	dependencyMocks.domainContext.On("Close").Return().Twice()
+
+	failed, err := testOc.Evict(ctx, "evicted")
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{undispatchedTxID}, failed)
+	assert.Empty(t, testOc.incompleteTxSProcessMap)
+
+}
+
+func TestSequencerEvictFinalizeFail(t *testing.T) {
+
+	ctx := context.Background()
+	testOc, dependencyMocks, ocDone := newSequencerForTesting(t, ctx, nil)
+	defer ocDone()
+
+	dependencyMocks.txManager.On("FinalizeTransactions", mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New("pop"))
+
+	_, err := testOc.Evict(ctx, "evicted")
+	assert.Regexp(t, "pop", err)
+
+}
+
+func TestSequencerAdminTransactionNotInFlight(t *testing.T) {
+
+	ctx := context.Background()
+	testOc, _, ocDone := newSequencerForTesting(t, ctx, nil)
+	defer func() {
+		testOc.Stop()
+		ocDone()
+	}()
+
+	err := testOc.FailTransaction(ctx, uuid.New(), "failed")
+	assert.Regexp(t, "PD011840", err)
+
+	err = testOc.ReassembleTransaction(ctx, uuid.New())
+	assert.Regexp(t, "PD011840", err)
+
+}
+
+func TestSequencerAdminTransactionDispatched(t *testing.T) {
+
+	ctx := context.Background()
+	testOc, _, ocDone := newSequencerForTesting(t, ctx, nil)
+	defer func() {
+		testOc.Stop()
+		ocDone()
+	}()
+
+	txID := uuid.New()
+	dispatched := ptmgrtypes.NewMockTransactionFlow(t)
+	dispatched.On("Dispatched", mock.Anything).Return(true)
+	testOc.incompleteTxSProcessMap[txID.String()] = dispatched
+
+	err := testOc.FailTransaction(ctx, txID, "failed")
+	assert.Regexp(t, "PD011841", err)
+
+	err = testOc.ReassembleTransaction(ctx, txID)
+	assert.Regexp(t, "PD011841", err)
+
+}
@@ -22,7 +22,6 @@ import (
 	"github.com/google/uuid"
 	"github.com/kaleido-io/paladin/core/internal/components"
 	"github.com/kaleido-io/paladin/core/internal/preparedtxdistribution"
-	"github.com/kaleido-io/paladin/core/internal/statedistribution"
 	"github.com/kaleido-io/paladin/toolkit/pkg/log"
 	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
 	"gorm.io/gorm"
@@ -33,8 +32,8 @@ type dispatchOperation struct {
 	publicDispatches         []*PublicDispatch
 	privateDispatches        []*components.ValidatedTransaction
 	preparedTransactions     []*components.PrepareTransactionWithRefs
-	preparedTxnDistributions []*preparedtxdistribution.PreparedTxnDistributionPersisted
-	stateDistributions       []*statedistribution.StateDistributionPersisted
+	preparedTxnDistributions []*preparedtxdistribution.PreparedTxnDistribution
+	stateDistributions       []*components.StateDistribution
 }
 
 type DispatchPersisted struct {
@@ -62,28 +61,6 @@ type DispatchBatch struct {
 // to submit public transactions.
 func (s *syncPoints) PersistDispatchBatch(dCtx components.DomainContext, contractAddress tktypes.EthAddress, dispatchBatch *DispatchBatch, stateDistributions []*components.StateDistribution, preparedTxnDistributions []*preparedtxdistribution.PreparedTxnDistribution) error {
 
-	stateDistributionsPersisted := make([]*statedistribution.StateDistributionPersisted, 0, len(stateDistributions))
-	for _, stateDistribution := range stateDistributions {
-		stateDistributionsPersisted = append(stateDistributionsPersisted, &statedistribution.StateDistributionPersisted{
-			ID:              stateDistribution.ID,
-			StateID:         tktypes.MustParseHexBytes(stateDistribution.StateID),
-			IdentityLocator: stateDistribution.IdentityLocator,
-			DomainName:      stateDistribution.Domain,
-			ContractAddress: *tktypes.MustEthAddress(stateDistribution.ContractAddress),
-		})
-	}
-
-	preparedTxnDistributionsPersisted := make([]*preparedtxdistribution.PreparedTxnDistributionPersisted, 0, len(dispatchBatch.PreparedTransactions))
-	for _, preparedTxnDistribution := range preparedTxnDistributions {
-		preparedTxnDistributionsPersisted = append(preparedTxnDistributionsPersisted, &preparedtxdistribution.PreparedTxnDistributionPersisted{
-			ID:              preparedTxnDistribution.ID,
-			PreparedTxnID:   preparedTxnDistribution.PreparedTxnID,
-			IdentityLocator: preparedTxnDistribution.IdentityLocator,
-			DomainName:      preparedTxnDistribution.Domain,
-			ContractAddress: preparedTxnDistribution.ContractAddress,
-		})
-	}
-
 	// Send the write operation with all of the batch sequence operations to the flush worker
 	op := s.writer.Queue(dCtx.Ctx(), &syncPointOperation{
 		domainContext:   dCtx,
@@ -92,8 +69,8 @@ func (s *syncPoints) PersistDispatchBatch(dCtx components.DomainContext, contrac
 			publicDispatches:         dispatchBatch.PublicDispatches,
 			privateDispatches:        dispatchBatch.PrivateDispatches,
 			preparedTransactions:     dispatchBatch.PreparedTransactions,
-			preparedTxnDistributions: preparedTxnDistributionsPersisted,
-			stateDistributions:       stateDistributionsPersisted,
+			preparedTxnDistributions: preparedTxnDistributions,
+			stateDistributions:       stateDistributions,
 		},
 	})
 
@@ -197,45 +174,25 @@ func (s *syncPoints) writeDispatchOperations(ctx context.Context, dbTX *gorm.DB,
 			log.L(ctx).Debug("No prepared transaction distributions to persist")
 		} else {
 
-			log.L(ctx).Debugf("Writing distribution record to send prepared transaction to remote node %d", len(op.preparedTxnDistributions))
-			err := dbTX.
-				Table("prepared_txn_distributions").
-				Clauses(clause.OnConflict{
-					Columns: []clause.Column{
-						{Name: "prepared_txn_id"},
-						{Name: "identity_locator"},
-					},
-					DoNothing: true, // immutable
-				}).
-				Create(op.preparedTxnDistributions).
-				Error
-
+			log.L(ctx).Debugf("Queuing reliable messages to send prepared transaction to remote node %d", len(op.preparedTxnDistributions))
+			sendPostCommit, err := s.preparedTxnDistributer.SendPreparedTransactions(ctx, dbTX, op.preparedTxnDistributions)
 			if err != nil {
 				log.L(ctx).Errorf("Error persisting prepared transaction distributions: %s", err)
 				return nil, err
 			}
+			postCommits = append(postCommits, sendPostCommit)
 		}
 
 		if len(op.stateDistributions) == 0 {
 			log.L(ctx).Debug("No state distributions to persist")
 		} else {
-			log.L(ctx).Debugf("Writing state distributions %d", len(op.stateDistributions))
-			err := dbTX.
-				Table("state_distributions").
-				Clauses(clause.OnConflict{
-					Columns: []clause.Column{
-						{Name: "state_id"},
-						{Name: "identity_locator"},
-					},
-					DoNothing: true, // immutable
-				}).
-				Create(op.stateDistributions).
-				Error
-
+			log.L(ctx).Debugf("Queuing reliable messages to send states to remote nodes %d", len(op.stateDistributions))
+			sendPostCommit, err := s.stateDistributer.SendStateDistributions(ctx, dbTX, op.stateDistributions)
 			if err != nil {
 				log.L(ctx).Errorf("Error persisting state distributions: %s", err)
 				return nil, err
 			}
+			postCommits = append(postCommits, sendPostCommit)
 		}
 
 	}
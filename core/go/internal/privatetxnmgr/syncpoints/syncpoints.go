@@ -25,6 +25,7 @@ import (
 	"github.com/kaleido-io/paladin/core/internal/components"
 	"github.com/kaleido-io/paladin/core/internal/flushwriter"
 	"github.com/kaleido-io/paladin/core/internal/preparedtxdistribution"
+	"github.com/kaleido-io/paladin/core/internal/statedistribution"
 
 	"github.com/kaleido-io/paladin/core/pkg/persistence"
 	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
@@ -48,7 +49,8 @@ type SyncPoints interface {
 
 	// PersistDispatchSequence takes a DispatchBatch and for each sequence in the batch, it integrates
 	// with the PublicTxManager to record the transactions in its persistence store and also writes the dispatch
-	// to the PrivateTxnManager's persistence store in the same database transaction
+	// to the PrivateTxnManager's persistence store in the same database transaction, along with the reliable messages
+	// that distribute any states and prepared transactions to their recipients
 	// Although the actual persistence is offloaded to the flushwriter, this method is synchronous and will block until the
 	// dispatch sequence is written to the database
 	PersistDispatchBatch(dCtx components.DomainContext, contractAddress tktypes.EthAddress, dispatchBatch *DispatchBatch, stateDistributions []*components.StateDistribution, preparedTxnDistributions []*preparedtxdistribution.PreparedTxnDistribution) error
@@ -65,18 +67,22 @@ type SyncPoints interface {
 }
 
 type syncPoints struct {
-	started  bool
-	writer   flushwriter.Writer[*syncPointOperation, *noResult]
-	txMgr    components.TXManager
-	pubTxMgr components.PublicTxManager
+	started                bool
+	writer                 flushwriter.Writer[*syncPointOperation, *noResult]
+	txMgr                  components.TXManager
+	pubTxMgr               components.PublicTxManager
+	stateDistributer       statedistribution.StateDistributer
+	preparedTxnDistributer preparedtxdistribution.PreparedTransactionDistributer
 }
 
-func NewSyncPoints(ctx context.Context, conf *pldconf.FlushWriterConfig, p persistence.Persistence, txMgr components.TXManager, pubTxMgr components.PublicTxManager) SyncPoints {
+func NewSyncPoints(ctx context.Context, conf *pldconf.FlushWriterConfig, p persistence.Persistence, txMgr components.TXManager, pubTxMgr components.PublicTxManager, stateDistributer statedistribution.StateDistributer, preparedTxnDistributer preparedtxdistribution.PreparedTransactionDistributer) SyncPoints {
 	s := &syncPoints{
-		txMgr:    txMgr,
-		pubTxMgr: pubTxMgr,
+		txMgr:                  txMgr,
+		pubTxMgr:               pubTxMgr,
+		stateDistributer:       stateDistributer,
+		preparedTxnDistributer: preparedTxnDistributer,
 	}
-	s.writer = flushwriter.NewWriter(ctx, s.runBatch, p, conf, &WriterConfigDefaults)
+	s.writer = flushwriter.NewWriter(ctx, "private_tx_syncpoints", s.runBatch, p, conf, &WriterConfigDefaults)
 	return s
 }
 
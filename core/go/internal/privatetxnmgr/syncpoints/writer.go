@@ -88,12 +88,16 @@ func (s *syncPoints) runBatch(ctx context.Context, dbTX *gorm.DB, values []*sync
 	// to in the DB transaction below using foreign key relationships
 	domainContextDBTXCallbacks := make([]func(err error), 0, len(domainContextsToFlush))
 	var pubTXCbs []func()
+	var finalizeCB func()
 	dbTXCallback := func(err error) {
 		for _, dcTXCallback := range domainContextDBTXCallbacks {
 			if dcTXCallback != nil {
 				dcTXCallback(err)
 			}
 		}
+		if err == nil && finalizeCB != nil {
+			finalizeCB()
+		}
 		if err == nil {
 			for _, publicTXCallback := range pubTXCbs {
 				publicTXCallback()
@@ -124,7 +128,7 @@ func (s *syncPoints) runBatch(ctx context.Context, dbTX *gorm.DB, values []*sync
 	// assumption at time of coding because WriteKey returns the contract address
 	// but probably should consider a less brittle way to codify this assertion
 	if err == nil && len(finalizeOperations) > 0 {
-		err = s.writeFailureOperations(ctx, dbTX, finalizeOperations) // err variable must not be re-allocated
+		finalizeCB, err = s.writeFailureOperations(ctx, dbTX, finalizeOperations) // err variable must not be re-allocated
 	}
 
 	if err == nil && len(dispatchOperations) > 0 {
@@ -47,6 +47,7 @@ func NewTransactionFlow(
 	selectCoordinator ptmgrtypes.CoordinatorSelector,
 	assembleCoordinator ptmgrtypes.AssembleCoordinator,
 	environment ptmgrtypes.SequencerEnvironment,
+	metrics *privateTxManagerMetrics,
 ) ptmgrtypes.TransactionFlow {
 
 	return &transactionFlow{
@@ -76,6 +77,8 @@ func NewTransactionFlow(
 		selectCoordinator:           selectCoordinator,
 		assembleCoordinator:         assembleCoordinator,
 		environment:                 environment,
+		metrics:                     metrics,
+		loadedTime:                  time.Now(),
 	}
 }
 
@@ -122,6 +125,8 @@ type transactionFlow struct {
 	selectCoordinator           ptmgrtypes.CoordinatorSelector
 	assembleCoordinator         ptmgrtypes.AssembleCoordinator
 	environment                 ptmgrtypes.SequencerEnvironment
+	metrics                     *privateTxManagerMetrics
+	loadedTime                  time.Time    // when this node loaded the transaction into memory, for stage latency metrics
 	statusLock                  sync.RWMutex // under normal conditions, there should be only one contender for this lock ( the Write side of it) - i.e. the sequencer event loop so it should not normally slow things down
 	// however, it is not safe for the API thread to read the in memory status while the even loop is writing so things will slow down on the event loop thread while an API consumer is reading the status
 }
@@ -131,7 +136,8 @@ func (tf *transactionFlow) IsComplete(_ context.Context) bool {
 }
 
 func (tf *transactionFlow) ReadyForSequencing(ctx context.Context) bool {
-	return tf.transaction.PostAssembly != nil
+	// a transaction that is being finalized (e.g. failed by an administrator) must not be dispatched
+	return tf.transaction.PostAssembly != nil && !tf.finalizeRequired
 }
 
 func (tf *transactionFlow) Dispatched(_ context.Context) bool {
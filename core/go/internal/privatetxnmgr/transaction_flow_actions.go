@@ -23,10 +23,12 @@ import (
 	"github.com/google/uuid"
 	"github.com/hyperledger/firefly-common/pkg/i18n"
 	"github.com/kaleido-io/paladin/core/internal/msgs"
+	"github.com/kaleido-io/paladin/core/internal/tracing"
 	"github.com/kaleido-io/paladin/toolkit/pkg/log"
 	"github.com/kaleido-io/paladin/toolkit/pkg/pldapi"
 	"github.com/kaleido-io/paladin/toolkit/pkg/prototk"
 	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 func (tf *transactionFlow) logActionDebug(ctx context.Context, msg string) {
@@ -572,7 +574,13 @@ func (tf *transactionFlow) requestEndorsement(ctx context.Context, idempotencyKe
 		)
 
 	} else {
-		// This is a remote party, so we need to send an endorsement request to the remote node
+		// This is a remote party, so we need to send an endorsement request to the remote node.
+		// The span covers sending the request - the remote endorsement is linked to it as a child
+		ctx, span := tracer.Start(ctx, "privatetx.request_endorsement")
+		span.SetAttributes(
+			attribute.String("paladin.transaction_id", tf.transaction.ID.String()),
+			attribute.String("paladin.party", party),
+		)
 
 		err = tf.transportWriter.SendEndorsementRequest(
 			ctx,
@@ -589,6 +597,7 @@ func (tf *transactionFlow) requestEndorsement(ctx context.Context, idempotencyKe
 			tf.transaction.PostAssembly.OutputStates,
 			tf.transaction.PostAssembly.InfoStates,
 		)
+		tracing.EndSpan(span, err)
 		if err != nil {
 			log.L(ctx).Errorf("Failed to send endorsement request to party %s: %s", party, err)
 			tf.latestError = i18n.ExpandWithCode(ctx, i18n.MessageKey(msgs.MsgPrivateTxManagerEndorsementRequestError), party, err.Error())
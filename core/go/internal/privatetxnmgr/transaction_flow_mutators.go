@@ -65,6 +65,10 @@ func (tf *transactionFlow) ApplyEvent(ctx context.Context, event ptmgrtypes.Priv
 		tf.applyTransactionNudgeEvent(ctx, event)
 	case *ptmgrtypes.DelegationForInFlightEvent:
 		tf.applyDelegationForInFlightEvent(ctx, event)
+	case *ptmgrtypes.TransactionForceFailedEvent:
+		tf.applyTransactionForceFailedEvent(ctx, event)
+	case *ptmgrtypes.TransactionReassembleEvent:
+		tf.applyTransactionReassembleEvent(ctx, event)
 
 	default:
 		log.L(ctx).Warnf("Unknown event type: %T", event)
@@ -100,6 +104,7 @@ func (tf *transactionFlow) applyTransactionAssembledEvent(ctx context.Context, e
 		}
 		tf.revertTransaction(ctx, i18n.ExpandWithCode(ctx, i18n.MessageKey(msgs.MsgPrivateTxManagerAssembleRevert), revertReason))
 		tf.assembleCoordinator.Complete(event.AssembleRequestID, nil)
+		tf.metrics.recordStage("assemble_reverted", tf.loadedTime)
 		return
 	}
 	if tf.transaction.PostAssembly.AssemblyResult == prototk.AssembleTransactionResponse_PARK {
@@ -108,9 +113,11 @@ func (tf *transactionFlow) applyTransactionAssembledEvent(ctx context.Context, e
 		tf.status = "parked"
 		tf.assemblePending = false
 		tf.assembleCoordinator.Complete(event.AssembleRequestID, nil)
+		tf.metrics.recordStage("parked", tf.loadedTime)
 		return
 	}
 	tf.status = "assembled"
+	tf.metrics.recordStage("assembled", tf.loadedTime)
 	tf.writeAndLockStates(ctx)
 	//allow assembly thread to proceed
 	sds, err := tf.GetStateDistributions(ctx)
@@ -133,6 +140,7 @@ func (tf *transactionFlow) applyTransactionAssembleFailedEvent(ctx context.Conte
 	// set assemblePending to false so that the transaction can be re-assembled
 	tf.assemblePending = false
 	tf.assembleCoordinator.Complete(event.AssembleRequestID, nil)
+	tf.metrics.recordStage("assemble_failed", tf.loadedTime)
 }
 
 func (tf *transactionFlow) applyTransactionSignedEvent(ctx context.Context, event *ptmgrtypes.TransactionSignedEvent) {
@@ -165,6 +173,12 @@ func (tf *transactionFlow) applyTransactionEndorsedEvent(ctx context.Context, ev
 	//we have (had) a pending request for this endorsement but it is no longer pending because we now have a response
 	delete(pendingRequestsForAttRequestName, event.Party)
 
+	endorsementResult := "endorsed"
+	if event.RevertReason != nil {
+		endorsementResult = "rejected"
+	}
+	tf.metrics.recordEndorsement(endorsementResult, tf.clock.Now().Sub(pendingRequest.requestTime))
+
 	if event.RevertReason != nil {
 		log.L(ctx).Infof("Endorsement for transaction %s was rejected: %s", tf.transaction.ID.String(), *event.RevertReason)
 		// endorsement errors trigger a re-assemble
@@ -190,6 +204,7 @@ func (tf *transactionFlow) applyTransactionDispatchedEvent(ctx context.Context,
 	tf.latestEvent = "TransactionDispatchedEvent"
 	tf.status = "dispatched"
 	tf.dispatched = true
+	tf.metrics.recordStage("dispatched", tf.loadedTime)
 }
 
 func (tf *transactionFlow) applyTransactionPreparedEvent(ctx context.Context, _ *ptmgrtypes.TransactionPreparedEvent) {
@@ -197,6 +212,7 @@ func (tf *transactionFlow) applyTransactionPreparedEvent(ctx context.Context, _
 	tf.latestEvent = "TransactionPreparedEvent"
 	tf.status = "prepared"
 	tf.prepared = true
+	tf.metrics.recordStage("prepared", tf.loadedTime)
 }
 
 func (tf *transactionFlow) applyTransactionConfirmedEvent(ctx context.Context, event *ptmgrtypes.TransactionConfirmedEvent) {
@@ -204,12 +220,14 @@ func (tf *transactionFlow) applyTransactionConfirmedEvent(ctx context.Context, e
 	tf.latestEvent = "TransactionConfirmedEvent"
 	tf.status = "confirmed"
 	tf.finalizeRequired = true
+	tf.metrics.recordStage("confirmed", tf.loadedTime)
 }
 
 func (tf *transactionFlow) applyTransactionRevertedEvent(ctx context.Context, _ *ptmgrtypes.TransactionRevertedEvent) {
 	log.L(ctx).Debugf("transactionFlow:applyTransactionRevertedEvent transactionID:%s", tf.transaction.ID.String())
 	tf.latestEvent = "TransactionRevertedEvent"
 	tf.status = "reverted"
+	tf.metrics.recordStage("reverted", tf.loadedTime)
 }
 
 func (tf *transactionFlow) applyTransactionDelegationAcknowledgedEvent(ctx context.Context, event *ptmgrtypes.TransactionDelegationAcknowledgedEvent) {
@@ -293,3 +311,39 @@ func (tf *transactionFlow) applyDelegationForInFlightEvent(ctx context.Context,
 	}
 
 }
+
+func (tf *transactionFlow) applyTransactionForceFailedEvent(ctx context.Context, event *ptmgrtypes.TransactionForceFailedEvent) {
+	log.L(ctx).Warnf("applyTransactionForceFailedEvent transaction %s: %s", tf.transaction.ID, event.FailureMessage)
+	tf.latestEvent = "TransactionForceFailedEvent"
+	if tf.dispatched || tf.finalizeRequired {
+		log.L(ctx).Warnf("Ignoring request to fail transaction %s that is already dispatched or finalizing", tf.transaction.ID)
+		return
+	}
+	if tf.delegateRequestTimer != nil {
+		tf.delegateRequestTimer.Stop()
+	}
+	tf.delegateRequestTimer = nil
+	tf.status = "failed"
+	// the next Action initiates the finalize, which writes the failure receipt and
+	// then releases any state locks held by the transaction in the domain context
+	tf.finalizeRequired = true
+	tf.finalizePending = false
+	tf.finalizeRevertReason = event.FailureMessage
+	tf.metrics.recordStage("force_failed", tf.loadedTime)
+}
+
+func (tf *transactionFlow) applyTransactionReassembleEvent(ctx context.Context, _ *ptmgrtypes.TransactionReassembleEvent) {
+	log.L(ctx).Infof("applyTransactionReassembleEvent transaction %s", tf.transaction.ID)
+	tf.latestEvent = "TransactionReassembleEvent"
+	if tf.dispatched || tf.finalizeRequired || tf.assemblePending || tf.transaction.PostAssembly == nil {
+		log.L(ctx).Warnf("Ignoring request to re-assemble transaction %s (status=%s assemblePending=%t)", tf.transaction.ID, tf.status, tf.assemblePending)
+		return
+	}
+	// discard the assembly along with any signatures and endorsements gathered against it,
+	// and the state locks it holds, so the next Action starts again from assembly
+	tf.transaction.PostAssembly = nil
+	tf.requestedSignatures = false
+	tf.pendingEndorsementRequests = make(map[string]map[string]*endorsementRequest)
+	tf.domainContext.ResetTransactions(tf.transaction.ID)
+	tf.status = "new"
+}
@@ -94,7 +94,7 @@ func newTransactionFlowForTesting(t *testing.T, ctx context.Context, transaction
 
 	assembleCoordinator := NewAssembleCoordinator(ctx, nodeName, 1, mocks.allComponents, mocks.domainSmartContract, mocks.domainContext, mocks.transportWriter, *contractAddress, mocks.environment, 1*time.Second, mocks.stateDistributer, mocks.localAssembler)
 
-	tp := NewTransactionFlow(ctx, transaction, nodeName, mocks.allComponents, mocks.domainSmartContract, mocks.domainContext, mocks.publisher, mocks.endorsementGatherer, mocks.identityResolver, mocks.syncPoints, mocks.transportWriter, 1*time.Minute, mocks.coordinatorSelector, assembleCoordinator, mocks.environment)
+	tp := NewTransactionFlow(ctx, transaction, nodeName, mocks.allComponents, mocks.domainSmartContract, mocks.domainContext, mocks.publisher, mocks.endorsementGatherer, mocks.identityResolver, mocks.syncPoints, mocks.transportWriter, 1*time.Minute, mocks.coordinatorSelector, assembleCoordinator, mocks.environment, newPrivateTxManagerMetrics())
 
 	return tp.(*transactionFlow), mocks
 }
@@ -1804,6 +1804,86 @@ func TestGetTxStatusPendingEndorsements(t *testing.T) {
 	// Endorsements []PrivateTxEndorsementStatus `json:"endorsements"`
 }
 
+func TestApplyTransactionForceFailedEvent(t *testing.T) {
+	ctx := context.Background()
+	newTxID := uuid.New()
+	testContractAddress := *tktypes.RandAddress()
+	testTx := &components.PrivateTransaction{
+		ID:           newTxID,
+		Address:      testContractAddress,
+		PostAssembly: &components.TransactionPostAssembly{},
+	}
+	tp, _ := newTransactionFlowForTesting(t, ctx, testTx, "node1")
+	assert.True(t, tp.ReadyForSequencing(ctx))
+
+	tp.ApplyEvent(ctx, &ptmgrtypes.TransactionForceFailedEvent{
+		PrivateTransactionEventBase: ptmgrtypes.PrivateTransactionEventBase{
+			TransactionID:   newTxID.String(),
+			ContractAddress: testContractAddress.String(),
+		},
+		FailureMessage: "pop",
+	})
+
+	assert.Equal(t, "failed", tp.status)
+	assert.True(t, tp.finalizeRequired)
+	assert.Equal(t, "pop", tp.finalizeRevertReason)
+	// must not be dispatched while the failure is being finalized
+	assert.False(t, tp.ReadyForSequencing(ctx))
+}
+
+func TestApplyTransactionForceFailedEventDispatched(t *testing.T) {
+	ctx := context.Background()
+	newTxID := uuid.New()
+	tp, _ := newTransactionFlowForTesting(t, ctx, &components.PrivateTransaction{ID: newTxID}, "node1")
+	tp.dispatched = true
+
+	tp.ApplyEvent(ctx, &ptmgrtypes.TransactionForceFailedEvent{
+		PrivateTransactionEventBase: ptmgrtypes.PrivateTransactionEventBase{
+			TransactionID: newTxID.String(),
+		},
+		FailureMessage: "pop",
+	})
+
+	assert.False(t, tp.finalizeRequired)
+	assert.Empty(t, tp.finalizeRevertReason)
+}
+
+func TestApplyTransactionReassembleEvent(t *testing.T) {
+	ctx := context.Background()
+	newTxID := uuid.New()
+	testContractAddress := *tktypes.RandAddress()
+	testTx := &components.PrivateTransaction{
+		ID:           newTxID,
+		Address:      testContractAddress,
+		PostAssembly: &components.TransactionPostAssembly{},
+	}
+	tp, mocks := newTransactionFlowForTesting(t, ctx, testTx, "node1")
+	tp.status = "signed"
+	tp.requestedSignatures = true
+	tp.pendingEndorsementRequests["foo"] = map[string]*endorsementRequest{
+		"bob@node2": {requestTime: time.Now(), idempotencyKey: "key1"},
+	}
+	mocks.domainContext.On("ResetTransactions", newTxID).Return()
+
+	event := &ptmgrtypes.TransactionReassembleEvent{
+		PrivateTransactionEventBase: ptmgrtypes.PrivateTransactionEventBase{
+			TransactionID:   newTxID.String(),
+			ContractAddress: testContractAddress.String(),
+		},
+	}
+	tp.ApplyEvent(ctx, event)
+
+	assert.Nil(t, testTx.PostAssembly)
+	assert.False(t, tp.requestedSignatures)
+	assert.Empty(t, tp.pendingEndorsementRequests)
+	assert.Equal(t, "new", tp.status)
+	assert.False(t, tp.ReadyForSequencing(ctx))
+
+	// a second request is ignored until the transaction has been assembled again
+	tp.ApplyEvent(ctx, event)
+	mocks.domainContext.AssertNumberOfCalls(t, "ResetTransactions", 1)
+}
+
 type fakeClock struct {
 	timePassed time.Duration
 }
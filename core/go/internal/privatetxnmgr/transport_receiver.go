@@ -19,6 +19,7 @@ import (
 	"context"
 
 	"github.com/kaleido-io/paladin/core/internal/components"
+	"github.com/kaleido-io/paladin/core/internal/tracing"
 	"github.com/kaleido-io/paladin/toolkit/pkg/log"
 )
 
@@ -33,26 +34,24 @@ func (p *privateTxManager) ReceiveTransportMessage(ctx context.Context, message
 	//Send the event to the sequencer for the contract and any transaction manager for the signing key
 	messagePayload := message.Payload
 	replyToDestination := message.ReplyTo
+	// handlers run in the background against the manager context, linked to the sender's trace
+	handlerCtx := tracing.ExtractContext(p.ctx, message.TraceContext)
 
 	switch message.MessageType {
 	case "EndorsementRequest":
-		go p.handleEndorsementRequest(p.ctx, messagePayload, replyToDestination)
+		go p.handleEndorsementRequest(handlerCtx, messagePayload, replyToDestination)
 	case "EndorsementResponse":
-		go p.handleEndorsementResponse(p.ctx, messagePayload)
+		go p.handleEndorsementResponse(handlerCtx, messagePayload)
 	case "DelegationRequest":
-		go p.handleDelegationRequest(p.ctx, messagePayload, replyToDestination)
+		go p.handleDelegationRequest(handlerCtx, messagePayload, replyToDestination)
 	case "DelegationRequestAcknowledgment":
-		go p.handleDelegationRequestAcknowledgment(p.ctx, messagePayload)
+		go p.handleDelegationRequestAcknowledgment(handlerCtx, messagePayload)
 	case "AssembleRequest":
-		go p.handleAssembleRequest(p.ctx, messagePayload, replyToDestination)
+		go p.handleAssembleRequest(handlerCtx, messagePayload, replyToDestination)
 	case "AssembleResponse":
-		go p.handleAssembleResponse(p.ctx, messagePayload)
+		go p.handleAssembleResponse(handlerCtx, messagePayload)
 	case "AssembleError":
-		go p.handleAssembleError(p.ctx, messagePayload)
-	case "StateProducedEvent":
-		go p.handleStateProducedEvent(p.ctx, messagePayload, replyToDestination)
-	case "StateAcknowledgedEvent":
-		go p.stateDistributer.HandleStateAcknowledgedEvent(p.ctx, message.Payload)
+		go p.handleAssembleError(handlerCtx, messagePayload)
 	default:
 		log.L(ctx).Errorf("Unknown message type: %s", message.MessageType)
 	}
@@ -31,6 +31,7 @@ const (
 	ActionSuspend AsyncRequestType = iota
 	ActionResume
 	ActionCompleted
+	ActionReplace
 )
 
 func (pte *pubTxManager) persistSuspendedFlag(ctx context.Context, from tktypes.EthAddress, nonce uint64, suspended bool) error {
@@ -44,7 +45,50 @@ func (pte *pubTxManager) persistSuspendedFlag(ctx context.Context, from tktypes.
 		Error
 }
 
-func (pte *pubTxManager) dispatchAction(ctx context.Context, from tktypes.EthAddress, nonce uint64, action AsyncRequestType) error {
+// The replacement values have been validated before calling this function
+func (pte *pubTxManager) persistReplacement(ctx context.Context, from tktypes.EthAddress, nonce uint64, replacement *TxReplacement) error {
+	log.L(ctx).Infof("Updating transaction %s:%d with replacement to=%s gas=%d", from, nonce, replacement.To, replacement.Gas)
+	updates := map[string]any{
+		"to":       replacement.To,
+		"data":     replacement.Data,
+		"gas":      replacement.Gas,
+		"value":    replacement.Value,
+		"replaced": tktypes.TimestampNow(),
+	}
+	if replacement.GasPricing != nil {
+		updates["fixed_gas_pricing"] = tktypes.JSONString(replacement.GasPricing)
+	}
+	return pte.p.DB().
+		WithContext(ctx).
+		Table("public_txns").
+		Where(`"from" = ?`, from).
+		Where("nonce = ?", nonce).
+		Updates(updates).
+		Error
+}
+
+// When the transaction is not in-flight, we can only update it in the DB if it has never been submitted.
+// Otherwise we would lose the gas pricing information we need to build a valid replacement.
+func (pte *pubTxManager) persistReplacementNotInFlight(ctx context.Context, from tktypes.EthAddress, nonce uint64, replacement *TxReplacement) error {
+	var submissions int64
+	err := pte.p.DB().
+		WithContext(ctx).
+		Table("public_submissions").
+		Joins(`JOIN public_txns ON public_txns."pub_txn_id" = public_submissions."pub_txn_id"`).
+		Where(`public_txns."from" = ?`, from).
+		Where(`public_txns."nonce" = ?`, nonce).
+		Count(&submissions).
+		Error
+	if err != nil {
+		return err
+	}
+	if submissions > 0 {
+		return i18n.NewError(ctx, msgs.MsgPublicTxNotInFlight, from, nonce)
+	}
+	return pte.persistReplacement(ctx, from, nonce, replacement)
+}
+
+func (pte *pubTxManager) dispatchAction(ctx context.Context, from tktypes.EthAddress, nonce uint64, action AsyncRequestType, replacement *TxReplacement) error {
 	response := make(chan error, 1)
 	startTime := time.Now()
 	go func() {
@@ -55,7 +99,7 @@ func (pte *pubTxManager) dispatchAction(ctx context.Context, from tktypes.EthAdd
 		case ActionCompleted:
 			// Only need to pass this on if there's an orchestrator in flight for this signing address
 			if orchestratorInFlight {
-				inFlightOrchestrator.dispatchAction(ctx, nonce, action, response)
+				inFlightOrchestrator.dispatchAction(ctx, nonce, action, nil, response)
 			}
 		case ActionSuspend, ActionResume:
 			suspended := false
@@ -67,7 +111,14 @@ func (pte *pubTxManager) dispatchAction(ctx context.Context, from tktypes.EthAdd
 				response <- pte.persistSuspendedFlag(ctx, from, nonce, suspended)
 			} else {
 				// has to be done in the context of the orchestrator
-				inFlightOrchestrator.dispatchAction(ctx, nonce, action, response)
+				inFlightOrchestrator.dispatchAction(ctx, nonce, action, nil, response)
+			}
+		case ActionReplace:
+			if !orchestratorInFlight {
+				response <- pte.persistReplacementNotInFlight(ctx, from, nonce, replacement)
+			} else {
+				// has to be done in the context of the orchestrator
+				inFlightOrchestrator.dispatchAction(ctx, nonce, action, replacement, response)
 			}
 		}
 	}()
@@ -80,7 +131,7 @@ func (pte *pubTxManager) dispatchAction(ctx context.Context, from tktypes.EthAdd
 	}
 }
 
-func (oc *orchestrator) dispatchAction(ctx context.Context, nonce uint64, action AsyncRequestType, response chan<- error) {
+func (oc *orchestrator) dispatchAction(ctx context.Context, nonce uint64, action AsyncRequestType, replacement *TxReplacement, response chan<- error) {
 	oc.inFlightTxsMux.Lock()
 	defer oc.inFlightTxsMux.Unlock()
 	var pending *inFlightTransactionStageController
@@ -107,7 +158,28 @@ func (oc *orchestrator) dispatchAction(ctx context.Context, nonce uint64, action
 			// Ok we've now got the lock that means we can write to the DB
 			// No optimization of this write, as it's a user action from the side of normal processing
 			response <- oc.persistSuspendedFlag(ctx, oc.signingAddress, nonce, suspendedFlag)
+		case ActionReplace:
+			// The replacement is persisted before it is queued to the in-flight transaction,
+			// which will sign and submit it once any running stage is complete
+			if pending.stateManager.IsReadyToExit() {
+				response <- i18n.NewError(ctx, msgs.MsgStatusUpdateForbidden)
+				return
+			}
+			// We check the gas pricing against the previous submission now, so the user gets an error if
+			// the configured maximum gas price would not allow a replacement to be accepted by the chain
+			gpo, err := pending.replacementGasPrice(ctx, replacement.GasPricing)
+			if err == nil {
+				replacement.GasPricing = gpo
+				err = oc.persistReplacement(ctx, oc.signingAddress, nonce, replacement)
+			}
+			if err == nil {
+				err = pending.NotifyReplacement(ctx, replacement)
+			}
+			response <- err
 		}
 		oc.MarkInFlightTxStale()
+	} else if action == ActionReplace {
+		// We hold the in-flight lock, so the orchestrator cannot load the transaction while we update it
+		response <- oc.persistReplacementNotInFlight(ctx, oc.signingAddress, nonce, replacement)
 	}
 }
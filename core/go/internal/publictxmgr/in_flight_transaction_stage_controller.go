@@ -54,6 +54,8 @@ func (ifs InFlightStatus) String() string {
 	}
 }
 
+const minReplacementGasPriceIncreasePercent = 10
+
 type inFlightTransactionStageController struct {
 	testOnlyNoActionMode bool // Note: this flag can never be set in normal code path, exposed for testing only
 	testOnlyNoEventMode  bool // Note: this flag can never be set in normal code path, exposed for testing only
@@ -72,6 +74,9 @@ type inFlightTransactionStageController struct {
 
 	newStatus *InFlightStatus
 
+	// a user requested update (or cancel), to be submitted as a replacement transaction
+	pendingReplacement *TxReplacement
+
 	// deleteRequested bool // figure out what's the reliable approach for deletion
 }
 
@@ -136,6 +141,11 @@ func NewInFlightTransactionStageController(
 	ift.MarkTime("wait_in_inflight_queue")
 	imtxs := NewInMemoryTxStateManager(enth.ctx, ptx)
 	ift.stateManager = NewInFlightTransactionStateManager(enth.thMetrics, enth.balanceManager, enth.bIndexer, ift, imtxs, oc.retry, oc, oc.submissionWriter, ift.testOnlyNoEventMode)
+	if ptx.Replaced != nil && (len(ptx.Submissions) == 0 || *ptx.Replaced > ptx.Submissions[0].Created) {
+		// The user updated or cancelled the transaction, and we did not submit the replacement before
+		// we were last processing it. So it is applied again against the pricing of the last submission.
+		ift.pendingReplacement = ptx.replacement()
+	}
 	return ift
 }
 
@@ -473,7 +483,11 @@ func (it *inFlightTransactionStageController) ProduceLatestInFlightStageContext(
 	}
 
 	if it.stateManager.GetRunningStageContext(ctx) == nil {
-		// no running context in flight
+		// no running context in flight, so it is safe to apply any replacement the user has requested.
+		// The stage selection below then re-signs and submits the replacement transaction
+		if it.pendingReplacement != nil && !it.stateManager.IsReadyToExit() {
+			it.applyReplacement(ctx)
+		}
 		// first check whether the current transaction is before the confirmed nonce
 		if it.newStatus != nil && !it.stateManager.IsReadyToExit() && *it.newStatus != it.stateManager.GetInFlightStatus() { // first apply any status update that's required
 			log.L(ctx).Debugf("Transaction with ID %s entering status update, current status: %s, target status: %s", it.stateManager.GetSignerNonce(), it.stateManager.GetInFlightStatus(), *it.newStatus)
@@ -570,6 +584,134 @@ func (it *inFlightTransactionStageController) calculateNewGasPrice(ctx context.C
 	return newGpo
 }
 
+func (it *inFlightTransactionStageController) applyReplacement(ctx context.Context) {
+	replacement := it.pendingReplacement
+	it.pendingReplacement = nil
+	gpo, err := it.replacementGasPrice(ctx, replacement.GasPricing)
+	if err != nil {
+		// The gas price was checked when the replacement was accepted, so we only get here if the
+		// gas price of the transaction has been increased since to a point we cannot replace it
+		log.L(ctx).Errorf("Unable to apply replacement for transaction %s: %s", it.stateManager.GetSignerNonce(), err)
+		return
+	}
+	replacement.GasPricing = gpo
+	// The final gas pricing is persisted before we sign, so that if the transaction is reloaded
+	// before the replacement is submitted, it is applied again with the same pricing
+	if err := it.persistReplacement(ctx, it.stateManager.GetFrom(), it.stateManager.GetNonce(), replacement); err != nil {
+		log.L(ctx).Errorf("Failed to persist replacement for transaction %s (will retry): %s", it.stateManager.GetSignerNonce(), err)
+		it.pendingReplacement = replacement
+		return
+	}
+	log.L(ctx).Infof("Applying replacement for transaction %s to=%s gas=%d gasPricing=%s", it.stateManager.GetSignerNonce(), replacement.To, replacement.Gas, tktypes.JSONString(gpo))
+	it.stateManager.ApplyInMemoryUpdates(ctx, &BaseTXUpdates{
+		Replacement: replacement,
+		GasPricing:  gpo,
+	})
+	// The transaction hash we have recorded no longer matches the transaction, so we must sign and submit again
+	it.stateManager.SetValidatedTransactionHashMatchState(ctx, false)
+}
+
+// Returns the gas pricing to submit a replacement with, given the gas pricing requested by the user (if any).
+// Fails if the minimum increase over the previous submission that the chain requires to accept the
+// replacement exceeds the configured maximum gas price, as the replacement would be rejected.
+func (it *inFlightTransactionStageController) replacementGasPrice(ctx context.Context, requestedGpo *pldapi.PublicTxGasPricing) (*pldapi.PublicTxGasPricing, error) {
+	existingGpo := it.stateManager.GetGasPriceObject()
+	switch {
+	case existingGpo == nil:
+		return requestedGpo, nil
+	case requestedGpo == nil:
+		return it.calculateReplacementGasPrice(ctx, existingGpo)
+	case it.stateManager.GetTransactionHash() != nil:
+		return it.enforceMinimumReplacementGasPrice(ctx, requestedGpo, existingGpo)
+	default:
+		return requestedGpo, nil
+	}
+}
+
+func increaseByPercent(value *big.Int, percent int) *big.Int {
+	newValue := new(big.Int).Mul(value, big.NewInt(int64(100+percent)))
+	return newValue.Div(newValue, big.NewInt(100))
+}
+
+func (it *inFlightTransactionStageController) checkReplacementGasPriceMax(ctx context.Context, minValue *big.Int) error {
+	if it.gasPriceIncreaseMax != nil && minValue.Cmp(it.gasPriceIncreaseMax) == 1 {
+		return i18n.NewError(ctx, msgs.MsgPublicTxReplacementGasPriceMax, it.stateManager.GetSignerNonce(), minValue, it.gasPriceIncreaseMax)
+	}
+	return nil
+}
+
+// A replacement transaction with the same nonce is only accepted by the chain if the gas price
+// is increased over the previous submission, so we apply the configured percentage increase
+// (with a minimum of the default price bump required by Ethereum clients)
+func (it *inFlightTransactionStageController) calculateReplacementGasPrice(ctx context.Context, existingGpo *pldapi.PublicTxGasPricing) (*pldapi.PublicTxGasPricing, error) {
+	increasePercent := it.gasPriceIncreasePercent
+	if increasePercent < minReplacementGasPriceIncreasePercent {
+		increasePercent = minReplacementGasPriceIncreasePercent
+	}
+	var err error
+	increase := func(v *tktypes.HexUint256) *tktypes.HexUint256 {
+		if v == nil || err != nil {
+			return nil
+		}
+		if err = it.checkReplacementGasPriceMax(ctx, increaseByPercent(v.Int(), minReplacementGasPriceIncreasePercent)); err != nil {
+			return nil
+		}
+		newValue := increaseByPercent(v.Int(), increasePercent)
+		if it.gasPriceIncreaseMax != nil && newValue.Cmp(it.gasPriceIncreaseMax) == 1 {
+			newValue.Set(it.gasPriceIncreaseMax)
+		}
+		return (*tktypes.HexUint256)(newValue)
+	}
+	newGpo := &pldapi.PublicTxGasPricing{
+		GasPrice:             increase(existingGpo.GasPrice),
+		MaxFeePerGas:         increase(existingGpo.MaxFeePerGas),
+		MaxPriorityFeePerGas: increase(existingGpo.MaxPriorityFeePerGas),
+	}
+	if err != nil {
+		return nil, err
+	}
+	log.L(ctx).Debugf("Increased gas price for replacement of transaction %s from %s to %s", it.stateManager.GetSignerNonce(), tktypes.JSONString(existingGpo), tktypes.JSONString(newGpo))
+	return newGpo, nil
+}
+
+// A gas price supplied by the user for a replacement transaction is only accepted by the chain if it
+// exceeds the previous submission by the minimum increase, so any value below that is bumped up to it.
+// Where the user switches between legacy and EIP-1559 pricing, the legacy gas price is compared
+// against both the max fee and the max priority fee, as Ethereum clients do.
+// Values the user supplies above the configured maximum gas price are honored, but we do not
+// bump a value above the maximum ourselves.
+func (it *inFlightTransactionStageController) enforceMinimumReplacementGasPrice(ctx context.Context, requestedGpo, existingGpo *pldapi.PublicTxGasPricing) (*pldapi.PublicTxGasPricing, error) {
+	increased := false
+	var err error
+	atLeastMinIncrease := func(requested, existing, existingLegacy *tktypes.HexUint256) *tktypes.HexUint256 {
+		if existing == nil {
+			existing = existingLegacy
+		}
+		if requested == nil || existing == nil || err != nil {
+			return requested
+		}
+		minValue := increaseByPercent(existing.Int(), minReplacementGasPriceIncreasePercent)
+		if requested.Int().Cmp(minValue) < 0 {
+			increased = true
+			err = it.checkReplacementGasPriceMax(ctx, minValue)
+			return (*tktypes.HexUint256)(minValue)
+		}
+		return requested
+	}
+	newGpo := &pldapi.PublicTxGasPricing{
+		GasPrice:             atLeastMinIncrease(requestedGpo.GasPrice, existingGpo.GasPrice, existingGpo.MaxFeePerGas),
+		MaxFeePerGas:         atLeastMinIncrease(requestedGpo.MaxFeePerGas, existingGpo.MaxFeePerGas, existingGpo.GasPrice),
+		MaxPriorityFeePerGas: atLeastMinIncrease(requestedGpo.MaxPriorityFeePerGas, existingGpo.MaxPriorityFeePerGas, existingGpo.GasPrice),
+	}
+	if err != nil {
+		return nil, err
+	}
+	if increased {
+		log.L(ctx).Warnf("Increased requested gas price for replacement of transaction %s from %s to %s, as the previous submission used %s", it.stateManager.GetSignerNonce(), tktypes.JSONString(requestedGpo), tktypes.JSONString(newGpo), tktypes.JSONString(existingGpo))
+	}
+	return newGpo, nil
+}
+
 func calculateGasRequiredForTransaction(ctx context.Context, gpo *pldapi.PublicTxGasPricing, gasLimit uint64) (gasRequired *big.Int, err error) {
 	if gpo.GasPrice != nil {
 		log.L(ctx).Debugf("gas calculation using GasPrice (%+v)", gpo.GasPrice)
@@ -603,6 +745,17 @@ func (it *inFlightTransactionStageController) NotifyStatusUpdate(ctx context.Con
 	return true, nil
 }
 
+func (it *inFlightTransactionStageController) NotifyReplacement(ctx context.Context, replacement *TxReplacement) error {
+	if it.stateManager.IsReadyToExit() {
+		return i18n.NewError(ctx, msgs.MsgStatusUpdateForbidden)
+	}
+	// queue the replacement to be applied in future evaluation loops, once any running stage is complete
+	it.transactionMux.Lock()
+	defer it.transactionMux.Unlock()
+	it.pendingReplacement = replacement
+	return nil
+}
+
 func (it *inFlightTransactionStageController) TriggerRetrieveGasPrice(ctx context.Context) error {
 	it.executeAsync(func() {
 		gasPrice, err := it.gasPriceClient.GetGasPriceObject(ctx)
@@ -17,9 +17,12 @@ package publictxmgr
 
 import (
 	"fmt"
+	"math/big"
 	"testing"
 	"time"
 
+	"github.com/kaleido-io/paladin/toolkit/pkg/pldapi"
+	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -160,3 +163,73 @@ func TestProduceLatestInFlightStageContextStatusUpdatePanic(t *testing.T) {
 	assert.NotEqual(t, rsc, it.stateManager.GetRunningStageContext(ctx))
 	inFlightStageMananger.bufferedStageOutputs = make([]*StageOutput, 0)
 }
+
+func TestReplacementGasPriceExceedsMax(t *testing.T) {
+	ctx, o, _, done := newTestOrchestrator(t)
+	defer done()
+	o.gasPriceIncreaseMax = big.NewInt(1050)
+	it, _ := newInflightTransaction(o, 1, func(tx *DBPublicTxn) {
+		tx.Submissions = []*DBPubTxnSubmission{
+			{
+				Created:         tktypes.TimestampNow(),
+				TransactionHash: tktypes.Bytes32(tktypes.RandBytes(32)),
+				GasPricing:      tktypes.JSONString(&pldapi.PublicTxGasPricing{GasPrice: tktypes.Int64ToInt256(1000)}),
+			},
+		}
+	})
+
+	// The increase we would make is capped below the minimum the chain will accept
+	_, err := it.replacementGasPrice(ctx, nil)
+	assert.Regexp(t, "PD011940.*1100.*1050", err)
+
+	// Bumping the price the user requested up to the minimum would exceed the maximum
+	_, err = it.replacementGasPrice(ctx, &pldapi.PublicTxGasPricing{GasPrice: tktypes.Int64ToInt256(1001)})
+	assert.Regexp(t, "PD011940", err)
+
+	// A price the user requests above the maximum is used as supplied
+	gpo, err := it.replacementGasPrice(ctx, &pldapi.PublicTxGasPricing{GasPrice: tktypes.Int64ToInt256(2000)})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2000), gpo.GasPrice.Int().Int64())
+
+	// With a maximum that allows the minimum increase, the increase is capped at the maximum
+	o.gasPriceIncreaseMax = big.NewInt(1150)
+	o.gasPriceIncreasePercent = 50
+	gpo, err = it.replacementGasPrice(ctx, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1150), gpo.GasPrice.Int().Int64())
+}
+
+func TestInFlightTransactionReloadsUnsubmittedReplacement(t *testing.T) {
+	_, o, _, done := newTestOrchestrator(t)
+	defer done()
+
+	lastSubmit := tktypes.TimestampNow()
+	withReplacement := func(replaced tktypes.Timestamp) func(tx *DBPublicTxn) {
+		return func(tx *DBPublicTxn) {
+			tx.Data = tktypes.HexBytes("replaced")
+			tx.FixedGasPricing = tktypes.JSONString(&pldapi.PublicTxGasPricing{GasPrice: tktypes.Int64ToInt256(1100)})
+			tx.Replaced = &replaced
+			tx.Submissions = []*DBPubTxnSubmission{
+				{
+					Created:         lastSubmit,
+					TransactionHash: tktypes.Bytes32(tktypes.RandBytes(32)),
+					GasPricing:      tktypes.JSONString(&pldapi.PublicTxGasPricing{GasPrice: tktypes.Int64ToInt256(1000)}),
+				},
+			}
+		}
+	}
+
+	// Replaced after the last submission, so we need to apply and submit the replacement
+	it, _ := newInflightTransaction(o, 1, withReplacement(lastSubmit+1))
+	require.NotNil(t, it.pendingReplacement)
+	assert.Equal(t, tktypes.HexBytes("replaced"), it.pendingReplacement.Data)
+	assert.Equal(t, int64(1100), it.pendingReplacement.GasPricing.GasPrice.Int().Int64())
+
+	// Replaced before the last submission, so the replacement has already been submitted
+	it, _ = newInflightTransaction(o, 2, withReplacement(lastSubmit-1))
+	assert.Nil(t, it.pendingReplacement)
+
+	// Never replaced
+	it, _ = newInflightTransaction(o, 3)
+	assert.Nil(t, it.pendingReplacement)
+}
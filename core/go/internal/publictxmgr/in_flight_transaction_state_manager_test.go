@@ -48,7 +48,7 @@ func newTestInFlightTransactionStateManager(t *testing.T) (*testInFlightTransact
 
 	mockInMemoryState := NewTestInMemoryTxState(t)
 	mockActionTriggers := publictxmocks.NewInFlightStageActionTriggers(t)
-	iftxs := NewInFlightTransactionStateManager(&publicTxEngineMetrics{}, balanceManager, m.blockIndexer, mockActionTriggers, mockInMemoryState,
+	iftxs := NewInFlightTransactionStateManager(newPublicTxEngineMetrics(), balanceManager, m.blockIndexer, mockActionTriggers, mockInMemoryState,
 		retry.NewRetryIndefinite(&pldconf.RetryConfig{
 			InitialDelay: confutil.P("1ms"),
 			MaxDelay:     confutil.P("100ms"),
@@ -109,6 +109,17 @@ func (imtxs *inMemoryTxState) ApplyInMemoryUpdates(ctx context.Context, txUpdate
 	if txUpdates.TransactionHash != nil {
 		mtx.TransactionHash = txUpdates.TransactionHash
 	}
+
+	if txUpdates.Replacement != nil {
+		// The persisted values have already been updated, so we just need to reflect them in memory
+		mtx.ptx.To = txUpdates.Replacement.To
+		mtx.ptx.Data = txUpdates.Replacement.Data
+		mtx.ptx.Gas = txUpdates.Replacement.Gas
+		mtx.ptx.Value = txUpdates.Replacement.Value
+		if txUpdates.Replacement.GasPricing != nil {
+			mtx.ptx.FixedGasPricing = tktypes.JSONString(txUpdates.Replacement.GasPricing)
+		}
+	}
 }
 
 func (imtxs *inMemoryTxState) GetPubTxnID() uint64 {
@@ -18,46 +18,128 @@ package publictxmgr
 import (
 	"context"
 
+	"github.com/kaleido-io/paladin/core/internal/metrics"
 	"github.com/kaleido-io/paladin/toolkit/pkg/log"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 type PublicTxManagerMetricsManager interface {
-	InitMetrics(ctx context.Context)
+	InitMetrics(ctx context.Context, r prometheus.Registerer) error
 	RecordOperationMetrics(ctx context.Context, operationName string, operationResult string, durationInSeconds float64)
 	RecordStageChangeMetrics(ctx context.Context, stage string, durationInSeconds float64)
-	RecordInFlightTxQueueMetrics(ctx context.Context, usedCountPerStage map[string]int, freeCount int)
+	RecordInFlightOrchestratorPoolMetrics(ctx context.Context, usedCountPerState map[string]int, freeCount int)
+	RecordInFlightTxQueueMetrics(ctx context.Context, signingAddress string, usedCountPerStage map[string]int, freeCount int)
+	ClearInFlightTxQueueMetrics(ctx context.Context, signingAddress string)
 	RecordCompletedTransactionCountMetrics(ctx context.Context, processStatus string)
 }
 
 type publicTxEngineMetrics struct {
+	operationDuration    *prometheus.HistogramVec
+	stageDuration        *prometheus.HistogramVec
+	orchestratorPoolUsed *prometheus.GaugeVec
+	orchestratorPoolFree prometheus.Gauge
+	txQueueUsed          *prometheus.GaugeVec
+	txQueueFree          *prometheus.GaugeVec
+	completedTxCount     *prometheus.CounterVec
 }
 
-func (thm *publicTxEngineMetrics) InitMetrics(ctx context.Context) {
+func newPublicTxEngineMetrics() *publicTxEngineMetrics {
+	const subsystem = "public_tx"
+	return &publicTxEngineMetrics{
+		operationDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: subsystem,
+			Name:      "operation_duration_seconds",
+			Help:      "Duration of public transaction operations, such as signing and submission",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"operation", "result"}),
+		stageDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: subsystem,
+			Name:      "stage_duration_seconds",
+			Help:      "Time in-flight public transactions spend in each stage",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"stage"}),
+		orchestratorPoolUsed: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: subsystem,
+			Name:      "orchestrators",
+			Help:      "Number of in-flight signing address orchestrators by state",
+		}, []string{"state"}),
+		orchestratorPoolFree: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: subsystem,
+			Name:      "orchestrators_free",
+			Help:      "Number of free slots in the in-flight orchestrator pool",
+		}),
+		txQueueUsed: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: subsystem,
+			Name:      "inflight_transactions",
+			Help:      "Number of in-flight transactions for each signing address by stage",
+		}, []string{"signer", "stage"}),
+		txQueueFree: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: subsystem,
+			Name:      "inflight_transactions_free",
+			Help:      "Number of free in-flight transaction slots for each signing address",
+		}, []string{"signer"}),
+		completedTxCount: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: subsystem,
+			Name:      "completed_total",
+			Help:      "Number of public transactions that completed processing",
+		}, []string{"status"}),
+	}
+}
+
+func (thm *publicTxEngineMetrics) InitMetrics(ctx context.Context, r prometheus.Registerer) error {
 	log.L(ctx).Tracef("Init metrics")
-	// TODO
+	for _, c := range []prometheus.Collector{
+		thm.operationDuration,
+		thm.stageDuration,
+		thm.orchestratorPoolUsed,
+		thm.orchestratorPoolFree,
+		thm.txQueueUsed,
+		thm.txQueueFree,
+		thm.completedTxCount,
+	} {
+		if err := r.Register(c); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (thm *publicTxEngineMetrics) RecordOperationMetrics(ctx context.Context, operationName string, operationResult string, durationInSeconds float64) {
-	log.L(ctx).Tracef("RecordOperationMetrics")
-	// TODO
+	thm.operationDuration.WithLabelValues(operationName, operationResult).Observe(durationInSeconds)
 }
 
 func (thm *publicTxEngineMetrics) RecordStageChangeMetrics(ctx context.Context, stage string, durationInSeconds float64) {
-	log.L(ctx).Tracef("RecordStageChangeMetrics")
-	// TODO
+	thm.stageDuration.WithLabelValues(stage).Observe(durationInSeconds)
 }
 
 func (thm *publicTxEngineMetrics) RecordInFlightOrchestratorPoolMetrics(ctx context.Context, usedCountPerState map[string]int, freeCount int) {
-	log.L(ctx).Tracef("RecordInFlightEnginePoolMetrics")
-	// TODO
+	for state, count := range usedCountPerState {
+		thm.orchestratorPoolUsed.WithLabelValues(state).Set(float64(count))
+	}
+	thm.orchestratorPoolFree.Set(float64(freeCount))
+}
+
+func (thm *publicTxEngineMetrics) RecordInFlightTxQueueMetrics(ctx context.Context, signingAddress string, usedCountPerStage map[string]int, freeCount int) {
+	for stage, count := range usedCountPerStage {
+		thm.txQueueUsed.WithLabelValues(signingAddress, stage).Set(float64(count))
+	}
+	thm.txQueueFree.WithLabelValues(signingAddress).Set(float64(freeCount))
 }
 
-func (thm *publicTxEngineMetrics) RecordInFlightTxQueueMetrics(ctx context.Context, usedCountPerStage map[string]int, freeCount int) {
-	log.L(ctx).Tracef("RecordInFlightTxQueueMetrics")
-	// TODO
+// ClearInFlightTxQueueMetrics removes the queue metrics when an orchestrator stops, so we
+// do not keep reporting stale values for signing addresses that are swapped out of the pool
+func (thm *publicTxEngineMetrics) ClearInFlightTxQueueMetrics(ctx context.Context, signingAddress string) {
+	thm.txQueueUsed.DeletePartialMatch(prometheus.Labels{"signer": signingAddress})
+	thm.txQueueFree.DeleteLabelValues(signingAddress)
 }
 
 func (thm *publicTxEngineMetrics) RecordCompletedTransactionCountMetrics(ctx context.Context, processStatus string) {
-	log.L(ctx).Tracef("RecordCompletedTransactionCountMetrics")
-	// TODO
+	thm.completedTxCount.WithLabelValues(processStatus).Inc()
 }
@@ -18,17 +18,36 @@ package publictxmgr
 import (
 	"context"
 	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestMetrics(t *testing.T) {
-	// none of the functions are actually implemented, so it's purely for test coverage
-	btem := &publicTxEngineMetrics{}
+	btem := newPublicTxEngineMetrics()
 	ctx := context.Background()
-	btem.InitMetrics(ctx)
+	r := prometheus.NewRegistry()
+	err := btem.InitMetrics(ctx, r)
+	require.NoError(t, err)
+
+	btem.RecordCompletedTransactionCountMetrics(ctx, "success")
 	btem.RecordCompletedTransactionCountMetrics(ctx, "success")
 	btem.RecordOperationMetrics(ctx, "test", "success", 12)
 	btem.RecordStageChangeMetrics(ctx, "test", 12)
-	btem.RecordInFlightOrchestratorPoolMetrics(ctx, nil, 1)
-	btem.RecordInFlightTxQueueMetrics(ctx, nil, 1)
-	btem.RecordCompletedTransactionCountMetrics(ctx, "test")
+	btem.RecordInFlightOrchestratorPoolMetrics(ctx, map[string]int{"running": 2}, 1)
+	btem.RecordInFlightTxQueueMetrics(ctx, "0x1234", map[string]int{"signing": 3}, 1)
+
+	assert.Equal(t, float64(2), testutil.ToFloat64(btem.completedTxCount.WithLabelValues("success")))
+	assert.Equal(t, float64(2), testutil.ToFloat64(btem.orchestratorPoolUsed.WithLabelValues("running")))
+	assert.Equal(t, float64(3), testutil.ToFloat64(btem.txQueueUsed.WithLabelValues("0x1234", "signing")))
+	assert.Equal(t, 1, testutil.CollectAndCount(btem.txQueueFree))
+
+	btem.ClearInFlightTxQueueMetrics(ctx, "0x1234")
+	assert.Equal(t, 0, testutil.CollectAndCount(btem.txQueueUsed))
+	assert.Equal(t, 0, testutil.CollectAndCount(btem.txQueueFree))
+
+	err = btem.InitMetrics(ctx, r)
+	assert.Error(t, err)
 }
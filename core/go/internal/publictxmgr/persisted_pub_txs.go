@@ -34,6 +34,7 @@ type DBPublicTxn struct {
 	Value           *tktypes.HexUint256    `gorm:"column:value"`
 	Data            tktypes.HexBytes       `gorm:"column:data"`
 	Suspended       bool                   `gorm:"column:suspended"`                            // excluded from processing because it's suspended by user
+	Replaced        *tktypes.Timestamp     `gorm:"column:replaced"`                             // last time a user update or cancel was persisted
 	Completed       *DBPublicTxnCompletion `gorm:"foreignKey:pub_txn_id;references:pub_txn_id"` // excluded from processing because it's done
 	Submissions     []*DBPubTxnSubmission  `gorm:"-"`                                           // we do the aggregation, not GORM
 	// Binding is used only on queries by transaction (GORM doesn't seem to allow us to define a separate struct for this)
@@ -44,6 +45,20 @@ func (DBPublicTxn) TableName() string {
 	return "public_txns"
 }
 
+// The replacement the user most recently requested with an update or cancel
+func (ptx *DBPublicTxn) replacement() *TxReplacement {
+	replacement := &TxReplacement{
+		To:    ptx.To,
+		Data:  ptx.Data,
+		Gas:   ptx.Gas,
+		Value: ptx.Value,
+	}
+	if gpo := recoverGasPriceOptions(ptx.FixedGasPricing); gpo.GasPrice != nil || gpo.MaxFeePerGas != nil || gpo.MaxPriorityFeePerGas != nil {
+		replacement.GasPricing = &gpo
+	}
+	return replacement
+}
+
 type DBPublicTxnBinding struct {
 	PublicTxnID     uint64                               `gorm:"column:pub_txn_id;primaryKey"`
 	Transaction     uuid.UUID                            `gorm:"column:transaction"`
@@ -35,7 +35,7 @@ type submissionWriter struct {
 
 func newSubmissionWriter(bgCtx context.Context, p persistence.Persistence, conf *pldconf.PublicTxManagerConfig) *submissionWriter {
 	sw := &submissionWriter{}
-	sw.Writer = flushwriter.NewWriter(bgCtx, sw.runBatch, p, &conf.Manager.SubmissionWriter, &pldconf.PublicTxManagerDefaults.Manager.SubmissionWriter)
+	sw.Writer = flushwriter.NewWriter(bgCtx, "public_tx_submission", sw.runBatch, p, &conf.Manager.SubmissionWriter, &pldconf.PublicTxManagerDefaults.Manager.SubmissionWriter)
 	return sw
 }
 
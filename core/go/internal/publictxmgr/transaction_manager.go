@@ -43,11 +43,15 @@ import (
 	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
 
 	"github.com/kaleido-io/paladin/core/internal/msgs"
+	"github.com/kaleido-io/paladin/core/internal/tracing"
 
+	"go.opentelemetry.io/otel/attribute"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 )
 
+var tracer = tracing.Tracer("publictxmgr")
+
 // configurations
 // metrics
 
@@ -60,6 +64,9 @@ const (
 	UpdateDelete                   // Instructs that the transaction should be removed completely from persistence - generally only returned when TX status is TxStatusDeleteRequested
 )
 
+// the intrinsic gas cost of a simple transfer, which is what we replace a transaction with to cancel it
+const cancelTransactionGas = 21000
+
 // Public Tx Engine:
 // - It offers two ways of calculating gas price: use a fixed number, use the built-in API of a ethereum connector
 // - It resubmits the transaction based on a configured interval until it succeed or fail
@@ -146,10 +153,14 @@ func NewPublicTransactionManager(ctx context.Context, conf *pldconf.PublicTxMana
 		gasPriceIncreasePercent:     confutil.Int(conf.GasPrice.IncreasePercentage, *pldconf.PublicTxManagerDefaults.GasPrice.IncreasePercentage),
 		activityRecordCache:         cache.NewCache[uint64, *txActivityRecords](&conf.Manager.ActivityRecords.CacheConfig, &pldconf.PublicTxManagerDefaults.Manager.ActivityRecords.CacheConfig),
 		maxActivityRecordsPerTx:     confutil.Int(conf.Manager.ActivityRecords.RecordsPerTransaction, *pldconf.PublicTxManagerDefaults.Manager.ActivityRecords.RecordsPerTransaction),
+		thMetrics:                   newPublicTxEngineMetrics(),
 	}
 }
 
 func (ble *pubTxManager) PreInit(pic components.PreInitComponents) (result *components.ManagerInitResult, err error) {
+	if err := ble.thMetrics.InitMetrics(ble.ctx, pic.MetricsManager().Registry()); err != nil {
+		return nil, err
+	}
 	return &components.ManagerInitResult{}, nil
 }
 
@@ -288,6 +299,10 @@ func (ble *pubTxManager) ValidateTransaction(ctx context.Context, dbTX *gorm.DB,
 }
 
 func (ble *pubTxManager) WriteNewTransactions(ctx context.Context, dbTX *gorm.DB, transactions []*components.PublicTxSubmission) (postCommit func(), pubTxns []*pldapi.PublicTx, err error) {
+	ctx, span := tracer.Start(ctx, "publictx.write_new_transactions")
+	span.SetAttributes(attribute.Int("paladin.transaction_count", len(transactions)))
+	defer func() { tracing.EndSpan(span, err) }()
+
 	persistedTransactions := make([]*DBPublicTxn, len(transactions))
 	for i, txi := range transactions {
 		persistedTransactions[i] = &DBPublicTxn{
@@ -562,19 +577,100 @@ func (ble *pubTxManager) getTransactionSubmissions(ctx context.Context, dbTX *go
 }
 
 func (ble *pubTxManager) SuspendTransaction(ctx context.Context, from tktypes.EthAddress, nonce uint64) error {
-	if err := ble.dispatchAction(ctx, from, nonce, ActionSuspend); err != nil {
+	if err := ble.dispatchAction(ctx, from, nonce, ActionSuspend, nil); err != nil {
 		return err
 	}
 	return nil
 }
 
 func (ble *pubTxManager) ResumeTransaction(ctx context.Context, from tktypes.EthAddress, nonce uint64) error {
-	if err := ble.dispatchAction(ctx, from, nonce, ActionResume); err != nil {
+	if err := ble.dispatchAction(ctx, from, nonce, ActionResume, nil); err != nil {
 		return err
 	}
 	return nil
 }
 
+func (ble *pubTxManager) getPendingTransactionByNonce(ctx context.Context, from tktypes.EthAddress, nonce uint64) (*DBPublicTxn, error) {
+	var ptxs []*DBPublicTxn
+	err := ble.p.DB().
+		WithContext(ctx).
+		Table("public_txns").
+		Where(`"from" = ?`, from).
+		Where("nonce = ?", nonce).
+		Joins("Completed").
+		Limit(1).
+		Find(&ptxs).
+		Error
+	if err != nil {
+		return nil, err
+	}
+	if len(ptxs) == 0 {
+		return nil, i18n.NewError(ctx, msgs.MsgPublicTxNotFoundForNonce, from, nonce)
+	}
+	if ptxs[0].Completed != nil {
+		return nil, i18n.NewError(ctx, msgs.MsgPublicTxAlreadyCompleted, from, nonce)
+	}
+	return ptxs[0], nil
+}
+
+// Component interface: update the data and/or gas pricing of a pending transaction, which is submitted
+// as a replacement transaction with the same nonce (remaining bound to the same Paladin transaction)
+func (ble *pubTxManager) UpdateTransaction(ctx context.Context, from tktypes.EthAddress, nonce uint64, update *pldapi.PublicTxUpdate) error {
+	ptx, err := ble.getPendingTransactionByNonce(ctx, from, nonce)
+	if err != nil {
+		return err
+	}
+
+	replacement := &TxReplacement{
+		To:    ptx.To,
+		Data:  ptx.Data,
+		Gas:   ptx.Gas,
+		Value: ptx.Value,
+	}
+	if update.Value != nil {
+		replacement.Value = update.Value
+	}
+	if update.GasPrice != nil || update.MaxFeePerGas != nil || update.MaxPriorityFeePerGas != nil {
+		replacement.GasPricing = &update.PublicTxGasPricing
+	}
+	if update.Data != nil {
+		replacement.Data = update.Data
+	}
+	if update.Gas != nil && *update.Gas != 0 {
+		replacement.Gas = update.Gas.Uint64()
+	} else if update.Data != nil {
+		// The new calldata might need a different amount of gas
+		gasEstimateResult, err := ble.ethClient.EstimateGasNoResolve(ctx, buildEthTX(
+			from,
+			nil, /* estimate as the next transaction, rather than replacing our nonce */
+			replacement.To,
+			replacement.Data,
+			&pldapi.PublicTxOptions{Value: replacement.Value, PublicTxGasPricing: update.PublicTxGasPricing},
+		))
+		if err != nil {
+			if ethclient.MapSubmissionRejected(err) && len(gasEstimateResult.RevertData) > 0 {
+				return ble.rootTxMgr.CalculateRevertError(ctx, ble.p.DB(), gasEstimateResult.RevertData)
+			}
+			return err
+		}
+		replacement.Gas = gasEstimateResult.GasLimit.Uint64()
+	}
+
+	return ble.dispatchAction(ctx, from, nonce, ActionReplace, replacement)
+}
+
+// Component interface: cancel a pending transaction, by replacing it with a zero value transfer
+// from the signing address to itself, with the same nonce
+func (ble *pubTxManager) CancelTransaction(ctx context.Context, from tktypes.EthAddress, nonce uint64) error {
+	if _, err := ble.getPendingTransactionByNonce(ctx, from, nonce); err != nil {
+		return err
+	}
+	return ble.dispatchAction(ctx, from, nonce, ActionReplace, &TxReplacement{
+		To:  &from,
+		Gas: cancelTransactionGas,
+	})
+}
+
 func (pte *pubTxManager) UpdateSubStatus(ctx context.Context, imtx InMemoryTxStateReadOnly, subStatus BaseTxSubStatus, action BaseTxAction, info *fftypes.JSONAny, err *fftypes.JSONAny, actionOccurred *tktypes.Timestamp) error {
 	// TODO: Choose after testing the right way to treat these records - if text is right or not
 	if err == nil {
@@ -733,10 +829,27 @@ func (pte *pubTxManager) MatchUpdateConfirmedTransactions(ctx context.Context, d
 
 }
 
+// Called when a chain re-org removes blocks after forkBlock, before the indexed transactions in those blocks
+// are deleted. Removes the completions of the transactions confirmed in those blocks, so they are pending
+// again and are processed through to confirmation on the new canonical chain (with the same nonce).
+func (pte *pubTxManager) RewindConfirmedTransactions(ctx context.Context, dbTX *gorm.DB, forkBlock int64) (func(), error) {
+	result := dbTX.
+		WithContext(ctx).
+		Table("public_completions").
+		Where(`"tx_hash" IN (?)`, dbTX.Table("indexed_transactions").Select(`"hash"`).Where(`"block_number" > ?`, forkBlock)).
+		Delete(&DBPublicTxnCompletion{})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	log.L(ctx).Warnf("Returned %d public transactions confirmed after block %d to pending", result.RowsAffected, forkBlock)
+	// The orchestrators need to poll to load the pending transactions again
+	return pte.MarkInFlightOrchestratorsStale, nil
+}
+
 // We've got to be super careful not to block this thread, so we treat this just like a suspend/resume
 // on each of these transactions
 func (pte *pubTxManager) NotifyConfirmPersisted(ctx context.Context, confirms []*components.PublicTxMatch) {
 	for _, conf := range confirms {
-		_ = pte.dispatchAction(ctx, *conf.From, conf.Nonce, ActionCompleted)
+		_ = pte.dispatchAction(ctx, *conf.From, conf.Nonce, ActionCompleted, nil)
 	}
 }
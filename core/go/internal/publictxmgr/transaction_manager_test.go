@@ -32,6 +32,7 @@ import (
 	"github.com/kaleido-io/paladin/config/pkg/pldconf"
 	"github.com/kaleido-io/paladin/core/internal/components"
 	"github.com/kaleido-io/paladin/core/internal/keymanager"
+	"github.com/kaleido-io/paladin/core/internal/metrics"
 	"github.com/kaleido-io/paladin/core/mocks/componentmocks"
 	"github.com/kaleido-io/paladin/core/mocks/ethclientmocks"
 	"github.com/kaleido-io/paladin/core/pkg/blockindexer"
@@ -78,6 +79,7 @@ func baseMocks(t *testing.T) *mocksAndTestControl {
 	mocks.ethClientFactory.On("HTTPClient").Return(mocks.ethClient).Maybe()
 	mocks.allComponents.On("BlockIndexer").Return(mocks.blockIndexer).Maybe()
 	mocks.allComponents.On("TxManager").Return(mocks.txManager).Maybe()
+	mocks.allComponents.On("MetricsManager").Return(metrics.NewMetricsManager(context.Background())).Maybe()
 	return mocks
 }
 
@@ -200,6 +202,18 @@ func TestNewEngineErrors(t *testing.T) {
 	assert.Regexp(t, "lookup failed", err)
 }
 
+func TestPreInitMetricsError(t *testing.T) {
+	mm := metrics.NewMetricsManager(context.Background())
+	err := newPublicTxEngineMetrics().InitMetrics(context.Background(), mm.Registry())
+	require.NoError(t, err)
+
+	mac := componentmocks.NewAllComponents(t)
+	mac.On("MetricsManager").Return(mm)
+	pmgr := NewPublicTransactionManager(context.Background(), &pldconf.PublicTxManagerConfig{})
+	_, err = pmgr.PreInit(mac)
+	assert.Regexp(t, "duplicate metrics collector registration", err)
+}
+
 func TestInit(t *testing.T) {
 	_, _, _, done := newTestPublicTxManager(t, false)
 	defer done()
@@ -578,3 +592,326 @@ func TestEngineSuspendResumeRealDB(t *testing.T) {
 	assert.Equal(t, txNonce, newNonce)
 
 }
+
+func TestEngineUpdateCancelRealDB(t *testing.T) {
+
+	ctx, ble, m, done := newTestPublicTxManager(t, true, func(mocks *mocksAndTestControl, conf *pldconf.PublicTxManagerConfig) {
+		conf.Manager.Interval = confutil.P("50ms")
+		conf.Orchestrator.Interval = confutil.P("50ms")
+		conf.Manager.OrchestratorIdleTimeout = confutil.P("1ms")
+		conf.GasPrice.FixedGasPrice = nil
+	})
+	defer done()
+
+	keyMapping, err := m.keyManager.ResolveKeyNewDatabaseTX(ctx, "signer1", algorithms.ECDSA_SECP256K1, verifiers.ETH_ADDRESS)
+	require.NoError(t, err)
+	resolvedKey := *tktypes.MustEthAddress(keyMapping.Verifier.Verifier)
+
+	chainID, _ := rand.Int(rand.Reader, big.NewInt(100000000000000))
+	m.ethClient.On("ChainID").Return(chainID.Int64())
+	m.ethClient.On("GasPrice", mock.Anything).Return(tktypes.MustParseHexUint256("1000000000000000"), nil)
+	m.ethClient.On("GetTransactionCount", mock.Anything, mock.Anything).Return(confutil.P(tktypes.HexUint64(1122334455)), nil)
+
+	// Capture each submission
+	submissions := make(chan *ethsigner.Transaction, 10)
+	srtx := m.ethClient.On("SendRawTransaction", mock.Anything, mock.Anything)
+	srtx.Run(func(args mock.Arguments) {
+		signedMessage := args[1].(tktypes.HexBytes)
+		_, ethTx, err := ethsigner.RecoverRawTransaction(ctx, ethtypes.HexBytes0xPrefix(signedMessage), m.ethClient.ChainID())
+		require.NoError(t, err)
+		submissions <- ethTx.Transaction
+		srtx.Return(calculateTransactionHash(signedMessage), nil)
+	})
+	waitSubmission := func() *ethsigner.Transaction {
+		select {
+		case ethTx := <-submissions:
+			return ethTx
+		case <-time.After(5 * time.Second):
+			require.FailNow(t, "timed out waiting for submission")
+			return nil
+		}
+	}
+
+	txID := uuid.New()
+	fakeTxManagerInsert(t, ble.p.DB(), txID, "signer1")
+	target := tktypes.RandAddress()
+	_, err = ble.SingleTransactionSubmit(ctx, &components.PublicTxSubmission{
+		Bindings: []*components.PaladinTXReference{
+			{TransactionID: txID, TransactionType: pldapi.TransactionTypePublic.Enum()},
+		},
+		PublicTxInput: pldapi.PublicTxInput{
+			From: &resolvedKey,
+			To:   target,
+			Data: tktypes.HexBytes("data1"),
+			PublicTxOptions: pldapi.PublicTxOptions{
+				Gas: confutil.P(tktypes.HexUint64(1223451)),
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	ethTx := waitSubmission()
+	nonce := ethTx.Nonce.Uint64()
+	assert.Equal(t, ethtypes.HexBytes0xPrefix("data1"), ethTx.Data)
+
+	// Update the data - with no gas pricing, so the gas price is increased
+	err = ble.UpdateTransaction(ctx, resolvedKey, nonce, &pldapi.PublicTxUpdate{
+		Data: tktypes.HexBytes("data2"),
+		PublicTxOptions: pldapi.PublicTxOptions{
+			Gas: confutil.P(tktypes.HexUint64(2334562)),
+		},
+	})
+	require.NoError(t, err)
+	ethTx = waitSubmission()
+	assert.Equal(t, nonce, ethTx.Nonce.Uint64())
+	assert.Equal(t, ethtypes.HexBytes0xPrefix("data2"), ethTx.Data)
+	assert.Equal(t, uint64(2334562), ethTx.GasLimit.Uint64())
+
+	// Update with explicit gas pricing - where the priority fee is not enough of an increase
+	// over the previous gas price to be accepted, so is increased to the minimum required
+	err = ble.UpdateTransaction(ctx, resolvedKey, nonce, &pldapi.PublicTxUpdate{
+		PublicTxOptions: pldapi.PublicTxOptions{
+			PublicTxGasPricing: pldapi.PublicTxGasPricing{
+				MaxFeePerGas:         tktypes.MustParseHexUint256("2000000000000000"),
+				MaxPriorityFeePerGas: tktypes.MustParseHexUint256("1000000000000000"),
+			},
+		},
+	})
+	require.NoError(t, err)
+	ethTx = waitSubmission()
+	assert.Equal(t, nonce, ethTx.Nonce.Uint64())
+	assert.Equal(t, ethtypes.HexBytes0xPrefix("data2"), ethTx.Data)
+	assert.Equal(t, "2000000000000000", ethTx.MaxFeePerGas.BigInt().String())
+	assert.Equal(t, "1210000000000000", ethTx.MaxPriorityFeePerGas.BigInt().String())
+
+	// Cancel
+	err = ble.CancelTransaction(ctx, resolvedKey, nonce)
+	require.NoError(t, err)
+	ethTx = waitSubmission()
+	assert.Equal(t, nonce, ethTx.Nonce.Uint64())
+	assert.Equal(t, resolvedKey.String(), ethTx.To.String())
+	assert.Empty(t, ethTx.Data)
+	assert.Equal(t, uint64(cancelTransactionGas), ethTx.GasLimit.Uint64())
+	assert.Equal(t, "2200000000000000", ethTx.MaxFeePerGas.BigInt().String())
+	assert.Equal(t, "1331000000000000", ethTx.MaxPriorityFeePerGas.BigInt().String())
+
+	// All the submissions are recorded against the same public transaction, still bound to our TX
+	byTxn, err := ble.QueryPublicTxForTransactions(ctx, ble.p.DB(), []uuid.UUID{txID}, nil)
+	require.NoError(t, err)
+	require.Len(t, byTxn[txID], 1)
+	ptx := byTxn[txID][0]
+	assert.Equal(t, resolvedKey, *ptx.To)
+	assert.Empty(t, ptx.Data)
+	// The gas pricing of the replacement is persisted after it has been increased
+	assert.Equal(t, "2200000000000000", ptx.MaxFeePerGas.Int().String())
+	assert.Equal(t, "1331000000000000", ptx.MaxPriorityFeePerGas.Int().String())
+	// Submissions are flushed before they are sent, and are returned newest first
+	require.Len(t, ptx.Submissions, 4)
+	assert.Equal(t, "1000000000000000", ptx.Submissions[3].GasPrice.Int().String())
+	assert.Equal(t, "1100000000000000", ptx.Submissions[2].GasPrice.Int().String())
+	assert.Equal(t, "2000000000000000", ptx.Submissions[1].MaxFeePerGas.Int().String())
+	assert.Equal(t, "2200000000000000", ptx.Submissions[0].MaxFeePerGas.Int().String())
+
+	// Confirm the cancellation
+	matches, err := ble.MatchUpdateConfirmedTransactions(ctx, ble.p.DB(), []*blockindexer.IndexedTransactionNotify{
+		{
+			IndexedTransaction: pldapi.IndexedTransaction{
+				Hash:   ptx.Submissions[0].TransactionHash,
+				From:   &resolvedKey,
+				To:     &resolvedKey,
+				Nonce:  nonce,
+				Result: pldapi.TXResult_SUCCESS.Enum(),
+			},
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, matches, 1)
+	assert.Equal(t, txID, matches[0].TransactionID)
+	ble.NotifyConfirmPersisted(ctx, matches)
+
+	// Can't update once complete
+	err = ble.CancelTransaction(ctx, resolvedKey, nonce)
+	assert.Regexp(t, "PD011938", err)
+
+}
+
+func TestEngineUpdateExceedsMaxGasPriceRealDB(t *testing.T) {
+
+	ctx, ble, m, done := newTestPublicTxManager(t, true, func(mocks *mocksAndTestControl, conf *pldconf.PublicTxManagerConfig) {
+		conf.Manager.Interval = confutil.P("50ms")
+		conf.Orchestrator.Interval = confutil.P("50ms")
+		conf.GasPrice.FixedGasPrice = nil
+		conf.GasPrice.IncreaseMax = confutil.P("1050000000000000")
+	})
+	defer done()
+
+	keyMapping, err := m.keyManager.ResolveKeyNewDatabaseTX(ctx, "signer1", algorithms.ECDSA_SECP256K1, verifiers.ETH_ADDRESS)
+	require.NoError(t, err)
+	resolvedKey := *tktypes.MustEthAddress(keyMapping.Verifier.Verifier)
+
+	chainID, _ := rand.Int(rand.Reader, big.NewInt(100000000000000))
+	m.ethClient.On("ChainID").Return(chainID.Int64())
+	m.ethClient.On("GasPrice", mock.Anything).Return(tktypes.MustParseHexUint256("1000000000000000"), nil)
+	m.ethClient.On("GetTransactionCount", mock.Anything, mock.Anything).Return(confutil.P(tktypes.HexUint64(1122334455)), nil)
+
+	submissions := make(chan *ethsigner.Transaction, 10)
+	srtx := m.ethClient.On("SendRawTransaction", mock.Anything, mock.Anything)
+	srtx.Run(func(args mock.Arguments) {
+		signedMessage := args[1].(tktypes.HexBytes)
+		_, ethTx, err := ethsigner.RecoverRawTransaction(ctx, ethtypes.HexBytes0xPrefix(signedMessage), m.ethClient.ChainID())
+		require.NoError(t, err)
+		submissions <- ethTx.Transaction
+		srtx.Return(calculateTransactionHash(signedMessage), nil)
+	})
+
+	txID := uuid.New()
+	fakeTxManagerInsert(t, ble.p.DB(), txID, "signer1")
+	_, err = ble.SingleTransactionSubmit(ctx, &components.PublicTxSubmission{
+		Bindings: []*components.PaladinTXReference{
+			{TransactionID: txID, TransactionType: pldapi.TransactionTypePublic.Enum()},
+		},
+		PublicTxInput: pldapi.PublicTxInput{
+			From: &resolvedKey,
+			To:   tktypes.RandAddress(),
+			Data: tktypes.HexBytes("data1"),
+			PublicTxOptions: pldapi.PublicTxOptions{
+				Gas: confutil.P(tktypes.HexUint64(1223451)),
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	var ethTx *ethsigner.Transaction
+	select {
+	case ethTx = <-submissions:
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "timed out waiting for submission")
+	}
+
+	// The maximum gas price does not allow the minimum increase for a replacement
+	err = ble.CancelTransaction(ctx, resolvedKey, ethTx.Nonce.Uint64())
+	assert.Regexp(t, "PD011940", err)
+
+	// Nothing is persisted for the rejected replacement
+	byTxn, err := ble.QueryPublicTxForTransactions(ctx, ble.p.DB(), []uuid.UUID{txID}, nil)
+	require.NoError(t, err)
+	require.Len(t, byTxn[txID], 1)
+	assert.Equal(t, tktypes.HexBytes("data1"), byTxn[txID][0].Data)
+
+}
+
+func TestEngineUpdateNotInFlight(t *testing.T) {
+
+	ctx, ble, m, done := newTestPublicTxManager(t, true, func(mocks *mocksAndTestControl, conf *pldconf.PublicTxManagerConfig) {
+		mocks.disableManagerStart = true
+	})
+	defer done()
+
+	keyMapping, err := m.keyManager.ResolveKeyNewDatabaseTX(ctx, "signer1", algorithms.ECDSA_SECP256K1, verifiers.ETH_ADDRESS)
+	require.NoError(t, err)
+	resolvedKey := *tktypes.MustEthAddress(keyMapping.Verifier.Verifier)
+
+	err = ble.CancelTransaction(ctx, resolvedKey, 12345)
+	assert.Regexp(t, "PD011937", err)
+
+	// Write a transaction with a nonce directly, as if it had been allocated
+	ptx := &DBPublicTxn{
+		From:  resolvedKey,
+		Nonce: confutil.P(uint64(12345)),
+		To:    tktypes.RandAddress(),
+		Gas:   100000,
+		Data:  tktypes.HexBytes("data1"),
+	}
+	err = ble.p.DB().Create(ptx).Error
+	require.NoError(t, err)
+
+	// Updating the data requires a gas estimate, which can fail
+	m.ethClient.On("EstimateGasNoResolve", mock.Anything, mock.Anything, mock.Anything).
+		Return(ethclient.EstimateGasResult{}, fmt.Errorf("pop")).Once()
+	err = ble.UpdateTransaction(ctx, resolvedKey, 12345, &pldapi.PublicTxUpdate{Data: tktypes.HexBytes("data2")})
+	assert.Regexp(t, "pop", err)
+
+	// With no orchestrator, the DB is updated directly as it has never been submitted
+	m.ethClient.On("EstimateGasNoResolve", mock.Anything, mock.Anything, mock.Anything).
+		Return(ethclient.EstimateGasResult{GasLimit: 200000}, nil).Once()
+	err = ble.UpdateTransaction(ctx, resolvedKey, 12345, &pldapi.PublicTxUpdate{Data: tktypes.HexBytes("data2")})
+	require.NoError(t, err)
+	txs, err := ble.QueryPublicTxWithBindings(ctx, ble.p.DB(), query.NewQueryBuilder().Equal("localId", ptx.PublicTxnID).Limit(1).Query())
+	require.NoError(t, err)
+	require.Len(t, txs, 1)
+	assert.Equal(t, tktypes.HexBytes("data2"), txs[0].Data)
+	assert.Equal(t, uint64(200000), txs[0].Gas.Uint64())
+
+	// Once there is a submission, we can't update it without it being in-flight
+	err = ble.p.DB().Create(&DBPubTxnSubmission{
+		PublicTxnID:     ptx.PublicTxnID,
+		Created:         tktypes.TimestampNow(),
+		TransactionHash: tktypes.Bytes32(tktypes.RandBytes(32)),
+	}).Error
+	require.NoError(t, err)
+	err = ble.CancelTransaction(ctx, resolvedKey, 12345)
+	assert.Regexp(t, "PD011939", err)
+
+}
+
+func TestRewindConfirmedTransactionsRealDB(t *testing.T) {
+	ctx, ble, _, done := newTestPublicTxManager(t, true, func(mocks *mocksAndTestControl, conf *pldconf.PublicTxManagerConfig) {
+		mocks.disableManagerStart = true
+	})
+	defer done()
+
+	from := *tktypes.RandAddress()
+	db := ble.p.DB()
+	completedInBlock := func(nonce uint64, blockNumber int64) uint64 {
+		ptx := &DBPublicTxn{From: from, Nonce: &nonce, Gas: 21000}
+		err := db.Table("public_txns").Create(ptx).Error
+		require.NoError(t, err)
+		txHash := tktypes.Bytes32(tktypes.RandBytes(32))
+		err = db.Table("indexed_blocks").Create(&pldapi.IndexedBlock{
+			Number: blockNumber,
+			Hash:   tktypes.Bytes32(tktypes.RandBytes(32)),
+		}).Error
+		require.NoError(t, err)
+		err = db.Table("indexed_transactions").Create(&pldapi.IndexedTransaction{
+			Hash:        txHash,
+			BlockNumber: blockNumber,
+			From:        &from,
+			Nonce:       nonce,
+			Result:      pldapi.TXResult_SUCCESS.Enum(),
+		}).Error
+		require.NoError(t, err)
+		err = db.Create(&DBPublicTxnCompletion{
+			PublicTxnID:     ptx.PublicTxnID,
+			TransactionHash: txHash,
+			Success:         true,
+		}).Error
+		require.NoError(t, err)
+		return ptx.PublicTxnID
+	}
+	beforeFork := completedInBlock(0, 100)
+	afterFork := completedInBlock(1, 101)
+
+	postCommit, err := ble.RewindConfirmedTransactions(ctx, db, 100)
+	require.NoError(t, err)
+	require.NotNil(t, postCommit)
+	postCommit()
+
+	var completions []*DBPublicTxnCompletion
+	err = db.Find(&completions).Error
+	require.NoError(t, err)
+	require.Len(t, completions, 1)
+	assert.Equal(t, beforeFork, completions[0].PublicTxnID)
+	assert.NotEqual(t, afterFork, completions[0].PublicTxnID)
+}
+
+func TestRewindConfirmedTransactionsFail(t *testing.T) {
+	ctx, ble, m, done := newTestPublicTxManager(t, false, func(mocks *mocksAndTestControl, conf *pldconf.PublicTxManagerConfig) {
+		mocks.disableManagerStart = true
+	})
+	defer done()
+
+	m.db.ExpectExec("DELETE.*public_completions").WillReturnError(fmt.Errorf("pop"))
+
+	_, err := ble.RewindConfirmedTransactions(ctx, ble.p.DB(), 100)
+	assert.Regexp(t, "pop", err)
+}
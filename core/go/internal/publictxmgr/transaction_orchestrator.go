@@ -190,6 +190,7 @@ func (oc *orchestrator) orchestratorLoop() {
 	log.L(ctx).Infof("Orchestrator for signing address %s started polling based on interval %s", oc.signingAddress, oc.orchestratorPollingInterval)
 
 	defer close(oc.orchestratorLoopDone)
+	defer oc.thMetrics.ClearInFlightTxQueueMetrics(ctx, oc.signingAddress.String())
 
 	if err := oc.initNextNonceFromDBRetry(ctx); err != nil {
 		log.L(ctx).Warnf("Context cancelled while obtaining highest nonce for %s: %s", oc.signingAddress, err)
@@ -428,8 +429,8 @@ func (oc *orchestrator) pollAndProcess(ctx context.Context) (polled int, total i
 		if polled > 0 {
 			log.L(ctx).Debugf("InFlight set updated len=%d head-nonce=%d tail-nonce=%d old-tail=%d", len(oc.inFlightTxs), oc.inFlightTxs[0].stateManager.GetNonce(), oc.inFlightTxs[total-1].stateManager.GetNonce(), highestInFlightNonce)
 		}
-		oc.thMetrics.RecordInFlightTxQueueMetrics(ctx, stageCounts, oc.maxInFlightTxs-len(oc.inFlightTxs))
 	}
+	oc.thMetrics.RecordInFlightTxQueueMetrics(ctx, oc.signingAddress.String(), stageCounts, oc.maxInFlightTxs-len(oc.inFlightTxs))
 	log.L(ctx).Debugf("Orchestrator polling from DB took %s", time.Since(pollStart))
 	// now check and process each transaction
 
@@ -23,9 +23,11 @@ import (
 	"github.com/hyperledger/firefly-common/pkg/i18n"
 	"github.com/kaleido-io/paladin/config/pkg/confutil"
 	"github.com/kaleido-io/paladin/core/internal/msgs"
+	"github.com/kaleido-io/paladin/core/internal/tracing"
 	"github.com/kaleido-io/paladin/core/pkg/ethclient"
 	"github.com/kaleido-io/paladin/toolkit/pkg/log"
 	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
+	"go.opentelemetry.io/otel/attribute"
 	"golang.org/x/crypto/sha3"
 )
 
@@ -40,12 +42,18 @@ func calculateTransactionHash(rawTxnData []byte) *tktypes.Bytes32 {
 }
 
 func (it *inFlightTransactionStageController) submitTX(ctx context.Context, mtx InMemoryTxStateReadOnly, signedMessage []byte) (*tktypes.Bytes32, *tktypes.Timestamp, ethclient.ErrorReason, SubmissionOutcome, error) {
+	_, span := tracer.Start(ctx, "publictx.submit")
+	span.SetAttributes(attribute.String("paladin.signer_nonce", mtx.GetSignerNonce()))
+
 	var txHash *tktypes.Bytes32
 	sendStart := time.Now()
 	calculatedTxHash := mtx.GetTransactionHash() // must have been persisted in previous stage
 	if calculatedTxHash == nil {
-		return nil, nil, ethclient.ErrorReasonInvalidInputs, SubmissionOutcomeFailedRequiresRetry, i18n.NewError(ctx, msgs.MsgInvalidStateMissingTXHash)
+		err := i18n.NewError(ctx, msgs.MsgInvalidStateMissingTXHash)
+		tracing.EndSpan(span, err)
+		return nil, nil, ethclient.ErrorReasonInvalidInputs, SubmissionOutcomeFailedRequiresRetry, err
 	}
+	span.SetAttributes(attribute.String("paladin.transaction_hash", calculatedTxHash.String()))
 	log.L(ctx).Debugf("Sending raw transaction %s (lastSubmit=%s), Hash=%s", mtx.GetSignerNonce(), mtx.GetLastSubmitTime(), txHash)
 
 	submissionTime := confutil.P(tktypes.TimestampNow())
@@ -123,8 +131,11 @@ func (it *inFlightTransactionStageController) submitTX(ctx context.Context, mtx
 	})
 
 	if retryError != nil {
+		tracing.EndSpan(span, retryError)
 		return nil, submissionTime, submissionErrorReason, SubmissionOutcomeFailedRequiresRetry, retryError
 	}
 
+	span.SetAttributes(attribute.String("paladin.submission_outcome", string(submissionOutcome)))
+	tracing.EndSpan(span, submissionError)
 	return txHash, submissionTime, submissionErrorReason, submissionOutcome, submissionError
 }
@@ -54,6 +54,17 @@ type BaseTXUpdates struct {
 	ErrorMessage      *string
 	NewSubmission     *DBPubTxnSubmission
 	FlushedSubmission *DBPubTxnSubmission
+	Replacement       *TxReplacement
+}
+
+// TxReplacement contains the new values for a transaction that has been updated or cancelled
+// by the user, which must be submitted as a replacement transaction with the same nonce
+type TxReplacement struct {
+	To         *tktypes.EthAddress
+	Data       tktypes.HexBytes
+	Gas        uint64
+	Value      *tktypes.HexUint256
+	GasPricing *pldapi.PublicTxGasPricing // nil if the gas price of the previous submission should be increased
 }
 
 // PublicTransactionEventType is a enum type that contains all types of transaction process events
@@ -399,6 +410,7 @@ type InFlightTransactionStateManager interface {
 	CanSubmit(ctx context.Context, cost *big.Int) bool
 	CanBeRemoved(ctx context.Context) bool
 	GetInFlightStatus() InFlightStatus
+	ApplyInMemoryUpdates(ctx context.Context, txUpdates *BaseTXUpdates)
 
 	// stage management
 	StartNewStageContext(ctx context.Context, stage InFlightTxStage, substatus BaseTxSubStatus)
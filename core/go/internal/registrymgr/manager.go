@@ -42,6 +42,8 @@ type registryManager struct {
 
 	p            persistence.Persistence
 	blockIndexer blockindexer.BlockIndexer
+	txManager    components.TXManager
+	transportMgr components.TransportManager
 	rpcModule    *rpcserver.RPCModule
 
 	// We provide a high level of customization of how the nodes are looked up in the registry
@@ -86,6 +88,8 @@ func (rm *registryManager) PreInit(pic components.PreInitComponents) (_ *compone
 
 func (rm *registryManager) PostInit(c components.AllComponents) error {
 	rm.blockIndexer = c.BlockIndexer()
+	rm.txManager = c.TxManager()
+	rm.transportMgr = c.TransportManager()
 	return nil
 }
 
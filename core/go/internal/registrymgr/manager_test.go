@@ -31,6 +31,7 @@ import (
 	"github.com/kaleido-io/paladin/toolkit/pkg/prototk"
 	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 )
 
@@ -39,6 +40,8 @@ type mockComponents struct {
 	db            sqlmock.Sqlmock
 	allComponents *componentmocks.AllComponents
 	blockIndexer  *componentmocks.BlockIndexer
+	txManager     *componentmocks.TXManager
+	transportMgr  *componentmocks.TransportManager
 }
 
 func newTestRegistryManager(t *testing.T, realDB bool, conf *pldconf.RegistryManagerConfig, extraSetup ...func(mc *mockComponents)) (context.Context, *registryManager, *mockComponents, func()) {
@@ -46,9 +49,14 @@ func newTestRegistryManager(t *testing.T, realDB bool, conf *pldconf.RegistryMan
 
 	mc := &mockComponents{
 		blockIndexer:  componentmocks.NewBlockIndexer(t),
+		txManager:     componentmocks.NewTXManager(t),
+		transportMgr:  componentmocks.NewTransportManager(t),
 		allComponents: componentmocks.NewAllComponents(t),
 	}
 	mc.allComponents.On("BlockIndexer").Return(mc.blockIndexer).Maybe()
+	mc.allComponents.On("TxManager").Return(mc.txManager).Maybe()
+	mc.allComponents.On("TransportManager").Return(mc.transportMgr).Maybe()
+	mc.transportMgr.On("TransportDetailsChanged", mock.Anything).Maybe()
 
 	var p persistence.Persistence
 	var err error
@@ -161,6 +161,52 @@ func (r *registry) UpsertRegistryRecords(ctx context.Context, req *prototk.Upser
 	return &prototk.UpsertRegistryRecordsResponse{}, nil
 }
 
+func (r *registry) SendTransaction(ctx context.Context, req *prototk.SendTransactionRequest) (*prototk.SendTransactionResponse, error) {
+	contractAddr, err := tktypes.ParseEthAddress(req.ContractAddress)
+	if err != nil {
+		return nil, i18n.WrapError(ctx, err, msgs.MsgRegistryTxInvalidContract, r.name, req.ContractAddress)
+	}
+	if !r.isRegistryContract(contractAddr) {
+		return nil, i18n.NewError(ctx, msgs.MsgRegistryTxContractNotAllowed, r.name, contractAddr)
+	}
+
+	var functionABI abi.Entry
+	if err := json.Unmarshal([]byte(req.FunctionAbiJson), &functionABI); err != nil {
+		return nil, i18n.WrapError(ctx, err, msgs.MsgRegistryTxInvalidFunctionABI, r.name)
+	}
+
+	// Registries only submit public transactions directly against their own smart contracts.
+	// Validation of the signer and parameters is performed by the transaction manager.
+	txID, err := r.rm.txManager.SendTransaction(ctx, &pldapi.TransactionInput{
+		TransactionBase: pldapi.TransactionBase{
+			Type:     pldapi.TransactionTypePublic.Enum(),
+			From:     req.From,
+			To:       contractAddr,
+			Function: functionABI.String(),
+			Data:     tktypes.RawJSON(req.ParamsJson),
+		},
+		ABI: abi.ABI{&functionABI},
+	})
+	if err != nil {
+		return nil, err
+	}
+	log.L(ctx).Infof("Registry '%s' submitted transaction %s to %s (%s)", r.name, txID, contractAddr, functionABI.String())
+	return &prototk.SendTransactionResponse{TransactionId: txID.String()}, nil
+}
+
+// The contracts a registry can submit transactions to are the ones it listens to for events
+func (r *registry) isRegistryContract(addr *tktypes.EthAddress) bool {
+	if r.config == nil {
+		return false
+	}
+	for _, es := range r.config.EventSources {
+		if esAddr, err := tktypes.ParseEthAddress(es.ContractAddress); err == nil && esAddr.Equals(addr) {
+			return true
+		}
+	}
+	return false
+}
+
 func (r *registry) handleEventBatch(ctx context.Context, dbTX *gorm.DB, batch *blockindexer.EventDeliveryBatch) (blockindexer.PostCommit, error) {
 
 	// Build the proto version of these events
@@ -345,6 +391,10 @@ func (r *registry) upsertRegistryRecords(ctx context.Context, dbTX *gorm.DB, pro
 		//
 		// So instead we just zap the whole cache when we have an update.
 		r.rm.transportDetailsCache.Clear()
+
+		// For the same reason, the transports are told any node's details might have changed,
+		// so they can re-verify their existing connections against the latest details.
+		r.rm.transportMgr.TransportDetailsChanged(ctx)
 	}, nil
 }
 
@@ -542,6 +592,58 @@ func (r *registry) QueryEntriesWithProps(ctx context.Context, dbTX *gorm.DB, fAc
 	return withProps, nil
 }
 
+func (r *registry) RegisterIdentity(ctx context.Context, identity *pldapi.RegistryIdentityInput) (*uuid.UUID, error) {
+	// We apply the same name rules as we do when entries are indexed, so an entry is
+	// not registered on-chain that would then be discarded.
+	if err := tktypes.ValidateSafeCharsStartEndAlphaNum(ctx, identity.Name, tktypes.DefaultNameMaxLen, "name"); err != nil {
+		return nil, i18n.WrapError(ctx, err, msgs.MsgRegistryInvalidEntryName, identity.Name)
+	}
+
+	parentID := ""
+	if len(identity.ParentID) > 0 {
+		parentID = identity.ParentID.String()
+	}
+	res, err := r.api.RegisterIdentity(ctx, &prototk.RegisterIdentityRequest{
+		From:     identity.From,
+		ParentId: parentID,
+		Name:     identity.Name,
+		Owner:    identity.Owner,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.parseTransactionID(ctx, res.TransactionId)
+}
+
+func (r *registry) SetProperty(ctx context.Context, property *pldapi.RegistryPropertyInput) (*uuid.UUID, error) {
+	if len(property.EntryID) == 0 {
+		return nil, i18n.NewError(ctx, msgs.MsgRegistryInvalidEntryID, property.EntryID)
+	}
+	// Reserved "$" prefixed properties can only be set by the registry plugin itself
+	if err := tktypes.ValidateSafeCharsStartEndAlphaNum(ctx, property.Name, tktypes.DefaultNameMaxLen, "name"); err != nil {
+		return nil, i18n.WrapError(ctx, err, msgs.MsgRegistryInvalidPropertyName, property.Name)
+	}
+
+	res, err := r.api.SetProperty(ctx, &prototk.SetPropertyRequest{
+		From:    property.From,
+		EntryId: property.EntryID.String(),
+		Name:    property.Name,
+		Value:   property.Value,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.parseTransactionID(ctx, res.TransactionId)
+}
+
+func (r *registry) parseTransactionID(ctx context.Context, txIDStr string) (*uuid.UUID, error) {
+	txID, err := uuid.Parse(txIDStr)
+	if err != nil {
+		return nil, i18n.WrapError(ctx, err, msgs.MsgRegistryInvalidTransactionID, r.name, txIDStr)
+	}
+	return &txID, nil
+}
+
 func (r *registry) close() {
 	r.cancelCtx()
 	<-r.initDone
@@ -167,7 +167,7 @@ func newSystemPropFor(id, name, value string) *prototk.RegistryProperty {
 }
 
 func TestUpsertRegistryRecordsRealDBok(t *testing.T) {
-	ctx, rm, tp, _, done := newTestRegistry(t, true)
+	ctx, rm, tp, mc, done := newTestRegistry(t, true)
 	defer done()
 
 	r, err := rm.GetRegistry(ctx, "test1")
@@ -190,6 +190,7 @@ func TestUpsertRegistryRecordsRealDBok(t *testing.T) {
 	res, err := tp.r.UpsertRegistryRecords(ctx, upsert1)
 	require.NoError(t, err)
 	assert.NotNil(t, res)
+	mc.transportMgr.AssertCalled(t, "TransportDetailsChanged", mock.Anything)
 
 	// Test getting all the entries with props
 	entries, err := r.QueryEntriesWithProps(ctx, db, "active", query.NewQueryBuilder().Limit(100).Query())
@@ -602,3 +603,191 @@ func TestHandleEventBatchError(t *testing.T) {
 	require.Regexp(t, "pop", err)
 
 }
+
+func TestSendTransaction(t *testing.T) {
+
+	ctx, _, tp, mc, done := newTestRegistry(t, false)
+	defer done()
+
+	contractAddr := tktypes.RandAddress()
+	tp.r.config = &prototk.RegistryConfig{
+		EventSources: []*prototk.RegistryEventSource{
+			{ /* no address */ },
+			{ContractAddress: contractAddr.String()},
+		},
+	}
+	txID := uuid.New()
+	mc.txManager.On("SendTransaction", mock.Anything, mock.MatchedBy(func(tx *pldapi.TransactionInput) bool {
+		return tx.Type.V() == pldapi.TransactionTypePublic &&
+			tx.From == "key1" &&
+			tx.To.Equals(contractAddr) &&
+			tx.Function == "doStuff(string)" &&
+			tx.Data.String() == `{"thing":"value"}` &&
+			len(tx.ABI) == 1
+	})).Return(&txID, nil)
+
+	res, err := tp.r.SendTransaction(ctx, &prototk.SendTransactionRequest{
+		From:            "key1",
+		ContractAddress: contractAddr.String(),
+		FunctionAbiJson: `{"type":"function","name":"doStuff","inputs":[{"name":"thing","type":"string"}]}`,
+		ParamsJson:      `{"thing":"value"}`,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, txID.String(), res.TransactionId)
+
+}
+
+func TestSendTransactionFail(t *testing.T) {
+
+	ctx, _, tp, mc, done := newTestRegistry(t, false)
+	defer done()
+
+	mc.txManager.On("SendTransaction", mock.Anything, mock.Anything).Return(nil, fmt.Errorf("pop"))
+
+	contractAddr := tktypes.RandAddress()
+	tp.r.config = &prototk.RegistryConfig{
+		EventSources: []*prototk.RegistryEventSource{{ContractAddress: contractAddr.String()}},
+	}
+	_, err := tp.r.SendTransaction(ctx, &prototk.SendTransactionRequest{
+		ContractAddress: contractAddr.String(),
+		FunctionAbiJson: `{"type":"function","name":"doStuff"}`,
+	})
+	assert.Regexp(t, "pop", err)
+
+}
+
+func TestSendTransactionBadRequest(t *testing.T) {
+
+	ctx, _, tp, _, done := newTestRegistry(t, false)
+	defer done()
+
+	_, err := tp.r.SendTransaction(ctx, &prototk.SendTransactionRequest{
+		ContractAddress: "wrong",
+	})
+	assert.Regexp(t, "PD012110", err)
+
+	contractAddr := tktypes.RandAddress()
+	tp.r.config = &prototk.RegistryConfig{
+		EventSources: []*prototk.RegistryEventSource{{ContractAddress: contractAddr.String()}},
+	}
+	_, err = tp.r.SendTransaction(ctx, &prototk.SendTransactionRequest{
+		ContractAddress: contractAddr.String(),
+		FunctionAbiJson: `!json`,
+	})
+	assert.Regexp(t, "PD012111", err)
+
+	// Registries can only submit transactions to their own contracts
+	_, err = tp.r.SendTransaction(ctx, &prototk.SendTransactionRequest{
+		ContractAddress: tktypes.RandAddress().String(),
+		FunctionAbiJson: `{"type":"function","name":"doStuff"}`,
+	})
+	assert.Regexp(t, "PD012113", err)
+
+	tp.r.config = nil
+	_, err = tp.r.SendTransaction(ctx, &prototk.SendTransactionRequest{
+		ContractAddress: contractAddr.String(),
+		FunctionAbiJson: `{"type":"function","name":"doStuff"}`,
+	})
+	assert.Regexp(t, "PD012113", err)
+
+}
+
+func TestRegisterIdentityWithParent(t *testing.T) {
+
+	ctx, _, tp, _, done := newTestRegistry(t, false)
+	defer done()
+
+	parentID := tktypes.RandBytes(32)
+	txID := uuid.New()
+	tp.Functions.RegisterIdentity = func(ctx context.Context, req *prototk.RegisterIdentityRequest) (*prototk.RegisterIdentityResponse, error) {
+		assert.Equal(t, tktypes.HexBytes(parentID).String(), req.ParentId)
+		return &prototk.RegisterIdentityResponse{TransactionId: txID.String()}, nil
+	}
+
+	res, err := tp.r.RegisterIdentity(ctx, &pldapi.RegistryIdentityInput{
+		ParentID: parentID,
+		Name:     "child1",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, txID, *res)
+
+}
+
+func TestRegisterIdentityBadName(t *testing.T) {
+
+	ctx, _, tp, _, done := newTestRegistry(t, false)
+	defer done()
+
+	_, err := tp.r.RegisterIdentity(ctx, &pldapi.RegistryIdentityInput{
+		Name: "$wrong",
+	})
+	assert.Regexp(t, "PD012104", err)
+
+}
+
+func TestRegisterIdentityFail(t *testing.T) {
+
+	ctx, _, tp, _, done := newTestRegistry(t, false)
+	defer done()
+
+	tp.Functions.RegisterIdentity = func(ctx context.Context, req *prototk.RegisterIdentityRequest) (*prototk.RegisterIdentityResponse, error) {
+		return nil, fmt.Errorf("pop")
+	}
+
+	_, err := tp.r.RegisterIdentity(ctx, &pldapi.RegistryIdentityInput{
+		Name: "node1",
+	})
+	assert.Regexp(t, "pop", err)
+
+}
+
+func TestRegisterIdentityBadTransactionID(t *testing.T) {
+
+	ctx, _, tp, _, done := newTestRegistry(t, false)
+	defer done()
+
+	tp.Functions.RegisterIdentity = func(ctx context.Context, req *prototk.RegisterIdentityRequest) (*prototk.RegisterIdentityResponse, error) {
+		return &prototk.RegisterIdentityResponse{TransactionId: "wrong"}, nil
+	}
+
+	_, err := tp.r.RegisterIdentity(ctx, &pldapi.RegistryIdentityInput{
+		Name: "node1",
+	})
+	assert.Regexp(t, "PD012112", err)
+
+}
+
+func TestSetPropertyBadInput(t *testing.T) {
+
+	ctx, _, tp, _, done := newTestRegistry(t, false)
+	defer done()
+
+	_, err := tp.r.SetProperty(ctx, &pldapi.RegistryPropertyInput{
+		Name: "prop1",
+	})
+	assert.Regexp(t, "PD012103", err)
+
+	_, err = tp.r.SetProperty(ctx, &pldapi.RegistryPropertyInput{
+		EntryID: tktypes.RandBytes(32),
+		Name:    "$owner",
+	})
+	assert.Regexp(t, "PD012105", err)
+
+}
+
+func TestSetPropertyFail(t *testing.T) {
+
+	ctx, _, tp, _, done := newTestRegistry(t, false)
+	defer done()
+
+	tp.Functions.SetProperty = func(ctx context.Context, req *prototk.SetPropertyRequest) (*prototk.SetPropertyResponse, error) {
+		return nil, fmt.Errorf("pop")
+	}
+
+	_, err := tp.r.SetProperty(ctx, &pldapi.RegistryPropertyInput{
+		EntryID: tktypes.RandBytes(32),
+		Name:    "prop1",
+	})
+	assert.Regexp(t, "pop", err)
+
+}
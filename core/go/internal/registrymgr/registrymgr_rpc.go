@@ -19,6 +19,7 @@ package registrymgr
 import (
 	"context"
 
+	"github.com/google/uuid"
 	"github.com/kaleido-io/paladin/core/internal/components"
 	"github.com/kaleido-io/paladin/toolkit/pkg/pldapi"
 	"github.com/kaleido-io/paladin/toolkit/pkg/query"
@@ -35,7 +36,9 @@ func (rm *registryManager) initRPC() {
 		Add("reg_registries", rm.rpcListRegistries()).
 		Add("reg_queryEntries", rm.rpcQueryEntries()).
 		Add("reg_queryEntriesWithProps", rm.rpcQueryEntriesWithProps()).
-		Add("reg_getEntryProperties", rm.rpcGetEntryProperties())
+		Add("reg_getEntryProperties", rm.rpcGetEntryProperties()).
+		Add("reg_registerIdentity", rm.rpcRegisterIdentity()).
+		Add("reg_setProperty", rm.rpcSetProperty())
 }
 
 func (rm *registryManager) rpcListRegistries() rpcserver.RPCHandler {
@@ -94,3 +97,35 @@ func (rm *registryManager) rpcGetEntryProperties() rpcserver.RPCHandler {
 		)
 	})
 }
+
+func (rm *registryManager) rpcRegisterIdentity() rpcserver.RPCHandler {
+	return rpcserver.RPCMethod2(func(ctx context.Context,
+		registryName string,
+		identity pldapi.RegistryIdentityInput,
+	) (*uuid.UUID, error) {
+		if err := rpcserver.AuthorizeKey(ctx, identity.From); err != nil {
+			return nil, err
+		}
+		return withRegistry(ctx, rm, registryName,
+			func(r components.Registry) (*uuid.UUID, error) {
+				return r.RegisterIdentity(ctx, &identity)
+			},
+		)
+	})
+}
+
+func (rm *registryManager) rpcSetProperty() rpcserver.RPCHandler {
+	return rpcserver.RPCMethod2(func(ctx context.Context,
+		registryName string,
+		property pldapi.RegistryPropertyInput,
+	) (*uuid.UUID, error) {
+		if err := rpcserver.AuthorizeKey(ctx, property.From); err != nil {
+			return nil, err
+		}
+		return withRegistry(ctx, rm, registryName,
+			func(r components.Registry) (*uuid.UUID, error) {
+				return r.SetProperty(ctx, &property)
+			},
+		)
+	})
+}
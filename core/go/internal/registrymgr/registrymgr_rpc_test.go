@@ -21,6 +21,7 @@ import (
 	"testing"
 
 	"github.com/go-resty/resty/v2"
+	"github.com/google/uuid"
 	"github.com/kaleido-io/paladin/config/pkg/confutil"
 	"github.com/kaleido-io/paladin/config/pkg/pldconf"
 	"github.com/kaleido-io/paladin/toolkit/pkg/pldapi"
@@ -28,6 +29,7 @@ import (
 	"github.com/kaleido-io/paladin/toolkit/pkg/query"
 	"github.com/kaleido-io/paladin/toolkit/pkg/rpcclient"
 	"github.com/kaleido-io/paladin/toolkit/pkg/rpcserver"
+	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -80,21 +82,111 @@ func TestRPCQuery(t *testing.T) {
 
 }
 
-func newTestRPCServer(t *testing.T, ctx context.Context, rm *registryManager) (rpcclient.Client, func()) {
+func TestRPCPublish(t *testing.T) {
+	ctx, rm, tp, _, done := newTestRegistry(t, false)
+	defer done()
+
+	rpc, rpcDone := newTestRPCServer(t, ctx, rm)
+	defer rpcDone()
+
+	regTxID := uuid.New()
+	tp.Functions.RegisterIdentity = func(ctx context.Context, req *prototk.RegisterIdentityRequest) (*prototk.RegisterIdentityResponse, error) {
+		assert.Equal(t, "admin", req.From)
+		assert.Empty(t, req.ParentId)
+		assert.Equal(t, "node1", req.Name)
+		assert.Equal(t, "0x4c9e3a2ac8b7ab2fb8e0bee8d2c8bbbc5f1f4f76", req.Owner)
+		return &prototk.RegisterIdentityResponse{TransactionId: regTxID.String()}, nil
+	}
+	propTxID := uuid.New()
+	entryID := tktypes.RandBytes(32)
+	tp.Functions.SetProperty = func(ctx context.Context, req *prototk.SetPropertyRequest) (*prototk.SetPropertyResponse, error) {
+		assert.Equal(t, "node1key", req.From)
+		assert.Equal(t, tktypes.HexBytes(entryID).String(), req.EntryId)
+		assert.Equal(t, "transport.grpc", req.Name)
+		assert.Equal(t, "details", req.Value)
+		return &prototk.SetPropertyResponse{TransactionId: propTxID.String()}, nil
+	}
+
+	var txID *uuid.UUID
+	err := rpc.CallRPC(ctx, &txID, "reg_registerIdentity", tp.r.name, &pldapi.RegistryIdentityInput{
+		From:  "admin",
+		Name:  "node1",
+		Owner: "0x4c9e3a2ac8b7ab2fb8e0bee8d2c8bbbc5f1f4f76",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, regTxID, *txID)
+
+	err = rpc.CallRPC(ctx, &txID, "reg_setProperty", tp.r.name, &pldapi.RegistryPropertyInput{
+		From:    "node1key",
+		EntryID: entryID,
+		Name:    "transport.grpc",
+		Value:   "details",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, propTxID, *txID)
+
+	err = rpc.CallRPC(ctx, &txID, "reg_registerIdentity", "unknown", &pldapi.RegistryIdentityInput{})
+	assert.Regexp(t, "PD012101", err)
+
+	err = rpc.CallRPC(ctx, &txID, "reg_setProperty", "unknown", &pldapi.RegistryPropertyInput{})
+	assert.Regexp(t, "PD012101", err)
+}
+
+func TestRPCPublishKeyForbidden(t *testing.T) {
+	ctx, rm, tp, _, done := newTestRegistry(t, false)
+	defer done()
+
+	rpc, rpcDone := newTestRPCServer(t, ctx, rm, func(conf *pldconf.RPCServerConfig, rc *resty.Client) {
+		conf.Auth = pldconf.RPCAuthConfig{
+			Tokens: []pldconf.RPCAuthTokenConfig{{Principal: "user1", Token: "token1"}},
+			Policies: []pldconf.RPCAuthPolicyConfig{{
+				Principals: []string{"user1"},
+				Methods:    []string{"reg_"},
+				Keys:       []string{"node1key"},
+			}},
+		}
+		rc.SetAuthToken("token1")
+	})
+	defer rpcDone()
+
+	var txID *uuid.UUID
+	err := rpc.CallRPC(ctx, &txID, "reg_registerIdentity", tp.r.name, &pldapi.RegistryIdentityInput{
+		From:  "admin",
+		Name:  "node1",
+		Owner: "0x4c9e3a2ac8b7ab2fb8e0bee8d2c8bbbc5f1f4f76",
+	})
+	assert.Regexp(t, "PD020709", err)
+
+	err = rpc.CallRPC(ctx, &txID, "reg_setProperty", tp.r.name, &pldapi.RegistryPropertyInput{
+		From:    "otherkey",
+		EntryID: tktypes.RandBytes(32),
+		Name:    "transport.grpc",
+		Value:   "details",
+	})
+	assert.Regexp(t, "PD020709", err)
+}
 
-	s, err := rpcserver.NewRPCServer(ctx, &pldconf.RPCServerConfig{
+func newTestRPCServer(t *testing.T, ctx context.Context, rm *registryManager, extraSetup ...func(conf *pldconf.RPCServerConfig, rc *resty.Client)) (rpcclient.Client, func()) {
+
+	conf := &pldconf.RPCServerConfig{
 		HTTP: pldconf.RPCServerConfigHTTP{
 			HTTPServerConfig: pldconf.HTTPServerConfig{Address: confutil.P("127.0.0.1"), Port: confutil.P(0)},
 		},
 		WS: pldconf.RPCServerConfigWS{Disabled: true},
-	})
+	}
+	rc := resty.New()
+	for _, fn := range extraSetup {
+		fn(conf, rc)
+	}
+
+	s, err := rpcserver.NewRPCServer(ctx, conf)
 	require.NoError(t, err)
 	err = s.Start()
 	require.NoError(t, err)
 
 	s.Register(rm.RPCModule())
 
-	c := rpcclient.WrapRestyClient(resty.New().SetBaseURL(fmt.Sprintf("http://%s", s.HTTPAddr())))
+	c := rpcclient.WrapRestyClient(rc.SetBaseURL(fmt.Sprintf("http://%s", s.HTTPAddr())))
 
 	return c, s.Stop
 
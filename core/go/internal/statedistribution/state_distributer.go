@@ -17,8 +17,8 @@ package statedistribution
 
 import (
 	"context"
-	"time"
 
+	"github.com/google/uuid"
 	"github.com/hyperledger/firefly-common/pkg/i18n"
 	"github.com/kaleido-io/paladin/config/pkg/pldconf"
 	"github.com/kaleido-io/paladin/core/internal/components"
@@ -28,9 +28,15 @@ import (
 	"github.com/kaleido-io/paladin/toolkit/pkg/log"
 	"github.com/kaleido-io/paladin/toolkit/pkg/retry"
 	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
-const RETRY_TIMEOUT = 5 * time.Second
+// The page size for handing over distributions that were not acknowledged before an upgrade
+const recoveryPageSize = 100
+
+// StateReceivedHandler is notified once a state received from another node has been committed
+type StateReceivedHandler func(ctx context.Context, stateProducedEvent *pb.StateProducedEvent)
 
 func NewStateDistributer(
 	ctx context.Context,
@@ -38,26 +44,20 @@ func NewStateDistributer(
 	stateManager components.StateManager,
 	keyManager components.KeyManager,
 	persistence persistence.Persistence,
-	conf *pldconf.DistributerConfig,
+	stateReceived StateReceivedHandler,
 ) StateDistributer {
-	sd := &stateDistributer{
+	return &stateDistributer{
 		persistence:      persistence,
-		inputChan:        make(chan *components.StateDistribution),
-		retryChan:        make(chan string),
-		acknowledgedChan: make(chan string),
-		pendingMap:       make(map[string]*components.StateDistribution),
 		stateManager:     stateManager,
 		keyManager:       keyManager,
 		transportManager: transportManager,
 		localNodeName:    transportManager.LocalNodeName(),
+		stateReceived:    stateReceived,
 		retry:            retry.NewRetryIndefinite(&pldconf.RetryConfig{}, &pldconf.GenericRetryDefaults.RetryConfig),
 	}
-	sd.acknowledgementWriter = NewAcknowledgementWriter(ctx, sd.persistence, &conf.AcknowledgementWriter)
-	sd.receivedStateWriter = NewReceivedStateWriter(ctx, stateManager, persistence, &conf.ReceivedObjectWriter)
-
-	return sd
 }
 
+// Distributions recorded by earlier versions, which retried each one until it was acknowledged
 type StateDistributionPersisted struct {
 	Created               tktypes.Timestamp  `json:"created" gorm:"column:created;autoCreateTime:nano"`
 	ID                    string             `json:"id"`
@@ -70,38 +70,40 @@ type StateDistributionPersisted struct {
 	NullifierPayloadType  *string            `json:"nullifierPayloadType,omitempty"`
 }
 
+type stateDistributionAcknowledgement struct {
+	StateDistribution string `json:"stateDistribution" gorm:"column:state_distribution"`
+	ID                string `json:"id"                gorm:"column:id"`
+}
+
 /*
 StateDistributer is a component that is responsible for distributing state to remote parties
 
-	it runs in its own goroutine and periodically sends states to the intended recipients
-	until each recipient has acknowledged receipt of the state.
-
-	This operates on in-memory data but will initialize from persistent storage on startup
+	the states are sent as reliable messages by the transport manager, which persists them in the
+	DB transaction that records the dispatch, and resends them until the receiving node has written
+	them (and any nullifier it needs to build for them) to its own DB.
 */
 type StateDistributer interface {
 	Start(ctx context.Context) error
 	Stop(ctx context.Context)
 	BuildNullifiers(ctx context.Context, stateDistributions []*components.StateDistribution) ([]*components.NullifierUpsert, error)
-	DistributeStates(ctx context.Context, stateDistributions []*components.StateDistribution)
-	HandleStateProducedEvent(ctx context.Context, stateProducedEvent *pb.StateProducedEvent, distributingNode string)
-	HandleStateAcknowledgedEvent(ctx context.Context, messagePayload []byte)
+	// SendStateDistributions queues the states for delivery to the node of each recipient, within the
+	// supplied DB transaction. The postCommit function must be called once it commits.
+	SendStateDistributions(ctx context.Context, dbTX *gorm.DB, stateDistributions []*components.StateDistribution) (postCommit func(), err error)
+	// DistributeStates queues the states for delivery in a new DB transaction
+	DistributeStates(ctx context.Context, stateDistributions []*components.StateDistribution) error
 }
 
 type stateDistributer struct {
-	runCtx                context.Context
-	stopRunCtx            context.CancelFunc
-	persistence           persistence.Persistence
-	stateManager          components.StateManager
-	keyManager            components.KeyManager
-	inputChan             chan *components.StateDistribution
-	retryChan             chan string
-	acknowledgedChan      chan string
-	pendingMap            map[string]*components.StateDistribution
-	acknowledgementWriter *acknowledgementWriter
-	receivedStateWriter   *receivedStateWriter
-	transportManager      components.TransportManager
-	localNodeName         string
-	retry                 *retry.Retry
+	runCtx           context.Context
+	stopRunCtx       context.CancelFunc
+	persistence      persistence.Persistence
+	stateManager     components.StateManager
+	keyManager       components.KeyManager
+	transportManager components.TransportManager
+	localNodeName    string
+	stateReceived    StateReceivedHandler
+	retry            *retry.Retry
+	recoveryDone     chan struct{}
 }
 
 func (sd *stateDistributer) Start(bgCtx context.Context) error {
@@ -109,116 +111,119 @@ func (sd *stateDistributer) Start(bgCtx context.Context) error {
 	ctx := sd.runCtx
 	log.L(ctx).Info("stateDistributer:Start")
 
-	sd.acknowledgementWriter.Start()
-	sd.receivedStateWriter.Start()
-
-	// TODO: This needs to be a worker per-peer - probably a whole state distributor per peer that can be swapped in/out.
-	// Currently it only runs on startup, and pushes all state distributions from before the startup time into the distributor.
-	startTime := tktypes.TimestampNow()
-	go func() {
-		page := 0
-		dispatched := 0
-		var lastEntry *StateDistributionPersisted
-		finished := false
-		for !finished {
-			err := sd.retry.Do(ctx, func(attempt int) (retryable bool, err error) {
-				page++
-				var stateDistributions []*StateDistributionPersisted
-				query := sd.persistence.DB().Table("state_distributions").
-					Select("state_distributions.*").
-					Joins("LEFT JOIN state_distribution_acknowledgments ON state_distributions.id = state_distribution_acknowledgments.state_distribution").
-					Where("state_distribution_acknowledgments.id IS NULL").
-					Where("created < ?", startTime).
-					Order("created").
-					Limit(100)
-				if lastEntry != nil {
-					query = query.Where("created > ?", lastEntry.Created)
-				}
-				err = query.Find(&stateDistributions).Error
-
-				if err != nil {
-					log.L(ctx).Errorf("Error getting state distributions: %s", err)
-					return true, err
-				}
-
-				log.L(ctx).Infof("stateDistributer loaded %d state distributions on startup (page=%d)", len(stateDistributions), page)
+	err := sd.transportManager.RegisterClient(ctx, sd)
+	if err != nil {
+		log.L(ctx).Errorf("Error registering transport client: %s", err)
+		return err
+	}
 
-				for _, stateDistribution := range stateDistributions {
-					state, err := sd.stateManager.GetState(ctx, sd.persistence.DB(), /* no TX for now */
-						stateDistribution.DomainName, stateDistribution.ContractAddress, stateDistribution.StateID, true, false)
-					if err != nil {
-						log.L(ctx).Errorf("Error getting state: %s", err)
-						continue
-					}
+	sd.recoveryDone = make(chan struct{})
+	go sd.recoverUnacknowledged(ctx)
+	return nil
+}
 
-					sd.inputChan <- &components.StateDistribution{
-						ID:                    stateDistribution.ID,
-						StateID:               stateDistribution.StateID.String(),
-						IdentityLocator:       stateDistribution.IdentityLocator,
-						Domain:                stateDistribution.DomainName,
-						ContractAddress:       stateDistribution.ContractAddress.String(),
-						SchemaID:              state.Schema.String(),
-						StateDataJson:         string(state.Data),
-						NullifierAlgorithm:    stateDistribution.NullifierAlgorithm,
-						NullifierVerifierType: stateDistribution.NullifierVerifierType,
-						NullifierPayloadType:  stateDistribution.NullifierPayloadType,
-					}
+func (sd *stateDistributer) Stop(ctx context.Context) {
+	sd.stopRunCtx()
+	<-sd.recoveryDone
+}
 
-					dispatched++
-					lastEntry = stateDistribution
-				}
-				finished = (len(stateDistributions) == 0)
-				return false, nil
+// Distributions recorded by earlier versions that were not acknowledged are handed over to the
+// transport manager, and marked as acknowledged in the same DB transaction.
+func (sd *stateDistributer) recoverUnacknowledged(ctx context.Context) {
+	defer close(sd.recoveryDone)
+
+	recovered := 0
+	for {
+		var pageSize int
+		err := sd.retry.Do(ctx, func(attempt int) (retryable bool, err error) {
+			var postCommit func()
+			err = sd.persistence.DB().Transaction(func(dbTX *gorm.DB) (err error) {
+				pageSize, postCommit, err = sd.recoverPage(ctx, dbTX)
+				return err
 			})
 			if err != nil {
-				log.L(ctx).Warnf("exiting before sending all recovered state distributions")
+				log.L(ctx).Errorf("Error recovering state distributions: %s", err)
+				return true, err
 			}
+			postCommit()
+			return false, nil
+		})
+		if err != nil {
+			log.L(ctx).Warnf("exiting before sending all recovered state distributions")
+			return
 		}
-		log.L(ctx).Infof("stateDistributer finished startup recovery after dispatching %d distributions", dispatched)
-	}()
-
-	go func() {
-		log.L(ctx).Info("stateDistributer:Loop starting loop")
-		for {
-			log.L(ctx).Debug("stateDistributer:Loop waiting for next event")
-
-			select {
-			case <-ctx.Done():
-				return
-			case stateDistributionID := <-sd.acknowledgedChan:
-				_, stillPending := sd.pendingMap[stateDistributionID]
-				if stillPending {
-					log.L(ctx).Debugf("stateDistributer:Loop processing acknowledgment %s", stateDistributionID)
-
-					delete(sd.pendingMap, stateDistributionID)
-				} else {
-					log.L(ctx).Debugf("stateDistributer:Loop already received acknowledgment %s", stateDistributionID)
-
-				}
-				//if we didn't find it in the map, it was already acknowledged
-
-			case stateDistributionID := <-sd.retryChan:
-
-				pendingDistribution, stillPending := sd.pendingMap[stateDistributionID]
-				if stillPending {
-					log.L(ctx).Debugf("stateDistributer:Loop retrying %s", stateDistributionID)
-					sd.sendState(ctx, pendingDistribution)
-				}
-				//if we didn't find it in the map, it was already acknowledged
-
-			case stateDistribution := <-sd.inputChan:
-				log.L(ctx).Debugf("stateDistributer:Loop new distribution %s", stateDistribution.ID)
+		if pageSize == 0 {
+			break
+		}
+		recovered += pageSize
+	}
+	log.L(ctx).Infof("stateDistributer finished startup recovery after handing over %d distributions", recovered)
+}
 
-				sd.pendingMap[stateDistribution.ID] = stateDistribution
-				sd.sendState(ctx, stateDistribution)
+func (sd *stateDistributer) recoverPage(ctx context.Context, dbTX *gorm.DB) (int, func(), error) {
+	var persisted []*StateDistributionPersisted
+	err := dbTX.
+		WithContext(ctx).
+		Table("state_distributions").
+		Select("state_distributions.*").
+		Joins("LEFT JOIN state_distribution_acknowledgments ON state_distributions.id = state_distribution_acknowledgments.state_distribution").
+		Where("state_distribution_acknowledgments.id IS NULL").
+		Order("created").
+		Limit(recoveryPageSize).
+		Find(&persisted).
+		Error
+	if err != nil || len(persisted) == 0 {
+		return 0, func() {}, err
+	}
 
-			}
+	distributions := make([]*components.StateDistribution, 0, len(persisted))
+	acks := make([]*stateDistributionAcknowledgement, len(persisted))
+	for i, sdp := range persisted {
+		acks[i] = &stateDistributionAcknowledgement{
+			StateDistribution: sdp.ID,
+			ID:                uuid.New().String(),
 		}
-	}()
-	return nil
+		// Any we cannot load are acknowledged without being sent, so they are not retried forever
+		state, err := sd.stateManager.GetState(ctx, dbTX, sdp.DomainName, sdp.ContractAddress, sdp.StateID, false, false)
+		if err != nil {
+			return 0, nil, err
+		}
+		if state == nil {
+			log.L(ctx).Errorf("State %s not found for distribution %s", sdp.StateID, sdp.ID)
+			continue
+		}
+		distributions = append(distributions, &components.StateDistribution{
+			ID:                    sdp.ID,
+			StateID:               sdp.StateID.String(),
+			IdentityLocator:       sdp.IdentityLocator,
+			Domain:                sdp.DomainName,
+			ContractAddress:       sdp.ContractAddress.String(),
+			SchemaID:              state.Schema.String(),
+			StateDataJson:         string(state.Data),
+			NullifierAlgorithm:    sdp.NullifierAlgorithm,
+			NullifierVerifierType: sdp.NullifierVerifierType,
+			NullifierPayloadType:  sdp.NullifierPayloadType,
+		})
+	}
+
+	postCommit, err := sd.SendStateDistributions(ctx, dbTX, distributions)
+	if err == nil {
+		err = dbTX.
+			WithContext(ctx).
+			Table("state_distribution_acknowledgments").
+			Clauses(clause.OnConflict{
+				DoNothing: true, // immutable
+			}).
+			Create(acks).
+			Error
+	}
+	if err != nil {
+		return 0, nil, err
+	}
+	return len(persisted), postCommit, nil
 }
 
-func (sd *stateDistributer) buildNullifier(ctx context.Context, krc components.KeyResolutionContextLazyDB, s *components.StateDistribution) (*components.NullifierUpsert, error) {
+func (sd *stateDistributer) buildNullifier(ctx context.Context, kr components.KeyResolver, s *components.StateDistribution) (*components.NullifierUpsert, error) {
 	// We need to call the signing engine with the local identity to build the nullifier
 	log.L(ctx).Infof("Generating nullifier for state %s on node %s (algorithm=%s,verifierType=%s,payloadType=%s)",
 		s.StateID, sd.localNodeName, *s.NullifierAlgorithm, *s.NullifierVerifierType, *s.NullifierPayloadType)
@@ -231,7 +236,7 @@ func (sd *stateDistributer) buildNullifier(ctx context.Context, krc components.K
 
 	// Call the signing engine to build the nullifier
 	var nulliferBytes []byte
-	mapping, err := krc.KeyResolverLazyDB().ResolveKey(identifier, *s.NullifierAlgorithm, *s.NullifierVerifierType)
+	mapping, err := kr.ResolveKey(identifier, *s.NullifierAlgorithm, *s.NullifierVerifierType)
 	if err == nil {
 		nulliferBytes, err = sd.keyManager.Sign(ctx, mapping, *s.NullifierPayloadType, []byte(s.StateDataJson))
 	}
@@ -244,6 +249,10 @@ func (sd *stateDistributer) buildNullifier(ctx context.Context, krc components.K
 	}, nil
 }
 
+func nullifierRequired(s *components.StateDistribution) bool {
+	return s.NullifierAlgorithm != nil && s.NullifierVerifierType != nil && s.NullifierPayloadType != nil
+}
+
 func (sd *stateDistributer) withKeyResolutionContext(ctx context.Context, fn func(krc components.KeyResolutionContextLazyDB) error) (err error) {
 
 	// Unlikely we'll be resolving any new identities on this path - if we do, we'll start a new DB transaction
@@ -266,12 +275,12 @@ func (sd *stateDistributer) BuildNullifiers(ctx context.Context, stateDistributi
 	nullifiers = []*components.NullifierUpsert{}
 	err = sd.withKeyResolutionContext(ctx, func(krc components.KeyResolutionContextLazyDB) error {
 		for _, s := range stateDistributions {
-			if s.NullifierAlgorithm == nil || s.NullifierVerifierType == nil || s.NullifierPayloadType == nil {
+			if !nullifierRequired(s) {
 				log.L(ctx).Debugf("No nullifier required for state %s on node %s", s.ID, sd.localNodeName)
 				continue
 			}
 
-			nullifier, err := sd.buildNullifier(ctx, krc, s)
+			nullifier, err := sd.buildNullifier(ctx, krc.KeyResolverLazyDB(), s)
 			if err != nil {
 				return err
 			}
@@ -282,9 +291,3 @@ func (sd *stateDistributer) BuildNullifiers(ctx context.Context, stateDistributi
 	})
 	return nullifiers, err
 }
-
-func (sd *stateDistributer) Stop(ctx context.Context) {
-	sd.stopRunCtx()
-	sd.acknowledgementWriter.Stop()
-	sd.receivedStateWriter.Stop()
-}
@@ -22,33 +22,44 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/kaleido-io/paladin/config/pkg/confutil"
-	"github.com/kaleido-io/paladin/config/pkg/pldconf"
 	"github.com/kaleido-io/paladin/core/internal/components"
 	"github.com/kaleido-io/paladin/core/mocks/componentmocks"
-	"github.com/kaleido-io/paladin/core/pkg/persistence/mockpersistence"
+	"github.com/kaleido-io/paladin/core/pkg/persistence"
+	pb "github.com/kaleido-io/paladin/core/pkg/proto/engine"
 	"github.com/kaleido-io/paladin/toolkit/pkg/pldapi"
 	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"gorm.io/gorm"
 )
 
 type mockComponents struct {
-	db               *mockpersistence.SQLMockProvider
+	p                persistence.Persistence
 	stateManager     *componentmocks.StateManager
 	keyManager       *componentmocks.KeyManager
 	transportManager *componentmocks.TransportManager
 	keyResolver      *componentmocks.KeyResolver
+	krc              *componentmocks.KeyResolutionContext
+	received         chan *pb.StateProducedEvent
 }
 
 func newTestStateDistributor(t *testing.T) (context.Context, *mockComponents, *stateDistributer) {
 	ctx := context.Background()
 
+	p, pDone, err := persistence.NewUnitTestPersistence(ctx, "statedistribution")
+	require.NoError(t, err)
+	t.Cleanup(pDone)
+
 	mc := &mockComponents{
+		p:                p,
 		stateManager:     componentmocks.NewStateManager(t),
 		keyManager:       componentmocks.NewKeyManager(t),
 		transportManager: componentmocks.NewTransportManager(t),
 		keyResolver:      componentmocks.NewKeyResolver(t),
+		krc:              componentmocks.NewKeyResolutionContext(t),
+		received:         make(chan *pb.StateProducedEvent, 1),
 	}
 	mc.transportManager.On("LocalNodeName").Return("node1")
 	mkrc := componentmocks.NewKeyResolutionContextLazyDB(t)
@@ -56,12 +67,13 @@ func newTestStateDistributor(t *testing.T) (context.Context, *mockComponents, *s
 	mkrc.On("Commit").Return(nil).Maybe()
 	mkrc.On("Rollback").Return().Maybe()
 	mc.keyManager.On("NewKeyResolutionContextLazyDB", mock.Anything).Return(mkrc).Maybe()
+	mc.krc.On("KeyResolver", mock.Anything).Return(mc.keyResolver).Maybe()
+	mc.keyManager.On("NewKeyResolutionContext", mock.Anything).Return(mc.krc).Maybe()
 
-	mdb, err := mockpersistence.NewSQLMockProvider()
-	require.NoError(t, err)
-	mc.db = mdb
-
-	sd := NewStateDistributer(ctx, mc.transportManager, mc.stateManager, mc.keyManager, mc.db.P, &pldconf.DistributerConfig{})
+	sd := NewStateDistributer(ctx, mc.transportManager, mc.stateManager, mc.keyManager, mc.p, func(ctx context.Context, stateProducedEvent *pb.StateProducedEvent) {
+		mc.received <- stateProducedEvent
+	})
+	sd.(*stateDistributer).runCtx = ctx
 
 	return ctx, mc, sd.(*stateDistributer)
 
@@ -173,3 +185,249 @@ func TestBuildNullifiersNotLocal(t *testing.T) {
 	assert.Regexp(t, "PD012400", err)
 
 }
+
+func TestSendStateDistributions(t *testing.T) {
+	ctx, mc, sd := newTestStateDistributor(t)
+
+	postCommit, err := sd.SendStateDistributions(ctx, mc.p.DB(), nil)
+	require.NoError(t, err)
+	postCommit()
+
+	distID := uuid.New()
+	stateID := tktypes.HexBytes(tktypes.RandBytes(32))
+	committed := false
+	mc.transportManager.On("SendReliable", ctx, mock.Anything, mock.Anything).Return(func() { committed = true }, nil).Run(func(args mock.Arguments) {
+		msg := args[2].(*components.TransportMessage)
+		assert.Equal(t, distID, msg.MessageID)
+		assert.Equal(t, "node2", msg.Node)
+		assert.Equal(t, "node1", msg.ReplyTo)
+		assert.Equal(t, STATE_DISTRIBUTER_DESTINATION, msg.Component)
+		var spe pb.StateProducedEvent
+		require.NoError(t, proto.Unmarshal(msg.Payload, &spe))
+		assert.Equal(t, "bob@node2", spe.Party)
+		assert.Equal(t, stateID.String(), spe.StateId)
+		assert.Equal(t, "nullifier_algo", *spe.NullifierAlgorithm)
+	}).Once()
+
+	err = sd.DistributeStates(ctx, []*components.StateDistribution{{
+		ID:                    distID.String(),
+		StateID:               stateID.String(),
+		IdentityLocator:       "bob@node2",
+		Domain:                "domain1",
+		ContractAddress:       tktypes.RandAddress().String(),
+		StateDataJson:         `{"state":"data"}`,
+		NullifierAlgorithm:    confutil.P("nullifier_algo"),
+		NullifierVerifierType: confutil.P("nullifier_verifier_type"),
+		NullifierPayloadType:  confutil.P("nullifier_payload_type"),
+	}})
+	require.NoError(t, err)
+	assert.True(t, committed)
+
+	mc.transportManager.On("SendReliable", ctx, mock.Anything, mock.Anything).Return(nil, assert.AnError).Once()
+	err = sd.DistributeStates(ctx, []*components.StateDistribution{{
+		ID:              uuid.NewString(),
+		IdentityLocator: "bob@node2",
+	}})
+	assert.Equal(t, assert.AnError, err)
+}
+
+func TestSendStateDistributionsBadDistribution(t *testing.T) {
+	ctx, mc, sd := newTestStateDistributor(t)
+
+	_, err := sd.SendStateDistributions(ctx, mc.p.DB(), []*components.StateDistribution{{
+		ID:              "not a uuid",
+		IdentityLocator: "bob@node2",
+	}})
+	assert.Error(t, err)
+
+	_, err = sd.SendStateDistributions(ctx, mc.p.DB(), []*components.StateDistribution{{
+		ID:              uuid.NewString(),
+		IdentityLocator: "bob",
+	}})
+	assert.Error(t, err)
+}
+
+func testStateProducedEvent(t *testing.T, withNullifier bool) (*pb.StateProducedEvent, []byte) {
+	spe := &pb.StateProducedEvent{
+		DistributionId:  uuid.NewString(),
+		StateId:         tktypes.HexBytes(tktypes.RandBytes(32)).String(),
+		StateDataJson:   `{"state":"data"}`,
+		Party:           "target@node1",
+		DomainName:      "domain1",
+		ContractAddress: tktypes.RandAddress().String(),
+		SchemaId:        tktypes.Bytes32(tktypes.RandBytes(32)).String(),
+	}
+	if withNullifier {
+		spe.NullifierAlgorithm = confutil.P("nullifier_algo")
+		spe.NullifierVerifierType = confutil.P("nullifier_verifier_type")
+		spe.NullifierPayloadType = confutil.P("nullifier_payload_type")
+	}
+	payload, err := proto.Marshal(spe)
+	require.NoError(t, err)
+	return spe, payload
+}
+
+func TestReceiveReliableTransportMessage(t *testing.T) {
+	ctx, mc, sd := newTestStateDistributor(t)
+
+	spe, payload := testStateProducedEvent(t, true)
+
+	mc.stateManager.On("WriteReceivedStates", ctx, mock.Anything, "domain1", mock.Anything).Return(nil, nil).Run(func(args mock.Arguments) {
+		states := args[3].([]*components.StateUpsertOutsideContext)
+		require.Len(t, states, 1)
+		assert.Equal(t, spe.ContractAddress, states[0].ContractAddress.String())
+		assert.Equal(t, spe.SchemaId, states[0].SchemaID.String())
+		assert.JSONEq(t, spe.StateDataJson, states[0].Data.String())
+	})
+	keyMapping := &pldapi.KeyMappingAndVerifier{}
+	mc.keyResolver.On("ResolveKey", "target", "nullifier_algo", "nullifier_verifier_type").Return(keyMapping, nil)
+	nullifierBytes := tktypes.RandBytes(32)
+	mc.keyManager.On("Sign", ctx, keyMapping, "nullifier_payload_type", []byte(`{"state":"data"}`)).Return(nullifierBytes, nil)
+	mc.stateManager.On("WriteNullifiersForReceivedStates", ctx, mock.Anything, "domain1", []*components.NullifierUpsert{{
+		ID:    nullifierBytes,
+		State: tktypes.MustParseHexBytes(spe.StateId),
+	}}).Return(nil)
+	mc.krc.On("PreCommit").Return(nil)
+	mc.krc.On("Close", true).Return()
+
+	postCommit, err := sd.ReceiveReliableTransportMessage(ctx, mc.p.DB(), &components.TransportMessage{
+		MessageType: "StateProducedEvent",
+		Payload:     payload,
+		ReplyTo:     "node2",
+	})
+	require.NoError(t, err)
+	postCommit()
+	assert.Equal(t, spe.DistributionId, (<-mc.received).DistributionId)
+}
+
+func TestReceiveReliableTransportMessageNullifierFail(t *testing.T) {
+	ctx, mc, sd := newTestStateDistributor(t)
+
+	_, payload := testStateProducedEvent(t, true)
+
+	mc.stateManager.On("WriteReceivedStates", ctx, mock.Anything, "domain1", mock.Anything).Return(nil, nil)
+	mc.keyResolver.On("ResolveKey", "target", "nullifier_algo", "nullifier_verifier_type").Return(nil, fmt.Errorf("pop"))
+	mc.krc.On("Close", false).Return()
+
+	_, err := sd.ReceiveReliableTransportMessage(ctx, mc.p.DB(), &components.TransportMessage{
+		MessageType: "StateProducedEvent",
+		Payload:     payload,
+	})
+	assert.Regexp(t, "PD012401.*pop", err)
+}
+
+func TestReceiveReliableTransportMessageWriteFail(t *testing.T) {
+	ctx, mc, sd := newTestStateDistributor(t)
+
+	_, payload := testStateProducedEvent(t, false)
+
+	mc.stateManager.On("WriteReceivedStates", ctx, mock.Anything, "domain1", mock.Anything).Return(nil, assert.AnError)
+	mc.krc.On("Close", false).Return()
+
+	_, err := sd.ReceiveReliableTransportMessage(ctx, mc.p.DB(), &components.TransportMessage{
+		MessageType: "StateProducedEvent",
+		Payload:     payload,
+	})
+	assert.Equal(t, assert.AnError, err)
+}
+
+func TestReceiveReliableTransportMessageDiscarded(t *testing.T) {
+	ctx, mc, sd := newTestStateDistributor(t)
+
+	badAddress, err := proto.Marshal(&pb.StateProducedEvent{ContractAddress: "wrong"})
+	require.NoError(t, err)
+	badSchema, err := proto.Marshal(&pb.StateProducedEvent{ContractAddress: tktypes.RandAddress().String(), SchemaId: "wrong"})
+	require.NoError(t, err)
+
+	for _, msg := range []*components.TransportMessage{
+		{MessageType: "WrongType"},
+		{MessageType: "StateProducedEvent", Payload: []byte("!protobuf")},
+		{MessageType: "StateProducedEvent", Payload: badAddress},
+		{MessageType: "StateProducedEvent", Payload: badSchema},
+	} {
+		postCommit, err := sd.ReceiveReliableTransportMessage(ctx, mc.p.DB(), msg)
+		require.NoError(t, err)
+		assert.Nil(t, postCommit)
+	}
+
+	// unreliable messages are ignored
+	sd.ReceiveTransportMessage(ctx, &components.TransportMessage{MessageType: "StateProducedEvent"})
+}
+
+func TestRecoverUnacknowledged(t *testing.T) {
+	ctx, mc, sd := newTestStateDistributor(t)
+
+	// Distributions recorded before the upgrade, one of which was acknowledged
+	schemaID := tktypes.Bytes32(tktypes.RandBytes(32))
+	contractAddress := tktypes.RandAddress()
+	stateIDs := []tktypes.HexBytes{tktypes.RandBytes(32), tktypes.RandBytes(32), tktypes.RandBytes(32)}
+	distIDs := []string{uuid.NewString(), uuid.NewString(), uuid.NewString()}
+	err := mc.p.DB().Transaction(func(dbTX *gorm.DB) error {
+		err := dbTX.Exec(`INSERT INTO schemas ("id", "created", "domain_name", "type", "signature", "definition", "labels") VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			schemaID, tktypes.TimestampNow(), "domain1", "abi", "", "{}", "[]").Error
+		for i, stateID := range stateIDs {
+			if err == nil {
+				err = dbTX.Exec(`INSERT INTO states ("id", "created", "domain_name", "schema", "contract_address", "data") VALUES (?, ?, ?, ?, ?, ?)`,
+					stateID, tktypes.TimestampNow(), "domain1", schemaID, contractAddress, "{}").Error
+			}
+			if err == nil {
+				err = dbTX.Table("state_distributions").Create(&StateDistributionPersisted{
+					ID:              distIDs[i],
+					StateID:         stateID,
+					IdentityLocator: "bob@node2",
+					DomainName:      "domain1",
+					ContractAddress: *contractAddress,
+				}).Error
+			}
+		}
+		if err != nil {
+			return err
+		}
+		return dbTX.Table("state_distribution_acknowledgments").Create(&stateDistributionAcknowledgement{
+			StateDistribution: distIDs[0],
+			ID:                uuid.NewString(),
+		}).Error
+	})
+	require.NoError(t, err)
+
+	// The second can no longer be loaded, so is acknowledged without being sent
+	mc.stateManager.On("GetState", mock.Anything, mock.Anything, "domain1", *contractAddress, stateIDs[1], false, false).Return(nil, nil)
+	mc.stateManager.On("GetState", mock.Anything, mock.Anything, "domain1", *contractAddress, stateIDs[2], false, false).Return(&pldapi.State{
+		StateBase: pldapi.StateBase{
+			ID:     stateIDs[2],
+			Schema: schemaID,
+			Data:   tktypes.RawJSON(`{"state":"data"}`),
+		},
+	}, nil)
+	sent := make(chan *components.TransportMessage, 1)
+	mc.transportManager.On("RegisterClient", mock.Anything, sd).Return(nil)
+	mc.transportManager.On("SendReliable", mock.Anything, mock.Anything, mock.Anything).Return(func() {}, nil).Run(func(args mock.Arguments) {
+		require.Len(t, args, 3)
+		sent <- args[2].(*components.TransportMessage)
+	}).Once()
+
+	err = sd.Start(ctx)
+	require.NoError(t, err)
+	<-sd.recoveryDone
+	sd.Stop(ctx)
+
+	msg := <-sent
+	assert.Equal(t, distIDs[2], msg.MessageID.String())
+	var spe pb.StateProducedEvent
+	require.NoError(t, proto.Unmarshal(msg.Payload, &spe))
+	assert.Equal(t, schemaID.String(), spe.SchemaId)
+	assert.Equal(t, `{"state":"data"}`, spe.StateDataJson)
+
+	var ackCount int64
+	err = mc.p.DB().Table("state_distribution_acknowledgments").Count(&ackCount).Error
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), ackCount)
+}
+
+func TestStartRegisterFail(t *testing.T) {
+	ctx, mc, sd := newTestStateDistributor(t)
+
+	mc.transportManager.On("RegisterClient", mock.Anything, sd).Return(assert.AnError)
+	err := sd.Start(ctx)
+	assert.Equal(t, assert.AnError, err)
+}
@@ -17,24 +17,47 @@ package statedistribution
 
 import (
 	"context"
-	"time"
 
+	"github.com/google/uuid"
 	"github.com/kaleido-io/paladin/core/internal/components"
 	pb "github.com/kaleido-io/paladin/core/pkg/proto/engine"
 	"github.com/kaleido-io/paladin/toolkit/pkg/log"
 	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
 	"google.golang.org/protobuf/proto"
+	"gorm.io/gorm"
 )
 
-func (sd *stateDistributer) DistributeStates(ctx context.Context, stateDistributions []*components.StateDistribution) {
-	log.L(ctx).Debugf("stateDistributer:DistributeStates %d state distributions", len(stateDistributions))
-	for _, stateDistribution := range stateDistributions {
-		sd.inputChan <- stateDistribution
+func (sd *stateDistributer) SendStateDistributions(ctx context.Context, dbTX *gorm.DB, stateDistributions []*components.StateDistribution) (postCommit func(), err error) {
+	log.L(ctx).Debugf("stateDistributer:SendStateDistributions %d state distributions", len(stateDistributions))
+	if len(stateDistributions) == 0 {
+		return func() {}, nil
 	}
+
+	messages := make([]*components.TransportMessage, len(stateDistributions))
+	for i, stateDistribution := range stateDistributions {
+		if messages[i], err = sd.buildStateMessage(ctx, stateDistribution); err != nil {
+			return nil, err
+		}
+	}
+	return sd.transportManager.SendReliable(ctx, dbTX, messages...)
+}
+
+func (sd *stateDistributer) DistributeStates(ctx context.Context, stateDistributions []*components.StateDistribution) error {
+	var postCommit func()
+	err := sd.persistence.DB().Transaction(func(dbTX *gorm.DB) (err error) {
+		postCommit, err = sd.SendStateDistributions(ctx, dbTX, stateDistributions)
+		return err
+	})
+	if err != nil {
+		log.L(ctx).Errorf("Error queuing state distributions: %s", err)
+		return err
+	}
+	postCommit()
+	return nil
 }
 
-func (sd *stateDistributer) sendState(ctx context.Context, stateDistribution *components.StateDistribution) {
-	log.L(ctx).Debugf("stateDistributer:sendState id=%s,domain=%s contractAddress=%s schemaId=%s stateId=%s identity=%s, nullifierAlgorithm=%v nullifierVerifierType=%v nullifierPayloadType=%v]",
+func (sd *stateDistributer) buildStateMessage(ctx context.Context, stateDistribution *components.StateDistribution) (*components.TransportMessage, error) {
+	log.L(ctx).Debugf("stateDistributer:buildStateMessage id=%s,domain=%s contractAddress=%s schemaId=%s stateId=%s identity=%s, nullifierAlgorithm=%v nullifierVerifierType=%v nullifierPayloadType=%v]",
 		stateDistribution.ID,
 		stateDistribution.Domain,
 		stateDistribution.ContractAddress,
@@ -46,6 +69,19 @@ func (sd *stateDistributer) sendState(ctx context.Context, stateDistribution *co
 		stateDistribution.NullifierPayloadType,
 	)
 
+	// The distribution ID is used as the message ID, so the receiving node removes any duplicates
+	messageID, err := uuid.Parse(stateDistribution.ID)
+	if err != nil {
+		log.L(ctx).Errorf("Error parsing distribution ID %s: %s", stateDistribution.ID, err)
+		return nil, err
+	}
+
+	targetNode, err := tktypes.PrivateIdentityLocator(stateDistribution.IdentityLocator).Node(ctx, false)
+	if err != nil {
+		log.L(ctx).Errorf("Error getting node for party %s", stateDistribution.IdentityLocator)
+		return nil, err
+	}
+
 	stateProducedEvent := &pb.StateProducedEvent{
 		DistributionId:        stateDistribution.ID,
 		DomainName:            stateDistribution.Domain,
@@ -60,31 +96,16 @@ func (sd *stateDistributer) sendState(ctx context.Context, stateDistribution *co
 	}
 	stateProducedEventBytes, err := proto.Marshal(stateProducedEvent)
 	if err != nil {
-		log.L(ctx).Errorf("Error marshalling delegate transaction message: %s", err)
-		return
-	}
-
-	targetNode, err := tktypes.PrivateIdentityLocator(stateDistribution.IdentityLocator).Node(ctx, false)
-	if err != nil {
-		log.L(ctx).Errorf("Error getting node for party %s", stateDistribution.IdentityLocator)
-		return
+		log.L(ctx).Errorf("Error marshalling state produced event: %s", err)
+		return nil, err
 	}
 
-	err = sd.transportManager.Send(ctx, &components.TransportMessage{
+	return &components.TransportMessage{
+		MessageID:   messageID,
 		MessageType: "StateProducedEvent",
 		Payload:     stateProducedEventBytes,
 		Node:        targetNode,
-		Component:   components.PRIVATE_TX_MANAGER_DESTINATION,
+		Component:   STATE_DISTRIBUTER_DESTINATION,
 		ReplyTo:     sd.localNodeName,
-	})
-	if err != nil {
-		log.L(ctx).Errorf("Error sending state produced event: %s", err)
-		return
-	}
-
-	go func() {
-		time.Sleep(RETRY_TIMEOUT)
-		sd.retryChan <- stateDistribution.ID
-	}()
-
+	}, nil
 }
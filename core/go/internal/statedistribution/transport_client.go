@@ -19,16 +19,52 @@ import (
 	"context"
 
 	"github.com/kaleido-io/paladin/core/internal/components"
+	"github.com/kaleido-io/paladin/core/internal/tracing"
 	pb "github.com/kaleido-io/paladin/core/pkg/proto/engine"
 	"github.com/kaleido-io/paladin/toolkit/pkg/log"
 	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
 	"google.golang.org/protobuf/proto"
+	"gorm.io/gorm"
 )
 
-func (sd *stateDistributer) HandleStateProducedEvent(ctx context.Context, stateProducedEvent *pb.StateProducedEvent, distributingNode string) {
-	log.L(ctx).Debugf("stateDistributer:handleStateProducedEvent")
+const STATE_DISTRIBUTER_DESTINATION = "state-distributer"
 
-	var err error
+func (sd *stateDistributer) Destination() string {
+	return STATE_DISTRIBUTER_DESTINATION
+}
+
+func (sd *stateDistributer) ReceiveTransportMessage(ctx context.Context, message *components.TransportMessage) {
+	// States are only exchanged as reliable messages
+	log.L(ctx).Errorf("Unexpected unreliable message type %s from %s", message.MessageType, message.ReplyTo)
+}
+
+// Writes the received state, and any nullifier we need to build for it, in the DB transaction that records
+// the receipt of the message, so it is written exactly once, and the sending node stops resending it once
+// it is committed.
+func (sd *stateDistributer) ReceiveReliableTransportMessage(ctx context.Context, dbTX *gorm.DB, message *components.TransportMessage) (_ func(), err error) {
+	log.L(ctx).Debugf("stateDistributer:ReceiveReliableTransportMessage")
+
+	// Messages we cannot parse are accepted and discarded, as they would fail in the same way when resent
+	if message.MessageType != "StateProducedEvent" {
+		log.L(ctx).Errorf("Unknown message type: %s", message.MessageType)
+		return nil, nil
+	}
+	stateProducedEvent := &pb.StateProducedEvent{}
+	err = proto.Unmarshal(message.Payload, stateProducedEvent)
+	if err != nil {
+		log.L(ctx).Errorf("Failed to unmarshal StateProducedEvent: %s", err)
+		return nil, nil
+	}
+	contractAddress, err := tktypes.ParseEthAddress(stateProducedEvent.ContractAddress)
+	if err != nil {
+		log.L(ctx).Errorf("Invalid contract address in StateProducedEvent %s: %s", stateProducedEvent.DistributionId, err)
+		return nil, nil
+	}
+	schemaID, err := tktypes.ParseBytes32(stateProducedEvent.SchemaId)
+	if err != nil {
+		log.L(ctx).Errorf("Invalid schema ID in StateProducedEvent %s: %s", stateProducedEvent.DistributionId, err)
+		return nil, nil
+	}
 	s := &components.StateDistribution{
 		ID:                    stateProducedEvent.DistributionId,
 		StateID:               stateProducedEvent.StateId,
@@ -42,59 +78,41 @@ func (sd *stateDistributer) HandleStateProducedEvent(ctx context.Context, stateP
 		NullifierPayloadType:  stateProducedEvent.NullifierPayloadType,
 	}
 
-	// We need to build any nullifiers that are required, before we dispatch to persistence
-	var nullifier *components.NullifierUpsert
-	if stateProducedEvent.NullifierAlgorithm != nil && stateProducedEvent.NullifierVerifierType != nil && stateProducedEvent.NullifierPayloadType != nil {
-		err = sd.withKeyResolutionContext(ctx, func(krc components.KeyResolutionContextLazyDB) (err error) {
-			nullifier, err = sd.buildNullifier(ctx, krc, s)
-			return err
-		})
-	}
+	// The key resolution shares the DB transaction, and is closed once it has committed.
+	// The identity for the nullifier is local, and already known from resolving the verifier
+	// the sender used to build the state, so in practice this only reads existing keys.
+	krc := sd.keyManager.NewKeyResolutionContext(ctx)
+	defer func() {
+		if err != nil {
+			krc.Close(false)
+		}
+	}()
 
-	if err == nil {
-		err = sd.receivedStateWriter.QueueAndWait(ctx,
-			s.Domain,
-			*tktypes.MustEthAddress(s.ContractAddress),
-			tktypes.MustParseBytes32(s.SchemaID),
-			tktypes.RawJSON(s.StateDataJson),
-			nullifier,
-		)
+	_, err = sd.stateManager.WriteReceivedStates(ctx, dbTX, s.Domain, []*components.StateUpsertOutsideContext{{
+		ContractAddress: *contractAddress,
+		SchemaID:        schemaID,
+		Data:            tktypes.RawJSON(s.StateDataJson),
+	}})
+	if err == nil && nullifierRequired(s) {
+		var nullifier *components.NullifierUpsert
+		nullifier, err = sd.buildNullifier(ctx, krc.KeyResolver(dbTX), s)
+		if err == nil {
+			err = sd.stateManager.WriteNullifiersForReceivedStates(ctx, dbTX, s.Domain, []*components.NullifierUpsert{nullifier})
+		}
 	}
-	if err != nil {
-		log.L(ctx).Errorf("Error writing state: %s", err)
-		//don't send the acknowledgement, we rely on the sender to retry
-		return
-	}
-
-	// No error means either this is the first time we have received this state or we already have it an onConflict ignore means we idempotently accept it
-	// If the latter, then the sender probably didn't get our previous acknowledgement so either way, we send an acknowledgement
-
-	err = sd.sendStateAcknowledgement(
-		ctx,
-		stateProducedEvent.DomainName,
-		stateProducedEvent.ContractAddress,
-		stateProducedEvent.StateId,
-		stateProducedEvent.Party,
-		distributingNode,
-		stateProducedEvent.DistributionId)
-	if err != nil {
-		log.L(ctx).Errorf("Error sending state acknowledgement: %s", err)
-		//not much more we can do here.  The sender will inevitably retry and we will hopefully send the ack next time
+	if err == nil {
+		err = krc.PreCommit()
 	}
-}
-
-func (sd *stateDistributer) HandleStateAcknowledgedEvent(ctx context.Context, messagePayload []byte) {
-	log.L(ctx).Debugf("stateDistributer:handleStateAcknowledgedEvent")
-	stateAcknowledgedEvent := &pb.StateAcknowledgedEvent{}
-	err := proto.Unmarshal(messagePayload, stateAcknowledgedEvent)
 	if err != nil {
-		log.L(ctx).Errorf("Failed to unmarshal StateAcknowledgedEvent: %s", err)
-		return
+		log.L(ctx).Errorf("Error writing state %s from %s: %s", s.StateID, message.ReplyTo, err)
+		return nil, err
 	}
-	sd.acknowledgementWriter.Queue(ctx, stateAcknowledgedEvent.DistributionId)
-	// no need to wait for the flush to complete, we can just stop the in memory loop from retrying
-	// worst case scenario, we crash before this is written to the DB, we do some redundant retries after a restart
-	// but waiting for the flush here is not going to prevent that
-	sd.acknowledgedChan <- stateAcknowledgedEvent.DistributionId
 
+	return func() {
+		krc.Close(true)
+		if sd.stateReceived != nil {
+			// Share with the sequencer in case the state is needed to assemble in-flight transactions
+			go sd.stateReceived(tracing.ExtractContext(sd.runCtx, message.TraceContext), stateProducedEvent)
+		}
+	}, nil
 }
@@ -128,6 +128,24 @@ func (ss *stateManager) WriteStateFinalizations(ctx context.Context, dbTX *gorm.
 	return err
 }
 
+// When the block indexer detects a re-org deeper than the confirmation depth, any
+// finalization records we indexed from blocks after the fork are no longer valid.
+// The domain event stream will be re-delivered the events from the new canonical chain.
+func (ss *stateManager) RewindStateFinalizations(ctx context.Context, dbTX *gorm.DB, domainName string, forkBlock int64) (err error) {
+	for _, table := range []string{"state_spend_records", "state_read_records", "state_confirm_records", "state_info_records"} {
+		if err == nil {
+			err = dbTX.
+				WithContext(ctx).
+				Table(table).
+				Where("domain_name = ?", domainName).
+				Where("block_number > ?", forkBlock).
+				Delete(&pldapi.StateConfirmRecord{}).
+				Error
+		}
+	}
+	return err
+}
+
 func (ss *stateManager) GetTransactionStates(ctx context.Context, dbTX *gorm.DB, txID uuid.UUID) (*pldapi.TransactionStates, error) {
 
 	// We query from the records table, joining in the other fields
@@ -136,8 +154,11 @@ func (ss *stateManager) GetTransactionStates(ctx context.Context, dbTX *gorm.DB,
 		WithContext(ctx).
 		// This query joins across three tables in a single query - pushing the complexity to the DB.
 		// The reason we have three tables is to make the queries for available states simpler.
+		// Spends of nullifiers are resolved to the state they nullify, where that is known locally.
 		Raw(`SELECT * from "states" RIGHT JOIN ( `+
-			`SELECT "transaction", "state", 'spent'     AS "record_type" FROM "state_spend_records"   WHERE "transaction" = ? UNION ALL `+
+			`SELECT "transaction", COALESCE("state_nullifiers"."state", "state_spend_records"."state") AS "state", 'spent' AS "record_type" FROM "state_spend_records" `+
+			`LEFT JOIN "state_nullifiers" ON "state_nullifiers"."domain_name" = "state_spend_records"."domain_name" AND "state_nullifiers"."id" = "state_spend_records"."state" `+
+			`WHERE "transaction" = ? UNION ALL `+
 			`SELECT "transaction", "state", 'read'      AS "record_type" FROM "state_read_records"    WHERE "transaction" = ? UNION ALL `+
 			`SELECT "transaction", "state", 'confirmed' AS "record_type" FROM "state_confirm_records" WHERE "transaction" = ? UNION ALL `+
 			`SELECT "transaction", "state", 'info'      AS "record_type" FROM "state_info_records"    WHERE "transaction" = ? ) "records" `+
@@ -25,12 +25,14 @@ import (
 	"github.com/google/uuid"
 
 	"github.com/kaleido-io/paladin/config/pkg/pldconf"
+	"github.com/kaleido-io/paladin/core/internal/components"
 	"github.com/kaleido-io/paladin/core/mocks/componentmocks"
 	"github.com/kaleido-io/paladin/core/pkg/persistence"
 	"github.com/kaleido-io/paladin/core/pkg/persistence/mockpersistence"
 	"github.com/kaleido-io/paladin/toolkit/pkg/pldapi"
 	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 )
 
@@ -132,6 +134,40 @@ func TestGetTransactionStatesUnavailable(t *testing.T) {
 	require.Equal(t, []tktypes.HexBytes{stateID4}, txStates.Unavailable.Info)
 }
 
+func TestGetTransactionStatesNullifierSpend(t *testing.T) {
+
+	ctx, ss, m, done := newDBTestStateManager(t)
+	defer done()
+
+	md := componentmocks.NewDomain(t)
+	md.On("Name").Return("domain1")
+	m.domainManager.On("GetDomainByName", mock.Anything, "domain1").Return(md, nil)
+
+	txID := uuid.New()
+	nullifierID := tktypes.HexBytes(tktypes.RandBytes(32))
+	stateID := tktypes.HexBytes(tktypes.RandBytes(32))
+	unknownNullifierID := tktypes.HexBytes(tktypes.RandBytes(32))
+
+	err := ss.WriteNullifiersForReceivedStates(ctx, ss.p.DB(), "domain1", []*components.NullifierUpsert{
+		{ID: nullifierID, State: stateID},
+	})
+	require.NoError(t, err)
+
+	err = ss.WriteStateFinalizations(ctx, ss.p.DB(),
+		[]*pldapi.StateSpendRecord{
+			{DomainName: "domain1", State: nullifierID, Transaction: txID},
+			{DomainName: "domain1", State: unknownNullifierID, Transaction: txID},
+		},
+		[]*pldapi.StateReadRecord{},
+		[]*pldapi.StateConfirmRecord{},
+		[]*pldapi.StateInfoRecord{})
+	require.NoError(t, err)
+
+	txStates, err := ss.GetTransactionStates(ctx, ss.p.DB(), txID)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []tktypes.HexBytes{stateID, unknownNullifierID}, txStates.Unavailable.Spent)
+}
+
 func TestGetTransactionStatesFail(t *testing.T) {
 
 	ctx, ss, db, _, done := newDBMockStateManager(t)
@@ -142,3 +178,61 @@ func TestGetTransactionStatesFail(t *testing.T) {
 	_, err := ss.GetTransactionStates(ctx, ss.p.DB(), uuid.New())
 	assert.Regexp(t, "pop", err)
 }
+
+func TestRewindStateFinalizations(t *testing.T) {
+
+	ctx, ss, _, done := newDBTestStateManager(t)
+	defer done()
+
+	txID1 := uuid.New()
+	txID2 := uuid.New()
+	block1000 := int64(1000)
+	block1001 := int64(1001)
+	stateIDs := make([]tktypes.HexBytes, 5)
+	for i := range stateIDs {
+		stateIDs[i] = tktypes.RandBytes(32)
+	}
+
+	err := ss.WriteStateFinalizations(ctx, ss.p.DB(),
+		[]*pldapi.StateSpendRecord{
+			{DomainName: "domain1", State: stateIDs[0], Transaction: txID2, BlockNumber: &block1001},
+		},
+		[]*pldapi.StateReadRecord{
+			{DomainName: "domain1", State: stateIDs[1], Transaction: txID2, BlockNumber: &block1001},
+		},
+		[]*pldapi.StateConfirmRecord{
+			{DomainName: "domain1", State: stateIDs[2], Transaction: txID1, BlockNumber: &block1000},
+			{DomainName: "domain1", State: stateIDs[3], Transaction: txID2, BlockNumber: &block1001},
+			{DomainName: "domain2", State: stateIDs[4], Transaction: txID2, BlockNumber: &block1001},
+		},
+		[]*pldapi.StateInfoRecord{
+			{DomainName: "domain1", State: stateIDs[0], Transaction: txID2, BlockNumber: &block1001},
+		})
+	require.NoError(t, err)
+
+	err = ss.RewindStateFinalizations(ctx, ss.p.DB(), "domain1", 1000)
+	require.NoError(t, err)
+
+	// The records from the rewound block are gone, for only this domain
+	txStates, err := ss.GetTransactionStates(ctx, ss.p.DB(), txID2)
+	require.NoError(t, err)
+	assert.Empty(t, txStates.Unavailable.Spent)
+	assert.Empty(t, txStates.Unavailable.Read)
+	assert.Equal(t, []tktypes.HexBytes{stateIDs[4]}, txStates.Unavailable.Confirmed)
+	assert.Empty(t, txStates.Unavailable.Info)
+
+	txStates, err = ss.GetTransactionStates(ctx, ss.p.DB(), txID1)
+	require.NoError(t, err)
+	assert.Equal(t, []tktypes.HexBytes{stateIDs[2]}, txStates.Unavailable.Confirmed)
+}
+
+func TestRewindStateFinalizationsFail(t *testing.T) {
+
+	ctx, ss, db, _, done := newDBMockStateManager(t)
+	defer done()
+
+	db.ExpectExec("DELETE.*state_spend_records").WillReturnError(fmt.Errorf("pop"))
+
+	err := ss.RewindStateFinalizations(ctx, ss.p.DB(), "domain1", 1000)
+	assert.Regexp(t, "pop", err)
+}
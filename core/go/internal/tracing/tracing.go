@@ -0,0 +1,173 @@
+/*
+ * Copyright © 2024 Kaleido, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package tracing
+
+import (
+	"context"
+	"io"
+	"os"
+
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/kaleido-io/paladin/config/pkg/confutil"
+	"github.com/kaleido-io/paladin/config/pkg/pldconf"
+	"github.com/kaleido-io/paladin/core/internal/msgs"
+	"github.com/kaleido-io/paladin/toolkit/pkg/log"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationPrefix = "github.com/kaleido-io/paladin/core/"
+
+// Tracing owns the OpenTelemetry tracer provider for the node. When enabled it is installed
+// as the global provider, so components obtain their tracer with Tracer() and are no-ops
+// when tracing is disabled.
+type Tracing interface {
+	Close()
+}
+
+type tracingManager struct {
+	bgCtx    context.Context
+	provider *sdktrace.TracerProvider
+	file     io.Closer
+}
+
+func NewTracing(ctx context.Context, conf *pldconf.TracingConfig) (_ Tracing, err error) {
+	// W3C trace context is always propagated, so a node with tracing disabled still passes
+	// through the context it receives from other nodes
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	tm := &tracingManager{bgCtx: ctx}
+	if !confutil.Bool(conf.Enabled, *pldconf.TracingDefaults.Enabled) {
+		log.L(ctx).Debugf("Tracing disabled")
+		return tm, nil
+	}
+
+	var exporter sdktrace.SpanExporter
+	exporterType := confutil.StringNotEmpty(conf.Exporter, *pldconf.TracingDefaults.Exporter)
+	switch exporterType {
+	case pldconf.TracingExporterOTLP:
+		exporter, err = newOTLPExporter(ctx, &conf.OTLP)
+	case pldconf.TracingExporterFile:
+		exporter, err = tm.newFileExporter(ctx, &conf.File)
+	default:
+		err = i18n.NewError(ctx, msgs.MsgTracingInvalidExporter, exporterType)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	res := resource.NewSchemaless(
+		attribute.String("service.name", confutil.StringNotEmpty(conf.ServiceName, *pldconf.TracingDefaults.ServiceName)),
+	)
+	sampleRatio := confutil.Float64Min(conf.SampleRatio, 0, *pldconf.TracingDefaults.SampleRatio)
+	tm.provider = sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(sampleRatio))),
+	)
+	otel.SetTracerProvider(tm.provider)
+	log.L(ctx).Infof("Tracing enabled exporter=%s sampleRatio=%f", exporterType, sampleRatio)
+	return tm, nil
+}
+
+func newOTLPExporter(ctx context.Context, conf *pldconf.TracingOTLPConfig) (sdktrace.SpanExporter, error) {
+	opts := []otlptracehttp.Option{
+		otlptracehttp.WithEndpoint(confutil.StringNotEmpty(conf.Endpoint, *pldconf.TracingDefaults.OTLP.Endpoint)),
+		otlptracehttp.WithURLPath(confutil.StringNotEmpty(conf.URLPath, *pldconf.TracingDefaults.OTLP.URLPath)),
+		otlptracehttp.WithTimeout(confutil.DurationMin(conf.Timeout, 0, *pldconf.TracingDefaults.OTLP.Timeout)),
+	}
+	if conf.Insecure {
+		opts = append(opts, otlptracehttp.WithInsecure())
+	}
+	if len(conf.Headers) > 0 {
+		opts = append(opts, otlptracehttp.WithHeaders(conf.Headers))
+	}
+	// this does not connect to the collector until the first batch is exported
+	exporter, err := otlptracehttp.New(ctx, opts...)
+	if err != nil {
+		return nil, i18n.WrapError(ctx, err, msgs.MsgTracingExporterInit)
+	}
+	return exporter, nil
+}
+
+func (tm *tracingManager) newFileExporter(ctx context.Context, conf *pldconf.TracingFileConfig) (sdktrace.SpanExporter, error) {
+	path := confutil.StringNotEmpty(conf.Path, *pldconf.TracingDefaults.File.Path)
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, i18n.WrapError(ctx, err, msgs.MsgTracingFileOpenFailed, path)
+	}
+	tm.file = f
+	// one JSON object per span, per line
+	exporter, err := stdouttrace.New(stdouttrace.WithWriter(f))
+	if err != nil {
+		_ = f.Close()
+		return nil, i18n.WrapError(ctx, err, msgs.MsgTracingExporterInit)
+	}
+	return exporter, nil
+}
+
+func (tm *tracingManager) Close() {
+	if tm.provider != nil {
+		// flushes any batched spans to the exporter
+		if err := tm.provider.Shutdown(tm.bgCtx); err != nil {
+			log.L(tm.bgCtx).Warnf("Failed to shut down tracing: %s", err)
+		}
+	}
+	if tm.file != nil {
+		_ = tm.file.Close()
+	}
+}
+
+// Tracer returns the tracer for a component, from the global provider
+func Tracer(component string) trace.Tracer {
+	return otel.Tracer(instrumentationPrefix + component)
+}
+
+// EndSpan ends a span, recording the error (if any) as the span status
+func EndSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// InjectContext serializes the span context of ctx for propagation to another node.
+// Returns nil if there is no span to propagate.
+func InjectContext(ctx context.Context) map[string]string {
+	carrier := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+	if len(carrier) == 0 {
+		return nil
+	}
+	return carrier
+}
+
+// ExtractContext returns a context with the remote span context from a carrier built by
+// InjectContext, so that spans started from it are linked into the originating trace.
+func ExtractContext(ctx context.Context, carrier map[string]string) context.Context {
+	if len(carrier) == 0 {
+		return ctx
+	}
+	return otel.GetTextMapPropagator().Extract(ctx, propagation.MapCarrier(carrier))
+}
@@ -0,0 +1,142 @@
+/*
+ * Copyright © 2024 Kaleido, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package tracing
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/kaleido-io/paladin/config/pkg/confutil"
+	"github.com/kaleido-io/paladin/config/pkg/pldconf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+func resetGlobalProvider(t *testing.T) {
+	t.Cleanup(func() {
+		otel.SetTracerProvider(noop.NewTracerProvider())
+	})
+}
+
+func TestTracingDisabled(t *testing.T) {
+	tm, err := NewTracing(context.Background(), &pldconf.TracingConfig{})
+	require.NoError(t, err)
+	defer tm.Close()
+
+	// no span is recorded, so there is no context to propagate
+	ctx, span := Tracer("test").Start(context.Background(), "noop")
+	defer span.End()
+	assert.False(t, span.SpanContext().IsSampled())
+	assert.Nil(t, InjectContext(ctx))
+}
+
+func TestTracingFileExporter(t *testing.T) {
+	resetGlobalProvider(t)
+	traceFile := path.Join(t.TempDir(), "traces.json")
+	tm, err := NewTracing(context.Background(), &pldconf.TracingConfig{
+		Enabled:     confutil.P(true),
+		ServiceName: confutil.P("node1"),
+		Exporter:    confutil.P(pldconf.TracingExporterFile),
+		File:        pldconf.TracingFileConfig{Path: &traceFile},
+	})
+	require.NoError(t, err)
+
+	// a span on the "sending" side, propagated to a "receiving" side
+	ctx, sendSpan := Tracer("test").Start(context.Background(), "send")
+	carrier := InjectContext(ctx)
+	assert.NotEmpty(t, carrier["traceparent"])
+	sendSpan.End()
+
+	remoteCtx := ExtractContext(context.Background(), carrier)
+	_, recvSpan := Tracer("test").Start(remoteCtx, "receive")
+	EndSpan(recvSpan, assert.AnError)
+
+	tm.Close()
+
+	f, err := os.Open(traceFile)
+	require.NoError(t, err)
+	defer f.Close()
+	type jsonSpan struct {
+		Name        string
+		SpanContext struct{ TraceID, SpanID string }
+		Parent      struct{ TraceID, SpanID string }
+		Status      struct{ Code string }
+	}
+	var spans []*jsonSpan
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 1024*1024), 1024*1024)
+	for scanner.Scan() {
+		var s jsonSpan
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &s))
+		spans = append(spans, &s)
+	}
+	require.Len(t, spans, 2)
+	assert.Equal(t, "send", spans[0].Name)
+	assert.Equal(t, "receive", spans[1].Name)
+	assert.Equal(t, spans[0].SpanContext.TraceID, spans[1].SpanContext.TraceID)
+	assert.Equal(t, spans[0].SpanContext.SpanID, spans[1].Parent.SpanID)
+	assert.Equal(t, "Error", spans[1].Status.Code)
+}
+
+func TestTracingOTLPExporter(t *testing.T) {
+	resetGlobalProvider(t)
+	tm, err := NewTracing(context.Background(), &pldconf.TracingConfig{
+		Enabled:     confutil.P(true),
+		SampleRatio: confutil.P(0.0),
+		OTLP: pldconf.TracingOTLPConfig{
+			Insecure: true,
+			Headers:  map[string]string{"authorization": "Bearer token"},
+		},
+	})
+	require.NoError(t, err)
+	defer tm.Close()
+
+	// nothing is sampled, so no export to the (non-existent) collector is attempted
+	_, span := Tracer("test").Start(context.Background(), "unsampled")
+	defer span.End()
+	assert.True(t, span.SpanContext().IsValid())
+	assert.False(t, span.SpanContext().IsSampled())
+}
+
+func TestTracingBadExporter(t *testing.T) {
+	_, err := NewTracing(context.Background(), &pldconf.TracingConfig{
+		Enabled:  confutil.P(true),
+		Exporter: confutil.P("wrong"),
+	})
+	assert.Regexp(t, "PD012500.*wrong", err)
+}
+
+func TestTracingBadFile(t *testing.T) {
+	_, err := NewTracing(context.Background(), &pldconf.TracingConfig{
+		Enabled:  confutil.P(true),
+		Exporter: confutil.P(pldconf.TracingExporterFile),
+		File:     pldconf.TracingFileConfig{Path: confutil.P(t.TempDir())},
+	})
+	assert.Regexp(t, "PD012501", err)
+}
+
+func TestExtractContextEmpty(t *testing.T) {
+	ctx := context.Background()
+	assert.Equal(t, ctx, ExtractContext(ctx, nil))
+	assert.False(t, trace.SpanContextFromContext(ExtractContext(ctx, map[string]string{"traceparent": "bad"})).IsValid())
+}
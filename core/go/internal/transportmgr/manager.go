@@ -17,7 +17,9 @@ package transportmgr
 
 import (
 	"context"
+	"sort"
 	"sync"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/hyperledger/firefly-common/pkg/i18n"
@@ -25,13 +27,21 @@ import (
 	"github.com/kaleido-io/paladin/config/pkg/pldconf"
 	"github.com/kaleido-io/paladin/core/internal/components"
 	"github.com/kaleido-io/paladin/core/internal/msgs"
+	"github.com/kaleido-io/paladin/core/internal/tracing"
+	"github.com/kaleido-io/paladin/core/pkg/persistence"
 
 	"github.com/kaleido-io/paladin/toolkit/pkg/log"
+	"github.com/kaleido-io/paladin/toolkit/pkg/pldapi"
 	"github.com/kaleido-io/paladin/toolkit/pkg/plugintk"
 	"github.com/kaleido-io/paladin/toolkit/pkg/prototk"
+	"github.com/kaleido-io/paladin/toolkit/pkg/retry"
 	"github.com/kaleido-io/paladin/toolkit/pkg/rpcserver"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
+var tracer = tracing.Tracer("transportmgr")
+
 type transportManager struct {
 	bgCtx context.Context
 	mux   sync.Mutex
@@ -40,6 +50,7 @@ type transportManager struct {
 	conf            *pldconf.TransportManagerConfig
 	localNodeName   string
 	registryManager components.RegistryManager
+	persistence     persistence.Persistence
 
 	transportsByID   map[uuid.UUID]*transport
 	transportsByName map[string]*transport
@@ -47,23 +58,51 @@ type transportManager struct {
 	destinations      map[string]components.TransportClient
 	destinationsFixed bool
 	destinationsMux   sync.RWMutex
+
+	peersLock    sync.Mutex
+	peers        map[string]*peer
+	peersStopped bool
+
+	reliableRetry             *retry.Retry
+	reliableMaxInFlight       int
+	reliableResendInterval    time.Duration
+	reliableMaxResendInterval time.Duration
+	reliableRetention         time.Duration
+	reliablePruneInterval     time.Duration
+	prunerCancel              context.CancelFunc
+	prunerDone                chan struct{}
+
+	metrics *transportMetrics
 }
 
 func NewTransportManager(bgCtx context.Context, conf *pldconf.TransportManagerConfig) components.TransportManager {
-	return &transportManager{
+	tm := &transportManager{
 		bgCtx:            bgCtx,
 		conf:             conf,
 		localNodeName:    conf.NodeName,
 		transportsByID:   make(map[uuid.UUID]*transport),
 		transportsByName: make(map[string]*transport),
 		destinations:     make(map[string]components.TransportClient),
+		peers:            make(map[string]*peer),
+		metrics:          newTransportMetrics(),
+
+		reliableRetry:          retry.NewRetryIndefinite(&conf.ReliableMessaging.Retry, &pldconf.TransportManagerDefaults.ReliableMessaging.Retry),
+		reliableMaxInFlight:    confutil.IntMin(conf.ReliableMessaging.MaxInFlight, 1, *pldconf.TransportManagerDefaults.ReliableMessaging.MaxInFlight),
+		reliableResendInterval: confutil.DurationMin(conf.ReliableMessaging.ResendInterval, 0, *pldconf.TransportManagerDefaults.ReliableMessaging.ResendInterval),
+		reliableRetention:      confutil.DurationMin(conf.ReliableMessaging.Retention, 0, *pldconf.TransportManagerDefaults.ReliableMessaging.Retention),
+		reliablePruneInterval:  confutil.DurationMin(conf.ReliableMessaging.PruneInterval, time.Second, *pldconf.TransportManagerDefaults.ReliableMessaging.PruneInterval),
 	}
+	tm.reliableMaxResendInterval = confutil.DurationMin(conf.ReliableMessaging.MaxResendInterval, tm.reliableResendInterval, *pldconf.TransportManagerDefaults.ReliableMessaging.MaxResendInterval)
+	return tm
 }
 
 func (tm *transportManager) PreInit(pic components.PreInitComponents) (*components.ManagerInitResult, error) {
 	if tm.localNodeName == "" {
 		return nil, i18n.NewError(tm.bgCtx, msgs.MsgTransportNodeNameNotConfigured)
 	}
+	if err := tm.metrics.register(pic.MetricsManager().Registry()); err != nil {
+		return nil, err
+	}
 	tm.initRPC()
 	return &components.ManagerInitResult{
 		RPCModules: []*rpcserver.RPCModule{tm.rpcModule},
@@ -75,18 +114,35 @@ func (tm *transportManager) PostInit(c components.AllComponents) error {
 	// plugin manager starts, and thus before any domain would have started any go-routine
 	// that could have cached a nil value in memory.
 	tm.registryManager = c.RegistryManager()
+	tm.persistence = c.Persistence()
 	return nil
 }
 
 func (tm *transportManager) Start() error {
 	tm.destinationsMux.Lock()
-	defer tm.destinationsMux.Unlock()
 	// All destinations must be registered as part of the startup sequence
 	tm.destinationsFixed = true
+	tm.destinationsMux.Unlock()
+
+	// Resume sending any reliable messages that were not acknowledged before we last stopped
+	if err := tm.startPeersWithQueuedMessages(); err != nil {
+		return err
+	}
+
+	var prunerCtx context.Context
+	prunerCtx, tm.prunerCancel = context.WithCancel(log.WithLogField(tm.bgCtx, "role", "reliable-pruner"))
+	tm.prunerDone = make(chan struct{})
+	go tm.pruner(prunerCtx, tm.prunerDone)
 	return nil
 }
 
 func (tm *transportManager) Stop() {
+	if tm.prunerCancel != nil {
+		tm.prunerCancel()
+		<-tm.prunerDone
+	}
+	tm.stopPeers()
+
 	tm.mux.Lock()
 	var allTransports []*transport
 	for _, t := range tm.transportsByID {
@@ -114,6 +170,17 @@ func (tm *transportManager) RegisterClient(ctx context.Context, client component
 
 }
 
+func (tm *transportManager) getDestination(ctx context.Context, destination string) (components.TransportClient, error) {
+	tm.destinationsMux.RLock()
+	defer tm.destinationsMux.RUnlock()
+	receiver, found := tm.destinations[destination]
+	if !found {
+		log.L(ctx).Errorf("Component not found: %s", destination)
+		return nil, i18n.NewError(ctx, msgs.MsgTransportDestinationNotFound, destination)
+	}
+	return receiver, nil
+}
+
 func (tm *transportManager) cleanupTransport(t *transport) {
 	// must not hold the transport lock when running this
 	t.close()
@@ -140,6 +207,18 @@ func (tm *transportManager) getTransportNames() []string {
 	return transportNames
 }
 
+func (tm *transportManager) getTransports() []*transport {
+	tm.mux.Lock()
+	defer tm.mux.Unlock()
+
+	transports := make([]*transport, 0, len(tm.transportsByName))
+	for _, t := range tm.transportsByName {
+		transports = append(transports, t)
+	}
+	sort.Slice(transports, func(i, j int) bool { return transports[i].name < transports[j].name })
+	return transports
+}
+
 func (tm *transportManager) getTransportByName(ctx context.Context, transportName string) (*transport, error) {
 	tm.mux.Lock()
 	defer tm.mux.Unlock()
@@ -159,6 +238,14 @@ func (tm *transportManager) getLocalTransportDetails(ctx context.Context, transp
 	return t.getLocalDetails(ctx)
 }
 
+func (tm *transportManager) TransportDetailsChanged(ctx context.Context) {
+	for _, t := range tm.getTransports() {
+		if err := t.transportDetailsChanged(ctx); err != nil {
+			log.L(ctx).Warnf("Failed to notify transport %s of transport details change: %s", t.name, err)
+		}
+	}
+}
+
 func (tm *transportManager) TransportRegistered(name string, id uuid.UUID, toTransport components.TransportManagerToTransport) (fromTransport plugintk.TransportCallbacks, err error) {
 	tm.mux.Lock()
 	defer tm.mux.Unlock()
@@ -192,9 +279,8 @@ func (tm *transportManager) LocalNodeName() string {
 	return tm.localNodeName
 }
 
-// See docs in components package
-func (tm *transportManager) Send(ctx context.Context, msg *components.TransportMessage) error {
-
+// Validates a message for sending, and sets the defaults
+func (tm *transportManager) prepareMessage(ctx context.Context, msg *components.TransportMessage) error {
 	// Check the message is valid
 	if len(msg.MessageType) == 0 ||
 		len(msg.Payload) == 0 {
@@ -210,6 +296,31 @@ func (tm *transportManager) Send(ctx context.Context, msg *components.TransportM
 		msg.ReplyTo = tm.localNodeName
 	}
 
+	var zeroUUID uuid.UUID
+	if msg.MessageID == zeroUUID {
+		msg.MessageID = uuid.New()
+	}
+	return nil
+}
+
+// See docs in components package
+func (tm *transportManager) Send(ctx context.Context, msg *components.TransportMessage) (err error) {
+	ctx, span := tracer.Start(ctx, "transport.send", trace.WithSpanKind(trace.SpanKindProducer), trace.WithAttributes(
+		attribute.String("paladin.node", msg.Node),
+		attribute.String("paladin.component", msg.Component),
+		attribute.String("paladin.message_type", msg.MessageType),
+	))
+	defer func() { tracing.EndSpan(span, err) }()
+
+	if err := tm.prepareMessage(ctx, msg); err != nil {
+		return err
+	}
+
+	// Record the outcome against the peer, for diagnostics
+	var outbound *pldapi.PeerOutbound
+	p := tm.getPeer(msg.Node)
+	defer func() { p.recordSend(outbound, err) }()
+
 	// Note the registry is responsible for caching to make this call as efficient as if
 	// we maintained the transport details in-memory ourselves.
 	registeredTransportDetails, err := tm.registryManager.GetNodeTransports(ctx, msg.Node)
@@ -222,7 +333,10 @@ func (tm *transportManager) Send(ctx context.Context, msg *components.TransportM
 	//       fallback to a secondary one currently.
 	var transport *transport
 	for _, rtd := range registeredTransportDetails {
-		transport = tm.transportsByName[rtd.Transport]
+		if transport = tm.transportsByName[rtd.Transport]; transport != nil {
+			outbound = &pldapi.PeerOutbound{Transport: rtd.Transport, Registry: rtd.Registry}
+			break
+		}
 	}
 	if transport == nil {
 		// If we didn't find one, then feedback to the caller which transports were registered
@@ -242,9 +356,8 @@ func (tm *transportManager) Send(ctx context.Context, msg *components.TransportM
 	if msg.CorrelationID != nil {
 		correlID = confutil.P(msg.CorrelationID.String())
 	}
-	var zeroUUID uuid.UUID
-	if msg.MessageID == zeroUUID {
-		msg.MessageID = uuid.New()
+	if msg.TraceContext == nil {
+		msg.TraceContext = tracing.InjectContext(ctx)
 	}
 	err = transport.send(ctx, &prototk.Message{
 		MessageType:   msg.MessageType,
@@ -254,6 +367,7 @@ func (tm *transportManager) Send(ctx context.Context, msg *components.TransportM
 		Node:          msg.Node,
 		ReplyTo:       msg.ReplyTo,
 		Payload:       msg.Payload,
+		TraceContext:  msg.TraceContext,
 	})
 	if err != nil {
 		return err
@@ -20,10 +20,14 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/google/uuid"
 	"github.com/kaleido-io/paladin/config/pkg/pldconf"
 	"github.com/kaleido-io/paladin/core/internal/components"
+	"github.com/kaleido-io/paladin/core/internal/metrics"
 	"github.com/kaleido-io/paladin/core/mocks/componentmocks"
+	"github.com/kaleido-io/paladin/core/pkg/persistence"
+	"github.com/kaleido-io/paladin/core/pkg/persistence/mockpersistence"
 	"github.com/sirupsen/logrus"
 
 	"github.com/kaleido-io/paladin/toolkit/pkg/plugintk"
@@ -36,12 +40,21 @@ import (
 type mockComponents struct {
 	c               *componentmocks.AllComponents
 	registryManager *componentmocks.RegistryManager
+	db              sqlmock.Sqlmock
+	p               persistence.Persistence
+	realDB          bool
 }
 
 func newMockComponents(t *testing.T) *mockComponents {
 	mc := &mockComponents{c: componentmocks.NewAllComponents(t)}
 	mc.registryManager = componentmocks.NewRegistryManager(t)
 	mc.c.On("RegistryManager").Return(mc.registryManager).Maybe()
+	mc.c.On("MetricsManager").Return(metrics.NewMetricsManager(context.Background())).Maybe()
+	mc.c.On("Persistence").Return(func() persistence.Persistence { return mc.p }).Maybe()
+	mp, err := mockpersistence.NewSQLMockProvider()
+	require.NoError(t, err)
+	mc.db = mp.Mock
+	mc.p = mp.P
 	return mc
 }
 
@@ -51,6 +64,8 @@ func newTestTransportManager(t *testing.T, conf *pldconf.TransportManagerConfig,
 	logrus.SetLevel(logrus.TraceLevel)
 
 	mc := newMockComponents(t)
+	// Start checks for peers with reliable messages to send
+	mc.db.ExpectQuery("SELECT DISTINCT.*reliable_msgs").WillReturnRows(sqlmock.NewRows([]string{"node"}))
 	var clients []components.TransportClient
 	for _, fn := range extraSetup {
 		client := fn(mc)
@@ -59,6 +74,13 @@ func newTestTransportManager(t *testing.T, conf *pldconf.TransportManagerConfig,
 		}
 	}
 
+	var pDone func()
+	if mc.realDB {
+		var err error
+		mc.p, pDone, err = persistence.NewUnitTestPersistence(ctx, "transportmgr")
+		require.NoError(t, err)
+	}
+
 	tm := NewTransportManager(ctx, conf)
 
 	ir, err := tm.PreInit(mc.c)
@@ -83,6 +105,9 @@ func newTestTransportManager(t *testing.T, conf *pldconf.TransportManagerConfig,
 		logrus.SetLevel(oldLevel)
 		cancelCtx()
 		tm.Stop()
+		if pDone != nil {
+			pDone()
+		}
 	}
 }
 
@@ -92,6 +117,16 @@ func TestMissingName(t *testing.T) {
 	assert.Regexp(t, "PD012002", err)
 }
 
+func TestPreInitMetricsError(t *testing.T) {
+	mc := newMockComponents(t)
+	err := newTransportMetrics().register(mc.c.MetricsManager().Registry())
+	require.NoError(t, err)
+
+	tm := NewTransportManager(context.Background(), &pldconf.TransportManagerConfig{NodeName: "node1"})
+	_, err = tm.PreInit(mc.c)
+	assert.Regexp(t, "duplicate metrics collector registration", err)
+}
+
 func TestConfiguredTransports(t *testing.T) {
 	_, dm, _, done := newTestTransportManager(t, &pldconf.TransportManagerConfig{
 		NodeName: "node1",
@@ -189,3 +224,23 @@ func TestGetLocalTransportDetailsNotFail(t *testing.T) {
 	_, err := tm.getLocalTransportDetails(ctx, tp.t.name)
 	assert.Regexp(t, "pop", err)
 }
+
+func TestTransportDetailsChanged(t *testing.T) {
+	ctx, tm, tp, done := newTestTransport(t)
+	defer done()
+
+	notified := 0
+	tp.Functions.TransportDetailsChanged = func(ctx context.Context, tdcr *prototk.TransportDetailsChangedRequest) (*prototk.TransportDetailsChangedResponse, error) {
+		notified++
+		return nil, fmt.Errorf("pop")
+	}
+
+	// Errors are logged, not returned
+	tm.TransportDetailsChanged(ctx)
+	assert.Equal(t, 1, notified)
+
+	// Transports that are not initialized are skipped
+	tp.t.initialized.Store(false)
+	tm.TransportDetailsChanged(ctx)
+	assert.Equal(t, 1, notified)
+}
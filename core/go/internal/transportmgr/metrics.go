@@ -0,0 +1,64 @@
+/*
+ * Copyright © 2024 Kaleido, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package transportmgr
+
+import (
+	"github.com/kaleido-io/paladin/core/internal/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type transportMetrics struct {
+	messagesSent     *prometheus.CounterVec
+	messagesReceived *prometheus.CounterVec
+}
+
+func newTransportMetrics() *transportMetrics {
+	return &transportMetrics{
+		messagesSent: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: "transport",
+			Name:      "messages_sent_total",
+			Help:      "Number of messages sent to each peer node",
+		}, []string{"transport", "peer", "result"}),
+		messagesReceived: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: "transport",
+			Name:      "messages_received_total",
+			Help:      "Number of messages received from each peer node",
+		}, []string{"transport", "peer"}),
+	}
+}
+
+func (m *transportMetrics) register(r prometheus.Registerer) error {
+	for _, c := range []prometheus.Collector{m.messagesSent, m.messagesReceived} {
+		if err := r.Register(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *transportMetrics) recordSend(transport, peer string, err error) {
+	result := "success"
+	if err != nil {
+		result = "fail"
+	}
+	m.messagesSent.WithLabelValues(transport, peer, result).Inc()
+}
+
+func (m *transportMetrics) recordReceive(transport, peer string) {
+	m.messagesReceived.WithLabelValues(transport, peer).Inc()
+}
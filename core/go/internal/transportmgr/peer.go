@@ -0,0 +1,387 @@
+/*
+ * Copyright © 2024 Kaleido, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package transportmgr
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kaleido-io/paladin/core/internal/components"
+	"github.com/kaleido-io/paladin/toolkit/pkg/log"
+	"github.com/kaleido-io/paladin/toolkit/pkg/pldapi"
+	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
+)
+
+// The number of recent errors retained for each peer
+const peerRecentErrors = 10
+
+// Each node this node has exchanged messages with since it started has a peer, that records
+// the status of the communication with that node.
+//
+// While there are reliable messages queued for the node, the peer also runs a single sender go
+// routine that reads the oldest unacknowledged messages in sequence order from the DB, and sends
+// them to the node. Messages that are not acknowledged within the resend interval are sent again.
+// The sender exits once all messages are acknowledged, and is restarted when the next is queued.
+type peer struct {
+	tm   *transportManager
+	name string
+
+	ctx       context.Context
+	cancelCtx context.CancelFunc
+
+	statsLock sync.Mutex
+	activated tktypes.Timestamp
+	outbound  *pldapi.PeerOutbound
+	stats     pldapi.PeerStats
+	errors    []*pldapi.PeerError
+
+	senderDone chan struct{} // nil while no sender is running (protected by the peers lock)
+	notify     chan struct{}
+	lastSent   map[uuid.UUID]*sentMessage
+}
+
+// The backoff for resending each message is tracked separately, as one message that is
+// never acknowledged (such as one for a component the peer does not have) must not
+// delay the others
+type sentMessage struct {
+	time           time.Time
+	resendInterval time.Duration
+}
+
+func (tm *transportManager) getPeer(node string) *peer {
+	tm.peersLock.Lock()
+	defer tm.peersLock.Unlock()
+	return tm.getPeerLocked(node)
+}
+
+func (tm *transportManager) getPeerLocked(node string) *peer {
+	p := tm.peers[node]
+	if p == nil {
+		p = &peer{
+			tm:        tm,
+			name:      node,
+			activated: tktypes.TimestampNow(),
+			notify:    make(chan struct{}, 1),
+		}
+		p.ctx, p.cancelCtx = context.WithCancel(log.WithLogField(tm.bgCtx, "peer", node))
+		tm.peers[node] = p
+	}
+	return p
+}
+
+func (tm *transportManager) startPeersWithQueuedMessages() error {
+	var nodes []string
+	err := tm.persistence.DB().
+		WithContext(tm.bgCtx).
+		Table("reliable_msgs").
+		Joins("LEFT JOIN reliable_msg_acks ON reliable_msg_acks.id = reliable_msgs.id").
+		Where("reliable_msg_acks.id IS NULL").
+		Distinct().
+		Pluck("reliable_msgs.node", &nodes).
+		Error
+	if err != nil {
+		return err
+	}
+	for _, node := range nodes {
+		tm.wakeSender(node, true)
+	}
+	return nil
+}
+
+// Notifies the sender for the node that the queue has changed, optionally starting
+// it if there is not one already running
+func (tm *transportManager) wakeSender(node string, start bool) {
+	tm.peersLock.Lock()
+	defer tm.peersLock.Unlock()
+
+	p := tm.peers[node]
+	if p == nil || p.senderDone == nil {
+		if !start || tm.peersStopped {
+			return
+		}
+		p = tm.getPeerLocked(node)
+		p.senderDone = make(chan struct{})
+		p.lastSent = make(map[uuid.UUID]*sentMessage)
+		go p.sender(p.senderDone)
+	}
+	select {
+	case p.notify <- struct{}{}:
+	default:
+	}
+}
+
+// Called with no messages left to send. Holding the lock while checking for a notification
+// means a message cannot be queued between the check, and the sender being removed.
+func (tm *transportManager) stopSenderIfIdle(p *peer) bool {
+	tm.peersLock.Lock()
+	defer tm.peersLock.Unlock()
+
+	select {
+	case <-p.notify:
+		return false
+	default:
+	}
+	p.senderDone = nil
+	return true
+}
+
+func (tm *transportManager) stopPeers() {
+	tm.peersLock.Lock()
+	tm.peersStopped = true
+	senders := make([]chan struct{}, 0, len(tm.peers))
+	for _, p := range tm.peers {
+		p.cancelCtx()
+		if p.senderDone != nil {
+			senders = append(senders, p.senderDone)
+		}
+	}
+	tm.peers = make(map[string]*peer)
+	tm.peersLock.Unlock()
+
+	for _, senderDone := range senders {
+		<-senderDone
+	}
+}
+
+func (p *peer) recordError(err error) {
+	// Called with the stats lock held
+	p.errors = append([]*pldapi.PeerError{{
+		Time:    tktypes.TimestampNow(),
+		Message: err.Error(),
+	}}, p.errors...)
+	if len(p.errors) > peerRecentErrors {
+		p.errors = p.errors[0:peerRecentErrors]
+	}
+}
+
+func (p *peer) recordSend(outbound *pldapi.PeerOutbound, err error) {
+	p.statsLock.Lock()
+	defer p.statsLock.Unlock()
+
+	if outbound != nil {
+		p.outbound = outbound
+	}
+	if err != nil {
+		p.stats.SendErrors++
+		p.recordError(err)
+		return
+	}
+	now := tktypes.TimestampNow()
+	p.stats.SentMsgs++
+	p.stats.LastSend = &now
+}
+
+func (p *peer) recordReceive(err error) {
+	p.statsLock.Lock()
+	defer p.statsLock.Unlock()
+
+	now := tktypes.TimestampNow()
+	p.stats.ReceivedMsgs++
+	p.stats.LastReceive = &now
+	if err != nil {
+		p.recordError(err)
+	}
+}
+
+func (p *peer) setInFlight(inFlight int) {
+	p.statsLock.Lock()
+	defer p.statsLock.Unlock()
+	p.stats.ReliableInFlight = inFlight
+}
+
+func (p *peer) info() *pldapi.PeerInfo {
+	p.statsLock.Lock()
+	defer p.statsLock.Unlock()
+
+	pi := &pldapi.PeerInfo{
+		Name:      p.name,
+		Activated: p.activated,
+		Stats:     p.stats,
+		Errors:    append([]*pldapi.PeerError{}, p.errors...),
+	}
+	if p.outbound != nil {
+		outbound := *p.outbound
+		pi.Outbound = &outbound
+	}
+	return pi
+}
+
+// Returns the status of the known peers - either all of them, or just the named one
+func (tm *transportManager) getPeersInfo(ctx context.Context, nodes ...string) ([]*pldapi.PeerInfo, error) {
+	tm.peersLock.Lock()
+	peers := make([]*peer, 0, len(tm.peers))
+	for _, p := range tm.peers {
+		if len(nodes) == 0 || p.name == nodes[0] {
+			peers = append(peers, p)
+		}
+	}
+	tm.peersLock.Unlock()
+	sort.Slice(peers, func(i, j int) bool { return peers[i].name < peers[j].name })
+
+	if len(peers) == 0 {
+		return []*pldapi.PeerInfo{}, nil
+	}
+
+	var queued []*struct {
+		Node  string `gorm:"column:node"`
+		Count int64  `gorm:"column:count"`
+	}
+	q := tm.persistence.DB().
+		WithContext(ctx).
+		Table("reliable_msgs").
+		Select("reliable_msgs.node AS node", "COUNT(*) AS count").
+		Joins("LEFT JOIN reliable_msg_acks ON reliable_msg_acks.id = reliable_msgs.id").
+		Where("reliable_msg_acks.id IS NULL").
+		Group("reliable_msgs.node")
+	if len(nodes) > 0 {
+		q = q.Where("reliable_msgs.node = ?", nodes[0])
+	}
+	if err := q.Find(&queued).Error; err != nil {
+		return nil, err
+	}
+	queuedByNode := make(map[string]int64, len(queued))
+	for _, qn := range queued {
+		queuedByNode[qn.Node] = qn.Count
+	}
+
+	transports := tm.getTransports()
+	peersInfo := make([]*pldapi.PeerInfo, len(peers))
+	for i, p := range peers {
+		pi := p.info()
+		pi.Stats.ReliableQueued = queuedByNode[p.name]
+		for _, t := range transports {
+			// The connection state is diagnostic information, so we do not fail if we cannot get it
+			if connInfo, err := t.getPeerInfo(ctx, p.name); err != nil {
+				log.L(ctx).Warnf("Failed to get connection info for peer %s from transport %s: %s", p.name, t.name, err)
+			} else if connInfo != nil {
+				if pi.Connections == nil {
+					pi.Connections = make(map[string]tktypes.RawJSON)
+				}
+				pi.Connections[t.name] = connInfo
+			}
+		}
+		peersInfo[i] = pi
+	}
+	return peersInfo, nil
+}
+
+func (p *peer) readPage() (page []*reliableMessage, err error) {
+	err = p.tm.reliableRetry.Do(p.ctx, func(attempt int) (retryable bool, err error) {
+		return true, p.tm.persistence.DB().
+			WithContext(p.ctx).
+			Table("reliable_msgs").
+			Select("reliable_msgs.*").
+			Joins("LEFT JOIN reliable_msg_acks ON reliable_msg_acks.id = reliable_msgs.id").
+			Where("reliable_msg_acks.id IS NULL").
+			Where("reliable_msgs.node = ?", p.name).
+			Order("reliable_msgs.sequence").
+			Limit(p.tm.reliableMaxInFlight).
+			Find(&page).
+			Error
+	})
+	return page, err
+}
+
+func (p *peer) send(rm *reliableMessage) error {
+	return p.tm.reliableRetry.Do(p.ctx, func(attempt int) (retryable bool, err error) {
+		err = p.tm.Send(p.ctx, &components.TransportMessage{
+			MessageID:   rm.ID,
+			Node:        p.name,
+			Component:   transportManagerDestination,
+			MessageType: msgTypeReliable,
+			Payload:     tktypes.JSONString(rm),
+		})
+		if err != nil {
+			log.L(p.ctx).Warnf("Failed to send reliable message %s (seq=%d) to %s (attempt=%d): %s", rm.ID, rm.Sequence, p.name, attempt, err)
+		}
+		return true, err
+	})
+}
+
+// Sends each message in the page that has not been sent, or is due to be resent,
+// and returns how long until the next message in the page is due to be resent.
+// The interval before resending a message doubles each time it is resent, up to the maximum.
+func (p *peer) sendPage(page []*reliableMessage) (nextResend time.Duration, err error) {
+	nextResend = p.tm.reliableMaxResendInterval
+	lastSent := make(map[uuid.UUID]*sentMessage, len(page)) // drops any that have been acked
+	for _, rm := range page {
+		resendInterval := p.tm.reliableResendInterval
+		if sent := p.lastSent[rm.ID]; sent != nil {
+			if due := time.Until(sent.time.Add(sent.resendInterval)); due > 0 {
+				lastSent[rm.ID] = sent
+				nextResend = min(nextResend, due)
+				continue
+			}
+			resendInterval = min(sent.resendInterval*2, p.tm.reliableMaxResendInterval)
+			log.L(p.ctx).Infof("Resending unacknowledged reliable message %s (seq=%d) to %s (next resend in %s)", rm.ID, rm.Sequence, p.name, resendInterval)
+		}
+		if err := p.send(rm); err != nil {
+			return 0, err
+		}
+		lastSent[rm.ID] = &sentMessage{time: time.Now(), resendInterval: resendInterval}
+		nextResend = min(nextResend, resendInterval)
+	}
+	p.lastSent = lastSent
+	p.setInFlight(len(lastSent))
+	return nextResend, nil
+}
+
+func (p *peer) waitForNotify(timeout time.Duration) bool {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-p.notify:
+	case <-timer.C:
+	case <-p.ctx.Done():
+		return false
+	}
+	return true
+}
+
+func (p *peer) sender(done chan struct{}) {
+	defer close(done)
+
+	for {
+		page, err := p.readPage()
+		if err != nil {
+			log.L(p.ctx).Debugf("Sender stopping: %s", err)
+			return
+		}
+
+		if len(page) == 0 {
+			p.lastSent = nil
+			p.setInFlight(0)
+			if p.tm.stopSenderIfIdle(p) {
+				log.L(p.ctx).Debugf("Sender stopping with all reliable messages acknowledged")
+				return
+			}
+			continue
+		}
+
+		nextResend, err := p.sendPage(page)
+		if err != nil {
+			log.L(p.ctx).Debugf("Sender stopping: %s", err)
+			return
+		}
+		if !p.waitForNotify(nextResend) {
+			log.L(p.ctx).Debugf("Sender stopping")
+			return
+		}
+	}
+}
@@ -0,0 +1,286 @@
+/*
+ * Copyright © 2024 Kaleido, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package transportmgr
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/kaleido-io/paladin/core/internal/components"
+	"github.com/kaleido-io/paladin/core/internal/msgs"
+	"github.com/kaleido-io/paladin/toolkit/pkg/log"
+	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Reliable messages are sent wrapped inside a message to the transport manager on the
+// receiving node, which acknowledges each one after it has been processed.
+const (
+	transportManagerDestination = "transport-manager"
+	msgTypeReliable             = "reliable"
+	msgTypeAck                  = "ack"
+)
+
+type reliableMessage struct {
+	Sequence      uint64            `json:"sequence"                gorm:"column:sequence;<-:false"` // allocated by the DB on insert
+	ID            uuid.UUID         `json:"id"                      gorm:"column:id"`
+	Created       tktypes.Timestamp `json:"created"                 gorm:"column:created"`
+	Node          string            `json:"node"                    gorm:"column:node"`
+	Component     string            `json:"component"               gorm:"column:component"`
+	ReplyTo       string            `json:"replyTo"                 gorm:"column:reply_to"`
+	MessageType   string            `json:"messageType"             gorm:"column:msg_type"`
+	CorrelationID *uuid.UUID        `json:"correlationId,omitempty" gorm:"column:correl_id"`
+	Payload       tktypes.HexBytes  `json:"payload"                 gorm:"column:payload"`
+}
+
+type reliableMessageAck struct {
+	ID   uuid.UUID         `json:"id"             gorm:"column:id"`
+	Time tktypes.Timestamp `json:"time,omitempty" gorm:"column:time"`
+}
+
+type receivedReliableMessage struct {
+	ID   uuid.UUID         `gorm:"column:id"`
+	Node string            `gorm:"column:node"`
+	Time tktypes.Timestamp `gorm:"column:time"`
+}
+
+// See docs in components package
+func (tm *transportManager) SendReliable(ctx context.Context, dbTX *gorm.DB, messages ...*components.TransportMessage) (postCommit func(), err error) {
+	nodes := make(map[string]bool)
+	rms := make([]*reliableMessage, len(messages))
+	now := tktypes.TimestampNow()
+	for i, msg := range messages {
+		if err := tm.prepareMessage(ctx, msg); err != nil {
+			return nil, err
+		}
+		if msg.Component == "" {
+			log.L(ctx).Errorf("Invalid reliable message send request %+v", msg)
+			return nil, i18n.NewError(ctx, msgs.MsgTransportInvalidMessage)
+		}
+		rms[i] = &reliableMessage{
+			ID:            msg.MessageID,
+			Created:       now,
+			Node:          msg.Node,
+			Component:     msg.Component,
+			ReplyTo:       msg.ReplyTo,
+			MessageType:   msg.MessageType,
+			CorrelationID: msg.CorrelationID,
+			Payload:       msg.Payload,
+		}
+		nodes[msg.Node] = true
+	}
+
+	if len(rms) > 0 {
+		err = dbTX.
+			WithContext(ctx).
+			Table("reliable_msgs").
+			Create(rms).
+			Error
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return func() {
+		for node := range nodes {
+			tm.wakeSender(node, true)
+		}
+	}, nil
+}
+
+func (tm *transportManager) receiveControlMessage(ctx context.Context, msg *components.TransportMessage) error {
+	switch msg.MessageType {
+	case msgTypeReliable:
+		return tm.receiveReliableMessage(ctx, msg)
+	case msgTypeAck:
+		return tm.receiveAck(ctx, msg)
+	default:
+		return i18n.NewError(ctx, msgs.MsgTransportUnknownControlMessage, msg.MessageType)
+	}
+}
+
+func (tm *transportManager) receiveReliableMessage(ctx context.Context, msg *components.TransportMessage) error {
+	var rm reliableMessage
+	if err := json.Unmarshal(msg.Payload, &rm); err != nil {
+		return i18n.WrapError(ctx, err, msgs.MsgTransportInvalidMessage)
+	}
+	if rm.Node != tm.localNodeName {
+		return i18n.NewError(ctx, msgs.MsgTransportInvalidNodeReceived, rm.Node, tm.localNodeName)
+	}
+	// Duplicates are detected per sending node, so we only accept reliable messages from a node
+	// the transport has authenticated - otherwise one node could suppress another's messages
+	if msg.FromNode == "" {
+		return i18n.NewError(ctx, msgs.MsgTransportSenderNotAuthenticated, rm.ID)
+	}
+	if rm.ReplyTo != msg.FromNode {
+		return i18n.NewError(ctx, msgs.MsgTransportSenderMismatch, rm.ReplyTo, msg.FromNode)
+	}
+
+	receiver, err := tm.getDestination(ctx, rm.Component)
+	if err != nil {
+		// No ack, so the message will be resent - the component might be available after an upgrade
+		return err
+	}
+	reliableReceiver, isReliable := receiver.(components.ReliableTransportClient)
+	if !isReliable {
+		// No ack, so the message will be resent - as above
+		log.L(ctx).Errorf("Reliable message %s (seq=%d) from %s for component %s that does not support reliable messages", rm.ID, rm.Sequence, msg.ReplyTo, rm.Component)
+		return i18n.NewError(ctx, msgs.MsgTransportReliableNotSupported, rm.Component)
+	}
+
+	inner := &components.TransportMessage{
+		MessageID:     rm.ID,
+		CorrelationID: rm.CorrelationID,
+		Component:     rm.Component,
+		Node:          rm.Node,
+		ReplyTo:       rm.ReplyTo,
+		MessageType:   rm.MessageType,
+		Payload:       rm.Payload,
+		TraceContext:  msg.TraceContext,
+	}
+
+	var isNew bool
+	var postCommit func()
+	err = tm.persistence.DB().Transaction(func(dbTX *gorm.DB) (err error) {
+		result := dbTX.
+			WithContext(ctx).
+			Table("reliable_msgs_received").
+			Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&receivedReliableMessage{
+				ID:   rm.ID,
+				Node: msg.FromNode,
+				Time: tktypes.TimestampNow(),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		isNew = result.RowsAffected > 0
+		if isNew {
+			postCommit, err = reliableReceiver.ReceiveReliableTransportMessage(ctx, dbTX, inner)
+		}
+		return err
+	})
+	if err != nil {
+		log.L(ctx).Errorf("Failed to process reliable message %s (seq=%d) from %s: %s", rm.ID, rm.Sequence, msg.ReplyTo, err)
+		return err
+	}
+
+	if !isNew {
+		// The sender did not get our previous ack, so we just ack it again
+		log.L(ctx).Debugf("Duplicate reliable message %s (seq=%d) from %s", rm.ID, rm.Sequence, msg.ReplyTo)
+	} else if postCommit != nil {
+		postCommit()
+	}
+
+	// The ack is not sent reliably, as the sender will resend the message if the ack is lost
+	if err := tm.Send(ctx, &components.TransportMessage{
+		Node:          msg.ReplyTo,
+		Component:     transportManagerDestination,
+		MessageType:   msgTypeAck,
+		CorrelationID: &rm.ID,
+		Payload:       tktypes.JSONString(&reliableMessageAck{ID: rm.ID}),
+	}); err != nil {
+		log.L(ctx).Warnf("Failed to send ack for reliable message %s to %s: %s", rm.ID, msg.ReplyTo, err)
+	}
+	return nil
+}
+
+func (tm *transportManager) receiveAck(ctx context.Context, msg *components.TransportMessage) error {
+	var ack reliableMessageAck
+	if err := json.Unmarshal(msg.Payload, &ack); err != nil {
+		return i18n.WrapError(ctx, err, msgs.MsgTransportInvalidMessage)
+	}
+	ack.Time = tktypes.TimestampNow()
+
+	err := tm.persistence.DB().
+		WithContext(ctx).
+		Table("reliable_msg_acks").
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&ack).
+		Error
+	if err != nil {
+		log.L(ctx).Errorf("Failed to record ack for reliable message %s from %s: %s", ack.ID, msg.ReplyTo, err)
+		return err
+	}
+	log.L(ctx).Debugf("Reliable message %s acknowledged by %s", ack.ID, msg.ReplyTo)
+
+	// Let the sender know, so it can move on to the next messages without waiting
+	tm.wakeSender(msg.ReplyTo, false)
+	return nil
+}
+
+// Removes the acknowledged messages, and the records of received messages, that are older than the
+// retention. Received records must outlive any resend of the message by the peer, or a resend after
+// the record is removed would be processed again.
+func (tm *transportManager) pruneReliableMessages(ctx context.Context) error {
+	cutoff := tktypes.Timestamp(time.Now().Add(-tm.reliableRetention).UnixNano())
+	var sentPruned, receivedPruned int64
+	err := tm.persistence.DB().Transaction(func(dbTX *gorm.DB) error {
+		// The messages must go before their acks, so they are never seen as unacknowledged
+		result := dbTX.
+			WithContext(ctx).
+			Table("reliable_msgs").
+			Where("id IN (?)", dbTX.Table("reliable_msg_acks").Select("id").Where("time < ?", cutoff)).
+			Delete(&reliableMessage{})
+		if result.Error != nil {
+			return result.Error
+		}
+		sentPruned = result.RowsAffected
+		err := dbTX.
+			WithContext(ctx).
+			Table("reliable_msg_acks").
+			Where("time < ?", cutoff).
+			Delete(&reliableMessageAck{}).
+			Error
+		if err != nil {
+			return err
+		}
+		result = dbTX.
+			WithContext(ctx).
+			Table("reliable_msgs_received").
+			Where("time < ?", cutoff).
+			Delete(&receivedReliableMessage{})
+		receivedPruned = result.RowsAffected
+		return result.Error
+	})
+	if err != nil {
+		log.L(ctx).Errorf("Failed to prune reliable messages: %s", err)
+		return err
+	}
+	log.L(ctx).Debugf("Pruned %d acknowledged and %d received reliable messages before %s", sentPruned, receivedPruned, cutoff)
+	return nil
+}
+
+func (tm *transportManager) pruner(ctx context.Context, done chan struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(tm.reliablePruneInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			// Failures are logged, and we try again at the next interval
+			_ = tm.pruneReliableMessages(ctx)
+		case <-ctx.Done():
+			log.L(ctx).Debugf("Reliable message pruner stopping")
+			return
+		}
+	}
+}
@@ -0,0 +1,615 @@
+/*
+ * Copyright © 2024 Kaleido, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package transportmgr
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/kaleido-io/paladin/config/pkg/confutil"
+	"github.com/kaleido-io/paladin/config/pkg/pldconf"
+	"github.com/kaleido-io/paladin/core/internal/components"
+	"github.com/kaleido-io/paladin/core/mocks/componentmocks"
+	"github.com/kaleido-io/paladin/toolkit/pkg/plugintk"
+	"github.com/kaleido-io/paladin/toolkit/pkg/prototk"
+	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+type testReliableClient struct {
+	received  chan *components.TransportMessage
+	committed chan uuid.UUID
+	handler   func(dbTX *gorm.DB, msg *components.TransportMessage) error
+}
+
+func newTestReliableClient() *testReliableClient {
+	return &testReliableClient{
+		received:  make(chan *components.TransportMessage, 10),
+		committed: make(chan uuid.UUID, 10),
+	}
+}
+
+func (rc *testReliableClient) Destination() string {
+	return "someComponent"
+}
+
+func (rc *testReliableClient) ReceiveTransportMessage(ctx context.Context, msg *components.TransportMessage) {
+	panic("unexpected unreliable delivery")
+}
+
+func (rc *testReliableClient) ReceiveReliableTransportMessage(ctx context.Context, dbTX *gorm.DB, msg *components.TransportMessage) (func(), error) {
+	if rc.handler != nil {
+		if err := rc.handler(dbTX, msg); err != nil {
+			return nil, err
+		}
+	}
+	rc.received <- msg
+	return func() { rc.committed <- msg.MessageID }, nil
+}
+
+func newReliableTestTransport(t *testing.T, nodeName, peerName string, client components.TransportClient) (context.Context, *transportManager, *testPlugin, func()) {
+	ctx, tm, _, done := newTestTransportManager(t, &pldconf.TransportManagerConfig{
+		NodeName: nodeName,
+		Transports: map[string]*pldconf.TransportConfig{
+			"test1": {},
+		},
+		ReliableMessaging: pldconf.ReliableMessagingConfig{
+			ResendInterval: confutil.P("50ms"),
+			Retry: pldconf.RetryConfig{
+				InitialDelay: confutil.P("1ms"),
+			},
+		},
+	}, func(mc *mockComponents) components.TransportClient {
+		mc.realDB = true
+		mc.registryManager.On("GetNodeTransports", mock.Anything, peerName).Return([]*components.RegistryNodeTransportEntry{
+			{Node: peerName, Registry: "registry1", Transport: "test1"},
+		}, nil).Maybe()
+		return client
+	})
+
+	tp := newTestPlugin(&plugintk.TransportAPIFunctions{
+		ConfigureTransport: func(ctx context.Context, ctr *prototk.ConfigureTransportRequest) (*prototk.ConfigureTransportResponse, error) {
+			return &prototk.ConfigureTransportResponse{}, nil
+		},
+	})
+	registerTestTransport(t, tm, tp)
+	return ctx, tm, tp, done
+}
+
+// Connects the sending plugin of each transport manager to the receiving callback of the other
+func newReliableTestPair(t *testing.T, client2 components.TransportClient) (context.Context, *transportManager, *testPlugin, *transportManager, *testPlugin, func()) {
+	ctx, tm1, tp1, done1 := newReliableTestTransport(t, "node1", "node2", nil)
+	_, tm2, tp2, done2 := newReliableTestTransport(t, "node2", "node1", client2)
+
+	link := func(to *testPlugin) func(ctx context.Context, req *prototk.SendMessageRequest) (*prototk.SendMessageResponse, error) {
+		return func(ctx context.Context, req *prototk.SendMessageRequest) (*prototk.SendMessageResponse, error) {
+			_, err := to.t.ReceiveMessage(ctx, &prototk.ReceiveMessageRequest{Message: req.Message, FromNode: req.Message.ReplyTo})
+			return &prototk.SendMessageResponse{}, err
+		}
+	}
+	tp1.Functions.SendMessage = link(tp2)
+	tp2.Functions.SendMessage = link(tp1)
+
+	return ctx, tm1, tp1, tm2, tp2, func() {
+		done1()
+		done2()
+	}
+}
+
+func testReliableMessage(i int) *components.TransportMessage {
+	return &components.TransportMessage{
+		Node:          "node2",
+		Component:     "someComponent",
+		CorrelationID: confutil.P(uuid.New()),
+		MessageType:   "myMessageType",
+		Payload:       []byte(fmt.Sprintf("message %d", i)),
+	}
+}
+
+func sendReliable(t *testing.T, ctx context.Context, tm *transportManager, messages ...*components.TransportMessage) func() {
+	var postCommit func()
+	err := tm.persistence.DB().Transaction(func(dbTX *gorm.DB) (err error) {
+		postCommit, err = tm.SendReliable(ctx, dbTX, messages...)
+		return err
+	})
+	require.NoError(t, err)
+	return postCommit
+}
+
+func waitForAcks(t *testing.T, ctx context.Context, tm *transportManager, count int64) {
+	for {
+		var acked int64
+		err := tm.persistence.DB().WithContext(ctx).Table("reliable_msg_acks").Count(&acked).Error
+		require.NoError(t, err)
+		tm.peersLock.Lock()
+		senders := 0
+		for _, p := range tm.peers {
+			if p.senderDone != nil {
+				senders++
+			}
+		}
+		tm.peersLock.Unlock()
+		if acked == count && senders == 0 {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestSendReliableInOrder(t *testing.T) {
+	client := newTestReliableClient()
+	ctx, tm1, _, _, _, done := newReliableTestPair(t, client)
+	defer done()
+
+	sent := []*components.TransportMessage{testReliableMessage(0), testReliableMessage(1), testReliableMessage(2)}
+	sendReliable(t, ctx, tm1, sent...)()
+
+	for _, msg := range sent {
+		received := <-client.received
+		assert.Equal(t, msg.MessageID, received.MessageID)
+		assert.Equal(t, msg.CorrelationID, received.CorrelationID)
+		assert.Equal(t, "node2", received.Node)
+		assert.Equal(t, "node1", received.ReplyTo)
+		assert.Equal(t, "myMessageType", received.MessageType)
+		assert.Equal(t, msg.Payload, received.Payload)
+		assert.Equal(t, msg.MessageID, <-client.committed)
+	}
+
+	waitForAcks(t, ctx, tm1, 3)
+}
+
+func TestSendReliableResendUntilProcessed(t *testing.T) {
+	client := newTestReliableClient()
+	var attempts atomic.Int32
+	client.handler = func(dbTX *gorm.DB, msg *components.TransportMessage) error {
+		if attempts.Add(1) == 1 {
+			return fmt.Errorf("pop")
+		}
+		return nil
+	}
+	ctx, tm1, _, _, _, done := newReliableTestPair(t, client)
+	defer done()
+
+	msg := testReliableMessage(0)
+	sendReliable(t, ctx, tm1, msg)()
+
+	received := <-client.received
+	assert.Equal(t, msg.MessageID, received.MessageID)
+	assert.Equal(t, int32(2), attempts.Load())
+
+	waitForAcks(t, ctx, tm1, 1)
+}
+
+func TestSendReliableRetrySendFailure(t *testing.T) {
+	client := newTestReliableClient()
+	ctx, tm1, tp1, _, tp2, done := newReliableTestPair(t, client)
+	defer done()
+
+	var attempts atomic.Int32
+	tp1.Functions.SendMessage = func(ctx context.Context, req *prototk.SendMessageRequest) (*prototk.SendMessageResponse, error) {
+		if attempts.Add(1) == 1 {
+			return nil, fmt.Errorf("pop")
+		}
+		_, err := tp2.t.ReceiveMessage(ctx, &prototk.ReceiveMessageRequest{Message: req.Message, FromNode: req.Message.ReplyTo})
+		return &prototk.SendMessageResponse{}, err
+	}
+
+	sendReliable(t, ctx, tm1, testReliableMessage(0))()
+	<-client.received
+	waitForAcks(t, ctx, tm1, 1)
+	assert.Equal(t, int32(2), attempts.Load())
+}
+
+func TestSendReliableLostAck(t *testing.T) {
+	client := newTestReliableClient()
+	ctx, tm1, tp1, _, tp2, done := newReliableTestPair(t, client)
+	defer done()
+
+	var acks atomic.Int32
+	tp2.Functions.SendMessage = func(ctx context.Context, req *prototk.SendMessageRequest) (*prototk.SendMessageResponse, error) {
+		if acks.Add(1) == 1 {
+			return &prototk.SendMessageResponse{}, nil // lost on the way
+		}
+		_, err := tp1.t.ReceiveMessage(ctx, &prototk.ReceiveMessageRequest{Message: req.Message, FromNode: req.Message.ReplyTo})
+		return &prototk.SendMessageResponse{}, err
+	}
+
+	sendReliable(t, ctx, tm1, testReliableMessage(0))()
+	<-client.received
+	waitForAcks(t, ctx, tm1, 1)
+
+	// The resend is acked again, but only processed once
+	assert.Equal(t, int32(2), acks.Load())
+	assert.Empty(t, client.received)
+}
+
+func TestSendReliableResumeOnStart(t *testing.T) {
+	client := newTestReliableClient()
+	ctx, tm1, _, _, _, done := newReliableTestPair(t, client)
+	defer done()
+
+	// Queue without calling the post-commit, as if we had stopped
+	msg := testReliableMessage(0)
+	_ = sendReliable(t, ctx, tm1, msg)
+
+	err := tm1.startPeersWithQueuedMessages()
+	require.NoError(t, err)
+
+	received := <-client.received
+	assert.Equal(t, msg.MessageID, received.MessageID)
+	waitForAcks(t, ctx, tm1, 1)
+}
+
+func TestReceiveReliableUnreliableClient(t *testing.T) {
+	client := componentmocks.NewTransportClient(t)
+	client.On("Destination").Return("someComponent")
+	ctx, _, tp, done := newTestTransport(t, func(mc *mockComponents) components.TransportClient {
+		return client
+	})
+	defer done()
+
+	// Not delivered or acknowledged, so the sender keeps the message
+	_, err := tp.t.ReceiveMessage(ctx, &prototk.ReceiveMessageRequest{
+		Message: testControlMessage(msgTypeReliable, tktypes.JSONString(&reliableMessage{
+			ID:        uuid.New(),
+			Node:      "node1",
+			ReplyTo:   "node2",
+			Component: "someComponent",
+		})),
+		FromNode: "node2",
+	})
+	assert.Regexp(t, "PD012015", err)
+}
+
+func TestSendReliableAfterStop(t *testing.T) {
+	_, tm, _, done := newReliableTestTransport(t, "node1", "node2", nil)
+	done()
+
+	tm.wakeSender("node2", true)
+	assert.Empty(t, tm.peers)
+}
+
+func TestSendReliableBadMessages(t *testing.T) {
+	ctx, tm, _, done := newTestTransport(t)
+	defer done()
+
+	_, err := tm.SendReliable(ctx, tm.persistence.DB(), &components.TransportMessage{
+		Node:        "node2",
+		MessageType: "myMessageType",
+		Payload:     []byte("something"),
+	})
+	assert.Regexp(t, "PD012000", err)
+
+	_, err = tm.SendReliable(ctx, tm.persistence.DB(), &components.TransportMessage{
+		Node:        "node2",
+		Component:   "someComponent",
+		MessageType: "myMessageType",
+	})
+	assert.Regexp(t, "PD012000", err)
+}
+
+func TestSendReliableInsertFail(t *testing.T) {
+	var mc *mockComponents
+	ctx, tm, _, done := newTestTransport(t, func(_mc *mockComponents) components.TransportClient {
+		mc = _mc
+		return nil
+	})
+	defer done()
+
+	mc.db.ExpectExec("INSERT.*reliable_msgs").WillReturnError(fmt.Errorf("pop"))
+
+	_, err := tm.SendReliable(ctx, tm.persistence.DB(), testReliableMessage(0))
+	assert.Regexp(t, "pop", err)
+}
+
+func TestStartPeersFail(t *testing.T) {
+	mc := newMockComponents(t)
+	mc.db.ExpectQuery("SELECT DISTINCT.*reliable_msgs").WillReturnError(fmt.Errorf("pop"))
+
+	tm := NewTransportManager(context.Background(), &pldconf.TransportManagerConfig{NodeName: "node1"})
+	err := tm.PostInit(mc.c)
+	require.NoError(t, err)
+	err = tm.Start()
+	assert.Regexp(t, "pop", err)
+}
+
+func testControlMessage(msgType string, payload []byte) *prototk.Message {
+	return &prototk.Message{
+		MessageId:   uuid.NewString(),
+		Node:        "node1",
+		Component:   transportManagerDestination,
+		ReplyTo:     "node2",
+		MessageType: msgType,
+		Payload:     payload,
+	}
+}
+
+func TestReceiveControlMessageErrors(t *testing.T) {
+	ctx, _, tp, done := newTestTransport(t)
+	defer done()
+
+	for _, test := range []struct {
+		msg      *prototk.Message
+		fromNode string
+		error    string
+	}{
+		{msg: testControlMessage("unknown", []byte("{}")), error: "PD012013"},
+		{msg: testControlMessage(msgTypeReliable, []byte("!json")), error: "PD012000"},
+		{msg: testControlMessage(msgTypeAck, []byte("!json")), error: "PD012000"},
+		{msg: testControlMessage(msgTypeReliable, tktypes.JSONString(&reliableMessage{Node: "node3"})), error: "PD012005"},
+		{msg: testControlMessage(msgTypeReliable, tktypes.JSONString(&reliableMessage{Node: "node1", ReplyTo: "node2"})), error: "PD012016"},
+		{msg: testControlMessage(msgTypeReliable, tktypes.JSONString(&reliableMessage{Node: "node1", ReplyTo: "node3"})), fromNode: "node2", error: "PD012017"},
+		{msg: testControlMessage(msgTypeReliable, tktypes.JSONString(&reliableMessage{Node: "node1", ReplyTo: "node2", Component: "unknown"})), fromNode: "node2", error: "PD012011"},
+		{msg: testControlMessage(msgTypeAck, []byte("{}")), fromNode: "node3", error: "PD012017"},
+	} {
+		_, err := tp.t.ReceiveMessage(ctx, &prototk.ReceiveMessageRequest{Message: test.msg, FromNode: test.fromNode})
+		assert.Regexp(t, test.error, err)
+	}
+}
+
+func TestReceiveReliableMessageDBFail(t *testing.T) {
+	var mc *mockComponents
+	ctx, _, tp, done := newTestTransport(t, func(_mc *mockComponents) components.TransportClient {
+		mc = _mc
+		return newTestReliableClient()
+	})
+	defer done()
+
+	mc.db.ExpectBegin()
+	mc.db.ExpectExec("INSERT.*reliable_msgs_received").WillReturnError(fmt.Errorf("pop"))
+	mc.db.ExpectRollback()
+
+	_, err := tp.t.ReceiveMessage(ctx, &prototk.ReceiveMessageRequest{
+		Message: testControlMessage(msgTypeReliable, tktypes.JSONString(&reliableMessage{
+			ID:        uuid.New(),
+			Node:      "node1",
+			ReplyTo:   "node2",
+			Component: "someComponent",
+		})),
+		FromNode: "node2",
+	})
+	assert.Regexp(t, "pop", err)
+}
+
+func TestReceiveReliableMessageAckFail(t *testing.T) {
+	client := newTestReliableClient()
+	ctx, _, tp, done := newReliableTestTransport(t, "node1", "node2", client)
+	defer done()
+
+	// We still process the message if we cannot send the ack
+	tp.Functions.SendMessage = func(ctx context.Context, req *prototk.SendMessageRequest) (*prototk.SendMessageResponse, error) {
+		return nil, fmt.Errorf("pop")
+	}
+
+	msgID := uuid.New()
+	_, err := tp.t.ReceiveMessage(ctx, &prototk.ReceiveMessageRequest{
+		Message: testControlMessage(msgTypeReliable, tktypes.JSONString(&reliableMessage{
+			ID:          msgID,
+			Node:        "node1",
+			ReplyTo:     "node2",
+			Component:   "someComponent",
+			MessageType: "myMessageType",
+			Payload:     []byte("something"),
+		})),
+		FromNode: "node2",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, msgID, (<-client.received).MessageID)
+}
+
+func TestReceiveReliableMessageDuplicatesPerNode(t *testing.T) {
+	client := newTestReliableClient()
+	ctx, tm, _, done := newTestTransportManager(t, &pldconf.TransportManagerConfig{
+		NodeName: "node1",
+		Transports: map[string]*pldconf.TransportConfig{
+			"test1": {},
+		},
+	}, func(mc *mockComponents) components.TransportClient {
+		mc.realDB = true
+		mc.registryManager.On("GetNodeTransports", mock.Anything, mock.Anything).Return(nil, fmt.Errorf("no acks"))
+		return client
+	})
+	defer done()
+	tp := newTestPlugin(&plugintk.TransportAPIFunctions{
+		ConfigureTransport: func(ctx context.Context, ctr *prototk.ConfigureTransportRequest) (*prototk.ConfigureTransportResponse, error) {
+			return &prototk.ConfigureTransportResponse{}, nil
+		},
+	})
+	registerTestTransport(t, tm, tp)
+
+	receive := func(msgID uuid.UUID, fromNode string) {
+		msg := testControlMessage(msgTypeReliable, tktypes.JSONString(&reliableMessage{
+			ID:          msgID,
+			Node:        "node1",
+			ReplyTo:     fromNode,
+			Component:   "someComponent",
+			MessageType: "myMessageType",
+			Payload:     []byte("something"),
+		}))
+		msg.ReplyTo = fromNode
+		_, err := tp.t.ReceiveMessage(ctx, &prototk.ReceiveMessageRequest{Message: msg, FromNode: fromNode})
+		require.NoError(t, err)
+	}
+
+	// The same ID from another node is not a duplicate, so cannot be used to suppress its messages
+	msgID := uuid.New()
+	receive(msgID, "node2")
+	receive(msgID, "node3")
+	receive(msgID, "node2")
+	assert.Equal(t, "node2", (<-client.received).ReplyTo)
+	assert.Equal(t, "node3", (<-client.received).ReplyTo)
+	assert.Empty(t, client.received)
+}
+
+func TestReceiveAckDBFail(t *testing.T) {
+	var mc *mockComponents
+	ctx, _, tp, done := newTestTransport(t, func(_mc *mockComponents) components.TransportClient {
+		mc = _mc
+		return nil
+	})
+	defer done()
+
+	mc.db.ExpectExec("INSERT.*reliable_msg_acks").WillReturnError(fmt.Errorf("pop"))
+
+	_, err := tp.t.ReceiveMessage(ctx, &prototk.ReceiveMessageRequest{
+		Message: testControlMessage(msgTypeAck, tktypes.JSONString(&reliableMessageAck{ID: uuid.New()})),
+	})
+	assert.Regexp(t, "pop", err)
+}
+
+func TestPeerStopWhileSending(t *testing.T) {
+	ctx, tm, tp, done := newReliableTestTransport(t, "node1", "node2", nil)
+	defer done()
+
+	sending := make(chan struct{}, 1)
+	tp.Functions.SendMessage = func(ctx context.Context, req *prototk.SendMessageRequest) (*prototk.SendMessageResponse, error) {
+		select {
+		case sending <- struct{}{}:
+		default:
+		}
+		return nil, fmt.Errorf("pop")
+	}
+
+	sendReliable(t, ctx, tm, testReliableMessage(0))()
+	<-sending
+
+	tm.stopPeers()
+	assert.Empty(t, tm.peers)
+}
+
+func TestSendPageResendBackoff(t *testing.T) {
+	_, tm, tp, done := newReliableTestTransport(t, "node1", "node2", nil)
+	defer done()
+	tm.reliableMaxResendInterval = 200 * time.Millisecond
+
+	var sends atomic.Int32
+	tp.Functions.SendMessage = func(ctx context.Context, req *prototk.SendMessageRequest) (*prototk.SendMessageResponse, error) {
+		sends.Add(1)
+		return &prototk.SendMessageResponse{}, nil
+	}
+
+	p := tm.getPeer("node2")
+	rm := &reliableMessage{ID: uuid.New(), Node: "node2", Component: "someComponent", MessageType: "myMessageType"}
+
+	// The interval doubles each time we resend, up to the maximum
+	for i, expected := range []time.Duration{50, 100, 200, 200} {
+		if i > 0 {
+			p.lastSent[rm.ID].time = time.Now().Add(-time.Hour)
+		}
+		nextResend, err := p.sendPage([]*reliableMessage{rm})
+		require.NoError(t, err)
+		assert.Equal(t, expected*time.Millisecond, nextResend)
+		assert.Equal(t, expected*time.Millisecond, p.lastSent[rm.ID].resendInterval)
+		assert.Equal(t, int32(i+1), sends.Load())
+	}
+
+	// Not due, so not resent
+	nextResend, err := p.sendPage([]*reliableMessage{rm})
+	require.NoError(t, err)
+	assert.LessOrEqual(t, nextResend, 200*time.Millisecond)
+	assert.Equal(t, int32(4), sends.Load())
+}
+
+func countRows(t *testing.T, ctx context.Context, tm *transportManager, table string) int64 {
+	var count int64
+	err := tm.persistence.DB().WithContext(ctx).Table(table).Count(&count).Error
+	require.NoError(t, err)
+	return count
+}
+
+func TestPruneReliableMessages(t *testing.T) {
+	client := newTestReliableClient()
+	ctx, tm1, _, tm2, _, done := newReliableTestPair(t, client)
+	defer done()
+
+	sendReliable(t, ctx, tm1, testReliableMessage(0), testReliableMessage(1))()
+	<-client.received
+	<-client.received
+	waitForAcks(t, ctx, tm1, 2)
+
+	// Within the retention nothing is removed
+	require.NoError(t, tm1.pruneReliableMessages(ctx))
+	require.NoError(t, tm2.pruneReliableMessages(ctx))
+	assert.Equal(t, int64(2), countRows(t, ctx, tm1, "reliable_msgs"))
+	assert.Equal(t, int64(2), countRows(t, ctx, tm2, "reliable_msgs_received"))
+
+	// Queue a message that is not sent, so is never acknowledged
+	_ = sendReliable(t, ctx, tm1, testReliableMessage(2))
+
+	tm1.reliableRetention = 0
+	tm2.reliableRetention = 0
+	require.NoError(t, tm1.pruneReliableMessages(ctx))
+	require.NoError(t, tm2.pruneReliableMessages(ctx))
+	assert.Equal(t, int64(1), countRows(t, ctx, tm1, "reliable_msgs"))
+	assert.Equal(t, int64(0), countRows(t, ctx, tm1, "reliable_msg_acks"))
+	assert.Equal(t, int64(0), countRows(t, ctx, tm2, "reliable_msgs_received"))
+}
+
+func TestPrunerLoop(t *testing.T) {
+	client := newTestReliableClient()
+	ctx, tm1, _, _, _, done := newReliableTestPair(t, client)
+	defer done()
+
+	sendReliable(t, ctx, tm1, testReliableMessage(0))()
+	<-client.received
+	waitForAcks(t, ctx, tm1, 1)
+
+	// Restart the pruner with a short interval
+	tm1.prunerCancel()
+	<-tm1.prunerDone
+	tm1.reliableRetention = 0
+	tm1.reliablePruneInterval = 1 * time.Millisecond
+	prunerCtx, prunerCancel := context.WithCancel(ctx)
+	tm1.prunerCancel, tm1.prunerDone = prunerCancel, make(chan struct{})
+	go tm1.pruner(prunerCtx, tm1.prunerDone)
+
+	for countRows(t, ctx, tm1, "reliable_msgs") > 0 {
+		time.Sleep(1 * time.Millisecond)
+	}
+}
+
+func TestPruneReliableMessagesDBFail(t *testing.T) {
+	for _, failTable := range []string{"reliable_msgs", "reliable_msg_acks", "reliable_msgs_received"} {
+		var mc *mockComponents
+		ctx, tm, _, done := newTestTransport(t, func(_mc *mockComponents) components.TransportClient {
+			mc = _mc
+			return nil
+		})
+
+		mc.db.ExpectBegin()
+		for _, table := range []string{"reliable_msgs", "reliable_msg_acks", "reliable_msgs_received"} {
+			if table == failTable {
+				mc.db.ExpectExec("DELETE FROM .*" + table).WillReturnError(fmt.Errorf("pop"))
+				break
+			}
+			mc.db.ExpectExec("DELETE FROM .*" + table).WillReturnResult(sqlmock.NewResult(0, 0))
+		}
+		mc.db.ExpectRollback()
+
+		err := tm.pruneReliableMessages(ctx)
+		assert.Regexp(t, "pop", err)
+		require.NoError(t, mc.db.ExpectationsWereMet())
+		done()
+	}
+}
@@ -25,10 +25,14 @@ import (
 	"github.com/kaleido-io/paladin/config/pkg/pldconf"
 	"github.com/kaleido-io/paladin/core/internal/components"
 	"github.com/kaleido-io/paladin/core/internal/msgs"
+	"github.com/kaleido-io/paladin/core/internal/tracing"
 
 	"github.com/kaleido-io/paladin/toolkit/pkg/log"
 	"github.com/kaleido-io/paladin/toolkit/pkg/prototk"
 	"github.com/kaleido-io/paladin/toolkit/pkg/retry"
+	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/protobuf/encoding/protojson"
 	"google.golang.org/protobuf/proto"
 )
@@ -102,6 +106,7 @@ func (t *transport) send(ctx context.Context, msg *prototk.Message) error {
 	}
 
 	_, err := t.api.SendMessage(ctx, &prototk.SendMessageRequest{Message: msg})
+	t.tm.metrics.recordSend(t.name, msg.Node, err)
 	if err != nil {
 		return err
 	}
@@ -140,6 +145,11 @@ func (t *transport) ReceiveMessage(ctx context.Context, req *prototk.ReceiveMess
 		return nil, i18n.NewError(ctx, msgs.MsgTransportInvalidNodeReceived, msg.Node, t.tm.localNodeName)
 	}
 
+	// Transports that authenticate the sending node tell us which node it was
+	if req.FromNode != "" && req.FromNode != msg.ReplyTo {
+		return nil, i18n.NewError(ctx, msgs.MsgTransportSenderMismatch, msg.ReplyTo, req.FromNode)
+	}
+
 	msgID, err := uuid.Parse(msg.MessageId)
 	if err != nil {
 		log.L(ctx).Errorf("Invalid messageId from transport: %s", protoToJSON(msg))
@@ -157,20 +167,42 @@ func (t *transport) ReceiveMessage(ctx context.Context, req *prototk.ReceiveMess
 		pCorrelID = &correlID
 	}
 
+	// the receive span is a child of the sender's span, and the trace context passed on to
+	// the component is that of the receive span, so its processing is a child of the receive
+	ctx, span := tracer.Start(tracing.ExtractContext(ctx, msg.TraceContext), "transport.receive",
+		trace.WithSpanKind(trace.SpanKindConsumer), trace.WithAttributes(
+			attribute.String("paladin.node", msg.ReplyTo),
+			attribute.String("paladin.component", msg.Component),
+			attribute.String("paladin.message_type", msg.MessageType),
+		))
+	defer span.End()
+
 	log.L(ctx).Debugf("transport %s message received id=%s (cid=%s)", t.name, msgID, correlIDStr)
+	t.tm.metrics.recordReceive(t.name, msg.ReplyTo)
 	if log.IsTraceEnabled() {
 		log.L(ctx).Tracef("transport %s message received: %s", t.name, protoToJSON(msg))
 	}
 
-	if err = t.deliverMessage(ctx, msg.Component, &components.TransportMessage{
+	tMsg := &components.TransportMessage{
 		MessageID:     msgID,
 		MessageType:   msg.MessageType,
 		Component:     msg.Component,
 		CorrelationID: pCorrelID,
 		Node:          msg.Node,
 		ReplyTo:       msg.ReplyTo,
+		FromNode:      req.FromNode,
 		Payload:       msg.Payload,
-	}); err != nil {
+		TraceContext:  tracing.InjectContext(ctx),
+	}
+	if msg.Component == transportManagerDestination {
+		err = t.tm.receiveControlMessage(ctx, tMsg)
+	} else {
+		err = t.deliverMessage(ctx, msg.Component, tMsg)
+	}
+	if msg.ReplyTo != "" {
+		t.tm.getPeer(msg.ReplyTo).recordReceive(err)
+	}
+	if err != nil {
 		return nil, err
 	}
 
@@ -178,14 +210,10 @@ func (t *transport) ReceiveMessage(ctx context.Context, req *prototk.ReceiveMess
 }
 
 func (t *transport) deliverMessage(ctx context.Context, destIdentity string, msg *components.TransportMessage) error {
-	t.tm.destinationsMux.RLock()
-	defer t.tm.destinationsMux.RUnlock()
-
 	// TODO: Reconcile why we're using the identity as the component routing location - Broadhurst/Hosie discussion required
-	receiver, found := t.tm.destinations[destIdentity]
-	if !found {
-		log.L(ctx).Errorf("Component not found: %s", msg.Component)
-		return i18n.NewError(ctx, msgs.MsgTransportDestinationNotFound, msg.Component)
+	receiver, err := t.tm.getDestination(ctx, destIdentity)
+	if err != nil {
+		return err
 	}
 
 	receiver.ReceiveTransportMessage(ctx, msg)
@@ -223,6 +251,29 @@ func (t *transport) getLocalDetails(ctx context.Context) (string, error) {
 	return res.TransportDetails, nil
 }
 
+// Returns nil if the transport has no connection state to report for the node
+func (t *transport) getPeerInfo(ctx context.Context, node string) (tktypes.RawJSON, error) {
+	if err := t.checkInit(ctx); err != nil {
+		return nil, err
+	}
+	res, err := t.api.GetPeerInfo(ctx, &prototk.GetPeerInfoRequest{Node: node})
+	if err != nil || res.PeerInfoJson == "" {
+		return nil, err
+	}
+	if !json.Valid([]byte(res.PeerInfoJson)) {
+		return nil, i18n.NewError(ctx, msgs.MsgTransportInvalidPeerInfo, t.name)
+	}
+	return tktypes.RawJSON(res.PeerInfoJson), nil
+}
+
+func (t *transport) transportDetailsChanged(ctx context.Context) error {
+	if err := t.checkInit(ctx); err != nil {
+		return err
+	}
+	_, err := t.api.TransportDetailsChanged(ctx, &prototk.TransportDetailsChangedRequest{})
+	return err
+}
+
 func (t *transport) close() {
 	t.cancelCtx()
 	<-t.initDone
@@ -18,6 +18,7 @@ package transportmgr
 import (
 	"context"
 	"fmt"
+	"sync"
 	"sync/atomic"
 	"testing"
 
@@ -25,12 +26,19 @@ import (
 	"github.com/kaleido-io/paladin/config/pkg/confutil"
 	"github.com/kaleido-io/paladin/config/pkg/pldconf"
 	"github.com/kaleido-io/paladin/core/internal/components"
+	"github.com/kaleido-io/paladin/core/internal/tracing"
 	"github.com/kaleido-io/paladin/core/mocks/componentmocks"
 	"github.com/kaleido-io/paladin/toolkit/pkg/plugintk"
 	"github.com/kaleido-io/paladin/toolkit/pkg/prototk"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type testPlugin struct {
@@ -160,6 +168,8 @@ func TestSendMessage(t *testing.T) {
 	require.NoError(t, err)
 
 	<-sentMessages
+
+	assert.Equal(t, float64(1), testutil.ToFloat64(tm.metrics.messagesSent.WithLabelValues("test1", "node2", "success")))
 }
 
 func TestSendMessageReplyToDefaultsToLocalNode(t *testing.T) {
@@ -192,6 +202,58 @@ func TestSendMessageReplyToDefaultsToLocalNode(t *testing.T) {
 	<-sentMessages
 }
 
+// the package tracer binds to the first global provider that is set, so all tests share one exporter
+var testSpanExporter = tracetest.NewInMemoryExporter()
+var testTracingInit sync.Once
+
+func withTestTracing(t *testing.T) *tracetest.InMemoryExporter {
+	testTracingInit.Do(func() {
+		otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(testSpanExporter)))
+	})
+	testSpanExporter.Reset()
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator())
+	})
+	return testSpanExporter
+}
+
+func TestSendMessageTraceContext(t *testing.T) {
+	recorder := withTestTracing(t)
+
+	ctx, tm, tp, done := newTestTransport(t, func(mc *mockComponents) components.TransportClient {
+		mc.registryManager.On("GetNodeTransports", mock.Anything, "node2").Return([]*components.RegistryNodeTransportEntry{
+			{
+				Node:      "node2",
+				Transport: "test1",
+				Details:   `{"likely":"json stuff"}`,
+			},
+		}, nil)
+		return nil
+	})
+	defer done()
+
+	ctx, parent := otel.Tracer("test").Start(ctx, "parent")
+	defer parent.End()
+
+	sentMessages := make(chan *prototk.Message, 1)
+	tp.Functions.SendMessage = func(ctx context.Context, req *prototk.SendMessageRequest) (*prototk.SendMessageResponse, error) {
+		sentMessages <- req.Message
+		return nil, nil
+	}
+
+	err := tm.Send(ctx, testMessage())
+	require.NoError(t, err)
+
+	sent := <-sentMessages
+	assert.Contains(t, sent.TraceContext["traceparent"], parent.SpanContext().TraceID().String())
+
+	ended := recorder.GetSpans()
+	require.Len(t, ended, 1)
+	assert.Equal(t, "transport.send", ended[0].Name)
+	assert.Equal(t, parent.SpanContext().SpanID(), ended[0].Parent.SpanID())
+}
+
 func TestSendMessageNotInit(t *testing.T) {
 	ctx, tm, tp, done := newTestTransport(t, func(mc *mockComponents) components.TransportClient {
 		mc.registryManager.On("GetNodeTransports", mock.Anything, "node2").Return([]*components.RegistryNodeTransportEntry{
@@ -313,7 +375,7 @@ func TestSendInvalidMessageNoPayload(t *testing.T) {
 func TestReceiveMessage(t *testing.T) {
 	receivedMessages := make(chan *components.TransportMessage, 1)
 
-	ctx, _, tp, done := newTestTransport(t, func(mc *mockComponents) components.TransportClient {
+	ctx, tm, tp, done := newTestTransport(t, func(mc *mockComponents) components.TransportClient {
 		receivingClient := componentmocks.NewTransportClient(t)
 		receivingClient.On("Destination").Return("receivingClient1")
 		receivingClient.On("ReceiveTransportMessage", mock.Anything, mock.Anything).Return().Run(func(args mock.Arguments) {
@@ -340,6 +402,52 @@ func TestReceiveMessage(t *testing.T) {
 	assert.NotNil(t, rmr)
 
 	<-receivedMessages
+
+	assert.Equal(t, float64(1), testutil.ToFloat64(tm.metrics.messagesReceived.WithLabelValues("test1", "node2")))
+}
+
+func TestReceiveMessageTraceContext(t *testing.T) {
+	recorder := withTestTracing(t)
+	receivedMessages := make(chan *components.TransportMessage, 1)
+
+	ctx, _, tp, done := newTestTransport(t, func(mc *mockComponents) components.TransportClient {
+		receivingClient := componentmocks.NewTransportClient(t)
+		receivingClient.On("Destination").Return("receivingClient1")
+		receivingClient.On("ReceiveTransportMessage", mock.Anything, mock.Anything).Return().Run(func(args mock.Arguments) {
+			receivedMessages <- args[1].(*components.TransportMessage)
+		})
+		return receivingClient
+	})
+	defer done()
+
+	traceContext := map[string]string{
+		"traceparent": "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01",
+	}
+	_, err := tp.t.ReceiveMessage(ctx, &prototk.ReceiveMessageRequest{
+		Message: &prototk.Message{
+			MessageId:    uuid.NewString(),
+			Node:         "node1",
+			Component:    "receivingClient1",
+			ReplyTo:      "node2",
+			MessageType:  "myMessageType",
+			Payload:      []byte("some data"),
+			TraceContext: traceContext,
+		},
+	})
+	require.NoError(t, err)
+
+	received := <-receivedMessages
+
+	ended := recorder.GetSpans()
+	require.Len(t, ended, 1)
+	assert.Equal(t, "transport.receive", ended[0].Name)
+	assert.Equal(t, "0af7651916cd43dd8448eb211c80319c", ended[0].SpanContext.TraceID().String())
+	assert.Equal(t, "b7ad6b7169203331", ended[0].Parent.SpanID().String())
+
+	// The component receives the context of the receive span, so its spans are children of it
+	handlerSpanCtx := trace.SpanContextFromContext(tracing.ExtractContext(context.Background(), received.TraceContext))
+	assert.Equal(t, ended[0].SpanContext.TraceID(), handlerSpanCtx.TraceID())
+	assert.Equal(t, ended[0].SpanContext.SpanID(), handlerSpanCtx.SpanID())
 }
 
 func TestReceiveMessageNoReceiver(t *testing.T) {
@@ -20,9 +20,13 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/go-resty/resty/v2"
+	"github.com/google/uuid"
 	"github.com/kaleido-io/paladin/config/pkg/confutil"
 	"github.com/kaleido-io/paladin/config/pkg/pldconf"
+	"github.com/kaleido-io/paladin/core/internal/components"
+	"github.com/kaleido-io/paladin/toolkit/pkg/pldapi"
 	"github.com/kaleido-io/paladin/toolkit/pkg/prototk"
 	"github.com/kaleido-io/paladin/toolkit/pkg/rpcclient"
 	"github.com/kaleido-io/paladin/toolkit/pkg/rpcserver"
@@ -60,6 +64,131 @@ func TestRPCLocalDetails(t *testing.T) {
 
 }
 
+func TestRPCPeers(t *testing.T) {
+	client := newTestReliableClient()
+	ctx, tm1, tp1, _, _, done := newReliableTestPair(t, client)
+	defer done()
+
+	rpc, rpcDone := newTestRPCServer(t, ctx, tm1)
+	defer rpcDone()
+
+	var peers []*pldapi.PeerInfo
+	err := rpc.CallRPC(ctx, &peers, "transport_peers")
+	require.NoError(t, err)
+	assert.Empty(t, peers)
+
+	tp1.Functions.GetPeerInfo = func(ctx context.Context, req *prototk.GetPeerInfoRequest) (*prototk.GetPeerInfoResponse, error) {
+		assert.Equal(t, "node2", req.Node)
+		return &prototk.GetPeerInfoResponse{PeerInfoJson: `{"state":"connected"}`}, nil
+	}
+
+	msg := testReliableMessage(0)
+	sendReliable(t, ctx, tm1, msg)()
+	<-client.received
+	waitForAcks(t, ctx, tm1, 1)
+
+	err = rpc.CallRPC(ctx, &peers, "transport_peers")
+	require.NoError(t, err)
+	require.Len(t, peers, 1)
+	assert.Equal(t, "node2", peers[0].Name)
+	assert.NotZero(t, peers[0].Activated)
+	assert.Equal(t, &pldapi.PeerOutbound{Transport: "test1", Registry: "registry1"}, peers[0].Outbound)
+	assert.JSONEq(t, `{"state":"connected"}`, peers[0].Connections["test1"].String())
+	assert.Equal(t, uint64(1), peers[0].Stats.SentMsgs)
+	assert.NotNil(t, peers[0].Stats.LastSend)
+	assert.Equal(t, uint64(1), peers[0].Stats.ReceivedMsgs) // the ack
+	assert.NotNil(t, peers[0].Stats.LastReceive)
+	assert.Zero(t, peers[0].Stats.ReliableQueued)
+	assert.Zero(t, peers[0].Stats.ReliableInFlight)
+	assert.Empty(t, peers[0].Errors)
+
+	var peer *pldapi.PeerInfo
+	err = rpc.CallRPC(ctx, &peer, "transport_peerInfo", "node2")
+	require.NoError(t, err)
+	assert.Equal(t, peers[0], peer)
+
+	err = rpc.CallRPC(ctx, &peer, "transport_peerInfo", "node3")
+	require.NoError(t, err)
+	assert.Nil(t, peer)
+}
+
+func TestRPCPeerInfoErrors(t *testing.T) {
+	ctx, tm, tp, done := newReliableTestTransport(t, "node1", "node2", nil)
+	defer done()
+
+	rpc, rpcDone := newTestRPCServer(t, ctx, tm)
+	defer rpcDone()
+
+	tp.Functions.SendMessage = func(ctx context.Context, req *prototk.SendMessageRequest) (*prototk.SendMessageResponse, error) {
+		return nil, fmt.Errorf("pop")
+	}
+	for i := 0; i < peerRecentErrors+1; i++ {
+		err := tm.Send(ctx, testReliableMessage(i))
+		assert.Regexp(t, "pop", err)
+	}
+
+	// Queued, but cannot be sent
+	sendReliable(t, ctx, tm, testReliableMessage(0))()
+
+	tp.Functions.GetPeerInfo = func(ctx context.Context, req *prototk.GetPeerInfoRequest) (*prototk.GetPeerInfoResponse, error) {
+		return nil, fmt.Errorf("pop")
+	}
+
+	var peer *pldapi.PeerInfo
+	err := rpc.CallRPC(ctx, &peer, "transport_peerInfo", "node2")
+	require.NoError(t, err)
+	assert.Equal(t, "node2", peer.Name)
+	assert.Nil(t, peer.Connections)
+	assert.Zero(t, peer.Stats.SentMsgs)
+	assert.GreaterOrEqual(t, peer.Stats.SendErrors, uint64(peerRecentErrors+1))
+	assert.Equal(t, int64(1), peer.Stats.ReliableQueued)
+	assert.Len(t, peer.Errors, peerRecentErrors)
+	assert.Regexp(t, "pop", peer.Errors[0].Message)
+
+	tp.Functions.GetPeerInfo = func(ctx context.Context, req *prototk.GetPeerInfoRequest) (*prototk.GetPeerInfoResponse, error) {
+		return &prototk.GetPeerInfoResponse{PeerInfoJson: "!json"}, nil
+	}
+	err = rpc.CallRPC(ctx, &peer, "transport_peerInfo", "node2")
+	require.NoError(t, err)
+	assert.Nil(t, peer.Connections)
+}
+
+func TestRPCPeersReceiveError(t *testing.T) {
+	var mc *mockComponents
+	ctx, tm, tp, done := newTestTransport(t, func(_mc *mockComponents) components.TransportClient {
+		mc = _mc
+		return nil
+	})
+	defer done()
+
+	rpc, rpcDone := newTestRPCServer(t, ctx, tm)
+	defer rpcDone()
+
+	_, err := tp.t.ReceiveMessage(ctx, &prototk.ReceiveMessageRequest{
+		Message: &prototk.Message{
+			MessageId:   uuid.NewString(),
+			Node:        "node1",
+			Component:   "unknown",
+			ReplyTo:     "node2",
+			MessageType: "myMessageType",
+			Payload:     []byte("some data"),
+		},
+	})
+	assert.Regexp(t, "PD012011", err)
+
+	mc.db.ExpectQuery("SELECT.*reliable_msgs").WillReturnRows(sqlmock.NewRows([]string{"node", "count"}))
+
+	var peers []*pldapi.PeerInfo
+	err = rpc.CallRPC(ctx, &peers, "transport_peers")
+	require.NoError(t, err)
+	require.Len(t, peers, 1)
+	assert.Equal(t, "node2", peers[0].Name)
+	assert.Nil(t, peers[0].Outbound)
+	assert.Nil(t, peers[0].Connections) // test plugin does not implement GetPeerInfo
+	assert.Equal(t, uint64(1), peers[0].Stats.ReceivedMsgs)
+	assert.Regexp(t, "PD012011", peers[0].Errors[0].Message)
+}
+
 func newTestRPCServer(t *testing.T, ctx context.Context, tm *transportManager) (rpcclient.Client, func()) {
 
 	s, err := rpcserver.NewRPCServer(ctx, &pldconf.RPCServerConfig{
@@ -79,3 +208,28 @@ func newTestRPCServer(t *testing.T, ctx context.Context, tm *transportManager) (
 	return c, s.Stop
 
 }
+
+func TestRPCPeersDBFail(t *testing.T) {
+	var mc *mockComponents
+	ctx, tm, _, done := newTestTransport(t, func(_mc *mockComponents) components.TransportClient {
+		mc = _mc
+		return nil
+	})
+	defer done()
+
+	rpc, rpcDone := newTestRPCServer(t, ctx, tm)
+	defer rpcDone()
+
+	tm.getPeer("node2")
+	mc.db.ExpectQuery("SELECT.*reliable_msgs").WillReturnError(fmt.Errorf("pop"))
+
+	var peers []*pldapi.PeerInfo
+	err := rpc.CallRPC(ctx, &peers, "transport_peers")
+	assert.Regexp(t, "pop", err)
+
+	mc.db.ExpectQuery("SELECT.*reliable_msgs").WillReturnError(fmt.Errorf("pop"))
+
+	var peer *pldapi.PeerInfo
+	err = rpc.CallRPC(ctx, &peer, "transport_peerInfo", "node2")
+	assert.Regexp(t, "pop", err)
+}
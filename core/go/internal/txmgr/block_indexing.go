@@ -66,18 +66,22 @@ func (tm *txManager) blockIndexerPreCommit(
 
 	// Write the receipts themselves - only way of duplicates should be a rewind of
 	// the block explorer, so we simply OnConflict ignore
-	if err := tm.FinalizeTransactions(ctx, dbTX, finalizeInfo); err != nil {
+	finalizeCommit, err := tm.FinalizeTransactions(ctx, dbTX, finalizeInfo)
+	if err != nil {
 		return nil, err
 	}
 
 	// Deliver the failures to the private transaction manager
+	privateFailuresCommit := func() {}
 	if len(failedForPrivateTx) > 0 {
-		if err := tm.privateTxMgr.NotifyFailedPublicTx(ctx, dbTX, failedForPrivateTx); err != nil {
+		if privateFailuresCommit, err = tm.privateTxMgr.NotifyFailedPublicTx(ctx, dbTX, failedForPrivateTx); err != nil {
 			return nil, err
 		}
 	}
 
 	return func() {
+		finalizeCommit()
+		privateFailuresCommit()
 		// We need to notify the public TX manager when the DB transaction for these has completed,
 		// so it can remove any in-memory processing (this is regardless of they were matched to
 		// a public or private transaction)
@@ -87,6 +91,28 @@ func (tm *txManager) blockIndexerPreCommit(
 	}, nil
 }
 
+// Called in the DB transaction that removes the blocks replaced by a chain re-org.
+// Receipts written for transactions in those blocks are deleted, and the public transactions
+// confirmed in them are returned to pending, so that each is tracked to a new receipt on the
+// canonical chain. Receipts already delivered to listeners are not recalled - the new receipt
+// is delivered when the transaction is confirmed again.
+func (tm *txManager) blockIndexerRewind(ctx context.Context, dbTX *gorm.DB, forkBlock int64) (blockindexer.PostCommit, error) {
+	publicRewindCommit, err := tm.publicTxMgr.RewindConfirmedTransactions(ctx, dbTX, forkBlock)
+	if err != nil {
+		return nil, err
+	}
+	result := dbTX.
+		WithContext(ctx).
+		Table("transaction_receipts").
+		Where(`"block_number" > ?`, forkBlock).
+		Delete(&transactionReceipt{})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	log.L(ctx).Warnf("Deleted %d receipts for transactions confirmed after block %d", result.RowsAffected, forkBlock)
+	return publicRewindCommit, nil
+}
+
 func (tm *txManager) mapBlockchainReceipt(pubTx *components.PublicTxMatch) *components.ReceiptInput {
 	receipt := &components.ReceiptInput{
 		TransactionID: pubTx.TransactionID,
@@ -193,7 +193,7 @@ func TestPrivateConfirmMatchPrivateFailures(t *testing.T) {
 		mc.privateTxMgr.On("NotifyFailedPublicTx", mock.Anything, mock.Anything, mock.MatchedBy(func(matches []*components.PublicTxMatch) bool {
 			return len(matches) == 1 &&
 				matches[0].TransactionID == txID2
-		})).Return(nil)
+		})).Return(func() {}, nil)
 
 		mc.publicTxMgr.On("NotifyConfirmPersisted", mock.Anything, mock.MatchedBy(func(matches []*components.PublicTxMatch) bool {
 			return len(matches) == 2 &&
@@ -256,7 +256,7 @@ func TestPrivateConfirmError(t *testing.T) {
 					IndexedTransactionNotify: txi,
 				},
 			}, nil)
-		mc.privateTxMgr.On("NotifyFailedPublicTx", mock.Anything, mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+		mc.privateTxMgr.On("NotifyFailedPublicTx", mock.Anything, mock.Anything, mock.Anything).Return(nil, fmt.Errorf("pop"))
 	})
 	defer done()
 
@@ -290,3 +290,65 @@ func TestConfirmInsertError(t *testing.T) {
 		[]*blockindexer.IndexedTransactionNotify{txi})
 	assert.Regexp(t, "pop", err)
 }
+
+func TestBlockIndexerRewindRealDB(t *testing.T) {
+
+	publicRewound := false
+	ctx, txm, done := newTestTransactionManager(t, true, func(conf *pldconf.TxManagerConfig, mc *mockComponents) {
+		mc.publicTxMgr.On("RewindConfirmedTransactions", mock.Anything, mock.Anything, int64(100)).
+			Return(func() { publicRewound = true }, nil)
+	})
+	defer done()
+
+	txIDBefore := uuid.New()
+	txIDAfter := uuid.New()
+	txIDOffChain := uuid.New()
+	for _, r := range []*transactionReceipt{
+		{TransactionID: txIDBefore, Indexed: tktypes.TimestampNow(), Success: true, BlockNumber: confutil.P(int64(100))},
+		{TransactionID: txIDAfter, Indexed: tktypes.TimestampNow(), Success: true, BlockNumber: confutil.P(int64(101))},
+		{TransactionID: txIDOffChain, Indexed: tktypes.TimestampNow(), FailureMessage: confutil.P("off-chain failure")},
+	} {
+		err := txm.p.DB().Table("transaction_receipts").Create(r).Error
+		require.NoError(t, err)
+	}
+
+	postCommit, err := txm.blockIndexerRewind(ctx, txm.p.DB(), 100)
+	require.NoError(t, err)
+	postCommit()
+	assert.True(t, publicRewound)
+
+	for txID, expected := range map[uuid.UUID]bool{
+		txIDBefore:   true,
+		txIDAfter:    false,
+		txIDOffChain: true,
+	} {
+		receipt, err := txm.GetTransactionReceiptByID(ctx, txID)
+		require.NoError(t, err)
+		assert.Equal(t, expected, receipt != nil, txID)
+	}
+}
+
+func TestBlockIndexerRewindPublicFail(t *testing.T) {
+
+	ctx, txm, done := newTestTransactionManager(t, false, func(conf *pldconf.TxManagerConfig, mc *mockComponents) {
+		mc.publicTxMgr.On("RewindConfirmedTransactions", mock.Anything, mock.Anything, int64(100)).
+			Return(nil, fmt.Errorf("pop"))
+	})
+	defer done()
+
+	_, err := txm.blockIndexerRewind(ctx, txm.p.DB(), 100)
+	assert.Regexp(t, "pop", err)
+}
+
+func TestBlockIndexerRewindDeleteFail(t *testing.T) {
+
+	ctx, txm, done := newTestTransactionManager(t, false, func(conf *pldconf.TxManagerConfig, mc *mockComponents) {
+		mc.publicTxMgr.On("RewindConfirmedTransactions", mock.Anything, mock.Anything, int64(100)).
+			Return(func() {}, nil)
+		mc.db.ExpectExec("DELETE.*transaction_receipts").WillReturnError(fmt.Errorf("pop"))
+	})
+	defer done()
+
+	_, err := txm.blockIndexerRewind(ctx, txm.p.DB(), 100)
+	assert.Regexp(t, "pop", err)
+}
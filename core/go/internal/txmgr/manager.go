@@ -17,8 +17,11 @@ package txmgr
 
 import (
 	"context"
+	"sync"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/kaleido-io/paladin/config/pkg/confutil"
 	"github.com/kaleido-io/paladin/config/pkg/pldconf"
 	"github.com/kaleido-io/paladin/core/internal/components"
 
@@ -26,18 +29,27 @@ import (
 	"github.com/kaleido-io/paladin/core/pkg/persistence"
 	"github.com/kaleido-io/paladin/toolkit/pkg/cache"
 	"github.com/kaleido-io/paladin/toolkit/pkg/pldapi"
+	"github.com/kaleido-io/paladin/toolkit/pkg/retry"
 	"github.com/kaleido-io/paladin/toolkit/pkg/rpcserver"
 	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
 )
 
 func NewTXManager(ctx context.Context, conf *pldconf.TxManagerConfig) components.TXManager {
 	return &txManager{
-		abiCache: cache.NewCache[tktypes.Bytes32, *pldapi.StoredABI](&conf.ABI.Cache, &pldconf.TxManagerDefaults.ABI.Cache),
-		txCache:  cache.NewCache[uuid.UUID, *components.ResolvedTransaction](&conf.Transactions.Cache, &pldconf.TxManagerDefaults.Transactions.Cache),
+		bgCtx:                ctx,
+		abiCache:             cache.NewCache[tktypes.Bytes32, *pldapi.StoredABI](&conf.ABI.Cache, &pldconf.TxManagerDefaults.ABI.Cache),
+		txCache:              cache.NewCache[uuid.UUID, *components.ResolvedTransaction](&conf.Transactions.Cache, &pldconf.TxManagerDefaults.Transactions.Cache),
+		receiptsRetry:        retry.NewRetryIndefinite(&conf.ReceiptListeners.Retry, &pldconf.TxManagerDefaults.ReceiptListeners.Retry),
+		receiptsReadPageSize: confutil.IntMin(conf.ReceiptListeners.ReadPageSize, 1, *pldconf.TxManagerDefaults.ReceiptListeners.ReadPageSize),
+		receiptsPollInterval: confutil.DurationMin(conf.ReceiptListeners.PollInterval, 0, *pldconf.TxManagerDefaults.ReceiptListeners.PollInterval),
+		receiptListeners:     make(map[string]*receiptListener),
+		receiptsNotify:       make(chan struct{}),
+		metrics:              newTXManagerMetrics(),
 	}
 }
 
 type txManager struct {
+	bgCtx            context.Context
 	p                persistence.Persistence
 	localNodeName    string
 	ethClientFactory ethclient.EthClientFactory
@@ -51,6 +63,17 @@ type txManager struct {
 	abiCache         cache.Cache[tktypes.Bytes32, *pldapi.StoredABI]
 	rpcModule        *rpcserver.RPCModule
 	debugRpcModule   *rpcserver.RPCModule
+	rpcEventStreams  *rpcserver.RPCEventSubscriptions
+
+	receiptsRetry        *retry.Retry
+	receiptsReadPageSize int
+	receiptsPollInterval time.Duration
+	receiptListenerLock  sync.Mutex
+	receiptListeners     map[string]*receiptListener
+	receiptsNotifyLock   sync.Mutex
+	receiptsNotify       chan struct{}
+
+	metrics *txManagerMetrics
 }
 
 func (tm *txManager) PostInit(c components.AllComponents) error {
@@ -67,13 +90,25 @@ func (tm *txManager) PostInit(c components.AllComponents) error {
 }
 
 func (tm *txManager) PreInit(c components.PreInitComponents) (*components.ManagerInitResult, error) {
+	if err := tm.metrics.register(c.MetricsManager().Registry()); err != nil {
+		return nil, err
+	}
 	tm.buildRPCModule()
 	return &components.ManagerInitResult{
 		RPCModules:       []*rpcserver.RPCModule{tm.rpcModule, tm.debugRpcModule},
 		PreCommitHandler: tm.blockIndexerPreCommit,
+		RewindHandler:    tm.blockIndexerRewind,
 	}, nil
 }
 
-func (tm *txManager) Start() error { return nil }
+func (tm *txManager) Start() error {
+	if err := tm.receiptListenersInit(tm.bgCtx); err != nil {
+		return err
+	}
+	tm.startReceiptListeners()
+	return nil
+}
 
-func (tm *txManager) Stop() {}
+func (tm *txManager) Stop() {
+	tm.stopReceiptListeners()
+}
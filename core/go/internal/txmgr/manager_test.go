@@ -21,6 +21,7 @@ import (
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/kaleido-io/paladin/config/pkg/pldconf"
+	"github.com/kaleido-io/paladin/core/internal/metrics"
 	"github.com/kaleido-io/paladin/core/mocks/componentmocks"
 	"github.com/kaleido-io/paladin/core/mocks/ethclientmocks"
 	"github.com/stretchr/testify/assert"
@@ -73,6 +74,7 @@ func newTestTransactionManager(t *testing.T, realDB bool, init ...func(conf *pld
 	componentMocks.On("IdentityResolver").Return(mc.identityResolver).Maybe()
 	componentMocks.On("EthClientFactory").Return(mc.ethClientFactory).Maybe()
 	componentMocks.On("TransportManager").Return(mc.transportManager).Maybe()
+	componentMocks.On("MetricsManager").Return(metrics.NewMetricsManager(ctx)).Maybe()
 	mc.transportManager.On("LocalNodeName").Return("node1").Maybe()
 
 	var p persistence.Persistence
@@ -86,6 +88,8 @@ func newTestTransactionManager(t *testing.T, realDB bool, init ...func(conf *pld
 		require.NoError(t, err)
 		p = mp.P
 		mc.db = mp.Mock
+		// Receipt listeners are loaded on start
+		mc.db.ExpectQuery("SELECT.*receipt_listeners").WillReturnRows(sqlmock.NewRows([]string{}))
 		pDone = func() {
 			require.NoError(t, mp.Mock.ExpectationsWereMet())
 		}
@@ -0,0 +1,76 @@
+/*
+ * Copyright © 2024 Kaleido, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package txmgr
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kaleido-io/paladin/core/internal/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type txManagerMetrics struct {
+	submitted  *prometheus.CounterVec
+	completed  *prometheus.CounterVec
+	completion *prometheus.HistogramVec
+}
+
+func newTXManagerMetrics() *txManagerMetrics {
+	return &txManagerMetrics{
+		submitted: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: "tx",
+			Name:      "submitted_total",
+			Help:      "Number of transactions submitted to this node",
+		}, []string{"type"}),
+		completed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: "tx",
+			Name:      "completed_total",
+			Help:      "Number of transaction receipts written by this node",
+		}, []string{"type", "result"}),
+		completion: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: "tx",
+			Name:      "completion_seconds",
+			Help:      "Time from submission to receipt, for transactions still held in the transaction cache",
+			Buckets:   prometheus.ExponentialBuckets(0.1, 2, 12),
+		}, []string{"type"}),
+	}
+}
+
+func (m *txManagerMetrics) register(r prometheus.Registerer) error {
+	for _, c := range []prometheus.Collector{m.submitted, m.completed, m.completion} {
+		if err := r.Register(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (tm *txManager) recordReceiptMetrics(txType string, txID uuid.UUID, success bool) {
+	result := "success"
+	if !success {
+		result = "fail"
+	}
+	tm.metrics.completed.WithLabelValues(txType, result).Inc()
+	// We do not query the DB to find the creation time, but we will have it in the cache
+	// for any transaction that has been processed through to completion recently
+	if tx, _ := tm.txCache.Get(txID); tx != nil && tx.Transaction.Created != 0 {
+		tm.metrics.completion.WithLabelValues(txType).Observe(time.Since(tx.Transaction.Created.Time()).Seconds())
+	}
+}
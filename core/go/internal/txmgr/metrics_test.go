@@ -0,0 +1,63 @@
+/*
+ * Copyright © 2024 Kaleido, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package txmgr
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kaleido-io/paladin/config/pkg/pldconf"
+	"github.com/kaleido-io/paladin/core/internal/components"
+	"github.com/kaleido-io/paladin/core/internal/metrics"
+	"github.com/kaleido-io/paladin/core/mocks/componentmocks"
+	"github.com/kaleido-io/paladin/toolkit/pkg/pldapi"
+	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordReceiptMetrics(t *testing.T) {
+	txm := NewTXManager(context.Background(), &pldconf.TxManagerConfig{}).(*txManager)
+
+	cachedTxID := uuid.New()
+	txm.txCache.Set(cachedTxID, &components.ResolvedTransaction{
+		Transaction: &pldapi.Transaction{
+			ID:      &cachedTxID,
+			Created: tktypes.Timestamp(time.Now().Add(-1 * time.Second).UnixNano()),
+		},
+	})
+
+	txm.recordReceiptMetrics("private", cachedTxID, true)
+	txm.recordReceiptMetrics("private", uuid.New(), true)
+
+	assert.Equal(t, float64(2), testutil.ToFloat64(txm.metrics.completed.WithLabelValues("private", "success")))
+	// Only the cached transaction has a known submission time
+	assert.Equal(t, 1, testutil.CollectAndCount(txm.metrics.completion))
+}
+
+func TestPreInitMetricsError(t *testing.T) {
+	mm := metrics.NewMetricsManager(context.Background())
+	err := newTXManagerMetrics().register(mm.Registry())
+	require.NoError(t, err)
+
+	mc := componentmocks.NewPreInitComponents(t)
+	mc.On("MetricsManager").Return(mm)
+	_, err = NewTXManager(context.Background(), &pldconf.TxManagerConfig{}).PreInit(mc)
+	assert.Regexp(t, "duplicate metrics collector registration", err)
+}
@@ -34,7 +34,11 @@ import (
 	"gorm.io/gorm/clause"
 )
 
+// Key of the PostgreSQL advisory lock held from inserting receipts until the DB transaction commits
+const receiptSequenceLockID int64 = 0x506c6452637074 // "PldRcpt"
+
 type transactionReceipt struct {
+	Sequence         uint64              `gorm:"column:sequence;<-:false"` // allocated by the DB on insert
 	TransactionID    uuid.UUID           `gorm:"column:transaction"`
 	Indexed          tktypes.Timestamp   `gorm:"column:indexed"`
 	Domain           string              `gorm:"column:domain"`
@@ -80,14 +84,17 @@ var transactionReceiptFilters = filters.FieldMap{
 	"success":         filters.BooleanField("success"),
 	"transactionHash": filters.HexBytesField("tx_hash"),
 	"blockNumber":     filters.Int64Field("block_number"),
+	"sequence":        filters.Int64Field("sequence"),
 }
 
 // FinalizeTransactions is called by the block indexing routine, but also can be called
-// by the private transaction manager if transactions fail without making it to the blockchain
-func (tm *txManager) FinalizeTransactions(ctx context.Context, dbTX *gorm.DB, info []*components.ReceiptInput) error {
+// by the private transaction manager if transactions fail without making it to the blockchain.
+// The returned postCommit function must be called once the DB transaction commits, to record
+// the receipt metrics and notify receipt listeners.
+func (tm *txManager) FinalizeTransactions(ctx context.Context, dbTX *gorm.DB, info []*components.ReceiptInput) (func(), error) {
 
 	if len(info) == 0 {
-		return nil
+		return func() {}, nil
 	}
 
 	receiptsToInsert := make([]*transactionReceipt, 0, len(info))
@@ -110,19 +117,19 @@ func (tm *txManager) FinalizeTransactions(ctx context.Context, dbTX *gorm.DB, in
 		switch ri.ReceiptType {
 		case components.RT_Success:
 			if ri.FailureMessage != "" || ri.RevertData != nil {
-				return i18n.NewError(ctx, msgs.MsgTxMgrInvalidReceiptNotification, tktypes.JSONString(ri))
+				return nil, i18n.NewError(ctx, msgs.MsgTxMgrInvalidReceiptNotification, tktypes.JSONString(ri))
 			}
 			receipt.Success = true
 		case components.RT_FailedWithMessage:
 			if ri.FailureMessage == "" || ri.RevertData != nil {
-				return i18n.NewError(ctx, msgs.MsgTxMgrInvalidReceiptNotification, tktypes.JSONString(ri))
+				return nil, i18n.NewError(ctx, msgs.MsgTxMgrInvalidReceiptNotification, tktypes.JSONString(ri))
 			}
 			receipt.Success = false
 			failureMsg = ri.FailureMessage
 			receipt.FailureMessage = &ri.FailureMessage
 		case components.RT_FailedOnChainWithRevertData:
 			if ri.FailureMessage != "" {
-				return i18n.NewError(ctx, msgs.MsgTxMgrInvalidReceiptNotification, tktypes.JSONString(ri))
+				return nil, i18n.NewError(ctx, msgs.MsgTxMgrInvalidReceiptNotification, tktypes.JSONString(ri))
 			}
 			receipt.Success = false
 			receipt.RevertData = ri.RevertData
@@ -130,13 +137,23 @@ func (tm *txManager) FinalizeTransactions(ctx context.Context, dbTX *gorm.DB, in
 			failureMsg = tm.CalculateRevertError(ctx, dbTX, ri.RevertData).Error()
 			receipt.FailureMessage = &failureMsg
 		default:
-			return i18n.NewError(ctx, msgs.MsgTxMgrInvalidReceiptNotification, tktypes.JSONString(ri))
+			return nil, i18n.NewError(ctx, msgs.MsgTxMgrInvalidReceiptNotification, tktypes.JSONString(ri))
 		}
 		log.L(ctx).Infof("Inserting receipt txId=%s success=%t failure=%s txHash=%v", receipt.TransactionID, receipt.Success, failureMsg, receipt.TransactionHash)
 		receiptsToInsert = append(receiptsToInsert, receipt)
 	}
 
 	if len(receiptsToInsert) > 0 {
+		// Receipt listeners rely on sequence numbers becoming visible in the order they are allocated,
+		// so on PostgreSQL only one transaction at a time can be between allocating receipt sequence
+		// numbers and committing. A transaction scoped advisory lock is taken for this, immediately
+		// before the insert, so readers, other writers of the table, and autovacuum are not blocked
+		// as they would be by a table lock. SQLite only allows a single writer, so does not need this.
+		if dbTX.Dialector.Name() == "postgres" {
+			if err := dbTX.Exec("SELECT pg_advisory_xact_lock(?)", receiptSequenceLockID).Error; err != nil {
+				return nil, err
+			}
+		}
 		err := dbTX.Table("transaction_receipts").
 			Clauses(clause.OnConflict{
 				Columns:   []clause.Column{{Name: "transaction"}},
@@ -145,14 +162,20 @@ func (tm *txManager) FinalizeTransactions(ctx context.Context, dbTX *gorm.DB, in
 			Create(receiptsToInsert).
 			Error
 		if err != nil {
-			return err
+			return nil, err
 		}
 	}
 
-	// TODO: Need to create an guaranteed increasing event table for these receipts, as applications
-	//       must be able to efficiently and reliably listen for them as they are written (good or bad)
-
-	return nil
+	return func() {
+		for _, receipt := range receiptsToInsert {
+			txType := pldapi.TransactionTypePublic
+			if receipt.Domain != "" {
+				txType = pldapi.TransactionTypePrivate
+			}
+			tm.recordReceiptMetrics(string(txType), receipt.TransactionID, receipt.Success)
+		}
+		tm.notifyNewReceipts()
+	}, nil
 }
 
 func (tm *txManager) CalculateRevertError(ctx context.Context, dbTX *gorm.DB, revertData tktypes.HexBytes) error {
@@ -310,6 +333,7 @@ func (tm *txManager) QueryTransactionReceipts(ctx context.Context, jq *query.Que
 		mapResult: func(pt *transactionReceipt) (*pldapi.TransactionReceipt, error) {
 			return &pldapi.TransactionReceipt{
 				ID:                     pt.TransactionID,
+				Sequence:               pt.Sequence,
 				TransactionReceiptData: *mapPersistedReceipt(pt),
 			}, nil
 		},
@@ -330,17 +354,22 @@ func (tm *txManager) GetTransactionReceiptByIDFull(ctx context.Context, id uuid.
 	if err != nil || receipt == nil {
 		return nil, err
 	}
-	fullReceipt := &pldapi.TransactionReceiptFull{TransactionReceipt: receipt}
+	return tm.buildFullReceipt(ctx, receipt)
+}
+
+func (tm *txManager) buildFullReceipt(ctx context.Context, receipt *pldapi.TransactionReceipt) (fullReceipt *pldapi.TransactionReceiptFull, err error) {
+	fullReceipt = &pldapi.TransactionReceiptFull{TransactionReceipt: receipt}
 	if receipt.Domain != "" {
-		fullReceipt.States, err = tm.stateMgr.GetTransactionStates(ctx, tm.p.DB(), id)
-		if err == nil {
-			d, domainErr := tm.domainMgr.GetDomainByName(ctx, receipt.Domain)
-			if domainErr == nil {
-				fullReceipt.DomainReceipt, domainErr = d.BuildDomainReceipt(ctx, tm.p.DB(), id, fullReceipt.States)
-			}
-			if domainErr != nil {
-				fullReceipt.DomainReceiptError = domainErr.Error()
-			}
+		fullReceipt.States, err = tm.stateMgr.GetTransactionStates(ctx, tm.p.DB(), receipt.ID)
+		if err != nil {
+			return nil, err
+		}
+		d, domainErr := tm.domainMgr.GetDomainByName(ctx, receipt.Domain)
+		if domainErr == nil {
+			fullReceipt.DomainReceipt, domainErr = d.BuildDomainReceipt(ctx, tm.p.DB(), receipt.ID, fullReceipt.States)
+		}
+		if domainErr != nil {
+			fullReceipt.DomainReceiptError = domainErr.Error()
 		}
 	}
 	return fullReceipt, nil
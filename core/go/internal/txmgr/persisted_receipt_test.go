@@ -28,6 +28,7 @@ import (
 	"github.com/kaleido-io/paladin/toolkit/pkg/pldapi"
 	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
 
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -39,7 +40,7 @@ func TestFinalizeTransactionsNoOp(t *testing.T) {
 	ctx, txm, done := newTestTransactionManager(t, false)
 	defer done()
 
-	err := txm.FinalizeTransactions(ctx, txm.p.DB(), nil)
+	_, err := txm.FinalizeTransactions(ctx, txm.p.DB(), nil)
 	assert.NoError(t, err)
 
 }
@@ -50,7 +51,7 @@ func TestFinalizeTransactionsSuccessWithFailure(t *testing.T) {
 	ctx, txm, done := newTestTransactionManager(t, false)
 	defer done()
 
-	err := txm.FinalizeTransactions(ctx, txm.p.DB(), []*components.ReceiptInput{
+	_, err := txm.FinalizeTransactions(ctx, txm.p.DB(), []*components.ReceiptInput{
 		{TransactionID: txID, ReceiptType: components.RT_Success,
 			FailureMessage: "not empty",
 		},
@@ -64,7 +65,7 @@ func TestFinalizeTransactionsBadType(t *testing.T) {
 	ctx, txm, done := newTestTransactionManager(t, false)
 	defer done()
 
-	err := txm.FinalizeTransactions(ctx, txm.p.DB(), []*components.ReceiptInput{
+	_, err := txm.FinalizeTransactions(ctx, txm.p.DB(), []*components.ReceiptInput{
 		{TransactionID: txID, ReceiptType: components.ReceiptType(42)}})
 	assert.Regexp(t, "PD012213", err)
 
@@ -76,7 +77,7 @@ func TestFinalizeTransactionsFailedWithMessageNoMessage(t *testing.T) {
 	ctx, txm, done := newTestTransactionManager(t, false)
 	defer done()
 
-	err := txm.FinalizeTransactions(ctx, txm.p.DB(), []*components.ReceiptInput{
+	_, err := txm.FinalizeTransactions(ctx, txm.p.DB(), []*components.ReceiptInput{
 		{TransactionID: txID, ReceiptType: components.RT_FailedWithMessage}})
 	assert.Regexp(t, "PD012213", err)
 
@@ -88,7 +89,7 @@ func TestFinalizeTransactionsFailedWithRevertDataWithMessage(t *testing.T) {
 	ctx, txm, done := newTestTransactionManager(t, false)
 	defer done()
 
-	err := txm.FinalizeTransactions(ctx, txm.p.DB(), []*components.ReceiptInput{
+	_, err := txm.FinalizeTransactions(ctx, txm.p.DB(), []*components.ReceiptInput{
 		{TransactionID: txID, ReceiptType: components.RT_FailedOnChainWithRevertData,
 			FailureMessage: "not empty"}})
 	assert.Regexp(t, "PD012213", err)
@@ -104,11 +105,12 @@ func TestFinalizeTransactionsInsertFail(t *testing.T) {
 	})
 	defer done()
 
-	err := txm.p.DB().Transaction(func(tx *gorm.DB) error {
-		return txm.FinalizeTransactions(ctx, tx, []*components.ReceiptInput{
+	err := txm.p.DB().Transaction(func(tx *gorm.DB) (err error) {
+		_, err = txm.FinalizeTransactions(ctx, tx, []*components.ReceiptInput{
 			{TransactionID: txID, ReceiptType: components.RT_FailedWithMessage,
 				FailureMessage: "something went wrong"},
 		})
+		return err
 	})
 	assert.Regexp(t, "pop", err)
 
@@ -192,24 +194,34 @@ func TestFinalizeTransactionsInsertOkOffChain(t *testing.T) {
 	})
 	require.NoError(t, err)
 
-	err = txm.p.DB().Transaction(func(tx *gorm.DB) error {
-		return txm.FinalizeTransactions(ctx, tx, []*components.ReceiptInput{
+	var postCommit func()
+	err = txm.p.DB().Transaction(func(tx *gorm.DB) (err error) {
+		postCommit, err = txm.FinalizeTransactions(ctx, tx, []*components.ReceiptInput{
 			{
 				TransactionID: *txID,
 				ReceiptType:   components.RT_FailedOnChainWithRevertData,
 			},
 		})
+		return err
 	})
 	require.NoError(t, err)
 
+	// Metrics are only recorded once the DB transaction has committed
+	assert.Zero(t, testutil.ToFloat64(txm.metrics.completed.WithLabelValues("public", "fail")))
+	postCommit()
+
 	receipt, err := txm.GetTransactionReceiptByID(ctx, *txID)
 	require.NoError(t, err)
 	require.NotNil(t, receipt)
 	require.JSONEq(t, fmt.Sprintf(`{
 		"id":"%s",
+		"sequence":1,
 		"failureMessage":"PD012214: Unable to decode revert data (no revert data available)"
 	}`, txID), string(tktypes.JSONString(receipt)))
 
+	assert.Equal(t, float64(1), testutil.ToFloat64(txm.metrics.submitted.WithLabelValues("private")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(txm.metrics.completed.WithLabelValues("public", "fail")))
+
 }
 
 func TestFinalizeTransactionsInsertOkEvent(t *testing.T) {
@@ -244,8 +256,9 @@ func TestFinalizeTransactionsInsertOkEvent(t *testing.T) {
 	})
 	assert.NoError(t, err)
 
-	err = txm.p.DB().Transaction(func(tx *gorm.DB) error {
-		return txm.FinalizeTransactions(ctx, tx, []*components.ReceiptInput{
+	var postCommit func()
+	err = txm.p.DB().Transaction(func(tx *gorm.DB) (err error) {
+		postCommit, err = txm.FinalizeTransactions(ctx, tx, []*components.ReceiptInput{
 			{
 				TransactionID: *txID,
 				Domain:        "domain1",
@@ -260,8 +273,10 @@ func TestFinalizeTransactionsInsertOkEvent(t *testing.T) {
 				},
 			},
 		})
+		return err
 	})
 	require.NoError(t, err)
+	postCommit()
 
 	receipt, err := txm.GetTransactionReceiptByIDFull(ctx, *txID)
 	require.NoError(t, err)
@@ -269,6 +284,7 @@ func TestFinalizeTransactionsInsertOkEvent(t *testing.T) {
 	require.NotNil(t, receipt)
 	require.JSONEq(t, fmt.Sprintf(`{
 		"id":"%s",
+		"sequence":1,
 		"domain": "domain1",
 		"blockNumber":12345, 
 		"logIndex":5,
@@ -0,0 +1,500 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package txmgr
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/kaleido-io/paladin/config/pkg/confutil"
+	"github.com/kaleido-io/paladin/core/internal/components"
+	"github.com/kaleido-io/paladin/core/internal/filters"
+	"github.com/kaleido-io/paladin/core/internal/msgs"
+	"github.com/kaleido-io/paladin/toolkit/pkg/log"
+	"github.com/kaleido-io/paladin/toolkit/pkg/pldapi"
+	"github.com/kaleido-io/paladin/toolkit/pkg/query"
+	"github.com/kaleido-io/paladin/toolkit/pkg/rpcserver"
+	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type persistedReceiptListener struct {
+	Name    string            `gorm:"column:name"`
+	Created tktypes.Timestamp `gorm:"column:created"`
+	Started *bool             `gorm:"column:started"`
+	Filters tktypes.RawJSON   `gorm:"column:filters"`
+	Options tktypes.RawJSON   `gorm:"column:options"`
+}
+
+type persistedReceiptCheckpoint struct {
+	Listener string            `gorm:"column:listener"`
+	Sequence uint64            `gorm:"column:sequence"`
+	Time     tktypes.Timestamp `gorm:"column:time"`
+}
+
+var receiptListenerFilters = filters.FieldMap{
+	"name":    filters.StringField("name"),
+	"created": filters.TimestampField("created"),
+	"started": filters.BooleanField("started"),
+}
+
+// Receipt listeners are held in memory, and each started listener has a single go routine
+// that reads pages of receipts in sequence order from the DB, delivers them to one of the
+// attached receivers, and checkpoints after each batch is acknowledged.
+type receiptListener struct {
+	tm   *txManager
+	spec *pldapi.TransactionReceiptListener
+
+	ctx       context.Context
+	cancelCtx context.CancelFunc
+	done      chan struct{}
+
+	receivers   *rpcserver.RPCEventReceivers[components.ReceiptReceiver]
+	nextBatchID uint64
+}
+
+type receiptDeliveryBatch struct {
+	ID       uint64
+	Receipts []*pldapi.TransactionReceiptFull
+}
+
+func (tm *txManager) receiptListenersInit(ctx context.Context) error {
+	var pls []*persistedReceiptListener
+	err := tm.p.DB().
+		WithContext(ctx).
+		Table("receipt_listeners").
+		Find(&pls).
+		Error
+	if err != nil {
+		return err
+	}
+
+	tm.receiptListenerLock.Lock()
+	defer tm.receiptListenerLock.Unlock()
+	for _, pl := range pls {
+		l, err := tm.buildListenerFromDB(ctx, pl)
+		if err != nil {
+			return err
+		}
+		tm.receiptListeners[l.spec.Name] = l
+	}
+	return nil
+}
+
+func (tm *txManager) startReceiptListeners() {
+	tm.receiptListenerLock.Lock()
+	defer tm.receiptListenerLock.Unlock()
+	for _, l := range tm.receiptListeners {
+		if *l.spec.Started {
+			l.start()
+		}
+	}
+}
+
+func (tm *txManager) stopReceiptListeners() {
+	tm.receiptListenerLock.Lock()
+	defer tm.receiptListenerLock.Unlock()
+	for _, l := range tm.receiptListeners {
+		l.stop()
+	}
+}
+
+// Called when receipts are written, so that listeners do not need to wait for the poll interval.
+// Note this is called before the DB transaction commits, so the poll interval remains
+// a safety net for the case where a listener runs its query before the commit.
+func (tm *txManager) notifyNewReceipts() {
+	tm.receiptsNotifyLock.Lock()
+	defer tm.receiptsNotifyLock.Unlock()
+	close(tm.receiptsNotify)
+	tm.receiptsNotify = make(chan struct{})
+}
+
+func (tm *txManager) receiptsNotifier() <-chan struct{} {
+	tm.receiptsNotifyLock.Lock()
+	defer tm.receiptsNotifyLock.Unlock()
+	return tm.receiptsNotify
+}
+
+func (tm *txManager) buildListenerFromDB(ctx context.Context, pl *persistedReceiptListener) (*receiptListener, error) {
+	spec := &pldapi.TransactionReceiptListener{
+		Name:    pl.Name,
+		Created: pl.Created,
+		Started: pl.Started,
+	}
+	if err := json.Unmarshal(pl.Filters, &spec.Filters); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(pl.Options, &spec.Options); err != nil {
+		return nil, err
+	}
+	return tm.newReceiptListener(spec), nil
+}
+
+func (tm *txManager) newReceiptListener(spec *pldapi.TransactionReceiptListener) *receiptListener {
+	return &receiptListener{
+		tm:        tm,
+		spec:      spec,
+		receivers: rpcserver.NewRPCEventReceivers[components.ReceiptReceiver](),
+	}
+}
+
+func (tm *txManager) CreateReceiptListener(ctx context.Context, spec *pldapi.TransactionReceiptListener) error {
+
+	if err := tktypes.ValidateSafeCharsStartEndAlphaNum(ctx, spec.Name, tktypes.DefaultNameMaxLen, "name"); err != nil {
+		return err
+	}
+	if spec.Filters.Type != nil {
+		txType, err := spec.Filters.Type.Validate()
+		if err != nil {
+			return err
+		}
+		if spec.Filters.Domain != "" && txType != pldapi.TransactionTypePrivate {
+			return i18n.NewError(ctx, msgs.MsgTxMgrReceiptListenerDomainFilter)
+		}
+	}
+	if spec.Started == nil {
+		spec.Started = confutil.P(true)
+	}
+	spec.Created = tktypes.TimestampNow()
+
+	tm.receiptListenerLock.Lock()
+	defer tm.receiptListenerLock.Unlock()
+	if tm.receiptListeners[spec.Name] != nil {
+		return i18n.NewError(ctx, msgs.MsgTxMgrReceiptListenerExists, spec.Name)
+	}
+
+	err := tm.p.DB().
+		WithContext(ctx).
+		Table("receipt_listeners").
+		Create(&persistedReceiptListener{
+			Name:    spec.Name,
+			Created: spec.Created,
+			Started: spec.Started,
+			Filters: tktypes.JSONString(&spec.Filters),
+			Options: tktypes.JSONString(&spec.Options),
+		}).
+		Error
+	if err != nil {
+		return err
+	}
+
+	l := tm.newReceiptListener(spec)
+	tm.receiptListeners[spec.Name] = l
+	if *spec.Started {
+		l.start()
+	}
+	return nil
+}
+
+func (tm *txManager) GetReceiptListener(ctx context.Context, name string) *pldapi.TransactionReceiptListener {
+	tm.receiptListenerLock.Lock()
+	defer tm.receiptListenerLock.Unlock()
+
+	l := tm.receiptListeners[name]
+	if l == nil {
+		return nil
+	}
+	spec := *l.spec
+	return &spec
+}
+
+func (tm *txManager) QueryReceiptListeners(ctx context.Context, dbTX *gorm.DB, jq *query.QueryJSON) ([]*pldapi.TransactionReceiptListener, error) {
+	qw := &queryWrapper[persistedReceiptListener, pldapi.TransactionReceiptListener]{
+		p:           tm.p,
+		table:       "receipt_listeners",
+		defaultSort: "-created",
+		filters:     receiptListenerFilters,
+		query:       jq,
+		mapResult: func(pl *persistedReceiptListener) (*pldapi.TransactionReceiptListener, error) {
+			l, err := tm.buildListenerFromDB(ctx, pl)
+			if err != nil {
+				return nil, err
+			}
+			return l.spec, nil
+		},
+	}
+	return qw.run(ctx, dbTX)
+}
+
+func (tm *txManager) getReceiptListener(ctx context.Context, name string) (*receiptListener, error) {
+	l := tm.receiptListeners[name]
+	if l == nil {
+		return nil, i18n.NewError(ctx, msgs.MsgTxMgrReceiptListenerNotFound, name)
+	}
+	return l, nil
+}
+
+func (tm *txManager) setReceiptListenerStarted(ctx context.Context, name string, started bool) error {
+	tm.receiptListenerLock.Lock()
+	defer tm.receiptListenerLock.Unlock()
+
+	l, err := tm.getReceiptListener(ctx, name)
+	if err != nil {
+		return err
+	}
+	err = tm.p.DB().
+		WithContext(ctx).
+		Table("receipt_listeners").
+		Where("name = ?", name).
+		Update("started", started).
+		Error
+	if err != nil {
+		return err
+	}
+	l.spec.Started = &started
+	if started {
+		l.start()
+	} else {
+		l.stop()
+	}
+	return nil
+}
+
+func (tm *txManager) StartReceiptListener(ctx context.Context, name string) error {
+	return tm.setReceiptListenerStarted(ctx, name, true)
+}
+
+func (tm *txManager) StopReceiptListener(ctx context.Context, name string) error {
+	return tm.setReceiptListenerStarted(ctx, name, false)
+}
+
+func (tm *txManager) DeleteReceiptListener(ctx context.Context, name string) error {
+	tm.receiptListenerLock.Lock()
+	defer tm.receiptListenerLock.Unlock()
+
+	l, err := tm.getReceiptListener(ctx, name)
+	if err != nil {
+		return err
+	}
+	l.stop()
+
+	err = tm.p.DB().Transaction(func(dbTX *gorm.DB) error {
+		// We delete the checkpoint explicitly, rather than relying on a cascade
+		err := dbTX.
+			WithContext(ctx).
+			Table("receipt_listener_checkpoints").
+			Where("listener = ?", name).
+			Delete(&persistedReceiptCheckpoint{}).
+			Error
+		if err == nil {
+			err = dbTX.
+				WithContext(ctx).
+				Table("receipt_listeners").
+				Where("name = ?", name).
+				Delete(&persistedReceiptListener{}).
+				Error
+		}
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	delete(tm.receiptListeners, name)
+	return nil
+}
+
+func (tm *txManager) AddReceiptReceiver(ctx context.Context, name string, r components.ReceiptReceiver) (components.ReceiverCloser, error) {
+	tm.receiptListenerLock.Lock()
+	defer tm.receiptListenerLock.Unlock()
+
+	l, err := tm.getReceiptListener(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return l.receivers.AddReceiver(r), nil
+}
+
+func (l *receiptListener) start() {
+	if l.done == nil {
+		l.ctx, l.cancelCtx = context.WithCancel(log.WithLogField(l.tm.bgCtx, "receipt-listener", l.spec.Name))
+		l.done = make(chan struct{})
+		go l.runListener()
+	}
+}
+
+func (l *receiptListener) stop() {
+	if l.done != nil {
+		l.cancelCtx()
+		<-l.done
+		l.done = nil
+	}
+}
+
+func (l *receiptListener) loadCheckpoint() (checkpoint uint64, err error) {
+	err = l.tm.receiptsRetry.Do(l.ctx, func(attempt int) (retryable bool, err error) {
+		var checkpoints []*persistedReceiptCheckpoint
+		err = l.tm.p.DB().
+			WithContext(l.ctx).
+			Table("receipt_listener_checkpoints").
+			Where("listener = ?", l.spec.Name).
+			Limit(1).
+			Find(&checkpoints).
+			Error
+		if err != nil {
+			return true, err
+		}
+		switch {
+		case len(checkpoints) > 0:
+			checkpoint = checkpoints[0].Sequence
+		case l.spec.Filters.SequenceAbove != nil:
+			checkpoint = *l.spec.Filters.SequenceAbove
+		default:
+			checkpoint = 0
+		}
+		return false, nil
+	})
+	return checkpoint, err
+}
+
+func (l *receiptListener) writeCheckpoint(checkpoint uint64) error {
+	// We do not use the listener context for the DB operation, so that a stop immediately
+	// after a batch is acknowledged does not result in the batch being redelivered.
+	return l.tm.receiptsRetry.Do(l.ctx, func(attempt int) (retryable bool, err error) {
+		return true, l.tm.p.DB().
+			WithContext(l.tm.bgCtx).
+			Table("receipt_listener_checkpoints").
+			Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "listener"}},
+				DoUpdates: clause.AssignmentColumns([]string{"sequence", "time"}),
+			}).
+			Create(&persistedReceiptCheckpoint{
+				Listener: l.spec.Name,
+				Sequence: checkpoint,
+				Time:     tktypes.TimestampNow(),
+			}).
+			Error
+	})
+}
+
+func (l *receiptListener) readPage(checkpoint uint64) (receipts []*transactionReceipt, err error) {
+	err = l.tm.receiptsRetry.Do(l.ctx, func(attempt int) (retryable bool, err error) {
+		q := l.tm.p.DB().
+			WithContext(l.ctx).
+			Table("transaction_receipts").
+			Where("sequence > ?", checkpoint).
+			Order("sequence").
+			Limit(l.tm.receiptsReadPageSize)
+		if l.spec.Filters.Type != nil {
+			switch l.spec.Filters.Type.V() {
+			case pldapi.TransactionTypePrivate:
+				q = q.Where("domain <> ''")
+			case pldapi.TransactionTypePublic:
+				q = q.Where("domain = ''")
+			}
+		}
+		if l.spec.Filters.Domain != "" {
+			q = q.Where("domain = ?", l.spec.Filters.Domain)
+		}
+		return true, q.Find(&receipts).Error
+	})
+	return receipts, err
+}
+
+func (l *receiptListener) buildBatch(page []*transactionReceipt) (batch *receiptDeliveryBatch, err error) {
+	l.nextBatchID++
+	batch = &receiptDeliveryBatch{
+		ID:       l.nextBatchID,
+		Receipts: make([]*pldapi.TransactionReceiptFull, len(page)),
+	}
+	for i, pr := range page {
+		r := &pldapi.TransactionReceipt{
+			ID:                     pr.TransactionID,
+			Sequence:               pr.Sequence,
+			TransactionReceiptData: *mapPersistedReceipt(pr),
+		}
+		if l.spec.Options.DomainReceipts {
+			err = l.tm.receiptsRetry.Do(l.ctx, func(attempt int) (retryable bool, err error) {
+				batch.Receipts[i], err = l.tm.buildFullReceipt(l.ctx, r)
+				return true, err
+			})
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			batch.Receipts[i] = &pldapi.TransactionReceiptFull{TransactionReceipt: r}
+		}
+	}
+	return batch, nil
+}
+
+func (l *receiptListener) deliverBatch(batch *receiptDeliveryBatch) error {
+	// Failure to deliver (including a nack) results in redelivery of the same batch
+	return l.tm.receiptsRetry.Do(l.ctx, func(attempt int) (retryable bool, err error) {
+		r, err := l.receivers.WaitForReceiver(l.ctx)
+		if err != nil {
+			return false, err
+		}
+		log.L(l.ctx).Debugf("Delivering receipt batch %d (receipts=%d)", batch.ID, len(batch.Receipts))
+		return true, r.DeliverReceiptBatch(l.ctx, batch.ID, batch.Receipts)
+	})
+}
+
+func (l *receiptListener) waitForNewReceipts(notify <-chan struct{}) bool {
+	poll := time.NewTimer(l.tm.receiptsPollInterval)
+	defer poll.Stop()
+	select {
+	case <-notify:
+	case <-poll.C:
+	case <-l.ctx.Done():
+		return false
+	}
+	return true
+}
+
+func (l *receiptListener) runListener() {
+	defer close(l.done)
+
+	checkpoint, err := l.loadCheckpoint()
+	if err != nil {
+		log.L(l.ctx).Warnf("Receipt listener stopping before reading checkpoint: %s", err)
+		return
+	}
+
+	for {
+		// Get the notifier before we query, so we cannot miss a notification
+		notify := l.tm.receiptsNotifier()
+		page, err := l.readPage(checkpoint)
+		if err != nil {
+			log.L(l.ctx).Warnf("Receipt listener stopping: %s", err)
+			return
+		}
+
+		if len(page) == 0 {
+			if !l.waitForNewReceipts(notify) {
+				log.L(l.ctx).Debugf("Receipt listener stopping")
+				return
+			}
+			continue
+		}
+
+		batch, err := l.buildBatch(page)
+		if err == nil {
+			err = l.deliverBatch(batch)
+		}
+		if err == nil {
+			checkpoint = page[len(page)-1].Sequence
+			err = l.writeCheckpoint(checkpoint)
+		}
+		if err != nil {
+			log.L(l.ctx).Warnf("Receipt listener stopping: %s", err)
+			return
+		}
+	}
+}
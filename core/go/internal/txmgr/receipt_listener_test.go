@@ -0,0 +1,350 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package txmgr
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/kaleido-io/paladin/config/pkg/confutil"
+	"github.com/kaleido-io/paladin/config/pkg/pldconf"
+	"github.com/kaleido-io/paladin/core/internal/components"
+	"github.com/kaleido-io/paladin/core/mocks/componentmocks"
+	"github.com/kaleido-io/paladin/toolkit/pkg/pldapi"
+	"github.com/kaleido-io/paladin/toolkit/pkg/query"
+	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+type testReceiptBatch struct {
+	batchID  uint64
+	receipts []*pldapi.TransactionReceiptFull
+	result   chan error
+}
+
+type testReceiptReceiver chan *testReceiptBatch
+
+func (trr testReceiptReceiver) DeliverReceiptBatch(ctx context.Context, batchID uint64, receipts []*pldapi.TransactionReceiptFull) error {
+	b := &testReceiptBatch{batchID: batchID, receipts: receipts, result: make(chan error)}
+	select {
+	case trr <- b:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	select {
+	case err := <-b.result:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func fastReceiptListeners(conf *pldconf.TxManagerConfig, mc *mockComponents) {
+	conf.ReceiptListeners.ReadPageSize = confutil.P(2)
+	conf.ReceiptListeners.PollInterval = confutil.P("10ms")
+	conf.ReceiptListeners.Retry.InitialDelay = confutil.P("0s")
+}
+
+func writeTestReceipts(t *testing.T, ctx context.Context, txm *txManager, receipts ...*components.ReceiptInput) {
+	var postCommit func()
+	err := txm.p.DB().Transaction(func(dbTX *gorm.DB) (err error) {
+		postCommit, err = txm.FinalizeTransactions(ctx, dbTX, receipts)
+		return err
+	})
+	require.NoError(t, err)
+	postCommit()
+}
+
+func successReceipt(domain string) *components.ReceiptInput {
+	return &components.ReceiptInput{
+		Domain:        domain,
+		TransactionID: uuid.New(),
+		ReceiptType:   components.RT_Success,
+	}
+}
+
+func TestReceiptListenerDeliveryCheckpointRestart(t *testing.T) {
+
+	ctx, txm, done := newTestTransactionManager(t, true, fastReceiptListeners)
+	defer done()
+
+	err := txm.CreateReceiptListener(ctx, &pldapi.TransactionReceiptListener{Name: "listener1"})
+	require.NoError(t, err)
+
+	receiver := make(testReceiptReceiver)
+	closer, err := txm.AddReceiptReceiver(ctx, "listener1", receiver)
+	require.NoError(t, err)
+	defer closer.Close()
+
+	r1, r2, r3 := successReceipt(""), successReceipt(""), successReceipt("")
+	writeTestReceipts(t, ctx, txm, r1, r2, r3)
+
+	// First batch is limited by page size - we nack it and get it again
+	b := <-receiver
+	require.Len(t, b.receipts, 2)
+	assert.Equal(t, r1.TransactionID, b.receipts[0].ID)
+	assert.Equal(t, uint64(1), b.receipts[0].Sequence)
+	assert.Equal(t, r2.TransactionID, b.receipts[1].ID)
+	b.result <- fmt.Errorf("nack")
+	b = <-receiver
+	require.Len(t, b.receipts, 2)
+	assert.Equal(t, r1.TransactionID, b.receipts[0].ID)
+	b.result <- nil
+
+	b = <-receiver
+	require.Len(t, b.receipts, 1)
+	assert.Equal(t, r3.TransactionID, b.receipts[0].ID)
+	b.result <- nil
+
+	// Stop the listener, and write another receipt
+	err = txm.StopReceiptListener(ctx, "listener1")
+	require.NoError(t, err)
+	assert.False(t, *txm.GetReceiptListener(ctx, "listener1").Started)
+	r4 := successReceipt("")
+	writeTestReceipts(t, ctx, txm, r4)
+
+	// Re-load everything from the DB, as would happen on restart
+	txm.Stop()
+	txm.receiptListeners = map[string]*receiptListener{}
+	err = txm.Start()
+	require.NoError(t, err)
+	require.NotNil(t, txm.GetReceiptListener(ctx, "listener1"))
+
+	// Start it again and check we only get the new receipt
+	closer, err = txm.AddReceiptReceiver(ctx, "listener1", receiver)
+	require.NoError(t, err)
+	defer closer.Close()
+	err = txm.StartReceiptListener(ctx, "listener1")
+	require.NoError(t, err)
+	b = <-receiver
+	require.Len(t, b.receipts, 1)
+	assert.Equal(t, r4.TransactionID, b.receipts[0].ID)
+	b.result <- nil
+
+	listeners, err := txm.QueryReceiptListeners(ctx, nil, query.NewQueryBuilder().Limit(10).Equal("name", "listener1").Query())
+	require.NoError(t, err)
+	require.Len(t, listeners, 1)
+	assert.True(t, *listeners[0].Started)
+
+	err = txm.DeleteReceiptListener(ctx, "listener1")
+	require.NoError(t, err)
+	assert.Nil(t, txm.GetReceiptListener(ctx, "listener1"))
+
+	listeners, err = txm.QueryReceiptListeners(ctx, nil, query.NewQueryBuilder().Limit(10).Query())
+	require.NoError(t, err)
+	assert.Empty(t, listeners)
+}
+
+func TestReceiptListenerFiltersAndDomainReceipts(t *testing.T) {
+
+	ctx, txm, done := newTestTransactionManager(t, true, fastReceiptListeners, func(conf *pldconf.TxManagerConfig, mc *mockComponents) {
+		mc.stateMgr.On("GetTransactionStates", mock.Anything, mock.Anything, mock.Anything).Return(
+			&pldapi.TransactionStates{None: true}, nil,
+		)
+		md := componentmocks.NewDomain(t)
+		mc.domainManager.On("GetDomainByName", mock.Anything, "domain1").Return(md, nil)
+		md.On("BuildDomainReceipt", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(tktypes.RawJSON(`{"some":"receipt"}`), nil)
+	})
+	defer done()
+
+	// Write some receipts before creating the listener, to check sequenceAbove
+	writeTestReceipts(t, ctx, txm, successReceipt("domain1"))
+
+	err := txm.CreateReceiptListener(ctx, &pldapi.TransactionReceiptListener{
+		Name: "listener1",
+		Filters: pldapi.TransactionReceiptFilters{
+			SequenceAbove: confutil.P(uint64(1)),
+			Type:          confutil.P(pldapi.TransactionTypePrivate.Enum()),
+			Domain:        "domain1",
+		},
+		Options: pldapi.TransactionReceiptListenerOptions{
+			DomainReceipts: true,
+		},
+	})
+	require.NoError(t, err)
+
+	receiver := make(testReceiptReceiver)
+	closer, err := txm.AddReceiptReceiver(ctx, "listener1", receiver)
+	require.NoError(t, err)
+	defer closer.Close()
+
+	r1, r2, r3 := successReceipt(""), successReceipt("domain2"), successReceipt("domain1")
+	writeTestReceipts(t, ctx, txm, r1, r2, r3)
+
+	b := <-receiver
+	require.Len(t, b.receipts, 1)
+	assert.Equal(t, r3.TransactionID, b.receipts[0].ID)
+	assert.JSONEq(t, `{"some":"receipt"}`, b.receipts[0].DomainReceipt.String())
+	assert.True(t, b.receipts[0].States.None)
+	b.result <- nil
+}
+
+func TestReceiptListenerPublicFilter(t *testing.T) {
+
+	ctx, txm, done := newTestTransactionManager(t, true, fastReceiptListeners)
+	defer done()
+
+	err := txm.CreateReceiptListener(ctx, &pldapi.TransactionReceiptListener{
+		Name: "listener1",
+		Filters: pldapi.TransactionReceiptFilters{
+			Type: confutil.P(pldapi.TransactionTypePublic.Enum()),
+		},
+	})
+	require.NoError(t, err)
+
+	receiver := make(testReceiptReceiver)
+	closer, err := txm.AddReceiptReceiver(ctx, "listener1", receiver)
+	require.NoError(t, err)
+	defer closer.Close()
+
+	r1, r2 := successReceipt("domain1"), successReceipt("")
+	writeTestReceipts(t, ctx, txm, r1, r2)
+
+	b := <-receiver
+	require.Len(t, b.receipts, 1)
+	assert.Equal(t, r2.TransactionID, b.receipts[0].ID)
+	assert.Nil(t, b.receipts[0].States)
+	b.result <- nil
+}
+
+func TestCreateReceiptListenerErrors(t *testing.T) {
+
+	ctx, txm, done := newTestTransactionManager(t, true)
+	defer done()
+
+	err := txm.CreateReceiptListener(ctx, &pldapi.TransactionReceiptListener{Name: "bad name"})
+	assert.Regexp(t, "PD020005", err)
+
+	err = txm.CreateReceiptListener(ctx, &pldapi.TransactionReceiptListener{
+		Name: "listener1",
+		Filters: pldapi.TransactionReceiptFilters{
+			Type: confutil.P(tktypes.Enum[pldapi.TransactionType]("wrong")),
+		},
+	})
+	assert.Regexp(t, "PD020003", err)
+
+	err = txm.CreateReceiptListener(ctx, &pldapi.TransactionReceiptListener{
+		Name: "listener1",
+		Filters: pldapi.TransactionReceiptFilters{
+			Type:   confutil.P(pldapi.TransactionTypePublic.Enum()),
+			Domain: "domain1",
+		},
+	})
+	assert.Regexp(t, "PD012235", err)
+
+	err = txm.CreateReceiptListener(ctx, &pldapi.TransactionReceiptListener{Name: "listener1", Started: confutil.P(false)})
+	require.NoError(t, err)
+
+	err = txm.CreateReceiptListener(ctx, &pldapi.TransactionReceiptListener{Name: "listener1"})
+	assert.Regexp(t, "PD012234", err)
+
+	err = txm.StartReceiptListener(ctx, "unknown")
+	assert.Regexp(t, "PD012233", err)
+
+	err = txm.StopReceiptListener(ctx, "unknown")
+	assert.Regexp(t, "PD012233", err)
+
+	err = txm.DeleteReceiptListener(ctx, "unknown")
+	assert.Regexp(t, "PD012233", err)
+
+	_, err = txm.AddReceiptReceiver(ctx, "unknown", make(testReceiptReceiver))
+	assert.Regexp(t, "PD012233", err)
+
+}
+
+func TestReceiptListenersInitFail(t *testing.T) {
+
+	ctx, txm, done := newTestTransactionManager(t, false, func(conf *pldconf.TxManagerConfig, mc *mockComponents) {
+		mc.db.ExpectQuery("SELECT.*receipt_listeners").WillReturnError(fmt.Errorf("pop"))
+	})
+	defer done()
+
+	err := txm.receiptListenersInit(ctx)
+	assert.Regexp(t, "pop", err)
+
+}
+
+func TestReceiptListenersInitBadData(t *testing.T) {
+
+	ctx, txm, done := newTestTransactionManager(t, false, func(conf *pldconf.TxManagerConfig, mc *mockComponents) {
+		mc.db.ExpectQuery("SELECT.*receipt_listeners").WillReturnRows(sqlmock.NewRows([]string{
+			"name", "filters", "options",
+		}).AddRow("listener1", `!!! bad filters`, `{}`))
+	})
+	defer done()
+
+	err := txm.receiptListenersInit(ctx)
+	assert.Error(t, err)
+
+}
+
+func TestReceiptListenerDBErrors(t *testing.T) {
+
+	var mdb sqlmock.Sqlmock
+	ctx, txm, done := newTestTransactionManager(t, false, func(conf *pldconf.TxManagerConfig, mc *mockComponents) {
+		mdb = mc.db
+	})
+	defer done()
+
+	mdb.ExpectExec("INSERT.*receipt_listeners").WillReturnError(fmt.Errorf("pop"))
+	err := txm.CreateReceiptListener(ctx, &pldapi.TransactionReceiptListener{Name: "listener1", Started: confutil.P(false)})
+	assert.Regexp(t, "pop", err)
+
+	txm.receiptListeners["listener1"] = txm.newReceiptListener(&pldapi.TransactionReceiptListener{
+		Name:    "listener1",
+		Started: confutil.P(false),
+	})
+
+	mdb.ExpectExec("UPDATE.*receipt_listeners").WillReturnError(fmt.Errorf("pop"))
+	err = txm.StartReceiptListener(ctx, "listener1")
+	assert.Regexp(t, "pop", err)
+
+	mdb.ExpectBegin()
+	mdb.ExpectExec("DELETE.*receipt_listener_checkpoints").WillReturnError(fmt.Errorf("pop"))
+	mdb.ExpectRollback()
+	err = txm.DeleteReceiptListener(ctx, "listener1")
+	assert.Regexp(t, "pop", err)
+
+}
+
+func TestReceiptListenerStopWaitingForReceiver(t *testing.T) {
+
+	ctx, txm, done := newTestTransactionManager(t, true, fastReceiptListeners)
+	defer done()
+
+	writeTestReceipts(t, ctx, txm, successReceipt(""))
+
+	// No receiver, so it will block waiting for one until stopped
+	err := txm.CreateReceiptListener(ctx, &pldapi.TransactionReceiptListener{Name: "listener1"})
+	require.NoError(t, err)
+
+	err = txm.StopReceiptListener(ctx, "listener1")
+	require.NoError(t, err)
+
+	// Checkpoint is not written
+	var checkpoints []*persistedReceiptCheckpoint
+	err = txm.p.DB().Table("receipt_listener_checkpoints").Find(&checkpoints).Error
+	require.NoError(t, err)
+	assert.Empty(t, checkpoints)
+
+}
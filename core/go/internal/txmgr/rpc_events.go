@@ -0,0 +1,45 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package txmgr
+
+import (
+	"context"
+
+	"github.com/kaleido-io/paladin/toolkit/pkg/pldapi"
+	"github.com/kaleido-io/paladin/toolkit/pkg/rpcserver"
+)
+
+const rpcSubscriptionTypeReceipts = "receipts"
+
+// Each ptx_subscribe subscription is attached as a receiver to a receipt listener
+type receiptListenerSubscription struct {
+	*rpcserver.RPCEventSubscription
+}
+
+func newRPCEventStreams(tm *txManager) *rpcserver.RPCEventSubscriptions {
+	return rpcserver.NewRPCEventSubscriptions("ptx", rpcSubscriptionTypeReceipts,
+		func(ctx context.Context, listenerName string, sub *rpcserver.RPCEventSubscription) (rpcserver.RPCEventReceiverCloser, error) {
+			return tm.AddReceiptReceiver(ctx, listenerName, &receiptListenerSubscription{sub})
+		})
+}
+
+func (sub *receiptListenerSubscription) DeliverReceiptBatch(ctx context.Context, batchID uint64, receipts []*pldapi.TransactionReceiptFull) error {
+	return sub.DeliverBatch(ctx, batchID, &pldapi.TransactionReceiptBatch{
+		BatchID:  batchID,
+		Receipts: receipts,
+	})
+}
@@ -0,0 +1,174 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package txmgr
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/kaleido-io/paladin/config/pkg/pldconf"
+	"github.com/kaleido-io/paladin/toolkit/pkg/pldapi"
+	"github.com/kaleido-io/paladin/toolkit/pkg/rpcserver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestTransactionManagerWithWebSocketRPC(t *testing.T, init ...func(*pldconf.TxManagerConfig, *mockComponents)) (context.Context, *rpcserver.UnitTestWSClient, *txManager, func()) {
+	ctx, txm, txmDone := newTestTransactionManager(t, true, init...)
+
+	wsc, wsDone, err := rpcserver.NewUnitTestWSClient(ctx, txm.rpcModule)
+	require.NoError(t, err)
+
+	return ctx, wsc, txm, func() {
+		wsDone()
+		txmDone()
+	}
+}
+
+func wsReceiveReceipts(t *testing.T, wsc *rpcserver.UnitTestWSClient) (string, *pldapi.TransactionReceiptBatch) {
+	msg, err := wsc.Receive()
+	require.NoError(t, err)
+	require.Equal(t, "ptx_subscription", msg.Method)
+	var batch pldapi.TransactionReceiptBatch
+	err = json.Unmarshal(msg.Params.Result, &batch)
+	require.NoError(t, err)
+	return msg.Params.Subscription, &batch
+}
+
+func TestRPCReceiptSubscription(t *testing.T) {
+
+	ctx, wsc, txm, done := newTestTransactionManagerWithWebSocketRPC(t, fastReceiptListeners)
+	defer done()
+
+	err := wsc.Send(ctx, 1, "ptx_createReceiptListener", &pldapi.TransactionReceiptListener{Name: "listener1"})
+	require.NoError(t, err)
+	msg, err := wsc.Receive()
+	require.NoError(t, err)
+	require.Nil(t, msg.Error)
+	assert.Equal(t, "true", msg.Result.String())
+
+	err = wsc.Send(ctx, 2, "ptx_subscribe", "wrong", "listener1")
+	require.NoError(t, err)
+	msg, err = wsc.Receive()
+	require.NoError(t, err)
+	assert.Regexp(t, "PD020715", msg.Error.Message)
+
+	err = wsc.Send(ctx, 3, "ptx_subscribe", "receipts", "unknown")
+	require.NoError(t, err)
+	msg, err = wsc.Receive()
+	require.NoError(t, err)
+	assert.Regexp(t, "PD012233", msg.Error.Message)
+
+	err = wsc.Send(ctx, 4, "ptx_subscribe", "receipts", "listener1")
+	require.NoError(t, err)
+	msg, err = wsc.Receive()
+	require.NoError(t, err)
+	require.Nil(t, msg.Error)
+	var subID string
+	err = json.Unmarshal(msg.Result, &subID)
+	require.NoError(t, err)
+
+	r1 := successReceipt("")
+	writeTestReceipts(t, ctx, txm, r1)
+
+	// Nack the first delivery
+	notifiedSubID, batch := wsReceiveReceipts(t, wsc)
+	assert.Equal(t, subID, notifiedSubID)
+	require.Len(t, batch.Receipts, 1)
+	assert.Equal(t, r1.TransactionID, batch.Receipts[0].ID)
+	err = wsc.Send(ctx, 5, "ptx_nack", subID)
+	require.NoError(t, err)
+
+	// Ack the redelivery
+	_, redelivered := wsReceiveReceipts(t, wsc)
+	assert.Equal(t, batch.BatchID, redelivered.BatchID)
+	require.Len(t, redelivered.Receipts, 1)
+	assert.Equal(t, r1.TransactionID, redelivered.Receipts[0].ID)
+	err = wsc.Send(ctx, 6, "ptx_ack", subID)
+	require.NoError(t, err)
+
+	// Next batch, which is still in flight when we unsubscribe
+	r2 := successReceipt("")
+	writeTestReceipts(t, ctx, txm, r2)
+	_, batch = wsReceiveReceipts(t, wsc)
+	require.Len(t, batch.Receipts, 1)
+	assert.Equal(t, r2.TransactionID, batch.Receipts[0].ID)
+
+	err = wsc.Send(ctx, 8, "ptx_unsubscribe", subID)
+	require.NoError(t, err)
+	msg, err = wsc.Receive()
+	require.NoError(t, err)
+	require.Nil(t, msg.Error)
+	assert.Equal(t, "true", msg.Result.String())
+
+	err = wsc.Send(ctx, 9, "ptx_getReceiptListener", "listener1")
+	require.NoError(t, err)
+	msg, err = wsc.Receive()
+	require.NoError(t, err)
+	require.Nil(t, msg.Error)
+	var l pldapi.TransactionReceiptListener
+	err = json.Unmarshal(msg.Result, &l)
+	require.NoError(t, err)
+	assert.Equal(t, "listener1", l.Name)
+
+	err = wsc.Send(ctx, 10, "ptx_queryReceiptListeners", map[string]any{"limit": 10})
+	require.NoError(t, err)
+	msg, err = wsc.Receive()
+	require.NoError(t, err)
+	require.Nil(t, msg.Error)
+	var ls []*pldapi.TransactionReceiptListener
+	err = json.Unmarshal(msg.Result, &ls)
+	require.NoError(t, err)
+	assert.Len(t, ls, 1)
+
+	for i, method := range []string{"ptx_stopReceiptListener", "ptx_startReceiptListener", "ptx_deleteReceiptListener"} {
+		err = wsc.Send(ctx, 11+i, method, "listener1")
+		require.NoError(t, err)
+		msg, err = wsc.Receive()
+		require.NoError(t, err)
+		require.Nil(t, msg.Error)
+	}
+
+}
+
+func TestRPCReceiptSubscriptionConnectionClosed(t *testing.T) {
+
+	ctx, wsc, txm, done := newTestTransactionManagerWithWebSocketRPC(t, fastReceiptListeners)
+	defer done()
+
+	err := txm.CreateReceiptListener(ctx, &pldapi.TransactionReceiptListener{Name: "listener1"})
+	require.NoError(t, err)
+
+	err = wsc.Send(ctx, 1, "ptx_subscribe", "receipts", "listener1")
+	require.NoError(t, err)
+	msg, err := wsc.Receive()
+	require.NoError(t, err)
+	require.Nil(t, msg.Error)
+
+	writeTestReceipts(t, ctx, txm, successReceipt(""))
+	_, _ = wsReceiveReceipts(t, wsc)
+
+	// Close without acking, and check the receiver is removed
+	wsc.Close()
+	l := txm.receiptListeners["listener1"]
+	for l.receivers.Count() > 0 {
+		time.Sleep(1 * time.Millisecond)
+	}
+
+}
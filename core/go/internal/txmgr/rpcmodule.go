@@ -28,6 +28,7 @@ import (
 )
 
 func (tm *txManager) buildRPCModule() {
+	tm.rpcEventStreams = newRPCEventStreams(tm)
 	tm.rpcModule = rpcserver.NewRPCModule("ptx").
 		Add("ptx_sendTransaction", tm.rpcSendTransaction()).
 		Add("ptx_sendTransactions", tm.rpcSendTransactions()).
@@ -50,6 +51,8 @@ func (tm *txManager) buildRPCModule() {
 		Add("ptx_queryPendingPublicTransactions", tm.rpcQueryPendingPublicTransactions()).
 		Add("ptx_getPublicTransactionByNonce", tm.rpcGetPublicTransactionByNonce()).
 		Add("ptx_getPublicTransactionByHash", tm.rpcGetPublicTransactionByHash()).
+		Add("ptx_updatePublicTransaction", tm.rpcUpdatePublicTransaction()).
+		Add("ptx_cancelPublicTransaction", tm.rpcCancelPublicTransaction()).
 		Add("ptx_getPreparedTransaction", tm.rpcGetPreparedTransaction()).
 		Add("ptx_queryPreparedTransactions", tm.rpcQueryPreparedTransactions()).
 		Add("ptx_storeABI", tm.rpcStoreABI()).
@@ -58,7 +61,14 @@ func (tm *txManager) buildRPCModule() {
 		Add("ptx_decodeCall", tm.rpcDecodeCall()).
 		Add("ptx_decodeEvent", tm.rpcDecodeEvent()).
 		Add("ptx_decodeError", tm.rpcDecodeError()).
-		Add("ptx_resolveVerifier", tm.rpcResolveVerifier())
+		Add("ptx_resolveVerifier", tm.rpcResolveVerifier()).
+		Add("ptx_createReceiptListener", tm.rpcCreateReceiptListener()).
+		Add("ptx_queryReceiptListeners", tm.rpcQueryReceiptListeners()).
+		Add("ptx_getReceiptListener", tm.rpcGetReceiptListener()).
+		Add("ptx_startReceiptListener", tm.rpcStartReceiptListener()).
+		Add("ptx_stopReceiptListener", tm.rpcStopReceiptListener()).
+		Add("ptx_deleteReceiptListener", tm.rpcDeleteReceiptListener()).
+		AddAsync(tm.rpcEventStreams)
 
 	tm.debugRpcModule = rpcserver.NewRPCModule("debug").
 		Add("debug_getTransactionStatus", tm.rpcDebugTransactionStatus())
@@ -68,6 +78,9 @@ func (tm *txManager) rpcSendTransaction() rpcserver.RPCHandler {
 	return rpcserver.RPCMethod1(func(ctx context.Context,
 		tx pldapi.TransactionInput,
 	) (*uuid.UUID, error) {
+		if err := rpcserver.AuthorizeKey(ctx, tx.From); err != nil {
+			return nil, err
+		}
 		return tm.SendTransaction(ctx, &tx)
 	})
 }
@@ -76,6 +89,9 @@ func (tm *txManager) rpcSendTransactions() rpcserver.RPCHandler {
 	return rpcserver.RPCMethod1(func(ctx context.Context,
 		txs []*pldapi.TransactionInput,
 	) ([]uuid.UUID, error) {
+		if err := authorizeTransactionKeys(ctx, txs); err != nil {
+			return nil, err
+		}
 		return tm.SendTransactions(ctx, txs)
 	})
 }
@@ -84,6 +100,9 @@ func (tm *txManager) rpcPrepareTransaction() rpcserver.RPCHandler {
 	return rpcserver.RPCMethod1(func(ctx context.Context,
 		tx pldapi.TransactionInput,
 	) (*uuid.UUID, error) {
+		if err := rpcserver.AuthorizeKey(ctx, tx.From); err != nil {
+			return nil, err
+		}
 		return tm.PrepareTransaction(ctx, &tx)
 	})
 }
@@ -92,14 +111,33 @@ func (tm *txManager) rpcPrepareTransactions() rpcserver.RPCHandler {
 	return rpcserver.RPCMethod1(func(ctx context.Context,
 		txs []*pldapi.TransactionInput,
 	) ([]uuid.UUID, error) {
+		if err := authorizeTransactionKeys(ctx, txs); err != nil {
+			return nil, err
+		}
 		return tm.PrepareTransactions(ctx, txs)
 	})
 }
 
+func authorizeTransactionKeys(ctx context.Context, txs []*pldapi.TransactionInput) error {
+	for _, tx := range txs {
+		if tx != nil {
+			if err := rpcserver.AuthorizeKey(ctx, tx.From); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 func (tm *txManager) rpcCall() rpcserver.RPCHandler {
 	return rpcserver.RPCMethod1(func(ctx context.Context,
 		tx *pldapi.TransactionCall,
 	) (result tktypes.RawJSON, err error) {
+		if tx != nil {
+			if err = rpcserver.AuthorizeKey(ctx, tx.From); err != nil {
+				return nil, err
+			}
+		}
 		err = tm.CallTransaction(ctx, &result, tx)
 		return
 	})
@@ -255,6 +293,55 @@ func (tm *txManager) rpcGetPublicTransactionByHash() rpcserver.RPCHandler {
 	})
 }
 
+func (tm *txManager) rpcUpdatePublicTransaction() rpcserver.RPCHandler {
+	return rpcserver.RPCMethod3(func(ctx context.Context,
+		from tktypes.EthAddress,
+		nonce tktypes.HexUint64,
+		update pldapi.PublicTxUpdate,
+	) (*pldapi.PublicTxWithBinding, error) {
+		if err := tm.authorizePublicTransaction(ctx, from, nonce); err != nil {
+			return nil, err
+		}
+		return tm.UpdatePublicTransaction(ctx, from, nonce, &update)
+	})
+}
+
+func (tm *txManager) rpcCancelPublicTransaction() rpcserver.RPCHandler {
+	return rpcserver.RPCMethod2(func(ctx context.Context,
+		from tktypes.EthAddress,
+		nonce tktypes.HexUint64,
+	) (*pldapi.PublicTxWithBinding, error) {
+		if err := tm.authorizePublicTransaction(ctx, from, nonce); err != nil {
+			return nil, err
+		}
+		return tm.CancelPublicTransaction(ctx, from, nonce)
+	})
+}
+
+// Public transactions are identified by the resolved signing address, so we load the Paladin
+// transaction the public transaction was submitted for, and authorize the key it was sent "from".
+// If there is no such transaction, the signing address itself must be authorized.
+func (tm *txManager) authorizePublicTransaction(ctx context.Context, from tktypes.EthAddress, nonce tktypes.HexUint64) error {
+	keyIdentifier := from.String()
+	ptx, err := tm.GetPublicTransactionByNonce(ctx, from, nonce)
+	if err != nil {
+		return err
+	}
+	if ptx != nil {
+		tx, err := tm.GetTransactionByID(ctx, ptx.Transaction)
+		if err != nil {
+			return err
+		}
+		if tx != nil {
+			// Transactions are stored with the local node name appended to the key identifier
+			if keyIdentifier, err = tktypes.PrivateIdentityLocator(tx.From).Identity(ctx); err != nil {
+				return err
+			}
+		}
+	}
+	return rpcserver.AuthorizeKey(ctx, keyIdentifier)
+}
+
 func (tm *txManager) rpcStoreABI() rpcserver.RPCHandler {
 	return rpcserver.RPCMethod1(func(ctx context.Context,
 		a abi.ABI,
@@ -289,6 +376,9 @@ func (tm *txManager) rpcResolveVerifier() rpcserver.RPCHandler {
 		algorithm string,
 		verifierType string,
 	) (string, error) {
+		if err := rpcserver.AuthorizeKey(ctx, lookup); err != nil {
+			return "", err
+		}
 		return tm.identityResolver.ResolveVerifier(ctx, lookup, algorithm, verifierType)
 	})
 }
@@ -329,3 +419,51 @@ func (tm *txManager) rpcDecodeEvent() rpcserver.RPCHandler {
 		return tm.DecodeEvent(ctx, tm.p.DB(), topics, data, dataFormat)
 	})
 }
+
+func (tm *txManager) rpcCreateReceiptListener() rpcserver.RPCHandler {
+	return rpcserver.RPCMethod1(func(ctx context.Context,
+		listener pldapi.TransactionReceiptListener,
+	) (bool, error) {
+		return true, tm.CreateReceiptListener(ctx, &listener)
+	})
+}
+
+func (tm *txManager) rpcQueryReceiptListeners() rpcserver.RPCHandler {
+	return rpcserver.RPCMethod1(func(ctx context.Context,
+		query query.QueryJSON,
+	) ([]*pldapi.TransactionReceiptListener, error) {
+		return tm.QueryReceiptListeners(ctx, tm.p.DB(), &query)
+	})
+}
+
+func (tm *txManager) rpcGetReceiptListener() rpcserver.RPCHandler {
+	return rpcserver.RPCMethod1(func(ctx context.Context,
+		name string,
+	) (*pldapi.TransactionReceiptListener, error) {
+		return tm.GetReceiptListener(ctx, name), nil
+	})
+}
+
+func (tm *txManager) rpcStartReceiptListener() rpcserver.RPCHandler {
+	return rpcserver.RPCMethod1(func(ctx context.Context,
+		name string,
+	) (bool, error) {
+		return true, tm.StartReceiptListener(ctx, name)
+	})
+}
+
+func (tm *txManager) rpcStopReceiptListener() rpcserver.RPCHandler {
+	return rpcserver.RPCMethod1(func(ctx context.Context,
+		name string,
+	) (bool, error) {
+		return true, tm.StopReceiptListener(ctx, name)
+	})
+}
+
+func (tm *txManager) rpcDeleteReceiptListener() rpcserver.RPCHandler {
+	return rpcserver.RPCMethod1(func(ctx context.Context,
+		name string,
+	) (bool, error) {
+		return true, tm.DeleteReceiptListener(ctx, name)
+	})
+}
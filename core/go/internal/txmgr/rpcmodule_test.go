@@ -43,6 +43,10 @@ import (
 )
 
 func newTestTransactionManagerWithRPC(t *testing.T, init ...func(*pldconf.TxManagerConfig, *mockComponents)) (context.Context, string, *txManager, func()) {
+	return newTestTransactionManagerWithRPCAuth(t, pldconf.RPCAuthConfig{}, init...)
+}
+
+func newTestTransactionManagerWithRPCAuth(t *testing.T, authConf pldconf.RPCAuthConfig, init ...func(*pldconf.TxManagerConfig, *mockComponents)) (context.Context, string, *txManager, func()) {
 	ctx, txm, txmDone := newTestTransactionManager(t, true, init...)
 
 	rpcServer, err := rpcserver.NewRPCServer(ctx, &pldconf.RPCServerConfig{
@@ -52,7 +56,8 @@ func newTestTransactionManagerWithRPC(t *testing.T, init ...func(*pldconf.TxMana
 				ShutdownTimeout: confutil.P("0"),
 			},
 		},
-		WS: pldconf.RPCServerConfigWS{Disabled: true},
+		WS:   pldconf.RPCServerConfigWS{Disabled: true},
+		Auth: authConf,
 	})
 	require.NoError(t, err)
 
@@ -252,7 +257,7 @@ func TestPublicTransactionLifecycle(t *testing.T) {
 	// Finalize the deploy as a success
 	txHash1 := tktypes.Bytes32(tktypes.RandBytes(32))
 	blockNumber1 := int64(12345)
-	err = tmr.FinalizeTransactions(ctx, tmr.p.DB(), []*components.ReceiptInput{
+	_, err = tmr.FinalizeTransactions(ctx, tmr.p.DB(), []*components.ReceiptInput{
 		{
 			TransactionID: tx1ID,
 			ReceiptType:   components.RT_Success,
@@ -291,7 +296,7 @@ func TestPublicTransactionLifecycle(t *testing.T) {
 	blockNumber2 := int64(12345)
 	revertData, err := sampleABI.Errors()["BadValue"].EncodeCallDataValuesCtx(ctx, []any{12345})
 	require.NoError(t, err)
-	err = tmr.FinalizeTransactions(ctx, tmr.p.DB(), []*components.ReceiptInput{
+	_, err = tmr.FinalizeTransactions(ctx, tmr.p.DB(), []*components.ReceiptInput{
 		{
 			TransactionID: tx2ID,
 			ReceiptType:   components.RT_FailedOnChainWithRevertData,
@@ -455,6 +460,125 @@ func TestPublicTransactionPassthroughQueries(t *testing.T) {
 	assert.Equal(t, sampleTxns[0], txn)
 }
 
+func TestPublicTransactionUpdateCancel(t *testing.T) {
+
+	tx := &pldapi.PublicTxWithBinding{
+		PublicTx: &pldapi.PublicTx{
+			From:  tktypes.EthAddress(tktypes.RandBytes(20)),
+			Nonce: confutil.P(tktypes.HexUint64(12345)),
+		},
+		PublicTxBinding: pldapi.PublicTxBinding{Transaction: uuid.New(), TransactionType: pldapi.TransactionTypePublic.Enum()},
+	}
+	ctx, url, _, done := newTestTransactionManagerWithRPC(t,
+		mockQueryPublicTxWithBindings(func(jq *query.QueryJSON) ([]*pldapi.PublicTxWithBinding, error) {
+			return []*pldapi.PublicTxWithBinding{tx}, nil
+		}),
+		func(conf *pldconf.TxManagerConfig, mc *mockComponents) {
+			mc.publicTxMgr.On("UpdateTransaction", mock.Anything, tx.From, uint64(12345), mock.MatchedBy(func(u *pldapi.PublicTxUpdate) bool {
+				return u.Data.String() == "0xfeedbeef"
+			})).Return(nil).Once()
+			mc.publicTxMgr.On("UpdateTransaction", mock.Anything, tx.From, uint64(12345), mock.Anything).Return(fmt.Errorf("pop")).Once()
+			mc.publicTxMgr.On("CancelTransaction", mock.Anything, tx.From, uint64(12345)).Return(nil).Once()
+			mc.publicTxMgr.On("CancelTransaction", mock.Anything, tx.From, uint64(12345)).Return(fmt.Errorf("pop")).Once()
+		},
+	)
+	defer done()
+
+	rpcClient, err := rpcclient.NewHTTPClient(ctx, &pldconf.HTTPClientConfig{URL: url})
+	require.NoError(t, err)
+
+	var txn *pldapi.PublicTxWithBinding
+	err = rpcClient.CallRPC(ctx, &txn, "ptx_updatePublicTransaction", tx.From, tx.Nonce, &pldapi.PublicTxUpdate{
+		Data: tktypes.MustParseHexBytes("0xfeedbeef"),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, tx, txn)
+
+	err = rpcClient.CallRPC(ctx, &txn, "ptx_updatePublicTransaction", tx.From, tx.Nonce, &pldapi.PublicTxUpdate{})
+	require.Regexp(t, "pop", err)
+
+	err = rpcClient.CallRPC(ctx, &txn, "ptx_cancelPublicTransaction", tx.From, tx.Nonce)
+	require.NoError(t, err)
+	assert.Equal(t, tx, txn)
+
+	err = rpcClient.CallRPC(ctx, &txn, "ptx_cancelPublicTransaction", tx.From, tx.Nonce)
+	require.Regexp(t, "pop", err)
+}
+
+func TestPublicTransactionUpdateCancelKeyForbidden(t *testing.T) {
+
+	senderAddr := tktypes.RandAddress()
+	var boundTxID uuid.UUID
+	ctx, url, txm, done := newTestTransactionManagerWithRPCAuth(t,
+		pldconf.RPCAuthConfig{
+			Tokens: []pldconf.RPCAuthTokenConfig{{Principal: "user1", Token: "token1"}},
+			Policies: []pldconf.RPCAuthPolicyConfig{{
+				Principals: []string{"user1"},
+				Methods:    []string{"ptx_"},
+				Keys:       []string{"sender1"},
+			}},
+		},
+		mockSubmitPublicTxOk(t, senderAddr),
+		mockQueryPublicTxWithBindings(func(jq *query.QueryJSON) ([]*pldapi.PublicTxWithBinding, error) {
+			return []*pldapi.PublicTxWithBinding{{
+				PublicTx: &pldapi.PublicTx{
+					From:  *senderAddr,
+					Nonce: confutil.P(tktypes.HexUint64(12345)),
+				},
+				PublicTxBinding: pldapi.PublicTxBinding{Transaction: boundTxID, TransactionType: pldapi.TransactionTypePublic.Enum()},
+			}}, nil
+		}),
+		func(conf *pldconf.TxManagerConfig, mc *mockComponents) {
+			mc.publicTxMgr.On("UpdateTransaction", mock.Anything, *senderAddr, uint64(12345), mock.Anything).Return(nil).Once()
+			mc.publicTxMgr.On("CancelTransaction", mock.Anything, *senderAddr, uint64(12345)).Return(nil).Once()
+			mc.identityResolver.On("ResolveVerifier", mock.Anything, "sender1", algorithms.ECDSA_SECP256K1, verifiers.ETH_ADDRESS).
+				Return(senderAddr.String(), nil)
+		},
+	)
+	defer done()
+
+	txID, err := txm.SendTransaction(ctx, &pldapi.TransactionInput{
+		TransactionBase: pldapi.TransactionBase{
+			From:     "sender1",
+			Type:     pldapi.TransactionTypePublic.Enum(),
+			To:       tktypes.RandAddress(),
+			Function: "set",
+			Data:     tktypes.RawJSON(`[12345]`),
+		},
+		ABI: abi.ABI{{Type: abi.Function, Name: "set", Inputs: abi.ParameterArray{{Type: "uint256"}}}},
+	})
+	require.NoError(t, err)
+	boundTxID = *txID
+
+	rpcClient, err := rpcclient.NewHTTPClient(ctx, &pldconf.HTTPClientConfig{
+		URL:         url,
+		HTTPHeaders: map[string]interface{}{"Authorization": "Bearer token1"},
+	})
+	require.NoError(t, err)
+
+	// The public transaction was submitted for a transaction from an authorized key
+	var txn *pldapi.PublicTxWithBinding
+	err = rpcClient.CallRPC(ctx, &txn, "ptx_updatePublicTransaction", senderAddr, 12345, &pldapi.PublicTxUpdate{})
+	require.NoError(t, err)
+	err = rpcClient.CallRPC(ctx, &txn, "ptx_cancelPublicTransaction", senderAddr, 12345)
+	require.NoError(t, err)
+
+	// Without a bound transaction the signing address is checked, which is not authorized
+	boundTxID = uuid.New()
+	err = rpcClient.CallRPC(ctx, &txn, "ptx_updatePublicTransaction", senderAddr, 12345, &pldapi.PublicTxUpdate{})
+	assert.Regexp(t, "PD020709", err)
+	err = rpcClient.CallRPC(ctx, &txn, "ptx_cancelPublicTransaction", senderAddr, 12345)
+	assert.Regexp(t, "PD020709", err)
+
+	var verifier string
+	err = rpcClient.CallRPC(ctx, &verifier, "ptx_resolveVerifier", "sender1", algorithms.ECDSA_SECP256K1, verifiers.ETH_ADDRESS)
+	require.NoError(t, err)
+	assert.Equal(t, senderAddr.String(), verifier)
+	err = rpcClient.CallRPC(ctx, &verifier, "ptx_resolveVerifier", "other1", algorithms.ECDSA_SECP256K1, verifiers.ETH_ADDRESS)
+	assert.Regexp(t, "PD020709", err)
+
+}
+
 func TestDetailedReceiptRPCsNotFound(t *testing.T) {
 
 	ctx, url, _, done := newTestTransactionManagerWithRPC(t, func(tmc *pldconf.TxManagerConfig, mc *mockComponents) {
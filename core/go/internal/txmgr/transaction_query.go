@@ -289,6 +289,20 @@ func (tm *txManager) GetPublicTransactionByNonce(ctx context.Context, from tktyp
 	return prs[0], nil
 }
 
+func (tm *txManager) UpdatePublicTransaction(ctx context.Context, from tktypes.EthAddress, nonce tktypes.HexUint64, update *pldapi.PublicTxUpdate) (*pldapi.PublicTxWithBinding, error) {
+	if err := tm.publicTxMgr.UpdateTransaction(ctx, from, nonce.Uint64(), update); err != nil {
+		return nil, err
+	}
+	return tm.GetPublicTransactionByNonce(ctx, from, nonce)
+}
+
+func (tm *txManager) CancelPublicTransaction(ctx context.Context, from tktypes.EthAddress, nonce tktypes.HexUint64) (*pldapi.PublicTxWithBinding, error) {
+	if err := tm.publicTxMgr.CancelTransaction(ctx, from, nonce.Uint64()); err != nil {
+		return nil, err
+	}
+	return tm.GetPublicTransactionByNonce(ctx, from, nonce)
+}
+
 func (tm *txManager) GetPublicTransactionByHash(ctx context.Context, hash tktypes.Bytes32) (*pldapi.PublicTxWithBinding, error) {
 	return tm.publicTxMgr.GetPublicTransactionForHash(ctx, tm.p.DB(), hash)
 }
@@ -424,6 +424,9 @@ func (tm *txManager) processNewTransactions(ctx context.Context, dbTX *gorm.DB,
 		if txiPostCommit != nil {
 			txiPostCommit()
 		}
+		for _, txi := range txis {
+			tm.metrics.submitted.WithLabelValues(string(txi.Transaction.Type.V())).Inc()
+		}
 	}
 	for i, tx := range txs {
 		abiPostCommit, txi, err := tm.resolveNewTransaction(ctx, dbTX, tx, submitMode)
@@ -44,6 +44,7 @@ import (
 	"github.com/kaleido-io/paladin/toolkit/pkg/rpcclient"
 	"github.com/kaleido-io/paladin/toolkit/pkg/rpcserver"
 	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
+	"github.com/prometheus/client_golang/prometheus"
 	"gorm.io/gorm"
 )
 
@@ -65,6 +66,13 @@ type BlockIndexer interface {
 	WaitForTransactionAnyResult(ctx context.Context, hash tktypes.Bytes32) (*pldapi.IndexedTransaction, error)
 	GetBlockListenerHeight(ctx context.Context) (highest uint64, err error)
 	GetConfirmedBlockHeight(ctx context.Context) (confirmed tktypes.HexUint64, err error)
+	CreateEventStream(ctx context.Context, stream *pldapi.EventStream) (*pldapi.EventStream, error)
+	GetEventStream(ctx context.Context, name string) (*pldapi.EventStream, error)
+	QueryEventStreams(ctx context.Context, jq *query.QueryJSON) ([]*pldapi.EventStream, error)
+	StartEventStream(ctx context.Context, name string) error
+	StopEventStream(ctx context.Context, name string) error
+	DeleteEventStream(ctx context.Context, name string) error
+	AddEventStreamReceiver(ctx context.Context, name string, r EventStreamReceiver) (EventStreamReceiverCloser, error)
 	RPCModule() *rpcserver.RPCModule
 }
 
@@ -77,6 +85,10 @@ type BlockIndexer interface {
 //
 // This implementation is thus deliberately simple assuming that when instability is found
 // in the notifications it can simply wipe out its view and start again.
+//
+// If a re-org is found that is deeper than the required confirmations (the parent hash of the
+// next confirmed block does not match the last block we dispatched) then the indexed data is
+// rolled back to the fork point, and the indexer resets from there.
 type blockIndexer struct {
 	parentCtxForReset          context.Context
 	cancelFunc                 func()
@@ -85,16 +97,19 @@ type blockIndexer struct {
 	wsConn                     rpcclient.WSClient
 	stateLock                  sync.Mutex
 	fromBlock                  *ethtypes.HexUint64
-	nextBlock                  *ethtypes.HexUint64 // nil in the special case of "latest" and no block received yet
-	highestConfirmedBlock      atomic.Int64        // set after we persist blocks
+	nextBlock                  *ethtypes.HexUint64       // nil in the special case of "latest" and no block received yet
+	highestConfirmedBlock      atomic.Int64              // set after we persist blocks
+	lastDispatchedHash         ethtypes.HexBytes0xPrefix // the next confirmed block must have this as its parent, or we have a re-org
 	blocksSinceCheckpoint      []*BlockInfoJSONRPC
 	newHeadToAdd               []*BlockInfoJSONRPC // used by the notification routine when there are new blocks that add directly onto the end of the blocksSinceCheckpoint
 	requiredConfirmations      int
+	maxReorgDepth              int
 	retry                      *retry.Retry
 	batchSize                  int
 	batchTimeout               time.Duration
 	txWaiters                  *inflight.InflightManager[tktypes.Bytes32, *pldapi.IndexedTransaction]
 	preCommitHandlers          []PreCommitHandler
+	rewindHandlers             []RewindHandler
 	eventStreams               map[uuid.UUID]*eventStream
 	eventStreamsHeadSet        map[uuid.UUID]*eventStream
 	eventStreamsLock           sync.Mutex
@@ -105,16 +120,24 @@ type blockIndexer struct {
 	processorDone              chan struct{}
 	dispatcherDone             chan struct{}
 	rpcModule                  *rpcserver.RPCModule
+	reorgsTotal                prometheus.Counter
 }
 
-func NewBlockIndexer(ctx context.Context, config *pldconf.BlockIndexerConfig, wsConfig *pldconf.WSClientConfig, persistence persistence.Persistence) (_ BlockIndexer, err error) {
+func NewBlockIndexer(ctx context.Context, config *pldconf.BlockIndexerConfig, wsConfig *pldconf.WSClientConfig, persistence persistence.Persistence, metricsRegistry prometheus.Registerer) (_ BlockIndexer, err error) {
 
 	blockListener, err := newBlockListener(ctx, config, wsConfig)
 	if err != nil {
 		return nil, err
 	}
 
-	return newBlockIndexer(ctx, config, persistence, blockListener)
+	bi, err := newBlockIndexer(ctx, config, persistence, blockListener)
+	if err != nil {
+		return nil, err
+	}
+	if err := bi.registerMetrics(metricsRegistry); err != nil {
+		return nil, err
+	}
+	return bi, nil
 }
 
 func newBlockIndexer(ctx context.Context, conf *pldconf.BlockIndexerConfig, persistence persistence.Persistence, blockListener *blockListener) (bi *blockIndexer, err error) {
@@ -124,6 +147,7 @@ func newBlockIndexer(ctx context.Context, conf *pldconf.BlockIndexerConfig, pers
 		wsConn:                     blockListener.wsConn,
 		blockListener:              blockListener,
 		requiredConfirmations:      confutil.IntMin(conf.RequiredConfirmations, 0, *pldconf.BlockIndexerDefaults.RequiredConfirmations),
+		maxReorgDepth:              confutil.IntMin(conf.MaxReorgDepth, 1, *pldconf.BlockIndexerDefaults.MaxReorgDepth),
 		retry:                      blockListener.retry,
 		batchSize:                  confutil.IntMin(conf.CommitBatchSize, 1, *pldconf.BlockIndexerDefaults.CommitBatchSize),
 		batchTimeout:               confutil.DurationMin(conf.CommitBatchTimeout, 0, *pldconf.BlockIndexerDefaults.CommitBatchTimeout),
@@ -133,6 +157,7 @@ func newBlockIndexer(ctx context.Context, conf *pldconf.BlockIndexerConfig, pers
 		esBlockDispatchQueueLength: confutil.IntMin(conf.EventStreams.BlockDispatchQueueLength, 0, *pldconf.EventStreamDefaults.BlockDispatchQueueLength),
 		esCatchUpQueryPageSize:     confutil.IntMin(conf.EventStreams.CatchUpQueryPageSize, 0, *pldconf.EventStreamDefaults.CatchUpQueryPageSize),
 		dispatcherTap:              make(chan struct{}, 1),
+		reorgsTotal:                newReorgsCounter(),
 	}
 	bi.highestConfirmedBlock.Store(-1)
 	if err := bi.setFromBlock(ctx, conf); err != nil {
@@ -156,11 +181,13 @@ func (bi *blockIndexer) Start(internalStreams ...*InternalEventStream) error {
 			}
 		case IESTypePreCommitHandler:
 			bi.preCommitHandlers = append(bi.preCommitHandlers, ies.PreCommitHandler)
+			if ies.RewindHandler != nil {
+				bi.rewindHandlers = append(bi.rewindHandlers, ies.RewindHandler)
+			}
 		}
 	}
 	bi.blockListener.start()
 	bi.startOrReset()
-	bi.startEventStreams()
 	return nil
 }
 
@@ -205,6 +232,9 @@ func (bi *blockIndexer) startOrReset() {
 
 	go bi.startup(runCtx)
 
+	// Event streams are stopped on reset, so they restart from their checkpoints
+	bi.startEventStreams()
+
 }
 
 func (bi *blockIndexer) startup(runCtx context.Context) {
@@ -268,39 +298,43 @@ func (bi *blockIndexer) GetBlockListenerHeight(ctx context.Context) (confirmed u
 	return bi.blockListener.getHighestBlock(ctx)
 }
 
-func (bi *blockIndexer) setFromBlock(ctx context.Context, conf *pldconf.BlockIndexerConfig) error {
-	var vUntyped interface{}
+func (bi *blockIndexer) setFromBlock(ctx context.Context, conf *pldconf.BlockIndexerConfig) (err error) {
 	fromBlock := conf.FromBlock
 	if fromBlock == nil {
 		fromBlock = pldconf.BlockIndexerDefaults.FromBlock
 	}
+	log.L(ctx).Infof("From block: %s", fromBlock)
+	bi.fromBlock, err = parseFromBlock(ctx, fromBlock)
+	return err
+}
+
+// Parses a block number, or the string "latest" (returned as nil)
+func parseFromBlock(ctx context.Context, fromBlock json.RawMessage) (*ethtypes.HexUint64, error) {
+	var vUntyped interface{}
 	dec := json.NewDecoder(bytes.NewReader(fromBlock))
 	dec.UseNumber()
 	if err := dec.Decode(&vUntyped); err != nil {
-		return i18n.WrapError(ctx, err, msgs.MsgBlockIndexerInvalidFromBlock, conf.FromBlock)
+		return nil, i18n.WrapError(ctx, err, msgs.MsgBlockIndexerInvalidFromBlock, fromBlock)
 	}
 	switch vTyped := vUntyped.(type) {
 	case string:
-		return bi.setFromBlockStr(ctx, vTyped)
+		return parseFromBlockStr(ctx, vTyped)
 	case json.Number:
-		return bi.setFromBlockStr(ctx, vTyped.String())
+		return parseFromBlockStr(ctx, vTyped.String())
 	default:
-		return i18n.NewError(ctx, msgs.MsgBlockIndexerInvalidFromBlock, conf.FromBlock)
+		return nil, i18n.NewError(ctx, msgs.MsgBlockIndexerInvalidFromBlock, fromBlock)
 	}
 }
 
-func (bi *blockIndexer) setFromBlockStr(ctx context.Context, fromBlock string) error {
-	log.L(ctx).Infof("From block: %s", fromBlock)
+func parseFromBlockStr(ctx context.Context, fromBlock string) (*ethtypes.HexUint64, error) {
 	if strings.EqualFold(fromBlock, "latest") {
-		bi.fromBlock = nil
-		return nil
+		return nil, nil
 	}
 	uint64Val, err := strconv.ParseUint(fromBlock, 0, 64)
 	if err != nil {
-		return i18n.WrapError(ctx, err, msgs.MsgBlockIndexerInvalidFromBlock, fromBlock)
+		return nil, i18n.WrapError(ctx, err, msgs.MsgBlockIndexerInvalidFromBlock, fromBlock)
 	}
-	bi.fromBlock = (*ethtypes.HexUint64)(&uint64Val)
-	return nil
+	return (*ethtypes.HexUint64)(&uint64Val), nil
 }
 
 func (bi *blockIndexer) restoreCheckpoint() error {
@@ -324,9 +358,11 @@ func (bi *blockIndexer) restoreCheckpoint() error {
 		log.L(bi.parentCtxForReset).Infof("Block indexer restarting from checkpoint fromBlock=%s", bi.fromBlock)
 		nextBlock := ethtypes.HexUint64(blocks[0].Number + 1)
 		bi.nextBlock = &nextBlock
+		bi.lastDispatchedHash = blocks[0].Hash[:]
 		bi.highestConfirmedBlock.Store(blocks[0].Number)
 	default:
 		bi.nextBlock = bi.fromBlock
+		bi.lastDispatchedHash = nil
 	}
 	return nil
 }
@@ -462,7 +498,25 @@ func (bi *blockIndexer) dispatcher(ctx context.Context) {
 			// spin getting blocks until we it looks like we need to wait for a notification
 			lastFromNotification := false
 			for bi.readNextBlock(ctx, &lastFromNotification) {
-				toDispatch := bi.getNextConfirmed(ctx)
+				toDispatch, reorg := bi.getNextConfirmed(ctx)
+				if reorg {
+					// Anything we have not yet written is discarded, and re-read after the reset
+					if batch != nil {
+						batch.timeoutCancel()
+						batch.wg.Wait()
+					}
+					if err := bi.rewindToForkPoint(ctx); err != nil {
+						if ctx.Err() == nil {
+							// We cannot safely continue indexing, so stop until the issue is resolved and we are restarted
+							log.L(ctx).Errorf("Block indexer stopped, as the chain re-org could not be handled: %s", err)
+						} else {
+							log.L(ctx).Debugf("Confirmed block dispatcher stopping during re-org rewind: %s", err)
+						}
+						return
+					}
+					go bi.startOrReset()
+					return // We know we need to exit
+				}
 				if toDispatch != nil {
 					pendingDispatch = append(pendingDispatch, toDispatch)
 				}
@@ -767,18 +821,24 @@ func (bi *blockIndexer) readNextBlock(ctx context.Context, lastFromNotification
 
 }
 
-func (bi *blockIndexer) getNextConfirmed(ctx context.Context) (toDispatch *BlockInfoJSONRPC) {
+func (bi *blockIndexer) getNextConfirmed(ctx context.Context) (toDispatch *BlockInfoJSONRPC, reorg bool) {
 	bi.stateLock.Lock()
 	defer bi.stateLock.Unlock()
 	if len(bi.blocksSinceCheckpoint) > bi.requiredConfirmations {
 		toDispatch = bi.blocksSinceCheckpoint[0]
+		if bi.lastDispatchedHash != nil && !toDispatch.ParentHash.Equals(bi.lastDispatchedHash) {
+			log.L(ctx).Warnf("Chain re-org detected beyond confirmation depth: confirmed block %d / %s has parent %s (expected %s)",
+				toDispatch.Number, toDispatch.Hash, toDispatch.ParentHash, bi.lastDispatchedHash)
+			return nil, true
+		}
 		// don't want memory to grow indefinitely by shifting right, so we create a new slice here
 		bi.blocksSinceCheckpoint = append([]*BlockInfoJSONRPC{}, bi.blocksSinceCheckpoint[1:]...)
 		newCheckpoint := toDispatch.Number + 1
 		bi.nextBlock = &newCheckpoint
+		bi.lastDispatchedHash = toDispatch.Hash
 		log.L(ctx).Debugf("Confirmed block popped for dispatch %d / %s (new blocksSinceCheckpoint=%d)", toDispatch.Number, toDispatch.Hash, len(bi.blocksSinceCheckpoint))
 	}
-	return toDispatch
+	return toDispatch, false
 }
 
 func (bi *blockIndexer) WaitForTransactionAnyResult(ctx context.Context, hash tktypes.Bytes32) (*pldapi.IndexedTransaction, error) {
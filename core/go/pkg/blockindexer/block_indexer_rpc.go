@@ -41,7 +41,14 @@ func (bi *blockIndexer) initRPC() {
 		Add("bidx_queryIndexedTransactions", bi.rpcQueryIndexedTransactions()).
 		Add("bidx_queryIndexedEvents", bi.rpcQueryIndexedEvents()).
 		Add("bidx_getConfirmedBlockHeight", bi.rpcGetConfirmedBlockHeight()).
-		Add("bidx_decodeTransactionEvents", bi.rpcDecodeTransactionEvents())
+		Add("bidx_decodeTransactionEvents", bi.rpcDecodeTransactionEvents()).
+		Add("bidx_createEventStream", bi.rpcCreateEventStream()).
+		Add("bidx_queryEventStreams", bi.rpcQueryEventStreams()).
+		Add("bidx_getEventStream", bi.rpcGetEventStream()).
+		Add("bidx_startEventStream", bi.rpcStartEventStream()).
+		Add("bidx_stopEventStream", bi.rpcStopEventStream()).
+		Add("bidx_deleteEventStream", bi.rpcDeleteEventStream()).
+		AddAsync(newRPCEventStreams(bi))
 }
 
 func (bi *blockIndexer) rpcGetBlockByNumber() rpcserver.RPCHandler {
@@ -125,3 +132,51 @@ func (bi *blockIndexer) rpcDecodeTransactionEvents() rpcserver.RPCHandler {
 		return bi.DecodeTransactionEvents(ctx, hash, abi, resultFormat)
 	})
 }
+
+func (bi *blockIndexer) rpcCreateEventStream() rpcserver.RPCHandler {
+	return rpcserver.RPCMethod1(func(ctx context.Context,
+		stream pldapi.EventStream,
+	) (*pldapi.EventStream, error) {
+		return bi.CreateEventStream(ctx, &stream)
+	})
+}
+
+func (bi *blockIndexer) rpcQueryEventStreams() rpcserver.RPCHandler {
+	return rpcserver.RPCMethod1(func(ctx context.Context,
+		jq query.QueryJSON,
+	) ([]*pldapi.EventStream, error) {
+		return bi.QueryEventStreams(ctx, &jq)
+	})
+}
+
+func (bi *blockIndexer) rpcGetEventStream() rpcserver.RPCHandler {
+	return rpcserver.RPCMethod1(func(ctx context.Context,
+		name string,
+	) (*pldapi.EventStream, error) {
+		return bi.GetEventStream(ctx, name)
+	})
+}
+
+func (bi *blockIndexer) rpcStartEventStream() rpcserver.RPCHandler {
+	return rpcserver.RPCMethod1(func(ctx context.Context,
+		name string,
+	) (bool, error) {
+		return true, bi.StartEventStream(ctx, name)
+	})
+}
+
+func (bi *blockIndexer) rpcStopEventStream() rpcserver.RPCHandler {
+	return rpcserver.RPCMethod1(func(ctx context.Context,
+		name string,
+	) (bool, error) {
+		return true, bi.StopEventStream(ctx, name)
+	})
+}
+
+func (bi *blockIndexer) rpcDeleteEventStream() rpcserver.RPCHandler {
+	return rpcserver.RPCMethod1(func(ctx context.Context,
+		name string,
+	) (bool, error) {
+		return true, bi.DeleteEventStream(ctx, name)
+	})
+}
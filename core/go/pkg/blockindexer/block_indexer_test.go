@@ -38,6 +38,7 @@ import (
 	"github.com/kaleido-io/paladin/toolkit/pkg/query"
 	"github.com/kaleido-io/paladin/toolkit/pkg/rpcclient"
 	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -276,7 +277,7 @@ func TestNewBlockIndexerBadTLS(t *testing.T) {
 				CAFile: t.TempDir(),
 			},
 		},
-	}, nil)
+	}, nil, prometheus.NewRegistry())
 	assert.Regexp(t, "PD020401", err)
 }
 
@@ -289,7 +290,7 @@ func TestNewBlockIndexerRestoreCheckpointFail(t *testing.T) {
 	wsConf := &pldconf.WSClientConfig{HTTPClientConfig: pldconf.HTTPClientConfig{URL: "ws://localhost:8546"}}
 
 	cancelledCtx, cancelCtx := context.WithCancel(context.Background())
-	bi, err := NewBlockIndexer(cancelledCtx, &pldconf.BlockIndexerConfig{}, wsConf, p.P)
+	bi, err := NewBlockIndexer(cancelledCtx, &pldconf.BlockIndexerConfig{}, wsConf, p.P, prometheus.NewRegistry())
 	require.NoError(t, err)
 	cancelCtx()
 
@@ -487,6 +487,14 @@ func (bl *blockListener) getHighestBlock(ctx context.Context) (uint64, error) {
 	log.L(bl.ctx).Debugf("ChainHead=%d", highestBlock)
 	return highestBlock, nil
 }
+
+// currentHighestBlock returns the chain head without waiting for it to be initialized
+func (bl *blockListener) currentHighestBlock() uint64 {
+	bl.highestBlockMux.RLock()
+	defer bl.highestBlockMux.RUnlock()
+	return bl.highestBlock
+}
+
 func (bl *blockListener) waitClosed() {
 	bl.wsMux.Lock()
 	listenLoopDone := bl.listenLoopDone
@@ -0,0 +1,161 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package blockindexer
+
+import (
+	"context"
+
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly-signer/pkg/ethtypes"
+	"github.com/kaleido-io/paladin/core/internal/msgs"
+	"github.com/kaleido-io/paladin/toolkit/pkg/log"
+	"github.com/kaleido-io/paladin/toolkit/pkg/pldapi"
+	"gorm.io/gorm"
+)
+
+// Called by the dispatcher when the next confirmed block does not build on the last block we dispatched.
+// Works backwards through the indexed blocks to find the highest one that is still on the canonical chain,
+// then removes everything after it and rewinds any event stream checkpoints to that point.
+// The caller must reset the indexer afterwards, to restart from the new checkpoint.
+func (bi *blockIndexer) rewindToForkPoint(ctx context.Context) error {
+	bi.reorgsTotal.Inc()
+
+	highestIndexed, err := bi.getHighestIndexedBlock(ctx)
+	if err != nil {
+		return err
+	}
+	if highestIndexed == nil {
+		log.L(ctx).Infof("Chain re-org detected with no indexed blocks to roll back")
+		return nil
+	}
+
+	forkBlock, err := bi.findForkPoint(ctx, *highestIndexed)
+	if err != nil {
+		return err
+	}
+	if forkBlock == *highestIndexed {
+		// The re-org only affected blocks we had not yet committed
+		log.L(ctx).Infof("Chain re-org detected after highest indexed block %d", forkBlock)
+		return nil
+	}
+	log.L(ctx).Warnf("Rolling back indexed blocks %d-%d after chain re-org (fork block=%d)", forkBlock+1, *highestIndexed, forkBlock)
+
+	// Event streams must not be processing while we move their checkpoints
+	bi.eventStreamsLock.Lock()
+	rewindHandlers := append([]RewindHandler{}, bi.rewindHandlers...)
+	for _, es := range bi.eventStreams {
+		es.stop()
+		if es.rewindHandler != nil {
+			rewindHandlers = append(rewindHandlers, es.rewindHandler)
+		}
+	}
+	bi.eventStreamsLock.Unlock()
+
+	var postCommits []PostCommit
+	err = bi.retry.Do(ctx, func(attempt int) (retryable bool, err error) {
+		postCommits = nil
+		return true, bi.persistence.DB().Transaction(func(dbTX *gorm.DB) (err error) {
+			for _, rewindHandler := range rewindHandlers {
+				var postCommit PostCommit
+				postCommit, err = rewindHandler(ctx, dbTX, forkBlock)
+				if err != nil {
+					return err
+				}
+				postCommits = append(postCommits, postCommit)
+			}
+			return bi.deleteIndexedDataAfter(ctx, dbTX, forkBlock)
+		})
+	})
+	if err != nil {
+		return err
+	}
+	bi.highestConfirmedBlock.Store(forkBlock)
+	for _, postCommit := range postCommits {
+		if postCommit != nil {
+			postCommit()
+		}
+	}
+	return nil
+}
+
+func (bi *blockIndexer) deleteIndexedDataAfter(ctx context.Context, dbTX *gorm.DB, forkBlock int64) error {
+	err := dbTX.
+		WithContext(ctx).
+		Table("indexed_events").
+		Where("block_number > ?", forkBlock).
+		Delete(&pldapi.IndexedEvent{}).
+		Error
+	if err == nil {
+		err = dbTX.
+			WithContext(ctx).
+			Table("indexed_transactions").
+			Where("block_number > ?", forkBlock).
+			Delete(&pldapi.IndexedTransaction{}).
+			Error
+	}
+	if err == nil {
+		err = dbTX.
+			WithContext(ctx).
+			Table("indexed_blocks").
+			Where("number > ?", forkBlock).
+			Delete(&pldapi.IndexedBlock{}).
+			Error
+	}
+	if err == nil {
+		// Event streams will re-process all events from the new canonical chain after the fork
+		err = dbTX.
+			WithContext(ctx).
+			Table("event_stream_checkpoints").
+			Where("block_number > ?", forkBlock).
+			Update("block_number", forkBlock).
+			Error
+	}
+	return err
+}
+
+// Returns the highest indexed block that matches the block at the same height on the chain,
+// or -1 if none of our indexed blocks are on the current canonical chain.
+// Fails if more than the maximum re-org depth of blocks have been replaced.
+func (bi *blockIndexer) findForkPoint(ctx context.Context, highestIndexed int64) (int64, error) {
+	for blockNumber := highestIndexed; blockNumber >= 0; blockNumber-- {
+		if highestIndexed-blockNumber >= int64(bi.maxReorgDepth) {
+			return -1, i18n.NewError(ctx, msgs.MsgBlockIndexerReorgTooDeep, bi.maxReorgDepth, blockNumber+1)
+		}
+		var indexedBlock *pldapi.IndexedBlock
+		var chainBlock *BlockInfoJSONRPC
+		err := bi.retry.Do(ctx, func(attempt int) (retryable bool, err error) {
+			indexedBlock, err = bi.GetIndexedBlockByNumber(ctx, uint64(blockNumber))
+			if err == nil && indexedBlock != nil {
+				chainBlock, err = bi.blockListener.getBlockInfoByNumber(ctx, ethtypes.HexUint64(blockNumber))
+			}
+			return true, err
+		})
+		if err != nil {
+			return -1, err
+		}
+		if indexedBlock == nil {
+			// We have reached the first block we indexed
+			break
+		}
+		if chainBlock != nil && chainBlock.Hash.Equals(indexedBlock.Hash[:]) {
+			log.L(ctx).Infof("Indexed block %d / %s is on the canonical chain", blockNumber, indexedBlock.Hash)
+			return blockNumber, nil
+		}
+		log.L(ctx).Infof("Indexed block %d / %s has been replaced on the chain", blockNumber, indexedBlock.Hash)
+	}
+	return -1, nil
+}
@@ -0,0 +1,243 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package blockindexer
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/hyperledger/firefly-signer/pkg/ethtypes"
+	"github.com/kaleido-io/paladin/config/pkg/pldconf"
+	"github.com/kaleido-io/paladin/toolkit/pkg/pldapi"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestBlockIndexerRewindReorgBeyondConfirmations(t *testing.T) {
+	ctx, bi, mRPC, blDone := newTestBlockIndexer(t)
+	defer blDone()
+
+	bi.requiredConfirmations = 0
+
+	// The new chain shares the first 3 blocks, and is longer
+	blocksBeforeReorg, receipts := testBlockArray(t, 10)
+	blocksAfterReorg, receiptsAfterReorg := testBlockArray(t, 15)
+	for i := 0; i < 3; i++ {
+		blockCopy := *blocksBeforeReorg[i]
+		blocksAfterReorg[i] = &blockCopy
+	}
+	blocksAfterReorg[3].ParentHash = blocksAfterReorg[2].Hash
+	for h, r := range receiptsAfterReorg {
+		receipts[h] = r
+	}
+
+	var isAfterReorg atomic.Bool
+	mockBlocksRPCCallsDynamic(mRPC, func(args mock.Arguments) ([]*BlockInfoJSONRPC, map[string][]*TXReceiptJSONRPC) {
+		if isAfterReorg.Load() {
+			return blocksAfterReorg, receipts
+		}
+		return blocksBeforeReorg, receipts
+	})
+
+	// An event stream that has processed all the blocks on the old chain
+	streamID := uuid.New()
+	err := bi.persistence.DB().Table("event_streams").Create(&EventStream{
+		ID:      streamID,
+		Name:    "reorg_test",
+		Type:    EventStreamTypeExternal.Enum(),
+		Sources: EventSources{},
+	}).Error
+	require.NoError(t, err)
+	err = bi.persistence.DB().Table("event_stream_checkpoints").Create(&EventStreamCheckpoint{
+		Stream:      streamID,
+		BlockNumber: 9,
+	}).Error
+	require.NoError(t, err)
+
+	rewound := make(chan int64, 1)
+	bi.rewindHandlers = append(bi.rewindHandlers, func(ctx context.Context, dbTX *gorm.DB, forkBlock int64) (PostCommit, error) {
+		return func() { rewound <- forkBlock }, nil
+	})
+	utBatchNotify := make(chan []*pldapi.IndexedBlock)
+	addBlockPostCommit(bi, func(blocks []*pldapi.IndexedBlock) { utBatchNotify <- blocks })
+
+	bi.startOrReset() // do not start block listener
+
+	for i := 0; i < len(blocksBeforeReorg); i++ {
+		notifiedBlocks := <-utBatchNotify
+		checkIndexedBlockEqual(t, blocksBeforeReorg[i], notifiedBlocks[0])
+	}
+
+	// Switch to the new chain, and notify the next block
+	isAfterReorg.Store(true)
+	bi.blockListener.notifyBlock(blocksAfterReorg[10])
+
+	assert.Equal(t, int64(2), <-rewound)
+
+	// We re-index from the fork point on the new chain
+	for i := 3; i < len(blocksAfterReorg); i++ {
+		notifiedBlocks := <-utBatchNotify
+		checkIndexedBlockEqual(t, blocksAfterReorg[i], notifiedBlocks[0])
+	}
+	for i := 0; i < len(blocksAfterReorg); i++ {
+		indexed, err := bi.GetIndexedBlockByNumber(ctx, uint64(i))
+		require.NoError(t, err)
+		checkIndexedBlockEqual(t, blocksAfterReorg[i], indexed)
+		txns, err := bi.GetBlockTransactionsByNumber(ctx, int64(i))
+		require.NoError(t, err)
+		require.Len(t, txns, 1)
+		assert.Equal(t, blocksAfterReorg[i].Transactions[0].Hash.String(), txns[0].Hash.String())
+	}
+
+	var checkpoints []*EventStreamCheckpoint
+	err = bi.persistence.DB().Table("event_stream_checkpoints").Where("stream = ?", streamID).Find(&checkpoints).Error
+	require.NoError(t, err)
+	require.Len(t, checkpoints, 1)
+	assert.Equal(t, int64(2), checkpoints[0].BlockNumber)
+
+	assert.Equal(t, float64(1), testutil.ToFloat64(bi.reorgsTotal))
+}
+
+func TestRewindToForkPointNoIndexedBlocks(t *testing.T) {
+	ctx, bi, _, blDone := newTestBlockIndexer(t)
+	defer blDone()
+
+	err := bi.rewindToForkPoint(ctx)
+	require.NoError(t, err)
+}
+
+func TestRewindToForkPointAllBlocksReplaced(t *testing.T) {
+	ctx, bi, mRPC, blDone := newTestBlockIndexer(t)
+	defer blDone()
+
+	blocks, _ := testBlockArray(t, 3)
+	for _, b := range blocks {
+		err := bi.persistence.DB().Table("indexed_blocks").Create(&pldapi.IndexedBlock{
+			Number: int64(b.Number),
+			Hash:   [32]byte(b.Hash),
+		}).Error
+		require.NoError(t, err)
+	}
+	newBlocks, receipts := testBlockArray(t, 3)
+	mockBlocksRPCCalls(mRPC, newBlocks, receipts)
+
+	var forkBlock int64
+	bi.rewindHandlers = append(bi.rewindHandlers, func(ctx context.Context, dbTX *gorm.DB, fb int64) (PostCommit, error) {
+		forkBlock = fb
+		return nil, nil
+	})
+
+	err := bi.rewindToForkPoint(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(-1), forkBlock)
+	assert.Equal(t, int64(-1), bi.highestConfirmedBlock.Load())
+
+	highest, err := bi.getHighestIndexedBlock(ctx)
+	require.NoError(t, err)
+	assert.Nil(t, highest)
+}
+
+func TestRewindToForkPointTooDeep(t *testing.T) {
+	ctx, bi, mRPC, blDone := newTestBlockIndexer(t)
+	defer blDone()
+	bi.maxReorgDepth = 2
+
+	blocks, _ := testBlockArray(t, 3)
+	for _, b := range blocks {
+		err := bi.persistence.DB().Table("indexed_blocks").Create(&pldapi.IndexedBlock{
+			Number: int64(b.Number),
+			Hash:   [32]byte(b.Hash),
+		}).Error
+		require.NoError(t, err)
+	}
+	newBlocks, receipts := testBlockArray(t, 3)
+	mockBlocksRPCCalls(mRPC, newBlocks, receipts)
+
+	bi.rewindHandlers = append(bi.rewindHandlers, func(ctx context.Context, dbTX *gorm.DB, fb int64) (PostCommit, error) {
+		assert.Fail(t, "should not be called")
+		return nil, nil
+	})
+
+	err := bi.rewindToForkPoint(ctx)
+	assert.Regexp(t, "PD011315.*2.*1", err)
+
+	// Nothing is removed
+	highest, err := bi.getHighestIndexedBlock(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), *highest)
+}
+
+func TestRewindToForkPointNoRollbackRequired(t *testing.T) {
+	ctx, bi, mRPC, blDone := newTestBlockIndexer(t)
+	defer blDone()
+
+	blocks, receipts := testBlockArray(t, 3)
+	mockBlocksRPCCalls(mRPC, blocks, receipts)
+	err := bi.persistence.DB().Table("indexed_blocks").Create(&pldapi.IndexedBlock{
+		Number: 2,
+		Hash:   [32]byte(blocks[2].Hash),
+	}).Error
+	require.NoError(t, err)
+
+	bi.rewindHandlers = append(bi.rewindHandlers, func(ctx context.Context, dbTX *gorm.DB, fb int64) (PostCommit, error) {
+		assert.Fail(t, "should not be called")
+		return nil, nil
+	})
+
+	err = bi.rewindToForkPoint(ctx)
+	require.NoError(t, err)
+}
+
+func TestRewindToForkPointHandlerFail(t *testing.T) {
+	ctx, bi, mRPC, blDone := newTestBlockIndexer(t)
+	defer blDone()
+
+	blocks, receipts := testBlockArray(t, 3)
+	mockBlocksRPCCalls(mRPC, blocks, receipts)
+	err := bi.persistence.DB().Table("indexed_blocks").Create(&pldapi.IndexedBlock{
+		Number: 2,
+		Hash:   [32]byte(ethtypes.MustNewHexBytes0xPrefix(fmt.Sprintf("0x%064x", 12345))),
+	}).Error
+	require.NoError(t, err)
+
+	cancelCtx, cancel := context.WithCancel(ctx)
+	bi.rewindHandlers = append(bi.rewindHandlers, func(ctx context.Context, dbTX *gorm.DB, fb int64) (PostCommit, error) {
+		cancel()
+		return nil, fmt.Errorf("pop")
+	})
+
+	err = bi.rewindToForkPoint(cancelCtx)
+	assert.Regexp(t, "PD020000", err)
+}
+
+func TestRewindToForkPointQueryFail(t *testing.T) {
+	ctx, bi, _, p, done := newMockBlockIndexer(t, &pldconf.BlockIndexerConfig{})
+	defer done()
+
+	p.Mock.ExpectQuery("SELECT.*indexed_blocks").WillReturnError(fmt.Errorf("pop"))
+
+	cancelCtx, cancel := context.WithCancel(ctx)
+	cancel()
+	err := bi.rewindToForkPoint(cancelCtx)
+	assert.Regexp(t, "PD020000", err)
+}
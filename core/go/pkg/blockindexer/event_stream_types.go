@@ -18,6 +18,7 @@ package blockindexer
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"sort"
 
@@ -32,8 +33,9 @@ import (
 )
 
 type EventStreamConfig struct {
-	BatchSize    *int    `json:"batchSize,omitempty"`
-	BatchTimeout *string `json:"batchTimeout,omitempty"`
+	BatchSize    *int            `json:"batchSize,omitempty"`
+	BatchTimeout *string         `json:"batchTimeout,omitempty"`
+	FromBlock    json.RawMessage `json:"fromBlock,omitempty"` // external streams only - used to set the initial checkpoint on creation
 }
 
 var EventStreamDefaults = &EventStreamConfig{
@@ -45,11 +47,13 @@ type EventStreamType string
 
 const (
 	EventStreamTypeInternal EventStreamType = "internal" // a core Paladin component, such as the state confirmation engine
+	EventStreamTypeExternal EventStreamType = "external" // created over the JSON/RPC API, and delivered to WebSocket subscribers
 )
 
 func (est EventStreamType) Options() []string {
 	return []string{
 		string(EventStreamTypeInternal),
+		string(EventStreamTypeExternal),
 	}
 }
 func (est EventStreamType) Enum() tktypes.Enum[EventStreamType] {
@@ -65,6 +69,7 @@ type EventStream struct {
 	Config  EventStreamConfig             `json:"config"         gorm:"type:bytes;serializer:json"`
 	Sources EventSources                  `json:"sources"        gorm:"serializer:json"` // immutable (event delivery behavior would be too undefined with mutability)
 	Format  tktypes.JSONFormatOptions     `json:"format"`
+	Started *bool                         `json:"started,omitempty"` // only external streams can be stopped
 }
 
 type EventSources []EventStreamSource
@@ -119,6 +124,17 @@ type EventDeliveryBatch struct {
 	Events     []*pldapi.EventWithData `json:"events"`
 }
 
+// Receives batches of events from an external event stream. Each batch must be
+// confirmed by returning nil, before the next batch is delivered.
+// Returning an error (including a nack from a remote receiver) results in redelivery.
+type EventStreamReceiver interface {
+	DeliverEventBatch(ctx context.Context, batchID uuid.UUID, events []*pldapi.EventWithData) error
+}
+
+type EventStreamReceiverCloser interface {
+	Close()
+}
+
 // Post commit callback is invoked after the DB transaction completes (only on success)
 type PostCommit func()
 
@@ -126,6 +142,12 @@ type PreCommitHandler func(ctx context.Context, dbTX *gorm.DB, blocks []*pldapi.
 
 type InternalStreamCallback func(ctx context.Context, dbTX *gorm.DB, batch *EventDeliveryBatch) (PostCommit, error)
 
+// Rewind handler is invoked when a chain re-org is detected that is deeper than the required confirmations,
+// WITHIN the database transaction that removes all indexed blocks after the fork point.
+// Any data written by the component for blocks with a number greater than forkBlock must be reverted.
+// forkBlock is -1 if all indexed blocks have been removed.
+type RewindHandler func(ctx context.Context, dbTX *gorm.DB, forkBlock int64) (PostCommit, error)
+
 type IESType int
 
 const (
@@ -143,4 +165,5 @@ type InternalEventStream struct {
 	Definition       *EventStream
 	Handler          InternalStreamCallback
 	PreCommitHandler PreCommitHandler
+	RewindHandler    RewindHandler // optional for either type
 }
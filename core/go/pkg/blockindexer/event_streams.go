@@ -28,6 +28,7 @@ import (
 	"github.com/kaleido-io/paladin/config/pkg/confutil"
 	"github.com/kaleido-io/paladin/toolkit/pkg/log"
 	"github.com/kaleido-io/paladin/toolkit/pkg/pldapi"
+	"github.com/kaleido-io/paladin/toolkit/pkg/rpcserver"
 	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
@@ -45,9 +46,13 @@ type eventStream struct {
 	blocks         chan *eventStreamBlock
 	dispatch       chan *eventDispatch
 	handler        InternalStreamCallback
+	rewindHandler  RewindHandler
 	serializer     *abi.Serializer
 	detectorDone   chan struct{}
 	dispatcherDone chan struct{}
+
+	// external event streams deliver to receivers attached over the API
+	receivers *rpcserver.RPCEventReceivers[EventStreamReceiver]
 }
 
 type eventBatch struct {
@@ -86,7 +91,7 @@ func (bi *blockIndexer) loadEventStreams(ctx context.Context) error {
 	}
 
 	for _, esDefinition := range eventStreams {
-		bi.initEventStream(ctx, esDefinition, nil /* no handler at this point */)
+		bi.initEventStream(ctx, esDefinition, nil /* no handler at this point */, nil)
 	}
 	return nil
 }
@@ -99,7 +104,7 @@ func (bi *blockIndexer) upsertInternalEventStream(ctx context.Context, ies *Inte
 		def = &EventStream{}
 	}
 
-	// This will need to open up when we have more externally consumable event streams
+	// External event streams are managed separately, via the API
 	def.Type = EventStreamTypeInternal.Enum()
 
 	// Validate the name
@@ -165,13 +170,13 @@ func (bi *blockIndexer) upsertInternalEventStream(ctx context.Context, ies *Inte
 
 	// We call init here
 	// TODO: Full stop/start lifecycle
-	es := bi.initEventStream(ctx, def, ies.Handler)
+	es := bi.initEventStream(ctx, def, ies.Handler, ies.RewindHandler)
 
 	return es, nil
 }
 
 // Note that the event stream must be stopped when this is called
-func (bi *blockIndexer) initEventStream(ctx context.Context, definition *EventStream, handler InternalStreamCallback) *eventStream {
+func (bi *blockIndexer) initEventStream(ctx context.Context, definition *EventStream, handler InternalStreamCallback, rewindHandler RewindHandler) *eventStream {
 	bi.eventStreamsLock.Lock()
 	defer bi.eventStreamsLock.Unlock()
 
@@ -189,6 +194,7 @@ func (bi *blockIndexer) initEventStream(ctx context.Context, definition *EventSt
 			blocks:     make(chan *eventStreamBlock, bi.esBlockDispatchQueueLength),
 			dispatch:   make(chan *eventDispatch, batchSize),
 			serializer: definition.Format.GetABISerializerIgnoreErrors(ctx),
+			receivers:  rpcserver.NewRPCEventReceivers[EventStreamReceiver](),
 		}
 	}
 
@@ -198,6 +204,7 @@ func (bi *blockIndexer) initEventStream(ctx context.Context, definition *EventSt
 
 	// Note the handler will be nil when this is first called on startup before we've been passed handlers.
 	es.handler = handler
+	es.rewindHandler = rewindHandler
 
 	// Calculate all the signatures we require
 	for _, source := range definition.Sources {
@@ -249,8 +256,18 @@ func (bi *blockIndexer) startEventStream(es *eventStream) {
 	es.start()
 }
 
+func (es *eventStream) isExternal() bool {
+	return es.definition.Type.V() == EventStreamTypeExternal
+}
+
 func (es *eventStream) start() {
-	if es.handler != nil && es.detectorDone == nil && es.dispatcherDone == nil {
+	// Internal event streams cannot start until their handler is registered.
+	// External event streams deliver to receivers, and can be stopped via the API.
+	canStart := es.handler != nil
+	if es.isExternal() {
+		canStart = es.definition.Started == nil || *es.definition.Started
+	}
+	if canStart && es.detectorDone == nil && es.dispatcherDone == nil {
 		es.ctx, es.cancelCtx = context.WithCancel(log.WithLogField(es.bi.parentCtxForReset, "eventstream", es.definition.ID.String()))
 		log.L(es.ctx).Infof("Starting event stream %s [%s]", es.definition.Name, es.definition.ID)
 		es.detectorDone = make(chan struct{})
@@ -266,9 +283,11 @@ func (es *eventStream) stop() {
 	}
 	if es.detectorDone != nil {
 		<-es.detectorDone
+		es.detectorDone = nil
 	}
 	if es.dispatcherDone != nil {
 		<-es.dispatcherDone
+		es.dispatcherDone = nil
 	}
 }
 
@@ -485,13 +504,23 @@ func (es *eventStream) dispatcher() {
 
 func (es *eventStream) runBatch(batch *eventBatch) error {
 
+	// External event streams must have the batch acknowledged by a receiver, before we
+	// update the checkpoint. We do not hold a DB transaction open while we wait for that.
+	if es.isExternal() {
+		if err := es.deliverExternalBatch(batch); err != nil {
+			return err
+		}
+	}
+
 	// We start a database transaction, run the callback function
 	return es.bi.retry.Do(es.ctx, func(attempt int) (retryable bool, err error) {
 		var postCommit PostCommit
 		err = es.bi.persistence.DB().Transaction(func(tx *gorm.DB) (err error) {
-			postCommit, err = es.handler(es.ctx, tx, &batch.EventDeliveryBatch)
-			if err != nil {
-				return err
+			if es.handler != nil {
+				postCommit, err = es.handler(es.ctx, tx, &batch.EventDeliveryBatch)
+				if err != nil {
+					return err
+				}
 			}
 			// commit the checkpoint
 			return tx.
@@ -0,0 +1,304 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package blockindexer
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/google/uuid"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly-signer/pkg/abi"
+	"github.com/kaleido-io/paladin/config/pkg/confutil"
+	"github.com/kaleido-io/paladin/core/internal/filters"
+	"github.com/kaleido-io/paladin/core/internal/msgs"
+	"github.com/kaleido-io/paladin/toolkit/pkg/log"
+	"github.com/kaleido-io/paladin/toolkit/pkg/pldapi"
+	"github.com/kaleido-io/paladin/toolkit/pkg/query"
+	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
+	"gorm.io/gorm"
+)
+
+var EventStreamFilters filters.FieldSet = filters.FieldMap{
+	"id":      filters.UUIDField("id"),
+	"name":    filters.StringField("name"),
+	"created": filters.TimestampField("created"),
+	"updated": filters.TimestampField("updated"),
+	"started": filters.BooleanField("started"),
+}
+
+func externalEventStreamToAPI(def *EventStream) *pldapi.EventStream {
+	sources := make([]pldapi.EventStreamSource, len(def.Sources))
+	for i, s := range def.Sources {
+		sources[i] = pldapi.EventStreamSource{ABI: s.ABI, Address: s.Address}
+	}
+	return &pldapi.EventStream{
+		ID:      def.ID,
+		Name:    def.Name,
+		Created: def.Created,
+		Started: confutil.P(def.Started == nil || *def.Started),
+		Sources: sources,
+		Config: pldapi.EventStreamConfig{
+			BatchSize:    def.Config.BatchSize,
+			BatchTimeout: def.Config.BatchTimeout,
+			FromBlock:    tktypes.RawJSON(def.Config.FromBlock),
+		},
+		Format: def.Format,
+	}
+}
+
+// MUST be called under the eventStreamsLock
+func (bi *blockIndexer) getExternalEventStream(name string) *eventStream {
+	for _, es := range bi.eventStreams {
+		if es.isExternal() && es.definition.Name == name {
+			return es
+		}
+	}
+	return nil
+}
+
+// MUST be called under the eventStreamsLock
+func (bi *blockIndexer) getExternalEventStreamOrErr(ctx context.Context, name string) (*eventStream, error) {
+	es := bi.getExternalEventStream(name)
+	if es == nil {
+		return nil, i18n.NewError(ctx, msgs.MsgBlockIndexerESNotFound, name)
+	}
+	return es, nil
+}
+
+func (bi *blockIndexer) isStarted() bool {
+	bi.stateLock.Lock()
+	defer bi.stateLock.Unlock()
+	return bi.started
+}
+
+func (bi *blockIndexer) CreateEventStream(ctx context.Context, spec *pldapi.EventStream) (*pldapi.EventStream, error) {
+
+	if err := tktypes.ValidateSafeCharsStartEndAlphaNum(ctx, spec.Name, tktypes.DefaultNameMaxLen, "name"); err != nil {
+		return nil, err
+	}
+	if _, err := spec.Format.GetABISerializer(ctx); err != nil {
+		return nil, err
+	}
+
+	def := &EventStream{
+		ID:   uuid.New(),
+		Name: spec.Name,
+		Type: EventStreamTypeExternal.Enum(),
+		Config: EventStreamConfig{
+			BatchSize:    spec.Config.BatchSize,
+			BatchTimeout: spec.Config.BatchTimeout,
+			FromBlock:    json.RawMessage(spec.Config.FromBlock),
+		},
+		Format:  spec.Format,
+		Started: confutil.P(spec.Started == nil || *spec.Started),
+	}
+	eventCount := 0
+	for _, s := range spec.Sources {
+		for _, abiEntry := range s.ABI {
+			if abiEntry.Type == abi.Event {
+				eventCount++
+			}
+		}
+		def.Sources = append(def.Sources, EventStreamSource{ABI: s.ABI, Address: s.Address})
+	}
+	if eventCount == 0 {
+		return nil, i18n.NewError(ctx, msgs.MsgBlockIndexerESNoEvents)
+	}
+
+	// The from block is only used to set the initial checkpoint of the stream.
+	// Without one, the stream starts from the earliest indexed block.
+	var initialCheckpoint *int64
+	if len(spec.Config.FromBlock) > 0 {
+		fromBlock, err := parseFromBlock(ctx, json.RawMessage(spec.Config.FromBlock))
+		if err != nil {
+			return nil, err
+		}
+		if fromBlock == nil {
+			// "latest" means events in blocks after the current confirmed head of the index
+			if highest := bi.highestConfirmedBlock.Load(); highest >= 0 {
+				initialCheckpoint = &highest
+			}
+		} else if *fromBlock > 0 {
+			initialCheckpoint = confutil.P(int64(*fromBlock) - 1)
+		}
+	}
+
+	bi.eventStreamsLock.Lock()
+	existing := bi.getExternalEventStream(def.Name)
+	bi.eventStreamsLock.Unlock()
+	if existing != nil {
+		return nil, i18n.NewError(ctx, msgs.MsgBlockIndexerESExists, def.Name)
+	}
+
+	err := bi.persistence.DB().Transaction(func(dbTX *gorm.DB) error {
+		err := dbTX.
+			Table("event_streams").
+			WithContext(ctx).
+			Create(def).
+			Error
+		if err == nil && initialCheckpoint != nil {
+			err = dbTX.
+				Table("event_stream_checkpoints").
+				WithContext(ctx).
+				Create(&EventStreamCheckpoint{
+					Stream:      def.ID,
+					BlockNumber: *initialCheckpoint,
+				}).
+				Error
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	log.L(ctx).Infof("Created external event stream %s [%s]", def.Name, def.ID)
+
+	es := bi.initEventStream(ctx, def, nil /* external streams deliver to receivers */, nil)
+	if bi.isStarted() {
+		bi.startEventStream(es)
+	}
+	return externalEventStreamToAPI(def), nil
+}
+
+func (bi *blockIndexer) GetEventStream(ctx context.Context, name string) (*pldapi.EventStream, error) {
+	bi.eventStreamsLock.Lock()
+	defer bi.eventStreamsLock.Unlock()
+
+	es := bi.getExternalEventStream(name)
+	if es == nil {
+		return nil, nil
+	}
+	return externalEventStreamToAPI(es.definition), nil
+}
+
+func (bi *blockIndexer) QueryEventStreams(ctx context.Context, jq *query.QueryJSON) ([]*pldapi.EventStream, error) {
+
+	if jq.Limit == nil || *jq.Limit == 0 {
+		return nil, i18n.NewError(ctx, msgs.MsgBlockIndexerLimitRequired)
+	}
+	q := bi.persistence.DB().
+		Table("event_streams").
+		Where("type = ?", EventStreamTypeExternal.Enum()).
+		WithContext(ctx)
+	q = filters.BuildGORM(ctx, jq, q, EventStreamFilters)
+	var results []*EventStream
+	err := q.Find(&results).Error
+	if err != nil {
+		return nil, err
+	}
+	streams := make([]*pldapi.EventStream, len(results))
+	for i, def := range results {
+		streams[i] = externalEventStreamToAPI(def)
+	}
+	return streams, nil
+}
+
+func (bi *blockIndexer) setEventStreamStarted(ctx context.Context, name string, started bool) error {
+	biStarted := bi.isStarted()
+
+	bi.eventStreamsLock.Lock()
+	defer bi.eventStreamsLock.Unlock()
+
+	es, err := bi.getExternalEventStreamOrErr(ctx, name)
+	if err != nil {
+		return err
+	}
+	err = bi.persistence.DB().
+		Table("event_streams").
+		Where("id = ?", es.definition.ID).
+		WithContext(ctx).
+		Update("started", started).
+		Error
+	if err != nil {
+		return err
+	}
+	es.definition.Started = &started
+	if !started {
+		es.stop()
+	} else if biStarted {
+		es.start()
+	}
+	return nil
+}
+
+func (bi *blockIndexer) StartEventStream(ctx context.Context, name string) error {
+	return bi.setEventStreamStarted(ctx, name, true)
+}
+
+func (bi *blockIndexer) StopEventStream(ctx context.Context, name string) error {
+	return bi.setEventStreamStarted(ctx, name, false)
+}
+
+func (bi *blockIndexer) DeleteEventStream(ctx context.Context, name string) error {
+	bi.eventStreamsLock.Lock()
+	defer bi.eventStreamsLock.Unlock()
+
+	es, err := bi.getExternalEventStreamOrErr(ctx, name)
+	if err != nil {
+		return err
+	}
+	es.stop()
+
+	err = bi.persistence.DB().Transaction(func(dbTX *gorm.DB) error {
+		// We delete the checkpoint explicitly, rather than relying on a cascade
+		err := dbTX.
+			Table("event_stream_checkpoints").
+			Where("stream = ?", es.definition.ID).
+			WithContext(ctx).
+			Delete(&EventStreamCheckpoint{}).
+			Error
+		if err == nil {
+			err = dbTX.
+				Table("event_streams").
+				Where("id = ?", es.definition.ID).
+				WithContext(ctx).
+				Delete(&EventStream{}).
+				Error
+		}
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	delete(bi.eventStreams, es.definition.ID)
+	log.L(ctx).Infof("Deleted external event stream %s [%s]", es.definition.Name, es.definition.ID)
+	return nil
+}
+
+func (bi *blockIndexer) AddEventStreamReceiver(ctx context.Context, name string, r EventStreamReceiver) (EventStreamReceiverCloser, error) {
+	bi.eventStreamsLock.Lock()
+	defer bi.eventStreamsLock.Unlock()
+
+	es, err := bi.getExternalEventStreamOrErr(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return es.receivers.AddReceiver(r), nil
+}
+
+func (es *eventStream) deliverExternalBatch(batch *eventBatch) error {
+	// Failure to deliver (including a nack) results in redelivery of the same batch
+	return es.bi.retry.Do(es.ctx, func(attempt int) (retryable bool, err error) {
+		r, err := es.receivers.WaitForReceiver(es.ctx)
+		if err != nil {
+			return false, err
+		}
+		log.L(es.ctx).Debugf("Delivering event batch %s (events=%d)", batch.BatchID, len(batch.Events))
+		return true, r.DeliverEventBatch(es.ctx, batch.BatchID, batch.Events)
+	})
+}
@@ -0,0 +1,321 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package blockindexer
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/hyperledger/firefly-signer/pkg/abi"
+	"github.com/kaleido-io/paladin/config/pkg/confutil"
+	"github.com/kaleido-io/paladin/config/pkg/pldconf"
+	"github.com/kaleido-io/paladin/toolkit/pkg/pldapi"
+	"github.com/kaleido-io/paladin/toolkit/pkg/query"
+	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testEventReceiver func(ctx context.Context, batchID uuid.UUID, events []*pldapi.EventWithData) error
+
+func (tr testEventReceiver) DeliverEventBatch(ctx context.Context, batchID uuid.UUID, events []*pldapi.EventWithData) error {
+	return tr(ctx, batchID, events)
+}
+
+func testExternalStream(name string) *pldapi.EventStream {
+	return &pldapi.EventStream{
+		Name: name,
+		Config: pldapi.EventStreamConfig{
+			BatchSize:    confutil.P(3),
+			BatchTimeout: confutil.P("5ms"),
+		},
+		// Listen to one of the three event types
+		Sources: []pldapi.EventStreamSource{{
+			ABI: abi.ABI{testABI[1]},
+		}},
+	}
+}
+
+func getTestCheckpoint(t *testing.T, bi *blockIndexer, streamID uuid.UUID) *int64 {
+	var checkpoints []*EventStreamCheckpoint
+	err := bi.persistence.DB().
+		Table("event_stream_checkpoints").
+		Where("stream = ?", streamID).
+		Find(&checkpoints).
+		Error
+	require.NoError(t, err)
+	if len(checkpoints) == 0 {
+		return nil
+	}
+	return &checkpoints[0].BlockNumber
+}
+
+func TestExternalEventStreamDeliveryWithNack(t *testing.T) {
+
+	// This test uses a real DB, includes the full block indexer, but simulates the blockchain.
+	ctx, bi, mRPC, blDone := newTestBlockIndexer(t)
+	defer blDone()
+
+	// Mock up the block calls to the blockchain for 15 blocks
+	blocks, receipts := testBlockArray(t, 15)
+	mockBlocksRPCCalls(mRPC, blocks, receipts)
+	mockBlockListenerNil(mRPC)
+
+	// Create the stream before the block indexer starts
+	es, err := bi.CreateEventStream(ctx, testExternalStream("stream1"))
+	require.NoError(t, err)
+	assert.True(t, *es.Started)
+	assert.NotEqual(t, uuid.UUID{}, es.ID)
+
+	err = bi.Start()
+	require.NoError(t, err)
+
+	// Nack the first batch, then accept everything
+	eventCollector := make(chan *pldapi.EventWithData)
+	batches := 0
+	var firstBatchID uuid.UUID
+	rc, err := bi.AddEventStreamReceiver(ctx, "stream1", testEventReceiver(func(ctx context.Context, batchID uuid.UUID, events []*pldapi.EventWithData) error {
+		batches++
+		assert.LessOrEqual(t, len(events), 3)
+		if batches == 1 {
+			firstBatchID = batchID
+			return fmt.Errorf("pop")
+		}
+		if batches == 2 {
+			// Redelivery of the same batch
+			assert.Equal(t, firstBatchID, batchID)
+		}
+		for _, e := range events {
+			select {
+			case eventCollector <- e:
+			case <-ctx.Done():
+			}
+		}
+		return nil
+	}))
+	require.NoError(t, err)
+	defer rc.Close()
+
+	for i := 0; i < len(blocks); i++ {
+		e := <-eventCollector
+		assert.Equal(t, int64(i), e.BlockNumber)
+		assert.JSONEq(t, fmt.Sprintf(`{
+			"intParam1": "%d",
+			"strParam2": "event_b_in_block_%d"
+		}`, 1000000+i, i), string(e.Data))
+	}
+
+	// Checkpoint gets written after the last batch is acknowledged
+	for {
+		cp := getTestCheckpoint(t, bi, es.ID)
+		if cp != nil && *cp == int64(len(blocks)-1) {
+			break
+		}
+		time.Sleep(1 * time.Millisecond)
+	}
+
+	// Stop, and check the status is reflected
+	err = bi.StopEventStream(ctx, "stream1")
+	require.NoError(t, err)
+	es, err = bi.GetEventStream(ctx, "stream1")
+	require.NoError(t, err)
+	assert.False(t, *es.Started)
+	streams, err := bi.QueryEventStreams(ctx, query.NewQueryBuilder().Equal("started", false).Limit(10).Query())
+	require.NoError(t, err)
+	require.Len(t, streams, 1)
+	assert.Equal(t, "stream1", streams[0].Name)
+	assert.Len(t, streams[0].Sources, 1)
+
+	// Restart it
+	err = bi.StartEventStream(ctx, "stream1")
+	require.NoError(t, err)
+	es, err = bi.GetEventStream(ctx, "stream1")
+	require.NoError(t, err)
+	assert.True(t, *es.Started)
+
+	// Delete it
+	err = bi.DeleteEventStream(ctx, "stream1")
+	require.NoError(t, err)
+	es, err = bi.GetEventStream(ctx, "stream1")
+	require.NoError(t, err)
+	assert.Nil(t, es)
+	streams, err = bi.QueryEventStreams(ctx, query.NewQueryBuilder().Limit(10).Query())
+	require.NoError(t, err)
+	assert.Empty(t, streams)
+
+}
+
+func TestExternalEventStreamLoadedOnRestartStopped(t *testing.T) {
+	ctx, bi, _, blDone := newTestBlockIndexer(t)
+	defer blDone()
+
+	spec := testExternalStream("stream1")
+	spec.Started = confutil.P(false)
+	_, err := bi.CreateEventStream(ctx, spec)
+	require.NoError(t, err)
+
+	// Clear out the in-memory state, and re-load from the DB
+	bi.eventStreams = make(map[uuid.UUID]*eventStream)
+	err = bi.loadEventStreams(ctx)
+	require.NoError(t, err)
+
+	bi.eventStreamsLock.Lock()
+	es := bi.getExternalEventStream("stream1")
+	bi.eventStreamsLock.Unlock()
+	require.NotNil(t, es)
+	assert.False(t, *es.definition.Started)
+
+	// Does not start, as it is stopped
+	es.start()
+	assert.Nil(t, es.detectorDone)
+}
+
+func TestExternalEventStreamFromBlock(t *testing.T) {
+	ctx, bi, _, blDone := newTestBlockIndexer(t)
+	defer blDone()
+
+	spec := testExternalStream("from5")
+	spec.Config.FromBlock = tktypes.RawJSON(`5`)
+	es, err := bi.CreateEventStream(ctx, spec)
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), *getTestCheckpoint(t, bi, es.ID))
+
+	spec = testExternalStream("from0")
+	spec.Config.FromBlock = tktypes.RawJSON(`"0x0"`)
+	es, err = bi.CreateEventStream(ctx, spec)
+	require.NoError(t, err)
+	assert.Nil(t, getTestCheckpoint(t, bi, es.ID))
+
+	spec = testExternalStream("latestnoblocks")
+	spec.Config.FromBlock = tktypes.RawJSON(`"latest"`)
+	es, err = bi.CreateEventStream(ctx, spec)
+	require.NoError(t, err)
+	assert.Nil(t, getTestCheckpoint(t, bi, es.ID))
+
+	bi.highestConfirmedBlock.Store(10)
+	spec = testExternalStream("latest")
+	spec.Config.FromBlock = tktypes.RawJSON(`"latest"`)
+	es, err = bi.CreateEventStream(ctx, spec)
+	require.NoError(t, err)
+	assert.Equal(t, int64(10), *getTestCheckpoint(t, bi, es.ID))
+	assert.JSONEq(t, `"latest"`, string(es.Config.FromBlock))
+
+	spec = testExternalStream("bad")
+	spec.Config.FromBlock = tktypes.RawJSON(`"pending"`)
+	_, err = bi.CreateEventStream(ctx, spec)
+	assert.Regexp(t, "PD011300", err)
+}
+
+func TestExternalEventStreamCreateErrors(t *testing.T) {
+	ctx, bi, _, blDone := newTestBlockIndexer(t)
+	defer blDone()
+
+	_, err := bi.CreateEventStream(ctx, testExternalStream(""))
+	assert.Regexp(t, "PD020005", err)
+
+	spec := testExternalStream("stream1")
+	spec.Format = "wrong"
+	_, err = bi.CreateEventStream(ctx, spec)
+	assert.Error(t, err)
+
+	spec = testExternalStream("stream1")
+	spec.Sources = []pldapi.EventStreamSource{{ABI: abi.ABI{{Type: abi.Function, Name: "notAnEvent"}}}}
+	_, err = bi.CreateEventStream(ctx, spec)
+	assert.Regexp(t, "PD011314", err)
+
+	_, err = bi.CreateEventStream(ctx, testExternalStream("stream1"))
+	require.NoError(t, err)
+	_, err = bi.CreateEventStream(ctx, testExternalStream("stream1"))
+	assert.Regexp(t, "PD011313", err)
+}
+
+func TestExternalEventStreamNotFound(t *testing.T) {
+	ctx, bi, _, blDone := newTestBlockIndexer(t)
+	defer blDone()
+
+	err := bi.StartEventStream(ctx, "unknown")
+	assert.Regexp(t, "PD011312", err)
+
+	err = bi.StopEventStream(ctx, "unknown")
+	assert.Regexp(t, "PD011312", err)
+
+	err = bi.DeleteEventStream(ctx, "unknown")
+	assert.Regexp(t, "PD011312", err)
+
+	_, err = bi.AddEventStreamReceiver(ctx, "unknown", nil)
+	assert.Regexp(t, "PD011312", err)
+
+	_, err = bi.QueryEventStreams(ctx, query.NewQueryBuilder().Query())
+	assert.Regexp(t, "PD011311", err)
+}
+
+func TestExternalEventStreamDBErrors(t *testing.T) {
+	ctx, bi, _, p, done := newMockBlockIndexer(t, &pldconf.BlockIndexerConfig{})
+	defer done()
+
+	p.Mock.ExpectBegin()
+	p.Mock.ExpectExec("INSERT.*event_streams").WillReturnError(fmt.Errorf("pop"))
+	p.Mock.ExpectRollback()
+	_, err := bi.CreateEventStream(ctx, testExternalStream("stream1"))
+	assert.Regexp(t, "pop", err)
+
+	p.Mock.ExpectQuery("SELECT.*event_streams").WillReturnError(fmt.Errorf("pop"))
+	_, err = bi.QueryEventStreams(ctx, query.NewQueryBuilder().Limit(1).Query())
+	assert.Regexp(t, "pop", err)
+
+	// Put one in memory to work on
+	def := &EventStream{ID: uuid.New(), Name: "stream1", Type: EventStreamTypeExternal.Enum()}
+	bi.initEventStream(ctx, def, nil, nil)
+
+	p.Mock.ExpectExec("UPDATE.*event_streams").WillReturnError(fmt.Errorf("pop"))
+	err = bi.StopEventStream(ctx, "stream1")
+	assert.Regexp(t, "pop", err)
+
+	p.Mock.ExpectBegin()
+	p.Mock.ExpectExec("DELETE.*event_stream_checkpoints").WillReturnError(fmt.Errorf("pop"))
+	p.Mock.ExpectRollback()
+	err = bi.DeleteEventStream(ctx, "stream1")
+	assert.Regexp(t, "pop", err)
+
+	require.NoError(t, p.Mock.ExpectationsWereMet())
+}
+
+func TestExternalEventStreamStopWhileWaitingForReceiver(t *testing.T) {
+	ctx, bi, _, p, done := newMockBlockIndexer(t, &pldconf.BlockIndexerConfig{})
+	defer done()
+
+	def := &EventStream{ID: uuid.New(), Name: "stream1", Type: EventStreamTypeExternal.Enum()}
+	es := bi.initEventStream(ctx, def, nil, nil)
+	es.ctx, es.cancelCtx = context.WithCancel(ctx)
+
+	p.Mock.ExpectExec("UPDATE.*event_streams").WillReturnResult(sqlmock.NewResult(0, 1))
+
+	delivered := make(chan error)
+	go func() {
+		delivered <- es.runBatch(&eventBatch{
+			EventDeliveryBatch: EventDeliveryBatch{BatchID: uuid.New()},
+		})
+	}()
+
+	err := bi.StopEventStream(ctx, "stream1")
+	require.NoError(t, err)
+	assert.Regexp(t, "PD020000", <-delivered)
+}
@@ -0,0 +1,70 @@
+/*
+ * Copyright © 2024 Kaleido, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package blockindexer
+
+import (
+	"github.com/kaleido-io/paladin/core/internal/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// The block indexer metrics are all computed on scrape, from the state already held in memory
+func (bi *blockIndexer) registerMetrics(r prometheus.Registerer) error {
+	collectors := []prometheus.Collector{
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: "blockindexer",
+			Name:      "chain_head_block",
+			Help:      "Highest block number reported by the blockchain node",
+		}, func() float64 { return float64(bi.blockListener.currentHighestBlock()) }),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: "blockindexer",
+			Name:      "confirmed_block",
+			Help:      "Highest block number confirmed and persisted by the block indexer",
+		}, func() float64 { return float64(bi.highestConfirmedBlock.Load()) }),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: "blockindexer",
+			Name:      "lag_blocks",
+			Help:      "Number of blocks the block indexer is behind the head of the chain",
+		}, bi.lagBlocks),
+		bi.reorgsTotal,
+	}
+	for _, c := range collectors {
+		if err := r.Register(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func newReorgsCounter() prometheus.Counter {
+	return prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "blockindexer",
+		Name:      "reorgs_total",
+		Help:      "Number of chain re-orgs detected beyond the confirmation depth, requiring indexed blocks to be rolled back",
+	})
+}
+
+func (bi *blockIndexer) lagBlocks() float64 {
+	head := int64(bi.blockListener.currentHighestBlock())
+	confirmed := bi.highestConfirmedBlock.Load()
+	if confirmed < 0 || head <= confirmed {
+		return 0
+	}
+	return float64(head - confirmed)
+}
@@ -0,0 +1,53 @@
+/*
+ * Copyright © 2024 Kaleido, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package blockindexer
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlockIndexerMetrics(t *testing.T) {
+	bi := &blockIndexer{blockListener: &blockListener{}, reorgsTotal: newReorgsCounter()}
+	bi.highestConfirmedBlock.Store(-1)
+
+	r := prometheus.NewRegistry()
+	err := bi.registerMetrics(r)
+	require.NoError(t, err)
+
+	// Nothing indexed yet
+	assert.Equal(t, float64(0), bi.lagBlocks())
+
+	bi.blockListener.highestBlock = 110
+	bi.highestConfirmedBlock.Store(100)
+	assert.Equal(t, float64(10), bi.lagBlocks())
+
+	count, err := testutil.GatherAndCount(r, "paladin_blockindexer_lag_blocks", "paladin_blockindexer_chain_head_block", "paladin_blockindexer_confirmed_block", "paladin_blockindexer_reorgs_total")
+	require.NoError(t, err)
+	assert.Equal(t, 4, count)
+
+	// Confirmed ahead of our view of the head
+	bi.highestConfirmedBlock.Store(111)
+	assert.Equal(t, float64(0), bi.lagBlocks())
+
+	// Double registration fails
+	err = bi.registerMetrics(r)
+	assert.Error(t, err)
+}
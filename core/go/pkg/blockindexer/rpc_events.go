@@ -0,0 +1,46 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package blockindexer
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/kaleido-io/paladin/toolkit/pkg/pldapi"
+	"github.com/kaleido-io/paladin/toolkit/pkg/rpcserver"
+)
+
+const rpcSubscriptionTypeEvents = "events"
+
+// Each bidx_subscribe subscription is attached as a receiver to an external event stream
+type eventStreamSubscription struct {
+	*rpcserver.RPCEventSubscription
+}
+
+func newRPCEventStreams(bi *blockIndexer) *rpcserver.RPCEventSubscriptions {
+	return rpcserver.NewRPCEventSubscriptions("bidx", rpcSubscriptionTypeEvents,
+		func(ctx context.Context, streamName string, sub *rpcserver.RPCEventSubscription) (rpcserver.RPCEventReceiverCloser, error) {
+			return bi.AddEventStreamReceiver(ctx, streamName, &eventStreamSubscription{sub})
+		})
+}
+
+func (sub *eventStreamSubscription) DeliverEventBatch(ctx context.Context, batchID uuid.UUID, events []*pldapi.EventWithData) error {
+	return sub.DeliverBatch(ctx, batchID, &pldapi.EventStreamBatch{
+		BatchID: batchID,
+		Events:  events,
+	})
+}
@@ -0,0 +1,180 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package blockindexer
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/kaleido-io/paladin/config/pkg/confutil"
+	"github.com/kaleido-io/paladin/toolkit/pkg/pldapi"
+	"github.com/kaleido-io/paladin/toolkit/pkg/rpcserver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func wsReceiveEvents(t *testing.T, wsc *rpcserver.UnitTestWSClient) (string, *pldapi.EventStreamBatch) {
+	msg, err := wsc.Receive()
+	require.NoError(t, err)
+	require.Equal(t, "bidx_subscription", msg.Method)
+	var batch pldapi.EventStreamBatch
+	err = json.Unmarshal(msg.Params.Result, &batch)
+	require.NoError(t, err)
+	return msg.Params.Subscription, &batch
+}
+
+func TestRPCEventStreamSubscription(t *testing.T) {
+
+	ctx, bi, mRPC, blDone := newTestBlockIndexer(t)
+	defer blDone()
+
+	blocks, receipts := testBlockArray(t, 3)
+	mockBlocksRPCCalls(mRPC, blocks, receipts)
+	mockBlockListenerNil(mRPC)
+
+	wsc, wsDone, err := rpcserver.NewUnitTestWSClient(ctx, bi.RPCModule())
+	require.NoError(t, err)
+	defer wsDone()
+
+	spec := testExternalStream("stream1")
+	spec.Config.BatchSize = confutil.P(1)
+	err = wsc.Send(ctx, 1, "bidx_createEventStream", spec)
+	require.NoError(t, err)
+	msg, err := wsc.Receive()
+	require.NoError(t, err)
+	require.Nil(t, msg.Error)
+	var es pldapi.EventStream
+	err = json.Unmarshal(msg.Result, &es)
+	require.NoError(t, err)
+	assert.Equal(t, "stream1", es.Name)
+
+	err = wsc.Send(ctx, 2, "bidx_subscribe", "wrong", "stream1")
+	require.NoError(t, err)
+	msg, err = wsc.Receive()
+	require.NoError(t, err)
+	assert.Regexp(t, "PD020715", msg.Error.Message)
+
+	err = wsc.Send(ctx, 3, "bidx_subscribe", "events", "unknown")
+	require.NoError(t, err)
+	msg, err = wsc.Receive()
+	require.NoError(t, err)
+	assert.Regexp(t, "PD011312", msg.Error.Message)
+
+	err = wsc.Send(ctx, 4, "bidx_subscribe", "events", "stream1")
+	require.NoError(t, err)
+	msg, err = wsc.Receive()
+	require.NoError(t, err)
+	require.Nil(t, msg.Error)
+	var subID string
+	err = json.Unmarshal(msg.Result, &subID)
+	require.NoError(t, err)
+
+	// Start indexing
+	err = bi.Start()
+	require.NoError(t, err)
+
+	// Nack the first delivery
+	notifiedSubID, batch := wsReceiveEvents(t, wsc)
+	assert.Equal(t, subID, notifiedSubID)
+	require.Len(t, batch.Events, 1)
+	firstBatchID := batch.BatchID
+	assert.Equal(t, int64(0), batch.Events[0].BlockNumber)
+	err = wsc.Send(ctx, 5, "bidx_nack", subID)
+	require.NoError(t, err)
+
+	// Ack the redelivery, and the remaining batches apart from the last,
+	// which is still in flight when we unsubscribe
+	for i := 0; i < len(blocks); i++ {
+		_, batch = wsReceiveEvents(t, wsc)
+		require.Len(t, batch.Events, 1)
+		if i == 0 {
+			assert.Equal(t, firstBatchID, batch.BatchID)
+		}
+		assert.Equal(t, int64(i), batch.Events[0].BlockNumber)
+		if i < len(blocks)-1 {
+			err = wsc.Send(ctx, 6+i, "bidx_ack", subID)
+			require.NoError(t, err)
+		}
+	}
+
+	err = wsc.Send(ctx, 20, "bidx_unsubscribe", subID)
+	require.NoError(t, err)
+	msg, err = wsc.Receive()
+	require.NoError(t, err)
+	require.Nil(t, msg.Error)
+	assert.Equal(t, "true", msg.Result.String())
+
+	err = wsc.Send(ctx, 21, "bidx_getEventStream", "stream1")
+	require.NoError(t, err)
+	msg, err = wsc.Receive()
+	require.NoError(t, err)
+	require.Nil(t, msg.Error)
+	err = json.Unmarshal(msg.Result, &es)
+	require.NoError(t, err)
+	assert.True(t, *es.Started)
+
+	err = wsc.Send(ctx, 22, "bidx_queryEventStreams", map[string]any{"limit": 10})
+	require.NoError(t, err)
+	msg, err = wsc.Receive()
+	require.NoError(t, err)
+	require.Nil(t, msg.Error)
+	var streams []*pldapi.EventStream
+	err = json.Unmarshal(msg.Result, &streams)
+	require.NoError(t, err)
+	assert.Len(t, streams, 1)
+
+	for i, method := range []string{"bidx_stopEventStream", "bidx_startEventStream", "bidx_deleteEventStream"} {
+		err = wsc.Send(ctx, 23+i, method, "stream1")
+		require.NoError(t, err)
+		msg, err = wsc.Receive()
+		require.NoError(t, err)
+		require.Nil(t, msg.Error)
+	}
+
+}
+
+func TestRPCEventStreamSubscriptionConnectionClosed(t *testing.T) {
+
+	ctx, bi, _, blDone := newTestBlockIndexer(t)
+	defer blDone()
+
+	wsc, wsDone, err := rpcserver.NewUnitTestWSClient(ctx, bi.RPCModule())
+	require.NoError(t, err)
+	defer wsDone()
+
+	_, err = bi.CreateEventStream(ctx, testExternalStream("stream1"))
+	require.NoError(t, err)
+
+	err = wsc.Send(ctx, 1, "bidx_subscribe", "events", "stream1")
+	require.NoError(t, err)
+	msg, err := wsc.Receive()
+	require.NoError(t, err)
+	require.Nil(t, msg.Error)
+
+	bi.eventStreamsLock.Lock()
+	es := bi.getExternalEventStream("stream1")
+	bi.eventStreamsLock.Unlock()
+	assert.Equal(t, 1, es.receivers.Count())
+
+	// Close, and check the receiver is removed
+	wsc.Close()
+	for es.receivers.Count() > 0 {
+		time.Sleep(1 * time.Millisecond)
+	}
+
+}
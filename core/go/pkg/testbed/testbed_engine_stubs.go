@@ -22,7 +22,6 @@ import (
 	"time"
 
 	"github.com/hyperledger/firefly-signer/pkg/abi"
-	"github.com/kaleido-io/paladin/config/pkg/pldconf"
 	"github.com/kaleido-io/paladin/core/internal/components"
 	"github.com/kaleido-io/paladin/core/internal/statedistribution"
 	"github.com/kaleido-io/paladin/toolkit/pkg/log"
@@ -159,7 +158,7 @@ func (tb *testbed) writeNullifiersToContext(dCtx components.DomainContext, tx *c
 		tb.c.StateManager(),
 		tb.c.KeyManager(),
 		tb.c.Persistence(),
-		&pldconf.DistributerConfig{},
+		nil,
 	)
 	nullifiers, err := sd.BuildNullifiers(tb.ctx, distributions.Local)
 	if err != nil {
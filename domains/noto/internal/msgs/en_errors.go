@@ -62,6 +62,13 @@ var (
 	MsgUnknownEvent                = ffe("PD200021", "Unknown event: %s")
 	MsgNotImplemented              = ffe("PD200022", "Not implemented")
 	MsgInvalidDelegate             = ffe("PD200023", "Invalid delegate: %s")
-	MsgNoDomainReceipt             = ffe("PD200024", "Not implemented. See state receipt for coin transfers")
 	MsgNoBurning                   = ffe("PD200025", "Burn is not enabled")
+	MsgNotaryOnly                  = ffe("PD200026", "'%s' can only be initiated by notary: expected=%s actual=%s")
+	MsgNotSupportedWithHooks       = ffe("PD200027", "'%s' is not supported for a notary with hooks")
+	MsgContractPaused              = ffe("PD200028", "Contract is paused")
+	MsgAccountFrozen               = ffe("PD200029", "Account %s is frozen")
+	MsgInvalidOutputs              = ffe("PD200030", "Invalid outputs to '%s': %v")
+	MsgLockNotFound                = ffe("PD200031", "Lock %s not found")
+	MsgLockNotController           = ffe("PD200032", "Lock %s can only be used by %s")
+	MsgLockAlreadyDelegated        = ffe("PD200033", "Lock %s is already delegated to %s")
 )
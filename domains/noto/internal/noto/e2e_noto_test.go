@@ -300,6 +300,30 @@ func TestNoto(t *testing.T) {
 	assert.Equal(t, notaryKey.Verifier.Verifier, coins[0].Data.Owner.String())
 	assert.Equal(t, int64(25), coins[1].Data.Amount.Int().Int64())
 	assert.Equal(t, recipient2Key.Verifier.Verifier, coins[1].Data.Owner.String())
+
+	log.L(ctx).Infof("Query balance of recipient2")
+	var balance types.BalanceOfResult
+	rpcerr = rpc.CallRPC(ctx, &balance, "ptx_call", &pldapi.TransactionCall{
+		TransactionInput: pldapi.TransactionInput{
+			TransactionBase: pldapi.TransactionBase{
+				Type:     pldapi.TransactionTypePrivate.Enum(),
+				Domain:   domainName,
+				From:     recipient2Name,
+				To:       &notoAddress,
+				Function: "balanceOf",
+				Data: toJSON(t, &types.BalanceOfParam{
+					Account: recipient2Name,
+				}),
+			},
+			ABI: types.NotoABI,
+		},
+	})
+	if rpcerr != nil {
+		require.NoError(t, rpcerr.Error())
+	}
+	assert.Equal(t, int64(1), balance.TotalStates.Int().Int64())
+	assert.Equal(t, int64(25), balance.TotalBalance.Int().Int64())
+	assert.False(t, balance.Overflow)
 }
 
 func TestNotoApprove(t *testing.T) {
@@ -80,11 +80,23 @@ func (h *approveHandler) Assemble(ctx context.Context, tx *types.ParsedTransacti
 		return nil, err
 	}
 
+	// The approval is recorded in info states, so it can be reported in the domain receipt
+	infoStates, err := h.noto.prepareInfo(params.Data, []string{notary, tx.Transaction.From})
+	if err != nil {
+		return nil, err
+	}
+	approvalState, err := h.noto.prepareApprovalInfo(params.Delegate, tktypes.NewBytes32FromSlice(transferHash), []string{notary, tx.Transaction.From})
+	if err != nil {
+		return nil, err
+	}
+	infoStates = append(infoStates, approvalState)
+
 	return &prototk.AssembleTransactionResponse{
 		AssemblyResult: prototk.AssembleTransactionResponse_OK,
 		AssembledTransaction: &prototk.AssembledTransaction{
 			InputStates:  []*prototk.StateRef{},
 			OutputStates: []*prototk.NewState{},
+			InfoStates:   infoStates,
 		},
 		AttestationPlan: []*prototk.AttestationRequest{
 			// Sender confirms the initial request with a signature
@@ -133,6 +145,9 @@ func (h *approveHandler) Endorse(ctx context.Context, tx *types.ParsedTransactio
 	if err := h.noto.validateOwners(ctx, tx, req, coins); err != nil {
 		return nil, err
 	}
+	if err := h.noto.validateStatus(ctx, req.StateQueryContext, coins); err != nil {
+		return nil, err
+	}
 
 	transferHash, err := h.transferHash(ctx, tx, params)
 	if err != nil {
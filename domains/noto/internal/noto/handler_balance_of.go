@@ -0,0 +1,81 @@
+/*
+ * Copyright © 2024 Kaleido, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package noto
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/kaleido-io/paladin/domains/noto/internal/msgs"
+	"github.com/kaleido-io/paladin/domains/noto/pkg/types"
+	"github.com/kaleido-io/paladin/toolkit/pkg/algorithms"
+	"github.com/kaleido-io/paladin/toolkit/pkg/prototk"
+	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
+	"github.com/kaleido-io/paladin/toolkit/pkg/verifiers"
+)
+
+type balanceOfHandler struct {
+	noto *Noto
+}
+
+func (h *balanceOfHandler) ValidateParams(ctx context.Context, config *types.NotoParsedConfig, params string) (interface{}, error) {
+	var balanceOfParams types.BalanceOfParam
+	if err := json.Unmarshal([]byte(params), &balanceOfParams); err != nil {
+		return nil, err
+	}
+	if balanceOfParams.Account == "" {
+		return nil, i18n.NewError(ctx, msgs.MsgParameterRequired, "account")
+	}
+	return &balanceOfParams, nil
+}
+
+func (h *balanceOfHandler) InitCall(ctx context.Context, tx *types.ParsedTransaction, req *prototk.InitCallRequest) (*prototk.InitCallResponse, error) {
+	params := tx.Params.(*types.BalanceOfParam)
+	return &prototk.InitCallResponse{
+		RequiredVerifiers: []*prototk.ResolveVerifierRequest{
+			{
+				Lookup:       params.Account,
+				Algorithm:    algorithms.ECDSA_SECP256K1,
+				VerifierType: verifiers.ETH_ADDRESS,
+			},
+		},
+	}, nil
+}
+
+func (h *balanceOfHandler) ExecCall(ctx context.Context, tx *types.ParsedTransaction, req *prototk.ExecCallRequest) (*prototk.ExecCallResponse, error) {
+	params := tx.Params.(*types.BalanceOfParam)
+	owner, err := h.noto.findEthAddressVerifier(ctx, "account", params.Account, req.ResolvedVerifiers)
+	if err != nil {
+		return nil, err
+	}
+
+	totalStates, totalBalance, overflow, err := h.noto.getAccountBalance(ctx, req.StateQueryContext, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	balanceJSON, err := json.Marshal(&types.BalanceOfResult{
+		TotalStates:  (*tktypes.HexUint256)(big.NewInt(int64(totalStates))),
+		TotalBalance: (*tktypes.HexUint256)(totalBalance),
+		Overflow:     overflow,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &prototk.ExecCallResponse{ResultJson: string(balanceJSON)}, nil
+}
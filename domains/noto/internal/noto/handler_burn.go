@@ -150,6 +150,9 @@ func (h *burnHandler) Endorse(ctx context.Context, tx *types.ParsedTransaction,
 	if err := h.noto.validateOwners(ctx, tx, req, coins); err != nil {
 		return nil, err
 	}
+	if err := h.noto.validateStatus(ctx, req.StateQueryContext, coins); err != nil {
+		return nil, err
+	}
 
 	// Notary checks the signature from the sender, then submits the transaction
 	if err := h.noto.validateTransferSignature(ctx, tx, req, coins); err != nil {
@@ -0,0 +1,176 @@
+/*
+ * Copyright © 2024 Kaleido, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+package noto
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/kaleido-io/paladin/domains/noto/internal/msgs"
+	"github.com/kaleido-io/paladin/domains/noto/pkg/types"
+	"github.com/kaleido-io/paladin/toolkit/pkg/algorithms"
+	"github.com/kaleido-io/paladin/toolkit/pkg/prototk"
+	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
+	"github.com/kaleido-io/paladin/toolkit/pkg/verifiers"
+)
+
+// Hands control of a lock to a delegate, who can then unlock it until the lock expires.
+// Only the owner can delegate, and only while the lock is not already controlled by another delegate.
+type delegateLockHandler struct {
+	noto *Noto
+}
+
+func (h *delegateLockHandler) ValidateParams(ctx context.Context, config *types.NotoParsedConfig, params string) (interface{}, error) {
+	var delegateParams types.DelegateLockParams
+	if err := json.Unmarshal([]byte(params), &delegateParams); err != nil {
+		return nil, err
+	}
+	if delegateParams.LockID.IsZero() {
+		return nil, i18n.NewError(ctx, msgs.MsgParameterRequired, "lockId")
+	}
+	if delegateParams.Delegate == "" {
+		return nil, i18n.NewError(ctx, msgs.MsgParameterRequired, "delegate")
+	}
+	return &delegateParams, nil
+}
+
+func (h *delegateLockHandler) Init(ctx context.Context, tx *types.ParsedTransaction, req *prototk.InitTransactionRequest) (*prototk.InitTransactionResponse, error) {
+	params := tx.Params.(*types.DelegateLockParams)
+	if err := h.noto.validateNoHooks(ctx, tx); err != nil {
+		return nil, err
+	}
+	return &prototk.InitTransactionResponse{
+		RequiredVerifiers: []*prototk.ResolveVerifierRequest{
+			{
+				Lookup:       tx.DomainConfig.NotaryLookup,
+				Algorithm:    algorithms.ECDSA_SECP256K1,
+				VerifierType: verifiers.ETH_ADDRESS,
+			},
+			{
+				Lookup:       tx.Transaction.From,
+				Algorithm:    algorithms.ECDSA_SECP256K1,
+				VerifierType: verifiers.ETH_ADDRESS,
+			},
+			{
+				Lookup:       params.Delegate,
+				Algorithm:    algorithms.ECDSA_SECP256K1,
+				VerifierType: verifiers.ETH_ADDRESS,
+			},
+		},
+	}, nil
+}
+
+func (h *delegateLockHandler) Assemble(ctx context.Context, tx *types.ParsedTransaction, req *prototk.AssembleTransactionRequest) (*prototk.AssembleTransactionResponse, error) {
+	params := tx.Params.(*types.DelegateLockParams)
+	notary := tx.DomainConfig.NotaryLookup
+
+	_, err := h.noto.findEthAddressVerifier(ctx, "notary", notary, req.ResolvedVerifiers)
+	if err != nil {
+		return nil, err
+	}
+	delegateAddress, err := h.noto.findEthAddressVerifier(ctx, "delegate", params.Delegate, req.ResolvedVerifiers)
+	if err != nil {
+		return nil, err
+	}
+
+	inputState, locked, err := h.noto.findLockedCoin(ctx, req.StateQueryContext, params.LockID)
+	if err != nil {
+		return nil, err
+	}
+	delegated := *locked
+	delegated.Salt = tktypes.RandHex(32)
+	delegated.Delegate = delegateAddress
+	outputState, err := h.noto.makeNewLockedCoinState(&delegated, []string{notary, tx.Transaction.From, params.Delegate})
+	if err != nil {
+		return nil, err
+	}
+
+	infoStates, err := h.noto.prepareInfo(params.Data, []string{notary, tx.Transaction.From, params.Delegate})
+	if err != nil {
+		return nil, err
+	}
+	attestation, err := h.noto.transferAttestationPlan(ctx, tx,
+		[]*types.NotoCoin{lockedAsCoin(locked)},
+		[]*types.NotoCoin{lockedAsCoin(&delegated)})
+	if err != nil {
+		return nil, err
+	}
+
+	return &prototk.AssembleTransactionResponse{
+		AssemblyResult: prototk.AssembleTransactionResponse_OK,
+		AssembledTransaction: &prototk.AssembledTransaction{
+			InputStates:  []*prototk.StateRef{inputState},
+			OutputStates: []*prototk.NewState{outputState},
+			InfoStates:   infoStates,
+		},
+		AttestationPlan: attestation,
+	}, nil
+}
+
+func (h *delegateLockHandler) Endorse(ctx context.Context, tx *types.ParsedTransaction, req *prototk.EndorseTransactionRequest) (*prototk.EndorseTransactionResponse, error) {
+	params := tx.Params.(*types.DelegateLockParams)
+	locks, err := h.noto.gatherLocks(ctx, req.Inputs, req.Outputs)
+	if err != nil {
+		return nil, err
+	}
+	if len(locks.inLocked) != 1 || len(locks.coins.inCoins) != 0 || locks.inLocked[0].LockID != params.LockID {
+		return nil, i18n.NewError(ctx, msgs.MsgInvalidInputs, "delegateLock", req.Inputs)
+	}
+	if len(locks.outLocked) != 1 || len(locks.coins.outCoins) != 0 {
+		return nil, i18n.NewError(ctx, msgs.MsgInvalidOutputs, "delegateLock", req.Outputs)
+	}
+
+	fromAddress, err := h.noto.findEthAddressVerifier(ctx, "from", tx.Transaction.From, req.ResolvedVerifiers)
+	if err != nil {
+		return nil, err
+	}
+	delegateAddress, err := h.noto.findEthAddressVerifier(ctx, "delegate", params.Delegate, req.ResolvedVerifiers)
+	if err != nil {
+		return nil, err
+	}
+	locked := locks.inLocked[0]
+	if !locked.Owner.Equals(fromAddress) {
+		return nil, i18n.NewError(ctx, msgs.MsgStateWrongOwner, locks.inStates[0].Id, tx.Transaction.From)
+	}
+	if !lockController(locked, time.Now()).Equals(locked.Owner) {
+		return nil, i18n.NewError(ctx, msgs.MsgLockAlreadyDelegated, params.LockID, locked.Delegate)
+	}
+
+	// Only the delegate may change
+	delegated := locks.outLocked[0]
+	if delegated.LockID != locked.LockID ||
+		!delegated.Owner.Equals(locked.Owner) ||
+		delegated.Amount == nil || delegated.Amount.Int().Cmp(locked.Amount.Int()) != 0 ||
+		delegated.Expiry != locked.Expiry ||
+		!delegated.Delegate.Equals(delegateAddress) {
+		return nil, i18n.NewError(ctx, msgs.MsgInvalidOutputs, "delegateLock", locks.outStates[0].StateDataJson)
+	}
+
+	signed := h.noto.signedLockCoins(locks)
+	if err := h.noto.validateStatus(ctx, req.StateQueryContext, signed); err != nil {
+		return nil, err
+	}
+	return h.noto.endorseTransfer(ctx, tx, req, signed)
+}
+
+func (h *delegateLockHandler) Prepare(ctx context.Context, tx *types.ParsedTransaction, req *prototk.PrepareTransactionRequest) (*prototk.PrepareTransactionResponse, error) {
+	baseTransaction, err := h.noto.baseLedgerTransfer(ctx, tx, req, "transfer")
+	if err != nil {
+		return nil, err
+	}
+	return baseTransaction.prepare(nil)
+}
@@ -0,0 +1,169 @@
+/*
+ * Copyright © 2024 Kaleido, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+package noto
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/kaleido-io/paladin/domains/noto/internal/msgs"
+	"github.com/kaleido-io/paladin/domains/noto/pkg/types"
+	"github.com/kaleido-io/paladin/toolkit/pkg/algorithms"
+	"github.com/kaleido-io/paladin/toolkit/pkg/prototk"
+	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
+	"github.com/kaleido-io/paladin/toolkit/pkg/verifiers"
+)
+
+// A transfer out of any account, initiated by the notary.
+// This deliberately bypasses the paused and frozen checks, so it can be used to move value out of a frozen account.
+type forceTransferHandler struct {
+	noto *Noto
+}
+
+func (h *forceTransferHandler) ValidateParams(ctx context.Context, config *types.NotoParsedConfig, params string) (interface{}, error) {
+	var forceTransferParams types.ForceTransferParams
+	if err := json.Unmarshal([]byte(params), &forceTransferParams); err != nil {
+		return nil, err
+	}
+	if forceTransferParams.From == "" {
+		return nil, i18n.NewError(ctx, msgs.MsgParameterRequired, "from")
+	}
+	if forceTransferParams.To == "" {
+		return nil, i18n.NewError(ctx, msgs.MsgParameterRequired, "to")
+	}
+	if forceTransferParams.Amount == nil || forceTransferParams.Amount.Int().Sign() != 1 {
+		return nil, i18n.NewError(ctx, msgs.MsgParameterGreaterThanZero, "amount")
+	}
+	return &forceTransferParams, nil
+}
+
+func (h *forceTransferHandler) Init(ctx context.Context, tx *types.ParsedTransaction, req *prototk.InitTransactionRequest) (*prototk.InitTransactionResponse, error) {
+	params := tx.Params.(*types.ForceTransferParams)
+	if err := h.noto.validateNotaryOnly(ctx, tx); err != nil {
+		return nil, err
+	}
+	return &prototk.InitTransactionResponse{
+		RequiredVerifiers: []*prototk.ResolveVerifierRequest{
+			{
+				Lookup:       tx.DomainConfig.NotaryLookup,
+				Algorithm:    algorithms.ECDSA_SECP256K1,
+				VerifierType: verifiers.ETH_ADDRESS,
+			},
+			{
+				Lookup:       params.From,
+				Algorithm:    algorithms.ECDSA_SECP256K1,
+				VerifierType: verifiers.ETH_ADDRESS,
+			},
+			{
+				Lookup:       params.To,
+				Algorithm:    algorithms.ECDSA_SECP256K1,
+				VerifierType: verifiers.ETH_ADDRESS,
+			},
+		},
+	}, nil
+}
+
+func (h *forceTransferHandler) Assemble(ctx context.Context, tx *types.ParsedTransaction, req *prototk.AssembleTransactionRequest) (*prototk.AssembleTransactionResponse, error) {
+	params := tx.Params.(*types.ForceTransferParams)
+	notary := tx.DomainConfig.NotaryLookup
+
+	_, err := h.noto.findEthAddressVerifier(ctx, "notary", notary, req.ResolvedVerifiers)
+	if err != nil {
+		return nil, err
+	}
+	fromAddress, err := h.noto.findEthAddressVerifier(ctx, "from", params.From, req.ResolvedVerifiers)
+	if err != nil {
+		return nil, err
+	}
+	toAddress, err := h.noto.findEthAddressVerifier(ctx, "to", params.To, req.ResolvedVerifiers)
+	if err != nil {
+		return nil, err
+	}
+
+	inputCoins, inputStates, total, err := h.noto.prepareInputs(ctx, req.StateQueryContext, fromAddress, params.Amount)
+	if err != nil {
+		return nil, err
+	}
+	outputCoins, outputStates, err := h.noto.prepareOutputs(toAddress, params.Amount, []string{notary, params.From, params.To})
+	if err != nil {
+		return nil, err
+	}
+	infoStates, err := h.noto.prepareInfo(params.Data, []string{notary, params.From, params.To})
+	if err != nil {
+		return nil, err
+	}
+
+	if total.Cmp(params.Amount.Int()) == 1 {
+		remainder := big.NewInt(0).Sub(total, params.Amount.Int())
+		returnedCoins, returnedStates, err := h.noto.prepareOutputs(fromAddress, (*tktypes.HexUint256)(remainder), []string{notary, params.From})
+		if err != nil {
+			return nil, err
+		}
+		outputCoins = append(outputCoins, returnedCoins...)
+		outputStates = append(outputStates, returnedStates...)
+	}
+
+	attestation, err := h.noto.transferAttestationPlan(ctx, tx, inputCoins, outputCoins)
+	if err != nil {
+		return nil, err
+	}
+
+	return &prototk.AssembleTransactionResponse{
+		AssemblyResult: prototk.AssembleTransactionResponse_OK,
+		AssembledTransaction: &prototk.AssembledTransaction{
+			InputStates:  inputStates,
+			OutputStates: outputStates,
+			InfoStates:   infoStates,
+		},
+		AttestationPlan: attestation,
+	}, nil
+}
+
+func (h *forceTransferHandler) Endorse(ctx context.Context, tx *types.ParsedTransaction, req *prototk.EndorseTransactionRequest) (*prototk.EndorseTransactionResponse, error) {
+	params := tx.Params.(*types.ForceTransferParams)
+	if err := h.noto.validateNotaryOnly(ctx, tx); err != nil {
+		return nil, err
+	}
+	coins, err := h.noto.gatherCoins(ctx, req.Inputs, req.Outputs)
+	if err != nil {
+		return nil, err
+	}
+	if err := h.noto.validateTransferAmounts(ctx, coins); err != nil {
+		return nil, err
+	}
+
+	// All inputs must come from the account being transferred out of (rather than the notary who sent the transaction)
+	fromAddress, err := h.noto.findEthAddressVerifier(ctx, "from", params.From, req.ResolvedVerifiers)
+	if err != nil {
+		return nil, err
+	}
+	for i, coin := range coins.inCoins {
+		if !coin.Owner.Equals(fromAddress) {
+			return nil, i18n.NewError(ctx, msgs.MsgStateWrongOwner, coins.inStates[i].Id, params.From)
+		}
+	}
+
+	return h.noto.endorseTransfer(ctx, tx, req, coins)
+}
+
+func (h *forceTransferHandler) Prepare(ctx context.Context, tx *types.ParsedTransaction, req *prototk.PrepareTransactionRequest) (*prototk.PrepareTransactionResponse, error) {
+	baseTransaction, err := h.noto.baseLedgerTransfer(ctx, tx, req, "transfer")
+	if err != nil {
+		return nil, err
+	}
+	return baseTransaction.prepare(nil)
+}
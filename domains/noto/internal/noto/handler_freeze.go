@@ -0,0 +1,144 @@
+/*
+ * Copyright © 2024 Kaleido, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+package noto
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/kaleido-io/paladin/domains/noto/internal/msgs"
+	"github.com/kaleido-io/paladin/domains/noto/pkg/types"
+	"github.com/kaleido-io/paladin/toolkit/pkg/algorithms"
+	"github.com/kaleido-io/paladin/toolkit/pkg/prototk"
+	"github.com/kaleido-io/paladin/toolkit/pkg/verifiers"
+)
+
+// Handles both "freeze" and "unfreeze", which replace the status state of a single account
+type freezeHandler struct {
+	noto   *Noto
+	frozen bool
+}
+
+func (h *freezeHandler) ValidateParams(ctx context.Context, config *types.NotoParsedConfig, params string) (interface{}, error) {
+	var freezeParams types.FreezeParams
+	if err := json.Unmarshal([]byte(params), &freezeParams); err != nil {
+		return nil, err
+	}
+	if freezeParams.Account == "" {
+		return nil, i18n.NewError(ctx, msgs.MsgParameterRequired, "account")
+	}
+	return &freezeParams, nil
+}
+
+func (h *freezeHandler) Init(ctx context.Context, tx *types.ParsedTransaction, req *prototk.InitTransactionRequest) (*prototk.InitTransactionResponse, error) {
+	params := tx.Params.(*types.FreezeParams)
+	if err := h.noto.validateNotaryOnly(ctx, tx); err != nil {
+		return nil, err
+	}
+	return &prototk.InitTransactionResponse{
+		RequiredVerifiers: []*prototk.ResolveVerifierRequest{
+			{
+				Lookup:       tx.DomainConfig.NotaryLookup,
+				Algorithm:    algorithms.ECDSA_SECP256K1,
+				VerifierType: verifiers.ETH_ADDRESS,
+			},
+			{
+				Lookup:       params.Account,
+				Algorithm:    algorithms.ECDSA_SECP256K1,
+				VerifierType: verifiers.ETH_ADDRESS,
+			},
+		},
+	}, nil
+}
+
+func (h *freezeHandler) Assemble(ctx context.Context, tx *types.ParsedTransaction, req *prototk.AssembleTransactionRequest) (*prototk.AssembleTransactionResponse, error) {
+	params := tx.Params.(*types.FreezeParams)
+	notary := tx.DomainConfig.NotaryLookup
+
+	_, err := h.noto.findEthAddressVerifier(ctx, "notary", notary, req.ResolvedVerifiers)
+	if err != nil {
+		return nil, err
+	}
+	accountAddress, err := h.noto.findEthAddressVerifier(ctx, "account", params.Account, req.ResolvedVerifiers)
+	if err != nil {
+		return nil, err
+	}
+
+	distributionList := statusDistributionList([]string{notary, params.Account}, params.Parties)
+	inputStates, outputStates, err := h.noto.prepareAccountStatus(ctx, req.StateQueryContext, accountAddress, h.frozen, distributionList)
+	if err != nil {
+		return nil, err
+	}
+	infoStates, err := h.noto.prepareInfo(params.Data, distributionList)
+	if err != nil {
+		return nil, err
+	}
+	attestation, err := h.noto.transferAttestationPlan(ctx, tx, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &prototk.AssembleTransactionResponse{
+		AssemblyResult: prototk.AssembleTransactionResponse_OK,
+		AssembledTransaction: &prototk.AssembledTransaction{
+			InputStates:  inputStates,
+			OutputStates: outputStates,
+			InfoStates:   infoStates,
+		},
+		AttestationPlan: attestation,
+	}, nil
+}
+
+func (h *freezeHandler) Endorse(ctx context.Context, tx *types.ParsedTransaction, req *prototk.EndorseTransactionRequest) (*prototk.EndorseTransactionResponse, error) {
+	params := tx.Params.(*types.FreezeParams)
+	if err := h.noto.validateNotaryOnly(ctx, tx); err != nil {
+		return nil, err
+	}
+	accountAddress, err := h.noto.findEthAddressVerifier(ctx, "account", params.Account, req.ResolvedVerifiers)
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := h.noto.validateStatusStates(ctx, tx.FunctionABI.Name, h.noto.accountStatusSchema.Id, req.Inputs, req.Outputs)
+	if err != nil {
+		return nil, err
+	}
+	var status types.NotoAccountStatus
+	if err := json.Unmarshal([]byte(output.StateDataJson), &status); err != nil {
+		return nil, i18n.NewError(ctx, msgs.MsgInvalidStateData, output.Id, err)
+	}
+	if status.Frozen != h.frozen || !accountAddress.Equals(status.Account) {
+		return nil, i18n.NewError(ctx, msgs.MsgInvalidOutputs, tx.FunctionABI.Name, output.StateDataJson)
+	}
+	for _, input := range req.Inputs {
+		var previous types.NotoAccountStatus
+		if err := json.Unmarshal([]byte(input.StateDataJson), &previous); err != nil {
+			return nil, i18n.NewError(ctx, msgs.MsgInvalidStateData, input.Id, err)
+		}
+		if !accountAddress.Equals(previous.Account) {
+			return nil, i18n.NewError(ctx, msgs.MsgStateWrongOwner, input.Id, params.Account)
+		}
+	}
+	return h.noto.endorseTransfer(ctx, tx, req, &gatheredCoins{})
+}
+
+func (h *freezeHandler) Prepare(ctx context.Context, tx *types.ParsedTransaction, req *prototk.PrepareTransactionRequest) (*prototk.PrepareTransactionResponse, error) {
+	baseTransaction, err := h.noto.baseLedgerTransfer(ctx, tx, req, "transfer")
+	if err != nil {
+		return nil, err
+	}
+	return baseTransaction.prepare(nil)
+}
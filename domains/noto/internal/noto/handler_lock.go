@@ -0,0 +1,183 @@
+/*
+ * Copyright © 2024 Kaleido, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+package noto
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/kaleido-io/paladin/domains/noto/internal/msgs"
+	"github.com/kaleido-io/paladin/domains/noto/pkg/types"
+	"github.com/kaleido-io/paladin/toolkit/pkg/algorithms"
+	"github.com/kaleido-io/paladin/toolkit/pkg/prototk"
+	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
+	"github.com/kaleido-io/paladin/toolkit/pkg/verifiers"
+)
+
+// Moves value from the sender into a locked coin, which can only be spent by "unlock"
+type lockHandler struct {
+	noto *Noto
+}
+
+func (h *lockHandler) ValidateParams(ctx context.Context, config *types.NotoParsedConfig, params string) (interface{}, error) {
+	var lockParams types.LockParams
+	if err := json.Unmarshal([]byte(params), &lockParams); err != nil {
+		return nil, err
+	}
+	if lockParams.Amount == nil || lockParams.Amount.Int().Sign() != 1 {
+		return nil, i18n.NewError(ctx, msgs.MsgParameterGreaterThanZero, "amount")
+	}
+	return &lockParams, nil
+}
+
+func (h *lockHandler) Init(ctx context.Context, tx *types.ParsedTransaction, req *prototk.InitTransactionRequest) (*prototk.InitTransactionResponse, error) {
+	if err := h.noto.validateNoHooks(ctx, tx); err != nil {
+		return nil, err
+	}
+	return &prototk.InitTransactionResponse{
+		RequiredVerifiers: []*prototk.ResolveVerifierRequest{
+			{
+				Lookup:       tx.DomainConfig.NotaryLookup,
+				Algorithm:    algorithms.ECDSA_SECP256K1,
+				VerifierType: verifiers.ETH_ADDRESS,
+			},
+			{
+				Lookup:       tx.Transaction.From,
+				Algorithm:    algorithms.ECDSA_SECP256K1,
+				VerifierType: verifiers.ETH_ADDRESS,
+			},
+		},
+	}, nil
+}
+
+func (h *lockHandler) Assemble(ctx context.Context, tx *types.ParsedTransaction, req *prototk.AssembleTransactionRequest) (*prototk.AssembleTransactionResponse, error) {
+	params := tx.Params.(*types.LockParams)
+	notary := tx.DomainConfig.NotaryLookup
+
+	_, err := h.noto.findEthAddressVerifier(ctx, "notary", notary, req.ResolvedVerifiers)
+	if err != nil {
+		return nil, err
+	}
+	fromAddress, err := h.noto.findEthAddressVerifier(ctx, "from", tx.Transaction.From, req.ResolvedVerifiers)
+	if err != nil {
+		return nil, err
+	}
+	lockID, err := tktypes.ParseBytes32Ctx(ctx, tx.Transaction.TransactionId)
+	if err != nil {
+		return nil, err
+	}
+
+	inputCoins, inputStates, total, err := h.noto.prepareInputs(ctx, req.StateQueryContext, fromAddress, params.Amount)
+	if err != nil {
+		return nil, err
+	}
+	locked := &types.NotoLockedCoin{
+		Salt:     tktypes.RandHex(32),
+		LockID:   lockID,
+		Owner:    fromAddress,
+		Amount:   params.Amount,
+		Delegate: &tktypes.EthAddress{},
+		Expiry:   params.Expiry,
+	}
+	lockedState, err := h.noto.makeNewLockedCoinState(locked, []string{notary, tx.Transaction.From})
+	if err != nil {
+		return nil, err
+	}
+	outputCoins := []*types.NotoCoin{lockedAsCoin(locked)}
+	outputStates := []*prototk.NewState{lockedState}
+
+	if total.Cmp(params.Amount.Int()) == 1 {
+		remainder := big.NewInt(0).Sub(total, params.Amount.Int())
+		returnedCoins, returnedStates, err := h.noto.prepareOutputs(fromAddress, (*tktypes.HexUint256)(remainder), []string{notary, tx.Transaction.From})
+		if err != nil {
+			return nil, err
+		}
+		outputCoins = append(outputCoins, returnedCoins...)
+		outputStates = append(outputStates, returnedStates...)
+	}
+
+	infoStates, err := h.noto.prepareInfo(params.Data, []string{notary, tx.Transaction.From})
+	if err != nil {
+		return nil, err
+	}
+	attestation, err := h.noto.transferAttestationPlan(ctx, tx, inputCoins, outputCoins)
+	if err != nil {
+		return nil, err
+	}
+
+	return &prototk.AssembleTransactionResponse{
+		AssemblyResult: prototk.AssembleTransactionResponse_OK,
+		AssembledTransaction: &prototk.AssembledTransaction{
+			InputStates:  inputStates,
+			OutputStates: outputStates,
+			InfoStates:   infoStates,
+		},
+		AttestationPlan: attestation,
+	}, nil
+}
+
+func (h *lockHandler) Endorse(ctx context.Context, tx *types.ParsedTransaction, req *prototk.EndorseTransactionRequest) (*prototk.EndorseTransactionResponse, error) {
+	params := tx.Params.(*types.LockParams)
+	locks, err := h.noto.gatherLocks(ctx, req.Inputs, req.Outputs)
+	if err != nil {
+		return nil, err
+	}
+	if len(locks.inLocked) != 0 || len(locks.coins.inCoins) == 0 {
+		return nil, i18n.NewError(ctx, msgs.MsgInvalidInputs, "lock", req.Inputs)
+	}
+	if len(locks.outLocked) != 1 {
+		return nil, i18n.NewError(ctx, msgs.MsgInvalidOutputs, "lock", req.Outputs)
+	}
+	if err := h.noto.validateOwners(ctx, tx, req, locks.coins); err != nil {
+		return nil, err
+	}
+
+	// The locked coin must belong to the sender, and match the requested lock exactly
+	fromAddress, err := h.noto.findEthAddressVerifier(ctx, "from", tx.Transaction.From, req.ResolvedVerifiers)
+	if err != nil {
+		return nil, err
+	}
+	lockID, err := tktypes.ParseBytes32Ctx(ctx, tx.Transaction.TransactionId)
+	if err != nil {
+		return nil, err
+	}
+	locked := locks.outLocked[0]
+	if locked.LockID != lockID ||
+		!locked.Owner.Equals(fromAddress) ||
+		locked.Amount == nil || locked.Amount.Int().Cmp(params.Amount.Int()) != 0 ||
+		!locked.Delegate.IsZero() ||
+		locked.Expiry != params.Expiry {
+		return nil, i18n.NewError(ctx, msgs.MsgInvalidOutputs, "lock", locks.outStates[0].StateDataJson)
+	}
+	outTotal := big.NewInt(0).Add(locks.coins.outTotal, locked.Amount.Int())
+	if locks.coins.inTotal.Cmp(outTotal) != 0 {
+		return nil, i18n.NewError(ctx, msgs.MsgInvalidAmount, "lock", locks.coins.inTotal.Text(10), outTotal.Text(10))
+	}
+	if err := h.noto.validateStatus(ctx, req.StateQueryContext, locks.coins); err != nil {
+		return nil, err
+	}
+
+	return h.noto.endorseTransfer(ctx, tx, req, h.noto.signedLockCoins(locks))
+}
+
+func (h *lockHandler) Prepare(ctx context.Context, tx *types.ParsedTransaction, req *prototk.PrepareTransactionRequest) (*prototk.PrepareTransactionResponse, error) {
+	baseTransaction, err := h.noto.baseLedgerTransfer(ctx, tx, req, "transfer")
+	if err != nil {
+		return nil, err
+	}
+	return baseTransaction.prepare(nil)
+}
@@ -142,6 +142,9 @@ func (h *mintHandler) Endorse(ctx context.Context, tx *types.ParsedTransaction,
 	if err := h.noto.validateMintAmounts(ctx, params, coins); err != nil {
 		return nil, err
 	}
+	if err := h.noto.validateStatus(ctx, req.StateQueryContext, coins); err != nil {
+		return nil, err
+	}
 
 	// Notary checks the signature from the sender, then submits the transaction
 	if err := h.noto.validateTransferSignature(ctx, tx, req, coins); err != nil {
@@ -24,9 +24,7 @@ import (
 	"github.com/kaleido-io/paladin/domains/noto/internal/msgs"
 	"github.com/kaleido-io/paladin/domains/noto/pkg/types"
 	"github.com/kaleido-io/paladin/toolkit/pkg/algorithms"
-	"github.com/kaleido-io/paladin/toolkit/pkg/domain"
 	"github.com/kaleido-io/paladin/toolkit/pkg/prototk"
-	"github.com/kaleido-io/paladin/toolkit/pkg/signpayloads"
 	"github.com/kaleido-io/paladin/toolkit/pkg/solutils"
 	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
 	"github.com/kaleido-io/paladin/toolkit/pkg/verifiers"
@@ -115,55 +113,9 @@ func (h *transferHandler) Assemble(ctx context.Context, tx *types.ParsedTransact
 		outputStates = append(outputStates, returnedStates...)
 	}
 
-	var attestation []*prototk.AttestationRequest
-	switch tx.DomainConfig.Variant {
-	case types.NotoVariantDefault:
-		encodedTransfer, err := h.noto.encodeTransferUnmasked(ctx, tx.ContractAddress, inputCoins, outputCoins)
-		if err != nil {
-			return nil, err
-		}
-		attestation = []*prototk.AttestationRequest{
-			// Sender confirms the initial request with a signature
-			{
-				Name:            "sender",
-				AttestationType: prototk.AttestationType_SIGN,
-				Algorithm:       algorithms.ECDSA_SECP256K1,
-				VerifierType:    verifiers.ETH_ADDRESS,
-				Payload:         encodedTransfer,
-				PayloadType:     signpayloads.OPAQUE_TO_RSV,
-				Parties:         []string{req.Transaction.From},
-			},
-			// Notary will endorse the assembled transaction (by submitting to the ledger)
-			{
-				Name:            "notary",
-				AttestationType: prototk.AttestationType_ENDORSE,
-				Algorithm:       algorithms.ECDSA_SECP256K1,
-				VerifierType:    verifiers.ETH_ADDRESS,
-				Parties:         []string{notary},
-			},
-		}
-	case types.NotoVariantSelfSubmit:
-		attestation = []*prototk.AttestationRequest{
-			// Notary will endorse the assembled transaction (by providing a signature)
-			{
-				Name:            "notary",
-				AttestationType: prototk.AttestationType_ENDORSE,
-				Algorithm:       algorithms.ECDSA_SECP256K1,
-				VerifierType:    verifiers.ETH_ADDRESS,
-				PayloadType:     signpayloads.OPAQUE_TO_RSV,
-				Parties:         []string{notary},
-			},
-			// Sender will endorse the assembled transaction (by submitting to the ledger)
-			{
-				Name:            "sender",
-				AttestationType: prototk.AttestationType_ENDORSE,
-				Algorithm:       algorithms.ECDSA_SECP256K1,
-				VerifierType:    verifiers.ETH_ADDRESS,
-				Parties:         []string{req.Transaction.From},
-			},
-		}
-	default:
-		return nil, i18n.NewError(ctx, msgs.MsgUnknownDomainVariant, tx.DomainConfig.Variant)
+	attestation, err := h.noto.transferAttestationPlan(ctx, tx, inputCoins, outputCoins)
+	if err != nil {
+		return nil, err
 	}
 
 	return &prototk.AssembleTransactionResponse{
@@ -188,107 +140,11 @@ func (h *transferHandler) Endorse(ctx context.Context, tx *types.ParsedTransacti
 	if err := h.noto.validateOwners(ctx, tx, req, coins); err != nil {
 		return nil, err
 	}
-
-	switch tx.DomainConfig.Variant {
-	case types.NotoVariantDefault:
-		if req.EndorsementRequest.Name == "notary" {
-			// Notary checks the signature from the sender, then submits the transaction
-			if err := h.noto.validateTransferSignature(ctx, tx, req, coins); err != nil {
-				return nil, err
-			}
-			return &prototk.EndorseTransactionResponse{
-				EndorsementResult: prototk.EndorseTransactionResponse_ENDORSER_SUBMIT,
-			}, nil
-		}
-	case types.NotoVariantSelfSubmit:
-		if req.EndorsementRequest.Name == "notary" {
-			// Notary provides a signature for the assembled payload (to be verified on base ledger)
-			inputIDs := make([]interface{}, len(req.Inputs))
-			outputIDs := make([]interface{}, len(req.Outputs))
-			for i, state := range req.Inputs {
-				inputIDs[i] = state.Id
-			}
-			for i, state := range req.Outputs {
-				outputIDs[i] = state.Id
-			}
-			data, err := h.noto.encodeTransactionData(ctx, req.Transaction, req.Info)
-			if err != nil {
-				return nil, err
-			}
-			encodedTransfer, err := h.noto.encodeTransferMasked(ctx, tx.ContractAddress, inputIDs, outputIDs, data)
-			if err != nil {
-				return nil, err
-			}
-			return &prototk.EndorseTransactionResponse{
-				EndorsementResult: prototk.EndorseTransactionResponse_SIGN,
-				Payload:           encodedTransfer,
-			}, nil
-		} else if req.EndorsementRequest.Name == "sender" {
-			if req.EndorsementVerifier.Lookup == tx.Transaction.From {
-				// Sender submits the transaction
-				return &prototk.EndorseTransactionResponse{
-					EndorsementResult: prototk.EndorseTransactionResponse_ENDORSER_SUBMIT,
-				}, nil
-			}
-		}
-	default:
-		return nil, i18n.NewError(ctx, msgs.MsgUnknownDomainVariant, tx.DomainConfig.Variant)
-	}
-
-	return nil, i18n.NewError(ctx, msgs.MsgUnrecognizedEndorsement, req.EndorsementRequest.Name)
-}
-
-func (h *transferHandler) baseLedgerTransfer(ctx context.Context, tx *types.ParsedTransaction, req *prototk.PrepareTransactionRequest, withApproval bool) (*TransactionWrapper, error) {
-	inputs := make([]string, len(req.InputStates))
-	for i, state := range req.InputStates {
-		inputs[i] = state.Id
-	}
-	outputs := make([]string, len(req.OutputStates))
-	for i, state := range req.OutputStates {
-		outputs[i] = state.Id
-	}
-
-	var signature *prototk.AttestationResult
-	switch tx.DomainConfig.Variant {
-	case types.NotoVariantDefault:
-		// Include the signature from the sender
-		// This is not verified on the base ledger, but can be verified by anyone with the unmasked state data
-		signature = domain.FindAttestation("sender", req.AttestationResult)
-		if signature == nil {
-			return nil, i18n.NewError(ctx, msgs.MsgAttestationNotFound, "sender")
-		}
-	case types.NotoVariantSelfSubmit:
-		// Include the signature from the notary (will be verified on base ledger)
-		signature = domain.FindAttestation("notary", req.AttestationResult)
-		if signature == nil {
-			return nil, i18n.NewError(ctx, msgs.MsgAttestationNotFound, "notary")
-		}
-	default:
-		return nil, i18n.NewError(ctx, msgs.MsgUnknownDomainVariant, tx.DomainConfig.Variant)
-	}
-
-	data, err := h.noto.encodeTransactionData(ctx, req.Transaction, req.InfoStates)
-	if err != nil {
-		return nil, err
-	}
-	params := &NotoTransferParams{
-		Inputs:    inputs,
-		Outputs:   outputs,
-		Signature: signature.Payload,
-		Data:      data,
-	}
-	paramsJSON, err := json.Marshal(params)
-	if err != nil {
+	if err := h.noto.validateStatus(ctx, req.StateQueryContext, coins); err != nil {
 		return nil, err
 	}
-	fn := "transfer"
-	if withApproval {
-		fn = "transferWithApproval"
-	}
-	return &TransactionWrapper{
-		functionABI: h.noto.contractABI.Functions()[fn],
-		paramsJSON:  paramsJSON,
-	}, nil
+
+	return h.noto.endorseTransfer(ctx, tx, req, coins)
 }
 
 func (h *transferHandler) hookTransfer(ctx context.Context, tx *types.ParsedTransaction, req *prototk.PrepareTransactionRequest, baseTransaction *TransactionWrapper) (*TransactionWrapper, error) {
@@ -381,12 +237,12 @@ func (h *transferHandler) Prepare(ctx context.Context, tx *types.ParsedTransacti
 	// If preparing a transaction for later use, return metadata allowing it to be delegated to an approved party
 	prepareApprovals := req.Transaction.Intent == prototk.TransactionSpecification_PREPARE_TRANSACTION
 
-	baseTransaction, err = h.baseLedgerTransfer(ctx, tx, req, false)
+	baseTransaction, err = h.noto.baseLedgerTransfer(ctx, tx, req, "transfer")
 	if err != nil {
 		return nil, err
 	}
 	if prepareApprovals {
-		withApprovalTransaction, err = h.baseLedgerTransfer(ctx, tx, req, true)
+		withApprovalTransaction, err = h.noto.baseLedgerTransfer(ctx, tx, req, "transferWithApproval")
 		if err != nil {
 			return nil, err
 		}
@@ -0,0 +1,195 @@
+/*
+ * Copyright © 2024 Kaleido, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+package noto
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"time"
+
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/kaleido-io/paladin/domains/noto/internal/msgs"
+	"github.com/kaleido-io/paladin/domains/noto/pkg/types"
+	"github.com/kaleido-io/paladin/toolkit/pkg/algorithms"
+	"github.com/kaleido-io/paladin/toolkit/pkg/prototk"
+	"github.com/kaleido-io/paladin/toolkit/pkg/verifiers"
+)
+
+// Spends a locked coin, distributing its value to one or more recipients.
+// Can be performed by the delegate of the lock, or by the owner if the lock has not been delegated or has expired.
+type unlockHandler struct {
+	noto *Noto
+}
+
+func (h *unlockHandler) ValidateParams(ctx context.Context, config *types.NotoParsedConfig, params string) (interface{}, error) {
+	var unlockParams types.UnlockParams
+	if err := json.Unmarshal([]byte(params), &unlockParams); err != nil {
+		return nil, err
+	}
+	if unlockParams.LockID.IsZero() {
+		return nil, i18n.NewError(ctx, msgs.MsgParameterRequired, "lockId")
+	}
+	if len(unlockParams.Recipients) == 0 {
+		return nil, i18n.NewError(ctx, msgs.MsgParameterRequired, "recipients")
+	}
+	for _, recipient := range unlockParams.Recipients {
+		if recipient.To == "" {
+			return nil, i18n.NewError(ctx, msgs.MsgParameterRequired, "to")
+		}
+		if recipient.Amount == nil || recipient.Amount.Int().Sign() != 1 {
+			return nil, i18n.NewError(ctx, msgs.MsgParameterGreaterThanZero, "amount")
+		}
+	}
+	return &unlockParams, nil
+}
+
+func (h *unlockHandler) Init(ctx context.Context, tx *types.ParsedTransaction, req *prototk.InitTransactionRequest) (*prototk.InitTransactionResponse, error) {
+	params := tx.Params.(*types.UnlockParams)
+	if err := h.noto.validateNoHooks(ctx, tx); err != nil {
+		return nil, err
+	}
+	requiredVerifiers := []*prototk.ResolveVerifierRequest{
+		{
+			Lookup:       tx.DomainConfig.NotaryLookup,
+			Algorithm:    algorithms.ECDSA_SECP256K1,
+			VerifierType: verifiers.ETH_ADDRESS,
+		},
+		{
+			Lookup:       tx.Transaction.From,
+			Algorithm:    algorithms.ECDSA_SECP256K1,
+			VerifierType: verifiers.ETH_ADDRESS,
+		},
+	}
+	for _, recipient := range params.Recipients {
+		requiredVerifiers = append(requiredVerifiers, &prototk.ResolveVerifierRequest{
+			Lookup:       recipient.To,
+			Algorithm:    algorithms.ECDSA_SECP256K1,
+			VerifierType: verifiers.ETH_ADDRESS,
+		})
+	}
+	return &prototk.InitTransactionResponse{
+		RequiredVerifiers: requiredVerifiers,
+	}, nil
+}
+
+func (h *unlockHandler) Assemble(ctx context.Context, tx *types.ParsedTransaction, req *prototk.AssembleTransactionRequest) (*prototk.AssembleTransactionResponse, error) {
+	params := tx.Params.(*types.UnlockParams)
+	notary := tx.DomainConfig.NotaryLookup
+
+	_, err := h.noto.findEthAddressVerifier(ctx, "notary", notary, req.ResolvedVerifiers)
+	if err != nil {
+		return nil, err
+	}
+
+	inputState, locked, err := h.noto.findLockedCoin(ctx, req.StateQueryContext, params.LockID)
+	if err != nil {
+		return nil, err
+	}
+
+	var outputCoins []*types.NotoCoin
+	var outputStates []*prototk.NewState
+	infoDistribution := []string{notary, tx.Transaction.From}
+	total := big.NewInt(0)
+	for _, recipient := range params.Recipients {
+		toAddress, err := h.noto.findEthAddressVerifier(ctx, "to", recipient.To, req.ResolvedVerifiers)
+		if err != nil {
+			return nil, err
+		}
+		coins, states, err := h.noto.prepareOutputs(toAddress, recipient.Amount, []string{notary, tx.Transaction.From, recipient.To})
+		if err != nil {
+			return nil, err
+		}
+		outputCoins = append(outputCoins, coins...)
+		outputStates = append(outputStates, states...)
+		infoDistribution = append(infoDistribution, recipient.To)
+		total = total.Add(total, recipient.Amount.Int())
+	}
+	if total.Cmp(locked.Amount.Int()) != 0 {
+		return nil, i18n.NewError(ctx, msgs.MsgInvalidAmount, "unlock", locked.Amount.Int().Text(10), total.Text(10))
+	}
+
+	infoStates, err := h.noto.prepareInfo(params.Data, infoDistribution)
+	if err != nil {
+		return nil, err
+	}
+	attestation, err := h.noto.transferAttestationPlan(ctx, tx, []*types.NotoCoin{lockedAsCoin(locked)}, outputCoins)
+	if err != nil {
+		return nil, err
+	}
+
+	return &prototk.AssembleTransactionResponse{
+		AssemblyResult: prototk.AssembleTransactionResponse_OK,
+		AssembledTransaction: &prototk.AssembledTransaction{
+			InputStates:  []*prototk.StateRef{inputState},
+			OutputStates: outputStates,
+			InfoStates:   infoStates,
+		},
+		AttestationPlan: attestation,
+	}, nil
+}
+
+func (h *unlockHandler) Endorse(ctx context.Context, tx *types.ParsedTransaction, req *prototk.EndorseTransactionRequest) (*prototk.EndorseTransactionResponse, error) {
+	params := tx.Params.(*types.UnlockParams)
+	locks, err := h.noto.gatherLocks(ctx, req.Inputs, req.Outputs)
+	if err != nil {
+		return nil, err
+	}
+	if len(locks.inLocked) != 1 || len(locks.coins.inCoins) != 0 || locks.inLocked[0].LockID != params.LockID {
+		return nil, i18n.NewError(ctx, msgs.MsgInvalidInputs, "unlock", req.Inputs)
+	}
+	if len(locks.outLocked) != 0 || len(locks.coins.outCoins) != len(params.Recipients) {
+		return nil, i18n.NewError(ctx, msgs.MsgInvalidOutputs, "unlock", req.Outputs)
+	}
+
+	fromAddress, err := h.noto.findEthAddressVerifier(ctx, "from", tx.Transaction.From, req.ResolvedVerifiers)
+	if err != nil {
+		return nil, err
+	}
+	locked := locks.inLocked[0]
+	controller := lockController(locked, time.Now())
+	if !controller.Equals(fromAddress) {
+		return nil, i18n.NewError(ctx, msgs.MsgLockNotController, params.LockID, controller)
+	}
+
+	// Each output must pay the matching recipient
+	for i, recipient := range params.Recipients {
+		toAddress, err := h.noto.findEthAddressVerifier(ctx, "to", recipient.To, req.ResolvedVerifiers)
+		if err != nil {
+			return nil, err
+		}
+		coin := locks.coins.outCoins[i]
+		if !coin.Owner.Equals(toAddress) || coin.Amount.Int().Cmp(recipient.Amount.Int()) != 0 {
+			return nil, i18n.NewError(ctx, msgs.MsgInvalidOutputs, "unlock", locks.coins.outStates[i].Id)
+		}
+	}
+	if locks.coins.outTotal.Cmp(locked.Amount.Int()) != 0 {
+		return nil, i18n.NewError(ctx, msgs.MsgInvalidAmount, "unlock", locked.Amount.Int().Text(10), locks.coins.outTotal.Text(10))
+	}
+
+	signed := h.noto.signedLockCoins(locks)
+	if err := h.noto.validateStatus(ctx, req.StateQueryContext, signed); err != nil {
+		return nil, err
+	}
+	return h.noto.endorseTransfer(ctx, tx, req, signed)
+}
+
+func (h *unlockHandler) Prepare(ctx context.Context, tx *types.ParsedTransaction, req *prototk.PrepareTransactionRequest) (*prototk.PrepareTransactionResponse, error) {
+	baseTransaction, err := h.noto.baseLedgerTransfer(ctx, tx, req, "transfer")
+	if err != nil {
+		return nil, err
+	}
+	return baseTransaction.prepare(nil)
+}
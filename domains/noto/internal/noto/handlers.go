@@ -28,6 +28,7 @@ import (
 	"github.com/kaleido-io/paladin/toolkit/pkg/algorithms"
 	"github.com/kaleido-io/paladin/toolkit/pkg/domain"
 	"github.com/kaleido-io/paladin/toolkit/pkg/prototk"
+	"github.com/kaleido-io/paladin/toolkit/pkg/signpayloads"
 	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
 	"github.com/kaleido-io/paladin/toolkit/pkg/verifiers"
 )
@@ -42,6 +43,31 @@ func (n *Noto) GetHandler(method string) types.DomainHandler {
 		return &burnHandler{noto: n}
 	case "approveTransfer":
 		return &approveHandler{noto: n}
+	case "pause":
+		return &pauseHandler{noto: n, paused: true}
+	case "unpause":
+		return &pauseHandler{noto: n, paused: false}
+	case "freeze":
+		return &freezeHandler{noto: n, frozen: true}
+	case "unfreeze":
+		return &freezeHandler{noto: n, frozen: false}
+	case "forceTransfer":
+		return &forceTransferHandler{noto: n}
+	case "lock":
+		return &lockHandler{noto: n}
+	case "delegateLock":
+		return &delegateLockHandler{noto: n}
+	case "unlock":
+		return &unlockHandler{noto: n}
+	default:
+		return nil
+	}
+}
+
+func (n *Noto) GetCallHandler(method string) types.DomainCallHandler {
+	switch method {
+	case "balanceOf":
+		return &balanceOfHandler{noto: n}
 	default:
 		return nil
 	}
@@ -81,6 +107,23 @@ func (n *Noto) validateBurnAmounts(ctx context.Context, params *types.BurnParams
 	return nil
 }
 
+// Check that an operation reserved for the notary was initiated by the notary
+func (n *Noto) validateNotaryOnly(ctx context.Context, tx *types.ParsedTransaction) error {
+	notary := tx.DomainConfig.NotaryLookup
+	if tx.Transaction.From != notary {
+		return i18n.NewError(ctx, msgs.MsgNotaryOnly, tx.FunctionABI.Name, notary, tx.Transaction.From)
+	}
+	return n.validateNoHooks(ctx, tx)
+}
+
+// Check that an operation submitted directly to the base ledger is not being used with hooks
+func (n *Noto) validateNoHooks(ctx context.Context, tx *types.ParsedTransaction) error {
+	if tx.DomainConfig.NotaryType == types.NotaryTypePente {
+		return i18n.NewError(ctx, msgs.MsgNotSupportedWithHooks, tx.FunctionABI.Name)
+	}
+	return nil
+}
+
 // Check that the sender of a transfer provided a signature on the input transaction details
 func (n *Noto) validateTransferSignature(ctx context.Context, tx *types.ParsedTransaction, req *prototk.EndorseTransactionRequest, coins *gatheredCoins) error {
 	signature := domain.FindAttestation("sender", req.Signatures)
@@ -135,6 +178,163 @@ func (n *Noto) validateOwners(ctx context.Context, tx *types.ParsedTransaction,
 	return nil
 }
 
+// Attestation plan for a transfer of coins, which depends on the variant of the Noto contract
+func (n *Noto) transferAttestationPlan(ctx context.Context, tx *types.ParsedTransaction, inputCoins, outputCoins []*types.NotoCoin) ([]*prototk.AttestationRequest, error) {
+	notary := tx.DomainConfig.NotaryLookup
+	var attestation []*prototk.AttestationRequest
+	switch tx.DomainConfig.Variant {
+	case types.NotoVariantDefault:
+		encodedTransfer, err := n.encodeTransferUnmasked(ctx, tx.ContractAddress, inputCoins, outputCoins)
+		if err != nil {
+			return nil, err
+		}
+		attestation = []*prototk.AttestationRequest{
+			// Sender confirms the initial request with a signature
+			{
+				Name:            "sender",
+				AttestationType: prototk.AttestationType_SIGN,
+				Algorithm:       algorithms.ECDSA_SECP256K1,
+				VerifierType:    verifiers.ETH_ADDRESS,
+				Payload:         encodedTransfer,
+				PayloadType:     signpayloads.OPAQUE_TO_RSV,
+				Parties:         []string{tx.Transaction.From},
+			},
+			// Notary will endorse the assembled transaction (by submitting to the ledger)
+			{
+				Name:            "notary",
+				AttestationType: prototk.AttestationType_ENDORSE,
+				Algorithm:       algorithms.ECDSA_SECP256K1,
+				VerifierType:    verifiers.ETH_ADDRESS,
+				Parties:         []string{notary},
+			},
+		}
+	case types.NotoVariantSelfSubmit:
+		attestation = []*prototk.AttestationRequest{
+			// Notary will endorse the assembled transaction (by providing a signature)
+			{
+				Name:            "notary",
+				AttestationType: prototk.AttestationType_ENDORSE,
+				Algorithm:       algorithms.ECDSA_SECP256K1,
+				VerifierType:    verifiers.ETH_ADDRESS,
+				PayloadType:     signpayloads.OPAQUE_TO_RSV,
+				Parties:         []string{notary},
+			},
+			// Sender will endorse the assembled transaction (by submitting to the ledger)
+			{
+				Name:            "sender",
+				AttestationType: prototk.AttestationType_ENDORSE,
+				Algorithm:       algorithms.ECDSA_SECP256K1,
+				VerifierType:    verifiers.ETH_ADDRESS,
+				Parties:         []string{tx.Transaction.From},
+			},
+		}
+	default:
+		return nil, i18n.NewError(ctx, msgs.MsgUnknownDomainVariant, tx.DomainConfig.Variant)
+	}
+	return attestation, nil
+}
+
+// Endorse a transfer of coins, which depends on the variant of the Noto contract
+func (n *Noto) endorseTransfer(ctx context.Context, tx *types.ParsedTransaction, req *prototk.EndorseTransactionRequest, coins *gatheredCoins) (*prototk.EndorseTransactionResponse, error) {
+	switch tx.DomainConfig.Variant {
+	case types.NotoVariantDefault:
+		if req.EndorsementRequest.Name == "notary" {
+			// Notary checks the signature from the sender, then submits the transaction
+			if err := n.validateTransferSignature(ctx, tx, req, coins); err != nil {
+				return nil, err
+			}
+			return &prototk.EndorseTransactionResponse{
+				EndorsementResult: prototk.EndorseTransactionResponse_ENDORSER_SUBMIT,
+			}, nil
+		}
+	case types.NotoVariantSelfSubmit:
+		if req.EndorsementRequest.Name == "notary" {
+			// Notary provides a signature for the assembled payload (to be verified on base ledger)
+			inputIDs := make([]interface{}, len(req.Inputs))
+			outputIDs := make([]interface{}, len(req.Outputs))
+			for i, state := range req.Inputs {
+				inputIDs[i] = state.Id
+			}
+			for i, state := range req.Outputs {
+				outputIDs[i] = state.Id
+			}
+			data, err := n.encodeTransactionData(ctx, req.Transaction, req.Info)
+			if err != nil {
+				return nil, err
+			}
+			encodedTransfer, err := n.encodeTransferMasked(ctx, tx.ContractAddress, inputIDs, outputIDs, data)
+			if err != nil {
+				return nil, err
+			}
+			return &prototk.EndorseTransactionResponse{
+				EndorsementResult: prototk.EndorseTransactionResponse_SIGN,
+				Payload:           encodedTransfer,
+			}, nil
+		} else if req.EndorsementRequest.Name == "sender" {
+			if req.EndorsementVerifier.Lookup == tx.Transaction.From {
+				// Sender submits the transaction
+				return &prototk.EndorseTransactionResponse{
+					EndorsementResult: prototk.EndorseTransactionResponse_ENDORSER_SUBMIT,
+				}, nil
+			}
+		}
+	default:
+		return nil, i18n.NewError(ctx, msgs.MsgUnknownDomainVariant, tx.DomainConfig.Variant)
+	}
+
+	return nil, i18n.NewError(ctx, msgs.MsgUnrecognizedEndorsement, req.EndorsementRequest.Name)
+}
+
+// Prepare a call to a base ledger function that accepts inputs, outputs, a signature and data ("transfer" or "transferWithApproval")
+func (n *Noto) baseLedgerTransfer(ctx context.Context, tx *types.ParsedTransaction, req *prototk.PrepareTransactionRequest, fn string) (*TransactionWrapper, error) {
+	inputs := make([]string, len(req.InputStates))
+	for i, state := range req.InputStates {
+		inputs[i] = state.Id
+	}
+	outputs := make([]string, len(req.OutputStates))
+	for i, state := range req.OutputStates {
+		outputs[i] = state.Id
+	}
+
+	var signature *prototk.AttestationResult
+	switch tx.DomainConfig.Variant {
+	case types.NotoVariantDefault:
+		// Include the signature from the sender
+		// This is not verified on the base ledger, but can be verified by anyone with the unmasked state data
+		signature = domain.FindAttestation("sender", req.AttestationResult)
+		if signature == nil {
+			return nil, i18n.NewError(ctx, msgs.MsgAttestationNotFound, "sender")
+		}
+	case types.NotoVariantSelfSubmit:
+		// Include the signature from the notary (will be verified on base ledger)
+		signature = domain.FindAttestation("notary", req.AttestationResult)
+		if signature == nil {
+			return nil, i18n.NewError(ctx, msgs.MsgAttestationNotFound, "notary")
+		}
+	default:
+		return nil, i18n.NewError(ctx, msgs.MsgUnknownDomainVariant, tx.DomainConfig.Variant)
+	}
+
+	data, err := n.encodeTransactionData(ctx, req.Transaction, req.InfoStates)
+	if err != nil {
+		return nil, err
+	}
+	params := &NotoTransferParams{
+		Inputs:    inputs,
+		Outputs:   outputs,
+		Signature: signature.Payload,
+		Data:      data,
+	}
+	paramsJSON, err := json.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+	return &TransactionWrapper{
+		functionABI: n.contractABI.Functions()[fn],
+		paramsJSON:  paramsJSON,
+	}, nil
+}
+
 // Parse a resolved verifier as an eth address
 func (n *Noto) findEthAddressVerifier(ctx context.Context, label, lookup string, verifierList []*prototk.ResolvedVerifier) (*tktypes.EthAddress, error) {
 	verifier := domain.FindVerifier(lookup, algorithms.ECDSA_SECP256K1, verifiers.ETH_ADDRESS, verifierList)
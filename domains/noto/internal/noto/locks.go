@@ -0,0 +1,142 @@
+/*
+ * Copyright © 2024 Kaleido, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package noto
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/kaleido-io/paladin/domains/noto/internal/msgs"
+	"github.com/kaleido-io/paladin/domains/noto/pkg/types"
+	"github.com/kaleido-io/paladin/toolkit/pkg/prototk"
+	"github.com/kaleido-io/paladin/toolkit/pkg/query"
+	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
+)
+
+// The coins and locked coins spent and created by a lock operation
+type gatheredLocks struct {
+	coins     *gatheredCoins
+	inLocked  []*types.NotoLockedCoin
+	inStates  []*prototk.EndorsableState
+	outLocked []*types.NotoLockedCoin
+	outStates []*prototk.EndorsableState
+}
+
+func (n *Noto) unmarshalLockedCoin(stateData string) (*types.NotoLockedCoin, error) {
+	var locked types.NotoLockedCoin
+	err := json.Unmarshal([]byte(stateData), &locked)
+	return &locked, err
+}
+
+func (n *Noto) makeNewLockedCoinState(locked *types.NotoLockedCoin, distributionList []string) (*prototk.NewState, error) {
+	lockedJSON, err := json.Marshal(locked)
+	if err != nil {
+		return nil, err
+	}
+	return &prototk.NewState{
+		SchemaId:         n.lockedCoinSchema.Id,
+		StateDataJson:    string(lockedJSON),
+		DistributionList: distributionList,
+	}, nil
+}
+
+// Find the available locked coin for a lock ID
+func (n *Noto) findLockedCoin(ctx context.Context, stateQueryContext string, lockID tktypes.Bytes32) (*prototk.StateRef, *types.NotoLockedCoin, error) {
+	queryBuilder := query.NewQueryBuilder().
+		Limit(1).
+		Equal("lockId", lockID.String())
+	states, err := n.findAvailableStates(ctx, stateQueryContext, n.lockedCoinSchema.Id, queryBuilder.Query().String())
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(states) == 0 {
+		return nil, nil, i18n.NewError(ctx, msgs.MsgLockNotFound, lockID)
+	}
+	locked, err := n.unmarshalLockedCoin(states[0].DataJson)
+	if err != nil {
+		return nil, nil, i18n.NewError(ctx, msgs.MsgInvalidStateData, states[0].Id, err)
+	}
+	return &prototk.StateRef{SchemaId: states[0].SchemaId, Id: states[0].Id}, locked, nil
+}
+
+// Separate the coins from the locked coins, and parse both
+func (n *Noto) gatherLocks(ctx context.Context, inputs, outputs []*prototk.EndorsableState) (*gatheredLocks, error) {
+	var inCoinStates, outCoinStates []*prototk.EndorsableState
+	locks := &gatheredLocks{}
+	for _, state := range inputs {
+		if state.SchemaId == n.lockedCoinSchema.Id {
+			locked, err := n.unmarshalLockedCoin(state.StateDataJson)
+			if err != nil {
+				return nil, i18n.NewError(ctx, msgs.MsgInvalidStateData, state.Id, err)
+			}
+			locks.inLocked = append(locks.inLocked, locked)
+			locks.inStates = append(locks.inStates, state)
+		} else {
+			inCoinStates = append(inCoinStates, state)
+		}
+	}
+	for _, state := range outputs {
+		if state.SchemaId == n.lockedCoinSchema.Id {
+			locked, err := n.unmarshalLockedCoin(state.StateDataJson)
+			if err != nil {
+				return nil, i18n.NewError(ctx, msgs.MsgInvalidStateData, state.Id, err)
+			}
+			locks.outLocked = append(locks.outLocked, locked)
+			locks.outStates = append(locks.outStates, state)
+		} else {
+			outCoinStates = append(outCoinStates, state)
+		}
+	}
+	var err error
+	locks.coins, err = n.gatherCoins(ctx, inCoinStates, outCoinStates)
+	return locks, err
+}
+
+// The coins signed by the sender of a lock operation. Locked coins are signed as plain coins, and always come first.
+func (n *Noto) signedLockCoins(locks *gatheredLocks) *gatheredCoins {
+	signed := &gatheredCoins{}
+	for _, locked := range locks.inLocked {
+		signed.inCoins = append(signed.inCoins, lockedAsCoin(locked))
+	}
+	signed.inCoins = append(signed.inCoins, locks.coins.inCoins...)
+	for _, locked := range locks.outLocked {
+		signed.outCoins = append(signed.outCoins, lockedAsCoin(locked))
+	}
+	signed.outCoins = append(signed.outCoins, locks.coins.outCoins...)
+	return signed
+}
+
+func lockedAsCoin(locked *types.NotoLockedCoin) *types.NotoCoin {
+	return &types.NotoCoin{
+		Salt:   locked.Salt,
+		Owner:  locked.Owner,
+		Amount: locked.Amount,
+	}
+}
+
+// The party allowed to unlock or delegate a lock: the delegate until the lock expires, otherwise the owner
+func lockController(locked *types.NotoLockedCoin, now time.Time) *tktypes.EthAddress {
+	if !locked.Delegate.IsZero() && !lockExpired(locked, now) {
+		return locked.Delegate
+	}
+	return locked.Owner
+}
+
+func lockExpired(locked *types.NotoLockedCoin, now time.Time) bool {
+	return locked.Expiry != 0 && now.Unix() >= int64(locked.Expiry)
+}
@@ -0,0 +1,330 @@
+/*
+ * Copyright © 2024 Kaleido, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package noto
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/kaleido-io/paladin/domains/noto/pkg/types"
+	"github.com/kaleido-io/paladin/toolkit/pkg/prototk"
+	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func lockedCoinJSON(t *testing.T, lockID tktypes.Bytes32, owner, delegate *tktypes.EthAddress, amount int64, expiry int64) string {
+	if delegate == nil {
+		delegate = &tktypes.EthAddress{}
+	}
+	lockedJSON, err := json.Marshal(&types.NotoLockedCoin{
+		Salt:     tktypes.RandHex(32),
+		LockID:   lockID,
+		Owner:    owner,
+		Amount:   tktypes.Int64ToInt256(amount),
+		Delegate: delegate,
+		Expiry:   tktypes.HexUint64(expiry),
+	})
+	require.NoError(t, err)
+	return string(lockedJSON)
+}
+
+func newLockNoto(lockedState *prototk.StoredState, coinState *prototk.StoredState) *Noto {
+	return newStatusNoto(func(req *prototk.FindAvailableStatesRequest) (*prototk.FindAvailableStatesResponse, error) {
+		switch req.SchemaId {
+		case "locked":
+			if lockedState == nil {
+				return &prototk.FindAvailableStatesResponse{}, nil
+			}
+			return &prototk.FindAvailableStatesResponse{States: []*prototk.StoredState{lockedState}}, nil
+		case "coin":
+			return &prototk.FindAvailableStatesResponse{States: []*prototk.StoredState{coinState}}, nil
+		default:
+			return &prototk.FindAvailableStatesResponse{}, nil
+		}
+	})
+}
+
+func toEndorsable(states []*prototk.NewState) []*prototk.EndorsableState {
+	endorsable := make([]*prototk.EndorsableState, len(states))
+	for i, state := range states {
+		endorsable[i] = &prototk.EndorsableState{Id: tktypes.RandHex(32), SchemaId: state.SchemaId, StateDataJson: state.StateDataJson}
+	}
+	return endorsable
+}
+
+func TestLockParams(t *testing.T) {
+	n := &Noto{}
+	ctx := context.Background()
+
+	_, err := n.InitTransaction(ctx, &prototk.InitTransactionRequest{
+		Transaction: newNotoTransaction(t, `{"notaryLookup":"notary"}`, "lock", "alice", `{"amount":0}`),
+	})
+	assert.ErrorContains(t, err, "PD200008")
+
+	_, err = n.InitTransaction(ctx, &prototk.InitTransactionRequest{
+		Transaction: newNotoTransaction(t, `{"notaryLookup":"notary","notaryType":"0x1"}`, "lock", "alice", `{"amount":1}`),
+	})
+	assert.ErrorContains(t, err, "PD200027")
+
+	_, err = n.InitTransaction(ctx, &prototk.InitTransactionRequest{
+		Transaction: newNotoTransaction(t, `{"notaryLookup":"notary"}`, "delegateLock", "alice", `{"delegate":"bob"}`),
+	})
+	assert.ErrorContains(t, err, "PD200007")
+
+	_, err = n.InitTransaction(ctx, &prototk.InitTransactionRequest{
+		Transaction: newNotoTransaction(t, `{"notaryLookup":"notary"}`, "delegateLock", "alice", fmt.Sprintf(`{"lockId":"%s"}`, tktypes.RandHex(32))),
+	})
+	assert.ErrorContains(t, err, "PD200007")
+
+	_, err = n.InitTransaction(ctx, &prototk.InitTransactionRequest{
+		Transaction: newNotoTransaction(t, `{"notaryLookup":"notary"}`, "unlock", "alice", fmt.Sprintf(`{"lockId":"%s","recipients":[]}`, tktypes.RandHex(32))),
+	})
+	assert.ErrorContains(t, err, "PD200007")
+
+	_, err = n.InitTransaction(ctx, &prototk.InitTransactionRequest{
+		Transaction: newNotoTransaction(t, `{"notaryLookup":"notary"}`, "unlock", "alice", fmt.Sprintf(`{"lockId":"%s","recipients":[{"to":"bob","amount":0}]}`, tktypes.RandHex(32))),
+	})
+	assert.ErrorContains(t, err, "PD200008")
+
+	res, err := n.InitTransaction(ctx, &prototk.InitTransactionRequest{
+		Transaction: newNotoTransaction(t, `{"notaryLookup":"notary"}`, "unlock", "alice", fmt.Sprintf(`{"lockId":"%s","recipients":[{"to":"bob","amount":1},{"to":"carol","amount":2}]}`, tktypes.RandHex(32))),
+	})
+	require.NoError(t, err)
+	assert.Len(t, res.RequiredVerifiers, 4)
+}
+
+func TestLockAssembleAndEndorse(t *testing.T) {
+	ctx := context.Background()
+	notary := tktypes.RandAddress()
+	alice := tktypes.RandAddress()
+	coinJSON := fmt.Sprintf(`{"salt":"%s","owner":"%s","amount":"0x0f"}`, tktypes.RandHex(32), alice)
+	n := newLockNoto(nil, &prototk.StoredState{Id: tktypes.RandHex(32), SchemaId: "coin", DataJson: coinJSON})
+
+	tx := newNotoTransaction(t, selfSubmitConfig, "lock", "alice", `{"amount":10,"expiry":1000}`)
+	verifiers := []*prototk.ResolvedVerifier{resolvedVerifier("notary", notary), resolvedVerifier("alice", alice)}
+	assembleRes, err := n.AssembleTransaction(ctx, &prototk.AssembleTransactionRequest{
+		Transaction:       tx,
+		ResolvedVerifiers: verifiers,
+	})
+	require.NoError(t, err)
+	assembled := assembleRes.AssembledTransaction
+	require.Len(t, assembled.InputStates, 1)
+	require.Len(t, assembled.OutputStates, 2)
+	assert.Equal(t, "locked", assembled.OutputStates[0].SchemaId)
+	assert.Equal(t, "coin", assembled.OutputStates[1].SchemaId)
+
+	var locked types.NotoLockedCoin
+	err = json.Unmarshal([]byte(assembled.OutputStates[0].StateDataJson), &locked)
+	require.NoError(t, err)
+	assert.Equal(t, tktypes.MustParseBytes32(tx.TransactionId), locked.LockID)
+	assert.Equal(t, alice, locked.Owner)
+	assert.Equal(t, int64(10), locked.Amount.Int().Int64())
+	assert.Equal(t, uint64(1000), locked.Expiry.Uint64())
+	assert.True(t, locked.Delegate.IsZero())
+
+	inputs := []*prototk.EndorsableState{{Id: assembled.InputStates[0].Id, SchemaId: "coin", StateDataJson: coinJSON}}
+	outputs := toEndorsable(assembled.OutputStates)
+	endorseRes, err := n.EndorseTransaction(ctx, &prototk.EndorseTransactionRequest{
+		Transaction:        tx,
+		ResolvedVerifiers:  verifiers,
+		EndorsementRequest: &prototk.AttestationRequest{Name: "notary"},
+		Inputs:             inputs,
+		Outputs:            outputs,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, prototk.EndorseTransactionResponse_SIGN, endorseRes.EndorsementResult)
+
+	// Lock must match the request
+	_, err = n.EndorseTransaction(ctx, &prototk.EndorseTransactionRequest{
+		Transaction:        newNotoTransaction(t, selfSubmitConfig, "lock", "alice", `{"amount":10,"expiry":2000}`),
+		ResolvedVerifiers:  verifiers,
+		EndorsementRequest: &prototk.AttestationRequest{Name: "notary"},
+		Inputs:             inputs,
+		Outputs:            outputs,
+	})
+	assert.ErrorContains(t, err, "PD200030")
+
+	// Value must be conserved
+	_, err = n.EndorseTransaction(ctx, &prototk.EndorseTransactionRequest{
+		Transaction:        tx,
+		ResolvedVerifiers:  verifiers,
+		EndorsementRequest: &prototk.AttestationRequest{Name: "notary"},
+		Inputs:             inputs,
+		Outputs:            outputs[:1],
+	})
+	assert.ErrorContains(t, err, "PD200013")
+
+	_, err = n.EndorseTransaction(ctx, &prototk.EndorseTransactionRequest{
+		Transaction:        tx,
+		ResolvedVerifiers:  verifiers,
+		EndorsementRequest: &prototk.AttestationRequest{Name: "notary"},
+		Inputs:             inputs,
+		Outputs:            outputs[1:],
+	})
+	assert.ErrorContains(t, err, "PD200030")
+}
+
+func TestDelegateLock(t *testing.T) {
+	ctx := context.Background()
+	notary := tktypes.RandAddress()
+	alice := tktypes.RandAddress()
+	bob := tktypes.RandAddress()
+	carol := tktypes.RandAddress()
+	lockID := tktypes.Bytes32Keccak([]byte("lock1"))
+	lockedJSON := lockedCoinJSON(t, lockID, alice, nil, 10, 0)
+	lockedStateID := tktypes.RandHex(32)
+	n := newLockNoto(&prototk.StoredState{Id: lockedStateID, SchemaId: "locked", DataJson: lockedJSON}, nil)
+
+	tx := newNotoTransaction(t, selfSubmitConfig, "delegateLock", "alice", fmt.Sprintf(`{"lockId":"%s","delegate":"bob"}`, lockID))
+	verifiers := []*prototk.ResolvedVerifier{resolvedVerifier("notary", notary), resolvedVerifier("alice", alice), resolvedVerifier("bob", bob)}
+	assembleRes, err := n.AssembleTransaction(ctx, &prototk.AssembleTransactionRequest{
+		Transaction:       tx,
+		ResolvedVerifiers: verifiers,
+	})
+	require.NoError(t, err)
+	assembled := assembleRes.AssembledTransaction
+	require.Len(t, assembled.InputStates, 1)
+	assert.Equal(t, lockedStateID, assembled.InputStates[0].Id)
+	require.Len(t, assembled.OutputStates, 1)
+	assert.Equal(t, []string{"notary", "alice", "bob"}, assembled.OutputStates[0].DistributionList)
+
+	var delegated types.NotoLockedCoin
+	err = json.Unmarshal([]byte(assembled.OutputStates[0].StateDataJson), &delegated)
+	require.NoError(t, err)
+	assert.Equal(t, bob, delegated.Delegate)
+	assert.Equal(t, lockID, delegated.LockID)
+
+	endorse := func(tx *prototk.TransactionSpecification, inputJSON string) error {
+		_, err := n.EndorseTransaction(ctx, &prototk.EndorseTransactionRequest{
+			Transaction:        tx,
+			ResolvedVerifiers:  verifiers,
+			EndorsementRequest: &prototk.AttestationRequest{Name: "notary"},
+			Inputs:             []*prototk.EndorsableState{{Id: lockedStateID, SchemaId: "locked", StateDataJson: inputJSON}},
+			Outputs:            toEndorsable(assembled.OutputStates),
+		})
+		return err
+	}
+	require.NoError(t, endorse(tx, lockedJSON))
+
+	// Cannot take control from an active delegate
+	err = endorse(tx, lockedCoinJSON(t, lockID, alice, carol, 10, time.Now().Add(1*time.Hour).Unix()))
+	assert.ErrorContains(t, err, "PD200033")
+
+	// Can re-delegate once the previous delegation has expired
+	expiredJSON := lockedCoinJSON(t, lockID, alice, carol, 10, time.Now().Add(-1*time.Hour).Unix())
+	expiredNoto := newLockNoto(&prototk.StoredState{Id: lockedStateID, SchemaId: "locked", DataJson: expiredJSON}, nil)
+	reassembleRes, err := expiredNoto.AssembleTransaction(ctx, &prototk.AssembleTransactionRequest{
+		Transaction:       tx,
+		ResolvedVerifiers: verifiers,
+	})
+	require.NoError(t, err)
+	_, err = expiredNoto.EndorseTransaction(ctx, &prototk.EndorseTransactionRequest{
+		Transaction:        tx,
+		ResolvedVerifiers:  verifiers,
+		EndorsementRequest: &prototk.AttestationRequest{Name: "notary"},
+		Inputs:             []*prototk.EndorsableState{{Id: lockedStateID, SchemaId: "locked", StateDataJson: expiredJSON}},
+		Outputs:            toEndorsable(reassembleRes.AssembledTransaction.OutputStates),
+	})
+	require.NoError(t, err)
+
+	// Delegate is the only field that may change
+	err = endorse(tx, lockedCoinJSON(t, lockID, alice, nil, 11, 0))
+	assert.ErrorContains(t, err, "PD200030")
+
+	// Only the owner can delegate
+	err = endorse(newNotoTransaction(t, selfSubmitConfig, "delegateLock", "bob", fmt.Sprintf(`{"lockId":"%s","delegate":"bob"}`, lockID)), lockedJSON)
+	assert.ErrorContains(t, err, "PD200018")
+
+	// Lock ID must match
+	err = endorse(newNotoTransaction(t, selfSubmitConfig, "delegateLock", "alice", fmt.Sprintf(`{"lockId":"%s","delegate":"bob"}`, tktypes.RandHex(32))), lockedJSON)
+	assert.ErrorContains(t, err, "PD200012")
+
+	// Lock must exist
+	n = newLockNoto(nil, nil)
+	_, err = n.AssembleTransaction(ctx, &prototk.AssembleTransactionRequest{
+		Transaction:       tx,
+		ResolvedVerifiers: verifiers,
+	})
+	assert.ErrorContains(t, err, "PD200031")
+}
+
+func TestUnlock(t *testing.T) {
+	ctx := context.Background()
+	notary := tktypes.RandAddress()
+	alice := tktypes.RandAddress()
+	bob := tktypes.RandAddress()
+	carol := tktypes.RandAddress()
+	lockID := tktypes.Bytes32Keccak([]byte("lock1"))
+	activeJSON := lockedCoinJSON(t, lockID, alice, bob, 10, time.Now().Add(1*time.Hour).Unix())
+	expiredJSON := lockedCoinJSON(t, lockID, alice, bob, 10, time.Now().Add(-1*time.Hour).Unix())
+	lockedStateID := tktypes.RandHex(32)
+	n := newLockNoto(&prototk.StoredState{Id: lockedStateID, SchemaId: "locked", DataJson: activeJSON}, nil)
+
+	unlockParams := fmt.Sprintf(`{"lockId":"%s","recipients":[{"to":"carol","amount":7},{"to":"alice","amount":3}]}`, lockID)
+	tx := newNotoTransaction(t, selfSubmitConfig, "unlock", "bob", unlockParams)
+	verifiers := []*prototk.ResolvedVerifier{
+		resolvedVerifier("notary", notary),
+		resolvedVerifier("alice", alice),
+		resolvedVerifier("bob", bob),
+		resolvedVerifier("carol", carol),
+	}
+	assembleRes, err := n.AssembleTransaction(ctx, &prototk.AssembleTransactionRequest{
+		Transaction:       tx,
+		ResolvedVerifiers: verifiers,
+	})
+	require.NoError(t, err)
+	assembled := assembleRes.AssembledTransaction
+	require.Len(t, assembled.InputStates, 1)
+	require.Len(t, assembled.OutputStates, 2)
+	assert.Contains(t, assembled.OutputStates[0].StateDataJson, carol.String())
+	assert.Contains(t, assembled.OutputStates[1].StateDataJson, alice.String())
+
+	outputs := toEndorsable(assembled.OutputStates)
+	endorse := func(tx *prototk.TransactionSpecification, inputJSON string, outputs []*prototk.EndorsableState) error {
+		_, err := n.EndorseTransaction(ctx, &prototk.EndorseTransactionRequest{
+			Transaction:        tx,
+			ResolvedVerifiers:  verifiers,
+			EndorsementRequest: &prototk.AttestationRequest{Name: "notary"},
+			Inputs:             []*prototk.EndorsableState{{Id: lockedStateID, SchemaId: "locked", StateDataJson: inputJSON}},
+			Outputs:            outputs,
+		})
+		return err
+	}
+	require.NoError(t, endorse(tx, activeJSON, outputs))
+
+	// Owner cannot unlock while the delegate is in control, but can once the lock expires
+	ownerTX := newNotoTransaction(t, selfSubmitConfig, "unlock", "alice", unlockParams)
+	err = endorse(ownerTX, activeJSON, outputs)
+	assert.ErrorContains(t, err, "PD200032")
+	require.NoError(t, endorse(ownerTX, expiredJSON, outputs))
+
+	// Outputs must match the recipients
+	err = endorse(tx, activeJSON, []*prototk.EndorsableState{outputs[1], outputs[0]})
+	assert.ErrorContains(t, err, "PD200030")
+	err = endorse(tx, activeJSON, outputs[:1])
+	assert.ErrorContains(t, err, "PD200030")
+
+	// Recipients must add up to the locked amount
+	_, err = n.AssembleTransaction(ctx, &prototk.AssembleTransactionRequest{
+		Transaction:       newNotoTransaction(t, selfSubmitConfig, "unlock", "bob", fmt.Sprintf(`{"lockId":"%s","recipients":[{"to":"carol","amount":7}]}`, lockID)),
+		ResolvedVerifiers: verifiers,
+	})
+	assert.ErrorContains(t, err, "PD200013")
+}
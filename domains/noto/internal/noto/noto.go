@@ -53,15 +53,19 @@ func NewNoto(callbacks plugintk.DomainCallbacks) plugintk.DomainAPI {
 type Noto struct {
 	Callbacks plugintk.DomainCallbacks
 
-	name              string
-	config            types.DomainConfig
-	chainID           int64
-	coinSchema        *prototk.StateSchema
-	dataSchema        *prototk.StateSchema
-	factoryABI        abi.ABI
-	contractABI       abi.ABI
-	transferSignature string
-	approvedSignature string
+	name                 string
+	config               types.DomainConfig
+	chainID              int64
+	coinSchema           *prototk.StateSchema
+	dataSchema           *prototk.StateSchema
+	approvalSchema       *prototk.StateSchema
+	contractStatusSchema *prototk.StateSchema
+	accountStatusSchema  *prototk.StateSchema
+	lockedCoinSchema     *prototk.StateSchema
+	factoryABI           abi.ABI
+	contractABI          abi.ABI
+	transferSignature    string
+	approvedSignature    string
 }
 
 type NotoDeployParams struct {
@@ -161,6 +165,22 @@ func (n *Noto) ConfigureDomain(ctx context.Context, req *prototk.ConfigureDomain
 	if err != nil {
 		return nil, err
 	}
+	approvalSchemaJSON, err := json.Marshal(types.TransferApprovalABI)
+	if err != nil {
+		return nil, err
+	}
+	contractStatusSchemaJSON, err := json.Marshal(types.NotoContractStatusABI)
+	if err != nil {
+		return nil, err
+	}
+	accountStatusSchemaJSON, err := json.Marshal(types.NotoAccountStatusABI)
+	if err != nil {
+		return nil, err
+	}
+	lockedCoinSchemaJSON, err := json.Marshal(types.NotoLockedCoinABI)
+	if err != nil {
+		return nil, err
+	}
 
 	var events abi.ABI
 	for _, entry := range contract.ABI {
@@ -175,8 +195,15 @@ func (n *Noto) ConfigureDomain(ctx context.Context, req *prototk.ConfigureDomain
 
 	return &prototk.ConfigureDomainResponse{
 		DomainConfig: &prototk.DomainConfig{
-			AbiStateSchemasJson: []string{string(coinSchemaJSON), string(infoSchemaJSON)},
-			AbiEventsJson:       string(eventsJSON),
+			AbiStateSchemasJson: []string{
+				string(coinSchemaJSON),
+				string(infoSchemaJSON),
+				string(approvalSchemaJSON),
+				string(contractStatusSchemaJSON),
+				string(accountStatusSchemaJSON),
+				string(lockedCoinSchemaJSON),
+			},
+			AbiEventsJson: string(eventsJSON),
 		},
 	}, nil
 }
@@ -184,6 +211,10 @@ func (n *Noto) ConfigureDomain(ctx context.Context, req *prototk.ConfigureDomain
 func (n *Noto) InitDomain(ctx context.Context, req *prototk.InitDomainRequest) (*prototk.InitDomainResponse, error) {
 	n.coinSchema = req.AbiStateSchemas[0]
 	n.dataSchema = req.AbiStateSchemas[1]
+	n.approvalSchema = req.AbiStateSchemas[2]
+	n.contractStatusSchema = req.AbiStateSchemas[3]
+	n.accountStatusSchema = req.AbiStateSchemas[4]
+	n.lockedCoinSchema = req.AbiStateSchemas[5]
 	return &prototk.InitDomainResponse{}, nil
 }
 
@@ -369,43 +400,72 @@ func (n *Noto) validateTransaction(ctx context.Context, tx *prototk.TransactionS
 	if err != nil {
 		return nil, nil, err
 	}
-
-	var domainConfig *types.NotoParsedConfig
-	err = json.Unmarshal([]byte(tx.ContractInfo.ContractConfigJson), &domainConfig)
+	handler := n.GetHandler(functionABI.Name)
+	if handler == nil {
+		return nil, nil, i18n.NewError(ctx, msgs.MsgUnknownFunction, functionABI.Name)
+	}
+	parsedTx, err := n.parseTransaction(ctx, tx, &functionABI, handler.ValidateParams)
 	if err != nil {
 		return nil, nil, err
 	}
+	return parsedTx, handler, nil
+}
 
-	abi := types.NotoABI.Functions()[functionABI.Name]
-	handler := n.GetHandler(functionABI.Name)
-	if abi == nil || handler == nil {
+func (n *Noto) validateCall(ctx context.Context, tx *prototk.TransactionSpecification) (*types.ParsedTransaction, types.DomainCallHandler, error) {
+	var functionABI abi.Entry
+	err := json.Unmarshal([]byte(tx.FunctionAbiJson), &functionABI)
+	if err != nil {
+		return nil, nil, err
+	}
+	handler := n.GetCallHandler(functionABI.Name)
+	if handler == nil {
 		return nil, nil, i18n.NewError(ctx, msgs.MsgUnknownFunction, functionABI.Name)
 	}
-	params, err := handler.ValidateParams(ctx, domainConfig, tx.FunctionParamsJson)
+	parsedTx, err := n.parseTransaction(ctx, tx, &functionABI, handler.ValidateParams)
 	if err != nil {
 		return nil, nil, err
 	}
+	return parsedTx, handler, nil
+}
+
+func (n *Noto) parseTransaction(ctx context.Context, tx *prototk.TransactionSpecification, functionABI *abi.Entry,
+	validateParams func(ctx context.Context, config *types.NotoParsedConfig, params string) (interface{}, error),
+) (*types.ParsedTransaction, error) {
+	var domainConfig *types.NotoParsedConfig
+	err := json.Unmarshal([]byte(tx.ContractInfo.ContractConfigJson), &domainConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	abi := types.NotoABI.Functions()[functionABI.Name]
+	if abi == nil {
+		return nil, i18n.NewError(ctx, msgs.MsgUnknownFunction, functionABI.Name)
+	}
+	params, err := validateParams(ctx, domainConfig, tx.FunctionParamsJson)
+	if err != nil {
+		return nil, err
+	}
 
 	signature, err := abi.SolidityStringCtx(ctx)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 	if tx.FunctionSignature != signature {
-		return nil, nil, i18n.NewError(ctx, msgs.MsgUnexpectedFunctionSignature, functionABI.Name, signature, tx.FunctionSignature)
+		return nil, i18n.NewError(ctx, msgs.MsgUnexpectedFunctionSignature, functionABI.Name, signature, tx.FunctionSignature)
 	}
 
 	contractAddress, err := ethtypes.NewAddress(tx.ContractInfo.ContractAddress)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 
 	return &types.ParsedTransaction{
 		Transaction:     tx,
-		FunctionABI:     &functionABI,
+		FunctionABI:     functionABI,
 		ContractAddress: contractAddress,
 		DomainConfig:    domainConfig,
 		Params:          params,
-	}, handler, nil
+	}, nil
 }
 
 func (n *Noto) recoverSignature(ctx context.Context, payload ethtypes.HexBytes0xPrefix, signature []byte) (*ethtypes.Address0xHex, error) {
@@ -515,12 +575,13 @@ func (n *Noto) decodeTransactionData(ctx context.Context, data tktypes.HexBytes)
 	return &dataValues, err
 }
 
-func (n *Noto) parseStatesFromEvent(txID tktypes.Bytes32, states []tktypes.Bytes32) []*prototk.StateUpdate {
+func (n *Noto) parseStatesFromEvent(txID tktypes.Bytes32, states []tktypes.Bytes32, location *prototk.OnChainEventLocation) []*prototk.StateUpdate {
 	refs := make([]*prototk.StateUpdate, len(states))
 	for i, state := range states {
 		refs[i] = &prototk.StateUpdate{
 			Id:            state.String(),
 			TransactionId: txID.String(),
+			Location:      location,
 		}
 	}
 	return refs
@@ -541,12 +602,13 @@ func (n *Noto) HandleEventBatch(ctx context.Context, req *prototk.HandleEventBat
 					TransactionId: txData.TransactionID.String(),
 					Location:      ev.Location,
 				})
-				res.SpentStates = append(res.SpentStates, n.parseStatesFromEvent(txData.TransactionID, transfer.Inputs)...)
-				res.ConfirmedStates = append(res.ConfirmedStates, n.parseStatesFromEvent(txData.TransactionID, transfer.Outputs)...)
+				res.SpentStates = append(res.SpentStates, n.parseStatesFromEvent(txData.TransactionID, transfer.Inputs, ev.Location)...)
+				res.ConfirmedStates = append(res.ConfirmedStates, n.parseStatesFromEvent(txData.TransactionID, transfer.Outputs, ev.Location)...)
 				for _, state := range txData.InfoStates {
 					res.InfoStates = append(res.InfoStates, &prototk.StateUpdate{
 						Id:            state.String(),
 						TransactionId: txData.TransactionID.String(),
+						Location:      ev.Location,
 					})
 				}
 			}
@@ -566,6 +628,7 @@ func (n *Noto) HandleEventBatch(ctx context.Context, req *prototk.HandleEventBat
 					res.InfoStates = append(res.InfoStates, &prototk.StateUpdate{
 						Id:            state.String(),
 						TransactionId: txData.TransactionID.String(),
+						Location:      ev.Location,
 					})
 				}
 			}
@@ -586,15 +649,22 @@ func (n *Noto) ValidateStateHashes(ctx context.Context, req *prototk.ValidateSta
 	return nil, i18n.NewError(ctx, msgs.MsgNotImplemented)
 }
 
-func (n *Noto) InitCall(ctx context.Context, req *prototk.InitCallRequest) (*prototk.InitCallResponse, error) {
+func (n *Noto) HandleRPC(ctx context.Context, req *prototk.HandleRPCRequest) (*prototk.HandleRPCResponse, error) {
 	return nil, i18n.NewError(ctx, msgs.MsgNotImplemented)
 }
 
-func (n *Noto) ExecCall(ctx context.Context, req *prototk.ExecCallRequest) (*prototk.ExecCallResponse, error) {
-	return nil, i18n.NewError(ctx, msgs.MsgNotImplemented)
+func (n *Noto) InitCall(ctx context.Context, req *prototk.InitCallRequest) (*prototk.InitCallResponse, error) {
+	tx, handler, err := n.validateCall(ctx, req.Transaction)
+	if err != nil {
+		return nil, err
+	}
+	return handler.InitCall(ctx, tx, req)
 }
 
-func (n *Noto) BuildReceipt(ctx context.Context, req *prototk.BuildReceiptRequest) (*prototk.BuildReceiptResponse, error) {
-	// TODO: Event logs for transfers would be great for Noto
-	return nil, i18n.NewError(ctx, msgs.MsgNoDomainReceipt)
+func (n *Noto) ExecCall(ctx context.Context, req *prototk.ExecCallRequest) (*prototk.ExecCallResponse, error) {
+	tx, handler, err := n.validateCall(ctx, req.Transaction)
+	if err != nil {
+		return nil, err
+	}
+	return handler.ExecCall(ctx, tx, req)
 }
@@ -17,6 +17,7 @@ package noto
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"testing"
 
@@ -206,3 +207,126 @@ func TestPrepareTransactionBadAbi(t *testing.T) {
 	})
 	assert.ErrorContains(t, err, "invalid character")
 }
+
+type testDomainCallbacks struct {
+	returnFunc func(req *prototk.FindAvailableStatesRequest) (*prototk.FindAvailableStatesResponse, error)
+}
+
+func (dc *testDomainCallbacks) FindAvailableStates(ctx context.Context, req *prototk.FindAvailableStatesRequest) (*prototk.FindAvailableStatesResponse, error) {
+	return dc.returnFunc(req)
+}
+
+func (dc *testDomainCallbacks) EncodeData(ctx context.Context, req *prototk.EncodeDataRequest) (*prototk.EncodeDataResponse, error) {
+	return nil, nil
+}
+
+func (dc *testDomainCallbacks) RecoverSigner(ctx context.Context, req *prototk.RecoverSignerRequest) (*prototk.RecoverSignerResponse, error) {
+	return nil, nil
+}
+
+func (dc *testDomainCallbacks) QueryContractEvents(ctx context.Context, req *prototk.QueryContractEventsRequest) (*prototk.QueryContractEventsResponse, error) {
+	return nil, nil
+}
+
+func (dc *testDomainCallbacks) DecodeData(context.Context, *prototk.DecodeDataRequest) (*prototk.DecodeDataResponse, error) {
+	return nil, nil
+}
+
+func newBalanceOfTransaction(t *testing.T, account string) *prototk.TransactionSpecification {
+	balanceOfABI := types.NotoABI.Functions()["balanceOf"]
+	functionABI, err := json.Marshal(balanceOfABI)
+	require.NoError(t, err)
+	return &prototk.TransactionSpecification{
+		ContractInfo: &prototk.ContractInfo{
+			ContractAddress:    tktypes.RandAddress().String(),
+			ContractConfigJson: `{"notaryLookup":"notary"}`,
+		},
+		FunctionAbiJson:    string(functionABI),
+		FunctionSignature:  balanceOfABI.SolString(),
+		FunctionParamsJson: fmt.Sprintf(`{"account":"%s"}`, account),
+	}
+}
+
+func TestInitCallBadFunction(t *testing.T) {
+	n := &Noto{}
+	_, err := n.InitCall(context.Background(), &prototk.InitCallRequest{
+		Transaction: &prototk.TransactionSpecification{
+			ContractInfo: &prototk.ContractInfo{
+				ContractConfigJson: `{"notaryLookup":"notary"}`,
+			},
+			FunctionAbiJson: `{"name": "transfer"}`,
+		},
+	})
+	assert.ErrorContains(t, err, "PD200001")
+}
+
+func TestInitCallMissingAccount(t *testing.T) {
+	n := &Noto{}
+	_, err := n.InitCall(context.Background(), &prototk.InitCallRequest{
+		Transaction: newBalanceOfTransaction(t, ""),
+	})
+	assert.ErrorContains(t, err, "PD200007")
+}
+
+func TestBalanceOf(t *testing.T) {
+	ctx := context.Background()
+	owner := tktypes.RandAddress()
+	coinJSON := func(amount int64) string {
+		return fmt.Sprintf(`{"salt":"%s","owner":"%s","amount":"%d"}`, tktypes.RandHex(32), owner, amount)
+	}
+	var queries []string
+	n := &Noto{
+		coinSchema: &prototk.StateSchema{Id: "coin"},
+		Callbacks: &testDomainCallbacks{
+			returnFunc: func(req *prototk.FindAvailableStatesRequest) (*prototk.FindAvailableStatesResponse, error) {
+				queries = append(queries, req.QueryJson)
+				return &prototk.FindAvailableStatesResponse{
+					States: []*prototk.StoredState{
+						{Id: "state1", DataJson: coinJSON(10)},
+						{Id: "state2", DataJson: coinJSON(15)},
+					},
+				}, nil
+			},
+		},
+	}
+
+	tx := newBalanceOfTransaction(t, "alice")
+	initRes, err := n.InitCall(ctx, &prototk.InitCallRequest{Transaction: tx})
+	require.NoError(t, err)
+	require.Len(t, initRes.RequiredVerifiers, 1)
+	assert.Equal(t, "alice", initRes.RequiredVerifiers[0].Lookup)
+
+	verifiers := []*prototk.ResolvedVerifier{{
+		Lookup:       "alice",
+		Algorithm:    initRes.RequiredVerifiers[0].Algorithm,
+		VerifierType: initRes.RequiredVerifiers[0].VerifierType,
+		Verifier:     owner.String(),
+	}}
+	execRes, err := n.ExecCall(ctx, &prototk.ExecCallRequest{
+		Transaction:       tx,
+		ResolvedVerifiers: verifiers,
+		StateQueryContext: "query1",
+	})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"totalStates":"0x02","totalBalance":"0x19","overflow":false}`, execRes.ResultJson)
+	require.Len(t, queries, 1)
+	assert.Contains(t, queries[0], owner.String())
+
+	MaxBalanceStates = 1
+	defer func() { MaxBalanceStates = 1000 }()
+	execRes, err = n.ExecCall(ctx, &prototk.ExecCallRequest{
+		Transaction:       tx,
+		ResolvedVerifiers: verifiers,
+		StateQueryContext: "query1",
+	})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"totalStates":"0x01","totalBalance":"0x0a","overflow":true}`, execRes.ResultJson)
+}
+
+func TestBalanceOfMissingVerifier(t *testing.T) {
+	n := &Noto{}
+	_, err := n.ExecCall(context.Background(), &prototk.ExecCallRequest{
+		Transaction: newBalanceOfTransaction(t, "alice"),
+	})
+	assert.ErrorContains(t, err, "PD200011")
+}
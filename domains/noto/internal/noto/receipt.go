@@ -0,0 +1,205 @@
+/*
+ * Copyright © 2024 Kaleido, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package noto
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/kaleido-io/paladin/domains/noto/internal/msgs"
+	"github.com/kaleido-io/paladin/domains/noto/pkg/types"
+	"github.com/kaleido-io/paladin/toolkit/pkg/prototk"
+	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
+)
+
+func (n *Noto) BuildReceipt(ctx context.Context, req *prototk.BuildReceiptRequest) (*prototk.BuildReceiptResponse, error) {
+	receipt := &types.NotoDomainReceipt{}
+
+	var err error
+	receipt.States.Inputs, err = n.receiptStates(ctx, req.InputStates)
+	if err != nil {
+		return nil, err
+	}
+	receipt.States.Outputs, err = n.receiptStates(ctx, req.OutputStates)
+	if err != nil {
+		return nil, err
+	}
+	receipt.States.LockedInputs, err = n.receiptLockedStates(ctx, req.InputStates)
+	if err != nil {
+		return nil, err
+	}
+	receipt.States.LockedOutputs, err = n.receiptLockedStates(ctx, req.OutputStates)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, state := range req.InfoStates {
+		switch state.SchemaId {
+		case n.dataSchema.Id:
+			var info types.TransactionData
+			if err := json.Unmarshal([]byte(state.StateDataJson), &info); err != nil {
+				return nil, i18n.NewError(ctx, msgs.MsgInvalidStateData, state.Id, err)
+			}
+			receipt.Data = info.Data
+		case n.approvalSchema.Id:
+			var approval types.TransferApproval
+			if err := json.Unmarshal([]byte(state.StateDataJson), &approval); err != nil {
+				return nil, i18n.NewError(ctx, msgs.MsgInvalidStateData, state.Id, err)
+			}
+			receipt.Delegate = approval.Delegate
+		}
+	}
+
+	// Transfers can only be derived when all the inputs and outputs are available,
+	// as a missing input would otherwise be reported as a mint
+	if req.Complete {
+		receipt.Transfers = n.receiptTransfers(&receipt.States)
+	}
+
+	receiptJSON, err := json.Marshal(receipt)
+	if err != nil {
+		return nil, err
+	}
+	return &prototk.BuildReceiptResponse{
+		ReceiptJson: string(receiptJSON),
+	}, nil
+}
+
+func (n *Noto) receiptStates(ctx context.Context, states []*prototk.EndorsableState) ([]*types.ReceiptState, error) {
+	coins := make([]*types.ReceiptState, 0, len(states))
+	for _, state := range states {
+		if state.SchemaId != n.coinSchema.Id {
+			continue
+		}
+		id, err := tktypes.ParseHexBytes(ctx, state.Id)
+		if err != nil {
+			return nil, i18n.NewError(ctx, msgs.MsgInvalidStateData, state.Id, err)
+		}
+		coin, err := n.unmarshalCoin(state.StateDataJson)
+		if err != nil {
+			return nil, i18n.NewError(ctx, msgs.MsgInvalidStateData, state.Id, err)
+		}
+		coins = append(coins, &types.ReceiptState{ID: id, Data: coin})
+	}
+	return coins, nil
+}
+
+func (n *Noto) receiptLockedStates(ctx context.Context, states []*prototk.EndorsableState) ([]*types.ReceiptLockedState, error) {
+	var locked []*types.ReceiptLockedState
+	for _, state := range states {
+		if state.SchemaId != n.lockedCoinSchema.Id {
+			continue
+		}
+		id, err := tktypes.ParseHexBytes(ctx, state.Id)
+		if err != nil {
+			return nil, i18n.NewError(ctx, msgs.MsgInvalidStateData, state.Id, err)
+		}
+		coin, err := n.unmarshalLockedCoin(state.StateDataJson)
+		if err != nil {
+			return nil, i18n.NewError(ctx, msgs.MsgInvalidStateData, state.Id, err)
+		}
+		locked = append(locked, &types.ReceiptLockedState{ID: id, Data: coin})
+	}
+	return locked, nil
+}
+
+type ownerBalance struct {
+	owner  *tktypes.EthAddress
+	amount *big.Int
+}
+
+// Net off the inputs and outputs of each owner (so change returned to the sender, and value they lock, is not reported),
+// then match the owners who lost value to those who gained it. Any value lost without a matching
+// recipient was burned, and any value gained without a matching sender was minted.
+func (n *Noto) receiptTransfers(states *types.ReceiptStates) []*types.ReceiptTransfer {
+	var owners []*ownerBalance
+	balances := make(map[tktypes.EthAddress]*ownerBalance)
+	adjust := func(coin *types.NotoCoin, sign int) {
+		if coin.Owner == nil || coin.Amount == nil {
+			return
+		}
+		b := balances[*coin.Owner]
+		if b == nil {
+			b = &ownerBalance{owner: coin.Owner, amount: big.NewInt(0)}
+			balances[*coin.Owner] = b
+			owners = append(owners, b)
+		}
+		if sign < 0 {
+			b.amount.Sub(b.amount, coin.Amount.Int())
+		} else {
+			b.amount.Add(b.amount, coin.Amount.Int())
+		}
+	}
+	for _, state := range states.Inputs {
+		adjust(state.Data, -1)
+	}
+	for _, state := range states.LockedInputs {
+		adjust(lockedAsCoin(state.Data), -1)
+	}
+	for _, state := range states.Outputs {
+		adjust(state.Data, 1)
+	}
+	for _, state := range states.LockedOutputs {
+		adjust(lockedAsCoin(state.Data), 1)
+	}
+
+	var senders, recipients []*ownerBalance
+	for _, b := range owners {
+		switch b.amount.Sign() {
+		case -1:
+			senders = append(senders, &ownerBalance{owner: b.owner, amount: new(big.Int).Neg(b.amount)})
+		case 1:
+			recipients = append(recipients, b)
+		}
+	}
+
+	var transfers []*types.ReceiptTransfer
+	for len(senders) > 0 && len(recipients) > 0 {
+		from, to := senders[0], recipients[0]
+		amount := new(big.Int).Set(from.amount)
+		if to.amount.Cmp(amount) < 0 {
+			amount.Set(to.amount)
+		}
+		transfers = append(transfers, &types.ReceiptTransfer{
+			From:   from.owner,
+			To:     to.owner,
+			Amount: (*tktypes.HexUint256)(amount),
+		})
+		from.amount.Sub(from.amount, amount)
+		to.amount.Sub(to.amount, amount)
+		if from.amount.Sign() == 0 {
+			senders = senders[1:]
+		}
+		if to.amount.Sign() == 0 {
+			recipients = recipients[1:]
+		}
+	}
+	for _, from := range senders {
+		transfers = append(transfers, &types.ReceiptTransfer{
+			From:   from.owner,
+			Amount: (*tktypes.HexUint256)(from.amount),
+		})
+	}
+	for _, to := range recipients {
+		transfers = append(transfers, &types.ReceiptTransfer{
+			To:     to.owner,
+			Amount: (*tktypes.HexUint256)(to.amount),
+		})
+	}
+	return transfers
+}
@@ -0,0 +1,221 @@
+/*
+ * Copyright © 2024 Kaleido, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package noto
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/kaleido-io/paladin/domains/noto/pkg/types"
+	"github.com/kaleido-io/paladin/toolkit/pkg/prototk"
+	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newReceiptNoto() *Noto {
+	return &Noto{
+		coinSchema:       &prototk.StateSchema{Id: "coin"},
+		dataSchema:       &prototk.StateSchema{Id: "data"},
+		approvalSchema:   &prototk.StateSchema{Id: "approval"},
+		lockedCoinSchema: &prototk.StateSchema{Id: "locked"},
+	}
+}
+
+func receiptCoin(owner *tktypes.EthAddress, amount int64) *prototk.EndorsableState {
+	return &prototk.EndorsableState{
+		Id:            tktypes.RandHex(32),
+		SchemaId:      "coin",
+		StateDataJson: fmt.Sprintf(`{"salt":"%s","owner":"%s","amount":"%d"}`, tktypes.RandHex(32), owner, amount),
+	}
+}
+
+func buildReceipt(t *testing.T, n *Noto, req *prototk.BuildReceiptRequest) *types.NotoDomainReceipt {
+	res, err := n.BuildReceipt(context.Background(), req)
+	require.NoError(t, err)
+	var receipt types.NotoDomainReceipt
+	err = json.Unmarshal([]byte(res.ReceiptJson), &receipt)
+	require.NoError(t, err)
+	return &receipt
+}
+
+func TestBuildReceiptTransfer(t *testing.T) {
+	n := newReceiptNoto()
+	alice := tktypes.RandAddress()
+	bob := tktypes.RandAddress()
+
+	receipt := buildReceipt(t, n, &prototk.BuildReceiptRequest{
+		Complete:     true,
+		InputStates:  []*prototk.EndorsableState{receiptCoin(alice, 60), receiptCoin(alice, 40)},
+		OutputStates: []*prototk.EndorsableState{receiptCoin(bob, 75), receiptCoin(alice, 25)},
+		InfoStates: []*prototk.EndorsableState{
+			{Id: tktypes.RandHex(32), SchemaId: "data", StateDataJson: `{"salt":"0x01","data":"0xfeedbeef"}`},
+		},
+	})
+	assert.Len(t, receipt.States.Inputs, 2)
+	assert.Len(t, receipt.States.Outputs, 2)
+	assert.Equal(t, bob, receipt.States.Outputs[0].Data.Owner)
+	assert.Equal(t, "0xfeedbeef", receipt.Data.String())
+	assert.Nil(t, receipt.Delegate)
+	require.Len(t, receipt.Transfers, 1)
+	assert.Equal(t, alice, receipt.Transfers[0].From)
+	assert.Equal(t, bob, receipt.Transfers[0].To)
+	assert.Equal(t, int64(75), receipt.Transfers[0].Amount.Int().Int64())
+}
+
+func TestBuildReceiptMintAndBurn(t *testing.T) {
+	n := newReceiptNoto()
+	alice := tktypes.RandAddress()
+
+	receipt := buildReceipt(t, n, &prototk.BuildReceiptRequest{
+		Complete:     true,
+		OutputStates: []*prototk.EndorsableState{receiptCoin(alice, 100)},
+	})
+	require.Len(t, receipt.Transfers, 1)
+	assert.Nil(t, receipt.Transfers[0].From)
+	assert.Equal(t, alice, receipt.Transfers[0].To)
+	assert.Equal(t, int64(100), receipt.Transfers[0].Amount.Int().Int64())
+
+	receipt = buildReceipt(t, n, &prototk.BuildReceiptRequest{
+		Complete:     true,
+		InputStates:  []*prototk.EndorsableState{receiptCoin(alice, 100)},
+		OutputStates: []*prototk.EndorsableState{receiptCoin(alice, 70)},
+	})
+	require.Len(t, receipt.Transfers, 1)
+	assert.Equal(t, alice, receipt.Transfers[0].From)
+	assert.Nil(t, receipt.Transfers[0].To)
+	assert.Equal(t, int64(30), receipt.Transfers[0].Amount.Int().Int64())
+}
+
+func TestBuildReceiptMultipleOwners(t *testing.T) {
+	n := newReceiptNoto()
+	alice := tktypes.RandAddress()
+	bob := tktypes.RandAddress()
+	carol := tktypes.RandAddress()
+
+	receipt := buildReceipt(t, n, &prototk.BuildReceiptRequest{
+		Complete:     true,
+		InputStates:  []*prototk.EndorsableState{receiptCoin(alice, 50), receiptCoin(bob, 50)},
+		OutputStates: []*prototk.EndorsableState{receiptCoin(carol, 80)},
+	})
+	require.Len(t, receipt.Transfers, 3)
+	assert.Equal(t, alice, receipt.Transfers[0].From)
+	assert.Equal(t, carol, receipt.Transfers[0].To)
+	assert.Equal(t, int64(50), receipt.Transfers[0].Amount.Int().Int64())
+	assert.Equal(t, bob, receipt.Transfers[1].From)
+	assert.Equal(t, carol, receipt.Transfers[1].To)
+	assert.Equal(t, int64(30), receipt.Transfers[1].Amount.Int().Int64())
+	assert.Equal(t, bob, receipt.Transfers[2].From)
+	assert.Nil(t, receipt.Transfers[2].To)
+	assert.Equal(t, int64(20), receipt.Transfers[2].Amount.Int().Int64())
+}
+
+func TestBuildReceiptApproval(t *testing.T) {
+	n := newReceiptNoto()
+	delegate := tktypes.RandAddress()
+
+	receipt := buildReceipt(t, n, &prototk.BuildReceiptRequest{
+		Complete: true,
+		InfoStates: []*prototk.EndorsableState{
+			{Id: tktypes.RandHex(32), SchemaId: "data", StateDataJson: `{"salt":"0x01","data":"0x"}`},
+			{Id: tktypes.RandHex(32), SchemaId: "approval", StateDataJson: fmt.Sprintf(`{"salt":"0x02","delegate":"%s","transferHash":"%s"}`, delegate, tktypes.RandHex(32))},
+		},
+	})
+	assert.Equal(t, delegate, receipt.Delegate)
+	assert.Empty(t, receipt.Transfers)
+}
+
+func TestBuildReceiptIncomplete(t *testing.T) {
+	n := newReceiptNoto()
+	bob := tktypes.RandAddress()
+
+	receipt := buildReceipt(t, n, &prototk.BuildReceiptRequest{
+		Complete:     false,
+		OutputStates: []*prototk.EndorsableState{receiptCoin(bob, 10)},
+	})
+	assert.Len(t, receipt.States.Outputs, 1)
+	assert.Empty(t, receipt.Transfers)
+}
+
+func TestBuildReceiptBadStates(t *testing.T) {
+	n := newReceiptNoto()
+	ctx := context.Background()
+
+	_, err := n.BuildReceipt(ctx, &prototk.BuildReceiptRequest{
+		InputStates: []*prototk.EndorsableState{{Id: "bad", SchemaId: "coin", StateDataJson: "{}"}},
+	})
+	assert.ErrorContains(t, err, "PD200006")
+
+	_, err = n.BuildReceipt(ctx, &prototk.BuildReceiptRequest{
+		OutputStates: []*prototk.EndorsableState{{Id: tktypes.RandHex(32), SchemaId: "coin", StateDataJson: "!json"}},
+	})
+	assert.ErrorContains(t, err, "PD200006")
+
+	_, err = n.BuildReceipt(ctx, &prototk.BuildReceiptRequest{
+		InfoStates: []*prototk.EndorsableState{{Id: tktypes.RandHex(32), SchemaId: "data", StateDataJson: "!json"}},
+	})
+	assert.ErrorContains(t, err, "PD200006")
+
+	_, err = n.BuildReceipt(ctx, &prototk.BuildReceiptRequest{
+		InfoStates: []*prototk.EndorsableState{{Id: tktypes.RandHex(32), SchemaId: "approval", StateDataJson: "!json"}},
+	})
+	assert.ErrorContains(t, err, "PD200006")
+}
+
+func receiptLockedCoin(owner *tktypes.EthAddress, amount int64) *prototk.EndorsableState {
+	return &prototk.EndorsableState{
+		Id:       tktypes.RandHex(32),
+		SchemaId: "locked",
+		StateDataJson: fmt.Sprintf(`{"salt":"%s","lockId":"%s","owner":"%s","amount":"%d","delegate":"%s","expiry":"0x0"}`,
+			tktypes.RandHex(32), tktypes.RandHex(32), owner, amount, tktypes.EthAddress{}),
+	}
+}
+
+func TestBuildReceiptLockAndUnlock(t *testing.T) {
+	n := newReceiptNoto()
+	alice := tktypes.RandAddress()
+	bob := tktypes.RandAddress()
+
+	// Locking value moves it between states of the same owner
+	receipt := buildReceipt(t, n, &prototk.BuildReceiptRequest{
+		Complete:     true,
+		InputStates:  []*prototk.EndorsableState{receiptCoin(alice, 100)},
+		OutputStates: []*prototk.EndorsableState{receiptLockedCoin(alice, 60), receiptCoin(alice, 40)},
+	})
+	assert.Len(t, receipt.States.Inputs, 1)
+	assert.Len(t, receipt.States.Outputs, 1)
+	require.Len(t, receipt.States.LockedOutputs, 1)
+	assert.Equal(t, alice, receipt.States.LockedOutputs[0].Data.Owner)
+	assert.Empty(t, receipt.Transfers)
+
+	receipt = buildReceipt(t, n, &prototk.BuildReceiptRequest{
+		Complete:     true,
+		InputStates:  []*prototk.EndorsableState{receiptLockedCoin(alice, 60)},
+		OutputStates: []*prototk.EndorsableState{receiptCoin(bob, 60)},
+	})
+	require.Len(t, receipt.States.LockedInputs, 1)
+	require.Len(t, receipt.Transfers, 1)
+	assert.Equal(t, alice, receipt.Transfers[0].From)
+	assert.Equal(t, bob, receipt.Transfers[0].To)
+	assert.Equal(t, int64(60), receipt.Transfers[0].Amount.Int().Int64())
+
+	_, err := n.BuildReceipt(context.Background(), &prototk.BuildReceiptRequest{
+		InputStates: []*prototk.EndorsableState{{Id: tktypes.RandHex(32), SchemaId: "locked", StateDataJson: "!json"}},
+	})
+	assert.ErrorContains(t, err, "PD200006")
+}
@@ -34,6 +34,9 @@ import (
 var EIP712DomainName = "noto"
 var EIP712DomainVersion = "0.0.1"
 
+// The maximum number of coins summed by a balanceOf call, beyond which the result is flagged as an overflow
+var MaxBalanceStates = 1000
+
 var NotoTransferUnmaskedTypeSet = eip712.TypeSet{
 	"Transfer": {
 		{Name: "inputs", Type: "Coin[]"},
@@ -113,7 +116,7 @@ func (n *Noto) prepareInputs(ctx context.Context, stateQueryContext string, owne
 		}
 
 		log.L(ctx).Debugf("State query: %s", queryBuilder.Query())
-		states, err := n.findAvailableStates(ctx, stateQueryContext, queryBuilder.Query().String())
+		states, err := n.findAvailableStates(ctx, stateQueryContext, n.coinSchema.Id, queryBuilder.Query().String())
 
 		if err != nil {
 			return nil, nil, nil, err
@@ -141,6 +144,32 @@ func (n *Noto) prepareInputs(ctx context.Context, stateQueryContext string, owne
 	}
 }
 
+// Sum the available coins for an owner, up to a maximum number of states
+func (n *Noto) getAccountBalance(ctx context.Context, stateQueryContext string, owner *tktypes.EthAddress) (totalStates int, totalBalance *big.Int, overflow bool, err error) {
+	queryBuilder := query.NewQueryBuilder().
+		Limit(MaxBalanceStates+1).
+		Sort(".created").
+		Equal("owner", owner.String())
+
+	states, err := n.findAvailableStates(ctx, stateQueryContext, n.coinSchema.Id, queryBuilder.Query().String())
+	if err != nil {
+		return 0, nil, false, err
+	}
+	if len(states) > MaxBalanceStates {
+		overflow = true
+		states = states[:MaxBalanceStates]
+	}
+	totalBalance = big.NewInt(0)
+	for _, state := range states {
+		coin, err := n.unmarshalCoin(state.DataJson)
+		if err != nil {
+			return 0, nil, false, i18n.NewError(ctx, msgs.MsgInvalidStateData, state.Id, err)
+		}
+		totalBalance = totalBalance.Add(totalBalance, coin.Amount.Int())
+	}
+	return len(states), totalBalance, overflow, nil
+}
+
 func (n *Noto) prepareOutputs(ownerAddress *tktypes.EthAddress, amount *tktypes.HexUint256, distributionList []string) ([]*types.NotoCoin, []*prototk.NewState, error) {
 	// Always produce a single coin for the entire output amount
 	// TODO: make this configurable
@@ -162,10 +191,26 @@ func (n *Noto) prepareInfo(data tktypes.HexBytes, distributionList []string) ([]
 	return []*prototk.NewState{newState}, err
 }
 
-func (n *Noto) findAvailableStates(ctx context.Context, stateQueryContext, query string) ([]*prototk.StoredState, error) {
+func (n *Noto) prepareApprovalInfo(delegate *tktypes.EthAddress, transferHash tktypes.Bytes32, distributionList []string) (*prototk.NewState, error) {
+	approvalJSON, err := json.Marshal(&types.TransferApproval{
+		Salt:         tktypes.RandHex(32),
+		Delegate:     delegate,
+		TransferHash: transferHash,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &prototk.NewState{
+		SchemaId:         n.approvalSchema.Id,
+		StateDataJson:    string(approvalJSON),
+		DistributionList: distributionList,
+	}, nil
+}
+
+func (n *Noto) findAvailableStates(ctx context.Context, stateQueryContext, schemaID, query string) ([]*prototk.StoredState, error) {
 	req := &prototk.FindAvailableStatesRequest{
 		StateQueryContext: stateQueryContext,
-		SchemaId:          n.coinSchema.Id,
+		SchemaId:          schemaID,
 		QueryJson:         query,
 	}
 	res, err := n.Callbacks.FindAvailableStates(ctx, req)
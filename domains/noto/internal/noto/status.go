@@ -0,0 +1,173 @@
+/*
+ * Copyright © 2024 Kaleido, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package noto
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/kaleido-io/paladin/domains/noto/internal/msgs"
+	"github.com/kaleido-io/paladin/domains/noto/pkg/types"
+	"github.com/kaleido-io/paladin/toolkit/pkg/prototk"
+	"github.com/kaleido-io/paladin/toolkit/pkg/query"
+	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
+)
+
+// Find the current contract status states (there should be at most one)
+func (n *Noto) findContractStatus(ctx context.Context, stateQueryContext string) ([]*prototk.StoredState, error) {
+	queryBuilder := query.NewQueryBuilder().
+		Limit(10).
+		Sort(".created")
+	return n.findAvailableStates(ctx, stateQueryContext, n.contractStatusSchema.Id, queryBuilder.Query().String())
+}
+
+// Find the current status states for a set of accounts (there should be at most one per account)
+func (n *Noto) findAccountStatus(ctx context.Context, stateQueryContext string, accounts []*tktypes.EthAddress) ([]*prototk.StoredState, error) {
+	values := make([]any, len(accounts))
+	for i, account := range accounts {
+		values[i] = account.String()
+	}
+	queryBuilder := query.NewQueryBuilder().
+		Limit(len(accounts)*10).
+		Sort(".created").
+		In("account", values)
+	return n.findAvailableStates(ctx, stateQueryContext, n.accountStatusSchema.Id, queryBuilder.Query().String())
+}
+
+// Check that the contract is not paused, and that none of the owners of the input or output coins are frozen
+func (n *Noto) validateStatus(ctx context.Context, stateQueryContext string, coins *gatheredCoins) error {
+	contractStates, err := n.findContractStatus(ctx, stateQueryContext)
+	if err != nil {
+		return err
+	}
+	for _, state := range contractStates {
+		var status types.NotoContractStatus
+		if err := json.Unmarshal([]byte(state.DataJson), &status); err != nil {
+			return i18n.NewError(ctx, msgs.MsgInvalidStateData, state.Id, err)
+		}
+		if status.Paused {
+			return i18n.NewError(ctx, msgs.MsgContractPaused)
+		}
+	}
+
+	var owners []*tktypes.EthAddress
+	seen := make(map[tktypes.EthAddress]bool)
+	for _, coin := range append(append([]*types.NotoCoin{}, coins.inCoins...), coins.outCoins...) {
+		if coin.Owner != nil && !seen[*coin.Owner] {
+			seen[*coin.Owner] = true
+			owners = append(owners, coin.Owner)
+		}
+	}
+	if len(owners) == 0 {
+		return nil
+	}
+	accountStates, err := n.findAccountStatus(ctx, stateQueryContext, owners)
+	if err != nil {
+		return err
+	}
+	for _, state := range accountStates {
+		var status types.NotoAccountStatus
+		if err := json.Unmarshal([]byte(state.DataJson), &status); err != nil {
+			return i18n.NewError(ctx, msgs.MsgInvalidStateData, state.Id, err)
+		}
+		if status.Frozen {
+			return i18n.NewError(ctx, msgs.MsgAccountFrozen, status.Account)
+		}
+	}
+	return nil
+}
+
+// Spend any existing contract status, and replace it with a new one
+func (n *Noto) prepareContractStatus(ctx context.Context, stateQueryContext string, paused bool, distributionList []string) ([]*prototk.StateRef, []*prototk.NewState, error) {
+	existing, err := n.findContractStatus(ctx, stateQueryContext)
+	if err != nil {
+		return nil, nil, err
+	}
+	statusJSON, err := json.Marshal(&types.NotoContractStatus{
+		Salt:   tktypes.RandHex(32),
+		Paused: paused,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return n.statusStateRefs(existing), []*prototk.NewState{{
+		SchemaId:         n.contractStatusSchema.Id,
+		StateDataJson:    string(statusJSON),
+		DistributionList: distributionList,
+	}}, nil
+}
+
+// Spend any existing status for an account, and replace it with a new one
+func (n *Noto) prepareAccountStatus(ctx context.Context, stateQueryContext string, account *tktypes.EthAddress, frozen bool, distributionList []string) ([]*prototk.StateRef, []*prototk.NewState, error) {
+	existing, err := n.findAccountStatus(ctx, stateQueryContext, []*tktypes.EthAddress{account})
+	if err != nil {
+		return nil, nil, err
+	}
+	statusJSON, err := json.Marshal(&types.NotoAccountStatus{
+		Salt:    tktypes.RandHex(32),
+		Account: account,
+		Frozen:  frozen,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return n.statusStateRefs(existing), []*prototk.NewState{{
+		SchemaId:         n.accountStatusSchema.Id,
+		StateDataJson:    string(statusJSON),
+		DistributionList: distributionList,
+	}}, nil
+}
+
+// Status states are shared with the notary and any directly affected party, plus the counterparties
+// that the notary lists on the transaction, so that each of them can verify the status
+func statusDistributionList(lookups ...[]string) []string {
+	var distributionList []string
+	seen := make(map[string]bool)
+	for _, list := range lookups {
+		for _, lookup := range list {
+			if !seen[lookup] {
+				seen[lookup] = true
+				distributionList = append(distributionList, lookup)
+			}
+		}
+	}
+	return distributionList
+}
+
+func (n *Noto) statusStateRefs(states []*prototk.StoredState) []*prototk.StateRef {
+	refs := make([]*prototk.StateRef, len(states))
+	for i, state := range states {
+		refs[i] = &prototk.StateRef{
+			SchemaId: state.SchemaId,
+			Id:       state.Id,
+		}
+	}
+	return refs
+}
+
+// Check that a status change only spends states of the expected status schema, and creates exactly one
+func (n *Noto) validateStatusStates(ctx context.Context, method, schemaID string, inputs, outputs []*prototk.EndorsableState) (*prototk.EndorsableState, error) {
+	for _, state := range inputs {
+		if state.SchemaId != schemaID {
+			return nil, i18n.NewError(ctx, msgs.MsgUnknownSchema, state.SchemaId)
+		}
+	}
+	if len(outputs) != 1 || outputs[0].SchemaId != schemaID {
+		return nil, i18n.NewError(ctx, msgs.MsgInvalidOutputs, method, outputs)
+	}
+	return outputs[0], nil
+}
@@ -0,0 +1,399 @@
+/*
+ * Copyright © 2024 Kaleido, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package noto
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/kaleido-io/paladin/domains/noto/pkg/types"
+	"github.com/kaleido-io/paladin/toolkit/pkg/algorithms"
+	"github.com/kaleido-io/paladin/toolkit/pkg/prototk"
+	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
+	"github.com/kaleido-io/paladin/toolkit/pkg/verifiers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const selfSubmitConfig = `{"notaryLookup":"notary","variant":"0x1"}`
+
+func newStatusNoto(returnFunc func(req *prototk.FindAvailableStatesRequest) (*prototk.FindAvailableStatesResponse, error)) *Noto {
+	return &Noto{
+		coinSchema:           &prototk.StateSchema{Id: "coin"},
+		dataSchema:           &prototk.StateSchema{Id: "data"},
+		approvalSchema:       &prototk.StateSchema{Id: "approval"},
+		contractStatusSchema: &prototk.StateSchema{Id: "contractStatus"},
+		accountStatusSchema:  &prototk.StateSchema{Id: "accountStatus"},
+		lockedCoinSchema:     &prototk.StateSchema{Id: "locked"},
+		Callbacks:            &testDomainCallbacks{returnFunc: returnFunc},
+	}
+}
+
+func newNotoTransaction(t *testing.T, config, function, from, params string) *prototk.TransactionSpecification {
+	functionABI := types.NotoABI.Functions()[function]
+	functionJSON, err := json.Marshal(functionABI)
+	require.NoError(t, err)
+	return &prototk.TransactionSpecification{
+		TransactionId: tktypes.RandHex(32),
+		From:          from,
+		ContractInfo: &prototk.ContractInfo{
+			ContractAddress:    tktypes.RandAddress().String(),
+			ContractConfigJson: config,
+		},
+		FunctionAbiJson:    string(functionJSON),
+		FunctionSignature:  functionABI.SolString(),
+		FunctionParamsJson: params,
+	}
+}
+
+func resolvedVerifier(lookup string, address *tktypes.EthAddress) *prototk.ResolvedVerifier {
+	return &prototk.ResolvedVerifier{
+		Lookup:       lookup,
+		Algorithm:    algorithms.ECDSA_SECP256K1,
+		VerifierType: verifiers.ETH_ADDRESS,
+		Verifier:     address.String(),
+	}
+}
+
+func TestNotaryOnlyOperations(t *testing.T) {
+	n := &Noto{}
+	ctx := context.Background()
+
+	_, err := n.InitTransaction(ctx, &prototk.InitTransactionRequest{
+		Transaction: newNotoTransaction(t, `{"notaryLookup":"notary"}`, "pause", "alice", `{}`),
+	})
+	assert.ErrorContains(t, err, "PD200026")
+
+	_, err = n.InitTransaction(ctx, &prototk.InitTransactionRequest{
+		Transaction: newNotoTransaction(t, `{"notaryLookup":"notary"}`, "freeze", "alice", `{"account":"bob"}`),
+	})
+	assert.ErrorContains(t, err, "PD200026")
+
+	_, err = n.InitTransaction(ctx, &prototk.InitTransactionRequest{
+		Transaction: newNotoTransaction(t, `{"notaryLookup":"notary","notaryType":"0x1"}`, "forceTransfer", "notary", `{"from":"alice","to":"bob","amount":1}`),
+	})
+	assert.ErrorContains(t, err, "PD200027")
+
+	res, err := n.InitTransaction(ctx, &prototk.InitTransactionRequest{
+		Transaction: newNotoTransaction(t, `{"notaryLookup":"notary"}`, "freeze", "notary", `{"account":"bob"}`),
+	})
+	require.NoError(t, err)
+	require.Len(t, res.RequiredVerifiers, 2)
+	assert.Equal(t, "bob", res.RequiredVerifiers[1].Lookup)
+}
+
+func TestNotaryOperationParams(t *testing.T) {
+	n := &Noto{}
+	ctx := context.Background()
+
+	_, err := n.InitTransaction(ctx, &prototk.InitTransactionRequest{
+		Transaction: newNotoTransaction(t, `{"notaryLookup":"notary"}`, "unfreeze", "notary", `{}`),
+	})
+	assert.ErrorContains(t, err, "PD200007")
+
+	_, err = n.InitTransaction(ctx, &prototk.InitTransactionRequest{
+		Transaction: newNotoTransaction(t, `{"notaryLookup":"notary"}`, "forceTransfer", "notary", `{"to":"bob","amount":1}`),
+	})
+	assert.ErrorContains(t, err, "PD200007")
+
+	_, err = n.InitTransaction(ctx, &prototk.InitTransactionRequest{
+		Transaction: newNotoTransaction(t, `{"notaryLookup":"notary"}`, "forceTransfer", "notary", `{"from":"alice","amount":1}`),
+	})
+	assert.ErrorContains(t, err, "PD200007")
+
+	_, err = n.InitTransaction(ctx, &prototk.InitTransactionRequest{
+		Transaction: newNotoTransaction(t, `{"notaryLookup":"notary"}`, "forceTransfer", "notary", `{"from":"alice","to":"bob"}`),
+	})
+	assert.ErrorContains(t, err, "PD200008")
+}
+
+func TestPauseAssembleAndEndorse(t *testing.T) {
+	ctx := context.Background()
+	notary := tktypes.RandAddress()
+	existingID := tktypes.RandHex(32)
+	n := newStatusNoto(func(req *prototk.FindAvailableStatesRequest) (*prototk.FindAvailableStatesResponse, error) {
+		assert.Equal(t, "contractStatus", req.SchemaId)
+		return &prototk.FindAvailableStatesResponse{
+			States: []*prototk.StoredState{
+				{Id: existingID, SchemaId: "contractStatus", DataJson: `{"salt":"0x01","paused":false}`},
+			},
+		}, nil
+	})
+
+	tx := newNotoTransaction(t, selfSubmitConfig, "pause", "notary", `{"parties":["alice"],"data":"0x1234"}`)
+	verifiers := []*prototk.ResolvedVerifier{resolvedVerifier("notary", notary)}
+	assembleRes, err := n.AssembleTransaction(ctx, &prototk.AssembleTransactionRequest{
+		Transaction:       tx,
+		ResolvedVerifiers: verifiers,
+	})
+	require.NoError(t, err)
+	assembled := assembleRes.AssembledTransaction
+	require.Len(t, assembled.InputStates, 1)
+	assert.Equal(t, existingID, assembled.InputStates[0].Id)
+	require.Len(t, assembled.OutputStates, 1)
+	assert.Equal(t, "contractStatus", assembled.OutputStates[0].SchemaId)
+	assert.Contains(t, assembled.OutputStates[0].StateDataJson, `"paused":true`)
+	assert.Equal(t, []string{"notary", "alice"}, assembled.OutputStates[0].DistributionList)
+	require.Len(t, assembled.InfoStates, 1)
+	assert.Equal(t, []string{"notary", "alice"}, assembled.InfoStates[0].DistributionList)
+	require.Len(t, assembleRes.AttestationPlan, 2)
+
+	inputs := []*prototk.EndorsableState{{Id: existingID, SchemaId: "contractStatus", StateDataJson: `{"salt":"0x01","paused":false}`}}
+	outputs := []*prototk.EndorsableState{{Id: tktypes.RandHex(32), SchemaId: "contractStatus", StateDataJson: assembled.OutputStates[0].StateDataJson}}
+	endorseRes, err := n.EndorseTransaction(ctx, &prototk.EndorseTransactionRequest{
+		Transaction:         tx,
+		ResolvedVerifiers:   verifiers,
+		EndorsementRequest:  &prototk.AttestationRequest{Name: "notary"},
+		EndorsementVerifier: verifiers[0],
+		Inputs:              inputs,
+		Outputs:             outputs,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, prototk.EndorseTransactionResponse_SIGN, endorseRes.EndorsementResult)
+
+	// Only the notary can pause
+	_, err = n.EndorseTransaction(ctx, &prototk.EndorseTransactionRequest{
+		Transaction:        newNotoTransaction(t, selfSubmitConfig, "pause", "alice", `{}`),
+		ResolvedVerifiers:  verifiers,
+		EndorsementRequest: &prototk.AttestationRequest{Name: "notary"},
+		Inputs:             inputs,
+		Outputs:            outputs,
+	})
+	assert.ErrorContains(t, err, "PD200026")
+
+	// Status must match the operation
+	_, err = n.EndorseTransaction(ctx, &prototk.EndorseTransactionRequest{
+		Transaction:        newNotoTransaction(t, selfSubmitConfig, "unpause", "notary", `{}`),
+		ResolvedVerifiers:  verifiers,
+		EndorsementRequest: &prototk.AttestationRequest{Name: "notary"},
+		Inputs:             inputs,
+		Outputs:            outputs,
+	})
+	assert.ErrorContains(t, err, "PD200030")
+
+	// Coins cannot be moved by a status change
+	_, err = n.EndorseTransaction(ctx, &prototk.EndorseTransactionRequest{
+		Transaction:        tx,
+		ResolvedVerifiers:  verifiers,
+		EndorsementRequest: &prototk.AttestationRequest{Name: "notary"},
+		Inputs:             []*prototk.EndorsableState{{Id: tktypes.RandHex(32), SchemaId: "coin"}},
+		Outputs:            outputs,
+	})
+	assert.ErrorContains(t, err, "PD200003")
+
+	_, err = n.EndorseTransaction(ctx, &prototk.EndorseTransactionRequest{
+		Transaction:        tx,
+		ResolvedVerifiers:  verifiers,
+		EndorsementRequest: &prototk.AttestationRequest{Name: "notary"},
+		Inputs:             inputs,
+	})
+	assert.ErrorContains(t, err, "PD200030")
+}
+
+func TestFreezeAssembleAndEndorse(t *testing.T) {
+	ctx := context.Background()
+	notary := tktypes.RandAddress()
+	bob := tktypes.RandAddress()
+	n := newStatusNoto(func(req *prototk.FindAvailableStatesRequest) (*prototk.FindAvailableStatesResponse, error) {
+		assert.Equal(t, "accountStatus", req.SchemaId)
+		assert.Contains(t, req.QueryJson, bob.String())
+		return &prototk.FindAvailableStatesResponse{}, nil
+	})
+
+	tx := newNotoTransaction(t, selfSubmitConfig, "freeze", "notary", `{"account":"bob","parties":["alice","bob"]}`)
+	verifiers := []*prototk.ResolvedVerifier{resolvedVerifier("notary", notary), resolvedVerifier("bob", bob)}
+	assembleRes, err := n.AssembleTransaction(ctx, &prototk.AssembleTransactionRequest{
+		Transaction:       tx,
+		ResolvedVerifiers: verifiers,
+	})
+	require.NoError(t, err)
+	assembled := assembleRes.AssembledTransaction
+	assert.Empty(t, assembled.InputStates)
+	require.Len(t, assembled.OutputStates, 1)
+	assert.Equal(t, []string{"notary", "bob", "alice"}, assembled.OutputStates[0].DistributionList)
+	require.Len(t, assembled.InfoStates, 1)
+	assert.Equal(t, []string{"notary", "bob", "alice"}, assembled.InfoStates[0].DistributionList)
+
+	var status types.NotoAccountStatus
+	err = json.Unmarshal([]byte(assembled.OutputStates[0].StateDataJson), &status)
+	require.NoError(t, err)
+	assert.True(t, status.Frozen)
+	assert.Equal(t, bob, status.Account)
+
+	outputs := []*prototk.EndorsableState{{Id: tktypes.RandHex(32), SchemaId: "accountStatus", StateDataJson: assembled.OutputStates[0].StateDataJson}}
+	endorseRes, err := n.EndorseTransaction(ctx, &prototk.EndorseTransactionRequest{
+		Transaction:         tx,
+		ResolvedVerifiers:   verifiers,
+		EndorsementRequest:  &prototk.AttestationRequest{Name: "sender"},
+		EndorsementVerifier: verifiers[0],
+		Outputs:             outputs,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, prototk.EndorseTransactionResponse_ENDORSER_SUBMIT, endorseRes.EndorsementResult)
+
+	// Only the notary can freeze
+	_, err = n.EndorseTransaction(ctx, &prototk.EndorseTransactionRequest{
+		Transaction:        newNotoTransaction(t, selfSubmitConfig, "freeze", "bob", `{"account":"bob"}`),
+		ResolvedVerifiers:  verifiers,
+		EndorsementRequest: &prototk.AttestationRequest{Name: "notary"},
+		Outputs:            outputs,
+	})
+	assert.ErrorContains(t, err, "PD200026")
+
+	// Cannot spend the status of a different account
+	_, err = n.EndorseTransaction(ctx, &prototk.EndorseTransactionRequest{
+		Transaction:        tx,
+		ResolvedVerifiers:  verifiers,
+		EndorsementRequest: &prototk.AttestationRequest{Name: "notary"},
+		Inputs: []*prototk.EndorsableState{{
+			Id:            tktypes.RandHex(32),
+			SchemaId:      "accountStatus",
+			StateDataJson: fmt.Sprintf(`{"salt":"0x01","account":"%s","frozen":false}`, tktypes.RandAddress()),
+		}},
+		Outputs: outputs,
+	})
+	assert.ErrorContains(t, err, "PD200018")
+
+	// Cannot freeze a different account
+	_, err = n.EndorseTransaction(ctx, &prototk.EndorseTransactionRequest{
+		Transaction:        tx,
+		ResolvedVerifiers:  verifiers,
+		EndorsementRequest: &prototk.AttestationRequest{Name: "notary"},
+		Outputs: []*prototk.EndorsableState{{
+			Id:            tktypes.RandHex(32),
+			SchemaId:      "accountStatus",
+			StateDataJson: fmt.Sprintf(`{"salt":"0x01","account":"%s","frozen":true}`, tktypes.RandAddress()),
+		}},
+	})
+	assert.ErrorContains(t, err, "PD200030")
+}
+
+func TestValidateStatus(t *testing.T) {
+	ctx := context.Background()
+	alice := tktypes.RandAddress()
+	bob := tktypes.RandAddress()
+	coins := &gatheredCoins{
+		inCoins:  []*types.NotoCoin{{Owner: alice}},
+		outCoins: []*types.NotoCoin{{Owner: bob}, {Owner: alice}},
+	}
+
+	var contractStates, accountStates []*prototk.StoredState
+	var accountQueries []string
+	n := newStatusNoto(func(req *prototk.FindAvailableStatesRequest) (*prototk.FindAvailableStatesResponse, error) {
+		if req.SchemaId == "contractStatus" {
+			return &prototk.FindAvailableStatesResponse{States: contractStates}, nil
+		}
+		accountQueries = append(accountQueries, req.QueryJson)
+		return &prototk.FindAvailableStatesResponse{States: accountStates}, nil
+	})
+
+	err := n.validateStatus(ctx, "query1", coins)
+	require.NoError(t, err)
+	require.Len(t, accountQueries, 1)
+	assert.Contains(t, accountQueries[0], alice.String())
+	assert.Contains(t, accountQueries[0], bob.String())
+
+	accountStates = []*prototk.StoredState{{Id: "s1", DataJson: fmt.Sprintf(`{"salt":"0x01","account":"%s","frozen":false}`, bob)}}
+	err = n.validateStatus(ctx, "query1", coins)
+	require.NoError(t, err)
+
+	accountStates = []*prototk.StoredState{{Id: "s1", DataJson: fmt.Sprintf(`{"salt":"0x01","account":"%s","frozen":true}`, bob)}}
+	err = n.validateStatus(ctx, "query1", coins)
+	assert.ErrorContains(t, err, "PD200029")
+
+	accountStates = []*prototk.StoredState{{Id: "s1", DataJson: "!json"}}
+	err = n.validateStatus(ctx, "query1", coins)
+	assert.ErrorContains(t, err, "PD200006")
+
+	contractStates = []*prototk.StoredState{{Id: "s2", DataJson: `{"salt":"0x01","paused":true}`}}
+	err = n.validateStatus(ctx, "query1", coins)
+	assert.ErrorContains(t, err, "PD200028")
+
+	contractStates = []*prototk.StoredState{{Id: "s2", DataJson: "!json"}}
+	err = n.validateStatus(ctx, "query1", coins)
+	assert.ErrorContains(t, err, "PD200006")
+}
+
+func TestForceTransferAssembleAndEndorse(t *testing.T) {
+	ctx := context.Background()
+	notary := tktypes.RandAddress()
+	alice := tktypes.RandAddress()
+	bob := tktypes.RandAddress()
+	coinID := tktypes.RandHex(32)
+	coinJSON := fmt.Sprintf(`{"salt":"%s","owner":"%s","amount":"0x0f"}`, tktypes.RandHex(32), alice)
+	n := newStatusNoto(func(req *prototk.FindAvailableStatesRequest) (*prototk.FindAvailableStatesResponse, error) {
+		// Only coins are queried, as paused and frozen status are bypassed
+		assert.Equal(t, "coin", req.SchemaId)
+		return &prototk.FindAvailableStatesResponse{
+			States: []*prototk.StoredState{{Id: coinID, SchemaId: "coin", DataJson: coinJSON}},
+		}, nil
+	})
+
+	tx := newNotoTransaction(t, selfSubmitConfig, "forceTransfer", "notary", `{"from":"alice","to":"bob","amount":10}`)
+	verifiers := []*prototk.ResolvedVerifier{
+		resolvedVerifier("notary", notary),
+		resolvedVerifier("alice", alice),
+		resolvedVerifier("bob", bob),
+	}
+	assembleRes, err := n.AssembleTransaction(ctx, &prototk.AssembleTransactionRequest{
+		Transaction:       tx,
+		ResolvedVerifiers: verifiers,
+	})
+	require.NoError(t, err)
+	assembled := assembleRes.AssembledTransaction
+	require.Len(t, assembled.InputStates, 1)
+	require.Len(t, assembled.OutputStates, 2)
+	assert.Contains(t, assembled.OutputStates[0].StateDataJson, bob.String())
+	assert.Contains(t, assembled.OutputStates[1].StateDataJson, alice.String())
+
+	inputs := []*prototk.EndorsableState{{Id: coinID, SchemaId: "coin", StateDataJson: coinJSON}}
+	outputs := make([]*prototk.EndorsableState, len(assembled.OutputStates))
+	for i, state := range assembled.OutputStates {
+		outputs[i] = &prototk.EndorsableState{Id: tktypes.RandHex(32), SchemaId: "coin", StateDataJson: state.StateDataJson}
+	}
+	endorseRes, err := n.EndorseTransaction(ctx, &prototk.EndorseTransactionRequest{
+		Transaction:         tx,
+		ResolvedVerifiers:   verifiers,
+		EndorsementRequest:  &prototk.AttestationRequest{Name: "notary"},
+		EndorsementVerifier: verifiers[0],
+		Inputs:              inputs,
+		Outputs:             outputs,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, prototk.EndorseTransactionResponse_SIGN, endorseRes.EndorsementResult)
+
+	// Only the notary can force a transfer
+	_, err = n.EndorseTransaction(ctx, &prototk.EndorseTransactionRequest{
+		Transaction:        newNotoTransaction(t, selfSubmitConfig, "forceTransfer", "bob", `{"from":"alice","to":"bob","amount":10}`),
+		ResolvedVerifiers:  verifiers,
+		EndorsementRequest: &prototk.AttestationRequest{Name: "notary"},
+		Inputs:             inputs,
+		Outputs:            outputs,
+	})
+	assert.ErrorContains(t, err, "PD200026")
+
+	// Inputs must belong to the "from" account
+	_, err = n.EndorseTransaction(ctx, &prototk.EndorseTransactionRequest{
+		Transaction:        newNotoTransaction(t, selfSubmitConfig, "forceTransfer", "notary", `{"from":"bob","to":"alice","amount":10}`),
+		ResolvedVerifiers:  verifiers,
+		EndorsementRequest: &prototk.AttestationRequest{Name: "notary"},
+		Inputs:             inputs,
+		Outputs:            outputs,
+	})
+	assert.ErrorContains(t, err, "PD200018")
+}
@@ -68,10 +68,61 @@ type ApproveParams struct {
 	Delegate *tktypes.EthAddress    `json:"delegate"`
 }
 
+type PauseParams struct {
+	Parties []string         `json:"parties"`
+	Data    tktypes.HexBytes `json:"data"`
+}
+
+type FreezeParams struct {
+	Account string           `json:"account"`
+	Parties []string         `json:"parties"`
+	Data    tktypes.HexBytes `json:"data"`
+}
+
+type ForceTransferParams struct {
+	From   string              `json:"from"`
+	To     string              `json:"to"`
+	Amount *tktypes.HexUint256 `json:"amount"`
+	Data   tktypes.HexBytes    `json:"data"`
+}
+
+type LockParams struct {
+	Amount *tktypes.HexUint256 `json:"amount"`
+	Expiry tktypes.HexUint64   `json:"expiry"` // Unix time in seconds after which the owner can unlock without the delegate (0 for no expiry)
+	Data   tktypes.HexBytes    `json:"data"`
+}
+
+type DelegateLockParams struct {
+	LockID   tktypes.Bytes32  `json:"lockId"`
+	Delegate string           `json:"delegate"`
+	Data     tktypes.HexBytes `json:"data"`
+}
+
+type UnlockParams struct {
+	LockID     tktypes.Bytes32    `json:"lockId"`
+	Recipients []*UnlockRecipient `json:"recipients"`
+	Data       tktypes.HexBytes   `json:"data"`
+}
+
+type UnlockRecipient struct {
+	To     string              `json:"to"`
+	Amount *tktypes.HexUint256 `json:"amount"`
+}
+
 type ApproveExtraParams struct {
 	Data tktypes.HexBytes `json:"data"`
 }
 
+type BalanceOfParam struct {
+	Account string `json:"account"`
+}
+
+type BalanceOfResult struct {
+	TotalStates  *tktypes.HexUint256 `json:"totalStates"`
+	TotalBalance *tktypes.HexUint256 `json:"totalBalance"`
+	Overflow     bool                `json:"overflow"`
+}
+
 type NotoPublicTransaction struct {
 	FunctionABI *abi.Entry       `json:"functionABI"`
 	ParamsJSON  tktypes.RawJSON  `json:"paramsJSON"`
@@ -80,6 +80,7 @@ var NotoTransactionDataABI_V0 = &abi.ParameterArray{
 
 type DomainHandler = domain.DomainHandler[NotoParsedConfig]
 type ParsedTransaction = domain.ParsedTransaction[NotoParsedConfig]
+type DomainCallHandler = domain.DomainCallHandler[NotoParsedConfig]
 
 var NotaryTypeSigner tktypes.HexUint64 = 0x0000
 var NotaryTypePente tktypes.HexUint64 = 0x0001
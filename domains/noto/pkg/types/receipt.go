@@ -0,0 +1,52 @@
+/*
+ * Copyright © 2024 Kaleido, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package types
+
+import (
+	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
+)
+
+// NotoDomainReceipt is the domain receipt returned by ptx_getDomainReceipt for a Noto transaction
+type NotoDomainReceipt struct {
+	States    ReceiptStates       `json:"states"`
+	Transfers []*ReceiptTransfer  `json:"transfers,omitempty"`
+	Delegate  *tktypes.EthAddress `json:"delegate,omitempty"`
+	Data      tktypes.HexBytes    `json:"data,omitempty"`
+}
+
+type ReceiptStates struct {
+	Inputs        []*ReceiptState       `json:"inputs,omitempty"`
+	Outputs       []*ReceiptState       `json:"outputs,omitempty"`
+	LockedInputs  []*ReceiptLockedState `json:"lockedInputs,omitempty"`
+	LockedOutputs []*ReceiptLockedState `json:"lockedOutputs,omitempty"`
+}
+
+type ReceiptState struct {
+	ID   tktypes.HexBytes `json:"id"`
+	Data *NotoCoin        `json:"data"`
+}
+
+type ReceiptLockedState struct {
+	ID   tktypes.HexBytes `json:"id"`
+	Data *NotoLockedCoin  `json:"data"`
+}
+
+// ReceiptTransfer is a logical movement of value. A mint has no "from", and a burn has no "to".
+type ReceiptTransfer struct {
+	From   *tktypes.EthAddress `json:"from,omitempty"`
+	To     *tktypes.EthAddress `json:"to,omitempty"`
+	Amount *tktypes.HexUint256 `json:"amount"`
+}
@@ -56,3 +56,75 @@ var TransactionDataABI = &abi.Parameter{
 		{Name: "data", Type: "bytes"},
 	},
 }
+
+type TransferApproval struct {
+	Salt         string              `json:"salt"`
+	Delegate     *tktypes.EthAddress `json:"delegate"`
+	TransferHash tktypes.Bytes32     `json:"transferHash"`
+}
+
+var TransferApprovalABI = &abi.Parameter{
+	Type:         "tuple",
+	InternalType: "struct TransferApproval",
+	Components: abi.ParameterArray{
+		{Name: "salt", Type: "bytes32"},
+		{Name: "delegate", Type: "address", Indexed: true},
+		{Name: "transferHash", Type: "bytes32", Indexed: true},
+	},
+}
+
+// The paused status of the whole contract, maintained by the notary
+type NotoContractStatus struct {
+	Salt   string `json:"salt"`
+	Paused bool   `json:"paused"`
+}
+
+var NotoContractStatusABI = &abi.Parameter{
+	Type:         "tuple",
+	InternalType: "struct NotoContractStatus",
+	Components: abi.ParameterArray{
+		{Name: "salt", Type: "bytes32"},
+		{Name: "paused", Type: "bool", Indexed: true},
+	},
+}
+
+// The frozen status of a single account, maintained by the notary
+type NotoAccountStatus struct {
+	Salt    string              `json:"salt"`
+	Account *tktypes.EthAddress `json:"account"`
+	Frozen  bool                `json:"frozen"`
+}
+
+var NotoAccountStatusABI = &abi.Parameter{
+	Type:         "tuple",
+	InternalType: "struct NotoAccountStatus",
+	Components: abi.ParameterArray{
+		{Name: "salt", Type: "bytes32"},
+		{Name: "account", Type: "address", Indexed: true},
+		{Name: "frozen", Type: "bool", Indexed: true},
+	},
+}
+
+// Value that has been locked by its owner, so it can only be spent by the "unlock" operation.
+// The lock ID is the ID of the transaction that created the lock.
+type NotoLockedCoin struct {
+	Salt     string              `json:"salt"`
+	LockID   tktypes.Bytes32     `json:"lockId"`
+	Owner    *tktypes.EthAddress `json:"owner"`
+	Amount   *tktypes.HexUint256 `json:"amount"`
+	Delegate *tktypes.EthAddress `json:"delegate"` // Zero address if the lock has not been delegated
+	Expiry   tktypes.HexUint64   `json:"expiry"`   // Unix time in seconds (0 for no expiry)
+}
+
+var NotoLockedCoinABI = &abi.Parameter{
+	Type:         "tuple",
+	InternalType: "struct NotoLockedCoin",
+	Components: abi.ParameterArray{
+		{Name: "salt", Type: "bytes32"},
+		{Name: "lockId", Type: "bytes32", Indexed: true},
+		{Name: "owner", Type: "address", Indexed: true},
+		{Name: "amount", Type: "uint256", Indexed: true},
+		{Name: "delegate", Type: "address", Indexed: true},
+		{Name: "expiry", Type: "uint256"},
+	},
+}
@@ -0,0 +1,116 @@
+/*
+ * Copyright © 2024 Kaleido, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+// The Zeto prover service, which generates proofs for Paladin runtimes configured
+// with the "grpc" prover backend.
+//
+// Usage: zeto-prover <config file>
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/kaleido-io/paladin/config/pkg/confutil"
+	"github.com/kaleido-io/paladin/config/pkg/pldconf"
+	"github.com/kaleido-io/paladin/domains/zeto/internal/msgs"
+	"github.com/kaleido-io/paladin/domains/zeto/pkg/proto"
+	"github.com/kaleido-io/paladin/domains/zeto/pkg/zetosigner"
+	"github.com/kaleido-io/paladin/domains/zeto/pkg/zetosigner/zetosignerapi"
+	"github.com/kaleido-io/paladin/toolkit/pkg/log"
+	"github.com/kaleido-io/paladin/toolkit/pkg/tlsconf"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+type proverServiceConfig struct {
+	Server      proverServerConfig              `json:"server"`
+	SnarkProver zetosignerapi.SnarkProverConfig `json:"snarkProver"` // circuitsDir, provingKeysDir and maxProverPerCircuit for the circuits the service runs
+}
+
+type proverServerConfig struct {
+	Address  *string           `json:"address"`
+	Port     *int              `json:"port"`
+	TLS      pldconf.TLSConfig `json:"tls"`
+	Insecure bool              `json:"insecure"` // must be set to accept connections without TLS, as the witness inputs contain private keys
+}
+
+var proverServerDefaults = proverServerConfig{
+	Address: confutil.P("0.0.0.0"),
+	Port:    confutil.P(9000),
+}
+
+func main() {
+	if len(os.Args) != 2 {
+		fmt.Fprintf(os.Stderr, "usage: %s <config file>\n", os.Args[0])
+		os.Exit(1)
+	}
+	if err := run(context.Background(), os.Args[1]); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, configFile string) error {
+	var conf proverServiceConfig
+	if err := pldconf.ReadAndParseYAMLFile(ctx, configFile, &conf); err != nil {
+		return err
+	}
+
+	tlsConfig, err := tlsconf.BuildTLSConfig(ctx, &conf.Server.TLS, tlsconf.ServerType)
+	if err != nil {
+		return err
+	}
+	var serverOpts []grpc.ServerOption
+	if tlsConfig != nil {
+		serverOpts = append(serverOpts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	} else if !conf.Server.Insecure {
+		return i18n.NewError(ctx, msgs.MsgErrorProverServiceInsecure)
+	}
+
+	prover, err := zetosigner.NewProverServer(&conf.SnarkProver)
+	if err != nil {
+		return err
+	}
+
+	address := net.JoinHostPort(
+		confutil.StringNotEmpty(conf.Server.Address, *proverServerDefaults.Address),
+		strconv.Itoa(confutil.Int(conf.Server.Port, *proverServerDefaults.Port)),
+	)
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return i18n.NewError(ctx, msgs.MsgErrorProverServiceListen, address, err)
+	}
+
+	server := grpc.NewServer(serverOpts...)
+	proto.RegisterZetoProverServer(server, prover)
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		sig := <-signals
+		log.L(ctx).Infof("Stopping prover service on signal %s", sig)
+		server.GracefulStop()
+	}()
+
+	log.L(ctx).Infof("Zeto prover service listening on %s (tls=%t)", listener.Addr(), tlsConfig != nil)
+	return server.Serve(listener)
+}
@@ -141,10 +141,37 @@ var (
 	MsgErrorAssembleInputs                 = ffe("PD210099", "failed to assemble private inputs for witness calculation. %s")
 	MsgErrorCalcWitness                    = ffe("PD210100", "failed to calculate the witness. %s")
 	MsgErrorGenerateProof                  = ffe("PD210101", "failed to generate proof. %s")
-	MsgNoDomainReceipt                     = ffe("PD210102", "Not implemented. See state receipt for coin transfers")
 	MsgUnknownSignPayload                  = ffe("PD210103", "Sign payload type '%s' not recognized")
 	MsgNullifierGenerationFailed           = ffe("PD210104", "Failed to generate nullifier for coin")
 	MsgErrorDecodeDepositCall              = ffe("PD210105", "Failed to decode the deposit call. %s")
 	MsgErrorDecodeWithdrawCall             = ffe("PD210106", "Failed to decode the withdraw call. %s")
 	MsgParamTotalAmountInRange             = ffe("PD210107", "Total amount must be in the range (0, 2^100)")
+	MsgErrorDecodeBalanceOfCall            = ffe("PD210108", "Failed to decode the balanceOf call. %s")
+	MsgNoParamAccount                      = ffe("PD210109", "Parameter 'account' is required")
+	MsgErrorValidateInitCallTxSpec         = ffe("PD210110", "Failed to validate init call transaction spec. %s")
+	MsgErrorValidateExecCallTxSpec         = ffe("PD210111", "Failed to validate exec call transaction spec. %s")
+	MsgErrorQueryBalance                   = ffe("PD210112", "Failed to query the available coins for the balance. %s")
+	MsgErrorMarshalTransferInfoSchemaAbi   = ffe("PD210114", "Failed to marshal Zeto transfer info schema abi. %s")
+	MsgErrorHashTransferInfo               = ffe("PD210115", "Failed to hash transfer info state. %s")
+	MsgErrorCreateTransferInfo             = ffe("PD210116", "Failed to create transfer info state. %s")
+	MsgErrorParseReceiptState              = ffe("PD210117", "Failed to parse state %s for the domain receipt. %s")
+	MsgErrorUnknownProverBackend           = ffe("PD210118", "Prover backend type '%s' not recognized")
+	MsgErrorMissingProverAddress           = ffe("PD210119", "An address is required for the '%s' prover backend")
+	MsgErrorProverConnect                  = ffe("PD210120", "Failed to create the connection to the prover service at '%s'. %s")
+	MsgErrorProverQueueFull                = ffe("PD210121", "The prover queue is full, with %d proving requests waiting")
+	MsgErrorProverTimeout                  = ffe("PD210122", "Timed out waiting for a prover for circuit '%s'")
+	MsgErrorRemoteProver                   = ffe("PD210123", "The prover service failed to generate the proof for circuit '%s'. %s")
+	MsgErrorMarshalWitnessInputs           = ffe("PD210124", "Failed to marshal the witness inputs. %s")
+	MsgErrorParseWitnessInputs             = ffe("PD210125", "Failed to parse the witness inputs. %s")
+	MsgErrorMerkleTreeNotSupported         = ffe("PD210126", "Token '%s' does not use a Merkle tree")
+	MsgErrorQueryContractEvents            = ffe("PD210127", "Failed to query the events of contract %s. %s")
+	MsgErrorReadMerkleTreeNode             = ffe("PD210128", "Failed to read node %s of Merkle tree %s. %s")
+	MsgErrorSnapshotTreeName               = ffe("PD210129", "The snapshot is for Merkle tree '%s', not '%s'")
+	MsgErrorSnapshotRootMismatch           = ffe("PD210130", "The leaves of the snapshot produce root %s, not the root %s of the snapshot")
+	MsgErrorSnapshotRootNotIndexed         = ffe("PD210131", "Root %s of the snapshot was not produced by any of the indexed events of the contract")
+	MsgErrorUnknownRPCMethod               = ffe("PD210132", "Unknown RPC method '%s'")
+	MsgErrorRPCParams                      = ffe("PD210133", "Invalid parameters for RPC method '%s'. %s")
+	MsgErrorProverInsecure                 = ffe("PD210134", "TLS must be enabled for the '%s' prover backend, or 'insecure' set to allow unencrypted connections")
+	MsgErrorProverServiceInsecure          = ffe("PD210135", "TLS must be enabled for the prover service, or 'insecure' set to accept unencrypted connections")
+	MsgErrorProverServiceListen            = ffe("PD210136", "Failed to listen on '%s': %s")
 )
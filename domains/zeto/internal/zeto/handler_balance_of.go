@@ -0,0 +1,83 @@
+/*
+ * Copyright © 2024 Kaleido, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package zeto
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/kaleido-io/paladin/domains/zeto/internal/msgs"
+	"github.com/kaleido-io/paladin/domains/zeto/internal/zeto/common"
+	"github.com/kaleido-io/paladin/domains/zeto/pkg/types"
+	"github.com/kaleido-io/paladin/domains/zeto/pkg/zetosigner/zetosignerapi"
+	"github.com/kaleido-io/paladin/toolkit/pkg/domain"
+	pb "github.com/kaleido-io/paladin/toolkit/pkg/prototk"
+	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
+)
+
+type balanceOfHandler struct {
+	zeto *Zeto
+}
+
+func (h *balanceOfHandler) ValidateParams(ctx context.Context, config *types.DomainInstanceConfig, params string) (interface{}, error) {
+	var balanceOfParams types.BalanceOfParam
+	if err := json.Unmarshal([]byte(params), &balanceOfParams); err != nil {
+		return nil, i18n.NewError(ctx, msgs.MsgErrorDecodeBalanceOfCall, err)
+	}
+	if balanceOfParams.Account == "" {
+		return nil, i18n.NewError(ctx, msgs.MsgNoParamAccount)
+	}
+	return &balanceOfParams, nil
+}
+
+func (h *balanceOfHandler) InitCall(ctx context.Context, tx *types.ParsedTransaction, req *pb.InitCallRequest) (*pb.InitCallResponse, error) {
+	params := tx.Params.(*types.BalanceOfParam)
+	return &pb.InitCallResponse{
+		RequiredVerifiers: []*pb.ResolveVerifierRequest{
+			{
+				Lookup:       params.Account,
+				Algorithm:    h.zeto.getAlgoZetoSnarkBJJ(),
+				VerifierType: zetosignerapi.IDEN3_PUBKEY_BABYJUBJUB_COMPRESSED_0X,
+			},
+		},
+	}, nil
+}
+
+func (h *balanceOfHandler) ExecCall(ctx context.Context, tx *types.ParsedTransaction, req *pb.ExecCallRequest) (*pb.ExecCallResponse, error) {
+	params := tx.Params.(*types.BalanceOfParam)
+	resolvedAccount := domain.FindVerifier(params.Account, h.zeto.getAlgoZetoSnarkBJJ(), zetosignerapi.IDEN3_PUBKEY_BABYJUBJUB_COMPRESSED_0X, req.ResolvedVerifiers)
+	if resolvedAccount == nil {
+		return nil, i18n.NewError(ctx, msgs.MsgErrorResolveVerifier, params.Account)
+	}
+
+	useNullifiers := common.IsNullifiersToken(tx.DomainConfig.TokenName)
+	totalStates, totalBalance, overflow, err := h.zeto.getAccountBalance(ctx, useNullifiers, req.StateQueryContext, resolvedAccount.Verifier)
+	if err != nil {
+		return nil, err
+	}
+
+	balanceJSON, err := json.Marshal(&types.BalanceOfResult{
+		TotalStates:  (*tktypes.HexUint256)(big.NewInt(int64(totalStates))),
+		TotalBalance: (*tktypes.HexUint256)(totalBalance),
+		Overflow:     overflow,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &pb.ExecCallResponse{ResultJson: string(balanceJSON)}, nil
+}
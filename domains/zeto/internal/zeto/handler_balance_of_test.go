@@ -0,0 +1,105 @@
+package zeto
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/kaleido-io/paladin/domains/zeto/pkg/types"
+	"github.com/kaleido-io/paladin/domains/zeto/pkg/zetosigner/zetosignerapi"
+	"github.com/kaleido-io/paladin/toolkit/pkg/prototk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBalanceOfValidateParams(t *testing.T) {
+	h := &balanceOfHandler{}
+	ctx := context.Background()
+	config := &types.DomainInstanceConfig{}
+	v, err := h.ValidateParams(ctx, config, "{\"account\":\"Alice\"}")
+	require.NoError(t, err)
+	require.Equal(t, "Alice", v.(*types.BalanceOfParam).Account)
+
+	_, err = h.ValidateParams(ctx, config, "bad json")
+	require.ErrorContains(t, err, "PD210108: Failed to decode the balanceOf call.")
+
+	_, err = h.ValidateParams(ctx, config, "{}")
+	require.ErrorContains(t, err, "PD210109: Parameter 'account' is required")
+}
+
+func TestBalanceOfInitCall(t *testing.T) {
+	h := balanceOfHandler{
+		zeto: &Zeto{
+			name: "test1",
+		},
+	}
+	ctx := context.Background()
+	tx := &types.ParsedTransaction{
+		Params: &types.BalanceOfParam{Account: "Alice"},
+	}
+	res, err := h.InitCall(ctx, tx, &prototk.InitCallRequest{})
+	require.NoError(t, err)
+	assert.Len(t, res.RequiredVerifiers, 1)
+	assert.Equal(t, "Alice", res.RequiredVerifiers[0].Lookup)
+	assert.Equal(t, zetosignerapi.IDEN3_PUBKEY_BABYJUBJUB_COMPRESSED_0X, res.RequiredVerifiers[0].VerifierType)
+	assert.Equal(t, zetosignerapi.AlgoDomainZetoSnarkBJJ("test1"), res.RequiredVerifiers[0].Algorithm)
+}
+
+func TestBalanceOfExecCall(t *testing.T) {
+	testCallbacks := &testDomainCallbacks{
+		returnFunc: func() (*prototk.FindAvailableStatesResponse, error) {
+			return nil, errors.New("test error")
+		},
+	}
+	h := balanceOfHandler{
+		zeto: &Zeto{
+			name:      "test1",
+			Callbacks: testCallbacks,
+			coinSchema: &prototk.StateSchema{
+				Id: "coin",
+			},
+		},
+	}
+	ctx := context.Background()
+	tx := &types.ParsedTransaction{
+		Params: &types.BalanceOfParam{Account: "Alice"},
+		DomainConfig: &types.DomainInstanceConfig{
+			TokenName: "Zeto_AnonNullifier",
+		},
+	}
+	req := &prototk.ExecCallRequest{
+		StateQueryContext: "queryContext",
+		ResolvedVerifiers: []*prototk.ResolvedVerifier{
+			{
+				Lookup:       "Alice",
+				Verifier:     "0x19d2ee6b9770a4f8d7c3b7906bc7595684509166fa42d718d1d880b62bcb7922",
+				Algorithm:    h.zeto.getAlgoZetoSnarkBJJ(),
+				VerifierType: zetosignerapi.IDEN3_PUBKEY_BABYJUBJUB_COMPRESSED_0X,
+			},
+		},
+	}
+	_, err := h.ExecCall(ctx, tx, req)
+	assert.EqualError(t, err, "PD210112: Failed to query the available coins for the balance. test error")
+
+	testCallbacks.returnFunc = func() (*prototk.FindAvailableStatesResponse, error) {
+		return &prototk.FindAvailableStatesResponse{
+			States: []*prototk.StoredState{
+				{Id: "state1", DataJson: "{\"salt\":\"0x13de02d64a5736a56b2d35d2a83dd60397ba70aae6f8347629f0960d4fee5d58\",\"owner\":\"0x19d2ee6b9770a4f8d7c3b7906bc7595684509166fa42d718d1d880b62bcb7922\",\"amount\":\"0x0f\"}"},
+				{Id: "state2", DataJson: "{\"salt\":\"0x13de02d64a5736a56b2d35d2a83dd60397ba70aae6f8347629f0960d4fee5d59\",\"owner\":\"0x19d2ee6b9770a4f8d7c3b7906bc7595684509166fa42d718d1d880b62bcb7922\",\"amount\":\"0x0a\"}"},
+			},
+		}, nil
+	}
+	res, err := h.ExecCall(ctx, tx, req)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"totalStates":"0x02","totalBalance":"0x19","overflow":false}`, res.ResultJson)
+
+	MAX_BALANCE_STATES = 1
+	defer func() { MAX_BALANCE_STATES = 1000 }()
+	res, err = h.ExecCall(ctx, tx, req)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"totalStates":"0x01","totalBalance":"0x0f","overflow":true}`, res.ResultJson)
+
+	req.ResolvedVerifiers = nil
+	_, err = h.ExecCall(ctx, tx, req)
+	assert.EqualError(t, err, "PD210036: Failed to resolve verifier: Alice")
+}
@@ -88,7 +88,16 @@ func (h *depositHandler) Assemble(ctx context.Context, tx *types.ParsedTransacti
 		return nil, i18n.NewError(ctx, msgs.MsgErrorPrepTxOutputs, err)
 	}
 
-	payloadBytes, err := h.formatProvingRequest(ctx, outputCoins)
+	infoState, err := h.zeto.makeTransferInfoState(ctx, &types.ZetoTransferInfo{
+		To:     tx.Transaction.From,
+		ToKey:  outputCoins[0].Owner,
+		Amount: amount,
+	}, []string{tx.Transaction.From})
+	if err != nil {
+		return nil, i18n.NewError(ctx, msgs.MsgErrorCreateTransferInfo, err)
+	}
+
+	payloadBytes, err := h.formatProvingRequest(ctx, tx.Transaction.TransactionId, outputCoins)
 	if err != nil {
 		return nil, i18n.NewError(ctx, msgs.MsgErrorFormatProvingReq, err)
 	}
@@ -98,6 +107,7 @@ func (h *depositHandler) Assemble(ctx context.Context, tx *types.ParsedTransacti
 		AssemblyResult: pb.AssembleTransactionResponse_OK,
 		AssembledTransaction: &pb.AssembledTransaction{
 			OutputStates: outputStates,
+			InfoStates:   []*pb.NewState{infoState},
 			DomainData:   &amountStr,
 		},
 		AttestationPlan: []*pb.AttestationRequest{
@@ -147,7 +157,7 @@ func (h *depositHandler) Prepare(ctx context.Context, tx *types.ParsedTransactio
 		}
 	}
 
-	data, err := encodeTransactionData(ctx, req.Transaction)
+	data, err := encodeTransactionData(ctx, req.Transaction, req.InfoStates)
 	if err != nil {
 		return nil, i18n.NewError(ctx, msgs.MsgErrorEncodeTxData, err)
 	}
@@ -177,7 +187,7 @@ func (h *depositHandler) Prepare(ctx context.Context, tx *types.ParsedTransactio
 	}, nil
 }
 
-func (h *depositHandler) formatProvingRequest(ctx context.Context, outputCoins []*types.ZetoCoin) ([]byte, error) {
+func (h *depositHandler) formatProvingRequest(ctx context.Context, transactionId string, outputCoins []*types.ZetoCoin) ([]byte, error) {
 	outputSize := common.GetInputSize(len(outputCoins))
 	outputCommitments := make([]string, outputSize)
 	outputValueInts := make([]uint64, outputSize)
@@ -196,7 +206,8 @@ func (h *depositHandler) formatProvingRequest(ctx context.Context, outputCoins [
 	}
 
 	payload := &corepb.ProvingRequest{
-		CircuitId: constants.CIRCUIT_DEPOSIT,
+		CircuitId:     constants.CIRCUIT_DEPOSIT,
+		TransactionId: transactionId,
 		Common: &corepb.ProvingRequestCommon{
 			OutputCommitments: outputCommitments,
 			OutputValues:      outputValueInts,
@@ -19,7 +19,7 @@ import (
 func (z *Zeto) handleMintEvent(ctx context.Context, tree core.SparseMerkleTree, storage smt.StatesStorage, ev *prototk.OnChainEvent, tokenName string, res *prototk.HandleEventBatchResponse) error {
 	var mint MintEvent
 	if err := json.Unmarshal([]byte(ev.DataJson), &mint); err == nil {
-		txID := decodeTransactionData(mint.Data)
+		txID, infoStates := decodeTransactionData(mint.Data)
 		if txID == nil {
 			log.L(ctx).Errorf("Failed to decode transaction data for mint event: %s. Skip to the next event", mint.Data)
 			return nil
@@ -28,7 +28,8 @@ func (z *Zeto) handleMintEvent(ctx context.Context, tree core.SparseMerkleTree,
 			TransactionId: txID.String(),
 			Location:      ev.Location,
 		})
-		res.ConfirmedStates = append(res.ConfirmedStates, parseStatesFromEvent(txID, mint.Outputs)...)
+		res.InfoStates = append(res.InfoStates, parseInfoStatesFromData(txID, infoStates, ev.Location)...)
+		res.ConfirmedStates = append(res.ConfirmedStates, parseStatesFromEvent(txID, mint.Outputs, ev.Location)...)
 		if common.IsNullifiersToken(tokenName) {
 			err := z.updateMerkleTree(ctx, tree, storage, txID, mint.Outputs)
 			if err != nil {
@@ -44,7 +45,7 @@ func (z *Zeto) handleMintEvent(ctx context.Context, tree core.SparseMerkleTree,
 func (z *Zeto) handleTransferEvent(ctx context.Context, tree core.SparseMerkleTree, storage smt.StatesStorage, ev *prototk.OnChainEvent, tokenName string, res *prototk.HandleEventBatchResponse) error {
 	var transfer TransferEvent
 	if err := json.Unmarshal([]byte(ev.DataJson), &transfer); err == nil {
-		txID := decodeTransactionData(transfer.Data)
+		txID, infoStates := decodeTransactionData(transfer.Data)
 		if txID == nil {
 			log.L(ctx).Errorf("Failed to decode transaction data for transfer event: %s. Skip to the next event", transfer.Data)
 			return nil
@@ -53,8 +54,9 @@ func (z *Zeto) handleTransferEvent(ctx context.Context, tree core.SparseMerkleTr
 			TransactionId: txID.String(),
 			Location:      ev.Location,
 		})
-		res.SpentStates = append(res.SpentStates, parseStatesFromEvent(txID, transfer.Inputs)...)
-		res.ConfirmedStates = append(res.ConfirmedStates, parseStatesFromEvent(txID, transfer.Outputs)...)
+		res.InfoStates = append(res.InfoStates, parseInfoStatesFromData(txID, infoStates, ev.Location)...)
+		res.SpentStates = append(res.SpentStates, parseStatesFromEvent(txID, transfer.Inputs, ev.Location)...)
+		res.ConfirmedStates = append(res.ConfirmedStates, parseStatesFromEvent(txID, transfer.Outputs, ev.Location)...)
 		if common.IsNullifiersToken(tokenName) {
 			err := z.updateMerkleTree(ctx, tree, storage, txID, transfer.Outputs)
 			if err != nil {
@@ -70,7 +72,7 @@ func (z *Zeto) handleTransferEvent(ctx context.Context, tree core.SparseMerkleTr
 func (z *Zeto) handleTransferWithEncryptionEvent(ctx context.Context, tree core.SparseMerkleTree, storage smt.StatesStorage, ev *prototk.OnChainEvent, tokenName string, res *prototk.HandleEventBatchResponse) error {
 	var transfer TransferWithEncryptedValuesEvent
 	if err := json.Unmarshal([]byte(ev.DataJson), &transfer); err == nil {
-		txID := decodeTransactionData(transfer.Data)
+		txID, infoStates := decodeTransactionData(transfer.Data)
 		if txID == nil {
 			log.L(ctx).Errorf("Failed to decode transaction data for transfer event: %s. Skip to the next event", transfer.Data)
 			return nil
@@ -79,8 +81,9 @@ func (z *Zeto) handleTransferWithEncryptionEvent(ctx context.Context, tree core.
 			TransactionId: txID.String(),
 			Location:      ev.Location,
 		})
-		res.SpentStates = append(res.SpentStates, parseStatesFromEvent(txID, transfer.Inputs)...)
-		res.ConfirmedStates = append(res.ConfirmedStates, parseStatesFromEvent(txID, transfer.Outputs)...)
+		res.InfoStates = append(res.InfoStates, parseInfoStatesFromData(txID, infoStates, ev.Location)...)
+		res.SpentStates = append(res.SpentStates, parseStatesFromEvent(txID, transfer.Inputs, ev.Location)...)
+		res.ConfirmedStates = append(res.ConfirmedStates, parseStatesFromEvent(txID, transfer.Outputs, ev.Location)...)
 		if common.IsNullifiersToken(tokenName) {
 			err := z.updateMerkleTree(ctx, tree, storage, txID, transfer.Outputs)
 			if err != nil {
@@ -96,7 +99,7 @@ func (z *Zeto) handleTransferWithEncryptionEvent(ctx context.Context, tree core.
 func (z *Zeto) handleWithdrawEvent(ctx context.Context, tree core.SparseMerkleTree, storage smt.StatesStorage, ev *prototk.OnChainEvent, tokenName string, res *prototk.HandleEventBatchResponse) error {
 	var withdraw WithdrawEvent
 	if err := json.Unmarshal([]byte(ev.DataJson), &withdraw); err == nil {
-		txID := decodeTransactionData(withdraw.Data)
+		txID, infoStates := decodeTransactionData(withdraw.Data)
 		if txID == nil {
 			log.L(ctx).Errorf("Failed to decode transaction data for withdraw event: %s. Skip to the next event", withdraw.Data)
 			return nil
@@ -105,8 +108,9 @@ func (z *Zeto) handleWithdrawEvent(ctx context.Context, tree core.SparseMerkleTr
 			TransactionId: txID.String(),
 			Location:      ev.Location,
 		})
-		res.SpentStates = append(res.SpentStates, parseStatesFromEvent(txID, withdraw.Inputs)...)
-		res.ConfirmedStates = append(res.ConfirmedStates, parseStatesFromEvent(txID, []tktypes.HexUint256{withdraw.Output})...)
+		res.InfoStates = append(res.InfoStates, parseInfoStatesFromData(txID, infoStates, ev.Location)...)
+		res.SpentStates = append(res.SpentStates, parseStatesFromEvent(txID, withdraw.Inputs, ev.Location)...)
+		res.ConfirmedStates = append(res.ConfirmedStates, parseStatesFromEvent(txID, []tktypes.HexUint256{withdraw.Output}, ev.Location)...)
 		if common.IsNullifiersToken(tokenName) {
 			err := z.updateMerkleTree(ctx, tree, storage, txID, []tktypes.HexUint256{withdraw.Output})
 			if err != nil {
@@ -122,7 +126,7 @@ func (z *Zeto) handleWithdrawEvent(ctx context.Context, tree core.SparseMerkleTr
 func (z *Zeto) handleLockedEvent(ctx context.Context, ev *prototk.OnChainEvent, res *prototk.HandleEventBatchResponse) error {
 	var lock LockedEvent
 	if err := json.Unmarshal([]byte(ev.DataJson), &lock); err == nil {
-		txID := decodeTransactionData(lock.Data)
+		txID, _ := decodeTransactionData(lock.Data)
 		if txID == nil {
 			log.L(ctx).Errorf("Failed to decode transaction data for lock event: %s. Skip to the next event", lock.Data)
 			return nil
@@ -168,12 +172,25 @@ func (z *Zeto) addOutputToMerkleTree(ctx context.Context, tree core.SparseMerkle
 	return nil
 }
 
-func parseStatesFromEvent(txID tktypes.HexBytes, states []tktypes.HexUint256) []*prototk.StateUpdate {
+func parseStatesFromEvent(txID tktypes.HexBytes, states []tktypes.HexUint256, location *prototk.OnChainEventLocation) []*prototk.StateUpdate {
 	refs := make([]*prototk.StateUpdate, len(states))
 	for i, state := range states {
 		refs[i] = &prototk.StateUpdate{
 			Id:            state.String(),
 			TransactionId: txID.String(),
+			Location:      location,
+		}
+	}
+	return refs
+}
+
+func parseInfoStatesFromData(txID tktypes.HexBytes, states []tktypes.Bytes32, location *prototk.OnChainEventLocation) []*prototk.StateUpdate {
+	refs := make([]*prototk.StateUpdate, len(states))
+	for i, state := range states {
+		refs[i] = &prototk.StateUpdate{
+			Id:            state.String(),
+			TransactionId: txID.String(),
+			Location:      location,
 		}
 	}
 	return refs
@@ -17,6 +17,7 @@ package zeto
 
 import (
 	"context"
+	"fmt"
 	"testing"
 
 	"github.com/kaleido-io/paladin/domains/zeto/internal/zeto/smt"
@@ -83,6 +84,7 @@ func TestHandleTransferEvent(t *testing.T) {
 	ev := &prototk.OnChainEvent{
 		DataJson:          "bad json",
 		SoliditySignature: "event UTXOTransfer(uint256[] inputs, uint256[] outputs, address indexed submitter, bytes data)",
+		Location:          &prototk.OnChainEventLocation{BlockNumber: 1000},
 	}
 	res := &prototk.HandleEventBatchResponse{}
 
@@ -100,6 +102,8 @@ func TestHandleTransferEvent(t *testing.T) {
 	err = z.handleTransferEvent(ctx, merkleTree, storage, ev, "Zeto_AnonNullifier", res)
 	assert.NoError(t, err)
 	assert.Equal(t, "0x30e43028afbb41d6887444f4c2b4ed6d00000000000000000000000000000000", res.TransactionsComplete[0].TransactionId)
+	require.Len(t, res.ConfirmedStates, 1)
+	assert.Equal(t, int64(1000), res.ConfirmedStates[0].Location.BlockNumber)
 
 	ev.DataJson = "{\"data\":\"0x0001000030e43028afbb41d6887444f4c2b4ed6d00000000000000000000000000000000\",\"outputs\":[\"0xffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff\"],\"submitter\":\"0x74e71b05854ee819cb9397be01c82570a178d019\"}"
 	err = z.handleTransferEvent(ctx, merkleTree, storage, ev, "Zeto_AnonNullifier", res)
@@ -158,6 +162,44 @@ func TestHandleTransferWithEncryptionEvent(t *testing.T) {
 	assert.Equal(t, "0x30e43028afbb41d6887444f4c2b4ed6d00000000000000000000000000000000", res.TransactionsComplete[0].TransactionId)
 }
 
+func TestHandleTransferEventInfoStates(t *testing.T) {
+	z, testCallbacks := newTestZeto()
+	storage := smt.NewStatesStorage(testCallbacks, "testToken1", "context1", "merkle_tree_root", "merkle_tree_node")
+	merkleTree, err := smt.NewSmt(storage)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	txID := "0x30e43028afbb41d6887444f4c2b4ed6d00000000000000000000000000000000"
+	infoID := "0x" + tktypes.RandHex(32)
+	data, err := encodeTransactionData(ctx, &prototk.TransactionSpecification{TransactionId: txID}, []*prototk.EndorsableState{{Id: infoID}})
+	require.NoError(t, err)
+	assert.Equal(t, "0x00010001", data[0:4].String())
+
+	ev := &prototk.OnChainEvent{
+		DataJson:          fmt.Sprintf(`{"data":"%s","inputs":["0x1234"],"outputs":["0x5678"],"submitter":"0x74e71b05854ee819cb9397be01c82570a178d019"}`, data),
+		SoliditySignature: "event UTXOTransfer(uint256[] inputs, uint256[] outputs, address indexed submitter, bytes data)",
+	}
+	res := &prototk.HandleEventBatchResponse{}
+	err = z.handleTransferEvent(ctx, merkleTree, storage, ev, "testToken1", res)
+	require.NoError(t, err)
+	require.Len(t, res.TransactionsComplete, 1)
+	assert.Equal(t, txID, res.TransactionsComplete[0].TransactionId)
+	require.Len(t, res.InfoStates, 1)
+	assert.Equal(t, infoID, res.InfoStates[0].Id)
+	assert.Equal(t, txID, res.InfoStates[0].TransactionId)
+
+	// truncated info state IDs cannot be decoded
+	ev.DataJson = fmt.Sprintf(`{"data":"%s","inputs":["0x1234"],"outputs":["0x5678"]}`, data[:len(data)-1])
+	err = z.handleTransferEvent(ctx, merkleTree, storage, ev, "testToken1", res)
+	require.NoError(t, err)
+	assert.Len(t, res.TransactionsComplete, 1)
+
+	_, err = encodeTransactionData(ctx, &prototk.TransactionSpecification{TransactionId: txID}, []*prototk.EndorsableState{{Id: "bad"}})
+	assert.Error(t, err)
+	_, err = encodeTransactionData(ctx, &prototk.TransactionSpecification{TransactionId: "0x1234"}, []*prototk.EndorsableState{{Id: infoID}})
+	assert.ErrorContains(t, err, "PD210")
+}
+
 func TestUpdateMerkleTree(t *testing.T) {
 	z, testCallbacks := newTestZeto()
 	storage := smt.NewStatesStorage(testCallbacks, "testToken1", "context1", "merkle_tree_root", "merkle_tree_node")
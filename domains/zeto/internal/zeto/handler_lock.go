@@ -147,7 +147,7 @@ func (h *lockHandler) Assemble(ctx context.Context, tx *types.ParsedTransaction,
 	if err != nil {
 		return nil, i18n.NewError(ctx, msgs.MsgErrorDecodeContractAddress, err)
 	}
-	payloadBytes, err := h.formatProvingRequest(ctx, inputCoins, "check_utxos_owner", req.StateQueryContext, contractAddress)
+	payloadBytes, err := h.formatProvingRequest(ctx, tx.Transaction.TransactionId, inputCoins, "check_utxos_owner", req.StateQueryContext, contractAddress)
 	if err != nil {
 		return nil, i18n.NewError(ctx, msgs.MsgErrorFormatProvingReq, err)
 	}
@@ -200,7 +200,7 @@ func (h *lockHandler) Prepare(ctx context.Context, tx *types.ParsedTransaction,
 		return nil, i18n.NewError(ctx, msgs.MsgErrorUnmarshalProvingRes, err)
 	}
 
-	data, err := encodeTransactionData(ctx, req.Transaction)
+	data, err := encodeTransactionData(ctx, req.Transaction, req.InfoStates)
 	if err != nil {
 		return nil, i18n.NewError(ctx, msgs.MsgErrorEncodeTxData, err)
 	}
@@ -228,7 +228,7 @@ func (h *lockHandler) Prepare(ctx context.Context, tx *types.ParsedTransaction,
 	}, nil
 }
 
-func (h *lockHandler) formatProvingRequest(ctx context.Context, inputCoins []*types.ZetoCoin, circuitId, stateQueryContext string, contractAddress *tktypes.EthAddress) ([]byte, error) {
+func (h *lockHandler) formatProvingRequest(ctx context.Context, transactionId string, inputCoins []*types.ZetoCoin, circuitId, stateQueryContext string, contractAddress *tktypes.EthAddress) ([]byte, error) {
 	inputSize := common.GetInputSize(len(inputCoins))
 	inputCommitments := make([]string, inputSize)
 	inputValueInts := make([]uint64, inputSize)
@@ -251,7 +251,8 @@ func (h *lockHandler) formatProvingRequest(ctx context.Context, inputCoins []*ty
 	}
 
 	payload := &corepb.ProvingRequest{
-		CircuitId: circuitId,
+		CircuitId:     circuitId,
+		TransactionId: transactionId,
 		Common: &corepb.ProvingRequestCommon{
 			InputCommitments: inputCommitments,
 			InputValues:      inputValueInts,
@@ -76,7 +76,11 @@ func (h *mintHandler) Assemble(ctx context.Context, tx *types.ParsedTransaction,
 	params := tx.Params.([]*types.TransferParamEntry)
 
 	useNullifiers := common.IsNullifiersToken(tx.DomainConfig.TokenName)
-	_, outputStates, err := h.zeto.prepareOutputsForTransfer(ctx, useNullifiers, params, req.ResolvedVerifiers)
+	outputCoins, outputStates, err := h.zeto.prepareOutputsForTransfer(ctx, useNullifiers, params, req.ResolvedVerifiers)
+	if err != nil {
+		return nil, err
+	}
+	infoStates, err := h.zeto.prepareTransferInfo(ctx, tx.Transaction.From, nil, params, outputCoins)
 	if err != nil {
 		return nil, err
 	}
@@ -84,6 +88,7 @@ func (h *mintHandler) Assemble(ctx context.Context, tx *types.ParsedTransaction,
 		AssemblyResult: pb.AssembleTransactionResponse_OK,
 		AssembledTransaction: &pb.AssembledTransaction{
 			OutputStates: outputStates,
+			InfoStates:   infoStates,
 		},
 		AttestationPlan: []*pb.AttestationRequest{},
 	}, nil
@@ -107,7 +112,7 @@ func (h *mintHandler) Prepare(ctx context.Context, tx *types.ParsedTransaction,
 		outputs[i] = hash.String()
 	}
 
-	data, err := encodeTransactionData(ctx, req.Transaction)
+	data, err := encodeTransactionData(ctx, req.Transaction, req.InfoStates)
 	if err != nil {
 		return nil, i18n.NewError(ctx, msgs.MsgErrorEncodeTxData, err)
 	}
@@ -90,6 +90,9 @@ func TestMintAssemble(t *testing.T) {
 			coinSchema: &prototk.StateSchema{
 				Id: "coin",
 			},
+			transferInfoSchema: &prototk.StateSchema{
+				Id: "transfer_info",
+			},
 		},
 	}
 	ctx := context.Background()
@@ -29,6 +29,7 @@ import (
 	"github.com/kaleido-io/paladin/toolkit/pkg/prototk"
 	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"google.golang.org/protobuf/proto"
 )
 
@@ -100,6 +101,9 @@ func TestTransferAssemble(t *testing.T) {
 			coinSchema: &prototk.StateSchema{
 				Id: "coin",
 			},
+			transferInfoSchema: &prototk.StateSchema{
+				Id: "transfer_info",
+			},
 			merkleTreeRootSchema: &prototk.StateSchema{
 				Id: "merkle_tree_root",
 			},
@@ -110,7 +114,8 @@ func TestTransferAssemble(t *testing.T) {
 	}
 	ctx := context.Background()
 	txSpec := &prototk.TransactionSpecification{
-		From: "Bob",
+		TransactionId: "0x1234",
+		From:          "Bob",
 		ContractInfo: &prototk.ContractInfo{
 			ContractAddress: "0x1234567890123456789012345678901234567890",
 		},
@@ -214,6 +219,25 @@ func TestTransferAssemble(t *testing.T) {
 	assert.Equal(t, "0x7cdd539f3ed6c283494f47d8481f84308a6d7043087fb6711c9f1df04e2b8025", coin2.Owner.String())
 	assert.Equal(t, "0x06", coin2.Amount.String())
 
+	require.Len(t, res.AssembledTransaction.InfoStates, 1)
+	assert.Equal(t, "transfer_info", res.AssembledTransaction.InfoStates[0].SchemaId)
+	assert.Equal(t, []string{"Bob", "Alice"}, res.AssembledTransaction.InfoStates[0].DistributionList)
+	var info types.ZetoTransferInfo
+	err = json.Unmarshal([]byte(res.AssembledTransaction.InfoStates[0].StateDataJson), &info)
+	require.NoError(t, err)
+	assert.Equal(t, "Bob", info.From)
+	assert.Equal(t, "Alice", info.To)
+	assert.Equal(t, coin1.Owner, info.ToKey)
+	assert.Equal(t, "0x09", info.Amount.String())
+	infoHash, err := info.Hash(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, infoHash.String(), *res.AssembledTransaction.InfoStates[0].Id)
+
+	var provingReq corepb.ProvingRequest
+	err = proto.Unmarshal(res.AttestationPlan[0].Payload, &provingReq)
+	require.NoError(t, err)
+	assert.Equal(t, "0x1234", provingReq.TransactionId)
+
 	testCallbacks.returnFunc = func() (*prototk.FindAvailableStatesResponse, error) {
 		return &prototk.FindAvailableStatesResponse{
 			States: []*prototk.StoredState{
@@ -400,6 +424,9 @@ func TestGenerateMerkleProofs(t *testing.T) {
 			coinSchema: &prototk.StateSchema{
 				Id: "coin",
 			},
+			transferInfoSchema: &prototk.StateSchema{
+				Id: "transfer_info",
+			},
 			merkleTreeRootSchema: &prototk.StateSchema{
 				Id: "merkle_tree_root",
 			},
@@ -507,7 +534,8 @@ func TestGenerateMerkleProofs(t *testing.T) {
 }
 
 type testDomainCallbacks struct {
-	returnFunc func() (*prototk.FindAvailableStatesResponse, error)
+	returnFunc      func() (*prototk.FindAvailableStatesResponse, error)
+	queryEventsFunc func(*prototk.QueryContractEventsRequest) (*prototk.QueryContractEventsResponse, error)
 }
 
 func (dc *testDomainCallbacks) FindAvailableStates(ctx context.Context, req *prototk.FindAvailableStatesRequest) (*prototk.FindAvailableStatesResponse, error) {
@@ -521,6 +549,10 @@ func (dc *testDomainCallbacks) RecoverSigner(ctx context.Context, req *prototk.R
 	return nil, nil
 }
 
+func (dc *testDomainCallbacks) QueryContractEvents(ctx context.Context, req *prototk.QueryContractEventsRequest) (*prototk.QueryContractEventsResponse, error) {
+	return dc.queryEventsFunc(req)
+}
+
 func (dc *testDomainCallbacks) DecodeData(context.Context, *prototk.DecodeDataRequest) (*prototk.DecodeDataResponse, error) {
 	return nil, nil
 }
@@ -109,11 +109,20 @@ func (h *withdrawHandler) Assemble(ctx context.Context, tx *types.ParsedTransact
 		return nil, i18n.NewError(ctx, msgs.MsgErrorPrepTxOutputs, err)
 	}
 
+	infoState, err := h.zeto.makeTransferInfoState(ctx, &types.ZetoTransferInfo{
+		From:    tx.Transaction.From,
+		FromKey: outputCoin.Owner,
+		Amount:  amount,
+	}, []string{tx.Transaction.From})
+	if err != nil {
+		return nil, i18n.NewError(ctx, msgs.MsgErrorCreateTransferInfo, err)
+	}
+
 	contractAddress, err := tktypes.ParseEthAddress(req.Transaction.ContractInfo.ContractAddress)
 	if err != nil {
 		return nil, i18n.NewError(ctx, msgs.MsgErrorDecodeContractAddress, err)
 	}
-	payloadBytes, err := h.formatProvingRequest(ctx, inputCoins, outputCoin, tx.DomainConfig.CircuitId, tx.DomainConfig.TokenName, req.StateQueryContext, contractAddress)
+	payloadBytes, err := h.formatProvingRequest(ctx, tx.Transaction.TransactionId, inputCoins, outputCoin, tx.DomainConfig.CircuitId, tx.DomainConfig.TokenName, req.StateQueryContext, contractAddress)
 	if err != nil {
 		return nil, i18n.NewError(ctx, msgs.MsgErrorFormatProvingReq, err)
 	}
@@ -124,6 +133,7 @@ func (h *withdrawHandler) Assemble(ctx context.Context, tx *types.ParsedTransact
 		AssembledTransaction: &pb.AssembledTransaction{
 			InputStates:  inputStates,
 			OutputStates: []*pb.NewState{outputState},
+			InfoStates:   []*pb.NewState{infoState},
 			DomainData:   &amountStr,
 		},
 		AttestationPlan: []*pb.AttestationRequest{
@@ -183,7 +193,7 @@ func (h *withdrawHandler) Prepare(ctx context.Context, tx *types.ParsedTransacti
 	}
 	output := hash.String()
 
-	data, err := encodeTransactionData(ctx, req.Transaction)
+	data, err := encodeTransactionData(ctx, req.Transaction, req.InfoStates)
 	if err != nil {
 		return nil, i18n.NewError(ctx, msgs.MsgErrorEncodeTxData, err)
 	}
@@ -219,7 +229,7 @@ func (h *withdrawHandler) Prepare(ctx context.Context, tx *types.ParsedTransacti
 	}, nil
 }
 
-func (h *withdrawHandler) formatProvingRequest(ctx context.Context, inputCoins []*types.ZetoCoin, outputCoin *types.ZetoCoin, circuitId, tokenName, stateQueryContext string, contractAddress *tktypes.EthAddress) ([]byte, error) {
+func (h *withdrawHandler) formatProvingRequest(ctx context.Context, transactionId string, inputCoins []*types.ZetoCoin, outputCoin *types.ZetoCoin, circuitId, tokenName, stateQueryContext string, contractAddress *tktypes.EthAddress) ([]byte, error) {
 	inputSize := common.GetInputSize(len(inputCoins))
 	inputCommitments := make([]string, inputSize)
 	inputValueInts := make([]uint64, inputSize)
@@ -268,7 +278,8 @@ func (h *withdrawHandler) formatProvingRequest(ctx context.Context, inputCoins [
 	}
 
 	payload := &corepb.ProvingRequest{
-		CircuitId: getCircuitId(tokenName),
+		CircuitId:     getCircuitId(tokenName),
+		TransactionId: transactionId,
 		Common: &corepb.ProvingRequestCommon{
 			InputCommitments:  inputCommitments,
 			InputValues:       inputValueInts,
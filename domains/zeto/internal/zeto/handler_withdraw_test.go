@@ -59,6 +59,9 @@ func TestWithdrawAssemble(t *testing.T) {
 			coinSchema: &prototk.StateSchema{
 				Id: "coin",
 			},
+			transferInfoSchema: &prototk.StateSchema{
+				Id: "transfer_info",
+			},
 			merkleTreeRootSchema: &prototk.StateSchema{
 				Id: "merkle_tree_root",
 			},
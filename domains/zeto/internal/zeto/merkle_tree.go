@@ -0,0 +1,340 @@
+/*
+ * Copyright © 2024 Kaleido, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package zeto
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/hyperledger-labs/zeto/go-sdk/pkg/sparse-merkle-tree/core"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/kaleido-io/paladin/domains/zeto/internal/msgs"
+	"github.com/kaleido-io/paladin/domains/zeto/internal/zeto/common"
+	"github.com/kaleido-io/paladin/domains/zeto/internal/zeto/smt"
+	"github.com/kaleido-io/paladin/domains/zeto/pkg/types"
+	"github.com/kaleido-io/paladin/toolkit/pkg/log"
+	"github.com/kaleido-io/paladin/toolkit/pkg/prototk"
+	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
+)
+
+const merkleTreeEventsPageSize = 100
+
+// The maintenance operations on the Merkle tree of a contract run against a state query context
+// for the contract, which is used both to read the stored tree and to read back the events of
+// the contract. The operations that change the tree return the states to be written, rather
+// than writing them, in the same way as the event handlers.
+
+type merkleTreeRoot struct {
+	root tktypes.Bytes32
+	txID string
+}
+
+// merkleTreeReplay is the tree built from the indexed events of a contract, with the root
+// after each event that added to it
+type merkleTreeReplay struct {
+	storage smt.StatesStorage
+	tree    core.SparseMerkleTree
+	leaves  int
+	history []*merkleTreeRoot
+}
+
+func (r *merkleTreeReplay) findRoot(root tktypes.Bytes32) *merkleTreeRoot {
+	for _, h := range r.history {
+		if h.root == root {
+			return h
+		}
+	}
+	return nil
+}
+
+func (z *Zeto) merkleTreeName(ctx context.Context, contract *prototk.ContractInfo) (string, error) {
+	var domainConfig *types.DomainInstanceConfig
+	if err := json.Unmarshal([]byte(contract.ContractConfigJson), &domainConfig); err != nil {
+		return "", i18n.NewError(ctx, msgs.MsgErrorAbiDecodeDomainInstanceConfig, err)
+	}
+	contractAddress, err := tktypes.ParseEthAddress(contract.ContractAddress)
+	if err != nil {
+		return "", i18n.NewError(ctx, msgs.MsgErrorDecodeContractAddress, err)
+	}
+	if !common.IsNullifiersToken(domainConfig.TokenName) {
+		return "", i18n.NewError(ctx, msgs.MsgErrorMerkleTreeNotSupported, domainConfig.TokenName)
+	}
+	return smt.MerkleTreeName(domainConfig.TokenName, contractAddress), nil
+}
+
+func (z *Zeto) loadMerkleTree(ctx context.Context, smtName, stateQueryContext string) (core.SparseMerkleTree, error) {
+	storage := smt.NewStatesStorage(z.Callbacks, smtName, stateQueryContext, z.merkleTreeRootSchema.Id, z.merkleTreeNodeSchema.Id)
+	tree, err := smt.NewSmt(storage)
+	if err != nil {
+		return nil, i18n.NewError(ctx, msgs.MsgErrorNewSmt, smtName, err)
+	}
+	return tree, nil
+}
+
+func (z *Zeto) newEmptyMerkleTree(ctx context.Context, smtName, stateQueryContext string) (smt.StatesStorage, core.SparseMerkleTree, error) {
+	storage := smt.NewEmptyStatesStorage(z.Callbacks, smtName, stateQueryContext, z.merkleTreeRootSchema.Id, z.merkleTreeNodeSchema.Id)
+	tree, err := smt.NewSmt(storage)
+	if err != nil {
+		return nil, nil, i18n.NewError(ctx, msgs.MsgErrorNewSmt, smtName, err)
+	}
+	return storage, tree, nil
+}
+
+func merkleTreeRootBytes(ctx context.Context, tree core.SparseMerkleTree) (tktypes.Bytes32, error) {
+	root, err := tktypes.ParseBytes32(tree.Root().Hex())
+	if err != nil {
+		return root, i18n.NewError(ctx, msgs.MsgErrorParseRootNodeIdx, err)
+	}
+	return root, nil
+}
+
+// merkleTreeOutputs returns the UTXOs that an event adds to the Merkle tree, skipping the same
+// events as the event handlers do so that the replayed tree matches the one they build
+func (z *Zeto) merkleTreeOutputs(ctx context.Context, ev *prototk.OnChainEvent) (txID tktypes.HexBytes, outputs []tktypes.HexUint256) {
+	var data tktypes.HexBytes
+	var err error
+	switch ev.SoliditySignature {
+	case z.mintSignature:
+		var mint MintEvent
+		err = json.Unmarshal([]byte(ev.DataJson), &mint)
+		data, outputs = mint.Data, mint.Outputs
+	case z.transferSignature:
+		var transfer TransferEvent
+		err = json.Unmarshal([]byte(ev.DataJson), &transfer)
+		data, outputs = transfer.Data, transfer.Outputs
+	case z.transferWithEncSignature:
+		var transfer TransferWithEncryptedValuesEvent
+		err = json.Unmarshal([]byte(ev.DataJson), &transfer)
+		data, outputs = transfer.Data, transfer.Outputs
+	case z.withdrawSignature:
+		var withdraw WithdrawEvent
+		err = json.Unmarshal([]byte(ev.DataJson), &withdraw)
+		data, outputs = withdraw.Data, []tktypes.HexUint256{withdraw.Output}
+	default:
+		return nil, nil
+	}
+	if err != nil {
+		log.L(ctx).Errorf("Failed to unmarshal %s event: %s", ev.SoliditySignature, err)
+		return nil, nil
+	}
+	txID, _ = decodeTransactionData(data)
+	if txID == nil {
+		return nil, nil
+	}
+	return txID, outputs
+}
+
+// replayMerkleTree builds the Merkle tree of a contract from an empty tree, by adding the
+// outputs of each of the indexed events of the contract in turn
+func (z *Zeto) replayMerkleTree(ctx context.Context, smtName, stateQueryContext, contractAddress string) (*merkleTreeReplay, error) {
+	storage, tree, err := z.newEmptyMerkleTree(ctx, smtName, stateQueryContext)
+	if err != nil {
+		return nil, err
+	}
+	r := &merkleTreeReplay{storage: storage, tree: tree}
+	var after *prototk.OnChainEventLocation
+	for {
+		page, err := z.Callbacks.QueryContractEvents(ctx, &prototk.QueryContractEventsRequest{
+			StateQueryContext: stateQueryContext,
+			After:             after,
+			Limit:             merkleTreeEventsPageSize,
+		})
+		if err != nil {
+			return nil, i18n.NewError(ctx, msgs.MsgErrorQueryContractEvents, contractAddress, err)
+		}
+		for _, ev := range page.Events {
+			txID, outputs := z.merkleTreeOutputs(ctx, ev)
+			if txID == nil {
+				continue
+			}
+			leaves := 0
+			for _, out := range outputs {
+				if !out.NilOrZero() {
+					leaves++
+				}
+			}
+			if leaves == 0 {
+				continue
+			}
+			if err := z.updateMerkleTree(ctx, tree, storage, txID, outputs); err != nil {
+				return nil, i18n.NewError(ctx, msgs.MsgErrorUpdateSMT, ev.SoliditySignature, err)
+			}
+			root, err := merkleTreeRootBytes(ctx, tree)
+			if err != nil {
+				return nil, err
+			}
+			r.leaves += leaves
+			r.history = append(r.history, &merkleTreeRoot{root: root, txID: txID.HexString0xPrefix()})
+		}
+		if page.Next == nil {
+			return r, nil
+		}
+		after = page.Next
+	}
+}
+
+func (z *Zeto) checkMerkleTree(ctx context.Context, contract *prototk.ContractInfo, stateQueryContext string) (*types.MerkleTreeCheck, error) {
+	smtName, err := z.merkleTreeName(ctx, contract)
+	if err != nil {
+		return nil, err
+	}
+	local, err := z.loadMerkleTree(ctx, smtName, stateQueryContext)
+	if err != nil {
+		return nil, err
+	}
+	localLeaves, missingNodes, err := smt.ListLeaves(ctx, smtName, local)
+	if err != nil {
+		return nil, err
+	}
+	replay, err := z.replayMerkleTree(ctx, smtName, stateQueryContext, contract.ContractAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	check := &types.MerkleTreeCheck{
+		SmtName:       smtName,
+		LocalLeaves:   len(localLeaves),
+		MissingNodes:  missingNodes,
+		IndexedLeaves: replay.leaves,
+	}
+	if check.LocalRoot, err = merkleTreeRootBytes(ctx, local); err == nil {
+		check.IndexedRoot, err = merkleTreeRootBytes(ctx, replay.tree)
+	}
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case check.LocalRoot != check.IndexedRoot && !check.LocalRoot.IsZero() && replay.findRoot(check.LocalRoot) == nil:
+		check.Status = types.MerkleTreeDiverged
+	case missingNodes > 0:
+		check.Status = types.MerkleTreeIncomplete
+	case check.LocalRoot != check.IndexedRoot:
+		check.Status = types.MerkleTreeBehind
+	default:
+		check.Status = types.MerkleTreeInSync
+	}
+	return check, nil
+}
+
+func (z *Zeto) rebuildMerkleTree(ctx context.Context, contract *prototk.ContractInfo, stateQueryContext string) (*types.MerkleTreeUpdate, []*prototk.NewConfirmedState, error) {
+	smtName, err := z.merkleTreeName(ctx, contract)
+	if err != nil {
+		return nil, nil, err
+	}
+	local, err := z.loadMerkleTree(ctx, smtName, stateQueryContext)
+	if err != nil {
+		return nil, nil, err
+	}
+	replay, err := z.replayMerkleTree(ctx, smtName, stateQueryContext, contract.ContractAddress)
+	if err != nil {
+		return nil, nil, err
+	}
+	update := &types.MerkleTreeUpdate{
+		SmtName: smtName,
+		Leaves:  replay.leaves,
+	}
+	if update.PreviousRoot, err = merkleTreeRootBytes(ctx, local); err == nil {
+		update.Root, err = merkleTreeRootBytes(ctx, replay.tree)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	newStates, err := replay.storage.GetNewStates()
+	if err != nil {
+		return nil, nil, i18n.NewError(ctx, msgs.MsgErrorGetNewSmtStates, smtName, err)
+	}
+	return update, newStates, nil
+}
+
+func (z *Zeto) exportMerkleTree(ctx context.Context, contract *prototk.ContractInfo, stateQueryContext string) (*types.MerkleTreeSnapshot, error) {
+	smtName, err := z.merkleTreeName(ctx, contract)
+	if err != nil {
+		return nil, err
+	}
+	local, err := z.loadMerkleTree(ctx, smtName, stateQueryContext)
+	if err != nil {
+		return nil, err
+	}
+	leaves, missingNodes, err := smt.ListLeaves(ctx, smtName, local)
+	if err == nil && missingNodes > 0 {
+		err = i18n.NewError(ctx, msgs.MsgErrorReadMerkleTreeNode, local.Root().Hex(), smtName, "nodes missing")
+	}
+	if err != nil {
+		return nil, err
+	}
+	snapshot := &types.MerkleTreeSnapshot{
+		SmtName: smtName,
+		Leaves:  leaves,
+	}
+	if snapshot.Root, err = merkleTreeRootBytes(ctx, local); err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// importMerkleTree builds the tree from the leaves of a snapshot. The snapshot is only accepted
+// if its root is one that the indexed events of the contract produce, and the states of the tree
+// are recorded against the transaction that produced that root.
+func (z *Zeto) importMerkleTree(ctx context.Context, contract *prototk.ContractInfo, stateQueryContext string, snapshot *types.MerkleTreeSnapshot) (*types.MerkleTreeUpdate, []*prototk.NewConfirmedState, error) {
+	smtName, err := z.merkleTreeName(ctx, contract)
+	if err != nil {
+		return nil, nil, err
+	}
+	if snapshot.SmtName != smtName {
+		return nil, nil, i18n.NewError(ctx, msgs.MsgErrorSnapshotTreeName, snapshot.SmtName, smtName)
+	}
+	local, err := z.loadMerkleTree(ctx, smtName, stateQueryContext)
+	if err != nil {
+		return nil, nil, err
+	}
+	replay, err := z.replayMerkleTree(ctx, smtName, stateQueryContext, contract.ContractAddress)
+	if err != nil {
+		return nil, nil, err
+	}
+	indexedRoot := replay.findRoot(snapshot.Root)
+	if indexedRoot == nil {
+		return nil, nil, i18n.NewError(ctx, msgs.MsgErrorSnapshotRootNotIndexed, snapshot.Root)
+	}
+
+	storage, tree, err := z.newEmptyMerkleTree(ctx, smtName, stateQueryContext)
+	if err != nil {
+		return nil, nil, err
+	}
+	storage.SetTransactionId(indexedRoot.txID)
+	for _, leaf := range snapshot.Leaves {
+		if err := z.addOutputToMerkleTree(ctx, tree, leaf); err != nil {
+			return nil, nil, err
+		}
+	}
+	update := &types.MerkleTreeUpdate{
+		SmtName: smtName,
+		Leaves:  len(snapshot.Leaves),
+	}
+	if update.PreviousRoot, err = merkleTreeRootBytes(ctx, local); err == nil {
+		update.Root, err = merkleTreeRootBytes(ctx, tree)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if update.Root != snapshot.Root {
+		return nil, nil, i18n.NewError(ctx, msgs.MsgErrorSnapshotRootMismatch, update.Root, snapshot.Root)
+	}
+	newStates, err := storage.GetNewStates()
+	if err != nil {
+		return nil, nil, i18n.NewError(ctx, msgs.MsgErrorGetNewSmtStates, smtName, err)
+	}
+	return update, newStates, nil
+}
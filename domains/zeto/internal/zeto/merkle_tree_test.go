@@ -0,0 +1,281 @@
+package zeto
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/kaleido-io/paladin/domains/zeto/internal/zeto/smt"
+	"github.com/kaleido-io/paladin/domains/zeto/pkg/types"
+	"github.com/kaleido-io/paladin/toolkit/pkg/prototk"
+	"github.com/kaleido-io/paladin/toolkit/pkg/query"
+	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// merkleTreeTestCallbacks serves the Merkle tree states that have been written,
+// and a fixed list of contract events
+type merkleTreeTestCallbacks struct {
+	testDomainCallbacks
+	states    []*prototk.NewConfirmedState
+	events    []*prototk.OnChainEvent
+	pageSize  int
+	eventsErr error
+}
+
+func (dc *merkleTreeTestCallbacks) FindAvailableStates(ctx context.Context, req *prototk.FindAvailableStatesRequest) (*prototk.FindAvailableStatesResponse, error) {
+	var q query.QueryJSON
+	if err := json.Unmarshal([]byte(req.QueryJson), &q); err != nil {
+		return nil, err
+	}
+	res := &prototk.FindAvailableStatesResponse{}
+	for i := len(dc.states) - 1; i >= 0; i-- {
+		s := dc.states[i]
+		if s.SchemaId != req.SchemaId {
+			continue
+		}
+		if req.SchemaId == "merkle_tree_node" {
+			var n smt.MerkleTreeNode
+			if err := json.Unmarshal([]byte(s.StateDataJson), &n); err != nil {
+				return nil, err
+			}
+			if fmt.Sprintf(`"%s"`, n.RefKey.HexString()) != q.Eq[0].Value.String() {
+				continue
+			}
+		}
+		res.States = append(res.States, &prototk.StoredState{Id: *s.Id, DataJson: s.StateDataJson})
+		break
+	}
+	return res, nil
+}
+
+func (dc *merkleTreeTestCallbacks) QueryContractEvents(ctx context.Context, req *prototk.QueryContractEventsRequest) (*prototk.QueryContractEventsResponse, error) {
+	if dc.eventsErr != nil {
+		return nil, dc.eventsErr
+	}
+	start := 0
+	if req.After != nil {
+		start = int(req.After.LogIndex) + 1
+	}
+	end := start + dc.pageSize
+	res := &prototk.QueryContractEventsResponse{}
+	if end < len(dc.events) {
+		res.Next = &prototk.OnChainEventLocation{LogIndex: int64(end - 1)}
+	} else {
+		end = len(dc.events)
+	}
+	res.Events = dc.events[start:end]
+	return res, nil
+}
+
+func newMerkleTreeTestZeto() (*Zeto, *merkleTreeTestCallbacks, *prototk.ContractInfo) {
+	z, _ := newTestZeto()
+	z.withdrawSignature = "event UTXOWithdraw(uint256 amount, uint256[] inputs, uint256 output, address indexed submitter, bytes data)"
+	txData := func(i int) string {
+		return fmt.Sprintf("0x00010000%064x", i)
+	}
+	callbacks := &merkleTreeTestCallbacks{
+		pageSize: 2,
+		events: []*prototk.OnChainEvent{
+			{
+				SoliditySignature: z.mintSignature,
+				DataJson:          fmt.Sprintf(`{"outputs":["0x01","0x02"],"data":"%s"}`, txData(1)),
+			},
+			{
+				SoliditySignature: z.mintSignature,
+				DataJson:          `{"outputs":["0x09"],"data":"0x"}`, // skipped as the transaction is unknown
+			},
+			{
+				SoliditySignature: z.transferSignature,
+				DataJson:          fmt.Sprintf(`{"inputs":["0x01"],"outputs":["0x03","0x00"],"data":"%s"}`, txData(2)),
+			},
+			{
+				SoliditySignature: "event UTXOsLocked(uint256[] utxos, address indexed delegate, address indexed submitter, bytes data)",
+				DataJson:          fmt.Sprintf(`{"utxos":["0x02"],"data":"%s"}`, txData(3)),
+			},
+			{
+				SoliditySignature: z.withdrawSignature,
+				DataJson:          fmt.Sprintf(`{"amount":"0x01","inputs":["0x02"],"output":"0x04","data":"%s"}`, txData(4)),
+			},
+		},
+	}
+	z.Callbacks = callbacks
+	contract := &prototk.ContractInfo{
+		ContractAddress: "0x1234567890123456789012345678901234567890",
+		ContractConfigJson: tktypes.JSONString(map[string]interface{}{
+			"circuitId": "anon_nullifier",
+			"tokenName": "Zeto_AnonNullifier",
+		}).Pretty(),
+	}
+	return z, callbacks, contract
+}
+
+func TestMerkleTreeRebuild(t *testing.T) {
+	z, callbacks, contract := newMerkleTreeTestZeto()
+	ctx := context.Background()
+
+	check, err := z.checkMerkleTree(ctx, contract, "sqc1")
+	require.NoError(t, err)
+	assert.Equal(t, "smt_Zeto_AnonNullifier_0x1234567890123456789012345678901234567890", check.SmtName)
+	assert.Equal(t, types.MerkleTreeBehind, check.Status)
+	assert.True(t, check.LocalRoot.IsZero())
+	assert.Equal(t, 0, check.LocalLeaves)
+	assert.Equal(t, 4, check.IndexedLeaves)
+	assert.False(t, check.IndexedRoot.IsZero())
+
+	update, newStates, err := z.rebuildMerkleTree(ctx, contract, "sqc1")
+	require.NoError(t, err)
+	assert.True(t, update.PreviousRoot.IsZero())
+	assert.Equal(t, check.IndexedRoot, update.Root)
+	assert.Equal(t, 4, update.Leaves)
+	require.NotEmpty(t, newStates)
+	assert.Equal(t, "merkle_tree_root", newStates[0].SchemaId)
+	assert.Equal(t, fmt.Sprintf("0x%064x", 4), newStates[0].TransactionId)
+	callbacks.states = append(callbacks.states, newStates...)
+
+	check, err = z.checkMerkleTree(ctx, contract, "sqc1")
+	require.NoError(t, err)
+	assert.Equal(t, types.MerkleTreeInSync, check.Status)
+	assert.Equal(t, check.IndexedRoot, check.LocalRoot)
+	assert.Equal(t, 4, check.LocalLeaves)
+	assert.Zero(t, check.MissingNodes)
+}
+
+func TestMerkleTreeCheckStatus(t *testing.T) {
+	z, callbacks, contract := newMerkleTreeTestZeto()
+	ctx := context.Background()
+
+	// Build the tree as it was after the first mint
+	events := callbacks.events
+	callbacks.events = events[0:1]
+	_, newStates, err := z.rebuildMerkleTree(ctx, contract, "sqc1")
+	require.NoError(t, err)
+	callbacks.states = newStates
+	callbacks.events = events
+
+	check, err := z.checkMerkleTree(ctx, contract, "sqc1")
+	require.NoError(t, err)
+	assert.Equal(t, types.MerkleTreeBehind, check.Status)
+	assert.Equal(t, 2, check.LocalLeaves)
+
+	// Lose one of the leaves
+	for i, s := range callbacks.states {
+		if s.SchemaId == "merkle_tree_node" && s.TransactionId != "" {
+			var n smt.MerkleTreeNode
+			require.NoError(t, json.Unmarshal([]byte(s.StateDataJson), &n))
+			if n.Type[0] == 2 {
+				callbacks.states = append(callbacks.states[0:i], callbacks.states[i+1:]...)
+				break
+			}
+		}
+	}
+	check, err = z.checkMerkleTree(ctx, contract, "sqc1")
+	require.NoError(t, err)
+	assert.Equal(t, types.MerkleTreeIncomplete, check.Status)
+	assert.Equal(t, 1, check.LocalLeaves)
+	assert.Equal(t, 1, check.MissingNodes)
+
+	_, err = z.exportMerkleTree(ctx, contract, "sqc1")
+	assert.ErrorContains(t, err, "PD210128")
+
+	// A root that the contract never had
+	callbacks.events = []*prototk.OnChainEvent{events[2]}
+	check, err = z.checkMerkleTree(ctx, contract, "sqc1")
+	require.NoError(t, err)
+	assert.Equal(t, types.MerkleTreeDiverged, check.Status)
+}
+
+func TestMerkleTreeExportImport(t *testing.T) {
+	z, callbacks, contract := newMerkleTreeTestZeto()
+	ctx := context.Background()
+
+	_, newStates, err := z.rebuildMerkleTree(ctx, contract, "sqc1")
+	require.NoError(t, err)
+	callbacks.states = newStates
+
+	snapshot, err := z.exportMerkleTree(ctx, contract, "sqc1")
+	require.NoError(t, err)
+	assert.Len(t, snapshot.Leaves, 4)
+	assert.ElementsMatch(t, []string{"0x01", "0x02", "0x03", "0x04"}, []string{
+		snapshot.Leaves[0].String(), snapshot.Leaves[1].String(), snapshot.Leaves[2].String(), snapshot.Leaves[3].String(),
+	})
+
+	// Import into a node that has no tree
+	callbacks.states = nil
+	update, importedStates, err := z.importMerkleTree(ctx, contract, "sqc1", snapshot)
+	require.NoError(t, err)
+	assert.True(t, update.PreviousRoot.IsZero())
+	assert.Equal(t, snapshot.Root, update.Root)
+	assert.Equal(t, 4, update.Leaves)
+	for _, s := range importedStates {
+		assert.Equal(t, fmt.Sprintf("0x%064x", 4), s.TransactionId)
+	}
+	callbacks.states = importedStates
+
+	check, err := z.checkMerkleTree(ctx, contract, "sqc1")
+	require.NoError(t, err)
+	assert.Equal(t, types.MerkleTreeInSync, check.Status)
+}
+
+func TestMerkleTreeImportFail(t *testing.T) {
+	z, callbacks, contract := newMerkleTreeTestZeto()
+	ctx := context.Background()
+
+	_, newStates, err := z.rebuildMerkleTree(ctx, contract, "sqc1")
+	require.NoError(t, err)
+	callbacks.states = newStates
+	snapshot, err := z.exportMerkleTree(ctx, contract, "sqc1")
+	require.NoError(t, err)
+
+	_, _, err = z.importMerkleTree(ctx, contract, "sqc1", &types.MerkleTreeSnapshot{SmtName: "wrong"})
+	assert.ErrorContains(t, err, "PD210129")
+
+	_, _, err = z.importMerkleTree(ctx, contract, "sqc1", &types.MerkleTreeSnapshot{
+		SmtName: snapshot.SmtName,
+		Root:    tktypes.Bytes32(tktypes.RandBytes(32)),
+		Leaves:  snapshot.Leaves,
+	})
+	assert.ErrorContains(t, err, "PD210131")
+
+	_, _, err = z.importMerkleTree(ctx, contract, "sqc1", &types.MerkleTreeSnapshot{
+		SmtName: snapshot.SmtName,
+		Root:    snapshot.Root,
+		Leaves:  snapshot.Leaves[0:3],
+	})
+	assert.ErrorContains(t, err, "PD210130")
+
+	callbacks.eventsErr = errors.New("pop")
+	_, _, err = z.importMerkleTree(ctx, contract, "sqc1", snapshot)
+	assert.ErrorContains(t, err, "PD210127")
+	_, _, err = z.rebuildMerkleTree(ctx, contract, "sqc1")
+	assert.ErrorContains(t, err, "PD210127")
+	_, err = z.checkMerkleTree(ctx, contract, "sqc1")
+	assert.ErrorContains(t, err, "PD210127")
+}
+
+func TestMerkleTreeNotSupported(t *testing.T) {
+	z, _, contract := newMerkleTreeTestZeto()
+	ctx := context.Background()
+
+	contract.ContractConfigJson = `{"circuitId":"anon","tokenName":"Zeto_Anon"}`
+	_, err := z.checkMerkleTree(ctx, contract, "sqc1")
+	assert.ErrorContains(t, err, "PD210126")
+	_, _, err = z.rebuildMerkleTree(ctx, contract, "sqc1")
+	assert.ErrorContains(t, err, "PD210126")
+	_, err = z.exportMerkleTree(ctx, contract, "sqc1")
+	assert.ErrorContains(t, err, "PD210126")
+	_, _, err = z.importMerkleTree(ctx, contract, "sqc1", &types.MerkleTreeSnapshot{})
+	assert.ErrorContains(t, err, "PD210126")
+
+	contract.ContractConfigJson = `{!!! bad config`
+	_, err = z.checkMerkleTree(ctx, contract, "sqc1")
+	assert.ErrorContains(t, err, "PD210018")
+
+	contract.ContractConfigJson = `{"circuitId":"anon_nullifier","tokenName":"Zeto_AnonNullifier"}`
+	contract.ContractAddress = "0x1234"
+	_, err = z.checkMerkleTree(ctx, contract, "sqc1")
+	assert.ErrorContains(t, err, "PD210017")
+}
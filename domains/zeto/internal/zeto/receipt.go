@@ -0,0 +1,97 @@
+/*
+ * Copyright © 2024 Kaleido, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package zeto
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/kaleido-io/paladin/domains/zeto/internal/msgs"
+	"github.com/kaleido-io/paladin/domains/zeto/pkg/types"
+	"github.com/kaleido-io/paladin/toolkit/pkg/prototk"
+	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
+)
+
+// The coins themselves only carry the Baby Jubjub key of their owner, so the identities are taken
+// from the transfer info states, which record each party as resolved when the transaction was assembled.
+// Each node only has the info states that name one of its parties, and the coins distributed to it.
+func (z *Zeto) BuildReceipt(ctx context.Context, req *prototk.BuildReceiptRequest) (*prototk.BuildReceiptResponse, error) {
+	receipt := &types.ZetoDomainReceipt{}
+
+	owners := make(map[string]string)
+	for _, state := range req.InfoStates {
+		if state.SchemaId != z.transferInfoSchema.Id {
+			continue
+		}
+		var info types.ZetoTransferInfo
+		if err := json.Unmarshal([]byte(state.StateDataJson), &info); err != nil {
+			return nil, i18n.NewError(ctx, msgs.MsgErrorParseReceiptState, state.Id, err)
+		}
+		if info.From != "" {
+			owners[info.FromKey.String()] = info.From
+		}
+		if info.To != "" {
+			owners[info.ToKey.String()] = info.To
+		}
+		receipt.Transfers = append(receipt.Transfers, &types.ReceiptTransfer{
+			From:   info.From,
+			To:     info.To,
+			Amount: info.Amount,
+		})
+	}
+
+	var err error
+	receipt.States.Inputs, err = z.receiptStates(ctx, req.InputStates, owners)
+	if err != nil {
+		return nil, err
+	}
+	receipt.States.Outputs, err = z.receiptStates(ctx, req.OutputStates, owners)
+	if err != nil {
+		return nil, err
+	}
+
+	receiptJSON, err := json.Marshal(receipt)
+	if err != nil {
+		return nil, err
+	}
+	return &prototk.BuildReceiptResponse{
+		ReceiptJson: string(receiptJSON),
+	}, nil
+}
+
+func (z *Zeto) receiptStates(ctx context.Context, states []*prototk.EndorsableState, owners map[string]string) ([]*types.ReceiptState, error) {
+	coins := make([]*types.ReceiptState, 0, len(states))
+	for _, state := range states {
+		if state.SchemaId != z.coinSchema.Id {
+			continue
+		}
+		id, err := tktypes.ParseHexBytes(ctx, state.Id)
+		if err != nil {
+			return nil, i18n.NewError(ctx, msgs.MsgErrorParseReceiptState, state.Id, err)
+		}
+		coin, err := z.makeCoin(state.StateDataJson)
+		if err != nil {
+			return nil, i18n.NewError(ctx, msgs.MsgErrorParseReceiptState, state.Id, err)
+		}
+		coins = append(coins, &types.ReceiptState{
+			ID:    id,
+			Owner: owners[coin.Owner.String()],
+			Data:  coin,
+		})
+	}
+	return coins, nil
+}
@@ -0,0 +1,152 @@
+/*
+ * Copyright © 2024 Kaleido, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package zeto
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/kaleido-io/paladin/domains/zeto/pkg/types"
+	"github.com/kaleido-io/paladin/toolkit/pkg/prototk"
+	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	aliceKey = "0x19d2ee6b9770a4f8d7c3b7906bc7595684509166fa42d718d1d880b62bcb7922"
+	bobKey   = "0x7cdd539f3ed6c283494f47d8481f84308a6d7043087fb6711c9f1df04e2b8025"
+)
+
+func receiptCoin(owner string, amount int64) *prototk.EndorsableState {
+	return &prototk.EndorsableState{
+		Id:            tktypes.RandHex(32),
+		SchemaId:      "coin",
+		StateDataJson: fmt.Sprintf(`{"salt":"0x%s","owner":"%s","amount":"%d"}`, tktypes.RandHex(32), owner, amount),
+	}
+}
+
+func receiptTransferInfo(from, fromKey, to, toKey string, amount int64) *prototk.EndorsableState {
+	return &prototk.EndorsableState{
+		Id:       tktypes.RandHex(32),
+		SchemaId: "transfer_info",
+		StateDataJson: fmt.Sprintf(`{"salt":"0x%s","from":"%s","fromKey":"%s","to":"%s","toKey":"%s","amount":"%d"}`,
+			tktypes.RandHex(32), from, fromKey, to, toKey, amount),
+	}
+}
+
+func buildReceipt(t *testing.T, z *Zeto, req *prototk.BuildReceiptRequest) *types.ZetoDomainReceipt {
+	res, err := z.BuildReceipt(context.Background(), req)
+	require.NoError(t, err)
+	var receipt types.ZetoDomainReceipt
+	err = json.Unmarshal([]byte(res.ReceiptJson), &receipt)
+	require.NoError(t, err)
+	return &receipt
+}
+
+func TestBuildReceiptTransfer(t *testing.T) {
+	z, _ := newTestZeto()
+
+	// the sender's node has all the states of the transaction
+	receipt := buildReceipt(t, z, &prototk.BuildReceiptRequest{
+		Complete:     true,
+		InputStates:  []*prototk.EndorsableState{receiptCoin(aliceKey, 60), receiptCoin(aliceKey, 40)},
+		OutputStates: []*prototk.EndorsableState{receiptCoin(bobKey, 75), receiptCoin(aliceKey, 25)},
+		InfoStates:   []*prototk.EndorsableState{receiptTransferInfo("alice@node1", aliceKey, "bob@node2", bobKey, 75)},
+	})
+	require.Len(t, receipt.States.Inputs, 2)
+	assert.Equal(t, "alice@node1", receipt.States.Inputs[0].Owner)
+	assert.Equal(t, int64(60), receipt.States.Inputs[0].Data.Amount.Int().Int64())
+	require.Len(t, receipt.States.Outputs, 2)
+	assert.Equal(t, "bob@node2", receipt.States.Outputs[0].Owner)
+	assert.Equal(t, "alice@node1", receipt.States.Outputs[1].Owner)
+	require.Len(t, receipt.Transfers, 1)
+	assert.Equal(t, "alice@node1", receipt.Transfers[0].From)
+	assert.Equal(t, "bob@node2", receipt.Transfers[0].To)
+	assert.Equal(t, int64(75), receipt.Transfers[0].Amount.Int().Int64())
+
+	// the recipient's node only has its own output, but still knows who sent it
+	receipt = buildReceipt(t, z, &prototk.BuildReceiptRequest{
+		OutputStates: []*prototk.EndorsableState{receiptCoin(bobKey, 75)},
+		InfoStates:   []*prototk.EndorsableState{receiptTransferInfo("alice@node1", aliceKey, "bob@node2", bobKey, 75)},
+	})
+	assert.Empty(t, receipt.States.Inputs)
+	require.Len(t, receipt.States.Outputs, 1)
+	assert.Equal(t, "bob@node2", receipt.States.Outputs[0].Owner)
+	require.Len(t, receipt.Transfers, 1)
+	assert.Equal(t, "alice@node1", receipt.Transfers[0].From)
+}
+
+func TestBuildReceiptMintAndWithdraw(t *testing.T) {
+	z, _ := newTestZeto()
+
+	receipt := buildReceipt(t, z, &prototk.BuildReceiptRequest{
+		OutputStates: []*prototk.EndorsableState{receiptCoin(bobKey, 100)},
+		InfoStates:   []*prototk.EndorsableState{receiptTransferInfo("", "0x", "bob@node2", bobKey, 100)},
+	})
+	require.Len(t, receipt.Transfers, 1)
+	assert.Empty(t, receipt.Transfers[0].From)
+	assert.Equal(t, "bob@node2", receipt.Transfers[0].To)
+	assert.Equal(t, "bob@node2", receipt.States.Outputs[0].Owner)
+
+	receipt = buildReceipt(t, z, &prototk.BuildReceiptRequest{
+		InputStates:  []*prototk.EndorsableState{receiptCoin(bobKey, 100)},
+		OutputStates: []*prototk.EndorsableState{receiptCoin(bobKey, 70)},
+		InfoStates:   []*prototk.EndorsableState{receiptTransferInfo("bob@node2", bobKey, "", "0x", 30)},
+	})
+	require.Len(t, receipt.Transfers, 1)
+	assert.Equal(t, "bob@node2", receipt.Transfers[0].From)
+	assert.Empty(t, receipt.Transfers[0].To)
+	assert.Equal(t, int64(30), receipt.Transfers[0].Amount.Int().Int64())
+	assert.Equal(t, "bob@node2", receipt.States.Inputs[0].Owner)
+}
+
+func TestBuildReceiptUnknownOwner(t *testing.T) {
+	z, _ := newTestZeto()
+
+	// states from before transfer info was recorded are reported without an owner
+	receipt := buildReceipt(t, z, &prototk.BuildReceiptRequest{
+		OutputStates: []*prototk.EndorsableState{
+			receiptCoin(bobKey, 10),
+			{Id: tktypes.RandHex(32), SchemaId: "merkle_tree_node", StateDataJson: "{}"},
+		},
+	})
+	require.Len(t, receipt.States.Outputs, 1)
+	assert.Empty(t, receipt.States.Outputs[0].Owner)
+	assert.Empty(t, receipt.Transfers)
+}
+
+func TestBuildReceiptBadStates(t *testing.T) {
+	z, _ := newTestZeto()
+	ctx := context.Background()
+
+	_, err := z.BuildReceipt(ctx, &prototk.BuildReceiptRequest{
+		InputStates: []*prototk.EndorsableState{{Id: "bad", SchemaId: "coin", StateDataJson: "{}"}},
+	})
+	assert.ErrorContains(t, err, "PD210117")
+
+	_, err = z.BuildReceipt(ctx, &prototk.BuildReceiptRequest{
+		OutputStates: []*prototk.EndorsableState{{Id: tktypes.RandHex(32), SchemaId: "coin", StateDataJson: "!json"}},
+	})
+	assert.ErrorContains(t, err, "PD210117")
+
+	_, err = z.BuildReceipt(ctx, &prototk.BuildReceiptRequest{
+		InfoStates: []*prototk.EndorsableState{{Id: tktypes.RandHex(32), SchemaId: "transfer_info", StateDataJson: "!json"}},
+	})
+	assert.ErrorContains(t, err, "PD210117")
+}
@@ -0,0 +1,101 @@
+/*
+ * Copyright © 2024 Kaleido, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package zeto
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/kaleido-io/paladin/domains/zeto/internal/msgs"
+	"github.com/kaleido-io/paladin/domains/zeto/pkg/types"
+	"github.com/kaleido-io/paladin/toolkit/pkg/prototk"
+	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
+)
+
+const (
+	rpcCheckMerkleTree   = "checkMerkleTree"
+	rpcRebuildMerkleTree = "rebuildMerkleTree"
+	rpcExportMerkleTree  = "exportMerkleTree"
+	rpcImportMerkleTree  = "importMerkleTree"
+)
+
+// The methods are served by Paladin as domain_<name>_<method>, with the address of the
+// Zeto contract as the first parameter
+func getRPCMethods() []*prototk.DomainRPCMethod {
+	return []*prototk.DomainRPCMethod{
+		{Name: rpcCheckMerkleTree, Contract: true},
+		{Name: rpcRebuildMerkleTree, Contract: true},
+		{Name: rpcExportMerkleTree, Contract: true},
+		{Name: rpcImportMerkleTree, Contract: true},
+	}
+}
+
+func (z *Zeto) HandleRPC(ctx context.Context, req *prototk.HandleRPCRequest) (*prototk.HandleRPCResponse, error) {
+	if req.ContractInfo == nil || req.StateQueryContext == nil {
+		return nil, i18n.NewError(ctx, msgs.MsgErrorUnknownRPCMethod, req.Method)
+	}
+	contract, stateQueryContext := req.ContractInfo, *req.StateQueryContext
+
+	var result any
+	var newStates []*prototk.NewConfirmedState
+	var err error
+	switch req.Method {
+	case rpcCheckMerkleTree:
+		if _, err = parseRPCParams(ctx, req, 0); err == nil {
+			result, err = z.checkMerkleTree(ctx, contract, stateQueryContext)
+		}
+	case rpcRebuildMerkleTree:
+		if _, err = parseRPCParams(ctx, req, 0); err == nil {
+			result, newStates, err = z.rebuildMerkleTree(ctx, contract, stateQueryContext)
+		}
+	case rpcExportMerkleTree:
+		if _, err = parseRPCParams(ctx, req, 0); err == nil {
+			result, err = z.exportMerkleTree(ctx, contract, stateQueryContext)
+		}
+	case rpcImportMerkleTree:
+		var params []tktypes.RawJSON
+		var snapshot types.MerkleTreeSnapshot
+		if params, err = parseRPCParams(ctx, req, 1); err == nil {
+			if err = json.Unmarshal(params[0], &snapshot); err != nil {
+				err = i18n.NewError(ctx, msgs.MsgErrorRPCParams, req.Method, err)
+			}
+		}
+		if err == nil {
+			result, newStates, err = z.importMerkleTree(ctx, contract, stateQueryContext, &snapshot)
+		}
+	default:
+		err = i18n.NewError(ctx, msgs.MsgErrorUnknownRPCMethod, req.Method)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &prototk.HandleRPCResponse{
+		ResultJson: tktypes.JSONString(result).String(),
+		NewStates:  newStates,
+	}, nil
+}
+
+func parseRPCParams(ctx context.Context, req *prototk.HandleRPCRequest, count int) ([]tktypes.RawJSON, error) {
+	var params []tktypes.RawJSON
+	if err := json.Unmarshal([]byte(req.ParamsJson), &params); err != nil {
+		return nil, i18n.NewError(ctx, msgs.MsgErrorRPCParams, req.Method, err)
+	}
+	if len(params) != count {
+		return nil, i18n.NewError(ctx, msgs.MsgErrorRPCParams, req.Method, "wrong number of parameters")
+	}
+	return params, nil
+}
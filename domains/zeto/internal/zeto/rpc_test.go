@@ -0,0 +1,120 @@
+package zeto
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/kaleido-io/paladin/domains/zeto/pkg/types"
+	"github.com/kaleido-io/paladin/toolkit/pkg/prototk"
+	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleRPCMerkleTree(t *testing.T) {
+	z, callbacks, contract := newMerkleTreeTestZeto()
+	ctx := context.Background()
+	sqc := "sqc1"
+
+	res, err := z.HandleRPC(ctx, &prototk.HandleRPCRequest{
+		Method:            "rebuildMerkleTree",
+		ParamsJson:        `[]`,
+		StateQueryContext: &sqc,
+		ContractInfo:      contract,
+	})
+	require.NoError(t, err)
+	var update types.MerkleTreeUpdate
+	require.NoError(t, json.Unmarshal([]byte(res.ResultJson), &update))
+	assert.Equal(t, 4, update.Leaves)
+	require.NotEmpty(t, res.NewStates)
+	callbacks.states = res.NewStates
+
+	res, err = z.HandleRPC(ctx, &prototk.HandleRPCRequest{
+		Method:            "checkMerkleTree",
+		ParamsJson:        `[]`,
+		StateQueryContext: &sqc,
+		ContractInfo:      contract,
+	})
+	require.NoError(t, err)
+	var check types.MerkleTreeCheck
+	require.NoError(t, json.Unmarshal([]byte(res.ResultJson), &check))
+	assert.Equal(t, types.MerkleTreeInSync, check.Status)
+	assert.Empty(t, res.NewStates)
+
+	res, err = z.HandleRPC(ctx, &prototk.HandleRPCRequest{
+		Method:            "exportMerkleTree",
+		ParamsJson:        `[]`,
+		StateQueryContext: &sqc,
+		ContractInfo:      contract,
+	})
+	require.NoError(t, err)
+	snapshotJSON := res.ResultJson
+
+	callbacks.states = nil
+	res, err = z.HandleRPC(ctx, &prototk.HandleRPCRequest{
+		Method:            "importMerkleTree",
+		ParamsJson:        "[" + snapshotJSON + "]",
+		StateQueryContext: &sqc,
+		ContractInfo:      contract,
+	})
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal([]byte(res.ResultJson), &update))
+	assert.Equal(t, check.IndexedRoot, update.Root)
+	assert.NotEmpty(t, res.NewStates)
+}
+
+func TestHandleRPCFail(t *testing.T) {
+	z, _, contract := newMerkleTreeTestZeto()
+	ctx := context.Background()
+	sqc := "sqc1"
+
+	_, err := z.HandleRPC(ctx, &prototk.HandleRPCRequest{
+		Method:     "checkMerkleTree",
+		ParamsJson: `[]`,
+	})
+	assert.ErrorContains(t, err, "PD210132")
+
+	_, err = z.HandleRPC(ctx, &prototk.HandleRPCRequest{
+		Method:            "unknown",
+		ParamsJson:        `[]`,
+		StateQueryContext: &sqc,
+		ContractInfo:      contract,
+	})
+	assert.ErrorContains(t, err, "PD210132")
+
+	_, err = z.HandleRPC(ctx, &prototk.HandleRPCRequest{
+		Method:            "checkMerkleTree",
+		ParamsJson:        `["extra"]`,
+		StateQueryContext: &sqc,
+		ContractInfo:      contract,
+	})
+	assert.ErrorContains(t, err, "PD210133")
+
+	_, err = z.HandleRPC(ctx, &prototk.HandleRPCRequest{
+		Method:            "rebuildMerkleTree",
+		ParamsJson:        `{}`,
+		StateQueryContext: &sqc,
+		ContractInfo:      contract,
+	})
+	assert.ErrorContains(t, err, "PD210133")
+
+	_, err = z.HandleRPC(ctx, &prototk.HandleRPCRequest{
+		Method:            "importMerkleTree",
+		ParamsJson:        `["not a snapshot"]`,
+		StateQueryContext: &sqc,
+		ContractInfo:      contract,
+	})
+	assert.ErrorContains(t, err, "PD210133")
+
+	_, err = z.HandleRPC(ctx, &prototk.HandleRPCRequest{
+		Method:            "exportMerkleTree",
+		ParamsJson:        `[]`,
+		StateQueryContext: &sqc,
+		ContractInfo: &prototk.ContractInfo{
+			ContractAddress:    contract.ContractAddress,
+			ContractConfigJson: tktypes.JSONString(map[string]interface{}{"tokenName": "Zeto_Anon"}).Pretty(),
+		},
+	})
+	assert.ErrorContains(t, err, "PD210126")
+}
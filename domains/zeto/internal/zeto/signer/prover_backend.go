@@ -0,0 +1,205 @@
+/*
+ * Copyright © 2024 Kaleido, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package signer
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+	"sync/atomic"
+	"time"
+
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/iden3/go-rapidsnark/types"
+	"github.com/kaleido-io/paladin/config/pkg/confutil"
+	"github.com/kaleido-io/paladin/config/pkg/pldconf"
+	"github.com/kaleido-io/paladin/domains/zeto/internal/msgs"
+	"github.com/kaleido-io/paladin/domains/zeto/pkg/zetosigner/zetosignerapi"
+	"github.com/kaleido-io/paladin/toolkit/pkg/cache"
+)
+
+var defaultProverBackendConfig = zetosignerapi.ProverBackendConfig{
+	MaxConcurrent: confutil.P(10),
+	MaxQueued:     confutil.P(100),
+	Timeout:       confutil.P("2m"),
+	ProofCache: pldconf.CacheConfig{
+		Capacity: confutil.P(100),
+	},
+}
+
+// proverBackend calculates the witness from the assembled witness inputs for a circuit,
+// and generates the proof. The request key identifies the proving request across retries,
+// for backends that cache the proofs
+type proverBackend interface {
+	prove(ctx context.Context, circuitId, requestKey string, witnessInputs map[string]any) (*types.ZKProof, error)
+}
+
+// provingRequestKey identifies a proving request by the circuit, the transaction it was assembled for,
+// and a hash of the assembled request and the signing key. Signing the same assembly again gives the
+// same key, while a re-assembly of the transaction has new salts and so a new key.
+// The witness inputs cannot be used instead, as those of the encryption circuits contain a new
+// ephemeral key each time they are built
+func provingRequestKey(circuitId, transactionId string, payload []byte, publicKey string) string {
+	hash := sha256.Sum256(append([]byte(publicKey+":"), payload...))
+	return fmt.Sprintf("%s:%s:%s", circuitId, transactionId, hex.EncodeToString(hash[:]))
+}
+
+func newProverBackend(ctx context.Context, conf *zetosignerapi.ProverBackendConfig, local proverBackend) (proverBackend, error) {
+	switch conf.Type {
+	case "", zetosignerapi.PROVER_BACKEND_LOCAL:
+		// the local backend limits the concurrency with its own pool of WASM instances per circuit
+		return local, nil
+	case zetosignerapi.PROVER_BACKEND_GRPC:
+		backend, err := newGRPCProverBackend(ctx, conf)
+		if err != nil {
+			return nil, err
+		}
+		return newProverQueue(conf, backend), nil
+	case zetosignerapi.PROVER_BACKEND_STUB:
+		return newProverQueue(conf, &stubProverBackend{}), nil
+	default:
+		return nil, i18n.NewError(ctx, msgs.MsgErrorUnknownProverBackend, conf.Type)
+	}
+}
+
+// proverQueue limits the proving requests that are sent to a backend at once, and
+// how many can wait for their turn, and keeps recent proofs by request key so that
+// a retried signing of the same assembled transaction is not proved again
+type proverQueue struct {
+	backend   proverBackend
+	slots     chan struct{}
+	queued    atomic.Int64
+	maxQueued int64
+	timeout   time.Duration
+	proofs    cache.Cache[string, *types.ZKProof]
+}
+
+func newProverQueue(conf *zetosignerapi.ProverBackendConfig, backend proverBackend) *proverQueue {
+	return &proverQueue{
+		backend:   backend,
+		slots:     make(chan struct{}, confutil.IntMin(conf.MaxConcurrent, 1, *defaultProverBackendConfig.MaxConcurrent)),
+		maxQueued: int64(confutil.IntMin(conf.MaxQueued, 0, *defaultProverBackendConfig.MaxQueued)),
+		timeout:   confutil.DurationMin(conf.Timeout, 0, *defaultProverBackendConfig.Timeout),
+		proofs:    cache.NewCache[string, *types.ZKProof](&conf.ProofCache, &defaultProverBackendConfig.ProofCache),
+	}
+}
+
+func (q *proverQueue) prove(ctx context.Context, circuitId, requestKey string, witnessInputs map[string]any) (*types.ZKProof, error) {
+	if requestKey != "" {
+		if proof, ok := q.proofs.Get(requestKey); ok {
+			return proof, nil
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, q.timeout)
+	defer cancel()
+
+	if err := q.waitForSlot(ctx, circuitId); err != nil {
+		return nil, err
+	}
+	defer func() { <-q.slots }()
+
+	proof, err := q.backend.prove(ctx, circuitId, requestKey, witnessInputs)
+	if err != nil {
+		return nil, err
+	}
+	if requestKey != "" {
+		q.proofs.Set(requestKey, proof)
+	}
+	return proof, nil
+}
+
+func (q *proverQueue) waitForSlot(ctx context.Context, circuitId string) error {
+	select {
+	case q.slots <- struct{}{}:
+		return nil
+	default:
+	}
+	if q.queued.Add(1) > q.maxQueued {
+		q.queued.Add(-1)
+		return i18n.NewError(ctx, msgs.MsgErrorProverQueueFull, q.maxQueued)
+	}
+	defer q.queued.Add(-1)
+	select {
+	case q.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return i18n.NewError(ctx, msgs.MsgErrorProverTimeout, circuitId)
+	}
+}
+
+// stubProverBackend skips the witness calculation, and returns a proof made of zeros
+// with enough public signals for any of the circuits. The proofs will not verify, so
+// this is only for testing the flow of transactions without the circuits
+type stubProverBackend struct{}
+
+func (s *stubProverBackend) prove(ctx context.Context, circuitId, requestKey string, witnessInputs map[string]any) (*types.ZKProof, error) {
+	pubSignals := make([]string, 64)
+	for i := range pubSignals {
+		pubSignals[i] = "0"
+	}
+	return &types.ZKProof{
+		Proof: &types.ProofData{
+			A:        []string{"0", "0", "1"},
+			B:        [][]string{{"0", "0"}, {"0", "0"}, {"1", "0"}},
+			C:        []string{"0", "0", "1"},
+			Protocol: "groth16",
+		},
+		PubSignals: pubSignals,
+	}, nil
+}
+
+// marshalWitnessInputs writes the big integers in the witness inputs as decimal strings,
+// as they would lose precision as JSON numbers
+func marshalWitnessInputs(ctx context.Context, witnessInputs map[string]any) ([]byte, error) {
+	inputs := make(map[string]any, len(witnessInputs))
+	for name, v := range witnessInputs {
+		inputs[name] = stringifyWitnessInput(reflect.ValueOf(v))
+	}
+	inputsJSON, err := json.Marshal(inputs)
+	if err != nil {
+		return nil, i18n.NewError(ctx, msgs.MsgErrorMarshalWitnessInputs, err)
+	}
+	return inputsJSON, nil
+}
+
+func stringifyWitnessInput(v reflect.Value) any {
+	if !v.IsValid() {
+		return nil
+	}
+	if i, ok := v.Interface().(*big.Int); ok {
+		if i == nil {
+			return nil
+		}
+		return i.String()
+	}
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		items := make([]any, v.Len())
+		for i := range items {
+			items[i] = stringifyWitnessInput(v.Index(i))
+		}
+		return items
+	case reflect.Interface:
+		return stringifyWitnessInput(v.Elem())
+	default:
+		return v.Interface()
+	}
+}
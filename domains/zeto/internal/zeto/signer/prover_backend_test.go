@@ -0,0 +1,300 @@
+/*
+ * Copyright © 2024 Kaleido, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package signer
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync/atomic"
+	"testing"
+
+	"github.com/hyperledger-labs/zeto/go-sdk/pkg/crypto"
+	"github.com/iden3/go-rapidsnark/types"
+	"github.com/iden3/go-rapidsnark/witness/v2"
+	"github.com/kaleido-io/paladin/config/pkg/confutil"
+	"github.com/kaleido-io/paladin/domains/zeto/pkg/constants"
+	pb "github.com/kaleido-io/paladin/domains/zeto/pkg/proto"
+	"github.com/kaleido-io/paladin/domains/zeto/pkg/zetosigner/zetosignerapi"
+	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+type testProverBackend struct {
+	calls   atomic.Int32
+	started chan struct{}
+	release chan struct{}
+	err     error
+}
+
+func (tb *testProverBackend) prove(ctx context.Context, circuitId, requestKey string, witnessInputs map[string]any) (*types.ZKProof, error) {
+	tb.calls.Add(1)
+	if tb.started != nil {
+		tb.started <- struct{}{}
+	}
+	if tb.release != nil {
+		<-tb.release
+	}
+	if tb.err != nil {
+		return nil, tb.err
+	}
+	return &types.ZKProof{
+		Proof:      &types.ProofData{A: []string{circuitId}},
+		PubSignals: []string{fmt.Sprintf("%v", witnessInputs["value"])},
+	}, nil
+}
+
+// countingProverBackend counts the proving requests that reach a backend
+type countingProverBackend struct {
+	proverBackend
+	calls atomic.Int32
+}
+
+func (cb *countingProverBackend) prove(ctx context.Context, circuitId, requestKey string, witnessInputs map[string]any) (*types.ZKProof, error) {
+	cb.calls.Add(1)
+	return cb.proverBackend.prove(ctx, circuitId, requestKey, witnessInputs)
+}
+
+func newTestSignPayload(t *testing.T, circuitId string) (*TestUser, []byte) {
+	alice := NewTestKeypair()
+	bob := NewTestKeypair()
+	req := pb.ProvingRequest{
+		CircuitId:     circuitId,
+		TransactionId: tktypes.Bytes32(tktypes.RandBytes(32)).String(),
+		Common: &pb.ProvingRequestCommon{
+			InputCommitments: []string{crypto.NewSalt().Text(16), crypto.NewSalt().Text(16)},
+			InputValues:      []uint64{30, 40},
+			InputSalts:       []string{crypto.NewSalt().Text(16), crypto.NewSalt().Text(16)},
+			InputOwner:       "alice/key0",
+			OutputValues:     []uint64{32, 38},
+			OutputSalts:      []string{crypto.NewSalt().Text(16), crypto.NewSalt().Text(16)},
+			OutputOwners:     []string{EncodeBabyJubJubPublicKey(bob.PublicKey), EncodeBabyJubJubPublicKey(alice.PublicKey)},
+		},
+	}
+	payload, err := proto.Marshal(&req)
+	require.NoError(t, err)
+	return alice, payload
+}
+
+func TestNewProverBackend(t *testing.T) {
+	ctx := context.Background()
+	local := &testProverBackend{}
+
+	backend, err := newProverBackend(ctx, &zetosignerapi.ProverBackendConfig{}, local)
+	require.NoError(t, err)
+	assert.Equal(t, local, backend)
+
+	backend, err = newProverBackend(ctx, &zetosignerapi.ProverBackendConfig{Type: zetosignerapi.PROVER_BACKEND_LOCAL}, local)
+	require.NoError(t, err)
+	assert.Equal(t, local, backend)
+
+	backend, err = newProverBackend(ctx, &zetosignerapi.ProverBackendConfig{Type: zetosignerapi.PROVER_BACKEND_STUB}, local)
+	require.NoError(t, err)
+	queue := backend.(*proverQueue)
+	assert.IsType(t, &stubProverBackend{}, queue.backend)
+	assert.Equal(t, 10, cap(queue.slots))
+	assert.Equal(t, int64(100), queue.maxQueued)
+
+	_, err = newProverBackend(ctx, &zetosignerapi.ProverBackendConfig{Type: zetosignerapi.PROVER_BACKEND_GRPC}, local)
+	assert.EqualError(t, err, "PD210119: An address is required for the 'grpc' prover backend")
+
+	// plaintext connections to the prover service must be opted into
+	_, err = newProverBackend(ctx, &zetosignerapi.ProverBackendConfig{Type: zetosignerapi.PROVER_BACKEND_GRPC, Address: "localhost:9000"}, local)
+	assert.EqualError(t, err, "PD210134: TLS must be enabled for the 'grpc' prover backend, or 'insecure' set to allow unencrypted connections")
+
+	backend, err = newProverBackend(ctx, &zetosignerapi.ProverBackendConfig{Type: zetosignerapi.PROVER_BACKEND_GRPC, Address: "localhost:9000", Insecure: true}, local)
+	require.NoError(t, err)
+	assert.IsType(t, &grpcProverBackend{}, backend.(*proverQueue).backend)
+
+	_, err = newProverBackend(ctx, &zetosignerapi.ProverBackendConfig{Type: "wrong"}, local)
+	assert.EqualError(t, err, "PD210118: Prover backend type 'wrong' not recognized")
+}
+
+func TestSnarkProveStubBackend(t *testing.T) {
+	prover, err := newSnarkProver(&zetosignerapi.SnarkProverConfig{
+		Backend: zetosignerapi.ProverBackendConfig{Type: zetosignerapi.PROVER_BACKEND_STUB},
+	})
+	require.NoError(t, err)
+
+	alice, payload := newTestSignPayload(t, constants.CIRCUIT_ANON_ENC)
+	proofBytes, err := prover.Sign(context.Background(), zetosignerapi.AlgoDomainZetoSnarkBJJ("zeto"), zetosignerapi.PAYLOAD_DOMAIN_ZETO_SNARK, alice.PrivateKey[:], payload)
+	require.NoError(t, err)
+
+	var res pb.ProvingResponse
+	require.NoError(t, proto.Unmarshal(proofBytes, &res))
+	assert.Equal(t, []string{"0", "0", "1"}, res.Proof.A)
+	assert.Len(t, res.Proof.B, 3)
+	assert.Equal(t, "0,0", res.PublicInputs["ecdhPublicKey"])
+	assert.Equal(t, "0", res.PublicInputs["encryptionNonce"])
+}
+
+func TestSnarkProveStubBackendRetry(t *testing.T) {
+	prover, err := newSnarkProver(&zetosignerapi.SnarkProverConfig{
+		Backend: zetosignerapi.ProverBackendConfig{Type: zetosignerapi.PROVER_BACKEND_STUB},
+	})
+	require.NoError(t, err)
+	queue := prover.backend.(*proverQueue)
+	backend := &countingProverBackend{proverBackend: queue.backend}
+	queue.backend = backend
+
+	// the witness inputs of the encryption circuits have a new ephemeral key each time,
+	// but a retried signing of the same assembled request still reuses the proof
+	alice, payload := newTestSignPayload(t, constants.CIRCUIT_ANON_ENC)
+	algo := zetosignerapi.AlgoDomainZetoSnarkBJJ("zeto")
+	proof1, err := prover.Sign(context.Background(), algo, zetosignerapi.PAYLOAD_DOMAIN_ZETO_SNARK, alice.PrivateKey[:], payload)
+	require.NoError(t, err)
+	proof2, err := prover.Sign(context.Background(), algo, zetosignerapi.PAYLOAD_DOMAIN_ZETO_SNARK, alice.PrivateKey[:], payload)
+	require.NoError(t, err)
+	assert.Equal(t, proof1, proof2)
+	assert.Equal(t, int32(1), backend.calls.Load())
+
+	// a re-assembly of the transaction has new salts, so is proved again
+	_, payload = newTestSignPayload(t, constants.CIRCUIT_ANON_ENC)
+	_, err = prover.Sign(context.Background(), algo, zetosignerapi.PAYLOAD_DOMAIN_ZETO_SNARK, alice.PrivateKey[:], payload)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), backend.calls.Load())
+}
+
+func TestProvingRequestKey(t *testing.T) {
+	key := provingRequestKey("circuit1", "tx1", []byte("request"), "key1")
+	assert.Regexp(t, "^circuit1:tx1:[0-9a-f]{64}$", key)
+	assert.Equal(t, key, provingRequestKey("circuit1", "tx1", []byte("request"), "key1"))
+	assert.NotEqual(t, key, provingRequestKey("circuit2", "tx1", []byte("request"), "key1"))
+	assert.NotEqual(t, key, provingRequestKey("circuit1", "tx2", []byte("request"), "key1"))
+	assert.NotEqual(t, key, provingRequestKey("circuit1", "tx1", []byte("request2"), "key1"))
+	assert.NotEqual(t, key, provingRequestKey("circuit1", "tx1", []byte("request"), "key2"))
+}
+
+func TestProverQueueCache(t *testing.T) {
+	ctx := context.Background()
+	backend := &testProverBackend{}
+	queue := newProverQueue(&zetosignerapi.ProverBackendConfig{}, backend)
+
+	proof, err := queue.prove(ctx, "circuit1", "key1", map[string]any{"value": big.NewInt(1)})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1"}, proof.PubSignals)
+
+	// the inputs of a retry are not compared, only the request key
+	proof, err = queue.prove(ctx, "circuit1", "key1", map[string]any{"value": big.NewInt(2)})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1"}, proof.PubSignals)
+	assert.Equal(t, int32(1), backend.calls.Load())
+
+	_, err = queue.prove(ctx, "circuit1", "key2", map[string]any{"value": big.NewInt(1)})
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), backend.calls.Load())
+
+	// requests without a key are always proved
+	_, err = queue.prove(ctx, "circuit1", "", map[string]any{"value": big.NewInt(1)})
+	require.NoError(t, err)
+	_, err = queue.prove(ctx, "circuit1", "", map[string]any{"value": big.NewInt(1)})
+	require.NoError(t, err)
+	assert.Equal(t, int32(4), backend.calls.Load())
+
+	// failures are not cached
+	backend.err = fmt.Errorf("pop")
+	_, err = queue.prove(ctx, "circuit2", "key3", map[string]any{})
+	assert.EqualError(t, err, "pop")
+	_, err = queue.prove(ctx, "circuit2", "key3", map[string]any{})
+	assert.EqualError(t, err, "pop")
+	assert.Equal(t, int32(6), backend.calls.Load())
+	assert.Equal(t, int64(0), queue.queued.Load())
+	assert.Empty(t, queue.slots)
+}
+
+func TestProverQueueFull(t *testing.T) {
+	ctx := context.Background()
+	backend := &testProverBackend{
+		started: make(chan struct{}, 1),
+		release: make(chan struct{}),
+	}
+	queue := newProverQueue(&zetosignerapi.ProverBackendConfig{
+		MaxConcurrent: confutil.P(1),
+		MaxQueued:     confutil.P(0),
+	}, backend)
+
+	done := make(chan error)
+	go func() {
+		_, err := queue.prove(ctx, "circuit1", "", map[string]any{"value": big.NewInt(1)})
+		done <- err
+	}()
+	<-backend.started
+
+	_, err := queue.prove(ctx, "circuit1", "", map[string]any{"value": big.NewInt(2)})
+	assert.EqualError(t, err, "PD210121: The prover queue is full, with 0 proving requests waiting")
+
+	close(backend.release)
+	require.NoError(t, <-done)
+	assert.Equal(t, int64(0), queue.queued.Load())
+}
+
+func TestProverQueueTimeout(t *testing.T) {
+	ctx := context.Background()
+	backend := &testProverBackend{
+		started: make(chan struct{}, 1),
+		release: make(chan struct{}),
+	}
+	queue := newProverQueue(&zetosignerapi.ProverBackendConfig{
+		MaxConcurrent: confutil.P(1),
+		Timeout:       confutil.P("10ms"),
+	}, backend)
+
+	done := make(chan error)
+	go func() {
+		_, err := queue.prove(ctx, "circuit1", "", map[string]any{"value": big.NewInt(1)})
+		done <- err
+	}()
+	<-backend.started
+
+	_, err := queue.prove(ctx, "circuit1", "", map[string]any{"value": big.NewInt(2)})
+	assert.EqualError(t, err, "PD210122: Timed out waiting for a prover for circuit 'circuit1'")
+	assert.Equal(t, int64(0), queue.queued.Load())
+
+	close(backend.release)
+	require.NoError(t, <-done)
+}
+
+func TestMarshalWitnessInputs(t *testing.T) {
+	bigValue, ok := new(big.Int).SetString("21888242871839275222246405745257275088548364400416034343698204186575808495616", 10)
+	require.True(t, ok)
+	witnessInputs := map[string]any{
+		"root":        bigValue,
+		"values":      []*big.Int{big.NewInt(1), big.NewInt(2)},
+		"merkleProof": [][]*big.Int{{big.NewInt(3)}, {big.NewInt(4), nil}},
+		"nested":      []any{big.NewInt(5)},
+		"empty":       nil,
+	}
+	inputsJSON, err := marshalWitnessInputs(context.Background(), witnessInputs)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"root": "21888242871839275222246405745257275088548364400416034343698204186575808495616",
+		"values": ["1", "2"],
+		"merkleProof": [["3"], ["4", null]],
+		"nested": ["5"],
+		"empty": null
+	}`, string(inputsJSON))
+
+	delete(witnessInputs, "empty")
+	witnessInputs["merkleProof"] = [][]*big.Int{{big.NewInt(3)}, {big.NewInt(4)}}
+	inputsJSON, err = marshalWitnessInputs(context.Background(), witnessInputs)
+	require.NoError(t, err)
+	parsed, err := witness.ParseInputs(inputsJSON)
+	require.NoError(t, err)
+	assert.Equal(t, 0, bigValue.Cmp(parsed["root"].(*big.Int)))
+	assert.Equal(t, "[[3] [4]]", fmt.Sprintf("%v", parsed["merkleProof"]))
+}
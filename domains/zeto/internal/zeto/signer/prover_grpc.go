@@ -0,0 +1,132 @@
+/*
+ * Copyright © 2024 Kaleido, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package signer
+
+import (
+	"context"
+
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/iden3/go-rapidsnark/types"
+	"github.com/iden3/go-rapidsnark/witness/v2"
+	"github.com/kaleido-io/paladin/domains/zeto/internal/msgs"
+	pb "github.com/kaleido-io/paladin/domains/zeto/pkg/proto"
+	"github.com/kaleido-io/paladin/domains/zeto/pkg/zetosigner/zetosignerapi"
+	"github.com/kaleido-io/paladin/toolkit/pkg/tlsconf"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// grpcProverBackend sends the witness inputs to a prover service in another process,
+// which runs the "local" backend
+type grpcProverBackend struct {
+	client pb.ZetoProverClient
+}
+
+func newGRPCProverBackend(ctx context.Context, conf *zetosignerapi.ProverBackendConfig) (*grpcProverBackend, error) {
+	if conf.Address == "" {
+		return nil, i18n.NewError(ctx, msgs.MsgErrorMissingProverAddress, zetosignerapi.PROVER_BACKEND_GRPC)
+	}
+	// the witness inputs contain private keys, so plaintext connections must be chosen explicitly
+	if !conf.TLS.Enabled && !conf.Insecure {
+		return nil, i18n.NewError(ctx, msgs.MsgErrorProverInsecure, zetosignerapi.PROVER_BACKEND_GRPC)
+	}
+	tlsConfig, err := tlsconf.BuildTLSConfig(ctx, &conf.TLS, tlsconf.ClientType)
+	if err != nil {
+		return nil, err
+	}
+	creds := insecure.NewCredentials()
+	if tlsConfig != nil {
+		creds = credentials.NewTLS(tlsConfig)
+	}
+	// the connection is established on the first request, and re-established as required
+	conn, err := grpc.NewClient(conf.Address, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return nil, i18n.NewError(ctx, msgs.MsgErrorProverConnect, conf.Address, err)
+	}
+	return &grpcProverBackend{
+		client: pb.NewZetoProverClient(conn),
+	}, nil
+}
+
+func (g *grpcProverBackend) prove(ctx context.Context, circuitId, requestKey string, witnessInputs map[string]any) (*types.ZKProof, error) {
+	inputsJSON, err := marshalWitnessInputs(ctx, witnessInputs)
+	if err != nil {
+		return nil, err
+	}
+	res, err := g.client.Prove(ctx, &pb.ProveRequest{
+		CircuitId:         circuitId,
+		WitnessInputsJson: string(inputsJSON),
+	})
+	if err != nil {
+		return nil, i18n.NewError(ctx, msgs.MsgErrorRemoteProver, circuitId, err)
+	}
+	proof := &types.ZKProof{
+		Proof: &types.ProofData{
+			A:        res.Proof.GetA(),
+			C:        res.Proof.GetC(),
+			Protocol: res.Proof.GetProtocol(),
+		},
+		PubSignals: res.PubSignals,
+	}
+	for _, b := range res.Proof.GetB() {
+		proof.Proof.B = append(proof.Proof.B, b.Items)
+	}
+	return proof, nil
+}
+
+// proverServer is the prover service, which generates the proofs for the "grpc" backend
+type proverServer struct {
+	pb.UnimplementedZetoProverServer
+	backend proverBackend
+}
+
+func NewProverServer(conf *zetosignerapi.SnarkProverConfig) (pb.ZetoProverServer, error) {
+	// the service always proves locally, whatever backend is configured for the signer
+	localConf := *conf
+	localConf.Backend = zetosignerapi.ProverBackendConfig{Type: zetosignerapi.PROVER_BACKEND_LOCAL}
+	sp, err := newSnarkProver(&localConf)
+	if err != nil {
+		return nil, err
+	}
+	return &proverServer{backend: sp}, nil
+}
+
+func (s *proverServer) Prove(ctx context.Context, req *pb.ProveRequest) (*pb.ProveResponse, error) {
+	if req.CircuitId == "" {
+		return nil, i18n.NewError(ctx, msgs.MsgErrorMissingCircuitID)
+	}
+	witnessInputs, err := witness.ParseInputs([]byte(req.WitnessInputsJson))
+	if err != nil {
+		return nil, i18n.NewError(ctx, msgs.MsgErrorParseWitnessInputs, err)
+	}
+	proof, err := s.backend.prove(ctx, req.CircuitId, "", witnessInputs)
+	if err != nil {
+		return nil, err
+	}
+	snark := &pb.SnarkProof{
+		A:        proof.Proof.A,
+		C:        proof.Proof.C,
+		Protocol: proof.Proof.Protocol,
+	}
+	for _, b := range proof.Proof.B {
+		snark.B = append(snark.B, &pb.B_Item{Items: b})
+	}
+	return &pb.ProveResponse{
+		Proof:      snark,
+		PubSignals: proof.PubSignals,
+	}, nil
+}
@@ -0,0 +1,181 @@
+/*
+ * Copyright © 2024 Kaleido, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package signer
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"net"
+	"testing"
+
+	"github.com/iden3/go-rapidsnark/types"
+	"github.com/iden3/go-rapidsnark/witness/v2"
+	"github.com/kaleido-io/paladin/config/pkg/pldconf"
+	"github.com/kaleido-io/paladin/domains/zeto/pkg/constants"
+	pb "github.com/kaleido-io/paladin/domains/zeto/pkg/proto"
+	"github.com/kaleido-io/paladin/domains/zeto/pkg/zetosigner/zetosignerapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+type recordingWitnessCalculator struct {
+	testWitnessCalculator
+	inputs map[string]interface{}
+}
+
+func (r *recordingWitnessCalculator) CalculateWTNSBin(inputs map[string]interface{}, _ bool) ([]byte, error) {
+	r.inputs = inputs
+	return []byte{}, nil
+}
+
+func newTestProverService(t *testing.T) (*snarkProver, string) {
+	server, err := NewProverServer(&zetosignerapi.SnarkProverConfig{
+		CircuitsDir:    "test",
+		ProvingKeysDir: "test",
+		// ignored by the prover service
+		Backend: zetosignerapi.ProverBackendConfig{Type: zetosignerapi.PROVER_BACKEND_GRPC},
+	})
+	require.NoError(t, err)
+	local := server.(*proverServer).backend.(*snarkProver)
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	s := grpc.NewServer()
+	pb.RegisterZetoProverServer(s, server)
+	go func() { _ = s.Serve(l) }()
+	t.Cleanup(s.Stop)
+
+	return local, l.Addr().String()
+}
+
+func TestGRPCProverBackend(t *testing.T) {
+	local, address := newTestProverService(t)
+	calculator := &recordingWitnessCalculator{}
+	local.circuitLoader = func(ctx context.Context, circuitID string, config *zetosignerapi.SnarkProverConfig) (witness.Calculator, []byte, error) {
+		assert.Equal(t, constants.CIRCUIT_ANON_NULLIFIER, circuitID)
+		return calculator, []byte("proving key"), nil
+	}
+	local.proofGenerator = func(ctx context.Context, witness []byte, provingKey []byte) (*types.ZKProof, error) {
+		return &types.ZKProof{
+			Proof: &types.ProofData{
+				A:        []string{"a1", "a2", "a3"},
+				B:        [][]string{{"b1.1", "b1.2"}, {"b2.1", "b2.2"}, {"b3.1", "b3.2"}},
+				C:        []string{"c1", "c2", "c3"},
+				Protocol: "groth16",
+			},
+			PubSignals: []string{"n1", "n2", "root"},
+		}, nil
+	}
+
+	prover, err := newSnarkProver(&zetosignerapi.SnarkProverConfig{
+		Backend: zetosignerapi.ProverBackendConfig{
+			Type:     zetosignerapi.PROVER_BACKEND_GRPC,
+			Address:  address,
+			Insecure: true,
+		},
+	})
+	require.NoError(t, err)
+
+	witnessInputs := map[string]any{
+		"root":    big.NewInt(12345),
+		"enabled": []*big.Int{big.NewInt(1), big.NewInt(0)},
+	}
+	proof, err := prover.backend.prove(context.Background(), constants.CIRCUIT_ANON_NULLIFIER, "", witnessInputs)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a1", "a2", "a3"}, proof.Proof.A)
+	assert.Equal(t, [][]string{{"b1.1", "b1.2"}, {"b2.1", "b2.2"}, {"b3.1", "b3.2"}}, proof.Proof.B)
+	assert.Equal(t, []string{"c1", "c2", "c3"}, proof.Proof.C)
+	assert.Equal(t, "groth16", proof.Proof.Protocol)
+	assert.Equal(t, []string{"n1", "n2", "root"}, proof.PubSignals)
+
+	// the witness inputs are rebuilt in the prover service
+	assert.Equal(t, "12345", calculator.inputs["root"].(*big.Int).String())
+	assert.Equal(t, "[1 0]", fmt.Sprintf("%v", calculator.inputs["enabled"]))
+}
+
+func TestGRPCProverBackendSign(t *testing.T) {
+	local, address := newTestProverService(t)
+	local.circuitLoader = func(ctx context.Context, circuitID string, config *zetosignerapi.SnarkProverConfig) (witness.Calculator, []byte, error) {
+		return &testWitnessCalculator{}, []byte("proving key"), nil
+	}
+	local.proofGenerator = func(ctx context.Context, witness []byte, provingKey []byte) (*types.ZKProof, error) {
+		return &types.ZKProof{
+			Proof: &types.ProofData{
+				A: []string{"a"},
+				B: [][]string{{"b1.1", "b1.2"}, {"b2.1", "b2.2"}},
+				C: []string{"c"},
+			},
+		}, nil
+	}
+
+	prover, err := newSnarkProver(&zetosignerapi.SnarkProverConfig{
+		Backend: zetosignerapi.ProverBackendConfig{
+			Type:     zetosignerapi.PROVER_BACKEND_GRPC,
+			Address:  address,
+			Insecure: true,
+		},
+	})
+	require.NoError(t, err)
+
+	alice, payload := newTestSignPayload(t, constants.CIRCUIT_ANON)
+	proofBytes, err := prover.Sign(context.Background(), zetosignerapi.AlgoDomainZetoSnarkBJJ("zeto"), zetosignerapi.PAYLOAD_DOMAIN_ZETO_SNARK, alice.PrivateKey[:], payload)
+	require.NoError(t, err)
+	var res pb.ProvingResponse
+	require.NoError(t, proto.Unmarshal(proofBytes, &res))
+	assert.Equal(t, []string{"a"}, res.Proof.A)
+	assert.Len(t, res.Proof.B, 2)
+}
+
+func TestGRPCProverBackendErrors(t *testing.T) {
+	ctx := context.Background()
+	local, address := newTestProverService(t)
+	local.circuitLoader = func(ctx context.Context, circuitID string, config *zetosignerapi.SnarkProverConfig) (witness.Calculator, []byte, error) {
+		return nil, nil, assert.AnError
+	}
+
+	backend, err := newGRPCProverBackend(ctx, &zetosignerapi.ProverBackendConfig{Address: address, Insecure: true})
+	require.NoError(t, err)
+	_, err = backend.prove(ctx, constants.CIRCUIT_ANON, "", map[string]any{})
+	assert.ErrorContains(t, err, "PD210123: The prover service failed to generate the proof for circuit 'anon'")
+	assert.ErrorContains(t, err, assert.AnError.Error())
+
+	_, err = backend.prove(ctx, "", "", map[string]any{})
+	assert.ErrorContains(t, err, "PD210091")
+
+	_, err = backend.prove(ctx, constants.CIRCUIT_ANON, "", map[string]any{"bad": func() {}})
+	assert.ErrorContains(t, err, "PD210124")
+
+	_, err = backend.client.Prove(ctx, &pb.ProveRequest{CircuitId: constants.CIRCUIT_ANON, WitnessInputsJson: "!json"})
+	assert.ErrorContains(t, err, "PD210125")
+
+	_, err = newGRPCProverBackend(ctx, &zetosignerapi.ProverBackendConfig{
+		Address: address,
+		TLS: pldconf.TLSConfig{
+			Enabled: true,
+			CAFile:  t.TempDir() + "/missing.pem",
+		},
+	})
+	assert.Error(t, err)
+
+	_, err = newGRPCProverBackend(ctx, &zetosignerapi.ProverBackendConfig{Address: "bad:///\x00", Insecure: true})
+	assert.ErrorContains(t, err, "PD210120")
+
+	_, err = NewProverServer(&zetosignerapi.SnarkProverConfig{})
+	require.NoError(t, err)
+}
@@ -54,6 +54,7 @@ type snarkProver struct {
 	circuitsWorkerIndexChan       map[string]chan *int
 	circuitLoader                 func(ctx context.Context, circuitID string, config *zetosignerapi.SnarkProverConfig) (witness.Calculator, []byte, error)
 	proofGenerator                func(ctx context.Context, witness []byte, provingKey []byte) (*types.ZKProof, error)
+	backend                       proverBackend
 }
 
 func NewSnarkProver(conf *zetosignerapi.SnarkProverConfig) (signerapi.InMemorySigner, error) {
@@ -64,7 +65,7 @@ func newSnarkProver(conf *zetosignerapi.SnarkProverConfig) (*snarkProver, error)
 	cacheConfig := pldconf.CacheConfig{
 		Capacity: confutil.P(50),
 	}
-	return &snarkProver{
+	sp := &snarkProver{
 		zkpProverConfig:         conf,
 		circuitsCache:           cache.NewCache[string, witness.Calculator](&cacheConfig, &cacheConfig),
 		provingKeysCache:        cache.NewCache[string, []byte](&cacheConfig, &cacheConfig),
@@ -72,7 +73,13 @@ func newSnarkProver(conf *zetosignerapi.SnarkProverConfig) (*snarkProver, error)
 		proofGenerator:          generateProof,
 		workerPerCircuit:        confutil.Int(conf.MaxProverPerCircuit, *defaultSnarkProverConfig.MaxProverPerCircuit),
 		circuitsWorkerIndexChan: make(map[string]chan *int),
-	}, nil
+	}
+	backend, err := newProverBackend(context.Background(), &conf.Backend, sp)
+	if err != nil {
+		return nil, err
+	}
+	sp.backend = backend
+	return sp, nil
 }
 
 func (sp *snarkProver) GetVerifier(ctx context.Context, algorithm, verifierType string, privateKey []byte) (string, error) {
@@ -121,7 +128,28 @@ func (sp *snarkProver) Sign(ctx context.Context, algorithm, payloadType string,
 	}
 
 	circuitId := getCircuitId(inputs)
+	witnessInputs, err := buildWitnessInputs(ctx, circuitId, inputs.Common, extras, keyEntry)
+	if err != nil {
+		return nil, err
+	}
+
+	requestKey := provingRequestKey(circuitId, inputs.TransactionId, payload, EncodeBabyJubJubPublicKey(keyEntry.PublicKey))
+	proof, err := sp.backend.prove(ctx, circuitId, requestKey, witnessInputs)
+	if err != nil {
+		return nil, err
+	}
+
+	proofBytes, err := serializeProofResponse(circuitId, proof)
+	if err != nil {
+		return nil, err
+	}
+
+	return proofBytes, nil
+}
 
+// prove is the "local" backend, which calculates the witness and generates the proof
+// in this process, with a pool of WASM instances for each circuit
+func (sp *snarkProver) prove(ctx context.Context, circuitId, requestKey string, witnessInputs map[string]any) (*types.ZKProof, error) {
 	// obtain a slot for the proof generation for this specific circuit
 	// check whether this is a controlling channel
 	sp.circuitsWorkerIndexChanRWLock.RLock()
@@ -169,6 +197,7 @@ func (sp *snarkProver) Sign(ctx context.Context, algorithm, payloadType string,
 			// is served per WASM instance at any given time
 			c, p, err := sp.circuitLoader(ctx, circuitId, sp.zkpProverConfig)
 			if err != nil {
+				sp.proverCacheRWLock.Unlock()
 				return nil, err
 			}
 			sp.circuitsCache.Set(workerID, c)
@@ -178,22 +207,12 @@ func (sp *snarkProver) Sign(ctx context.Context, algorithm, payloadType string,
 		}
 		sp.proverCacheRWLock.Unlock()
 	}
-	wtns, err := calculateWitness(ctx, circuitId, inputs.Common, extras, keyEntry, circuit)
-	if err != nil {
-		return nil, err
-	}
-
-	proof, err := sp.proofGenerator(ctx, wtns, provingKey)
+	wtns, err := calculateWitness(ctx, circuit, witnessInputs)
 	if err != nil {
 		return nil, err
 	}
 
-	proofBytes, err := serializeProofResponse(circuitId, proof)
-	if err != nil {
-		return nil, err
-	}
-
-	return proofBytes, nil
+	return sp.proofGenerator(ctx, wtns, provingKey)
 }
 
 func getCircuitId(inputs *pb.ProvingRequest) string {
@@ -257,7 +276,7 @@ func serializeProofResponse(circuitId string, proof *types.ZKProof) ([]byte, err
 	return proto.Marshal(&res)
 }
 
-func calculateWitness(ctx context.Context, circuitId string, commonInputs *pb.ProvingRequestCommon, extras interface{}, keyEntry *core.KeyEntry, circuit witness.Calculator) ([]byte, error) {
+func buildWitnessInputs(ctx context.Context, circuitId string, commonInputs *pb.ProvingRequestCommon, extras interface{}, keyEntry *core.KeyEntry) (map[string]any, error) {
 	inputs, err := buildCircuitInputs(ctx, commonInputs)
 	if err != nil {
 		return nil, err
@@ -289,7 +308,10 @@ func calculateWitness(ctx context.Context, circuitId string, commonInputs *pb.Pr
 	case constants.CIRCUIT_LOCK, constants.CIRCUIT_LOCK_BATCH:
 		witnessInputs = assembleInputs_lock(inputs, keyEntry)
 	}
+	return witnessInputs, nil
+}
 
+func calculateWitness(ctx context.Context, circuit witness.Calculator, witnessInputs map[string]any) ([]byte, error) {
 	wtns, err := circuit.CalculateWTNSBin(witnessInputs, true)
 	if err != nil {
 		return nil, i18n.NewError(ctx, msgs.MsgErrorCalcWitness, err)
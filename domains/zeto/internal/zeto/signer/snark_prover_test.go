@@ -272,7 +272,7 @@ func TestSnarkProveErrorInputs(t *testing.T) {
 	payload, err := proto.Marshal(&req)
 	require.NoError(t, err)
 	_, err = prover.Sign(context.Background(), zetosignerapi.AlgoDomainZetoSnarkBJJ("zeto"), zetosignerapi.PAYLOAD_DOMAIN_ZETO_SNARK, alice.PrivateKey[:], payload)
-	assert.ErrorContains(t, err, "anon.wasm: no such file or directory")
+	assert.ErrorContains(t, err, "PD210084: Failed to parse input commitment")
 }
 
 func TestSnarkProveErrorLoadcircuits(t *testing.T) {
@@ -576,7 +576,7 @@ func TestCalculateWitness(t *testing.T) {
 		OutputOwners:     []string{"7cdd539f3ed6c283494f47d8481f84308a6d7043087fb6711c9f1df04e2b8025", "7cdd539f3ed6c283494f47d8481f84308a6d7043087fb6711c9f1df04e2b8025"},
 	}
 	ctx := context.Background()
-	_, err := calculateWitness(ctx, constants.CIRCUIT_ANON_ENC, inputs, extras1, nil, nil)
+	_, err := buildWitnessInputs(ctx, constants.CIRCUIT_ANON_ENC, inputs, extras1, nil)
 	assert.EqualError(t, err, "PD210099: failed to assemble private inputs for witness calculation. PD210077: Failed to parse encryption nonce")
 
 	extras2 := &pb.ProvingRequestExtras_Nullifiers{
@@ -596,7 +596,7 @@ func TestCalculateWitness(t *testing.T) {
 	keyEntry := &core.KeyEntry{
 		PrivateKeyForZkp: privKey,
 	}
-	_, err = calculateWitness(ctx, constants.CIRCUIT_ANON_NULLIFIER, inputs, extras2, keyEntry, nil)
+	_, err = buildWitnessInputs(ctx, constants.CIRCUIT_ANON_NULLIFIER, inputs, extras2, keyEntry)
 	assert.EqualError(t, err, "PD210099: failed to assemble private inputs for witness calculation. PD210079: Failed to calculate nullifier. inputs values not inside Finite Field")
 
 	inputs = &pb.ProvingRequestCommon{
@@ -605,7 +605,9 @@ func TestCalculateWitness(t *testing.T) {
 		OutputOwners: []string{"7cdd539f3ed6c283494f47d8481f84308a6d7043087fb6711c9f1df04e2b8025", "7cdd539f3ed6c283494f47d8481f84308a6d7043087fb6711c9f1df04e2b8025"},
 	}
 	circuit, _ := loadTestCircuit(t)
-	_, err = calculateWitness(ctx, constants.CIRCUIT_DEPOSIT, inputs, nil, keyEntry, circuit)
+	witnessInputs, err := buildWitnessInputs(ctx, constants.CIRCUIT_DEPOSIT, inputs, nil, keyEntry)
+	require.NoError(t, err)
+	_, err = calculateWitness(ctx, circuit, witnessInputs)
 	assert.ErrorContains(t, err, "PD210100: failed to calculate the witness")
 
 	inputs = &pb.ProvingRequestCommon{
@@ -617,9 +619,11 @@ func TestCalculateWitness(t *testing.T) {
 		OutputSalts:      []string{"1234567890123456789012345678901234567890123456789012345678901234", "1234567890123456789012345678901234567890123456789012345678901234"},
 		OutputOwners:     []string{"7cdd539f3ed6c283494f47d8481f84308a6d7043087fb6711c9f1df04e2b8025", "7cdd539f3ed6c283494f47d8481f84308a6d7043087fb6711c9f1df04e2b8025"},
 	}
-	_, err = calculateWitness(ctx, constants.CIRCUIT_WITHDRAW, inputs, nil, keyEntry, circuit)
+	witnessInputs, err = buildWitnessInputs(ctx, constants.CIRCUIT_WITHDRAW, inputs, nil, keyEntry)
+	require.NoError(t, err)
+	_, err = calculateWitness(ctx, circuit, witnessInputs)
 	assert.ErrorContains(t, err, "PD210100: failed to calculate the witness")
 
-	_, err = calculateWitness(ctx, constants.CIRCUIT_WITHDRAW_NULLIFIER, inputs, extras2, keyEntry, circuit)
+	_, err = buildWitnessInputs(ctx, constants.CIRCUIT_WITHDRAW_NULLIFIER, inputs, extras2, keyEntry)
 	assert.EqualError(t, err, "PD210099: failed to assemble private inputs for witness calculation. PD210079: Failed to calculate nullifier. inputs values not inside Finite Field")
 }
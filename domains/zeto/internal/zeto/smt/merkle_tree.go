@@ -16,8 +16,13 @@
 package smt
 
 import (
+	"context"
+	"errors"
+
 	"github.com/hyperledger-labs/zeto/go-sdk/pkg/sparse-merkle-tree/core"
 	"github.com/hyperledger-labs/zeto/go-sdk/pkg/sparse-merkle-tree/smt"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/kaleido-io/paladin/domains/zeto/internal/msgs"
 	"github.com/kaleido-io/paladin/domains/zeto/pkg/proto"
 	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
 )
@@ -44,3 +49,33 @@ func NewSmt(storage StatesStorage) (core.SparseMerkleTree, error) {
 func MerkleTreeName(tokenName string, domainInstanceContract *tktypes.EthAddress) string {
 	return "smt_" + tokenName + "_" + domainInstanceContract.String()
 }
+
+// ListLeaves walks the tree from its root, and returns the indexes of the leaves from left to right,
+// which are the hashes of the UTXOs that were added to the tree.
+// Nodes that are referenced by the tree, but cannot be found in the storage, are counted rather than
+// failing the walk, so that a damaged tree can be reported on.
+func ListLeaves(ctx context.Context, smtName string, tree core.SparseMerkleTree) (leaves []tktypes.HexUint256, missingNodes int, err error) {
+	leaves = []tktypes.HexUint256{}
+	var walk func(ref core.NodeRef) error
+	walk = func(ref core.NodeRef) error {
+		n, err := tree.GetNode(ref)
+		if errors.Is(err, core.ErrNotFound) {
+			missingNodes++
+			return nil
+		} else if err != nil {
+			return i18n.NewError(ctx, msgs.MsgErrorReadMerkleTreeNode, ref.Hex(), smtName, err)
+		}
+		switch n.Type() {
+		case core.NodeTypeLeaf:
+			leaves = append(leaves, tktypes.HexUint256(*n.Index().BigInt()))
+		case core.NodeTypeBranch:
+			if err := walk(n.LeftChild()); err != nil {
+				return err
+			}
+			return walk(n.RightChild())
+		}
+		return nil
+	}
+	err = walk(tree.Root())
+	return leaves, missingNodes, err
+}
@@ -16,10 +16,16 @@
 package smt
 
 import (
+	"context"
+	"errors"
+	"math/big"
 	"testing"
 
+	"github.com/hyperledger-labs/zeto/go-sdk/pkg/sparse-merkle-tree/node"
+	"github.com/kaleido-io/paladin/toolkit/pkg/prototk"
 	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestPackageInit(t *testing.T) {
@@ -32,3 +38,49 @@ func TestMerkleTreeName(t *testing.T) {
 	address, _ := tktypes.ParseEthAddress("0xe12c416382988005ace9b2e2f9a8a904d8be961c")
 	assert.Equal(t, "smt_test1_0xe12c416382988005ace9b2e2f9a8a904d8be961c", MerkleTreeName("test1", address))
 }
+
+func TestListLeaves(t *testing.T) {
+	ctx := context.Background()
+	storage := NewEmptyStatesStorage(&testDomainCallbacks{
+		returnFunc: func() (*prototk.FindAvailableStatesResponse, error) {
+			return nil, errors.New("pop")
+		},
+	}, "test", "context1", "root-schema", "node-schema")
+	tree, err := NewSmt(storage)
+	require.NoError(t, err)
+
+	leaves, missing, err := ListLeaves(ctx, "test", tree)
+	require.NoError(t, err)
+	assert.Empty(t, leaves)
+	assert.Zero(t, missing)
+
+	for _, i := range []int64{3, 1, 2} {
+		idx, err := node.NewNodeIndexFromBigInt(big.NewInt(i))
+		require.NoError(t, err)
+		leaf, err := node.NewLeafNode(node.NewIndexOnly(idx))
+		require.NoError(t, err)
+		require.NoError(t, tree.AddLeaf(leaf))
+	}
+	leaves, missing, err = ListLeaves(ctx, "test", tree)
+	require.NoError(t, err)
+	assert.Len(t, leaves, 3)
+	assert.Zero(t, missing)
+
+	// The nodes are only in the storage that built the tree
+	called := 0
+	storage = NewStatesStorage(&testDomainCallbacks{
+		returnFunc: func() (*prototk.FindAvailableStatesResponse, error) {
+			called++
+			if called > 1 {
+				return nil, errors.New("pop")
+			}
+			return &prototk.FindAvailableStatesResponse{
+				States: []*prototk.StoredState{{DataJson: `{"smtName":"test","rootIndex":"0x` + tree.Root().Hex() + `"}`}},
+			}, nil
+		},
+	}, "test", "context1", "root-schema", "node-schema")
+	loaded, err := NewSmt(storage)
+	require.NoError(t, err)
+	_, _, err = ListLeaves(ctx, "test", loaded)
+	assert.ErrorContains(t, err, "PD210128")
+}
@@ -48,6 +48,7 @@ type statesStorage struct {
 	pendingNodesTx    *nodesTx
 	rootNode          *smtRootNode
 	committedNewNodes map[core.NodeRef]*smtNode
+	ignoreStoredRoot  bool
 }
 
 // this corresponds to the new nodes resulted from the execution of
@@ -86,6 +87,15 @@ func NewStatesStorage(c plugintk.DomainCallbacks, smtName, stateQueryContext, ro
 	}
 }
 
+// NewEmptyStatesStorage is used to build a tree again from its leaves. The tree starts out
+// empty, rather than from the latest root stored for the SMT. Nodes are still read from the
+// stored states, which is safe as they are addressed by their content.
+func NewEmptyStatesStorage(c plugintk.DomainCallbacks, smtName, stateQueryContext, rootSchemaId, nodeSchemaId string) StatesStorage {
+	s := NewStatesStorage(c, smtName, stateQueryContext, rootSchemaId, nodeSchemaId).(*statesStorage)
+	s.ignoreStoredRoot = true
+	return s
+}
+
 func (s *statesStorage) SetTransactionId(txId string) {
 	if s.pendingNodesTx == nil {
 		s.pendingNodesTx = &nodesTx{
@@ -124,6 +134,10 @@ func (s *statesStorage) GetRootNodeRef() (core.NodeRef, error) {
 		return s.rootNode.root, nil
 	}
 
+	if s.ignoreStoredRoot {
+		return nil, core.ErrNotFound
+	}
+
 	queryBuilder := query.NewQueryBuilder().
 		Limit(1).
 		Sort(".created DESC").
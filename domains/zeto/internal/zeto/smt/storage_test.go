@@ -44,6 +44,10 @@ func (dc *testDomainCallbacks) RecoverSigner(ctx context.Context, req *prototk.R
 	return nil, nil
 }
 
+func (dc *testDomainCallbacks) QueryContractEvents(ctx context.Context, req *prototk.QueryContractEventsRequest) (*prototk.QueryContractEventsResponse, error) {
+	return nil, nil
+}
+
 func (dc *testDomainCallbacks) DecodeData(context.Context, *prototk.DecodeDataRequest) (*prototk.DecodeDataResponse, error) {
 	return nil, nil
 }
@@ -367,3 +371,18 @@ func TestGetNewStates(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Len(t, states, 2)
 }
+
+func TestEmptyStatesStorage(t *testing.T) {
+	stateQueryConext := "context1"
+	storage := NewEmptyStatesStorage(&testDomainCallbacks{
+		returnFunc: func() (*prototk.FindAvailableStatesResponse, error) {
+			return nil, errors.New("not expected")
+		},
+	}, "test", stateQueryConext, "root-schema", "node-schema")
+	_, err := storage.GetRootNodeRef()
+	assert.ErrorIs(t, err, core.ErrNotFound)
+
+	tree, err := NewSmt(storage)
+	assert.NoError(t, err)
+	assert.True(t, tree.Root().IsZero())
+}
@@ -37,6 +37,9 @@ import (
 var MAX_INPUT_COUNT = 10
 var MAX_OUTPUT_COUNT = 10
 
+// the maximum number of coins summed by a balanceOf call, beyond which the result is flagged as an overflow
+var MAX_BALANCE_STATES = 1000
+
 func getStateSchemas(ctx context.Context) ([]string, error) {
 	var schemas []string
 	coinJSON, err := json.Marshal(types.ZetoCoinABI)
@@ -57,6 +60,12 @@ func getStateSchemas(ctx context.Context) ([]string, error) {
 	}
 	schemas = append(schemas, string(smtNodeJSON))
 
+	transferInfoJSON, err := json.Marshal(types.ZetoTransferInfoABI)
+	if err != nil {
+		return nil, i18n.NewError(ctx, msgs.MsgErrorMarshalTransferInfoSchemaAbi, err)
+	}
+	schemas = append(schemas, string(transferInfoJSON))
+
 	return schemas, nil
 }
 
@@ -95,6 +104,53 @@ func (z *Zeto) makeNewState(ctx context.Context, useNullifiers bool, coin *types
 	return newState, nil
 }
 
+// the transfer info is distributed to every party named in it, so that each of their
+// domain receipts can identify the counterparty
+func (z *Zeto) makeTransferInfoState(ctx context.Context, info *types.ZetoTransferInfo, distributionList []string) (*pb.NewState, error) {
+	info.Salt = (*tktypes.HexUint256)(crypto.NewSalt())
+	infoJSON, err := json.Marshal(info)
+	if err != nil {
+		return nil, err
+	}
+	hash, err := info.Hash(ctx)
+	if err != nil {
+		return nil, i18n.NewError(ctx, msgs.MsgErrorHashTransferInfo, err)
+	}
+	hashStr := hash.String()
+	return &pb.NewState{
+		Id:               &hashStr,
+		SchemaId:         z.transferInfoSchema.Id,
+		StateDataJson:    string(infoJSON),
+		DistributionList: distributionList,
+	}, nil
+}
+
+// a nil senderKey is used for a mint, where the value is created rather than moved from the sender
+func (z *Zeto) prepareTransferInfo(ctx context.Context, sender string, senderKey tktypes.HexBytes, params []*types.TransferParamEntry, outputCoins []*types.ZetoCoin) ([]*pb.NewState, error) {
+	var infoStates []*pb.NewState
+	for i, param := range params {
+		distributionList := []string{sender}
+		if param.To != sender {
+			distributionList = append(distributionList, param.To)
+		}
+		info := &types.ZetoTransferInfo{
+			To:     param.To,
+			ToKey:  outputCoins[i].Owner,
+			Amount: param.Amount,
+		}
+		if senderKey != nil {
+			info.From = sender
+			info.FromKey = senderKey
+		}
+		infoState, err := z.makeTransferInfoState(ctx, info, distributionList)
+		if err != nil {
+			return nil, i18n.NewError(ctx, msgs.MsgErrorCreateTransferInfo, err)
+		}
+		infoStates = append(infoStates, infoState)
+	}
+	return infoStates, nil
+}
+
 func (z *Zeto) prepareInputsForTransfer(ctx context.Context, useNullifiers bool, stateQueryContext, senderKey string, params []*types.TransferParamEntry) ([]*types.ZetoCoin, []*pb.StateRef, *big.Int, *big.Int, error) {
 	expectedTotal := big.NewInt(0)
 	for _, param := range params {
@@ -153,6 +209,31 @@ func (z *Zeto) buildInputsForExpectedTotal(ctx context.Context, useNullifiers bo
 	}
 }
 
+func (z *Zeto) getAccountBalance(ctx context.Context, useNullifiers bool, stateQueryContext, ownerKey string) (totalStates int, totalBalance *big.Int, overflow bool, err error) {
+	queryBuilder := query.NewQueryBuilder().
+		Limit(MAX_BALANCE_STATES+1).
+		Sort(".created").
+		Equal("owner", ownerKey)
+
+	states, err := z.findAvailableStates(ctx, useNullifiers, stateQueryContext, queryBuilder.Query().String())
+	if err != nil {
+		return 0, nil, false, i18n.NewError(ctx, msgs.MsgErrorQueryBalance, err)
+	}
+	if len(states) > MAX_BALANCE_STATES {
+		overflow = true
+		states = states[:MAX_BALANCE_STATES]
+	}
+	totalBalance = big.NewInt(0)
+	for _, state := range states {
+		coin, err := z.makeCoin(state.DataJson)
+		if err != nil {
+			return 0, nil, false, i18n.NewError(ctx, msgs.MsgInvalidCoin, state.Id, err)
+		}
+		totalBalance = totalBalance.Add(totalBalance, coin.Amount.Int())
+	}
+	return len(states), totalBalance, overflow, nil
+}
+
 func (z *Zeto) prepareOutputsForTransfer(ctx context.Context, useNullifiers bool, params []*types.TransferParamEntry, resolvedVerifiers []*pb.ResolvedVerifier) ([]*types.ZetoCoin, []*pb.NewState, error) {
 	var coins []*types.ZetoCoin
 	var newStates []*pb.NewState
@@ -29,7 +29,7 @@ import (
 func TestGetStateSchemas(t *testing.T) {
 	schemas, err := getStateSchemas(context.Background())
 	assert.NoError(t, err)
-	assert.Len(t, schemas, 3)
+	assert.Len(t, schemas, 4)
 }
 
 func TestPrepareInputs(t *testing.T) {
@@ -91,26 +91,53 @@ func validateAmountParam(ctx context.Context, amount *tktypes.HexUint256, i int)
 	return nil
 }
 
-func encodeTransactionData(ctx context.Context, transaction *prototk.TransactionSpecification) (tktypes.HexBytes, error) {
+// the info states of the transaction are appended to the transaction ID, so that
+// they can be confirmed by the event that completes the transaction
+func encodeTransactionData(ctx context.Context, transaction *prototk.TransactionSpecification, infoStates []*prototk.EndorsableState) (tktypes.HexBytes, error) {
 	txID, err := tktypes.ParseHexBytes(ctx, transaction.TransactionId)
 	if err != nil {
 		return nil, i18n.NewError(ctx, msgs.MsgErrorParseTxId, err)
 	}
 	var data []byte
-	data = append(data, types.ZetoTransactionData_V0...)
-	data = append(data, txID...)
+	if len(infoStates) == 0 {
+		data = append(data, types.ZetoTransactionData_V0...)
+		data = append(data, txID...)
+		return data, nil
+	}
+	txID32, err := tktypes.ParseBytes32Ctx(ctx, transaction.TransactionId)
+	if err != nil {
+		return nil, i18n.NewError(ctx, msgs.MsgErrorParseTxId, err)
+	}
+	data = append(data, types.ZetoTransactionData_V1...)
+	data = append(data, txID32[:]...)
+	for _, state := range infoStates {
+		stateID, err := tktypes.ParseBytes32Ctx(ctx, state.Id)
+		if err != nil {
+			return nil, err
+		}
+		data = append(data, stateID[:]...)
+	}
 	return data, nil
 }
 
-func decodeTransactionData(data tktypes.HexBytes) (txID tktypes.HexBytes) {
+func decodeTransactionData(data tktypes.HexBytes) (txID tktypes.HexBytes, infoStates []tktypes.Bytes32) {
 	if len(data) < 4 {
-		return nil
+		return nil, nil
 	}
 	dataPrefix := data[0:4]
-	if dataPrefix.String() != types.ZetoTransactionData_V0.String() {
-		return nil
+	switch dataPrefix.String() {
+	case types.ZetoTransactionData_V0.String():
+		return data[4:], nil
+	case types.ZetoTransactionData_V1.String():
+		if len(data) < 36 || (len(data)-36)%32 != 0 {
+			return nil, nil
+		}
+		for i := 36; i < len(data); i += 32 {
+			infoStates = append(infoStates, tktypes.Bytes32(data[i:i+32]))
+		}
+		return data[4:36], infoStates
 	}
-	return data[4:]
+	return nil, nil
 }
 
 func encodeProof(proof *corepb.SnarkProof) map[string]interface{} {
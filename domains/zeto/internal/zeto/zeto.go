@@ -34,6 +34,7 @@ import (
 	"github.com/kaleido-io/paladin/domains/zeto/pkg/zetosigner"
 	"github.com/kaleido-io/paladin/domains/zeto/pkg/zetosigner/zetosignerapi"
 	"github.com/kaleido-io/paladin/toolkit/pkg/algorithms"
+	"github.com/kaleido-io/paladin/toolkit/pkg/domain"
 	"github.com/kaleido-io/paladin/toolkit/pkg/log"
 	"github.com/kaleido-io/paladin/toolkit/pkg/plugintk"
 	"github.com/kaleido-io/paladin/toolkit/pkg/prototk"
@@ -51,6 +52,7 @@ type Zeto struct {
 	coinSchema               *prototk.StateSchema
 	merkleTreeRootSchema     *prototk.StateSchema
 	merkleTreeNodeSchema     *prototk.StateSchema
+	transferInfoSchema       *prototk.StateSchema
 	mintSignature            string
 	transferSignature        string
 	transferWithEncSignature string
@@ -146,8 +148,10 @@ func (z *Zeto) ConfigureDomain(ctx context.Context, req *prototk.ConfigureDomain
 	z.registerEventSignatures(events)
 
 	var signingAlgos map[string]int32
-	if config.SnarkProver.CircuitsDir != "" {
-		// Only build the prover and enable the algorithms for signing if circuits configured
+	backendType := config.SnarkProver.Backend.Type
+	if config.SnarkProver.CircuitsDir != "" || (backendType != "" && backendType != zetosignerapi.PROVER_BACKEND_LOCAL) {
+		// Only build the prover and enable the algorithms for signing if circuits configured,
+		// or the proofs are generated elsewhere
 		z.snarkProver, err = zetosigner.NewSnarkProver(&config.SnarkProver)
 		if err != nil {
 			return nil, err
@@ -163,6 +167,7 @@ func (z *Zeto) ConfigureDomain(ctx context.Context, req *prototk.ConfigureDomain
 			AbiStateSchemasJson: schemas,
 			AbiEventsJson:       string(eventsJSON),
 			SigningAlgorithms:   signingAlgos,
+			RpcMethods:          getRPCMethods(),
 		},
 	}, nil
 }
@@ -171,16 +176,17 @@ func (z *Zeto) InitDomain(ctx context.Context, req *prototk.InitDomainRequest) (
 	z.coinSchema = req.AbiStateSchemas[0]
 	z.merkleTreeRootSchema = req.AbiStateSchemas[1]
 	z.merkleTreeNodeSchema = req.AbiStateSchemas[2]
+	z.transferInfoSchema = req.AbiStateSchemas[3]
 
 	return &prototk.InitDomainResponse{}, nil
 }
 
 func (z *Zeto) InitDeploy(ctx context.Context, req *prototk.InitDeployRequest) (*prototk.InitDeployResponse, error) {
-	_, err := z.validateDeploy(req.Transaction)
+	initParams, err := z.validateDeploy(req.Transaction)
 	if err != nil {
 		return nil, i18n.NewError(ctx, msgs.MsgErrorValidateInitDeployParams, err)
 	}
-	return &prototk.InitDeployResponse{
+	res := &prototk.InitDeployResponse{
 		RequiredVerifiers: []*prototk.ResolveVerifierRequest{
 			{
 				Lookup:       req.Transaction.From,
@@ -188,7 +194,15 @@ func (z *Zeto) InitDeploy(ctx context.Context, req *prototk.InitDeployRequest) (
 				VerifierType: verifiers.ETH_ADDRESS,
 			},
 		},
-	}, nil
+	}
+	if initParams.InitialOwner != "" {
+		res.RequiredVerifiers = append(res.RequiredVerifiers, &prototk.ResolveVerifierRequest{
+			Lookup:       initParams.InitialOwner,
+			Algorithm:    algorithms.ECDSA_SECP256K1,
+			VerifierType: verifiers.ETH_ADDRESS,
+		})
+	}
+	return res, nil
 }
 
 func (z *Zeto) PrepareDeploy(ctx context.Context, req *prototk.PrepareDeployRequest) (*prototk.PrepareDeployResponse, error) {
@@ -213,11 +227,20 @@ func (z *Zeto) PrepareDeploy(ctx context.Context, req *prototk.PrepareDeployRequ
 		return nil, err
 	}
 
+	// the owner of the contract is the only account allowed to mint, and defaults to the deployer
+	initialOwner := req.ResolvedVerifiers[0]
+	if initParams.InitialOwner != "" {
+		initialOwner = domain.FindVerifier(initParams.InitialOwner, algorithms.ECDSA_SECP256K1, verifiers.ETH_ADDRESS, req.ResolvedVerifiers)
+		if initialOwner == nil {
+			return nil, i18n.NewError(ctx, msgs.MsgErrorResolveVerifier, initParams.InitialOwner)
+		}
+	}
+
 	deployParams := &types.DeployParams{
 		TransactionID: req.Transaction.TransactionId,
 		Data:          tktypes.HexBytes(encoded),
 		TokenName:     initParams.TokenName,
-		InitialOwner:  req.ResolvedVerifiers[0].Verifier, // TODO: allow the initial owner to be specified by the deploy request
+		InitialOwner:  initialOwner.Verifier,
 	}
 	paramsJSON, err := json.Marshal(deployParams)
 	if err != nil {
@@ -308,6 +331,15 @@ func (z *Zeto) GetHandler(method string) types.DomainHandler {
 	}
 }
 
+func (z *Zeto) GetCallHandler(method string) types.DomainCallHandler {
+	switch method {
+	case "balanceOf":
+		return &balanceOfHandler{zeto: z}
+	default:
+		return nil
+	}
+}
+
 func (z *Zeto) decodeDomainConfig(ctx context.Context, domainConfig []byte) (*types.DomainInstanceConfig, error) {
 	configValues, err := types.DomainInstanceConfigABI.DecodeABIDataCtx(ctx, domainConfig, 0)
 	if err != nil {
@@ -334,40 +366,69 @@ func (z *Zeto) validateTransaction(ctx context.Context, tx *prototk.TransactionS
 	if err != nil {
 		return nil, nil, i18n.NewError(ctx, msgs.MsgErrorUnmarshalFuncAbi, err)
 	}
+	handler := z.GetHandler(functionABI.Name)
+	if handler == nil {
+		return nil, nil, i18n.NewError(ctx, msgs.MsgUnknownFunction, functionABI.Name)
+	}
+	parsedTx, err := z.parseTransaction(ctx, tx, &functionABI, handler.ValidateParams)
+	if err != nil {
+		return nil, nil, err
+	}
+	return parsedTx, handler, nil
+}
 
-	var domainConfig *types.DomainInstanceConfig
-	err = json.Unmarshal([]byte(tx.ContractInfo.ContractConfigJson), &domainConfig)
+func (z *Zeto) validateCall(ctx context.Context, tx *prototk.TransactionSpecification) (*types.ParsedTransaction, types.DomainCallHandler, error) {
+	var functionABI abi.Entry
+	err := json.Unmarshal([]byte(tx.FunctionAbiJson), &functionABI)
+	if err != nil {
+		return nil, nil, i18n.NewError(ctx, msgs.MsgErrorUnmarshalFuncAbi, err)
+	}
+	handler := z.GetCallHandler(functionABI.Name)
+	if handler == nil {
+		return nil, nil, i18n.NewError(ctx, msgs.MsgUnknownFunction, functionABI.Name)
+	}
+	parsedTx, err := z.parseTransaction(ctx, tx, &functionABI, handler.ValidateParams)
 	if err != nil {
 		return nil, nil, err
 	}
+	return parsedTx, handler, nil
+}
+
+func (z *Zeto) parseTransaction(ctx context.Context, tx *prototk.TransactionSpecification, functionABI *abi.Entry,
+	validateParams func(ctx context.Context, config *types.DomainInstanceConfig, params string) (interface{}, error),
+) (*types.ParsedTransaction, error) {
+	var domainConfig *types.DomainInstanceConfig
+	err := json.Unmarshal([]byte(tx.ContractInfo.ContractConfigJson), &domainConfig)
+	if err != nil {
+		return nil, err
+	}
 
 	abi := types.ZetoABI.Functions()[functionABI.Name]
-	handler := z.GetHandler(functionABI.Name)
-	if abi == nil || handler == nil {
-		return nil, nil, i18n.NewError(ctx, msgs.MsgUnknownFunction, functionABI.Name)
+	if abi == nil {
+		return nil, i18n.NewError(ctx, msgs.MsgUnknownFunction, functionABI.Name)
 	}
-	params, err := handler.ValidateParams(ctx, domainConfig, tx.FunctionParamsJson)
+	params, err := validateParams(ctx, domainConfig, tx.FunctionParamsJson)
 	if err != nil {
-		return nil, nil, i18n.NewError(ctx, msgs.MsgErrorValidateFuncParams, err)
+		return nil, i18n.NewError(ctx, msgs.MsgErrorValidateFuncParams, err)
 	}
 
 	signature := abi.SolString()
 	if tx.FunctionSignature != signature {
-		return nil, nil, i18n.NewError(ctx, msgs.MsgUnexpectedFuncSignature, functionABI.Name, signature, tx.FunctionSignature)
+		return nil, i18n.NewError(ctx, msgs.MsgUnexpectedFuncSignature, functionABI.Name, signature, tx.FunctionSignature)
 	}
 
 	contractAddress, err := ethtypes.NewAddress(tx.ContractInfo.ContractAddress)
 	if err != nil {
-		return nil, nil, i18n.NewError(ctx, msgs.MsgErrorDecodeContractAddress, err)
+		return nil, i18n.NewError(ctx, msgs.MsgErrorDecodeContractAddress, err)
 	}
 
 	return &types.ParsedTransaction{
 		Transaction:     tx,
-		FunctionABI:     &functionABI,
+		FunctionABI:     functionABI,
 		ContractAddress: contractAddress,
 		DomainConfig:    domainConfig,
 		Params:          params,
-	}, handler, nil
+	}, nil
 }
 
 func (z *Zeto) registerEventSignatures(eventAbis abi.ABI) {
@@ -494,6 +555,14 @@ func (z *Zeto) ValidateStateHashes(ctx context.Context, req *prototk.ValidateSta
 	var res prototk.ValidateStateHashesResponse
 	for _, state := range req.States {
 		log.L(ctx).Debugf("validating state hashes: %+v\n", state)
+		if state.SchemaId == z.transferInfoSchema.Id {
+			stateID, err := z.validateTransferInfoHash(ctx, state)
+			if err != nil {
+				return nil, err
+			}
+			res.StateIds = append(res.StateIds, stateID)
+			continue
+		}
 		var coin types.ZetoCoin
 		err := json.Unmarshal([]byte(state.StateDataJson), &coin)
 		if err != nil {
@@ -520,15 +589,33 @@ func (z *Zeto) ValidateStateHashes(ctx context.Context, req *prototk.ValidateSta
 	return &res, nil
 }
 
-func (z *Zeto) InitCall(ctx context.Context, req *prototk.InitCallRequest) (*prototk.InitCallResponse, error) {
-	return nil, i18n.NewError(ctx, msgs.MsgNotImplemented)
+func (z *Zeto) validateTransferInfoHash(ctx context.Context, state *prototk.EndorsableState) (string, error) {
+	var info types.ZetoTransferInfo
+	if err := json.Unmarshal([]byte(state.StateDataJson), &info); err != nil {
+		return "", i18n.NewError(ctx, msgs.MsgErrorUnmarshalStateData, err)
+	}
+	hash, err := info.Hash(ctx)
+	if err != nil {
+		return "", i18n.NewError(ctx, msgs.MsgErrorHashTransferInfo, err)
+	}
+	if state.Id != "" && hash.String() != state.Id {
+		return "", i18n.NewError(ctx, msgs.MsgErrorStateHashMismatch, hash.String(), state.Id)
+	}
+	return hash.String(), nil
 }
 
-func (z *Zeto) ExecCall(ctx context.Context, req *prototk.ExecCallRequest) (*prototk.ExecCallResponse, error) {
-	return nil, i18n.NewError(ctx, msgs.MsgNotImplemented)
+func (z *Zeto) InitCall(ctx context.Context, req *prototk.InitCallRequest) (*prototk.InitCallResponse, error) {
+	tx, handler, err := z.validateCall(ctx, req.Transaction)
+	if err != nil {
+		return nil, i18n.NewError(ctx, msgs.MsgErrorValidateInitCallTxSpec, err)
+	}
+	return handler.InitCall(ctx, tx, req)
 }
 
-func (z *Zeto) BuildReceipt(ctx context.Context, req *prototk.BuildReceiptRequest) (*prototk.BuildReceiptResponse, error) {
-	// TODO: Event logs for transfers would be great for Noto
-	return nil, i18n.NewError(ctx, msgs.MsgNoDomainReceipt)
+func (z *Zeto) ExecCall(ctx context.Context, req *prototk.ExecCallRequest) (*prototk.ExecCallResponse, error) {
+	tx, handler, err := z.validateCall(ctx, req.Transaction)
+	if err != nil {
+		return nil, i18n.NewError(ctx, msgs.MsgErrorValidateExecCallTxSpec, err)
+	}
+	return handler.ExecCall(ctx, tx, req)
 }
@@ -31,8 +31,10 @@ import (
 	"github.com/kaleido-io/paladin/domains/zeto/pkg/types"
 	"github.com/kaleido-io/paladin/domains/zeto/pkg/zetosigner"
 	"github.com/kaleido-io/paladin/domains/zeto/pkg/zetosigner/zetosignerapi"
+	"github.com/kaleido-io/paladin/toolkit/pkg/algorithms"
 	"github.com/kaleido-io/paladin/toolkit/pkg/prototk"
 	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
+	"github.com/kaleido-io/paladin/toolkit/pkg/verifiers"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/protobuf/proto"
@@ -64,7 +66,25 @@ func TestConfigureDomain(t *testing.T) {
 	res, err := z.ConfigureDomain(context.Background(), req)
 	assert.NoError(t, err)
 	assert.NotNil(t, res)
+	assert.Contains(t, res.DomainConfig.SigningAlgorithms, "domain:z1:snark:babyjubjub")
 
+	// signing is enabled without circuits when the proofs are generated elsewhere
+	dfConfig.SnarkProver = zetosignerapi.SnarkProverConfig{
+		Backend: zetosignerapi.ProverBackendConfig{Type: zetosignerapi.PROVER_BACKEND_STUB},
+	}
+	configBytes, err = json.Marshal(dfConfig)
+	require.NoError(t, err)
+	req.ConfigJson = string(configBytes)
+	res, err = z.ConfigureDomain(context.Background(), req)
+	require.NoError(t, err)
+	assert.Contains(t, res.DomainConfig.SigningAlgorithms, "domain:z1:snark:babyjubjub")
+
+	dfConfig.SnarkProver.Backend.Type = "wrong"
+	configBytes, err = json.Marshal(dfConfig)
+	require.NoError(t, err)
+	req.ConfigJson = string(configBytes)
+	_, err = z.ConfigureDomain(context.Background(), req)
+	assert.EqualError(t, err, "PD210118: Prover backend type 'wrong' not recognized")
 }
 
 func TestDecodeDomainConfig(t *testing.T) {
@@ -100,6 +120,9 @@ func TestInitDomain(t *testing.T) {
 			{
 				Id: "schema3",
 			},
+			{
+				Id: "schema4",
+			},
 		},
 	}
 	res, err := z.InitDomain(context.Background(), req)
@@ -108,6 +131,7 @@ func TestInitDomain(t *testing.T) {
 	assert.Equal(t, "schema1", z.coinSchema.Id)
 	assert.Equal(t, "schema2", z.merkleTreeRootSchema.Id)
 	assert.Equal(t, "schema3", z.merkleTreeNodeSchema.Id)
+	assert.Equal(t, "schema4", z.transferInfoSchema.Id)
 }
 
 func TestInitDeploy(t *testing.T) {
@@ -123,8 +147,16 @@ func TestInitDeploy(t *testing.T) {
 	assert.EqualError(t, err, "PD210005: Failed to validate init deploy parameters. invalid character 'b' looking for beginning of value")
 
 	req.Transaction.ConstructorParamsJson = "{}"
-	_, err = z.InitDeploy(context.Background(), req)
+	res, err := z.InitDeploy(context.Background(), req)
+	assert.NoError(t, err)
+	assert.Len(t, res.RequiredVerifiers, 1)
+
+	req.Transaction.ConstructorParamsJson = "{\"initialOwner\":\"issuer\"}"
+	res, err = z.InitDeploy(context.Background(), req)
 	assert.NoError(t, err)
+	require.Len(t, res.RequiredVerifiers, 2)
+	assert.Equal(t, "issuer", res.RequiredVerifiers[1].Lookup)
+	assert.Equal(t, verifiers.ETH_ADDRESS, res.RequiredVerifiers[1].VerifierType)
 }
 
 func TestPrepareDeploy(t *testing.T) {
@@ -159,8 +191,23 @@ func TestPrepareDeploy(t *testing.T) {
 	assert.EqualError(t, err, "PD210007: Failed to find circuit ID based on the token name. PD210000: Contract '' not found")
 
 	req.Transaction.ConstructorParamsJson = "{\"tokenName\":\"testToken1\"}"
+	res, err := z.PrepareDeploy(context.Background(), req)
+	assert.NoError(t, err)
+	assert.Contains(t, res.Transaction.ParamsJson, "\"initialOwner\":\"Alice\"")
+
+	req.Transaction.ConstructorParamsJson = "{\"tokenName\":\"testToken1\",\"initialOwner\":\"issuer\"}"
 	_, err = z.PrepareDeploy(context.Background(), req)
+	assert.EqualError(t, err, "PD210036: Failed to resolve verifier: issuer")
+
+	req.ResolvedVerifiers = append(req.ResolvedVerifiers, &prototk.ResolvedVerifier{
+		Lookup:       "issuer",
+		Algorithm:    algorithms.ECDSA_SECP256K1,
+		VerifierType: verifiers.ETH_ADDRESS,
+		Verifier:     "0x1234567890123456789012345678901234567890",
+	})
+	res, err = z.PrepareDeploy(context.Background(), req)
 	assert.NoError(t, err)
+	assert.Contains(t, res.Transaction.ParamsJson, "\"initialOwner\":\"0x1234567890123456789012345678901234567890\"")
 }
 
 func TestInitContract(t *testing.T) {
@@ -265,6 +312,9 @@ func TestAssembleTransaction(t *testing.T) {
 	z.coinSchema = &prototk.StateSchema{
 		Id: "coin",
 	}
+	z.transferInfoSchema = &prototk.StateSchema{
+		Id: "transfer_info",
+	}
 
 	assert.Equal(t, "z1", z.Name())
 	assert.Equal(t, "coin", z.CoinSchemaID())
@@ -378,6 +428,9 @@ func TestFindCoins(t *testing.T) {
 	z.coinSchema = &prototk.StateSchema{
 		Id: "coin",
 	}
+	z.transferInfoSchema = &prototk.StateSchema{
+		Id: "transfer_info",
+	}
 	useNullifiers := false
 	addr, _ := tktypes.ParseEthAddress("0x1234567890123456789012345678901234567890")
 	_, err := findCoins(context.Background(), z, useNullifiers, addr, "{}")
@@ -408,12 +461,18 @@ func newTestZeto() (*Zeto, *testDomainCallbacks) {
 	z.coinSchema = &prototk.StateSchema{
 		Id: "coin",
 	}
+	z.transferInfoSchema = &prototk.StateSchema{
+		Id: "transfer_info",
+	}
 	z.merkleTreeRootSchema = &prototk.StateSchema{
 		Id: "merkle_tree_root",
 	}
 	z.merkleTreeNodeSchema = &prototk.StateSchema{
 		Id: "merkle_tree_node",
 	}
+	z.transferInfoSchema = &prototk.StateSchema{
+		Id: "transfer_info",
+	}
 	z.mintSignature = "event UTXOMint(uint256[] outputs, address indexed submitter, bytes data)"
 	z.transferSignature = "event UTXOTransfer(uint256[] inputs, uint256[] outputs, address indexed submitter, bytes data)"
 	z.transferWithEncSignature = "event UTXOTransferWithEncryptedValues(uint256[] inputs, uint256[] outputs, uint256 encryptionNonce, uint256[2] ecdhPublicKey, uint256[] encryptedValues, address indexed submitter, bytes data)"
@@ -493,6 +552,9 @@ func TestGetVerifier(t *testing.T) {
 	z.coinSchema = &prototk.StateSchema{
 		Id: "coin",
 	}
+	z.transferInfoSchema = &prototk.StateSchema{
+		Id: "transfer_info",
+	}
 	snarkProver, err := zetosigner.NewSnarkProver(&zetosignerapi.SnarkProverConfig{})
 	assert.NoError(t, err)
 	z.snarkProver = snarkProver
@@ -521,6 +583,9 @@ func TestSign(t *testing.T) {
 	z.coinSchema = &prototk.StateSchema{
 		Id: "coin",
 	}
+	z.transferInfoSchema = &prototk.StateSchema{
+		Id: "transfer_info",
+	}
 	snarkProver := signer.NewTestProver(t)
 	z.snarkProver = snarkProver
 
@@ -631,6 +696,32 @@ func TestValidateStateHashes(t *testing.T) {
 	res, err = z.ValidateStateHashes(ctx, req)
 	assert.NoError(t, err)
 	assert.Len(t, res.StateIds, 1)
+
+	info := &types.ZetoTransferInfo{
+		Salt:    tktypes.MustParseHexUint256("0x01"),
+		From:    "Alice",
+		FromKey: tktypes.MustParseHexBytes("0x7cdd539f3ed6c283494f47d8481f84308a6d7043087fb6711c9f1df04e2b8025"),
+		Amount:  tktypes.MustParseHexUint256("0x0f"),
+	}
+	infoHash, err := info.Hash(ctx)
+	require.NoError(t, err)
+	req.States = []*prototk.EndorsableState{
+		{
+			SchemaId:      "transfer_info",
+			StateDataJson: tktypes.JSONString(info).String(),
+		},
+	}
+	res, err = z.ValidateStateHashes(ctx, req)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{infoHash.String()}, res.StateIds)
+
+	req.States[0].Id = "0x1234"
+	_, err = z.ValidateStateHashes(ctx, req)
+	assert.ErrorContains(t, err, "PD210086")
+
+	req.States[0].StateDataJson = "bad json"
+	_, err = z.ValidateStateHashes(ctx, req)
+	assert.ErrorContains(t, err, "PD210087")
 }
 
 func findCoins(ctx context.Context, z *Zeto, useNullifiers bool, contractAddress *tktypes.EthAddress, query string) ([]*types.ZetoCoin, error) {
@@ -660,14 +751,66 @@ func TestGetHandler(t *testing.T) {
 	assert.Nil(t, z.GetHandler("bad"))
 }
 
-func TestUnimplementedMethods(t *testing.T) {
-	z := &Zeto{}
-	_, err := z.InitCall(context.Background(), nil)
-	assert.ErrorContains(t, err, "PD210085: Not implemented")
+func TestGetCallHandler(t *testing.T) {
+	z := &Zeto{
+		name: "test1",
+	}
+	assert.NotNil(t, z.GetCallHandler("balanceOf"))
+	assert.Nil(t, z.GetCallHandler("transfer"))
+}
 
-	_, err = z.ExecCall(context.Background(), nil)
-	assert.ErrorContains(t, err, "PD210085: Not implemented")
+func TestInitCall(t *testing.T) {
+	z := &Zeto{
+		name: "test1",
+	}
+	req := &prototk.InitCallRequest{
+		Transaction: &prototk.TransactionSpecification{
+			FunctionAbiJson:    "bad json",
+			FunctionParamsJson: "bad json",
+			ContractInfo: &prototk.ContractInfo{
+				ContractConfigJson: `{}`,
+			},
+		},
+	}
+	_, err := z.InitCall(context.Background(), req)
+	assert.ErrorContains(t, err, "PD210110")
+
+	req.Transaction.FunctionAbiJson = "{\"type\":\"function\",\"name\":\"transfer\"}"
+	_, err = z.InitCall(context.Background(), req)
+	assert.EqualError(t, err, "PD210110: Failed to validate init call transaction spec. PD210014: Unknown function: transfer")
+
+	req.Transaction.FunctionAbiJson = "{\"type\":\"function\",\"name\":\"balanceOf\"}"
+	req.Transaction.FunctionParamsJson = "{}"
+	_, err = z.InitCall(context.Background(), req)
+	assert.EqualError(t, err, "PD210110: Failed to validate init call transaction spec. PD210015: Failed to validate function params. PD210109: Parameter 'account' is required")
 
-	_, err = z.BuildReceipt(context.Background(), nil)
-	assert.ErrorContains(t, err, "PD210102: Not implemented")
+	req.Transaction.FunctionParamsJson = "{\"account\":\"Alice\"}"
+	req.Transaction.FunctionSignature = types.ZetoABI.Functions()["balanceOf"].SolString()
+	req.Transaction.ContractInfo.ContractAddress = "0x1234567890123456789012345678901234567890"
+	res, err := z.InitCall(context.Background(), req)
+	require.NoError(t, err)
+	assert.Equal(t, "Alice", res.RequiredVerifiers[0].Lookup)
+}
+
+func TestExecCall(t *testing.T) {
+	z := &Zeto{
+		name: "test1",
+	}
+	req := &prototk.ExecCallRequest{
+		Transaction: &prototk.TransactionSpecification{
+			FunctionAbiJson:    "bad json",
+			FunctionParamsJson: "{\"account\":\"Alice\"}",
+			ContractInfo: &prototk.ContractInfo{
+				ContractConfigJson: `{}`,
+			},
+		},
+	}
+	_, err := z.ExecCall(context.Background(), req)
+	assert.ErrorContains(t, err, "PD210111")
+
+	req.Transaction.FunctionAbiJson = "{\"type\":\"function\",\"name\":\"balanceOf\"}"
+	req.Transaction.FunctionSignature = types.ZetoABI.Functions()["balanceOf"].SolString()
+	req.Transaction.ContractInfo.ContractAddress = "0x1234567890123456789012345678901234567890"
+	_, err = z.ExecCall(context.Background(), req)
+	assert.EqualError(t, err, "PD210036: Failed to resolve verifier: Alice")
 }
@@ -28,8 +28,8 @@ var zetoPrivateJSON []byte
 var ZetoABI = solutils.MustParseBuildABI(zetoPrivateJSON)
 
 type InitializerParams struct {
-	TokenName string `json:"tokenName"`
-	// InitialOwner string `json:"initialOwner"` // TODO: allow the initial owner to be specified by the deploy request
+	TokenName    string `json:"tokenName"`
+	InitialOwner string `json:"initialOwner"` // optional lookup for the owner of the contract - defaults to the deployer
 }
 
 type DeployParams struct {
@@ -64,3 +64,13 @@ type DepositParams struct {
 type WithdrawParams struct {
 	Amount *tktypes.HexUint256 `json:"amount"`
 }
+
+type BalanceOfParam struct {
+	Account string `json:"account"`
+}
+
+type BalanceOfResult struct {
+	TotalStates  *tktypes.HexUint256 `json:"totalStates"`
+	TotalBalance *tktypes.HexUint256 `json:"totalBalance"`
+	Overflow     bool                `json:"overflow"`
+}
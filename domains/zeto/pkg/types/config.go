@@ -86,5 +86,9 @@ var DomainInstanceConfigABI = &abi.ParameterArray{
 // marks the version of the Zeto transaction data schema
 var ZetoTransactionData_V0 = ethtypes.MustNewHexBytes0xPrefix("0x00010000")
 
+// V1 appends the IDs of the info states to the transaction ID
+var ZetoTransactionData_V1 = ethtypes.MustNewHexBytes0xPrefix("0x00010001")
+
 type DomainHandler = domain.DomainHandler[DomainInstanceConfig]
 type ParsedTransaction = domain.ParsedTransaction[DomainInstanceConfig]
+type DomainCallHandler = domain.DomainCallHandler[DomainInstanceConfig]
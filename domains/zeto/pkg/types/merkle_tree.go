@@ -0,0 +1,58 @@
+/*
+ * Copyright © 2024 Kaleido, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package types
+
+import (
+	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
+)
+
+type MerkleTreeStatus string
+
+const (
+	MerkleTreeInSync     MerkleTreeStatus = "inSync"     // the local root is the latest root of the indexed events
+	MerkleTreeBehind     MerkleTreeStatus = "behind"     // the local root is an earlier root of the indexed events
+	MerkleTreeDiverged   MerkleTreeStatus = "diverged"   // the local root was never produced by the indexed events
+	MerkleTreeIncomplete MerkleTreeStatus = "incomplete" // the local root was produced by the indexed events, but some of its nodes are not stored
+)
+
+// MerkleTreeCheck compares the local Merkle tree of a contract with the tree built by replaying
+// the UTXO events of the contract that have been indexed. The indexed root is not read from
+// the contract, so it is only as current as the block indexer of the node.
+type MerkleTreeCheck struct {
+	SmtName       string           `json:"smtName"`
+	Status        MerkleTreeStatus `json:"status"`
+	LocalRoot     tktypes.Bytes32  `json:"localRoot"`
+	LocalLeaves   int              `json:"localLeaves"`
+	MissingNodes  int              `json:"missingNodes"`
+	IndexedRoot   tktypes.Bytes32  `json:"indexedRoot"`
+	IndexedLeaves int              `json:"indexedLeaves"`
+}
+
+// MerkleTreeUpdate is the outcome of rebuilding a Merkle tree, or of importing a snapshot of one.
+type MerkleTreeUpdate struct {
+	SmtName      string          `json:"smtName"`
+	PreviousRoot tktypes.Bytes32 `json:"previousRoot"`
+	Root         tktypes.Bytes32 `json:"root"`
+	Leaves       int             `json:"leaves"`
+}
+
+// MerkleTreeSnapshot holds all the leaves of a Merkle tree. The root of the tree depends
+// only on its leaves, so the snapshot is all that is needed to build the tree again.
+type MerkleTreeSnapshot struct {
+	SmtName string               `json:"smtName"`
+	Root    tktypes.Bytes32      `json:"root"`
+	Leaves  []tktypes.HexUint256 `json:"leaves"`
+}
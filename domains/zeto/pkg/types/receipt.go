@@ -0,0 +1,45 @@
+/*
+ * Copyright © 2024 Kaleido, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package types
+
+import (
+	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
+)
+
+type ZetoDomainReceipt struct {
+	States    ReceiptStates      `json:"states"`
+	Transfers []*ReceiptTransfer `json:"transfers,omitempty"`
+}
+
+type ReceiptStates struct {
+	Inputs  []*ReceiptState `json:"inputs,omitempty"`
+	Outputs []*ReceiptState `json:"outputs,omitempty"`
+}
+
+// ReceiptState is a coin available to the local node. The owner is only set when the coin's
+// key matches a party recorded in the transfer info of the transaction.
+type ReceiptState struct {
+	ID    tktypes.HexBytes `json:"id"`
+	Owner string           `json:"owner,omitempty"`
+	Data  *ZetoCoin        `json:"data"`
+}
+
+// ReceiptTransfer is a logical movement of value. A mint or deposit has no "from", and a withdraw has no "to".
+type ReceiptTransfer struct {
+	From   string              `json:"from,omitempty"`
+	To     string              `json:"to,omitempty"`
+	Amount *tktypes.HexUint256 `json:"amount"`
+}
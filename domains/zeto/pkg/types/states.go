@@ -17,6 +17,7 @@ package types
 
 import (
 	"context"
+	"encoding/json"
 	"math/big"
 
 	"github.com/hyperledger/firefly-common/pkg/i18n"
@@ -70,3 +71,41 @@ var ZetoCoinABI = &abi.Parameter{
 		{Name: "amount", Type: "uint256", Indexed: true},
 	},
 }
+
+// ZetoTransferInfo is an info state recording a movement of value between the identities of a transaction,
+// as they were resolved when it was assembled. A mint or deposit has no "from", and a withdraw has no "to".
+type ZetoTransferInfo struct {
+	Salt    *tktypes.HexUint256 `json:"salt"`
+	From    string              `json:"from"`
+	FromKey tktypes.HexBytes    `json:"fromKey"`
+	To      string              `json:"to"`
+	ToKey   tktypes.HexBytes    `json:"toKey"`
+	Amount  *tktypes.HexUint256 `json:"amount"`
+}
+
+// Hash is the keccak256 of the ABI encoded info, as the Poseidon hash used for coins
+// cannot be applied to the identity strings
+func (z *ZetoTransferInfo) Hash(ctx context.Context) (tktypes.Bytes32, error) {
+	infoJSON, err := json.Marshal(z)
+	if err != nil {
+		return tktypes.Bytes32{}, err
+	}
+	encoded, err := ZetoTransferInfoABI.Components.EncodeABIDataJSONCtx(ctx, infoJSON)
+	if err != nil {
+		return tktypes.Bytes32{}, err
+	}
+	return tktypes.Bytes32Keccak(encoded), nil
+}
+
+var ZetoTransferInfoABI = &abi.Parameter{
+	Type:         "tuple",
+	InternalType: "struct ZetoTransferInfo",
+	Components: abi.ParameterArray{
+		{Name: "salt", Type: "uint256"},
+		{Name: "from", Type: "string", Indexed: true},
+		{Name: "fromKey", Type: "bytes"},
+		{Name: "to", Type: "string", Indexed: true},
+		{Name: "toKey", Type: "bytes"},
+		{Name: "amount", Type: "uint256"},
+	},
+}
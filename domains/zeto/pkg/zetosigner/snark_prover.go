@@ -2,6 +2,7 @@ package zetosigner
 
 import (
 	"github.com/kaleido-io/paladin/domains/zeto/internal/zeto/signer"
+	pb "github.com/kaleido-io/paladin/domains/zeto/pkg/proto"
 	"github.com/kaleido-io/paladin/domains/zeto/pkg/zetosigner/zetosignerapi"
 	"github.com/kaleido-io/paladin/toolkit/pkg/signerapi"
 )
@@ -9,3 +10,10 @@ import (
 func NewSnarkProver(conf *zetosignerapi.SnarkProverConfig) (signerapi.InMemorySigner, error) {
 	return signer.NewSnarkProver(conf)
 }
+
+// NewProverServer returns the service to host in a prover process, for signers that
+// are configured with the "grpc" prover backend. Register it on a gRPC server with
+// proto.RegisterZetoProverServer
+func NewProverServer(conf *zetosignerapi.SnarkProverConfig) (pb.ZetoProverServer, error) {
+	return signer.NewProverServer(conf)
+}
@@ -24,9 +24,27 @@ import (
 // based on SNARK, which typically takes a circuit and proving key
 type SnarkProverConfig struct {
 	signerapi.ConfigNoExt
-	CircuitsDir         string `json:"circuitsDir"`         // directory for the circuits runtime (WASM currently supported)
-	ProvingKeysDir      string `json:"provingKeysDir"`      // public parameters for the prover, specific to each circuit
-	MaxProverPerCircuit *int   `json:"maxProverPerCircuit"` // maximum number of proving runtime per circuit, each prover owns a standalone WASM instance
+	CircuitsDir         string              `json:"circuitsDir"`         // directory for the circuits runtime (WASM currently supported)
+	ProvingKeysDir      string              `json:"provingKeysDir"`      // public parameters for the prover, specific to each circuit
+	MaxProverPerCircuit *int                `json:"maxProverPerCircuit"` // maximum number of proving runtime per circuit, each prover owns a standalone WASM instance
+	Backend             ProverBackendConfig `json:"backend"`             // where the witness is calculated and the proof generated
+}
+
+const (
+	PROVER_BACKEND_LOCAL = "local" // in the signing process, with the WASM witness calculators and rapidsnark (default)
+	PROVER_BACKEND_GRPC  = "grpc"  // in a separate prover process, which runs the "local" backend behind a gRPC service
+	PROVER_BACKEND_STUB  = "stub"  // returns a fixed proof without running the circuits - for testing only, as the proofs do not verify
+)
+
+type ProverBackendConfig struct {
+	Type          string              `json:"type"`
+	Address       string              `json:"address"`       // target of the prover service for the "grpc" backend, such as "dns:///prover:9000"
+	TLS           pldconf.TLSConfig   `json:"tls"`           // TLS for the connection to the prover service
+	Insecure      bool                `json:"insecure"`      // must be set to connect to the prover service without TLS, as the witness inputs contain private keys
+	MaxConcurrent *int                `json:"maxConcurrent"` // proving requests in progress at once
+	MaxQueued     *int                `json:"maxQueued"`     // proving requests that can wait for a free slot, before further requests are rejected
+	Timeout       *string             `json:"timeout"`       // limit on a proving request, including the time it waits in the queue
+	ProofCache    pldconf.CacheConfig `json:"proofCache"`    // proofs by circuit, transaction and proving request, so a retried signing is not proved twice
 }
 
 // Implements the extensible config interface of the signer
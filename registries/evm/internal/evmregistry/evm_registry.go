@@ -22,6 +22,7 @@ import (
 	_ "embed"
 
 	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly-signer/pkg/abi"
 	"github.com/kaleido-io/paladin/registries/evm/internal/msgs"
 	"github.com/kaleido-io/paladin/toolkit/pkg/log"
 	"github.com/kaleido-io/paladin/toolkit/pkg/plugintk"
@@ -199,3 +200,57 @@ func (r *evmRegistry) HandleRegistryEvents(ctx context.Context, req *prototk.Han
 		Properties: properties,
 	}, nil
 }
+
+func (r *evmRegistry) RegisterIdentity(ctx context.Context, req *prototk.RegisterIdentityRequest) (*prototk.RegisterIdentityResponse, error) {
+	// Root entries in the registry are registered under the zero hash
+	params := &RegisterIdentityParams{Name: req.Name}
+	if req.ParentId != "" {
+		parentID, err := tktypes.ParseBytes32(req.ParentId)
+		if err != nil {
+			return nil, i18n.WrapError(ctx, err, msgs.MsgInvalidParentID, req.ParentId)
+		}
+		params.ParentIdentityHash = parentID
+	}
+	owner, err := tktypes.ParseEthAddress(req.Owner)
+	if err != nil {
+		return nil, i18n.WrapError(ctx, err, msgs.MsgInvalidOwnerAddress, req.Owner)
+	}
+	params.Owner = *owner
+
+	txID, err := r.sendTransaction(ctx, req.From, contractDetail.registerIdentityFunction, params)
+	if err != nil {
+		return nil, err
+	}
+	return &prototk.RegisterIdentityResponse{TransactionId: txID}, nil
+}
+
+func (r *evmRegistry) SetProperty(ctx context.Context, req *prototk.SetPropertyRequest) (*prototk.SetPropertyResponse, error) {
+	entryID, err := tktypes.ParseBytes32(req.EntryId)
+	if err != nil {
+		return nil, i18n.WrapError(ctx, err, msgs.MsgInvalidEntryID, req.EntryId)
+	}
+
+	txID, err := r.sendTransaction(ctx, req.From, contractDetail.setIdentityPropertyFunction, &SetIdentityPropertyParams{
+		IdentityHash: entryID,
+		Name:         req.Name,
+		Value:        req.Value,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &prototk.SetPropertyResponse{TransactionId: txID}, nil
+}
+
+func (r *evmRegistry) sendTransaction(ctx context.Context, from string, function *abi.Entry, params any) (string, error) {
+	res, err := r.callbacks.SendTransaction(ctx, &prototk.SendTransactionRequest{
+		From:            from,
+		ContractAddress: r.conf.ContractAddress.String(),
+		FunctionAbiJson: tktypes.JSONString(function).String(),
+		ParamsJson:      tktypes.JSONString(params).String(),
+	})
+	if err != nil {
+		return "", err
+	}
+	log.L(ctx).Infof("Submitted %s transaction %s to registry contract %s", function.Name, res.TransactionId, r.conf.ContractAddress)
+	return res.TransactionId, nil
+}
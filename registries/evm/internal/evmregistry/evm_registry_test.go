@@ -17,6 +17,7 @@ package evmregistry
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"testing"
 
@@ -29,12 +30,17 @@ import (
 
 type testCallbacks struct {
 	upsertRegistryRecords func(ctx context.Context, req *prototk.UpsertRegistryRecordsRequest) (*prototk.UpsertRegistryRecordsResponse, error)
+	sendTransaction       func(ctx context.Context, req *prototk.SendTransactionRequest) (*prototk.SendTransactionResponse, error)
 }
 
 func (tc *testCallbacks) UpsertRegistryRecords(ctx context.Context, req *prototk.UpsertRegistryRecordsRequest) (*prototk.UpsertRegistryRecordsResponse, error) {
 	return tc.upsertRegistryRecords(ctx, req)
 }
 
+func (tc *testCallbacks) SendTransaction(ctx context.Context, req *prototk.SendTransactionRequest) (*prototk.SendTransactionResponse, error) {
+	return tc.sendTransaction(ctx, req)
+}
+
 func TestPluginLifecycle(t *testing.T) {
 	pb := NewPlugin(context.Background())
 	assert.NotNil(t, pb)
@@ -377,3 +383,157 @@ func TestHandleRoot(t *testing.T) {
 	}`, tktypes.JSONString(res.Properties[0]).Pretty())
 
 }
+
+func newConfiguredTestRegistry(t *testing.T, callbacks *testCallbacks) (*evmRegistry, *tktypes.EthAddress) {
+	addr := tktypes.RandAddress()
+	registry := NewEVMRegistry(callbacks).(*evmRegistry)
+	_, err := registry.ConfigureRegistry(registry.bgCtx, &prototk.ConfigureRegistryRequest{
+		Name:       "evm",
+		ConfigJson: fmt.Sprintf(`{"contractAddress": "%s"}`, addr),
+	})
+	require.NoError(t, err)
+	return registry, addr
+}
+
+func TestRegisterIdentityRootOk(t *testing.T) {
+
+	owner := tktypes.RandAddress()
+	txID := uuid.NewString()
+	var addr *tktypes.EthAddress
+	callbacks := &testCallbacks{
+		sendTransaction: func(ctx context.Context, req *prototk.SendTransactionRequest) (*prototk.SendTransactionResponse, error) {
+			assert.Equal(t, "admin", req.From)
+			assert.Equal(t, addr.String(), req.ContractAddress)
+			assert.JSONEq(t, tktypes.JSONString(contractDetail.registerIdentityFunction).String(), req.FunctionAbiJson)
+			assert.JSONEq(t, fmt.Sprintf(`{
+				"parentIdentityHash": "%s",
+				"name": "node1",
+				"owner": "%s"
+			}`, tktypes.Bytes32{}, owner), req.ParamsJson)
+			return &prototk.SendTransactionResponse{TransactionId: txID}, nil
+		},
+	}
+	registry, addr := newConfiguredTestRegistry(t, callbacks)
+
+	res, err := registry.RegisterIdentity(registry.bgCtx, &prototk.RegisterIdentityRequest{
+		From:  "admin",
+		Name:  "node1",
+		Owner: owner.String(),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, txID, res.TransactionId)
+
+}
+
+func TestRegisterIdentityChildOk(t *testing.T) {
+
+	parentID := tktypes.Bytes32(tktypes.RandBytes(32))
+	callbacks := &testCallbacks{
+		sendTransaction: func(ctx context.Context, req *prototk.SendTransactionRequest) (*prototk.SendTransactionResponse, error) {
+			var params RegisterIdentityParams
+			err := json.Unmarshal([]byte(req.ParamsJson), &params)
+			require.NoError(t, err)
+			assert.Equal(t, parentID, params.ParentIdentityHash)
+			return &prototk.SendTransactionResponse{TransactionId: uuid.NewString()}, nil
+		},
+	}
+	registry, _ := newConfiguredTestRegistry(t, callbacks)
+
+	_, err := registry.RegisterIdentity(registry.bgCtx, &prototk.RegisterIdentityRequest{
+		ParentId: parentID.String(),
+		Name:     "child1",
+		Owner:    tktypes.RandAddress().String(),
+	})
+	require.NoError(t, err)
+
+}
+
+func TestRegisterIdentityBadInput(t *testing.T) {
+
+	registry, _ := newConfiguredTestRegistry(t, &testCallbacks{})
+
+	_, err := registry.RegisterIdentity(registry.bgCtx, &prototk.RegisterIdentityRequest{
+		ParentId: "wrong",
+	})
+	assert.Regexp(t, "PD060004", err)
+
+	_, err = registry.RegisterIdentity(registry.bgCtx, &prototk.RegisterIdentityRequest{
+		Owner: "wrong",
+	})
+	assert.Regexp(t, "PD060005", err)
+
+}
+
+func TestRegisterIdentitySendFail(t *testing.T) {
+
+	callbacks := &testCallbacks{
+		sendTransaction: func(ctx context.Context, req *prototk.SendTransactionRequest) (*prototk.SendTransactionResponse, error) {
+			return nil, fmt.Errorf("pop")
+		},
+	}
+	registry, _ := newConfiguredTestRegistry(t, callbacks)
+
+	_, err := registry.RegisterIdentity(registry.bgCtx, &prototk.RegisterIdentityRequest{
+		Owner: tktypes.RandAddress().String(),
+	})
+	assert.Regexp(t, "pop", err)
+
+}
+
+func TestSetPropertyOk(t *testing.T) {
+
+	entryID := tktypes.Bytes32(tktypes.RandBytes(32))
+	txID := uuid.NewString()
+	var addr *tktypes.EthAddress
+	callbacks := &testCallbacks{
+		sendTransaction: func(ctx context.Context, req *prototk.SendTransactionRequest) (*prototk.SendTransactionResponse, error) {
+			assert.Equal(t, "node1key", req.From)
+			assert.Equal(t, addr.String(), req.ContractAddress)
+			assert.JSONEq(t, tktypes.JSONString(contractDetail.setIdentityPropertyFunction).String(), req.FunctionAbiJson)
+			assert.JSONEq(t, fmt.Sprintf(`{
+				"identityHash": "%s",
+				"name": "transport.grpc",
+				"value": "{\"endpoint\":\"details\"}"
+			}`, entryID), req.ParamsJson)
+			return &prototk.SendTransactionResponse{TransactionId: txID}, nil
+		},
+	}
+	registry, addr := newConfiguredTestRegistry(t, callbacks)
+
+	res, err := registry.SetProperty(registry.bgCtx, &prototk.SetPropertyRequest{
+		From:    "node1key",
+		EntryId: entryID.String(),
+		Name:    "transport.grpc",
+		Value:   `{"endpoint":"details"}`,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, txID, res.TransactionId)
+
+}
+
+func TestSetPropertyBadEntryID(t *testing.T) {
+
+	registry, _ := newConfiguredTestRegistry(t, &testCallbacks{})
+
+	_, err := registry.SetProperty(registry.bgCtx, &prototk.SetPropertyRequest{
+		EntryId: "wrong",
+	})
+	assert.Regexp(t, "PD060006", err)
+
+}
+
+func TestSetPropertySendFail(t *testing.T) {
+
+	callbacks := &testCallbacks{
+		sendTransaction: func(ctx context.Context, req *prototk.SendTransactionRequest) (*prototk.SendTransactionResponse, error) {
+			return nil, fmt.Errorf("pop")
+		},
+	}
+	registry, _ := newConfiguredTestRegistry(t, callbacks)
+
+	_, err := registry.SetProperty(registry.bgCtx, &prototk.SetPropertyRequest{
+		EntryId: tktypes.Bytes32(tktypes.RandBytes(32)).String(),
+	})
+	assert.Regexp(t, "pop", err)
+
+}
@@ -32,6 +32,8 @@ type identityRegistryContractDefinition struct {
 	abi                         abi.ABI
 	identityRegisteredSignature tktypes.Bytes32
 	propertySetSignature        tktypes.Bytes32
+	registerIdentityFunction    *abi.Entry
+	setIdentityPropertyFunction *abi.Entry
 }
 
 const identityRegisteredEventSolSig = "event IdentityRegistered(bytes32 parentIdentityHash, bytes32 identityHash, string name, address owner)"
@@ -51,6 +53,22 @@ type PropertySetEvent struct {
 	Value        string          `json:"value"`
 }
 
+const registerIdentityFunctionSolSig = "function registerIdentity(bytes32 parentIdentityHash, string memory name, address owner) external { }"
+
+type RegisterIdentityParams struct {
+	ParentIdentityHash tktypes.Bytes32    `json:"parentIdentityHash"`
+	Name               string             `json:"name"`
+	Owner              tktypes.EthAddress `json:"owner"`
+}
+
+const setIdentityPropertyFunctionSolSig = "function setIdentityProperty(bytes32 identityHash, string memory name, string memory value) external { }"
+
+type SetIdentityPropertyParams struct {
+	IdentityHash tktypes.Bytes32 `json:"identityHash"`
+	Name         string          `json:"name"`
+	Value        string          `json:"value"`
+}
+
 func mustLoadIdentityRegistryContractDetail(buildOutput []byte) *identityRegistryContractDefinition {
 	var build SolidityBuild
 	err := json.Unmarshal(buildOutput, &build)
@@ -70,9 +88,23 @@ func mustLoadIdentityRegistryContractDetail(buildOutput []byte) *identityRegistr
 		panic(fmt.Sprintf("contract signature has changed: %s", propertySetEvent.SolString()))
 	}
 
+	// Likewise for the functions we submit transactions against
+
+	registerIdentityFunction := build.ABI.Functions()["registerIdentity"]
+	if registerIdentityFunction.SolString() != registerIdentityFunctionSolSig {
+		panic(fmt.Sprintf("contract signature has changed: %s", registerIdentityFunction.SolString()))
+	}
+
+	setIdentityPropertyFunction := build.ABI.Functions()["setIdentityProperty"]
+	if setIdentityPropertyFunction.SolString() != setIdentityPropertyFunctionSolSig {
+		panic(fmt.Sprintf("contract signature has changed: %s", setIdentityPropertyFunction.SolString()))
+	}
+
 	return &identityRegistryContractDefinition{
 		abi:                         build.ABI,
 		identityRegisteredSignature: tktypes.Bytes32(identityRegisteredEvent.SignatureHashBytes()),
 		propertySetSignature:        tktypes.Bytes32(propertySetEvent.SignatureHashBytes()),
+		registerIdentityFunction:    registerIdentityFunction,
+		setIdentityPropertyFunction: setIdentityPropertyFunction,
 	}
 }
@@ -54,6 +54,39 @@ func TestDetectsContractChangeAtBuildTime(t *testing.T) {
 		}))
 	})
 
+	assert.PanicsWithValue(t, "contract signature has changed: function registerIdentity(address different) external { }", func() {
+		mustLoadIdentityRegistryContractDetail(tktypes.JSONString(SolidityBuild{
+			ABI: abi.ABI{
+				contractDetail.abi.Events()["IdentityRegistered"],
+				contractDetail.abi.Events()["PropertySet"],
+				{
+					Type: abi.Function,
+					Name: "registerIdentity",
+					Inputs: abi.ParameterArray{
+						{Name: "different", Type: "address"},
+					},
+				},
+			},
+		}))
+	})
+
+	assert.PanicsWithValue(t, "contract signature has changed: function setIdentityProperty(address different) external { }", func() {
+		mustLoadIdentityRegistryContractDetail(tktypes.JSONString(SolidityBuild{
+			ABI: abi.ABI{
+				contractDetail.abi.Events()["IdentityRegistered"],
+				contractDetail.abi.Events()["PropertySet"],
+				contractDetail.abi.Functions()["registerIdentity"],
+				{
+					Type: abi.Function,
+					Name: "setIdentityProperty",
+					Inputs: abi.ParameterArray{
+						{Name: "different", Type: "address"},
+					},
+				},
+			},
+		}))
+	})
+
 }
 
 func TestBreaksIfBuildIsBroken(t *testing.T) {
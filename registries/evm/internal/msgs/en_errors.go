@@ -36,4 +36,7 @@ var (
 	MsgInvalidRegistryConfig  = ffe("PD060001", "Invalid registry configuration")
 	MsgInvalidRegistryEvent   = ffe("PD060002", "Invalid registry event %+v")
 	MsgMissingContractAddress = ffe("PD060003", "contractAddress is required in registry config")
+	MsgInvalidParentID        = ffe("PD060004", "Invalid parent ID '%s'")
+	MsgInvalidOwnerAddress    = ffe("PD060005", "Invalid owner '%s' - must be an Ethereum address")
+	MsgInvalidEntryID         = ffe("PD060006", "Invalid entry ID '%s'")
 )
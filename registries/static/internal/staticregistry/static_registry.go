@@ -82,6 +82,15 @@ func (r *staticRegistry) HandleRegistryEvents(ctx context.Context, req *prototk.
 	return nil, i18n.NewError(ctx, msgs.MsgFunctionUnsupported)
 }
 
+// The static registry is defined entirely in configuration, so cannot be updated at runtime
+func (r *staticRegistry) RegisterIdentity(ctx context.Context, req *prototk.RegisterIdentityRequest) (*prototk.RegisterIdentityResponse, error) {
+	return nil, i18n.NewError(ctx, msgs.MsgFunctionUnsupported)
+}
+
+func (r *staticRegistry) SetProperty(ctx context.Context, req *prototk.SetPropertyRequest) (*prototk.SetPropertyResponse, error) {
+	return nil, i18n.NewError(ctx, msgs.MsgFunctionUnsupported)
+}
+
 func (r *staticRegistry) recurseBuildUpsert(ctx context.Context, req *prototk.UpsertRegistryRecordsRequest, parentID tktypes.HexBytes, name string, inEntry *StaticEntry) error {
 
 	idHash := sha3.NewLegacyKeccak256()
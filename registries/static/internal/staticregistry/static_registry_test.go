@@ -34,6 +34,10 @@ func (tc *testCallbacks) UpsertRegistryRecords(ctx context.Context, req *prototk
 	return tc.upsertRegistryRecords(ctx, req)
 }
 
+func (tc *testCallbacks) SendTransaction(ctx context.Context, req *prototk.SendTransactionRequest) (*prototk.SendTransactionResponse, error) {
+	panic("unexpected")
+}
+
 func TestPluginLifecycle(t *testing.T) {
 	pb := NewPlugin()
 	assert.NotNil(t, pb)
@@ -190,6 +194,17 @@ func TestRegistryEventBatch(t *testing.T) {
 
 }
 
+func TestRegistryPublishUnsupported(t *testing.T) {
+
+	callbacks := &testCallbacks{}
+	transport := NewStatic(callbacks).(*staticRegistry)
+	_, err := transport.RegisterIdentity(context.Background(), &prototk.RegisterIdentityRequest{})
+	assert.Regexp(t, "PD040002", err)
+	_, err = transport.SetProperty(context.Background(), &prototk.SetPropertyRequest{})
+	assert.Regexp(t, "PD040002", err)
+
+}
+
 func TestRegistryUpsertBadData(t *testing.T) {
 	callbacks := &testCallbacks{}
 	transport := NewStatic(callbacks).(*staticRegistry)
@@ -31,6 +31,66 @@ interface INotoPrivate {
         address delegate
     ) external;
 
+    function pause(
+        string[] calldata parties,
+        bytes calldata data
+    ) external;
+
+    function unpause(
+        string[] calldata parties,
+        bytes calldata data
+    ) external;
+
+    function freeze(
+        string calldata account,
+        string[] calldata parties,
+        bytes calldata data
+    ) external;
+
+    function unfreeze(
+        string calldata account,
+        string[] calldata parties,
+        bytes calldata data
+    ) external;
+
+    function forceTransfer(
+        string calldata from,
+        string calldata to,
+        uint256 amount,
+        bytes calldata data
+    ) external;
+
+    function lock(
+        uint256 amount,
+        uint256 expiry,
+        bytes calldata data
+    ) external;
+
+    function delegateLock(
+        bytes32 lockId,
+        string calldata delegate,
+        bytes calldata data
+    ) external;
+
+    function unlock(
+        bytes32 lockId,
+        UnlockRecipient[] calldata recipients,
+        bytes calldata data
+    ) external;
+
+    function balanceOf(
+        string calldata account
+    ) external view returns (
+        uint256 totalStates,
+        uint256 totalBalance,
+        bool overflow
+    );
+
+    struct UnlockRecipient {
+        string to;
+        uint256 amount;
+    }
+
     struct StateEncoded {
         bytes id;
         string domain;
@@ -0,0 +1,45 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pldapi
+
+import (
+	"github.com/google/uuid"
+	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
+)
+
+// A domain plugin loaded by this node
+type Domain struct {
+	Name            string              `docstruct:"Domain" json:"name"`            // the name of the domain, as configured on this node
+	RegistryAddress *tktypes.EthAddress `docstruct:"Domain" json:"registryAddress"` // the address of the factory contract that registers smart contracts for the domain
+}
+
+// A private smart contract deployed through a domain, as indexed by this node
+type DomainSmartContract struct {
+	DomainName        string                `docstruct:"DomainSmartContract" json:"domainName,omitempty"` // unset if the domain registry is not configured on this node
+	DomainAddress     tktypes.EthAddress    `docstruct:"DomainSmartContract" json:"domainAddress"`
+	Address           tktypes.EthAddress    `docstruct:"DomainSmartContract" json:"address"`
+	DeployTransaction uuid.UUID             `docstruct:"DomainSmartContract" json:"deployTransaction"`
+	Config            *DomainContractConfig `docstruct:"DomainSmartContract" json:"config,omitempty"` // unset if the domain is not configured, or rejected the contract config
+}
+
+// The configuration of a private smart contract, as decoded by its domain
+type DomainContractConfig struct {
+	ContractConfigJSON   tktypes.RawJSON `docstruct:"DomainContractConfig" json:"contractConfig"`
+	CoordinatorSelection string          `docstruct:"DomainContractConfig" json:"coordinatorSelection"`
+	StaticCoordinator    *string         `docstruct:"DomainContractConfig" json:"staticCoordinator,omitempty"`
+	SubmitterSelection   string          `docstruct:"DomainContractConfig" json:"submitterSelection"`
+}
@@ -0,0 +1,49 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pldapi
+
+import (
+	"github.com/google/uuid"
+	"github.com/hyperledger/firefly-signer/pkg/abi"
+	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
+)
+
+type EventStream struct {
+	ID      uuid.UUID                 `docstruct:"EventStream" json:"id"`
+	Name    string                    `docstruct:"EventStream" json:"name"`
+	Created tktypes.Timestamp         `docstruct:"EventStream" json:"created"`
+	Started *bool                     `docstruct:"EventStream" json:"started"`
+	Sources []EventStreamSource       `docstruct:"EventStream" json:"sources"`
+	Config  EventStreamConfig         `docstruct:"EventStream" json:"config"`
+	Format  tktypes.JSONFormatOptions `docstruct:"EventStream" json:"format,omitempty"`
+}
+
+type EventStreamSource struct {
+	ABI     abi.ABI             `docstruct:"EventStreamSource" json:"abi,omitempty"`
+	Address *tktypes.EthAddress `docstruct:"EventStreamSource" json:"address,omitempty"`
+}
+
+type EventStreamConfig struct {
+	BatchSize    *int            `docstruct:"EventStreamConfig" json:"batchSize,omitempty"`
+	BatchTimeout *string         `docstruct:"EventStreamConfig" json:"batchTimeout,omitempty"`
+	FromBlock    tktypes.RawJSON `docstruct:"EventStreamConfig" json:"fromBlock,omitempty"`
+}
+
+type EventStreamBatch struct {
+	BatchID uuid.UUID        `docstruct:"EventStreamBatch" json:"batchId"`
+	Events  []*EventWithData `docstruct:"EventStreamBatch" json:"events"`
+}
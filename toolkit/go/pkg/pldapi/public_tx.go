@@ -47,6 +47,15 @@ type PublicTxInput struct {
 	PublicTxOptions
 }
 
+// Updates to a pending public transaction, which are submitted to the chain as a replacement
+// transaction with the same nonce. Any fields not supplied are unchanged.
+// If no gas pricing is supplied, the gas price of the previous submission is increased.
+// Supplied gas pricing is raised to the minimum increase over the previous submission the chain requires.
+type PublicTxUpdate struct {
+	Data tktypes.HexBytes `docstruct:"PublicTxUpdate" json:"data,omitempty"` // the new pre-encoded calldata
+	PublicTxOptions
+}
+
 type PublicTxSubmission struct {
 	From  tktypes.EthAddress `docstruct:"PublicTxSubmission" json:"from"`
 	Nonce tktypes.HexUint64  `docstruct:"PublicTxSubmission" json:"nonce"`
@@ -37,6 +37,22 @@ type RegistryProperty struct {
 	*ActiveFlag      `json:",omitempty"` // only returned from queries that explicitly look for inactive entries
 }
 
+// Request to register a new entry in a registry, by submitting a transaction built by the registry plugin
+type RegistryIdentityInput struct {
+	From     string           `docstruct:"RegistryIdentityInput" json:"from"`               // the key identifier that signs the registration, which must be authorized by the registry
+	ParentID tktypes.HexBytes `docstruct:"RegistryIdentityInput" json:"parentId,omitempty"` // nil for a root record, otherwise the ID of the parent entry
+	Name     string           `docstruct:"RegistryIdentityInput" json:"name"`               // unique across entries with the same parent
+	Owner    string           `docstruct:"RegistryIdentityInput" json:"owner"`              // the owner of the new entry, in the format understood by the registry
+}
+
+// Request to set a property on an existing entry, by submitting a transaction built by the registry plugin
+type RegistryPropertyInput struct {
+	From    string           `docstruct:"RegistryPropertyInput" json:"from"`    // the key identifier that signs the update, which must be authorized by the registry
+	EntryID tktypes.HexBytes `docstruct:"RegistryPropertyInput" json:"entryId"` // the ID of the entry that owns the property
+	Name    string           `docstruct:"RegistryPropertyInput" json:"name"`    // the name of the property
+	Value   string           `docstruct:"RegistryPropertyInput" json:"value"`   // the value of the property
+}
+
 type ActiveFlag struct {
 	Active bool `docstruct:"ActiveFlag" json:"active"`
 }
@@ -171,6 +171,7 @@ type StateConfirmRecord struct {
 	DomainName  string           `json:"-"                 gorm:"primaryKey"`
 	State       tktypes.HexBytes `json:"-"                 gorm:"primaryKey"`
 	Transaction uuid.UUID        `docstruct:"StateConfirm" json:"transaction"`
+	BlockNumber *int64           `json:"-"` // the block the record was indexed from, so it can be reverted on a chain re-org
 }
 
 // A spend record is written when indexing the blockchain, and can be written regardless
@@ -188,6 +189,7 @@ type StateSpendRecord struct {
 	DomainName  string           `json:"-"                 gorm:"primaryKey"`
 	State       tktypes.HexBytes `json:"-"                 gorm:"primaryKey"`
 	Transaction uuid.UUID        `docstruct:"StateSpend" json:"transaction"`
+	BlockNumber *int64           `json:"-"`
 }
 
 // We also record when we simply read a state during a transaction, without creating or
@@ -197,6 +199,7 @@ type StateReadRecord struct {
 	DomainName  string           `json:"-"                 gorm:"primaryKey"`
 	State       tktypes.HexBytes `json:"-"                 gorm:"primaryKey"`
 	Transaction uuid.UUID        `docstruct:"StateRead" json:"transaction"`
+	BlockNumber *int64           `json:"-"`
 }
 
 // Transactions can also refer to state that never exists before or after the transaction.
@@ -206,6 +209,7 @@ type StateInfoRecord struct {
 	DomainName  string           `json:"-"                 gorm:"primaryKey"`
 	State       tktypes.HexBytes `json:"-"                 gorm:"primaryKey"`
 	Transaction uuid.UUID        `docstruct:"StateConfirm" json:"transaction"`
+	BlockNumber *int64           `json:"-"`
 }
 
 type StateLockType string
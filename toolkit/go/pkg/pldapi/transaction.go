@@ -120,7 +120,8 @@ type ABIDecodedData struct {
 }
 
 type TransactionReceipt struct {
-	ID uuid.UUID `docstruct:"TransactionReceipt" json:"id,omitempty"` // transaction ID
+	ID       uuid.UUID `docstruct:"TransactionReceipt" json:"id,omitempty"`       // transaction ID
+	Sequence uint64    `docstruct:"TransactionReceipt" json:"sequence,omitempty"` // local sequence number assigned when the receipt was written, used for ordered delivery to listeners
 	TransactionReceiptData
 }
 
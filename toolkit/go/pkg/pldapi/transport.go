@@ -0,0 +1,49 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pldapi
+
+import "github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
+
+// A remote node this node has exchanged messages with since it started, or has reliable messages queued for
+type PeerInfo struct {
+	Name        string                     `docstruct:"PeerInfo" json:"name"`                  // the name of the remote node
+	Activated   tktypes.Timestamp          `docstruct:"PeerInfo" json:"activated"`             // when this node first sent to, or received from, the peer since starting
+	Outbound    *PeerOutbound              `docstruct:"PeerInfo" json:"outbound,omitempty"`    // the registry entry and transport resolved for sending to the peer
+	Connections map[string]tktypes.RawJSON `docstruct:"PeerInfo" json:"connections,omitempty"` // transport specific connection state reported by each transport plugin that has one for the peer
+	Stats       PeerStats                  `docstruct:"PeerInfo" json:"stats"`
+	Errors      []*PeerError               `docstruct:"PeerInfo" json:"errors,omitempty"` // the most recent send and receive errors, newest first
+}
+
+type PeerOutbound struct {
+	Transport string `docstruct:"PeerOutbound" json:"transport"` // the name of the local transport used to send to the peer
+	Registry  string `docstruct:"PeerOutbound" json:"registry"`  // the registry the transport details of the peer were resolved from
+}
+
+type PeerStats struct {
+	SentMsgs         uint64             `docstruct:"PeerStats" json:"sentMsgs"`
+	SendErrors       uint64             `docstruct:"PeerStats" json:"sendErrors"`
+	LastSend         *tktypes.Timestamp `docstruct:"PeerStats" json:"lastSend,omitempty"`
+	ReceivedMsgs     uint64             `docstruct:"PeerStats" json:"receivedMsgs"`
+	LastReceive      *tktypes.Timestamp `docstruct:"PeerStats" json:"lastReceive,omitempty"`
+	ReliableQueued   int64              `docstruct:"PeerStats" json:"reliableQueued"`   // reliable messages persisted for the peer that are not yet acknowledged
+	ReliableInFlight int                `docstruct:"PeerStats" json:"reliableInFlight"` // reliable messages sent to the peer that are awaiting acknowledgement
+}
+
+type PeerError struct {
+	Time    tktypes.Timestamp `docstruct:"PeerError" json:"time"`
+	Message string            `docstruct:"PeerError" json:"message"`
+}
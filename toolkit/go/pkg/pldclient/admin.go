@@ -0,0 +1,74 @@
+/*
+ * Copyright © 2024 Kaleido, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package pldclient
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
+)
+
+type Admin interface {
+	RPCModule
+
+	FailPrivateTransaction(ctx context.Context, contractAddress tktypes.EthAddress, txID uuid.UUID, reason string) (success bool, err error)
+	ReassemblePrivateTransaction(ctx context.Context, contractAddress tktypes.EthAddress, txID uuid.UUID) (success bool, err error)
+	EvictSequencer(ctx context.Context, contractAddress tktypes.EthAddress, reason string) (failedTransactions []uuid.UUID, err error)
+}
+
+// This is necessary because there's no way to introspect function parameter names via reflection
+var adminInfo = &rpcModuleInfo{
+	group: "admin",
+	methodInfo: map[string]RPCMethodInfo{
+		"admin_failPrivateTransaction": {
+			Inputs: []string{"contractAddress", "txId", "reason"},
+			Output: "success",
+		},
+		"admin_reassemblePrivateTransaction": {
+			Inputs: []string{"contractAddress", "txId"},
+			Output: "success",
+		},
+		"admin_evictSequencer": {
+			Inputs: []string{"contractAddress", "reason"},
+			Output: "failedTransactions",
+		},
+	},
+}
+
+type admin struct {
+	*rpcModuleInfo
+	c *paladinClient
+}
+
+func (c *paladinClient) Admin() Admin {
+	return &admin{rpcModuleInfo: adminInfo, c: c}
+}
+
+func (a *admin) FailPrivateTransaction(ctx context.Context, contractAddress tktypes.EthAddress, txID uuid.UUID, reason string) (success bool, err error) {
+	err = a.c.CallRPC(ctx, &success, "admin_failPrivateTransaction", contractAddress, txID, reason)
+	return
+}
+
+func (a *admin) ReassemblePrivateTransaction(ctx context.Context, contractAddress tktypes.EthAddress, txID uuid.UUID) (success bool, err error) {
+	err = a.c.CallRPC(ctx, &success, "admin_reassemblePrivateTransaction", contractAddress, txID)
+	return
+}
+
+func (a *admin) EvictSequencer(ctx context.Context, contractAddress tktypes.EthAddress, reason string) (failedTransactions []uuid.UUID, err error) {
+	err = a.c.CallRPC(ctx, &failedTransactions, "admin_evictSequencer", contractAddress, reason)
+	return
+}
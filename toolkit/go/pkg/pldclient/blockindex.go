@@ -72,6 +72,30 @@ var blockIndexInfo = &rpcModuleInfo{
 			Inputs: []string{"transactionHash", "abi", "resultFormat"},
 			Output: "events",
 		},
+		"bidx_createEventStream": {
+			Inputs: []string{"stream"},
+			Output: "stream",
+		},
+		"bidx_queryEventStreams": {
+			Inputs: []string{"query"},
+			Output: "streams",
+		},
+		"bidx_getEventStream": {
+			Inputs: []string{"streamName"},
+			Output: "stream",
+		},
+		"bidx_startEventStream": {
+			Inputs: []string{"streamName"},
+			Output: "success",
+		},
+		"bidx_stopEventStream": {
+			Inputs: []string{"streamName"},
+			Output: "success",
+		},
+		"bidx_deleteEventStream": {
+			Inputs: []string{"streamName"},
+			Output: "success",
+		},
 	},
 }
 
@@ -133,3 +157,33 @@ func (r *blockIndex) DecodeTransactionEvents(ctx context.Context, transactionHas
 	err = r.c.CallRPC(ctx, &events, "bidx_decodeTransactionEvents", transactionHash, abi, resultFormat)
 	return
 }
+
+func (r *blockIndex) CreateEventStream(ctx context.Context, stream *pldapi.EventStream) (created *pldapi.EventStream, err error) {
+	err = r.c.CallRPC(ctx, &created, "bidx_createEventStream", stream)
+	return
+}
+
+func (r *blockIndex) QueryEventStreams(ctx context.Context, query *query.QueryJSON) (streams []*pldapi.EventStream, err error) {
+	err = r.c.CallRPC(ctx, &streams, "bidx_queryEventStreams", query)
+	return
+}
+
+func (r *blockIndex) GetEventStream(ctx context.Context, streamName string) (stream *pldapi.EventStream, err error) {
+	err = r.c.CallRPC(ctx, &stream, "bidx_getEventStream", streamName)
+	return
+}
+
+func (r *blockIndex) StartEventStream(ctx context.Context, streamName string) (success bool, err error) {
+	err = r.c.CallRPC(ctx, &success, "bidx_startEventStream", streamName)
+	return
+}
+
+func (r *blockIndex) StopEventStream(ctx context.Context, streamName string) (success bool, err error) {
+	err = r.c.CallRPC(ctx, &success, "bidx_stopEventStream", streamName)
+	return
+}
+
+func (r *blockIndex) DeleteEventStream(ctx context.Context, streamName string) (success bool, err error) {
+	err = r.c.CallRPC(ctx, &success, "bidx_deleteEventStream", streamName)
+	return
+}
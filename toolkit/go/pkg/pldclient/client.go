@@ -54,11 +54,17 @@ type PaladinClient interface {
 	// Paladin Registry RPC interface
 	Registry() Registry
 
+	// Paladin Domain RPC interface
+	Domain() Domain
+
 	// Paladin state store RPC interface
 	StateStore() StateStore
 
 	// Paladin block index
 	BlockIndex() BlockIndex
+
+	// Paladin administrative actions on private transactions
+	Admin() Admin
 }
 
 type RPCModule interface {
@@ -0,0 +1,64 @@
+/*
+ * Copyright © 2024 Kaleido, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package pldclient
+
+import (
+	"context"
+
+	"github.com/kaleido-io/paladin/toolkit/pkg/pldapi"
+	"github.com/kaleido-io/paladin/toolkit/pkg/query"
+)
+
+type Domain interface {
+	RPCModule
+
+	ListDomains(ctx context.Context) (domains []*pldapi.Domain, err error)
+	QuerySmartContracts(ctx context.Context, jq query.QueryJSON) (contracts []*pldapi.DomainSmartContract, err error)
+}
+
+// This is necessary because there's no way to introspect function parameter names via reflection
+var domainInfo = &rpcModuleInfo{
+	group: "domain",
+	methodInfo: map[string]RPCMethodInfo{
+		"domain_listDomains": {
+			Inputs: []string{},
+			Output: "domains",
+		},
+		"domain_querySmartContracts": {
+			Inputs: []string{"query"},
+			Output: "contracts",
+		},
+	},
+}
+
+type domain struct {
+	*rpcModuleInfo
+	c *paladinClient
+}
+
+func (c *paladinClient) Domain() Domain {
+	return &domain{rpcModuleInfo: domainInfo, c: c}
+}
+
+func (d *domain) ListDomains(ctx context.Context) (domains []*pldapi.Domain, err error) {
+	err = d.c.CallRPC(ctx, &domains, "domain_listDomains")
+	return
+}
+
+func (d *domain) QuerySmartContracts(ctx context.Context, jq query.QueryJSON) (contracts []*pldapi.DomainSmartContract, err error) {
+	err = d.c.CallRPC(ctx, &contracts, "domain_querySmartContracts", jq)
+	return
+}
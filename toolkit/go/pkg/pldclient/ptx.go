@@ -56,6 +56,13 @@ type PTX interface {
 	QueryStoredABIs(ctx context.Context, jq *query.QueryJSON) (storedABIs []*pldapi.StoredABI, err error)
 
 	ResolveVerifier(ctx context.Context, keyIdentifier string, algorithm string, verifierType string) (verifier string, err error)
+
+	CreateReceiptListener(ctx context.Context, listener *pldapi.TransactionReceiptListener) (success bool, err error)
+	QueryReceiptListeners(ctx context.Context, jq *query.QueryJSON) (listeners []*pldapi.TransactionReceiptListener, err error)
+	GetReceiptListener(ctx context.Context, listenerName string) (listener *pldapi.TransactionReceiptListener, err error)
+	StartReceiptListener(ctx context.Context, listenerName string) (success bool, err error)
+	StopReceiptListener(ctx context.Context, listenerName string) (success bool, err error)
+	DeleteReceiptListener(ctx context.Context, listenerName string) (success bool, err error)
 }
 
 // This is necessary because there's no way to introspect function parameter names via reflection
@@ -158,6 +165,30 @@ var ptxInfo = &rpcModuleInfo{
 			Inputs: []string{"keyIdentifier", "algorithm", "verifierType"},
 			Output: "verifier",
 		},
+		"ptx_createReceiptListener": {
+			Inputs: []string{"listener"},
+			Output: "success",
+		},
+		"ptx_queryReceiptListeners": {
+			Inputs: []string{"query"},
+			Output: "listeners",
+		},
+		"ptx_getReceiptListener": {
+			Inputs: []string{"listenerName"},
+			Output: "listener",
+		},
+		"ptx_startReceiptListener": {
+			Inputs: []string{"listenerName"},
+			Output: "success",
+		},
+		"ptx_stopReceiptListener": {
+			Inputs: []string{"listenerName"},
+			Output: "success",
+		},
+		"ptx_deleteReceiptListener": {
+			Inputs: []string{"listenerName"},
+			Output: "success",
+		},
 	},
 }
 
@@ -289,3 +320,33 @@ func (p *ptx) ResolveVerifier(ctx context.Context, keyIdentifier string, algorit
 	err = p.c.CallRPC(ctx, &verifier, "ptx_resolveVerifier", keyIdentifier, algorithm, verifierType)
 	return
 }
+
+func (p *ptx) CreateReceiptListener(ctx context.Context, listener *pldapi.TransactionReceiptListener) (success bool, err error) {
+	err = p.c.CallRPC(ctx, &success, "ptx_createReceiptListener", listener)
+	return
+}
+
+func (p *ptx) QueryReceiptListeners(ctx context.Context, jq *query.QueryJSON) (listeners []*pldapi.TransactionReceiptListener, err error) {
+	err = p.c.CallRPC(ctx, &listeners, "ptx_queryReceiptListeners", jq)
+	return
+}
+
+func (p *ptx) GetReceiptListener(ctx context.Context, listenerName string) (listener *pldapi.TransactionReceiptListener, err error) {
+	err = p.c.CallRPC(ctx, &listener, "ptx_getReceiptListener", listenerName)
+	return
+}
+
+func (p *ptx) StartReceiptListener(ctx context.Context, listenerName string) (success bool, err error) {
+	err = p.c.CallRPC(ctx, &success, "ptx_startReceiptListener", listenerName)
+	return
+}
+
+func (p *ptx) StopReceiptListener(ctx context.Context, listenerName string) (success bool, err error) {
+	err = p.c.CallRPC(ctx, &success, "ptx_stopReceiptListener", listenerName)
+	return
+}
+
+func (p *ptx) DeleteReceiptListener(ctx context.Context, listenerName string) (success bool, err error) {
+	err = p.c.CallRPC(ctx, &success, "ptx_deleteReceiptListener", listenerName)
+	return
+}
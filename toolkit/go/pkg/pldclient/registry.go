@@ -18,6 +18,7 @@ package pldclient
 import (
 	"context"
 
+	"github.com/google/uuid"
 	"github.com/kaleido-io/paladin/toolkit/pkg/pldapi"
 	"github.com/kaleido-io/paladin/toolkit/pkg/query"
 	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
@@ -30,6 +31,8 @@ type Registry interface {
 	QueryEntries(ctx context.Context, registryName string, jq query.QueryJSON, activeFilter tktypes.Enum[pldapi.ActiveFilter]) (entries []*pldapi.RegistryEntry, err error)
 	QueryEntriesWithProps(ctx context.Context, registryName string, jq query.QueryJSON, activeFilter tktypes.Enum[pldapi.ActiveFilter]) (entries []*pldapi.RegistryEntryWithProperties, err error)
 	GetEntryProperties(ctx context.Context, registryName string, entryID tktypes.HexBytes, activeFilter tktypes.Enum[pldapi.ActiveFilter]) (entries []*pldapi.RegistryProperty, err error)
+	RegisterIdentity(ctx context.Context, registryName string, identity *pldapi.RegistryIdentityInput) (txID *uuid.UUID, err error)
+	SetProperty(ctx context.Context, registryName string, property *pldapi.RegistryPropertyInput) (txID *uuid.UUID, err error)
 }
 
 // This is necessary because there's no way to introspect function parameter names via reflection
@@ -52,6 +55,14 @@ var registryInfo = &rpcModuleInfo{
 			Inputs: []string{"registryName", "entryId", "activeFilter"},
 			Output: "properties",
 		},
+		"reg_registerIdentity": {
+			Inputs: []string{"registryName", "identity"},
+			Output: "transactionId",
+		},
+		"reg_setProperty": {
+			Inputs: []string{"registryName", "property"},
+			Output: "transactionId",
+		},
 	},
 }
 
@@ -83,3 +94,13 @@ func (r *registry) GetEntryProperties(ctx context.Context, registryName string,
 	err = r.c.CallRPC(ctx, &properties, "reg_getEntryProperties", registryName, entryID, activeFilter)
 	return
 }
+
+func (r *registry) RegisterIdentity(ctx context.Context, registryName string, identity *pldapi.RegistryIdentityInput) (txID *uuid.UUID, err error) {
+	err = r.c.CallRPC(ctx, &txID, "reg_registerIdentity", registryName, identity)
+	return
+}
+
+func (r *registry) SetProperty(ctx context.Context, registryName string, property *pldapi.RegistryPropertyInput) (txID *uuid.UUID, err error) {
+	err = r.c.CallRPC(ctx, &txID, "reg_setProperty", registryName, property)
+	return
+}
@@ -17,6 +17,8 @@ package pldclient
 
 import (
 	"context"
+
+	"github.com/kaleido-io/paladin/toolkit/pkg/pldapi"
 )
 
 type Transport interface {
@@ -24,6 +26,8 @@ type Transport interface {
 
 	NodeName(ctx context.Context) (nodeName string, err error)
 	LocalTransports(ctx context.Context) (transportNames []string, err error)
+	Peers(ctx context.Context) (peers []*pldapi.PeerInfo, err error)
+	PeerInfo(ctx context.Context, nodeName string) (peer *pldapi.PeerInfo, err error)
 }
 
 // This is necessary because there's no way to introspect function parameter names via reflection
@@ -42,6 +46,14 @@ var transportInfo = &rpcModuleInfo{
 			Inputs: []string{"transportName"},
 			Output: "transportDetailsStr",
 		},
+		"transport_peers": {
+			Inputs: []string{},
+			Output: "peers",
+		},
+		"transport_peerInfo": {
+			Inputs: []string{"nodeName"},
+			Output: "peer",
+		},
 	},
 }
 
@@ -68,3 +80,13 @@ func (t *transport) LocalTransportDetails(ctx context.Context, transportName str
 	err = t.c.CallRPC(ctx, &transportDetailsStr, "transport_localTransportDetails", transportName)
 	return
 }
+
+func (t *transport) Peers(ctx context.Context) (peers []*pldapi.PeerInfo, err error) {
+	err = t.c.CallRPC(ctx, &peers, "transport_peers")
+	return
+}
+
+func (t *transport) PeerInfo(ctx context.Context, nodeName string) (peer *pldapi.PeerInfo, err error) {
+	err = t.c.CallRPC(ctx, &peer, "transport_peerInfo", nodeName)
+	return
+}
@@ -41,6 +41,7 @@ type DomainAPI interface {
 	InitCall(context.Context, *prototk.InitCallRequest) (*prototk.InitCallResponse, error)
 	ExecCall(context.Context, *prototk.ExecCallRequest) (*prototk.ExecCallResponse, error)
 	BuildReceipt(context.Context, *prototk.BuildReceiptRequest) (*prototk.BuildReceiptResponse, error)
+	HandleRPC(context.Context, *prototk.HandleRPCRequest) (*prototk.HandleRPCResponse, error)
 }
 
 type DomainCallbacks interface {
@@ -48,6 +49,7 @@ type DomainCallbacks interface {
 	EncodeData(context.Context, *prototk.EncodeDataRequest) (*prototk.EncodeDataResponse, error)
 	DecodeData(context.Context, *prototk.DecodeDataRequest) (*prototk.DecodeDataResponse, error)
 	RecoverSigner(ctx context.Context, req *prototk.RecoverSignerRequest) (*prototk.RecoverSignerResponse, error)
+	QueryContractEvents(ctx context.Context, req *prototk.QueryContractEventsRequest) (*prototk.QueryContractEventsResponse, error)
 }
 
 type DomainFactory func(callbacks DomainCallbacks) DomainAPI
@@ -195,6 +197,10 @@ func (dp *domainHandler) RequestToPlugin(ctx context.Context, iReq PluginMessage
 		resMsg := &prototk.DomainMessage_BuildReceiptRes{}
 		resMsg.BuildReceiptRes, err = dp.api.BuildReceipt(ctx, input.BuildReceipt)
 		res.ResponseFromDomain = resMsg
+	case *prototk.DomainMessage_HandleRpc:
+		resMsg := &prototk.DomainMessage_HandleRpcRes{}
+		resMsg.HandleRpcRes, err = dp.api.HandleRPC(ctx, input.HandleRpc)
+		res.ResponseFromDomain = resMsg
 	default:
 		err = i18n.NewError(ctx, tkmsgs.MsgPluginUnsupportedRequest, input)
 	}
@@ -245,6 +251,17 @@ func (dp *domainHandler) RecoverSigner(ctx context.Context, req *prototk.Recover
 	})
 }
 
+func (dp *domainHandler) QueryContractEvents(ctx context.Context, req *prototk.QueryContractEventsRequest) (*prototk.QueryContractEventsResponse, error) {
+	res, err := dp.proxy.RequestFromPlugin(ctx, dp.Wrap(&prototk.DomainMessage{
+		RequestFromDomain: &prototk.DomainMessage_QueryContractEvents{
+			QueryContractEvents: req,
+		},
+	}))
+	return responseToPluginAs(ctx, res, err, func(msg *prototk.DomainMessage_QueryContractEventsRes) *prototk.QueryContractEventsResponse {
+		return msg.QueryContractEventsRes
+	})
+}
+
 type DomainAPIFunctions struct {
 	ConfigureDomain     func(context.Context, *prototk.ConfigureDomainRequest) (*prototk.ConfigureDomainResponse, error)
 	InitDomain          func(context.Context, *prototk.InitDomainRequest) (*prototk.InitDomainResponse, error)
@@ -262,6 +279,7 @@ type DomainAPIFunctions struct {
 	InitCall            func(context.Context, *prototk.InitCallRequest) (*prototk.InitCallResponse, error)
 	ExecCall            func(context.Context, *prototk.ExecCallRequest) (*prototk.ExecCallResponse, error)
 	BuildReceipt        func(context.Context, *prototk.BuildReceiptRequest) (*prototk.BuildReceiptResponse, error)
+	HandleRPC           func(context.Context, *prototk.HandleRPCRequest) (*prototk.HandleRPCResponse, error)
 }
 
 type DomainAPIBase struct {
@@ -331,3 +349,7 @@ func (db *DomainAPIBase) ExecCall(ctx context.Context, req *prototk.ExecCallRequ
 func (db *DomainAPIBase) BuildReceipt(ctx context.Context, req *prototk.BuildReceiptRequest) (*prototk.BuildReceiptResponse, error) {
 	return callPluginImpl(ctx, req, db.Functions.BuildReceipt)
 }
+
+func (db *DomainAPIBase) HandleRPC(ctx context.Context, req *prototk.HandleRPCRequest) (*prototk.HandleRPCResponse, error) {
+	return callPluginImpl(ctx, req, db.Functions.HandleRPC)
+}
@@ -114,6 +114,19 @@ func TestDomainCallback_RecoverSigner(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestDomainCallback_QueryContractEvents(t *testing.T) {
+	ctx, _, _, callbacks, inOutMap, done := setupDomainTests(t)
+	defer done()
+
+	inOutMap[fmt.Sprintf("%T", &prototk.DomainMessage_QueryContractEvents{})] = func(dm *prototk.DomainMessage) {
+		dm.ResponseToDomain = &prototk.DomainMessage_QueryContractEventsRes{
+			QueryContractEventsRes: &prototk.QueryContractEventsResponse{},
+		}
+	}
+	_, err := callbacks.QueryContractEvents(ctx, &prototk.QueryContractEventsRequest{})
+	require.NoError(t, err)
+}
+
 func TestDomainFunction_ConfigureDomain(t *testing.T) {
 	_, exerciser, funcs, _, _, done := setupDomainTests(t)
 	defer done()
@@ -386,6 +399,23 @@ func TestDomainFunction_BuildReceipt(t *testing.T) {
 	})
 }
 
+func TestDomainFunction_HandleRPC(t *testing.T) {
+	_, exerciser, funcs, _, _, done := setupDomainTests(t)
+	defer done()
+
+	// HandleRPC - paladin to domain
+	funcs.HandleRPC = func(ctx context.Context, cdr *prototk.HandleRPCRequest) (*prototk.HandleRPCResponse, error) {
+		return &prototk.HandleRPCResponse{}, nil
+	}
+	exerciser.doExchangeToPlugin(func(req *prototk.DomainMessage) {
+		req.RequestToDomain = &prototk.DomainMessage_HandleRpc{
+			HandleRpc: &prototk.HandleRPCRequest{},
+		}
+	}, func(res *prototk.DomainMessage) {
+		assert.IsType(t, &prototk.DomainMessage_HandleRpcRes{}, res.ResponseFromDomain)
+	})
+}
+
 func TestDomainRequestError(t *testing.T) {
 	_, exerciser, _, _, _, done := setupDomainTests(t)
 	defer done()
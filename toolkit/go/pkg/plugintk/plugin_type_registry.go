@@ -27,10 +27,13 @@ import (
 type RegistryAPI interface {
 	ConfigureRegistry(context.Context, *prototk.ConfigureRegistryRequest) (*prototk.ConfigureRegistryResponse, error)
 	HandleRegistryEvents(context.Context, *prototk.HandleRegistryEventsRequest) (*prototk.HandleRegistryEventsResponse, error)
+	RegisterIdentity(context.Context, *prototk.RegisterIdentityRequest) (*prototk.RegisterIdentityResponse, error)
+	SetProperty(context.Context, *prototk.SetPropertyRequest) (*prototk.SetPropertyResponse, error)
 }
 
 type RegistryCallbacks interface {
 	UpsertRegistryRecords(context.Context, *prototk.UpsertRegistryRecordsRequest) (*prototk.UpsertRegistryRecordsResponse, error)
+	SendTransaction(context.Context, *prototk.SendTransactionRequest) (*prototk.SendTransactionResponse, error)
 }
 
 type RegistryFactory func(callbacks RegistryCallbacks) RegistryAPI
@@ -122,6 +125,14 @@ func (th *registryHandler) RequestToPlugin(ctx context.Context, iReq PluginMessa
 		resMsg := &prototk.RegistryMessage_HandleRegistryEventsRes{}
 		resMsg.HandleRegistryEventsRes, err = th.api.HandleRegistryEvents(ctx, input.HandleRegistryEvents)
 		res.ResponseFromRegistry = resMsg
+	case *prototk.RegistryMessage_RegisterIdentity:
+		resMsg := &prototk.RegistryMessage_RegisterIdentityRes{}
+		resMsg.RegisterIdentityRes, err = th.api.RegisterIdentity(ctx, input.RegisterIdentity)
+		res.ResponseFromRegistry = resMsg
+	case *prototk.RegistryMessage_SetProperty:
+		resMsg := &prototk.RegistryMessage_SetPropertyRes{}
+		resMsg.SetPropertyRes, err = th.api.SetProperty(ctx, input.SetProperty)
+		res.ResponseFromRegistry = resMsg
 	default:
 		err = i18n.NewError(ctx, tkmsgs.MsgPluginUnsupportedRequest, input)
 	}
@@ -139,9 +150,22 @@ func (dh *registryHandler) UpsertRegistryRecords(ctx context.Context, req *proto
 	})
 }
 
+func (dh *registryHandler) SendTransaction(ctx context.Context, req *prototk.SendTransactionRequest) (*prototk.SendTransactionResponse, error) {
+	res, err := dh.proxy.RequestFromPlugin(ctx, dh.Wrap(&prototk.RegistryMessage{
+		RequestFromRegistry: &prototk.RegistryMessage_SendTransaction{
+			SendTransaction: req,
+		},
+	}))
+	return responseToPluginAs(ctx, res, err, func(msg *prototk.RegistryMessage_SendTransactionRes) *prototk.SendTransactionResponse {
+		return msg.SendTransactionRes
+	})
+}
+
 type RegistryAPIFunctions struct {
 	ConfigureRegistry    func(context.Context, *prototk.ConfigureRegistryRequest) (*prototk.ConfigureRegistryResponse, error)
 	HandleRegistryEvents func(context.Context, *prototk.HandleRegistryEventsRequest) (*prototk.HandleRegistryEventsResponse, error)
+	RegisterIdentity     func(context.Context, *prototk.RegisterIdentityRequest) (*prototk.RegisterIdentityResponse, error)
+	SetProperty          func(context.Context, *prototk.SetPropertyRequest) (*prototk.SetPropertyResponse, error)
 }
 
 type RegistryAPIBase struct {
@@ -155,3 +179,11 @@ func (tb *RegistryAPIBase) ConfigureRegistry(ctx context.Context, req *prototk.C
 func (tb *RegistryAPIBase) HandleRegistryEvents(ctx context.Context, req *prototk.HandleRegistryEventsRequest) (*prototk.HandleRegistryEventsResponse, error) {
 	return callPluginImpl(ctx, req, tb.Functions.HandleRegistryEvents)
 }
+
+func (tb *RegistryAPIBase) RegisterIdentity(ctx context.Context, req *prototk.RegisterIdentityRequest) (*prototk.RegisterIdentityResponse, error) {
+	return callPluginImpl(ctx, req, tb.Functions.RegisterIdentity)
+}
+
+func (tb *RegistryAPIBase) SetProperty(ctx context.Context, req *prototk.SetPropertyRequest) (*prototk.SetPropertyResponse, error) {
+	return callPluginImpl(ctx, req, tb.Functions.SetProperty)
+}
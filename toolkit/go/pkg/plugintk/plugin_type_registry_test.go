@@ -75,6 +75,19 @@ func TestRegistryallback_UpsertRegistryRecords(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestRegistryCallback_SendTransaction(t *testing.T) {
+	ctx, _, _, callbacks, inOutMap, done := setupRegistryTests(t)
+	defer done()
+
+	inOutMap[fmt.Sprintf("%T", &prototk.RegistryMessage_SendTransaction{})] = func(dm *prototk.RegistryMessage) {
+		dm.ResponseToRegistry = &prototk.RegistryMessage_SendTransactionRes{
+			SendTransactionRes: &prototk.SendTransactionResponse{},
+		}
+	}
+	_, err := callbacks.SendTransaction(ctx, &prototk.SendTransactionRequest{})
+	require.NoError(t, err)
+}
+
 func TestRegistryFunction_ConfigureRegistry(t *testing.T) {
 	_, exerciser, funcs, _, _, done := setupRegistryTests(t)
 	defer done()
@@ -109,6 +122,40 @@ func TestRegistryFunction_HandleRegistryEvents(t *testing.T) {
 	})
 }
 
+func TestRegistryFunction_RegisterIdentity(t *testing.T) {
+	_, exerciser, funcs, _, _, done := setupRegistryTests(t)
+	defer done()
+
+	// RegisterIdentity - paladin to registry
+	funcs.RegisterIdentity = func(ctx context.Context, cdr *prototk.RegisterIdentityRequest) (*prototk.RegisterIdentityResponse, error) {
+		return &prototk.RegisterIdentityResponse{}, nil
+	}
+	exerciser.doExchangeToPlugin(func(req *prototk.RegistryMessage) {
+		req.RequestToRegistry = &prototk.RegistryMessage_RegisterIdentity{
+			RegisterIdentity: &prototk.RegisterIdentityRequest{},
+		}
+	}, func(res *prototk.RegistryMessage) {
+		assert.IsType(t, &prototk.RegistryMessage_RegisterIdentityRes{}, res.ResponseFromRegistry)
+	})
+}
+
+func TestRegistryFunction_SetProperty(t *testing.T) {
+	_, exerciser, funcs, _, _, done := setupRegistryTests(t)
+	defer done()
+
+	// SetProperty - paladin to registry
+	funcs.SetProperty = func(ctx context.Context, cdr *prototk.SetPropertyRequest) (*prototk.SetPropertyResponse, error) {
+		return &prototk.SetPropertyResponse{}, nil
+	}
+	exerciser.doExchangeToPlugin(func(req *prototk.RegistryMessage) {
+		req.RequestToRegistry = &prototk.RegistryMessage_SetProperty{
+			SetProperty: &prototk.SetPropertyRequest{},
+		}
+	}, func(res *prototk.RegistryMessage) {
+		assert.IsType(t, &prototk.RegistryMessage_SetPropertyRes{}, res.ResponseFromRegistry)
+	})
+}
+
 func TestRegistryRequestError(t *testing.T) {
 	_, exerciser, _, _, _, done := setupRegistryTests(t)
 	defer done()
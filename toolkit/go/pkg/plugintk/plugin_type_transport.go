@@ -28,6 +28,8 @@ type TransportAPI interface {
 	ConfigureTransport(context.Context, *prototk.ConfigureTransportRequest) (*prototk.ConfigureTransportResponse, error)
 	SendMessage(context.Context, *prototk.SendMessageRequest) (*prototk.SendMessageResponse, error)
 	GetLocalDetails(context.Context, *prototk.GetLocalDetailsRequest) (*prototk.GetLocalDetailsResponse, error)
+	GetPeerInfo(context.Context, *prototk.GetPeerInfoRequest) (*prototk.GetPeerInfoResponse, error)
+	TransportDetailsChanged(context.Context, *prototk.TransportDetailsChangedRequest) (*prototk.TransportDetailsChangedResponse, error)
 }
 
 type TransportCallbacks interface {
@@ -128,6 +130,14 @@ func (th *transportHandler) RequestToPlugin(ctx context.Context, iReq PluginMess
 		resMsg := &prototk.TransportMessage_GetLocalDetailsRes{}
 		resMsg.GetLocalDetailsRes, err = th.api.GetLocalDetails(ctx, input.GetLocalDetails)
 		res.ResponseFromTransport = resMsg
+	case *prototk.TransportMessage_GetPeerInfo:
+		resMsg := &prototk.TransportMessage_GetPeerInfoRes{}
+		resMsg.GetPeerInfoRes, err = th.api.GetPeerInfo(ctx, input.GetPeerInfo)
+		res.ResponseFromTransport = resMsg
+	case *prototk.TransportMessage_TransportDetailsChanged:
+		resMsg := &prototk.TransportMessage_TransportDetailsChangedRes{}
+		resMsg.TransportDetailsChangedRes, err = th.api.TransportDetailsChanged(ctx, input.TransportDetailsChanged)
+		res.ResponseFromTransport = resMsg
 	default:
 		err = i18n.NewError(ctx, tkmsgs.MsgPluginUnsupportedRequest, input)
 	}
@@ -157,9 +167,11 @@ func (th *transportHandler) GetTransportDetails(ctx context.Context, req *protot
 }
 
 type TransportAPIFunctions struct {
-	ConfigureTransport func(context.Context, *prototk.ConfigureTransportRequest) (*prototk.ConfigureTransportResponse, error)
-	SendMessage        func(context.Context, *prototk.SendMessageRequest) (*prototk.SendMessageResponse, error)
-	GetLocalDetails    func(context.Context, *prototk.GetLocalDetailsRequest) (*prototk.GetLocalDetailsResponse, error)
+	ConfigureTransport      func(context.Context, *prototk.ConfigureTransportRequest) (*prototk.ConfigureTransportResponse, error)
+	SendMessage             func(context.Context, *prototk.SendMessageRequest) (*prototk.SendMessageResponse, error)
+	GetLocalDetails         func(context.Context, *prototk.GetLocalDetailsRequest) (*prototk.GetLocalDetailsResponse, error)
+	GetPeerInfo             func(context.Context, *prototk.GetPeerInfoRequest) (*prototk.GetPeerInfoResponse, error)
+	TransportDetailsChanged func(context.Context, *prototk.TransportDetailsChangedRequest) (*prototk.TransportDetailsChangedResponse, error)
 }
 
 type TransportAPIBase struct {
@@ -177,3 +189,11 @@ func (tb *TransportAPIBase) SendMessage(ctx context.Context, req *prototk.SendMe
 func (tb *TransportAPIBase) GetLocalDetails(ctx context.Context, req *prototk.GetLocalDetailsRequest) (*prototk.GetLocalDetailsResponse, error) {
 	return callPluginImpl(ctx, req, tb.Functions.GetLocalDetails)
 }
+
+func (tb *TransportAPIBase) GetPeerInfo(ctx context.Context, req *prototk.GetPeerInfoRequest) (*prototk.GetPeerInfoResponse, error) {
+	return callPluginImpl(ctx, req, tb.Functions.GetPeerInfo)
+}
+
+func (tb *TransportAPIBase) TransportDetailsChanged(ctx context.Context, req *prototk.TransportDetailsChangedRequest) (*prototk.TransportDetailsChangedResponse, error) {
+	return callPluginImpl(ctx, req, tb.Functions.TransportDetailsChanged)
+}
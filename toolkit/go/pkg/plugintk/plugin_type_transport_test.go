@@ -139,6 +139,40 @@ func TestTransportFunction_GetLocalDetails(t *testing.T) {
 	})
 }
 
+func TestTransportFunction_GetPeerInfo(t *testing.T) {
+	_, exerciser, funcs, _, _, done := setupTransportTests(t)
+	defer done()
+
+	// GetPeerInfo - paladin to transport
+	funcs.GetPeerInfo = func(ctx context.Context, gpr *prototk.GetPeerInfoRequest) (*prototk.GetPeerInfoResponse, error) {
+		return &prototk.GetPeerInfoResponse{}, nil
+	}
+	exerciser.doExchangeToPlugin(func(req *prototk.TransportMessage) {
+		req.RequestToTransport = &prototk.TransportMessage_GetPeerInfo{
+			GetPeerInfo: &prototk.GetPeerInfoRequest{},
+		}
+	}, func(res *prototk.TransportMessage) {
+		assert.IsType(t, &prototk.TransportMessage_GetPeerInfoRes{}, res.ResponseFromTransport)
+	})
+}
+
+func TestTransportFunction_TransportDetailsChanged(t *testing.T) {
+	_, exerciser, funcs, _, _, done := setupTransportTests(t)
+	defer done()
+
+	// TransportDetailsChanged - paladin to transport
+	funcs.TransportDetailsChanged = func(ctx context.Context, tdcr *prototk.TransportDetailsChangedRequest) (*prototk.TransportDetailsChangedResponse, error) {
+		return &prototk.TransportDetailsChangedResponse{}, nil
+	}
+	exerciser.doExchangeToPlugin(func(req *prototk.TransportMessage) {
+		req.RequestToTransport = &prototk.TransportMessage_TransportDetailsChanged{
+			TransportDetailsChanged: &prototk.TransportDetailsChangedRequest{},
+		}
+	}, func(res *prototk.TransportMessage) {
+		assert.IsType(t, &prototk.TransportMessage_TransportDetailsChangedRes{}, res.ResponseFromTransport)
+	})
+}
+
 func TestTransportRequestError(t *testing.T) {
 	_, exerciser, _, _, _, done := setupTransportTests(t)
 	defer done()
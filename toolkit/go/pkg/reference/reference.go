@@ -78,6 +78,8 @@ var allTypes = []interface{}{
 	pldapi.IndexedEvent{},
 	pldapi.TransactionReceipt{},
 	pldapi.TransactionReceiptFull{},
+	pldapi.TransactionReceiptListener{},
+	pldapi.TransactionReceiptBatch{},
 	pldapi.TransactionStates{},
 	pldapi.TransactionInput{},
 	pldapi.TransactionFull{},
@@ -107,11 +109,26 @@ var allTypes = []interface{}{
 		},
 	},
 	pldapi.RegistryProperty{},
+	pldapi.RegistryIdentityInput{},
+	pldapi.RegistryPropertyInput{},
+	pldapi.Domain{},
+	pldapi.DomainSmartContract{Config: &pldapi.DomainContractConfig{}},
+	pldapi.DomainContractConfig{},
+	pldapi.PeerInfo{
+		Outbound:    &pldapi.PeerOutbound{},
+		Connections: map[string]tktypes.RawJSON{},
+		Errors:      []*pldapi.PeerError{{}},
+	},
+	pldapi.PeerOutbound{},
+	pldapi.PeerStats{},
+	pldapi.PeerError{},
 	pldapi.OnChainLocation{},
 	pldapi.IndexedBlock{},
 	pldapi.IndexedTransaction{},
 	pldapi.IndexedEvent{},
 	pldapi.EventWithData{},
+	pldapi.EventStream{},
+	pldapi.EventStreamBatch{},
 	pldapi.ABIDecodedData{},
 	tktypes.JSONFormatOptions(""),
 	pldapi.StateStatusQualifier(""),
@@ -218,8 +235,10 @@ var allAPITypes = []pldclient.RPCModule{
 	pldclient.New().KeyManager(),
 	pldclient.New().Registry(),
 	pldclient.New().Transport(),
+	pldclient.New().Domain(),
 	pldclient.New().StateStore(),
 	pldclient.New().BlockIndex(),
+	pldclient.New().Admin(),
 }
 
 var allSimpleTypes = []interface{}{
@@ -0,0 +1,176 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rpcserver
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/kaleido-io/paladin/config/pkg/confutil"
+	"github.com/kaleido-io/paladin/config/pkg/pldconf"
+	"github.com/kaleido-io/paladin/toolkit/pkg/tkmsgs"
+)
+
+// Only asymmetric algorithms are supported, as the verifier only has access to public keys
+var jwtAlgorithms = []jose.SignatureAlgorithm{
+	jose.RS256, jose.RS384, jose.RS512,
+	jose.PS256, jose.PS384, jose.PS512,
+	jose.ES256, jose.ES384, jose.ES512,
+}
+
+const jwtMinRSAKeyBits = 2048
+
+type jwtVerifier struct {
+	issuer         string
+	audience       string
+	principalClaim string
+	clockSkew      time.Duration
+	keys           []*jose.JSONWebKey
+}
+
+func newJWTVerifier(ctx context.Context, conf *pldconf.RPCAuthJWTConfig) (*jwtVerifier, error) {
+	b, err := os.ReadFile(conf.JWKSFile)
+	if err != nil {
+		return nil, i18n.WrapError(ctx, err, tkmsgs.MsgJSONRPCAuthJWKSLoadFailed, conf.JWKSFile)
+	}
+	// We parse each key individually, so we can skip keys that are not for signing
+	// and report which key is invalid
+	var jwks struct {
+		Keys []json.RawMessage `json:"keys"`
+	}
+	if err := json.Unmarshal(b, &jwks); err != nil {
+		return nil, i18n.WrapError(ctx, err, tkmsgs.MsgJSONRPCAuthJWKSLoadFailed, conf.JWKSFile)
+	}
+	v := &jwtVerifier{
+		issuer:         conf.Issuer,
+		audience:       conf.Audience,
+		principalClaim: confutil.StringNotEmpty(conf.PrincipalClaim, *pldconf.RPCAuthJWTDefaults.PrincipalClaim),
+		clockSkew:      confutil.DurationMin(conf.ClockSkew, 0, *pldconf.RPCAuthJWTDefaults.ClockSkew),
+	}
+	for _, rawKey := range jwks.Keys {
+		var keyInfo struct {
+			Kid string `json:"kid"`
+			Use string `json:"use"`
+		}
+		_ = json.Unmarshal(rawKey, &keyInfo)
+		if keyInfo.Use != "" && keyInfo.Use != "sig" {
+			continue
+		}
+		var jwk jose.JSONWebKey
+		if err := json.Unmarshal(rawKey, &jwk); err != nil {
+			return nil, i18n.NewError(ctx, tkmsgs.MsgJSONRPCAuthJWKSInvalidKey, keyInfo.Kid, err)
+		}
+		if err := checkJWTVerificationKey(&jwk); err != nil {
+			return nil, i18n.NewError(ctx, tkmsgs.MsgJSONRPCAuthJWKSInvalidKey, keyInfo.Kid, err)
+		}
+		publicKey := jwk.Public()
+		v.keys = append(v.keys, &publicKey)
+	}
+	if len(v.keys) == 0 {
+		return nil, i18n.NewError(ctx, tkmsgs.MsgJSONRPCAuthJWKSLoadFailed, conf.JWKSFile)
+	}
+	return v, nil
+}
+
+func checkJWTVerificationKey(jwk *jose.JSONWebKey) error {
+	switch key := jwk.Public().Key.(type) {
+	case *rsa.PublicKey:
+		if key.N.BitLen() < jwtMinRSAKeyBits {
+			return fmt.Errorf("RSA key size %d is less than %d bits", key.N.BitLen(), jwtMinRSAKeyBits)
+		}
+		return nil
+	case *ecdsa.PublicKey:
+		return nil
+	default:
+		return fmt.Errorf("unsupported key type")
+	}
+}
+
+// The "typ" header is optional, but if set it must identify the token as a JWT (or an OAuth 2.0 JWT access token)
+func isJWTType(typ interface{}) bool {
+	s, _ := typ.(string)
+	switch strings.TrimPrefix(strings.ToLower(s), "application/") {
+	case "jwt", "at+jwt":
+		return true
+	default:
+		return false
+	}
+}
+
+// verify checks the signature and standard claims of a compact serialized JWT, returning the principal
+func (v *jwtVerifier) verify(ctx context.Context, token string) (string, error) {
+	parsed, err := jwt.ParseSigned(token, jwtAlgorithms)
+	if err != nil {
+		return "", i18n.NewError(ctx, tkmsgs.MsgJSONRPCAuthInvalidJWT, err)
+	}
+	// A compact serialized JWS has exactly one signature
+	header := parsed.Headers[0]
+	if _, ok := header.ExtraHeaders["crit"]; ok {
+		return "", i18n.NewError(ctx, tkmsgs.MsgJSONRPCAuthInvalidJWT, "critical header extensions are not supported")
+	}
+	if typ, ok := header.ExtraHeaders[jose.HeaderType]; ok && !isJWTType(typ) {
+		return "", i18n.NewError(ctx, tkmsgs.MsgJSONRPCAuthInvalidJWT, fmt.Sprintf("unsupported token type '%v'", typ))
+	}
+
+	// Without a key ID, we only accept the token if there is a single key it could be signed with
+	var keys []*jose.JSONWebKey
+	for _, k := range v.keys {
+		if header.KeyID == "" || k.KeyID == header.KeyID {
+			keys = append(keys, k)
+		}
+	}
+	if header.KeyID == "" && len(keys) > 1 {
+		return "", i18n.NewError(ctx, tkmsgs.MsgJSONRPCAuthInvalidJWT, "missing 'kid' header")
+	}
+	var claims jwt.Claims
+	var allClaims map[string]interface{}
+	verified := false
+	for _, k := range keys {
+		if err := parsed.Claims(k.Key, &claims, &allClaims); err == nil {
+			verified = true
+			break
+		}
+	}
+	if !verified {
+		return "", i18n.NewError(ctx, tkmsgs.MsgJSONRPCAuthInvalidJWT, "signature verification failed")
+	}
+
+	if claims.Expiry == nil {
+		return "", i18n.NewError(ctx, tkmsgs.MsgJSONRPCAuthInvalidJWT, "missing 'exp' claim")
+	}
+	expected := jwt.Expected{Issuer: v.issuer, Time: time.Now()}
+	if v.audience != "" {
+		expected.AnyAudience = jwt.Audience{v.audience}
+	}
+	if err := claims.ValidateWithLeeway(expected, v.clockSkew); err != nil {
+		return "", i18n.NewError(ctx, tkmsgs.MsgJSONRPCAuthInvalidJWT, err)
+	}
+	principal, _ := allClaims[v.principalClaim].(string)
+	if principal == "" {
+		return "", i18n.NewError(ctx, tkmsgs.MsgJSONRPCAuthInvalidJWT, fmt.Sprintf("missing '%s' claim", v.principalClaim))
+	}
+	return principal, nil
+}
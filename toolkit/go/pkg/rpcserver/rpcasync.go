@@ -0,0 +1,176 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rpcserver
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/kaleido-io/paladin/toolkit/pkg/log"
+	"github.com/kaleido-io/paladin/toolkit/pkg/rpcclient"
+	"github.com/kaleido-io/paladin/toolkit/pkg/tkmsgs"
+	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
+)
+
+// An async handler is one that can only be invoked over a WebSocket, because it starts
+// a long-lived instance that pushes notifications back to the client over the connection.
+// The start method (for example "ptx_subscribe") creates the instance, and the lifecycle
+// methods (for example "ptx_ack" / "ptx_unsubscribe") interact with it afterwards.
+type RPCAsyncHandler interface {
+	StartMethod() string
+	LifecycleMethods() []string
+	HandleStart(ctx context.Context, req *rpcclient.RPCRequest, ctrl RPCAsyncControl) (RPCAsyncInstance, *rpcclient.RPCResponse)
+	HandleLifecycle(ctx context.Context, req *rpcclient.RPCRequest) *rpcclient.RPCResponse
+}
+
+// Passed to the handler when an async instance is started, to allow it to push
+// notifications to the connection, and to tell the server when it has ended.
+// Closed must not be called from within HandleStart.
+type RPCAsyncControl interface {
+	ID() string
+	Closed()
+	Send(method string, params any)
+}
+
+// Returned by the handler on a successful start, so it can be told if the
+// connection goes away before the instance has been closed.
+type RPCAsyncInstance interface {
+	ConnectionClosed()
+}
+
+type asyncMethod struct {
+	handler RPCAsyncHandler
+	start   bool
+}
+
+type asyncControl struct {
+	id       string
+	handler  RPCAsyncHandler
+	wsc      *webSocketConnection
+	instance RPCAsyncInstance
+	ready    chan struct{}
+}
+
+type asyncNotification struct {
+	JSONRPC string `json:"jsonrpc"`
+	Method  string `json:"method"`
+	Params  any    `json:"params"`
+}
+
+func (s *rpcServer) getAsyncMethod(method string) *asyncMethod {
+	module := s.rpcModules[strings.SplitN(method, "_", 2)[0]]
+	if module == nil {
+		return nil
+	}
+	return module.asyncMethods[method]
+}
+
+func (s *rpcServer) processAsync(ctx context.Context, rpcReq *rpcclient.RPCRequest, wsc *webSocketConnection, am *asyncMethod) (*rpcclient.RPCResponse, bool) {
+	if rpcReq.ID == nil {
+		err := i18n.NewError(ctx, tkmsgs.MsgJSONRPCMissingRequestID)
+		return rpcclient.NewRPCErrorResponse(err, rpcReq.ID, rpcclient.RPCCodeInvalidRequest), false
+	}
+	if wsc == nil {
+		err := i18n.NewError(ctx, tkmsgs.MsgJSONRPCAsyncNonWSConn, rpcReq.Method)
+		return rpcclient.NewRPCErrorResponse(err, rpcReq.ID, rpcclient.RPCCodeInvalidRequest), false
+	}
+
+	log.L(ctx).Debugf("RPC-> %s (async)", rpcReq.Method)
+	if am.start {
+		return s.processAsyncStart(ctx, rpcReq, wsc, am)
+	}
+	rpcRes := am.handler.HandleLifecycle(ctx, rpcReq)
+	if rpcRes == nil {
+		// Lifecycle methods (such as acks) can choose not to send a reply
+		return nil, true
+	}
+	return rpcRes, rpcRes.Error == nil
+}
+
+func (s *rpcServer) processAsyncStart(ctx context.Context, rpcReq *rpcclient.RPCRequest, wsc *webSocketConnection, am *asyncMethod) (*rpcclient.RPCResponse, bool) {
+	ctrl := &asyncControl{
+		id:      tktypes.ShortID(),
+		handler: am.handler,
+		wsc:     wsc,
+		ready:   make(chan struct{}),
+	}
+	defer close(ctrl.ready)
+
+	// Lock around the start, so that a close of the connection cannot
+	// race with us adding the instance to the connection.
+	wsc.asyncMux.Lock()
+	var rpcRes *rpcclient.RPCResponse
+	ctrl.instance, rpcRes = am.handler.HandleStart(ctx, rpcReq, ctrl)
+	connClosed := wsc.asyncClosed
+	if ctrl.instance != nil && !connClosed {
+		wsc.asyncInstances[ctrl.id] = ctrl
+	}
+	wsc.asyncMux.Unlock()
+	if ctrl.instance != nil && connClosed {
+		ctrl.instance.ConnectionClosed()
+	}
+
+	// We send the response to the start ourselves, so that it is guaranteed to be
+	// queued to the connection before any notifications the instance sends.
+	if rpcRes == nil {
+		return nil, true
+	}
+	wsc.sendMessage(rpcRes)
+	return nil, rpcRes.Error == nil
+}
+
+func (ctrl *asyncControl) ID() string {
+	return ctrl.id
+}
+
+func (ctrl *asyncControl) Closed() {
+	ctrl.wsc.asyncMux.Lock()
+	defer ctrl.wsc.asyncMux.Unlock()
+	delete(ctrl.wsc.asyncInstances, ctrl.id)
+}
+
+func (ctrl *asyncControl) Send(method string, params any) {
+	b, _ := json.Marshal(&asyncNotification{
+		JSONRPC: "2.0",
+		Method:  method,
+		Params:  params,
+	})
+	select {
+	case <-ctrl.ready:
+	case <-ctrl.wsc.closing:
+		return
+	}
+	select {
+	case ctrl.wsc.send <- b:
+	case <-ctrl.wsc.closing:
+	}
+}
+
+func (c *webSocketConnection) closeAsyncInstances() {
+	c.asyncMux.Lock()
+	c.asyncClosed = true
+	instances := c.asyncInstances
+	c.asyncInstances = map[string]*asyncControl{}
+	c.asyncMux.Unlock()
+
+	for _, ctrl := range instances {
+		log.L(c.ctx).Infof("Closing async instance %s (%s) on WS disconnect", ctrl.id, ctrl.handler.StartMethod())
+		ctrl.instance.ConnectionClosed()
+	}
+}
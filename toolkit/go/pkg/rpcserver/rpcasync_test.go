@@ -0,0 +1,216 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rpcserver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/gorilla/websocket"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/kaleido-io/paladin/config/pkg/pldconf"
+	"github.com/kaleido-io/paladin/toolkit/pkg/rpcclient"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testAsyncHandler struct {
+	ctrls  map[string]RPCAsyncControl
+	closed chan string
+}
+
+type testAsyncInstance struct {
+	h  *testAsyncHandler
+	id string
+}
+
+func (h *testAsyncHandler) StartMethod() string {
+	return "ut_subscribe"
+}
+
+func (h *testAsyncHandler) LifecycleMethods() []string {
+	return []string{"ut_ack", "ut_unsubscribe"}
+}
+
+func (h *testAsyncHandler) HandleStart(ctx context.Context, req *rpcclient.RPCRequest, ctrl RPCAsyncControl) (RPCAsyncInstance, *rpcclient.RPCResponse) {
+	if len(req.Params) != 1 || req.Params[0].AsString() != "things" {
+		return nil, rpcclient.NewRPCErrorResponse(assert.AnError, req.ID, rpcclient.RPCCodeInvalidRequest)
+	}
+	h.ctrls[ctrl.ID()] = ctrl
+	return &testAsyncInstance{h: h, id: ctrl.ID()}, &rpcclient.RPCResponse{
+		JSONRpc: "2.0",
+		ID:      req.ID,
+		Result:  fftypes.JSONAnyPtr(`"` + ctrl.ID() + `"`),
+	}
+}
+
+func (h *testAsyncHandler) HandleLifecycle(ctx context.Context, req *rpcclient.RPCRequest) *rpcclient.RPCResponse {
+	switch req.Method {
+	case "ut_ack":
+		return nil
+	default:
+		ctrl := h.ctrls[req.Params[0].AsString()]
+		ctrl.Closed()
+		return &rpcclient.RPCResponse{
+			JSONRpc: "2.0",
+			ID:      req.ID,
+			Result:  fftypes.JSONAnyPtr(`true`),
+		}
+	}
+}
+
+func (i *testAsyncInstance) ConnectionClosed() {
+	i.h.closed <- i.id
+}
+
+func newTestAsyncHandler() *testAsyncHandler {
+	return &testAsyncHandler{
+		ctrls:  map[string]RPCAsyncControl{},
+		closed: make(chan string, 1),
+	}
+}
+
+func TestRPCAsyncWebSocketLifecycle(t *testing.T) {
+	url, s, done := newTestServerWebSockets(t, &pldconf.RPCServerConfig{})
+	defer done()
+
+	h := newTestAsyncHandler()
+	s.Register(NewRPCModule("ut").AddAsync(h))
+
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	readRes := func() map[string]any {
+		_ = conn.SetReadDeadline(time.Now().Add(1 * time.Second))
+		var res map[string]any
+		err := conn.ReadJSON(&res)
+		require.NoError(t, err)
+		return res
+	}
+
+	// Bad start
+	err = conn.WriteJSON(map[string]any{"jsonrpc": "2.0", "id": 1, "method": "ut_subscribe", "params": []any{"wrong"}})
+	require.NoError(t, err)
+	res := readRes()
+	assert.NotNil(t, res["error"])
+
+	// Missing ID
+	err = conn.WriteJSON(map[string]any{"jsonrpc": "2.0", "method": "ut_subscribe", "params": []any{"things"}})
+	require.NoError(t, err)
+	res = readRes()
+	assert.Regexp(t, "PD020701", res["error"])
+
+	// Good start
+	err = conn.WriteJSON(map[string]any{"jsonrpc": "2.0", "id": 2, "method": "ut_subscribe", "params": []any{"things"}})
+	require.NoError(t, err)
+	res = readRes()
+	subID := res["result"].(string)
+	assert.NotEmpty(t, subID)
+	ctrl := h.ctrls[subID]
+
+	// Push a notification
+	go ctrl.Send("ut_subscription", map[string]any{"subscription": subID, "result": "hello"})
+	res = readRes()
+	assert.Equal(t, "ut_subscription", res["method"])
+	assert.Equal(t, "hello", res["params"].(map[string]any)["result"])
+
+	// Ack gets no reply, so the next thing we get is the unsubscribe reply
+	err = conn.WriteJSON(map[string]any{"jsonrpc": "2.0", "id": 3, "method": "ut_ack", "params": []any{subID}})
+	require.NoError(t, err)
+	err = conn.WriteJSON(map[string]any{"jsonrpc": "2.0", "id": 4, "method": "ut_unsubscribe", "params": []any{subID}})
+	require.NoError(t, err)
+	res = readRes()
+	assert.Equal(t, float64(4), res["id"])
+	assert.Equal(t, true, res["result"])
+
+	// Start another, and check it is notified when the connection closes
+	err = conn.WriteJSON(map[string]any{"jsonrpc": "2.0", "id": 5, "method": "ut_subscribe", "params": []any{"things"}})
+	require.NoError(t, err)
+	res = readRes()
+	subID2 := res["result"].(string)
+
+	conn.Close()
+	assert.Equal(t, subID2, <-h.closed)
+
+}
+
+func TestRPCAsyncStartAfterConnClosed(t *testing.T) {
+	url, s, done := newTestServerWebSockets(t, &pldconf.RPCServerConfig{})
+	defer done()
+
+	h := newTestAsyncHandler()
+	s.Register(NewRPCModule("ut").AddAsync(h))
+
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	var wsConn *webSocketConnection
+	for wsConn == nil {
+		time.Sleep(1 * time.Millisecond)
+		s.wsMux.Lock()
+		for _, wsConn = range s.wsConnections {
+		}
+		s.wsMux.Unlock()
+	}
+	wsConn.close()
+
+	b, _ := json.Marshal(map[string]any{"jsonrpc": "2.0", "id": 1, "method": "ut_subscribe", "params": []any{"things"}})
+	req := &rpcclient.RPCRequest{}
+	err = json.Unmarshal(b, req)
+	require.NoError(t, err)
+	res, ok := s.processAsync(context.Background(), req, wsConn, s.getAsyncMethod("ut_subscribe"))
+	assert.True(t, ok)
+	assert.Nil(t, res) // sent directly to the connection
+	assert.NotEmpty(t, <-h.closed)
+	assert.Empty(t, wsConn.asyncInstances)
+
+	// The handler returns an untyped nil, so the WebSocket does not send a second reply
+	iRes, ok := s.rpcHandler(context.Background(), bytes.NewReader(b), wsConn)
+	assert.True(t, ok)
+	assert.True(t, iRes == nil)
+	assert.NotEmpty(t, <-h.closed)
+}
+
+func TestRPCAsyncHTTPNotSupported(t *testing.T) {
+	url, s, done := newTestServerHTTP(t, &pldconf.RPCServerConfig{})
+	defer done()
+
+	s.Register(NewRPCModule("ut").AddAsync(newTestAsyncHandler()))
+
+	var errResponse rpcclient.RPCResponse
+	res, err := resty.New().R().
+		SetBody(`{"jsonrpc":"2.0","id":1,"method":"ut_subscribe","params":["things"]}`).
+		SetError(&errResponse).
+		Post(url)
+	require.NoError(t, err)
+	assert.False(t, res.IsSuccess())
+	assert.Regexp(t, "PD020706", errResponse.Error.Message)
+}
+
+func TestRPCModuleAsyncPanicDup(t *testing.T) {
+	assert.Panics(t, func() {
+		NewRPCModule("ut").
+			Add("ut_ack", RPCMethod0(func(ctx context.Context) (any, error) { return nil, nil })).
+			AddAsync(newTestAsyncHandler())
+	})
+}
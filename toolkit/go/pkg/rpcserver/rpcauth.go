@@ -0,0 +1,215 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rpcserver
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/kaleido-io/paladin/config/pkg/pldconf"
+	"github.com/kaleido-io/paladin/toolkit/pkg/log"
+	"github.com/kaleido-io/paladin/toolkit/pkg/tkmsgs"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type rpcAuth struct {
+	tokens     []*pldconf.RPCAuthTokenConfig
+	basicUsers map[string][]byte
+	jwt        *jwtVerifier
+	policies   []*rpcAuthPolicy
+}
+
+type rpcAuthPolicy struct {
+	principals []string
+	methods    []string
+	keys       []*regexp.Regexp
+}
+
+// authContext is stored in the context of each request (and for the lifetime of each WebSocket
+// connection) once the caller has been authenticated.
+type authContext struct {
+	principal string
+	policies  []*rpcAuthPolicy // nil when no policies are configured, meaning unrestricted
+}
+
+type authContextKey struct{}
+
+func newRPCAuth(ctx context.Context, conf *pldconf.RPCAuthConfig) (*rpcAuth, error) {
+	if len(conf.Tokens) == 0 && len(conf.Basic) == 0 && conf.JWT.JWKSFile == "" {
+		if len(conf.Policies) > 0 {
+			log.L(ctx).Warnf("JSON/RPC authorization policies ignored, as no authentication is configured")
+		}
+		return nil, nil
+	}
+
+	a := &rpcAuth{
+		basicUsers: make(map[string][]byte),
+	}
+	for i := range conf.Tokens {
+		a.tokens = append(a.tokens, &conf.Tokens[i])
+	}
+	for _, u := range conf.Basic {
+		if _, err := bcrypt.Cost([]byte(u.PasswordHash)); err != nil {
+			return nil, i18n.WrapError(ctx, err, tkmsgs.MsgJSONRPCAuthBadPasswordHash, u.Username)
+		}
+		a.basicUsers[u.Username] = []byte(u.PasswordHash)
+	}
+	if conf.JWT.JWKSFile != "" {
+		var err error
+		if a.jwt, err = newJWTVerifier(ctx, &conf.JWT); err != nil {
+			return nil, err
+		}
+	}
+	for _, pc := range conf.Policies {
+		p := &rpcAuthPolicy{
+			principals: pc.Principals,
+			methods:    pc.Methods,
+		}
+		for _, k := range pc.Keys {
+			re, err := regexp.Compile("^(?:" + k + ")$")
+			if err != nil {
+				return nil, i18n.NewError(ctx, tkmsgs.MsgJSONRPCAuthInvalidKeyRegex, k, err)
+			}
+			p.keys = append(p.keys, re)
+		}
+		a.policies = append(a.policies, p)
+	}
+	return a, nil
+}
+
+// authenticate checks the credentials on an HTTP request (or WebSocket upgrade),
+// returning a context containing the authenticated principal.
+func (a *rpcAuth) authenticate(req *http.Request) (context.Context, error) {
+	ctx := req.Context()
+	principal, err := a.principalFromRequest(req)
+	if err != nil {
+		log.L(ctx).Errorf("JSON/RPC authentication failed: %s", err)
+		return nil, i18n.NewError(ctx, tkmsgs.MsgJSONRPCUnauthorized)
+	}
+	ac := &authContext{principal: principal}
+	if len(a.policies) > 0 {
+		ac.policies = []*rpcAuthPolicy{}
+		for _, p := range a.policies {
+			if p.appliesTo(principal) {
+				ac.policies = append(ac.policies, p)
+			}
+		}
+	}
+	ctx = context.WithValue(ctx, authContextKey{}, ac)
+	return log.WithLogField(ctx, "principal", principal), nil
+}
+
+func (a *rpcAuth) principalFromRequest(req *http.Request) (string, error) {
+	ctx := req.Context()
+	if username, password, ok := req.BasicAuth(); ok {
+		if hash, ok := a.basicUsers[username]; ok && bcrypt.CompareHashAndPassword(hash, []byte(password)) == nil {
+			return username, nil
+		}
+		return "", i18n.NewError(ctx, tkmsgs.MsgJSONRPCUnauthorized)
+	}
+	authHeader := req.Header.Get("Authorization")
+	if len(authHeader) > 7 && strings.EqualFold(authHeader[0:7], "bearer ") {
+		token := strings.TrimSpace(authHeader[7:])
+		for _, t := range a.tokens {
+			if subtle.ConstantTimeCompare([]byte(t.Token), []byte(token)) == 1 {
+				return t.Principal, nil
+			}
+		}
+		if a.jwt != nil {
+			return a.jwt.verify(ctx, token)
+		}
+	}
+	return "", i18n.NewError(ctx, tkmsgs.MsgJSONRPCUnauthorized)
+}
+
+func (a *rpcAuth) challenge(res http.ResponseWriter) {
+	if len(a.tokens) > 0 || a.jwt != nil {
+		res.Header().Add("WWW-Authenticate", "Bearer")
+	}
+	if len(a.basicUsers) > 0 {
+		res.Header().Add("WWW-Authenticate", `Basic realm="JSON/RPC"`)
+	}
+}
+
+func (p *rpcAuthPolicy) appliesTo(principal string) bool {
+	for _, pp := range p.principals {
+		if pp == "*" || pp == principal {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *rpcAuthPolicy) allowsMethod(method string) bool {
+	for _, m := range p.methods {
+		if m == "*" || m == method || (strings.HasSuffix(m, "_") && strings.HasPrefix(method, m)) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *rpcAuthPolicy) allowsKey(keyIdentifier string) bool {
+	if len(p.keys) == 0 {
+		return true
+	}
+	for _, re := range p.keys {
+		if re.MatchString(keyIdentifier) {
+			return true
+		}
+	}
+	return false
+}
+
+// authorizeMethod checks the principal can call the method, returning a context that
+// contains only the policies that grant the method - so key checks are made against those.
+func authorizeMethod(ctx context.Context, method string) (context.Context, error) {
+	ac, _ := ctx.Value(authContextKey{}).(*authContext)
+	if ac == nil || ac.policies == nil {
+		return ctx, nil
+	}
+	methodAC := &authContext{principal: ac.principal, policies: []*rpcAuthPolicy{}}
+	for _, p := range ac.policies {
+		if p.allowsMethod(method) {
+			methodAC.policies = append(methodAC.policies, p)
+		}
+	}
+	if len(methodAC.policies) == 0 {
+		return nil, i18n.NewError(ctx, tkmsgs.MsgJSONRPCMethodForbidden, ac.principal, method)
+	}
+	return context.WithValue(ctx, authContextKey{}, methodAC), nil
+}
+
+// AuthorizeKey should be called by RPC method implementations that use a key identifier
+// supplied by the caller (such as the "from" of a transaction) to check the authenticated
+// principal is permitted to use that key. It returns nil if authentication is not enabled.
+func AuthorizeKey(ctx context.Context, keyIdentifier string) error {
+	ac, _ := ctx.Value(authContextKey{}).(*authContext)
+	if ac == nil || ac.policies == nil {
+		return nil
+	}
+	for _, p := range ac.policies {
+		if p.allowsKey(keyIdentifier) {
+			return nil
+		}
+	}
+	return i18n.NewError(ctx, tkmsgs.MsgJSONRPCKeyForbidden, ac.principal, keyIdentifier)
+}
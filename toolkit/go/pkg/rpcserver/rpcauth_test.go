@@ -0,0 +1,438 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rpcserver
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+	"github.com/go-resty/resty/v2"
+	"github.com/hyperledger/firefly-common/pkg/wsclient"
+	"github.com/kaleido-io/paladin/config/pkg/pldconf"
+	"github.com/kaleido-io/paladin/toolkit/pkg/rpcclient"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func testAuthConfig(t *testing.T) pldconf.RPCAuthConfig {
+	hash, err := bcrypt.GenerateFromPassword([]byte("bobpass"), bcrypt.MinCost)
+	require.NoError(t, err)
+	return pldconf.RPCAuthConfig{
+		Tokens: []pldconf.RPCAuthTokenConfig{
+			{Principal: "admin", Token: "admin-token"},
+			{Principal: "app", Token: "app-token"},
+		},
+		Basic: []pldconf.RPCAuthBasicConfig{
+			{Username: "bob", PasswordHash: string(hash)},
+		},
+		Policies: []pldconf.RPCAuthPolicyConfig{
+			{Principals: []string{"admin"}, Methods: []string{"*"}},
+			{Principals: []string{"app", "bob"}, Methods: []string{"ut_"}, Keys: []string{"app/.*"}},
+			{Principals: []string{"*"}, Methods: []string{"public_hello"}},
+		},
+	}
+}
+
+func regTestAuthRPCs(s *rpcServer) {
+	regTestRPC(s, "ut_send", RPCMethod1(func(ctx context.Context, from string) (string, error) {
+		if err := AuthorizeKey(ctx, from); err != nil {
+			return "", err
+		}
+		return "sent", nil
+	}))
+	regTestRPC(s, "other_method", RPCMethod0(func(ctx context.Context) (string, error) {
+		return "other", nil
+	}))
+	regTestRPC(s, "public_hello", RPCMethod0(func(ctx context.Context) (string, error) {
+		return "hello", nil
+	}))
+}
+
+func TestRPCAuthHTTPTokensAndBasic(t *testing.T) {
+
+	url, s, done := newTestServerHTTP(t, &pldconf.RPCServerConfig{Auth: testAuthConfig(t)})
+	defer done()
+	regTestAuthRPCs(s)
+
+	call := func(r *resty.Request, method string, params ...string) (int, *rpcclient.RPCResponse) {
+		var rpcRes rpcclient.RPCResponse
+		res, err := r.
+			SetBody(map[string]interface{}{
+				"jsonrpc": "2.0",
+				"id":      "1",
+				"method":  method,
+				"params":  append([]string{}, params...),
+			}).
+			SetResult(&rpcRes).
+			SetError(&rpcRes).
+			Post(url)
+		require.NoError(t, err)
+		return res.StatusCode(), &rpcRes
+	}
+
+	// No credentials
+	status, rpcRes := call(resty.New().R(), "public_hello")
+	assert.Equal(t, http.StatusUnauthorized, status)
+	assert.Regexp(t, "PD020707", rpcRes.Error.Message)
+
+	// Bad token
+	status, _ = call(resty.New().R().SetAuthToken("wrong"), "public_hello")
+	assert.Equal(t, http.StatusUnauthorized, status)
+
+	// Bad password
+	status, _ = call(resty.New().R().SetBasicAuth("bob", "wrong"), "public_hello")
+	assert.Equal(t, http.StatusUnauthorized, status)
+
+	// Any principal can call the public method
+	status, rpcRes = call(resty.New().R().SetBasicAuth("bob", "bobpass"), "public_hello")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, `"hello"`, rpcRes.Result.String())
+
+	// App can use its own keys in the ut_ group
+	status, rpcRes = call(resty.New().R().SetAuthToken("app-token"), "ut_send", "app/key1")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, `"sent"`, rpcRes.Result.String())
+
+	// ... but not other keys
+	_, rpcRes = call(resty.New().R().SetAuthToken("app-token"), "ut_send", "treasury")
+	assert.Regexp(t, "PD020709.*app.*treasury", rpcRes.Error.Message)
+
+	// ... or methods in other groups
+	_, rpcRes = call(resty.New().R().SetBasicAuth("bob", "bobpass"), "other_method")
+	assert.Regexp(t, "PD020708.*bob.*other_method", rpcRes.Error.Message)
+
+	// Admin can do anything
+	status, rpcRes = call(resty.New().R().SetAuthToken("admin-token"), "ut_send", "treasury")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, `"sent"`, rpcRes.Result.String())
+	status, _ = call(resty.New().R().SetAuthToken("admin-token"), "other_method")
+	assert.Equal(t, http.StatusOK, status)
+
+	// Each request in a batch is authorized individually
+	var batchRes []*rpcclient.RPCResponse
+	res, err := resty.New().R().
+		SetAuthToken("app-token").
+		SetBody(`[
+			{"jsonrpc": "2.0", "id": "1", "method": "ut_send", "params": ["app/key1"]},
+			{"jsonrpc": "2.0", "id": "2", "method": "other_method", "params": []}
+		]`).
+		SetResult(&batchRes).
+		Post(url)
+	require.NoError(t, err)
+	assert.True(t, res.IsSuccess())
+	require.Len(t, batchRes, 2)
+	assert.Equal(t, `"sent"`, batchRes[0].Result.String())
+	assert.Regexp(t, "PD020708", batchRes[1].Error.Message)
+
+}
+
+func TestRPCAuthNoPoliciesAllowsAll(t *testing.T) {
+
+	url, s, done := newTestServerHTTP(t, &pldconf.RPCServerConfig{
+		Auth: pldconf.RPCAuthConfig{
+			Tokens: []pldconf.RPCAuthTokenConfig{{Principal: "app", Token: "app-token"}},
+		},
+	})
+	defer done()
+	regTestAuthRPCs(s)
+
+	var rpcRes rpcclient.RPCResponse
+	res, err := resty.New().R().
+		SetAuthToken("app-token").
+		SetBody(`{"jsonrpc": "2.0", "id": "1", "method": "ut_send", "params": ["treasury"]}`).
+		SetResult(&rpcRes).
+		Post(url)
+	require.NoError(t, err)
+	assert.True(t, res.IsSuccess())
+	assert.Equal(t, `"sent"`, rpcRes.Result.String())
+
+}
+
+func TestRPCAuthWebSocket(t *testing.T) {
+
+	ctx, cancelCtx := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancelCtx()
+	url, s, done := newTestServerWebSockets(t, &pldconf.RPCServerConfig{Auth: testAuthConfig(t)})
+	defer done()
+	regTestAuthRPCs(s)
+
+	noAuthClient := rpcclient.WrapWSConfig(&wsclient.WSConfig{WebSocketURL: url, DisableReconnect: true})
+	defer noAuthClient.Close()
+	err := noAuthClient.Connect(ctx)
+	assert.Error(t, err)
+
+	client := rpcclient.WrapWSConfig(&wsclient.WSConfig{
+		WebSocketURL:     url,
+		DisableReconnect: true,
+		HTTPHeaders:      map[string]interface{}{"Authorization": "Bearer app-token"},
+	})
+	defer client.Close()
+	err = client.Connect(ctx)
+	require.NoError(t, err)
+
+	var result string
+	rpcErr := client.CallRPC(ctx, &result, "ut_send", "app/key1")
+	require.Nil(t, rpcErr)
+	assert.Equal(t, "sent", result)
+
+	rpcErr = client.CallRPC(ctx, &result, "ut_send", "treasury")
+	assert.Regexp(t, "PD020709", rpcErr)
+
+	rpcErr = client.CallRPC(ctx, &result, "eth_subscribe", "newHeads")
+	assert.Regexp(t, "PD020708.*eth_subscribe", rpcErr)
+
+}
+
+// Keys are written as raw JSON, so the tests can include invalid keys
+type testJWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid,omitempty"`
+	Use string `json:"use,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+func writeTestJWKS(t *testing.T, keys ...interface{}) string {
+	jwksFile := filepath.Join(t.TempDir(), "jwks.json")
+	b, err := json.Marshal(map[string]interface{}{"keys": append([]interface{}{}, keys...)})
+	require.NoError(t, err)
+	err = os.WriteFile(jwksFile, b, 0644)
+	require.NoError(t, err)
+	return jwksFile
+}
+
+func b64url(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func publicJWK(kid string, key crypto.Signer) *jose.JSONWebKey {
+	return &jose.JSONWebKey{KeyID: kid, Key: key.Public()}
+}
+
+func signTestJWT(t *testing.T, alg jose.SignatureAlgorithm, kid string, key crypto.Signer, claims map[string]interface{}, extraHeaders ...map[jose.HeaderKey]interface{}) string {
+	opts := &jose.SignerOptions{}
+	for _, headers := range extraHeaders {
+		for k, v := range headers {
+			opts = opts.WithHeader(k, v)
+		}
+	}
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: alg, Key: &jose.JSONWebKey{KeyID: kid, Key: key}}, opts)
+	require.NoError(t, err)
+	token, err := jwt.Signed(signer).Claims(claims).Serialize()
+	require.NoError(t, err)
+	return token
+}
+
+func TestRPCAuthJWT(t *testing.T) {
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	url, s, done := newTestServerHTTP(t, &pldconf.RPCServerConfig{
+		Auth: pldconf.RPCAuthConfig{
+			JWT: pldconf.RPCAuthJWTConfig{
+				JWKSFile: writeTestJWKS(t, publicJWK("rsa1", rsaKey), publicJWK("ec1", ecKey),
+					&testJWK{Kty: "oct", Use: "enc"} /* ignored */),
+				Issuer:   "https://issuer.example.com",
+				Audience: "paladin",
+			},
+			Policies: []pldconf.RPCAuthPolicyConfig{
+				{Principals: []string{"*"}, Methods: []string{"ut_"}, Keys: []string{"app/.*"}},
+			},
+		},
+	})
+	defer done()
+	regTestAuthRPCs(s)
+
+	validClaims := func() map[string]interface{} {
+		return map[string]interface{}{
+			"sub": "app",
+			"iss": "https://issuer.example.com",
+			"aud": []string{"other", "paladin"},
+			"exp": time.Now().Add(1 * time.Minute).Unix(),
+			"nbf": time.Now().Add(-1 * time.Minute).Unix(),
+		}
+	}
+	call := func(token string) (int, *rpcclient.RPCResponse) {
+		var rpcRes rpcclient.RPCResponse
+		res, err := resty.New().R().
+			SetAuthToken(token).
+			SetBody(`{"jsonrpc": "2.0", "id": "1", "method": "ut_send", "params": ["app/key1"]}`).
+			SetResult(&rpcRes).
+			SetError(&rpcRes).
+			Post(url)
+		require.NoError(t, err)
+		return res.StatusCode(), &rpcRes
+	}
+
+	for _, tc := range []struct {
+		alg     jose.SignatureAlgorithm
+		kid     string
+		key     crypto.Signer
+		headers map[jose.HeaderKey]interface{}
+	}{
+		{jose.RS256, "rsa1", rsaKey, nil},
+		{jose.PS384, "rsa1", rsaKey, map[jose.HeaderKey]interface{}{"typ": "JWT"}},
+		{jose.ES256, "ec1", ecKey, map[jose.HeaderKey]interface{}{"typ": "application/at+jwt"}},
+	} {
+		status, rpcRes := call(signTestJWT(t, tc.alg, tc.kid, tc.key, validClaims(), tc.headers))
+		assert.Equal(t, http.StatusOK, status, tc.alg)
+		assert.Equal(t, `"sent"`, rpcRes.Result.String(), tc.alg)
+	}
+
+	expired := validClaims()
+	expired["exp"] = time.Now().Add(-1 * time.Hour).Unix()
+	notYet := validClaims()
+	notYet["nbf"] = time.Now().Add(1 * time.Hour).Unix()
+	noExp := validClaims()
+	delete(noExp, "exp")
+	wrongIss := validClaims()
+	wrongIss["iss"] = "someone else"
+	wrongAud := validClaims()
+	wrongAud["aud"] = "other"
+	noSub := validClaims()
+	delete(noSub, "sub")
+
+	for _, token := range []string{
+		"not.a.jwt",
+		"header.payload",
+		signTestJWT(t, jose.ES256, "ec1", otherKey, validClaims()),
+		signTestJWT(t, jose.ES256, "rsa1", ecKey, validClaims()),
+		signTestJWT(t, jose.RS256, "ec1", rsaKey, validClaims()),
+		signTestJWT(t, jose.ES256, "", ecKey, validClaims()), // no kid with multiple keys
+		signTestJWT(t, jose.ES256, "ec1", ecKey, expired),
+		signTestJWT(t, jose.ES256, "ec1", ecKey, notYet),
+		signTestJWT(t, jose.ES256, "ec1", ecKey, noExp),
+		signTestJWT(t, jose.ES256, "ec1", ecKey, wrongIss),
+		signTestJWT(t, jose.ES256, "ec1", ecKey, wrongAud),
+		signTestJWT(t, jose.ES256, "ec1", ecKey, noSub),
+		signTestJWT(t, jose.ES256, "ec1", ecKey, validClaims(), map[jose.HeaderKey]interface{}{"crit": []string{"exp"}, "exp": 12345}),
+		signTestJWT(t, jose.ES256, "ec1", ecKey, validClaims(), map[jose.HeaderKey]interface{}{"typ": "dpop+jwt"}),
+		b64url([]byte(`{"alg":"HS256"}`)) + ".e30.c2ln",
+		b64url([]byte(`{"alg":"none"}`)) + ".e30.",
+		b64url([]byte(`{"alg":"ES256"}`)) + ".e30.!!!",
+	} {
+		status, _ := call(token)
+		assert.Equal(t, http.StatusUnauthorized, status, token)
+	}
+
+}
+
+func TestJWTVerifierSingleKeyNoKid(t *testing.T) {
+	ctx := context.Background()
+	ecKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+	v, err := newJWTVerifier(ctx, &pldconf.RPCAuthJWTConfig{JWKSFile: writeTestJWKS(t, publicJWK("", ecKey))})
+	require.NoError(t, err)
+
+	principal, err := v.verify(ctx, signTestJWT(t, jose.ES384, "", ecKey, map[string]interface{}{
+		"sub": "app",
+		"exp": time.Now().Add(1 * time.Minute).Unix(),
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, "app", principal)
+
+	_, err = v.verify(ctx, signTestJWT(t, jose.ES384, "", ecKey, map[string]interface{}{
+		"sub": "app",
+		"exp": time.Now().Add(1 * time.Minute).Unix(),
+	}, map[jose.HeaderKey]interface{}{"crit": []string{"b64"}, "b64": true}))
+	assert.Regexp(t, "PD020713.*critical", err)
+}
+
+func TestRPCAuthBadConfig(t *testing.T) {
+	ctx := context.Background()
+
+	// Policies with no authentication are ignored
+	a, err := newRPCAuth(ctx, &pldconf.RPCAuthConfig{
+		Policies: []pldconf.RPCAuthPolicyConfig{{Principals: []string{"*"}}},
+	})
+	require.NoError(t, err)
+	assert.Nil(t, a)
+
+	_, err = newRPCAuth(ctx, &pldconf.RPCAuthConfig{
+		Basic: []pldconf.RPCAuthBasicConfig{{Username: "bob", PasswordHash: "plaintext"}},
+	})
+	assert.Regexp(t, "PD020714.*bob", err)
+
+	_, err = newRPCAuth(ctx, &pldconf.RPCAuthConfig{
+		Tokens:   []pldconf.RPCAuthTokenConfig{{Principal: "app", Token: "app-token"}},
+		Policies: []pldconf.RPCAuthPolicyConfig{{Principals: []string{"*"}, Keys: []string{"[[["}}},
+	})
+	assert.Regexp(t, "PD020710", err)
+
+	_, err = NewRPCServer(ctx, &pldconf.RPCServerConfig{
+		Auth: pldconf.RPCAuthConfig{
+			JWT: pldconf.RPCAuthJWTConfig{JWKSFile: filepath.Join(t.TempDir(), "missing.json")},
+		},
+	})
+	assert.Regexp(t, "PD020711", err)
+
+	badJSON := filepath.Join(t.TempDir(), "jwks.json")
+	err = os.WriteFile(badJSON, []byte(`{!!!`), 0644)
+	require.NoError(t, err)
+	for _, jwksFile := range []string{
+		badJSON,
+		writeTestJWKS(t),
+		writeTestJWKS(t, &testJWK{Kty: "oct", Use: "enc"}),
+	} {
+		_, err = newJWTVerifier(ctx, &pldconf.RPCAuthJWTConfig{JWKSFile: jwksFile})
+		assert.Regexp(t, "PD020711", err)
+	}
+
+	weakRSAKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+	for _, badKey := range []interface{}{
+		publicJWK("k1", weakRSAKey),
+		&testJWK{Kty: "oct", Kid: "k1"},
+		&testJWK{Kty: "RSA", Kid: "k1", N: "!!!", E: "AQAB"},
+		&testJWK{Kty: "RSA", Kid: "k1", N: "AQAB", E: b64url(make([]byte, 16))},
+		&testJWK{Kty: "EC", Kid: "k1", Crv: "P-123"},
+		&testJWK{Kty: "EC", Kid: "k1", Crv: "P-256", X: "!!!"},
+		&testJWK{Kty: "EC", Kid: "k1", Crv: "P-256", X: "AQAB", Y: "!!!"},
+		&testJWK{Kty: "EC", Kid: "k1", Crv: "P-256", X: "AQAB", Y: "AQAB"},
+		&testJWK{Kty: "EC", Kid: "k1", Crv: "P-256", X: b64url(make([]byte, 33)), Y: "AQAB"},
+	} {
+		_, err = newJWTVerifier(ctx, &pldconf.RPCAuthJWTConfig{JWKSFile: writeTestJWKS(t, badKey)})
+		assert.Regexp(t, "PD020712.*k1", err)
+	}
+}
+
+func TestAuthorizeKeyNoAuth(t *testing.T) {
+	assert.NoError(t, AuthorizeKey(context.Background(), "any"))
+	assert.NoError(t, AuthorizeKey(context.WithValue(context.Background(), authContextKey{}, &authContext{principal: "app"}), "any"))
+}
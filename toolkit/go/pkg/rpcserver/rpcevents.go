@@ -0,0 +1,293 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rpcserver
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/kaleido-io/paladin/toolkit/pkg/log"
+	"github.com/kaleido-io/paladin/toolkit/pkg/rpcclient"
+	"github.com/kaleido-io/paladin/toolkit/pkg/tkmsgs"
+)
+
+// Returned when a receiver is attached to an event source, to detach it again
+type RPCEventReceiverCloser interface {
+	Close()
+}
+
+// Attaches a new subscription as a receiver to the named event source on the server,
+// such as a receipt listener or an event stream
+type RPCEventSubscriptionAttach func(ctx context.Context, name string, sub *RPCEventSubscription) (RPCEventReceiverCloser, error)
+
+// Implements the <prefix>_subscribe/<prefix>_ack/<prefix>_nack/<prefix>_unsubscribe async JSON/RPC
+// methods over WebSockets. Each batch delivered to a subscription is sent as a <prefix>_subscription
+// notification, and must be acked or nacked by the client before the next batch is delivered.
+type RPCEventSubscriptions struct {
+	prefix    string
+	eventType string
+	attach    RPCEventSubscriptionAttach
+	subLock   sync.Mutex
+	subs      map[string]*RPCEventSubscription
+}
+
+type RPCEventSubscription struct {
+	es        *RPCEventSubscriptions
+	rrc       RPCEventReceiverCloser
+	ctrl      RPCAsyncControl
+	acksNacks chan *rpcAckNack
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+type rpcAckNack struct {
+	ack bool
+}
+
+type rpcSubscriptionNotification struct {
+	Subscription string `json:"subscription"`
+	Result       any    `json:"result"`
+}
+
+func NewRPCEventSubscriptions(prefix, eventType string, attach RPCEventSubscriptionAttach) *RPCEventSubscriptions {
+	return &RPCEventSubscriptions{
+		prefix:    prefix,
+		eventType: eventType,
+		attach:    attach,
+		subs:      make(map[string]*RPCEventSubscription),
+	}
+}
+
+func (es *RPCEventSubscriptions) StartMethod() string {
+	return es.prefix + "_subscribe"
+}
+
+func (es *RPCEventSubscriptions) LifecycleMethods() []string {
+	return []string{es.prefix + "_unsubscribe", es.prefix + "_ack", es.prefix + "_nack"}
+}
+
+func (es *RPCEventSubscriptions) HandleStart(ctx context.Context, req *rpcclient.RPCRequest, ctrl RPCAsyncControl) (RPCAsyncInstance, *rpcclient.RPCResponse) {
+	if len(req.Params) != 2 {
+		return nil, rpcclient.NewRPCErrorResponse(i18n.NewError(ctx, tkmsgs.MsgJSONRPCIncorrectParamCount, req.Method, 2, len(req.Params)), req.ID, rpcclient.RPCCodeInvalidRequest)
+	}
+	eventType := req.Params[0].AsString()
+	if eventType != es.eventType {
+		return nil, rpcclient.NewRPCErrorResponse(i18n.NewError(ctx, tkmsgs.MsgJSONRPCBadSubscriptionType, eventType), req.ID, rpcclient.RPCCodeInvalidRequest)
+	}
+	name := req.Params[1].AsString()
+
+	sub := &RPCEventSubscription{
+		es:        es,
+		ctrl:      ctrl,
+		acksNacks: make(chan *rpcAckNack, 1),
+		closed:    make(chan struct{}),
+	}
+	var err error
+	sub.rrc, err = es.attach(ctx, name, sub)
+	if err != nil {
+		return nil, rpcclient.NewRPCErrorResponse(err, req.ID, rpcclient.RPCCodeInvalidRequest)
+	}
+
+	es.subLock.Lock()
+	es.subs[ctrl.ID()] = sub
+	es.subLock.Unlock()
+
+	return sub, &rpcclient.RPCResponse{
+		JSONRpc: "2.0",
+		ID:      req.ID,
+		Result:  fftypes.JSONAnyPtr(fmt.Sprintf(`"%s"`, ctrl.ID())),
+	}
+}
+
+func (es *RPCEventSubscriptions) getSubscription(ctx context.Context, req *rpcclient.RPCRequest) (*RPCEventSubscription, *rpcclient.RPCResponse) {
+	var subID string
+	if len(req.Params) > 0 {
+		subID = req.Params[0].AsString()
+	}
+	es.subLock.Lock()
+	sub := es.subs[subID]
+	es.subLock.Unlock()
+	if sub == nil {
+		return nil, rpcclient.NewRPCErrorResponse(i18n.NewError(ctx, tkmsgs.MsgJSONRPCSubscriptionNotFound, subID), req.ID, rpcclient.RPCCodeInvalidRequest)
+	}
+	return sub, nil
+}
+
+func (es *RPCEventSubscriptions) HandleLifecycle(ctx context.Context, req *rpcclient.RPCRequest) *rpcclient.RPCResponse {
+	sub, errRes := es.getSubscription(ctx, req)
+	if errRes != nil {
+		return errRes
+	}
+	switch req.Method {
+	case es.prefix + "_ack", es.prefix + "_nack":
+		select {
+		case sub.acksNacks <- &rpcAckNack{ack: req.Method == es.prefix+"_ack"}:
+			log.L(ctx).Debugf("%s for subscription %s", req.Method, sub.ctrl.ID())
+		default:
+			log.L(ctx).Warnf("%s for subscription %s ignored - no batch in flight", req.Method, sub.ctrl.ID())
+		}
+		// Acks and nacks do not get a reply
+		return nil
+	default: // unsubscribe
+		sub.close()
+		return &rpcclient.RPCResponse{
+			JSONRpc: "2.0",
+			ID:      req.ID,
+			Result:  fftypes.JSONAnyPtr(`true`),
+		}
+	}
+}
+
+// DeliverBatch sends the batch to the client, and waits for it to be acked.
+// An error is returned if it is nacked, or the subscription closes first.
+func (sub *RPCEventSubscription) DeliverBatch(ctx context.Context, batchID any, batch any) error {
+	// Discard any stale ack/nack from a previous batch
+	select {
+	case <-sub.acksNacks:
+	default:
+	}
+
+	sub.ctrl.Send(sub.es.prefix+"_subscription", &rpcSubscriptionNotification{
+		Subscription: sub.ctrl.ID(),
+		Result:       batch,
+	})
+
+	select {
+	case ackNack := <-sub.acksNacks:
+		if !ackNack.ack {
+			return i18n.NewError(ctx, tkmsgs.MsgJSONRPCBatchNack, batchID)
+		}
+		return nil
+	case <-sub.closed:
+		return i18n.NewError(ctx, tkmsgs.MsgJSONRPCSubscriptionClosed, batchID)
+	case <-ctx.Done():
+		return i18n.NewError(ctx, tkmsgs.MsgContextCanceled)
+	}
+}
+
+func (sub *RPCEventSubscription) ConnectionClosed() {
+	sub.close()
+}
+
+func (sub *RPCEventSubscription) close() {
+	sub.closeOnce.Do(func() {
+		sub.rrc.Close()
+		close(sub.closed)
+
+		sub.es.subLock.Lock()
+		delete(sub.es.subs, sub.ctrl.ID())
+		sub.es.subLock.Unlock()
+
+		sub.ctrl.Closed()
+	})
+}
+
+// RPCEventReceivers holds the receivers attached to an event source, which each
+// batch is delivered to in turn.
+type RPCEventReceivers[R any] struct {
+	newReceivers chan bool
+	receiverLock sync.Mutex
+	receivers    []*registeredReceiver[R]
+	nextReceiver int
+}
+
+type registeredReceiver[R any] struct {
+	id       uuid.UUID
+	rr       *RPCEventReceivers[R]
+	receiver R
+}
+
+func NewRPCEventReceivers[R any]() *RPCEventReceivers[R] {
+	return &RPCEventReceivers[R]{
+		newReceivers: make(chan bool, 1),
+	}
+}
+
+func (r *registeredReceiver[R]) Close() {
+	r.rr.removeReceiver(r.id)
+}
+
+func (rr *RPCEventReceivers[R]) AddReceiver(r R) RPCEventReceiverCloser {
+	rr.receiverLock.Lock()
+	defer rr.receiverLock.Unlock()
+
+	registered := &registeredReceiver[R]{
+		id:       uuid.New(),
+		rr:       rr,
+		receiver: r,
+	}
+	rr.receivers = append(rr.receivers, registered)
+
+	select {
+	case rr.newReceivers <- true:
+	default:
+	}
+
+	return registered
+}
+
+func (rr *RPCEventReceivers[R]) removeReceiver(rid uuid.UUID) {
+	rr.receiverLock.Lock()
+	defer rr.receiverLock.Unlock()
+
+	newReceivers := make([]*registeredReceiver[R], 0, len(rr.receivers))
+	for _, existing := range rr.receivers {
+		if existing.id != rid {
+			newReceivers = append(newReceivers, existing)
+		}
+	}
+	rr.receivers = newReceivers
+}
+
+// Count returns the number of receivers currently attached
+func (rr *RPCEventReceivers[R]) Count() int {
+	rr.receiverLock.Lock()
+	defer rr.receiverLock.Unlock()
+	return len(rr.receivers)
+}
+
+// Round-robin across the available receivers
+func (rr *RPCEventReceivers[R]) getReceiver() *registeredReceiver[R] {
+	rr.receiverLock.Lock()
+	defer rr.receiverLock.Unlock()
+
+	if len(rr.receivers) == 0 {
+		return nil
+	}
+	r := rr.receivers[rr.nextReceiver%len(rr.receivers)]
+	rr.nextReceiver++
+	return r
+}
+
+// WaitForReceiver returns the next receiver to deliver a batch to, blocking until one is attached
+func (rr *RPCEventReceivers[R]) WaitForReceiver(ctx context.Context) (R, error) {
+	for {
+		if r := rr.getReceiver(); r != nil {
+			return r.receiver, nil
+		}
+		select {
+		case <-rr.newReceivers:
+		case <-ctx.Done():
+			var noReceiver R
+			return noReceiver, i18n.NewError(ctx, tkmsgs.MsgContextCanceled)
+		}
+	}
+}
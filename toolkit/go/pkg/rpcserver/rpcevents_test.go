@@ -0,0 +1,179 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rpcserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/kaleido-io/paladin/toolkit/pkg/rpcclient"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testEventSource struct {
+	receivers *RPCEventReceivers[*RPCEventSubscription]
+}
+
+func newTestEventSubscriptions(t *testing.T) (context.Context, *UnitTestWSClient, *testEventSource) {
+	ctx := context.Background()
+	src := &testEventSource{receivers: NewRPCEventReceivers[*RPCEventSubscription]()}
+	es := NewRPCEventSubscriptions("ut", "things", func(ctx context.Context, name string, sub *RPCEventSubscription) (RPCEventReceiverCloser, error) {
+		if name != "source1" {
+			return nil, assert.AnError
+		}
+		return src.receivers.AddReceiver(sub), nil
+	})
+	wsc, done, err := NewUnitTestWSClient(ctx, NewRPCModule("ut").AddAsync(es))
+	require.NoError(t, err)
+	t.Cleanup(done)
+	return ctx, wsc, src
+}
+
+func subscribe(t *testing.T, ctx context.Context, wsc *UnitTestWSClient, id int) string {
+	require.NoError(t, wsc.Send(ctx, id, "ut_subscribe", "things", "source1"))
+	msg, err := wsc.Receive()
+	require.NoError(t, err)
+	require.Nil(t, msg.Error)
+	var subID string
+	require.NoError(t, json.Unmarshal(msg.Result, &subID))
+	return subID
+}
+
+func TestRPCEventSubscriptionAckNack(t *testing.T) {
+	ctx, wsc, src := newTestEventSubscriptions(t)
+
+	subID := subscribe(t, ctx, wsc, 1)
+	sub, err := src.receivers.WaitForReceiver(ctx)
+	require.NoError(t, err)
+
+	delivered := make(chan error)
+	deliver := func(batchID int) {
+		go func() { delivered <- sub.DeliverBatch(ctx, batchID, map[string]any{"batch": batchID}) }()
+		msg, err := wsc.Receive()
+		require.NoError(t, err)
+		assert.Equal(t, "ut_subscription", msg.Method)
+		assert.Equal(t, subID, msg.Params.Subscription)
+		assert.JSONEq(t, fmt.Sprintf(`{"batch":%d}`, batchID), msg.Params.Result.String())
+	}
+
+	deliver(1)
+	require.NoError(t, wsc.Send(ctx, 2, "ut_nack", subID))
+	assert.Regexp(t, "PD020717.*1", <-delivered)
+
+	deliver(1)
+	require.NoError(t, wsc.Send(ctx, 3, "ut_ack", subID))
+	assert.NoError(t, <-delivered)
+
+	// Acks with no batch in flight are discarded, and not applied to the next batch
+	for i := 0; i < 2; i++ {
+		res := sub.es.HandleLifecycle(ctx, &rpcclient.RPCRequest{
+			Method: "ut_ack",
+			Params: []*fftypes.JSONAny{fftypes.JSONAnyPtr(`"` + subID + `"`)},
+		})
+		assert.Nil(t, res)
+	}
+	require.NoError(t, wsc.Send(ctx, 5, "ut_ack", "unknown"))
+	msg, err := wsc.Receive()
+	require.NoError(t, err)
+	assert.Regexp(t, "PD020716", msg.Error.Message)
+
+	deliver(2)
+	require.NoError(t, wsc.Send(ctx, 6, "ut_unsubscribe", subID))
+	msg, err = wsc.Receive()
+	require.NoError(t, err)
+	assert.Equal(t, "true", msg.Result.String())
+	assert.Regexp(t, "PD020718.*2", <-delivered)
+	assert.Zero(t, src.receivers.Count())
+}
+
+func TestRPCEventSubscriptionBadSubscribe(t *testing.T) {
+	ctx, wsc, _ := newTestEventSubscriptions(t)
+
+	require.NoError(t, wsc.Send(ctx, 1, "ut_subscribe", "things"))
+	msg, err := wsc.Receive()
+	require.NoError(t, err)
+	assert.Regexp(t, "PD020703", msg.Error.Message)
+
+	require.NoError(t, wsc.Send(ctx, 2, "ut_subscribe", "wrong", "source1"))
+	msg, err = wsc.Receive()
+	require.NoError(t, err)
+	assert.Regexp(t, "PD020715", msg.Error.Message)
+
+	require.NoError(t, wsc.Send(ctx, 3, "ut_subscribe", "things", "unknown"))
+	msg, err = wsc.Receive()
+	require.NoError(t, err)
+	assert.Regexp(t, assert.AnError.Error(), msg.Error.Message)
+}
+
+func TestRPCEventSubscriptionConnectionClosed(t *testing.T) {
+	ctx, wsc, src := newTestEventSubscriptions(t)
+
+	_ = subscribe(t, ctx, wsc, 1)
+	sub, err := src.receivers.WaitForReceiver(ctx)
+	require.NoError(t, err)
+
+	cancelledCtx, cancelCtx := context.WithCancel(ctx)
+	cancelCtx()
+	err = sub.DeliverBatch(cancelledCtx, 1, "batch")
+	assert.Regexp(t, "PD020000", err)
+	_, err = wsc.Receive()
+	require.NoError(t, err)
+
+	wsc.Close()
+	for src.receivers.Count() > 0 {
+		time.Sleep(1 * time.Millisecond)
+	}
+	sub.es.subLock.Lock()
+	assert.Empty(t, sub.es.subs)
+	sub.es.subLock.Unlock()
+}
+
+func TestRPCEventReceiversRoundRobin(t *testing.T) {
+	ctx := context.Background()
+	rr := NewRPCEventReceivers[string]()
+
+	waited := make(chan string)
+	go func() {
+		r, err := rr.WaitForReceiver(ctx)
+		assert.NoError(t, err)
+		waited <- r
+	}()
+	r1 := rr.AddReceiver("r1")
+	assert.Equal(t, "r1", <-waited)
+
+	r2 := rr.AddReceiver("r2")
+	assert.Equal(t, 2, rr.Count())
+	for _, expected := range []string{"r2", "r1", "r2"} {
+		r, err := rr.WaitForReceiver(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, expected, r)
+	}
+
+	r1.Close()
+	r2.Close()
+	assert.Zero(t, rr.Count())
+
+	cancelledCtx, cancelCtx := context.WithCancel(ctx)
+	cancelCtx()
+	_, err := rr.WaitForReceiver(cancelledCtx)
+	assert.Regexp(t, "PD020000", err)
+}
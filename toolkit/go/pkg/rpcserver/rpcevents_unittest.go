@@ -0,0 +1,123 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rpcserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/firefly-common/pkg/wsclient"
+	"github.com/kaleido-io/paladin/config/pkg/confutil"
+	"github.com/kaleido-io/paladin/config/pkg/pldconf"
+	"github.com/kaleido-io/paladin/toolkit/pkg/rpcclient"
+	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
+)
+
+// Used for unit tests throughout the project that want to call JSON/RPC methods, including
+// event subscriptions, over a real WebSocket connection to a local server
+type UnitTestWSClient struct {
+	wsc wsclient.WSClient
+}
+
+// A reply, or a subscription notification, received by a UnitTestWSClient
+type UnitTestWSMessage struct {
+	ID     any                 `json:"id"`
+	Method string              `json:"method"`
+	Result tktypes.RawJSON     `json:"result"`
+	Error  *rpcclient.RPCError `json:"error"`
+	Params struct {
+		Subscription string          `json:"subscription"`
+		Result       tktypes.RawJSON `json:"result"`
+	} `json:"params"`
+}
+
+// Starts a WebSocket only RPC server on a random local port with the supplied modules registered,
+// and connects a client to it. The returned function closes both.
+func NewUnitTestWSClient(ctx context.Context, modules ...*RPCModule) (*UnitTestWSClient, func(), error) {
+	rpcServer, err := NewRPCServer(ctx, &pldconf.RPCServerConfig{
+		HTTP: pldconf.RPCServerConfigHTTP{Disabled: true},
+		WS: pldconf.RPCServerConfigWS{
+			HTTPServerConfig: pldconf.HTTPServerConfig{
+				Address:         confutil.P("127.0.0.1"),
+				Port:            confutil.P(0),
+				ShutdownTimeout: confutil.P("0"),
+			},
+		},
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, module := range modules {
+		rpcServer.Register(module)
+	}
+	if err := rpcServer.Start(); err != nil {
+		return nil, nil, err
+	}
+
+	wsc, err := wsclient.New(ctx, &wsclient.WSConfig{
+		WebSocketURL:     fmt.Sprintf("ws://%s", rpcServer.WSAddr()),
+		DisableReconnect: true,
+	}, nil, nil)
+	if err == nil {
+		err = wsc.Connect()
+	}
+	if err != nil {
+		rpcServer.Stop()
+		return nil, nil, err
+	}
+
+	c := &UnitTestWSClient{wsc: wsc}
+	return c, func() {
+		c.Close()
+		rpcServer.Stop()
+	}, nil
+}
+
+// Close disconnects the client, leaving the server running
+func (c *UnitTestWSClient) Close() {
+	c.wsc.Close()
+}
+
+// Send sends a JSON/RPC request, without waiting for the reply
+func (c *UnitTestWSClient) Send(ctx context.Context, id int, method string, params ...any) error {
+	b, err := json.Marshal(map[string]any{
+		"jsonrpc": "2.0",
+		"id":      id,
+		"method":  method,
+		"params":  params,
+	})
+	if err != nil {
+		return err
+	}
+	return c.wsc.Send(ctx, b)
+}
+
+// Receive waits up to five seconds for the next reply or notification
+func (c *UnitTestWSClient) Receive() (*UnitTestWSMessage, error) {
+	select {
+	case b := <-c.wsc.Receive():
+		var msg UnitTestWSMessage
+		if err := json.Unmarshal(b, &msg); err != nil {
+			return nil, err
+		}
+		return &msg, nil
+	case <-time.After(5 * time.Second):
+		return nil, fmt.Errorf("timed out waiting for WebSocket message")
+	}
+}
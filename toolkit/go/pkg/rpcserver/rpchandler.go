@@ -29,6 +29,8 @@ import (
 	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
 )
 
+// rpcHandler returns either a single response or a batch of responses, or nil where the response
+// is delivered separately over the WebSocket (or no response is required)
 func (s *rpcServer) rpcHandler(ctx context.Context, r io.Reader, wsc *webSocketConnection) (interface{}, bool) {
 
 	b, err := io.ReadAll(r)
@@ -53,15 +55,31 @@ func (s *rpcServer) rpcHandler(ctx context.Context, r io.Reader, wsc *webSocketC
 	if err != nil {
 		return s.replyRPCParseError(ctx, b, err)
 	}
+	rpcRes, isOK := s.handleRPCRequest(ctx, &rpcRequest, wsc)
+	if rpcRes == nil {
+		// Must return an untyped nil, so the caller can tell there is no response to send
+		return nil, isOK
+	}
+	return rpcRes, isOK
+
+}
+
+func (s *rpcServer) handleRPCRequest(ctx context.Context, rpcReq *rpcclient.RPCRequest, wsc *webSocketConnection) (*rpcclient.RPCResponse, bool) {
+	ctx, err := authorizeMethod(ctx, rpcReq.Method)
+	if err != nil {
+		return rpcclient.NewRPCErrorResponse(err, rpcReq.ID, rpcclient.RPCCodeInvalidRequest), false
+	}
+	if am := s.getAsyncMethod(rpcReq.Method); am != nil {
+		return s.processAsync(ctx, rpcReq, wsc, am)
+	}
 	if wsc != nil {
-		if rpcRequest.Method == "eth_subscribe" {
-			return s.processSubscribe(ctx, &rpcRequest, wsc)
-		} else if rpcRequest.Method == "eth_unsubscribe" {
-			return s.processUnsubscribe(ctx, &rpcRequest, wsc)
+		if rpcReq.Method == "eth_subscribe" {
+			return s.processSubscribe(ctx, rpcReq, wsc)
+		} else if rpcReq.Method == "eth_unsubscribe" {
+			return s.processUnsubscribe(ctx, rpcReq, wsc)
 		}
 	}
-	return s.processRPC(ctx, &rpcRequest)
-
+	return s.processRPC(ctx, rpcReq)
 }
 
 func (s *rpcServer) replyRPCParseError(ctx context.Context, b []byte, err error) (*rpcclient.RPCResponse, bool) {
@@ -96,7 +114,11 @@ func (s *rpcServer) handleRPCBatch(ctx context.Context, rpcArray []*rpcclient.RP
 		rpcReq := r
 		go func() {
 			var ok bool
-			rpcResponses[responseNumber], ok = s.processRPC(ctx, rpcReq)
+			if reqCtx, err := authorizeMethod(ctx, rpcReq.Method); err != nil {
+				rpcResponses[responseNumber] = rpcclient.NewRPCErrorResponse(err, rpcReq.ID, rpcclient.RPCCodeInvalidRequest)
+			} else {
+				rpcResponses[responseNumber], ok = s.processRPC(reqCtx, rpcReq)
+			}
 			results <- ok
 		}()
 	}
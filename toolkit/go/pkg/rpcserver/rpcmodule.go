@@ -22,14 +22,17 @@ import (
 )
 
 type RPCModule struct {
-	group   string
-	methods map[string]RPCHandler
+	group        string
+	methods      map[string]RPCHandler
+	asyncMethods map[string]*asyncMethod
+	fallback     RPCHandler
 }
 
 func NewRPCModule(prefix string) *RPCModule {
 	return &RPCModule{
-		group:   strings.SplitN(prefix, "_", 2)[0],
-		methods: map[string]RPCHandler{},
+		group:        strings.SplitN(prefix, "_", 2)[0],
+		methods:      map[string]RPCHandler{},
+		asyncMethods: map[string]*asyncMethod{},
 	}
 }
 
@@ -40,13 +43,40 @@ func NewRPCModule(prefix string) *RPCModule {
 // This is inspired by strong adoption of this convention in the Ethereum ecosystem, although
 // it is not part of the JSON/RPC 2.0 standard.
 func (m *RPCModule) Add(method string, handler RPCHandler) *RPCModule {
+	m.checkMethod(method)
+	m.methods[method] = handler
+	return m
+}
+
+// A group handler receives any method in the group that has not been added individually,
+// for modules that route a dynamic set of methods (such as those registered by plugins).
+// It is responsible for rejecting methods it does not recognize.
+func (m *RPCModule) AddGroupHandler(handler RPCHandler) *RPCModule {
+	if m.fallback != nil {
+		panic(fmt.Sprintf("duplicate group handler: %s", m.group))
+	}
+	m.fallback = handler
+	return m
+}
+
+// Async methods are only available over WebSockets, and allow a handler to
+// push notifications to the client after the initial request returns.
+func (m *RPCModule) AddAsync(handler RPCAsyncHandler) *RPCModule {
+	m.checkMethod(handler.StartMethod())
+	m.asyncMethods[handler.StartMethod()] = &asyncMethod{handler: handler, start: true}
+	for _, method := range handler.LifecycleMethods() {
+		m.checkMethod(method)
+		m.asyncMethods[method] = &asyncMethod{handler: handler}
+	}
+	return m
+}
+
+func (m *RPCModule) checkMethod(method string) {
 	prefix := m.group + "_"
 	if !strings.HasPrefix(method, prefix) {
 		panic(fmt.Sprintf("invalid prefix %s (expected=%s)", method, prefix))
 	}
-	if m.methods[method] != nil {
+	if m.methods[method] != nil || m.asyncMethods[method] != nil {
 		panic(fmt.Sprintf("duplicate method: %s", method))
 	}
-	m.methods[method] = handler
-	return m
 }
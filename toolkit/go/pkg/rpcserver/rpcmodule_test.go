@@ -18,10 +18,12 @@ package rpcserver
 
 import (
 	"context"
+	"fmt"
 	"testing"
 
 	"github.com/go-resty/resty/v2"
 	"github.com/kaleido-io/paladin/config/pkg/pldconf"
+	"github.com/kaleido-io/paladin/toolkit/pkg/rpcclient"
 	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -81,3 +83,55 @@ func TestRCPModulePanicDup(t *testing.T) {
 	})
 
 }
+
+func TestRCPModuleGroupHandler(t *testing.T) {
+
+	url, s, done := newTestServerHTTP(t, &pldconf.RPCServerConfig{})
+	defer done()
+
+	s.Register(NewRPCModule("example").
+		Add("example_test1", RPCMethod0(func(ctx context.Context) (string, error) {
+			return "result0", nil
+		})).
+		AddGroupHandler(HandlerFunc(func(ctx context.Context, req *rpcclient.RPCRequest) *rpcclient.RPCResponse {
+			return mapResponse(ctx, req, req.Method, 0, nil)
+		})),
+	)
+
+	for method, expected := range map[string]string{
+		"example_test1":         "result0",
+		"example_other_method1": "example_other_method1",
+	} {
+		var jsonResponse tktypes.RawJSON
+		res, err := resty.New().R().
+			SetBody(fmt.Sprintf(`{
+			  "jsonrpc": "2.0",
+			  "id": "1",
+			  "method": "%s",
+			  "params": []
+			}`, method)).
+			SetResult(&jsonResponse).
+			SetError(&jsonResponse).
+			Post(url)
+		require.NoError(t, err)
+		assert.True(t, res.IsSuccess())
+		assert.JSONEq(t, fmt.Sprintf(`{
+			"jsonrpc": "2.0",
+			"id": "1",
+			"result": "%s"
+		}`, expected), (string)(jsonResponse))
+	}
+
+}
+
+func TestRCPModulePanicDupGroupHandler(t *testing.T) {
+	handler := HandlerFunc(func(ctx context.Context, req *rpcclient.RPCRequest) *rpcclient.RPCResponse {
+		return nil
+	})
+	assert.Panics(t, func() {
+		_ = NewRPCModule("example").
+			AddGroupHandler(handler).
+			AddGroupHandler(handler)
+	})
+
+}
@@ -41,6 +41,9 @@ func (s *rpcServer) processRPC(ctx context.Context, rpcReq *rpcclient.RPCRequest
 	module := s.rpcModules[group]
 	if module != nil {
 		handler = module.methods[rpcReq.Method]
+		if handler == nil {
+			handler = module.fallback
+		}
 	}
 	if handler == nil {
 		err := i18n.NewError(ctx, tkmsgs.MsgJSONRPCUnsupportedMethod, rpcReq.Method)
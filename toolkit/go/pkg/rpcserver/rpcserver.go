@@ -29,7 +29,9 @@ import (
 	"github.com/kaleido-io/paladin/toolkit/pkg/httpserver"
 	"github.com/kaleido-io/paladin/toolkit/pkg/log"
 	"github.com/kaleido-io/paladin/toolkit/pkg/router"
+	"github.com/kaleido-io/paladin/toolkit/pkg/rpcclient"
 	"github.com/kaleido-io/paladin/toolkit/pkg/staticserver"
+	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
 )
 
 type RPCServer interface {
@@ -53,6 +55,10 @@ func NewRPCServer(ctx context.Context, conf *pldconf.RPCServerConfig) (_ *rpcSer
 		rpcModules:    make(map[string]*RPCModule),
 	}
 
+	if s.auth, err = newRPCAuth(ctx, &conf.Auth); err != nil {
+		return nil, err
+	}
+
 	// Add the HTTP server
 	if !conf.HTTP.Disabled {
 		r, err := router.NewRouter(s.bgCtx, "JSON/RPC (HTTP)", &conf.HTTP.HTTPServerConfig)
@@ -101,6 +107,7 @@ type rpcServer struct {
 	wsUpgrader    *websocket.Upgrader
 	wsConnections map[string]*webSocketConnection
 	rpcModules    map[string]*RPCModule
+	auth          *rpcAuth
 }
 
 func (s *rpcServer) Register(module *RPCModule) {
@@ -134,7 +141,12 @@ func (s *rpcServer) httpHandler(res http.ResponseWriter, req *http.Request) {
 		res.WriteHeader(http.StatusMethodNotAllowed)
 	}
 
-	rpcRes, isOK := s.rpcHandler(req.Context(), req.Body, nil /* not websockets */)
+	ctx, ok := s.authenticate(res, req)
+	if !ok {
+		return
+	}
+
+	rpcRes, isOK := s.rpcHandler(ctx, req.Body, nil /* not websockets */)
 
 	res.Header().Set("Content-Type", "application/json; charset=utf-8")
 	status := http.StatusOK
@@ -146,12 +158,32 @@ func (s *rpcServer) httpHandler(res http.ResponseWriter, req *http.Request) {
 }
 
 func (s *rpcServer) wsHandler(res http.ResponseWriter, req *http.Request) {
+	// Credentials are checked on the upgrade request, and apply for the life of the connection
+	ctx, ok := s.authenticate(res, req)
+	if !ok {
+		return
+	}
 	conn, err := s.wsUpgrader.Upgrade(res, req, nil)
 	if err != nil {
-		log.L(req.Context()).Errorf("WebSocket upgrade failed: %s", err)
+		log.L(ctx).Errorf("WebSocket upgrade failed: %s", err)
 		return
 	}
-	s.newWSConnection(conn)
+	s.newWSConnection(ctx, conn)
+}
+
+func (s *rpcServer) authenticate(res http.ResponseWriter, req *http.Request) (context.Context, bool) {
+	if s.auth == nil {
+		return req.Context(), true
+	}
+	ctx, err := s.auth.authenticate(req)
+	if err != nil {
+		s.auth.challenge(res)
+		res.Header().Set("Content-Type", "application/json; charset=utf-8")
+		res.WriteHeader(http.StatusUnauthorized)
+		_ = json.NewEncoder(res).Encode(rpcclient.NewRPCErrorResponse(err, tktypes.RawJSON(`"1"`), rpcclient.RPCCodeInvalidRequest))
+		return nil, false
+	}
+	return ctx, true
 }
 
 func (s *rpcServer) Start() (err error) {
@@ -34,7 +34,7 @@ import (
 	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
 )
 
-func (s *rpcServer) newWSConnection(conn *websocket.Conn) {
+func (s *rpcServer) newWSConnection(authCtx context.Context, conn *websocket.Conn) {
 	s.wsMux.Lock()
 	defer s.wsMux.Unlock()
 
@@ -44,8 +44,15 @@ func (s *rpcServer) newWSConnection(conn *websocket.Conn) {
 		conn:    conn,
 		send:    make(chan []byte),
 		closing: make(chan struct{}),
+
+		asyncInstances: make(map[string]*asyncControl),
+	}
+	// The connection outlives the upgrade request, so only the authentication is carried over
+	bgCtx := s.bgCtx
+	if ac := authCtx.Value(authContextKey{}); ac != nil {
+		bgCtx = context.WithValue(bgCtx, authContextKey{}, ac)
 	}
-	c.ctx, c.cancelCtx = context.WithCancel(log.WithLogField(s.bgCtx, "wsconn", c.id))
+	c.ctx, c.cancelCtx = context.WithCancel(log.WithLogField(bgCtx, "wsconn", c.id))
 
 	s.wsConnections[c.id] = c
 	go c.listen()
@@ -188,6 +195,10 @@ type webSocketConnection struct {
 	subscriptions []*ethSubscription // TODO: Decide JSON/RPC sub model
 	send          chan ([]byte)
 	closing       chan (struct{})
+
+	asyncMux       sync.Mutex
+	asyncClosed    bool
+	asyncInstances map[string]*asyncControl
 }
 
 type ethPublicationParams struct {
@@ -203,6 +214,7 @@ type ethPublication struct {
 
 func (c *webSocketConnection) close() {
 	c.closeMux.Lock()
+	wasClosed := c.closed
 	if !c.closed {
 		c.closed = true
 		c.conn.Close()
@@ -211,6 +223,10 @@ func (c *webSocketConnection) close() {
 	}
 	c.closeMux.Unlock()
 
+	if !wasClosed {
+		c.closeAsyncInstances()
+	}
+
 	c.server.wsClosed(c.id)
 	log.L(c.ctx).Infof("WS disconnected")
 }
@@ -234,7 +250,9 @@ func (c *webSocketConnection) sender() {
 
 func (c *webSocketConnection) handleMessage(payload []byte) {
 	res, _ := c.server.rpcHandler(c.ctx, bytes.NewBuffer(payload), c)
-	c.sendMessage(res)
+	if res != nil {
+		c.sendMessage(res)
+	}
 }
 
 func (c *webSocketConnection) sendMessage(res interface{}) {
@@ -0,0 +1,98 @@
+/*
+ * Copyright © 2024 Kaleido, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package signer
+
+import (
+	"context"
+	"time"
+
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/kaleido-io/paladin/config/pkg/confutil"
+	"github.com/kaleido-io/paladin/config/pkg/pldconf"
+	"github.com/kaleido-io/paladin/toolkit/pkg/log"
+	"github.com/kaleido-io/paladin/toolkit/pkg/retry"
+	"github.com/kaleido-io/paladin/toolkit/pkg/rpcclient"
+	"github.com/kaleido-io/paladin/toolkit/pkg/signerapi"
+	"github.com/kaleido-io/paladin/toolkit/pkg/tkmsgs"
+)
+
+// JSON/RPC methods exposed by a remote signing module, such as the one provided by NewRemoteSigningServer
+const (
+	RemoteSignerMethodResolve  = "signer_resolve"
+	RemoteSignerMethodSign     = "signer_sign"
+	RemoteSignerMethodListKeys = "signer_listKeys"
+)
+
+type remoteSigningModule struct {
+	client         rpcclient.Client
+	requestTimeout time.Duration
+	retry          *retry.Retry
+}
+
+// NewRemoteSigningModule returns a SigningModule that passes each request over JSON/RPC to
+// a signing module running in a separate process (for example in a hardened pod, isolated from Paladin).
+//
+// Requests that fail before they are processed by the remote signing module (connection failures,
+// timeouts etc.) are retried according to the retry configuration. Errors returned by the remote
+// signing module itself are returned directly to the caller.
+func NewRemoteSigningModule(ctx context.Context, conf *pldconf.RemoteSignerConfig) (_ SigningModule, err error) {
+	rsm := &remoteSigningModule{
+		requestTimeout: confutil.DurationMin(conf.RequestTimeout, 0, *pldconf.RemoteSignerDefaults.RequestTimeout),
+		retry:          retry.NewRetryLimited(&conf.Retry, &pldconf.RemoteSignerDefaults.Retry),
+	}
+	rsm.client, err = rpcclient.NewHTTPClient(ctx, &conf.HTTPClientConfig)
+	if err != nil {
+		return nil, err
+	}
+	return rsm, nil
+}
+
+func (rsm *remoteSigningModule) AddInMemorySigner(prefix string, signer signerapi.InMemorySigner) {
+	// In-memory signers (such as those for ZKP domains) must be loaded into the remote signing module
+	log.L(context.Background()).Warnf("In-memory signer '%s' cannot be added to a remote signing module", prefix)
+}
+
+func (rsm *remoteSigningModule) call(ctx context.Context, method string, req, res any) error {
+	return rsm.retry.Do(ctx, func(attempt int) (retryable bool, err error) {
+		reqCtx, cancelCtx := context.WithTimeout(ctx, rsm.requestTimeout)
+		defer cancelCtx()
+		rpcErr := rsm.client.CallRPC(reqCtx, res, method, req)
+		if rpcErr != nil {
+			// The remote signing module returns all processing errors as invalid request errors,
+			// so anything else is a failure to communicate with it that can be retried.
+			retryable = rpcErr.RPCError().Code != int64(rpcclient.RPCCodeInvalidRequest)
+			return retryable, i18n.WrapError(ctx, rpcErr, tkmsgs.MsgSigningRemoteRequestFailed, method)
+		}
+		return false, nil
+	})
+}
+
+func (rsm *remoteSigningModule) Resolve(ctx context.Context, req *signerapi.ResolveKeyRequest) (res *signerapi.ResolveKeyResponse, err error) {
+	err = rsm.call(ctx, RemoteSignerMethodResolve, req, &res)
+	return res, err
+}
+
+func (rsm *remoteSigningModule) Sign(ctx context.Context, req *signerapi.SignRequest) (res *signerapi.SignResponse, err error) {
+	err = rsm.call(ctx, RemoteSignerMethodSign, req, &res)
+	return res, err
+}
+
+func (rsm *remoteSigningModule) List(ctx context.Context, req *signerapi.ListKeysRequest) (res *signerapi.ListKeysResponse, err error) {
+	err = rsm.call(ctx, RemoteSignerMethodListKeys, req, &res)
+	return res, err
+}
+
+func (rsm *remoteSigningModule) Close() {}
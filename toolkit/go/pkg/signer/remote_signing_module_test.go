@@ -0,0 +1,212 @@
+/*
+ * Copyright © 2024 Kaleido, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package signer
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kaleido-io/paladin/config/pkg/confutil"
+	"github.com/kaleido-io/paladin/config/pkg/pldconf"
+	"github.com/kaleido-io/paladin/toolkit/pkg/algorithms"
+	"github.com/kaleido-io/paladin/toolkit/pkg/signerapi"
+	"github.com/kaleido-io/paladin/toolkit/pkg/signpayloads"
+	"github.com/kaleido-io/paladin/toolkit/pkg/verifiers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func buildSelfSignedTLSKeyPair(t *testing.T, subject pkix.Name) (string, string) {
+	privatekey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	privateKeyPEM := &strings.Builder{}
+	err := pem.Encode(privateKeyPEM, &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(privatekey)})
+	require.NoError(t, err)
+	serialNumber, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	require.NoError(t, err)
+	x509Template := &x509.Certificate{
+		SerialNumber:          serialNumber,
+		Subject:               subject,
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(100 * time.Second),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IPAddresses:           []net.IP{net.IPv4(127, 0, 0, 1)},
+	}
+	derBytes, err := x509.CreateCertificate(rand.Reader, x509Template, x509Template, &privatekey.PublicKey, privatekey)
+	require.NoError(t, err)
+	publicKeyPEM := &strings.Builder{}
+	err = pem.Encode(publicKeyPEM, &pem.Block{Type: "CERTIFICATE", Bytes: derBytes})
+	require.NoError(t, err)
+	return publicKeyPEM.String(), privateKeyPEM.String()
+}
+
+func newTestRemoteSigningServer(t *testing.T, tlsConf pldconf.TLSConfig) (string, func()) {
+	ctx := context.Background()
+
+	sm, err := NewSigningModule(ctx, &signerapi.ConfigNoExt{
+		KeyStore: pldconf.KeyStoreConfig{
+			Type: pldconf.KeyStoreTypeFilesystem,
+			FileSystem: pldconf.FileSystemKeyStoreConfig{
+				Path: confutil.P(t.TempDir()),
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	s, err := NewRemoteSigningServer(ctx, &pldconf.HTTPServerConfig{
+		Address: confutil.P("127.0.0.1"),
+		Port:    confutil.P(0),
+		TLS:     tlsConf,
+	}, sm)
+	require.NoError(t, err)
+	err = s.Start()
+	require.NoError(t, err)
+
+	scheme := "http"
+	if tlsConf.Enabled {
+		scheme = "https"
+	}
+	return fmt.Sprintf("%s://%s", scheme, s.Addr()), func() {
+		s.Stop()
+		sm.Close()
+	}
+}
+
+func TestRemoteSigningModuleMTLS(t *testing.T) {
+	ctx := context.Background()
+
+	serverCert, serverKey := buildSelfSignedTLSKeyPair(t, pkix.Name{CommonName: "server.example.com"})
+	clientCert, clientKey := buildSelfSignedTLSKeyPair(t, pkix.Name{CommonName: "client.example.com"})
+
+	url, done := newTestRemoteSigningServer(t, pldconf.TLSConfig{
+		Enabled:    true,
+		CA:         clientCert,
+		Cert:       serverCert,
+		Key:        serverKey,
+		ClientAuth: true,
+	})
+	defer done()
+
+	rsm, err := NewRemoteSigningModule(ctx, &pldconf.RemoteSignerConfig{
+		HTTPClientConfig: pldconf.HTTPClientConfig{
+			URL: url,
+			TLS: pldconf.TLSConfig{
+				CA:   serverCert,
+				Cert: clientCert,
+				Key:  clientKey,
+			},
+		},
+	})
+	require.NoError(t, err)
+	defer rsm.Close()
+
+	resolveRes, err := rsm.Resolve(ctx, &signerapi.ResolveKeyRequest{
+		RequiredIdentifiers: []*signerapi.PublicKeyIdentifierType{{Algorithm: algorithms.ECDSA_SECP256K1, VerifierType: verifiers.ETH_ADDRESS}},
+		Name:                "key1",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "key1", resolveRes.KeyHandle)
+	assert.Equal(t, algorithms.ECDSA_SECP256K1, resolveRes.Identifiers[0].Algorithm)
+	assert.NotEmpty(t, resolveRes.Identifiers[0].Verifier)
+
+	signRes, err := rsm.Sign(ctx, &signerapi.SignRequest{
+		KeyHandle:   resolveRes.KeyHandle,
+		Algorithm:   algorithms.ECDSA_SECP256K1,
+		PayloadType: signpayloads.OPAQUE_TO_RSV,
+		Payload:     ([]byte)("sign me"),
+	})
+	require.NoError(t, err)
+	assert.Len(t, signRes.Payload, 65)
+
+	// Errors from the signing module are returned without retry
+	_, err = rsm.List(ctx, &signerapi.ListKeysRequest{Limit: 10})
+	assert.Regexp(t, "PD020828.*signer_listKeys.*PD020815", err)
+
+	// In-memory signers cannot be added remotely
+	rsm.AddInMemorySigner("domain:test", nil)
+
+	// A client without a certificate is rejected, and the failure is retried
+	noCertClient, err := NewRemoteSigningModule(ctx, &pldconf.RemoteSignerConfig{
+		HTTPClientConfig: pldconf.HTTPClientConfig{
+			URL: url,
+			TLS: pldconf.TLSConfig{
+				CA: serverCert,
+			},
+		},
+		Retry: pldconf.RetryConfigWithMax{
+			RetryConfig: pldconf.RetryConfig{InitialDelay: confutil.P("1ms")},
+			MaxAttempts: confutil.P(2),
+		},
+	})
+	require.NoError(t, err)
+	_, err = noCertClient.Resolve(ctx, &signerapi.ResolveKeyRequest{Name: "key1"})
+	assert.Regexp(t, "PD020828.*signer_resolve", err)
+}
+
+func TestRemoteSigningModuleRequestTimeout(t *testing.T) {
+	ctx := context.Background()
+
+	// A listener that accepts connections, but never responds
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+
+	rsm, err := NewRemoteSigningModule(ctx, &pldconf.RemoteSignerConfig{
+		HTTPClientConfig: pldconf.HTTPClientConfig{
+			URL: fmt.Sprintf("http://%s", l.Addr()),
+		},
+		RequestTimeout: confutil.P("10ms"),
+		Retry: pldconf.RetryConfigWithMax{
+			RetryConfig: pldconf.RetryConfig{InitialDelay: confutil.P("1ms")},
+			MaxAttempts: confutil.P(3),
+		},
+	})
+	require.NoError(t, err)
+
+	_, err = rsm.Sign(ctx, &signerapi.SignRequest{KeyHandle: "key1"})
+	assert.Regexp(t, "PD020828.*signer_sign", err)
+}
+
+func TestRemoteSigningModuleBadConfig(t *testing.T) {
+	_, err := NewRemoteSigningModule(context.Background(), &pldconf.RemoteSignerConfig{
+		HTTPClientConfig: pldconf.HTTPClientConfig{
+			URL: "wrong://",
+		},
+	})
+	assert.Regexp(t, "PD020501", err)
+}
+
+func TestRemoteSigningServerBadConfig(t *testing.T) {
+	_, err := NewRemoteSigningServer(context.Background(), &pldconf.HTTPServerConfig{
+		Address: confutil.P("127.0.0.1"),
+		Port:    confutil.P(0),
+		TLS: pldconf.TLSConfig{
+			Enabled: true,
+			CAFile:  t.TempDir(),
+		},
+	}, nil)
+	assert.Error(t, err)
+}
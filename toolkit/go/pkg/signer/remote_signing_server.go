@@ -0,0 +1,82 @@
+/*
+ * Copyright © 2024 Kaleido, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package signer
+
+import (
+	"context"
+	"net"
+
+	"github.com/kaleido-io/paladin/config/pkg/pldconf"
+	"github.com/kaleido-io/paladin/toolkit/pkg/rpcclient"
+	"github.com/kaleido-io/paladin/toolkit/pkg/rpcserver"
+)
+
+// RemoteSigningServer is a reference implementation of a server for the remote signing module,
+// exposing any SigningModule (such as one built with NewSigningModule) over JSON/RPC (HTTP).
+//
+// Use the TLS settings of the server configuration with clientAuth enabled for mTLS.
+type RemoteSigningServer interface {
+	Start() error
+	Stop()
+	Addr() net.Addr
+}
+
+type remoteSigningServer struct {
+	sm        SigningModule
+	rpcServer rpcserver.RPCServer
+}
+
+func NewRemoteSigningServer(ctx context.Context, conf *pldconf.HTTPServerConfig, sm SigningModule) (_ RemoteSigningServer, err error) {
+	s := &remoteSigningServer{sm: sm}
+	s.rpcServer, err = rpcserver.NewRPCServer(ctx, &pldconf.RPCServerConfig{
+		HTTP: pldconf.RPCServerConfigHTTP{HTTPServerConfig: *conf},
+		WS:   pldconf.RPCServerConfigWS{Disabled: true},
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.rpcServer.Register(rpcserver.NewRPCModule("signer").
+		Add(RemoteSignerMethodResolve, remoteSignerMethod(s.sm.Resolve)).
+		Add(RemoteSignerMethodSign, remoteSignerMethod(s.sm.Sign)).
+		Add(RemoteSignerMethodListKeys, remoteSignerMethod(s.sm.List)),
+	)
+	return s, nil
+}
+
+// All errors from the signing module are returned as invalid request errors, so the
+// client can distinguish them from communication failures that are safe to retry.
+func remoteSignerMethod[Req, Res any](impl func(ctx context.Context, req *Req) (*Res, error)) rpcserver.RPCHandler {
+	handler := rpcserver.RPCMethod1(impl)
+	return rpcserver.HandlerFunc(func(ctx context.Context, req *rpcclient.RPCRequest) *rpcclient.RPCResponse {
+		res := handler.Handle(ctx, req)
+		if res.Error != nil {
+			res.Error.Code = int64(rpcclient.RPCCodeInvalidRequest)
+		}
+		return res
+	})
+}
+
+func (s *remoteSigningServer) Start() error {
+	return s.rpcServer.Start()
+}
+
+func (s *remoteSigningServer) Stop() {
+	s.rpcServer.Stop()
+}
+
+func (s *remoteSigningServer) Addr() net.Addr {
+	return s.rpcServer.HTTPAddr()
+}
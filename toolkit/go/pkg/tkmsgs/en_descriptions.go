@@ -50,6 +50,20 @@ var (
 	EventWithDataSoliditySignature     = ffm("EventWithData.soliditySignature", "A Solidity style description of the event and parameters, including parameter names and whether they are indexed")
 	EventWithDataAddress               = ffm("EventWithData.address", "The address of the smart contract that emitted this event")
 	EventWithDataData                  = ffm("EventWithData.data", "JSON formatted data from the event")
+	EventStreamID                      = ffm("EventStream.id", "Server-generated identifier for the event stream")
+	EventStreamName                    = ffm("EventStream.name", "Unique name for the event stream")
+	EventStreamCreated                 = ffm("EventStream.created", "Time the event stream was created")
+	EventStreamStarted                 = ffm("EventStream.started", "If the event stream is started - can be set to false to disable delivery server-side")
+	EventStreamSources                 = ffm("EventStream.sources", "The event ABIs, and optional contract addresses, to deliver events for. Cannot be changed after creation")
+	EventStreamConfig                  = ffm("EventStream.config", "Batching and checkpoint configuration for the event stream")
+	EventStreamFormat                  = ffm("EventStream.format", "JSON formatting options for the decoded event data")
+	EventStreamSourceABI               = ffm("EventStreamSource.abi", "ABI containing the event definitions to match")
+	EventStreamSourceAddress           = ffm("EventStreamSource.address", "Only match events emitted by this contract address - if unset events from any address are matched")
+	EventStreamConfigBatchSize         = ffm("EventStreamConfig.batchSize", "Maximum number of events to deliver in each batch")
+	EventStreamConfigBatchTimeout      = ffm("EventStreamConfig.batchTimeout", "Maximum time to wait for a batch to fill before delivering a partial batch")
+	EventStreamConfigFromBlock         = ffm("EventStreamConfig.fromBlock", "The block to start delivering events from when the stream is created - a block number, or 'latest'. Defaults to the earliest indexed block")
+	EventStreamBatchBatchID            = ffm("EventStreamBatch.batchId", "Unique identifier for the batch - each batch must be acknowledged with bidx_ack before the next is delivered")
+	EventStreamBatchEvents             = ffm("EventStreamBatch.events", "The events in this batch, in blockchain order")
 )
 
 // pldapi/keymgr.go
@@ -80,6 +94,7 @@ var (
 	PublicTxInputFrom                      = ffm("PublicTxInput.from", "The resolved signing account")
 	PublicTxInputTo                        = ffm("PublicTxInput.to", "The target contract address (optional)")
 	PublicTxInputData                      = ffm("PublicTxInput.data", "The pre-encoded calldata (optional)")
+	PublicTxUpdateData                     = ffm("PublicTxUpdate.data", "The new pre-encoded calldata (optional)")
 	PublicTxSubmissionFrom                 = ffm("PublicTxSubmission.from", "The sender's Ethereum address")
 	PublicTxSubmissionNonce                = ffm("PublicTxSubmission.nonce", "The transaction nonce")
 	PublicTxSubmissionDataTime             = ffm("PublicTxSubmissionData.time", "The submission time")
@@ -141,6 +156,18 @@ var (
 	TransactionReceiptFullStates                  = ffm("TransactionReceiptFull.states", "The state receipt for the transaction (private transactions only)")
 	TransactionReceiptFullDomainReceipt           = ffm("TransactionReceiptFull.domainReceipt", "The domain receipt for the transaction (private transaction only)")
 	TransactionReceiptFullDomainReceiptError      = ffm("TransactionReceiptFull.domainReceiptError", "Contains the error if it was not possible to obtain the domain receipt for a private transaction")
+	TransactionReceiptSequence                    = ffm("TransactionReceipt.sequence", "A local sequence number assigned by this node when the receipt was written, giving a total order for delivery of receipts to listeners")
+	TransactionReceiptListenerName                = ffm("TransactionReceiptListener.name", "Unique name for the receipt listener")
+	TransactionReceiptListenerCreated             = ffm("TransactionReceiptListener.created", "Time the listener was created")
+	TransactionReceiptListenerStarted             = ffm("TransactionReceiptListener.started", "If the listener is started - can be set to false to disable delivery server-side")
+	TransactionReceiptListenerFilters             = ffm("TransactionReceiptListener.filters", "Filters to apply to receipts before delivery")
+	TransactionReceiptListenerOptions             = ffm("TransactionReceiptListener.options", "Options for how receipts are delivered")
+	TransactionReceiptFiltersSequenceAbove        = ffm("TransactionReceiptFilters.sequenceAbove", "Only deliver receipts with a sequence number above this value - if unset the listener starts from the beginning of the receipts table")
+	TransactionReceiptFiltersType                 = ffm("TransactionReceiptFilters.type", "Only deliver receipts for transactions of this type (public or private)")
+	TransactionReceiptFiltersDomain               = ffm("TransactionReceiptFilters.domain", "Only deliver receipts for private transactions in this domain")
+	TransactionReceiptListenerOptionsDomainRcpts  = ffm("TransactionReceiptListenerOptions.domainReceipts", "When true the full domain receipt and state receipt are included with private transaction receipts")
+	TransactionReceiptBatchBatchID                = ffm("TransactionReceiptBatch.batchId", "Batch number - each batch must be acknowledged with ptx_ack before the next is delivered")
+	TransactionReceiptBatchReceipts               = ffm("TransactionReceiptBatch.receipts", "The receipts in this batch, in sequence order")
 	TransactionActivityRecordTime                 = ffm("TransactionActivityRecord.time", "Time the record occurred")
 	TransactionActivityRecordMessage              = ffm("TransactionActivityRecord.message", "Activity message")
 	TransactionDependenciesDependsOn              = ffm("TransactionDependencies.dependsOn", "Transactions that this transaction depends on")
@@ -241,5 +268,49 @@ var (
 	OnChainLocationBlockNumber            = ffm("OnChainLocation.blockNumber", "For Ethereum blockchain backed registries, this is the block number where the registry entry/property was set")
 	OnChainLocationTransactionIndex       = ffm("OnChainLocation.transactionIndex", "The transaction index within the block")
 	OnChainLocationLogIndex               = ffm("OnChainLocation.logIndex", "The log index within the transaction of the event")
+	RegistryIdentityInputFrom             = ffm("RegistryIdentityInput.from", "The key identifier used to sign the registration transaction, which must be authorized by the registry to register entries under the parent")
+	RegistryIdentityInputParentID         = ffm("RegistryIdentityInput.parentId", "Unset to register a root record, otherwise the ID of the parent entry in the same registry")
+	RegistryIdentityInputName             = ffm("RegistryIdentityInput.name", "The name of the new entry, which must be unique across entries with the same parent")
+	RegistryIdentityInputOwner            = ffm("RegistryIdentityInput.owner", "The owner of the new entry, in the format understood by the registry. For the EVM registry this is an Ethereum address")
+	RegistryPropertyInputFrom             = ffm("RegistryPropertyInput.from", "The key identifier used to sign the transaction, which must be authorized by the registry to update the entry")
+	RegistryPropertyInputEntryID          = ffm("RegistryPropertyInput.entryId", "The ID of the entry to set the property on")
+	RegistryPropertyInputName             = ffm("RegistryPropertyInput.name", "The name of the property")
+	RegistryPropertyInputValue            = ffm("RegistryPropertyInput.value", "The value of the property")
 	ActiveFlagActive                      = ffm("ActiveFlag.active", "When querying with an activeFilter of 'any' or 'inactive', this boolean shows if the entry/property is active or not")
 )
+
+// pldapi/domains.go
+var (
+	DomainName                               = ffm("Domain.name", "The name of the domain, as configured on this node")
+	DomainRegistryAddress                    = ffm("Domain.registryAddress", "The address of the factory contract for the domain, which registers all private smart contracts deployed through it")
+	DomainSmartContractDomainName            = ffm("DomainSmartContract.domainName", "The name of the domain the smart contract belongs to. Unset if the domain registry is not configured on this node")
+	DomainSmartContractDomainAddress         = ffm("DomainSmartContract.domainAddress", "The address of the factory contract that registered the smart contract")
+	DomainSmartContractAddress               = ffm("DomainSmartContract.address", "The address of the private smart contract")
+	DomainSmartContractDeployTransaction     = ffm("DomainSmartContract.deployTransaction", "The ID of the Paladin transaction that deployed the smart contract")
+	DomainSmartContractConfig                = ffm("DomainSmartContract.config", "The configuration of the smart contract as decoded by the domain. Unset if the domain is not configured on this node, or the domain rejected the configuration")
+	DomainContractConfigContractConfig       = ffm("DomainContractConfig.contractConfig", "Domain specific JSON configuration of the smart contract")
+	DomainContractConfigCoordinatorSelection = ffm("DomainContractConfig.coordinatorSelection", "How the coordinator of transactions is selected for the smart contract")
+	DomainContractConfigStaticCoordinator    = ffm("DomainContractConfig.staticCoordinator", "The fixed coordinator of transactions, when the coordinator selection is COORDINATOR_STATIC")
+	DomainContractConfigSubmitterSelection   = ffm("DomainContractConfig.submitterSelection", "How the submitter of the base ledger transaction is selected for the smart contract")
+)
+
+// pldapi/transport.go
+var (
+	PeerInfoName              = ffm("PeerInfo.name", "The name of the remote node")
+	PeerInfoActivated         = ffm("PeerInfo.activated", "The time this node first sent a message to, or received a message from, the peer since it started")
+	PeerInfoOutbound          = ffm("PeerInfo.outbound", "The registry entry and local transport resolved for the most recent message sent to the peer")
+	PeerInfoConnections       = ffm("PeerInfo.connections", "Transport specific connection state for the peer, reported by each local transport plugin that has any")
+	PeerInfoStats             = ffm("PeerInfo.stats", "Statistics for the messages exchanged with the peer since this node started")
+	PeerInfoErrors            = ffm("PeerInfo.errors", "The most recent errors sending messages to, or processing messages received from, the peer - newest first")
+	PeerOutboundTransport     = ffm("PeerOutbound.transport", "The name of the local transport used to send to the peer")
+	PeerOutboundRegistry      = ffm("PeerOutbound.registry", "The name of the registry the transport details of the peer were resolved from")
+	PeerStatsSentMsgs         = ffm("PeerStats.sentMsgs", "The number of messages accepted by the transport for sending to the peer")
+	PeerStatsSendErrors       = ffm("PeerStats.sendErrors", "The number of messages that could not be sent to the peer")
+	PeerStatsLastSend         = ffm("PeerStats.lastSend", "The time of the last message sent to the peer")
+	PeerStatsReceivedMsgs     = ffm("PeerStats.receivedMsgs", "The number of messages received from the peer")
+	PeerStatsLastReceive      = ffm("PeerStats.lastReceive", "The time of the last message received from the peer")
+	PeerStatsReliableQueued   = ffm("PeerStats.reliableQueued", "The number of reliable messages persisted for the peer that have not been acknowledged")
+	PeerStatsReliableInFlight = ffm("PeerStats.reliableInFlight", "The number of reliable messages sent to the peer that are awaiting acknowledgement")
+	PeerErrorTime             = ffm("PeerError.time", "The time of the error")
+	PeerErrorMessage          = ffm("PeerError.message", "The error message")
+)
@@ -100,12 +100,25 @@ var (
 	MsgUIServerFailed               = ffe("PD020603", "HTTP server failed to load index file", 500)
 
 	// JSON/RPC PD0207XX
-	MsgJSONRPCInvalidRequest      = ffe("PD020700", "Invalid JSON/RPC request data")
-	MsgJSONRPCMissingRequestID    = ffe("PD020701", "Invalid JSON/RPC request. Must set request ID")
-	MsgJSONRPCUnsupportedMethod   = ffe("PD020702", "method not supported %s")
-	MsgJSONRPCIncorrectParamCount = ffe("PD020703", "method %s requires %d params (supplied=%d)")
-	MsgJSONRPCInvalidParam        = ffe("PD020704", "method %s parameter %d invalid: %s")
-	MsgJSONRPCResultSerialization = ffe("PD020705", "method %s result serialization failed: %s")
+	MsgJSONRPCInvalidRequest       = ffe("PD020700", "Invalid JSON/RPC request data")
+	MsgJSONRPCMissingRequestID     = ffe("PD020701", "Invalid JSON/RPC request. Must set request ID")
+	MsgJSONRPCUnsupportedMethod    = ffe("PD020702", "method not supported %s")
+	MsgJSONRPCIncorrectParamCount  = ffe("PD020703", "method %s requires %d params (supplied=%d)")
+	MsgJSONRPCInvalidParam         = ffe("PD020704", "method %s parameter %d invalid: %s")
+	MsgJSONRPCResultSerialization  = ffe("PD020705", "method %s result serialization failed: %s")
+	MsgJSONRPCAsyncNonWSConn       = ffe("PD020706", "method %s is only available on WebSocket connections")
+	MsgJSONRPCUnauthorized         = ffe("PD020707", "Unauthorized", 401)
+	MsgJSONRPCMethodForbidden      = ffe("PD020708", "Principal '%s' is not authorized to call method %s", 403)
+	MsgJSONRPCKeyForbidden         = ffe("PD020709", "Principal '%s' is not authorized to use key '%s'", 403)
+	MsgJSONRPCAuthInvalidKeyRegex  = ffe("PD020710", "Invalid key pattern '%s' in authorization policy: %s")
+	MsgJSONRPCAuthJWKSLoadFailed   = ffe("PD020711", "Failed to load JWKS file '%s'")
+	MsgJSONRPCAuthJWKSInvalidKey   = ffe("PD020712", "Invalid key '%s' in JWKS file: %s")
+	MsgJSONRPCAuthInvalidJWT       = ffe("PD020713", "Invalid JWT: %s")
+	MsgJSONRPCAuthBadPasswordHash  = ffe("PD020714", "Invalid bcrypt password hash for user '%s'")
+	MsgJSONRPCBadSubscriptionType  = ffe("PD020715", "Invalid subscription type '%s'")
+	MsgJSONRPCSubscriptionNotFound = ffe("PD020716", "Subscription '%s' not found")
+	MsgJSONRPCBatchNack            = ffe("PD020717", "Batch %v was rejected by the receiver")
+	MsgJSONRPCSubscriptionClosed   = ffe("PD020718", "Subscription closed before batch %v was acknowledged")
 
 	// Signing module PD0208XX
 	MsgSigningModuleBadPathError                = ffe("PD020800", "Path '%s' does not exist, or it is not a directory")
@@ -136,6 +149,7 @@ var (
 	MsgSigningEmptyPayload                      = ffe("PD020825", "No payload supplied for signing")
 	MsgSigningInvalidDomainAlgorithmNoPrefix    = ffe("PD020826", "Invalid domain algorithm (no 'domain:' prefix): %s")
 	MsgSigningNoDomainRegisteredWithModule      = ffe("PD020827", "Domain '%s' has not been registered in this signing module")
+	MsgSigningRemoteRequestFailed               = ffe("PD020828", "Request '%s' to remote signing module failed")
 
 	// Reference markdown PD0209XX
 	MsgReferenceMarkdownMissing = ffe("PD020900", "Reference markdown file missing: '%s'")
@@ -144,4 +158,12 @@ var (
 	// SolUtils module PD0210XX
 	MsgSolBuildParseFailed = ffe("PD021000", "Invalid link hash at position %d in bytecode. Fully qualified lib name: %s. Placeholder: %s. Lib name hash prefix: %s")
 	MsgSolBuildMissingLink = ffe("PD021001", "The solidity build is unlinked and requires an address for '%s'")
+
+	// TLS peer verification PD0211XX
+	MsgTLSVerifierRequiresOneCert      = ffe("PD021100", "certificate verifier expected exactly one certificate from peer certs=%d")
+	MsgTLSSubjectRegexpMismatch        = ffe("PD021101", "subjectMatchRegex did not match the subject in the certificate")
+	MsgTLSPeerCertificateIssuerInvalid = ffe("PD021102", "peer '%s' did not provide a certificate signed an expected issuer received=%s issuers=%v")
+	MsgTLSConnectionToWrongNode        = ffe("PD021103", "the TLS identity of the node '%s' does not match the expected node '%s'")
+	MsgTLSPEMCertificateInvalid        = ffe("PD021104", "invalid PEM encoded x509 certificate")
+	MsgTLSPeerCertificateNoLongerValid = ffe("PD021105", "certificate presented by peer '%s' is no longer valid")
 )
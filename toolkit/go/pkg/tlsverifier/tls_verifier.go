@@ -0,0 +1,184 @@
+/*
+ * Copyright © 2024 Kaleido, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package tlsverifier
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"regexp"
+	"time"
+
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/kaleido-io/paladin/toolkit/pkg/log"
+	"github.com/kaleido-io/paladin/toolkit/pkg/tkmsgs"
+)
+
+// IssuersLookup asks the Paladin server/registry for the PEM issuer certificates published by a node,
+// returning an error if the node is unknown or its published details are invalid
+type IssuersLookup func(ctx context.Context, node string) (issuers string, err error)
+
+// Verifier performs peer verification against the paladin registry as part of the TLS handshake,
+// for both the server and the client side of the connections of a transport.
+//
+// The node name is extracted from the subject of the single certificate provided by the peer,
+// and in direct certificate verification mode the certificate must be signed by (or be) one of
+// the issuers the node publishes in the registry.
+type Verifier struct {
+	bgCtx                  context.Context
+	directCertVerification bool
+	subjectMatchRegex      *regexp.Regexp
+	lookupIssuers          IssuersLookup
+}
+
+// VerifiedPeer is the outcome of a successful verification
+type VerifiedPeer struct {
+	Node string
+	Cert *x509.Certificate
+}
+
+func NewVerifier(bgCtx context.Context, directCertVerification bool, subjectMatchRegex *regexp.Regexp, lookupIssuers IssuersLookup) *Verifier {
+	return &Verifier{
+		bgCtx:                  bgCtx,
+		directCertVerification: directCertVerification,
+		subjectMatchRegex:      subjectMatchRegex,
+		lookupIssuers:          lookupIssuers,
+	}
+}
+
+// TLSConfig returns a copy of the base configuration that verifies the peer in VerifyConnection.
+// On the client side we connect expecting to find a particular node on the other side, and on
+// the server side expectedNode is empty. The optional onVerified function is called with the
+// peer once it has been verified.
+func (v *Verifier) TLSConfig(baseTLSConfig *tls.Config, expectedNode string, onVerified func(*VerifiedPeer)) *tls.Config {
+	tlsConfig := baseTLSConfig.Clone()
+	tlsConfig.VerifyConnection = func(cs tls.ConnectionState) error {
+		peer, err := v.VerifyPeer(v.bgCtx, &cs, expectedNode)
+		if err == nil && onVerified != nil {
+			onVerified(peer)
+		}
+		return err
+	}
+	return tlsConfig
+}
+
+func GetCertListFromPEM(ctx context.Context, pemBytes []byte) (certs []*x509.Certificate, err error) {
+	for {
+		block, remaining := pem.Decode(pemBytes)
+		if block == nil {
+			break
+		}
+		pemBytes = remaining
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, i18n.WrapError(ctx, err, tkmsgs.MsgTLSPEMCertificateInvalid)
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return nil, i18n.NewError(ctx, tkmsgs.MsgTLSPEMCertificateInvalid)
+	}
+	return certs, err
+}
+
+// PeerNodeName extracts the node name from the single certificate provided by the peer.
+// This is cheap, so can be used on each request over a connection to identify a peer that
+// was verified against the registry when the TLS connection was established.
+func (v *Verifier) PeerNodeName(ctx context.Context, cs *tls.ConnectionState) (string, *x509.Certificate, error) {
+	if len(cs.PeerCertificates) != 1 {
+		// We currently require exactly one certificate to be provided by the peer
+		return "", nil, i18n.NewError(ctx, tkmsgs.MsgTLSVerifierRequiresOneCert, len(cs.PeerCertificates))
+	}
+	cert := cs.PeerCertificates[0]
+
+	if v.subjectMatchRegex != nil {
+		match := v.subjectMatchRegex.FindStringSubmatch(cert.Subject.String())
+		if len(match) != 2 /* we require one capture group */ {
+			log.L(ctx).Errorf("subject regexp '%s' mismatch on '%s' len=%d (0:fail,1:no-groups,2+:too-many-groups)",
+				v.subjectMatchRegex, cert.Subject, len(match))
+			return "", nil, i18n.NewError(ctx, tkmsgs.MsgTLSSubjectRegexpMismatch)
+		}
+		return match[1], cert, nil
+	}
+	return cert.Subject.CommonName, cert, nil
+}
+
+// VerifyPeer verifies the certificate provided by the peer during a TLS handshake
+func (v *Verifier) VerifyPeer(ctx context.Context, cs *tls.ConnectionState, expectedNode string) (*VerifiedPeer, error) {
+	node, cert, err := v.PeerNodeName(ctx, cs)
+	if err != nil {
+		return nil, err
+	}
+	log.L(ctx).Infof("Received certificate %s (serial=%s)", cert.Subject.String(), cert.SerialNumber.Text(16))
+
+	if expectedNode != "" && node != expectedNode {
+		return nil, i18n.NewError(ctx, tkmsgs.MsgTLSConnectionToWrongNode, node, expectedNode)
+	}
+
+	// Ask the Paladin server/registry for details of the node we are peering with
+	issuers, err := v.lookupIssuers(ctx, node)
+	if err != nil {
+		log.L(ctx).Error(err.Error())
+		return nil, err
+	}
+
+	// If we need to check the issuer, do that now
+	if err := v.VerifyIssuer(ctx, node, cert, issuers); err != nil {
+		return nil, err
+	}
+	return &VerifiedPeer{Node: node, Cert: cert}, nil
+}
+
+// VerifyIssuer checks the certificate against the issuers the node currently publishes in the registry,
+// in direct certificate verification mode. There can be more than one during a rotation.
+func (v *Verifier) VerifyIssuer(ctx context.Context, node string, cert *x509.Certificate, issuers string) error {
+	if !v.directCertVerification {
+		return nil
+	}
+	issuerCerts, err := GetCertListFromPEM(ctx, []byte(issuers))
+	if err != nil {
+		return err
+	}
+	rootPool := x509.NewCertPool()
+	issuerSubjects := []string{}
+	for _, issuerCert := range issuerCerts {
+		rootPool.AddCert(issuerCert)
+		issuerSubjects = append(issuerSubjects, issuerCert.Subject.String())
+	}
+	if _, err = cert.Verify(x509.VerifyOptions{
+		// Only need to verify up to that issuer
+		Roots: rootPool,
+		// We do not verify key usages
+		KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	}); err != nil {
+		return i18n.WrapError(ctx, err, tkmsgs.MsgTLSPeerCertificateIssuerInvalid,
+			node, cert.Issuer.String(), issuerSubjects,
+		)
+	}
+	return nil
+}
+
+// RecheckPeer checks a certificate a peer connected with earlier is still valid, against the latest registered issuers for the node
+func (v *Verifier) RecheckPeer(ctx context.Context, node string, cert *x509.Certificate, issuers string) error {
+	if now := time.Now(); now.Before(cert.NotBefore) || now.After(cert.NotAfter) {
+		return i18n.NewError(ctx, tkmsgs.MsgTLSPeerCertificateNoLongerValid, node)
+	}
+	if err := v.VerifyIssuer(ctx, node, cert, issuers); err != nil {
+		return i18n.WrapError(ctx, err, tkmsgs.MsgTLSPeerCertificateNoLongerValid, node)
+	}
+	return nil
+}
@@ -0,0 +1,265 @@
+/*
+ * Copyright © 2024 Kaleido, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package tlsverifier
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testCert struct {
+	pem  string
+	key  *rsa.PrivateKey
+	cert *x509.Certificate
+}
+
+func buildTestCertificate(t *testing.T, subject pkix.Name, ca *testCert) *testCert {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 1024 /* smallish key to make the test faster */)
+	require.NoError(t, err)
+	serialNumber, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	require.NoError(t, err)
+	x509Template := &x509.Certificate{
+		SerialNumber:          serialNumber,
+		Subject:               subject,
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(100 * time.Second),
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageServerAuth},
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+	}
+	parent, parentKey := x509Template, privateKey
+	if ca != nil {
+		parent, parentKey = ca.cert, ca.key
+	} else {
+		x509Template.IsCA = true
+		x509Template.KeyUsage |= x509.KeyUsageCertSign
+	}
+	derBytes, err := x509.CreateCertificate(rand.Reader, x509Template, parent, &privateKey.PublicKey, parentKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(derBytes)
+	require.NoError(t, err)
+	certPEM := &strings.Builder{}
+	err = pem.Encode(certPEM, &pem.Block{Type: "CERTIFICATE", Bytes: derBytes})
+	require.NoError(t, err)
+	return &testCert{pem: certPEM.String(), key: privateKey, cert: cert}
+}
+
+func (tc *testCert) tlsCertificate() tls.Certificate {
+	return tls.Certificate{Certificate: [][]byte{tc.cert.Raw}, PrivateKey: tc.key, Leaf: tc.cert}
+}
+
+func registry(entries map[string]string) IssuersLookup {
+	return func(ctx context.Context, node string) (string, error) {
+		issuers, ok := entries[node]
+		if !ok {
+			return "", fmt.Errorf("not found")
+		}
+		return issuers, nil
+	}
+}
+
+func peerState(certs ...*testCert) *tls.ConnectionState {
+	cs := &tls.ConnectionState{}
+	for _, c := range certs {
+		cs.PeerCertificates = append(cs.PeerCertificates, c.cert)
+	}
+	return cs
+}
+
+func TestGetCertListFromPEM(t *testing.T) {
+	ctx := context.Background()
+	cert1 := buildTestCertificate(t, pkix.Name{CommonName: "node1"}, nil)
+	cert2 := buildTestCertificate(t, pkix.Name{CommonName: "node2"}, nil)
+
+	certs, err := GetCertListFromPEM(ctx, []byte(cert1.pem+cert2.pem))
+	require.NoError(t, err)
+	require.Len(t, certs, 2)
+	assert.Equal(t, "node1", certs[0].Subject.CommonName)
+	assert.Equal(t, "node2", certs[1].Subject.CommonName)
+
+	_, err = GetCertListFromPEM(ctx, []byte("Not a PEM"))
+	assert.Regexp(t, "PD021104", err)
+
+	badDER := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: []byte("wrong")})
+	_, err = GetCertListFromPEM(ctx, badDER)
+	assert.Regexp(t, "PD021104", err)
+}
+
+func TestPeerNodeName(t *testing.T) {
+	ctx := context.Background()
+	cert := buildTestCertificate(t, pkix.Name{CommonName: "node1", Organization: []string{"org1"}}, nil)
+
+	node, _, err := NewVerifier(ctx, true, nil, nil).PeerNodeName(ctx, peerState(cert))
+	require.NoError(t, err)
+	assert.Equal(t, "node1", node)
+
+	node, _, err = NewVerifier(ctx, true, regexp.MustCompile(`O=([0-9a-zA-Z]*)`), nil).PeerNodeName(ctx, peerState(cert))
+	require.NoError(t, err)
+	assert.Equal(t, "org1", node)
+
+	_, _, err = NewVerifier(ctx, true, regexp.MustCompile(`^OU=([0-9a-zA-Z]*)$`), nil).PeerNodeName(ctx, peerState(cert))
+	assert.Regexp(t, "PD021101", err)
+
+	_, _, err = NewVerifier(ctx, true, nil, nil).PeerNodeName(ctx, peerState())
+	assert.Regexp(t, "PD021100.*certs=0", err)
+
+	_, _, err = NewVerifier(ctx, true, nil, nil).PeerNodeName(ctx, peerState(cert, cert))
+	assert.Regexp(t, "PD021100.*certs=2", err)
+}
+
+func TestVerifyPeerDirectCertVerification(t *testing.T) {
+	ctx := context.Background()
+	node1 := buildTestCertificate(t, pkix.Name{CommonName: "node1"}, nil)
+	node1Old := buildTestCertificate(t, pkix.Name{CommonName: "node1"}, nil)
+	other := buildTestCertificate(t, pkix.Name{CommonName: "node1"}, nil)
+
+	v := NewVerifier(ctx, true, nil, registry(map[string]string{"node1": node1.pem}))
+	peer, err := v.VerifyPeer(ctx, peerState(node1), "")
+	require.NoError(t, err)
+	assert.Equal(t, "node1", peer.Node)
+	assert.Equal(t, node1.cert, peer.Cert)
+
+	peer, err = v.VerifyPeer(ctx, peerState(node1), "node1")
+	require.NoError(t, err)
+	assert.Equal(t, "node1", peer.Node)
+
+	// During a key rotation more than one issuer is published
+	v = NewVerifier(ctx, true, nil, registry(map[string]string{"node1": node1Old.pem + node1.pem}))
+	_, err = v.VerifyPeer(ctx, peerState(node1), "")
+	require.NoError(t, err)
+
+	v = NewVerifier(ctx, true, nil, registry(map[string]string{"node1": other.pem}))
+	_, err = v.VerifyPeer(ctx, peerState(node1), "")
+	assert.Regexp(t, "PD021102", err)
+
+	v = NewVerifier(ctx, true, nil, registry(map[string]string{"node1": "Not a PEM"}))
+	_, err = v.VerifyPeer(ctx, peerState(node1), "")
+	assert.Regexp(t, "PD021104", err)
+}
+
+func TestVerifyPeerCAVerification(t *testing.T) {
+	ctx := context.Background()
+	ca := buildTestCertificate(t, pkix.Name{CommonName: "ca"}, nil)
+	node1 := buildTestCertificate(t, pkix.Name{CommonName: "node1"}, ca)
+
+	// The TLS stack verifies the chain to the CA, so the published issuers are not checked
+	v := NewVerifier(ctx, false, regexp.MustCompile(`^.*CN=([0-9A-Za-z._-]+).*$`), registry(map[string]string{"node1": ""}))
+	peer, err := v.VerifyPeer(ctx, peerState(node1), "node1")
+	require.NoError(t, err)
+	assert.Equal(t, "node1", peer.Node)
+}
+
+func TestVerifyPeerFailures(t *testing.T) {
+	ctx := context.Background()
+	node1 := buildTestCertificate(t, pkix.Name{CommonName: "node1"}, nil)
+
+	v := NewVerifier(ctx, true, nil, registry(map[string]string{"node1": node1.pem}))
+	_, err := v.VerifyPeer(ctx, peerState(node1), "node3")
+	assert.Regexp(t, "PD021103.*node1.*node3", err)
+
+	_, err = v.VerifyPeer(ctx, peerState(), "")
+	assert.Regexp(t, "PD021100", err)
+
+	v = NewVerifier(ctx, true, nil, registry(map[string]string{}))
+	_, err = v.VerifyPeer(ctx, peerState(node1), "")
+	assert.Regexp(t, "not found", err)
+}
+
+func TestTLSConfigHandshake(t *testing.T) {
+	ctx := context.Background()
+	node1 := buildTestCertificate(t, pkix.Name{CommonName: "node1"}, nil)
+	node2 := buildTestCertificate(t, pkix.Name{CommonName: "node2"}, nil)
+	v := NewVerifier(ctx, true, nil, registry(map[string]string{"node1": node1.pem, "node2": node2.pem}))
+
+	handshake := func(clientCert, serverCert *testCert, expectedNode string) (clientPeer, serverPeer *VerifiedPeer, clientErr, serverErr error) {
+		base := &tls.Config{
+			MinVersion: tls.VersionTLS12,
+			// Skip the default verification, as VerifyConnection replaces it
+			InsecureSkipVerify: true,
+			ClientAuth:         tls.RequireAnyClientCert,
+		}
+		clientConf := v.TLSConfig(base, expectedNode, func(vp *VerifiedPeer) { clientPeer = vp })
+		clientConf.Certificates = []tls.Certificate{clientCert.tlsCertificate()}
+		serverConf := v.TLSConfig(base, "", func(vp *VerifiedPeer) { serverPeer = vp })
+		serverConf.Certificates = []tls.Certificate{serverCert.tlsCertificate()}
+
+		c1, c2 := net.Pipe()
+		defer c1.Close()
+		defer c2.Close()
+		serverDone := make(chan error)
+		go func() {
+			err := tls.Server(c2, serverConf).HandshakeContext(ctx)
+			_ = c2.Close()
+			serverDone <- err
+		}()
+		clientErr = tls.Client(c1, clientConf).HandshakeContext(ctx)
+		_ = c1.Close()
+		serverErr = <-serverDone
+		return
+	}
+
+	clientPeer, serverPeer, clientErr, serverErr := handshake(node1, node2, "node2")
+	require.NoError(t, clientErr)
+	require.NoError(t, serverErr)
+	assert.Equal(t, "node2", clientPeer.Node)
+	assert.Equal(t, "node1", serverPeer.Node)
+
+	// The client is connecting to the wrong node
+	clientPeer, _, clientErr, _ = handshake(node1, node2, "node3")
+	assert.Regexp(t, "PD021103", clientErr)
+	assert.Nil(t, clientPeer)
+}
+
+func TestRecheckPeer(t *testing.T) {
+	ctx := context.Background()
+	node1 := buildTestCertificate(t, pkix.Name{CommonName: "node1"}, nil)
+	other := buildTestCertificate(t, pkix.Name{CommonName: "node1"}, nil)
+
+	v := NewVerifier(ctx, true, nil, nil)
+	err := v.RecheckPeer(ctx, "node1", node1.cert, node1.pem)
+	require.NoError(t, err)
+
+	err = v.RecheckPeer(ctx, "node1", node1.cert, other.pem)
+	assert.Regexp(t, "PD021105.*PD021102", err)
+
+	err = v.RecheckPeer(ctx, "node1", &x509.Certificate{
+		NotBefore: time.Now().Add(-2 * time.Hour),
+		NotAfter:  time.Now().Add(-1 * time.Hour),
+	}, node1.pem)
+	assert.Regexp(t, "PD021105", err)
+
+	// With CA verification only the validity period is checked
+	err = NewVerifier(ctx, false, nil, nil).RecheckPeer(ctx, "node1", &x509.Certificate{
+		NotBefore: time.Now().Add(-1 * time.Hour),
+		NotAfter:  time.Now().Add(1 * time.Hour),
+	}, "")
+	assert.NoError(t, err)
+}
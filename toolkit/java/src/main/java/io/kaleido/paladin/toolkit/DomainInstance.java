@@ -46,6 +46,11 @@ public abstract class DomainInstance extends PluginInstance<Service.DomainMessag
     protected abstract CompletableFuture<ToDomain.ExecCallResponse> execCall(ToDomain.ExecCallRequest request);
     protected abstract CompletableFuture<ToDomain.BuildReceiptResponse> buildReceipt(ToDomain.BuildReceiptRequest request);
 
+    // Only called for methods registered in the DomainConfig, so domains without RPC methods need not override
+    protected CompletableFuture<ToDomain.HandleRPCResponse> handleRPC(ToDomain.HandleRPCRequest request) {
+        return CompletableFuture.failedFuture(new UnsupportedOperationException("unsupported RPC method: %s".formatted(request.getMethod())));
+    }
+
     protected DomainInstance(String grpcTarget, String instanceId) {
         super(grpcTarget, instanceId);
     }
@@ -82,6 +87,14 @@ public abstract class DomainInstance extends PluginInstance<Service.DomainMessag
         return requestReply(message).thenApply(Service.DomainMessage::getRecoverSignerRes);
     }
 
+    public CompletableFuture<FromDomain.QueryContractEventsResponse> queryContractEvents(FromDomain.QueryContractEventsRequest request) {
+        Service.DomainMessage message = Service.DomainMessage.newBuilder().
+                setHeader(newRequestHeader()).
+                setQueryContractEvents(request).
+                build();
+        return requestReply(message).thenApply(Service.DomainMessage::getQueryContractEventsRes);
+    }
+
     @Override
     final StreamObserver<Service.DomainMessage> connect(StreamObserver<Service.DomainMessage> observer) {
         return stub.connectDomain(observer);
@@ -118,6 +131,7 @@ public abstract class DomainInstance extends PluginInstance<Service.DomainMessag
                 case INIT_CALL -> initCall(request.getInitCall()).thenApply(response::setInitCallRes);
                 case EXEC_CALL -> execCall(request.getExecCall()).thenApply(response::setExecCallRes);
                 case BUILD_RECEIPT -> buildReceipt(request.getBuildReceipt()).thenApply(response::setBuildReceiptRes);
+                case HANDLE_RPC -> handleRPC(request.getHandleRpc()).thenApply(response::setHandleRpcRes);
                 default -> throw new IllegalArgumentException("unknown request: %s".formatted(request.getRequestToDomainCase()));
             };
             return resultApplied.thenApply((ra) -> {
@@ -0,0 +1,223 @@
+/*
+ * Copyright © 2024 Kaleido, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package grpctransport
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/kaleido-io/paladin/toolkit/pkg/log"
+	"github.com/kaleido-io/paladin/toolkit/pkg/prototk"
+	"github.com/kaleido-io/paladin/toolkit/pkg/tlsverifier"
+	"github.com/kaleido-io/paladin/transports/grpc/internal/msgs"
+)
+
+// The local certificate can be replaced at runtime when it is loaded from files, so
+// it is always accessed through an atomic pointer to one of these
+type localCert struct {
+	certificate       *tls.Certificate
+	additionalIssuers string
+	modTimes          []time.Time
+}
+
+// A connection to or from a peer, along with the certificate it was verified with
+type verifiedPeer struct {
+	nodeName string
+	cert     *x509.Certificate
+	close    func(err error)
+}
+
+func (t *grpcTransport) localCertFiles() []string {
+	var files []string
+	if t.conf.TLS.CertFile != "" && t.conf.TLS.KeyFile != "" {
+		files = append(files, t.conf.TLS.CertFile, t.conf.TLS.KeyFile)
+	}
+	if t.conf.AdditionalIssuersFile != nil && *t.conf.AdditionalIssuersFile != "" {
+		files = append(files, *t.conf.AdditionalIssuersFile)
+	}
+	return files
+}
+
+func localCertModTimes(files []string) ([]time.Time, error) {
+	modTimes := make([]time.Time, len(files))
+	for i, f := range files {
+		fi, err := os.Stat(f)
+		if err != nil {
+			return nil, err
+		}
+		modTimes[i] = fi.ModTime()
+	}
+	return modTimes, nil
+}
+
+// Loads the local certificate, and any additional issuers, from their files.
+// The modification times are taken first, so a change made while we are loading is picked up on the next check.
+func (t *grpcTransport) loadLocalCert(ctx context.Context, certificate *tls.Certificate) (_ *localCert, err error) {
+	lc := &localCert{certificate: certificate}
+	if lc.modTimes, err = localCertModTimes(t.localCertFiles()); err != nil {
+		return nil, err
+	}
+	if certificate != nil && t.conf.TLS.CertFile != "" && t.conf.TLS.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(t.conf.TLS.CertFile, t.conf.TLS.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		lc.certificate = &cert
+	}
+	if t.conf.AdditionalIssuersFile != nil && *t.conf.AdditionalIssuersFile != "" {
+		issuers, err := os.ReadFile(*t.conf.AdditionalIssuersFile)
+		if err == nil {
+			_, err = tlsverifier.GetCertListFromPEM(ctx, issuers)
+		}
+		if err != nil {
+			return nil, i18n.WrapError(ctx, err, msgs.MsgAdditionalIssuersInvalid, *t.conf.AdditionalIssuersFile)
+		}
+		lc.additionalIssuers = string(issuers)
+	}
+	return lc, nil
+}
+
+// Rather than letting Golang use a fixed certificate, we supply the latest one we have loaded for each handshake
+func (t *grpcTransport) useLocalCert(tlsConfig *tls.Config) {
+	if t.localCert.Load().certificate == nil {
+		return
+	}
+	tlsConfig.GetClientCertificate = func(cri *tls.CertificateRequestInfo) (*tls.Certificate, error) {
+		return t.localCert.Load().certificate, nil
+	}
+	tlsConfig.GetCertificate = func(chi *tls.ClientHelloInfo) (*tls.Certificate, error) {
+		return t.localCert.Load().certificate, nil
+	}
+}
+
+func (t *grpcTransport) reloadLocalCert(ctx context.Context) {
+	current := t.localCert.Load()
+	modTimes, err := localCertModTimes(t.localCertFiles())
+	if err != nil {
+		log.L(ctx).Errorf("Failed to check local certificate files for changes: %s", err)
+		return
+	}
+	if slices.EqualFunc(modTimes, current.modTimes, time.Time.Equal) {
+		return
+	}
+	// We keep using the current certificate if we fail to load the new one, which
+	// might happen if we catch the files part way through being updated
+	lc, err := t.loadLocalCert(ctx, current.certificate)
+	if err != nil {
+		log.L(ctx).Errorf("Failed to reload local certificate (will retry): %s", err)
+		return
+	}
+	t.localCert.Store(lc)
+	if lc.certificate != nil {
+		if leaf, err := x509.ParseCertificate(lc.certificate.Certificate[0]); err == nil {
+			log.L(ctx).Infof("Reloaded local certificate %s (serial=%s)", leaf.Subject, leaf.SerialNumber.Text(16))
+		}
+	}
+}
+
+func (t *grpcTransport) verifiedPeers() []*verifiedPeer {
+	t.connLock.L.Lock()
+	defer t.connLock.L.Unlock()
+	var peers []*verifiedPeer
+	for _, oc := range t.outboundConnections {
+		if ai := oc.verified.Load(); ai != nil && !oc.connecting {
+			peers = append(peers, &verifiedPeer{
+				nodeName: oc.nodeName,
+				cert:     ai.cert,
+				close:    func(err error) { t.closeOutbound(oc) },
+			})
+		}
+	}
+	for is := range t.inboundStreams {
+		if is.cert != nil {
+			peers = append(peers, &verifiedPeer{
+				nodeName: is.nodeName,
+				cert:     is.cert,
+				close:    is.close,
+			})
+		}
+	}
+	return peers
+}
+
+// Re-resolves the transport details of every connected peer, closing any connection
+// where the peer's certificate no longer verifies. A registry lookup failure does not
+// close the connection, as it might be transient.
+func (t *grpcTransport) recheckPeers(ctx context.Context) {
+	peers := t.verifiedPeers()
+	transportDetails := make(map[string]*PublishedTransportDetails)
+	for _, p := range peers {
+		td, resolved := transportDetails[p.nodeName]
+		if !resolved {
+			var err error
+			if td, err = t.getTransportDetails(ctx, p.nodeName); err != nil {
+				log.L(ctx).Warnf("Unable to recheck certificate of peer %s: %s", p.nodeName, err)
+			}
+			transportDetails[p.nodeName] = td
+		}
+		if td == nil {
+			continue
+		}
+		if err := t.peerVerifier.RecheckPeer(ctx, p.nodeName, p.cert, td.Issuers); err != nil {
+			log.L(ctx).Errorf("Closing connection with peer %s: %s", p.nodeName, err)
+			p.close(err)
+		}
+	}
+}
+
+// Called by Paladin when the registry is updated. The recheck is performed by the certificate
+// monitor, so this does not block the registry update, and a burst of updates results in one recheck.
+func (t *grpcTransport) TransportDetailsChanged(ctx context.Context, req *prototk.TransportDetailsChangedRequest) (*prototk.TransportDetailsChangedResponse, error) {
+	select {
+	case t.recheckPeersNow <- struct{}{}:
+	default:
+	}
+	return &prototk.TransportDetailsChangedResponse{}, nil
+}
+
+func (t *grpcTransport) certMonitor(certReloadInterval, peerRecheckInterval time.Duration) {
+	defer close(t.certMonitorDone)
+
+	var reloadTicker, recheckTicker <-chan time.Time
+	if certReloadInterval > 0 && len(t.localCertFiles()) > 0 {
+		ticker := time.NewTicker(certReloadInterval)
+		defer ticker.Stop()
+		reloadTicker = ticker.C
+	}
+	if peerRecheckInterval > 0 {
+		ticker := time.NewTicker(peerRecheckInterval)
+		defer ticker.Stop()
+		recheckTicker = ticker.C
+	}
+	for {
+		select {
+		case <-reloadTicker:
+			t.reloadLocalCert(t.bgCtx)
+		case <-t.recheckPeersNow:
+			t.recheckPeers(t.bgCtx)
+		case <-recheckTicker:
+			t.recheckPeers(t.bgCtx)
+		case <-t.bgCtx.Done():
+			log.L(t.bgCtx).Debugf("Certificate monitor for plugin %s stopped", t.name)
+			return
+		}
+	}
+}
@@ -0,0 +1,316 @@
+/*
+ * Copyright © 2024 Kaleido, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package grpctransport
+
+import (
+	"context"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/kaleido-io/paladin/config/pkg/confutil"
+	"github.com/kaleido-io/paladin/config/pkg/pldconf"
+	"github.com/kaleido-io/paladin/toolkit/pkg/prototk"
+	"github.com/kaleido-io/paladin/toolkit/pkg/tlsverifier"
+	"github.com/kaleido-io/paladin/transports/grpc/pkg/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Writes the files with a modification time in the future, so that each write is seen as a change
+func writeCertFiles(t *testing.T, dir string, cert, key string, modTime time.Time) (string, string) {
+	certFile, keyFile := path.Join(dir, "cert.pem"), path.Join(dir, "key.pem")
+	for f, content := range map[string]string{certFile: cert, keyFile: key} {
+		err := os.WriteFile(f, []byte(content), 0600)
+		require.NoError(t, err)
+		err = os.Chtimes(f, modTime, modTime)
+		require.NoError(t, err)
+	}
+	return certFile, keyFile
+}
+
+func getOutboundConn(plugin *grpcTransport, node string) *outboundConn {
+	plugin.connLock.L.Lock()
+	defer plugin.connLock.L.Unlock()
+	return plugin.outboundConnections[node]
+}
+
+func getLocalIssuers(t *testing.T, plugin *grpcTransport) string {
+	details, err := plugin.GetLocalDetails(context.Background(), &prototk.GetLocalDetailsRequest{})
+	require.NoError(t, err)
+	var pubDetails PublishedTransportDetails
+	err = json.Unmarshal([]byte(details.TransportDetails), &pubDetails)
+	require.NoError(t, err)
+	return pubDetails.Issuers
+}
+
+func TestReloadLocalCert(t *testing.T) {
+
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	cert1, key1 := buildTestCertificate(t, pkix.Name{CommonName: "node1"}, nil, nil)
+	certFile, keyFile := writeCertFiles(t, dir, cert1, key1, time.Now())
+	plugin, _, _, done := newTestGRPCTransport(t, "", "", &Config{
+		TLS:                pldconf.TLSConfig{CertFile: certFile, KeyFile: keyFile},
+		CertReloadInterval: confutil.P("0"), // we drive the reload
+	})
+	defer done()
+	assert.Equal(t, cert1, getLocalIssuers(t, plugin))
+
+	// No change
+	plugin.reloadLocalCert(ctx)
+	assert.Equal(t, cert1, getLocalIssuers(t, plugin))
+
+	// Half way through an update, where the key does not match the cert, we keep the old one
+	cert2, key2 := buildTestCertificate(t, pkix.Name{CommonName: "node1"}, nil, nil)
+	writeCertFiles(t, dir, cert2, key1, time.Now().Add(1*time.Minute))
+	plugin.reloadLocalCert(ctx)
+	assert.Equal(t, cert1, getLocalIssuers(t, plugin))
+
+	// Then we pick up the new one
+	writeCertFiles(t, dir, cert2, key2, time.Now().Add(2*time.Minute))
+	plugin.reloadLocalCert(ctx)
+	assert.Equal(t, cert2, getLocalIssuers(t, plugin))
+
+	// A missing file is logged, and the current certificate stays
+	err := os.Remove(keyFile)
+	require.NoError(t, err)
+	plugin.reloadLocalCert(ctx)
+	assert.Equal(t, cert2, getLocalIssuers(t, plugin))
+
+}
+
+func TestCertMonitorReloadsLocalCert(t *testing.T) {
+
+	dir := t.TempDir()
+
+	cert1, key1 := buildTestCertificate(t, pkix.Name{CommonName: "node1"}, nil, nil)
+	certFile, keyFile := writeCertFiles(t, dir, cert1, key1, time.Now())
+	plugin, _, _, done := newTestGRPCTransport(t, "", "", &Config{
+		TLS:                 pldconf.TLSConfig{CertFile: certFile, KeyFile: keyFile},
+		CertReloadInterval:  confutil.P("1ms"),
+		PeerRecheckInterval: confutil.P("1ms"),
+	})
+	defer done()
+
+	cert2, key2 := buildTestCertificate(t, pkix.Name{CommonName: "node1"}, nil, nil)
+	writeCertFiles(t, dir, cert2, key2, time.Now().Add(1*time.Minute))
+	for getLocalIssuers(t, plugin) != cert2 {
+		time.Sleep(1 * time.Millisecond)
+	}
+
+}
+
+func TestAdditionalIssuers(t *testing.T) {
+
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	cert1, key1 := buildTestCertificate(t, pkix.Name{CommonName: "node1"}, nil, nil)
+	cert2, key2 := buildTestCertificate(t, pkix.Name{CommonName: "node1"}, nil, nil)
+	issuersFile := path.Join(dir, "issuers.pem")
+	err := os.WriteFile(issuersFile, []byte(cert2), 0600)
+	require.NoError(t, err)
+
+	plugin, _, _, done := newTestGRPCTransport(t, cert1, key1, &Config{
+		AdditionalIssuersFile: &issuersFile,
+		CertReloadInterval:    confutil.P("0"), // we drive the reload
+	})
+	defer done()
+	assert.Equal(t, cert1+cert2, getLocalIssuers(t, plugin))
+
+	// Move to the new cert, and stop publishing the additional issuer
+	certFile, keyFile := writeCertFiles(t, dir, cert2, key2, time.Now().Add(1*time.Minute))
+	plugin.conf.TLS = pldconf.TLSConfig{CertFile: certFile, KeyFile: keyFile}
+	plugin.conf.AdditionalIssuersFile = nil
+	plugin.reloadLocalCert(ctx)
+	assert.Equal(t, cert2, getLocalIssuers(t, plugin))
+
+}
+
+func TestAdditionalIssuersInvalid(t *testing.T) {
+
+	issuersFile := path.Join(t.TempDir(), "issuers.pem")
+	err := os.WriteFile(issuersFile, []byte("not a PEM"), 0600)
+	require.NoError(t, err)
+
+	callbacks := &testCallbacks{}
+	transport := NewGRPCTransport(callbacks).(*grpcTransport)
+	_, err = transport.ConfigureTransport(transport.bgCtx, &prototk.ConfigureTransportRequest{
+		Name:       "grpc",
+		ConfigJson: `{"address": "127.0.0.1", "port": 0, "additionalIssuersFile": "` + issuersFile + `"}`,
+	})
+	assert.Regexp(t, "PD030015.*PD021104", err)
+
+}
+
+func TestAdditionalIssuersMissing(t *testing.T) {
+
+	callbacks := &testCallbacks{}
+	transport := NewGRPCTransport(callbacks).(*grpcTransport)
+	_, err := transport.ConfigureTransport(transport.bgCtx, &prototk.ConfigureTransportRequest{
+		Name:       "grpc",
+		ConfigJson: `{"address": "127.0.0.1", "port": 0, "additionalIssuersFile": "` + t.TempDir() + `/missing.pem"}`,
+	})
+	assert.Regexp(t, "missing.pem", err)
+
+}
+
+func TestRotatePeerCertificate(t *testing.T) {
+
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	received := make(chan *prototk.Message, 1)
+
+	node1Cert, node1Key := buildTestCertificate(t, pkix.Name{CommonName: "node1"}, nil, nil)
+	plugin1, transportDetails1, callbacks1, done1 := newTestGRPCTransport(t, node1Cert, node1Key, &Config{})
+	defer done1()
+
+	node2CertOld, node2KeyOld := buildTestCertificate(t, pkix.Name{CommonName: "node2"}, nil, nil)
+	certFile, keyFile := writeCertFiles(t, dir, node2CertOld, node2KeyOld, time.Now())
+	plugin2, transportDetails2, callbacks2, done2 := newTestGRPCTransport(t, "", "", &Config{
+		TLS: pldconf.TLSConfig{CertFile: certFile, KeyFile: keyFile},
+	})
+	defer done2()
+	transportDetails2.Issuers = node2CertOld
+	callbacks2.receiveMessage = func(ctx context.Context, rmr *prototk.ReceiveMessageRequest) (*prototk.ReceiveMessageResponse, error) {
+		received <- rmr.Message
+		return &prototk.ReceiveMessageResponse{}, nil
+	}
+
+	ptds := map[string]*PublishedTransportDetails{"node1": transportDetails1, "node2": transportDetails2}
+	mockRegistry(callbacks1, ptds)
+	mockRegistry(callbacks2, ptds)
+
+	send := func() error {
+		_, err := plugin1.SendMessage(ctx, &prototk.SendMessageRequest{
+			Message: &prototk.Message{ReplyTo: "node1", Component: "to.you", Node: "node2"},
+		})
+		return err
+	}
+	require.NoError(t, send())
+	<-received
+
+	// Publish the new certificate alongside the old one, then switch node2 over to it
+	node2CertNew, node2KeyNew := buildTestCertificate(t, pkix.Name{CommonName: "node2"}, nil, nil)
+	transportDetails2.Issuers = node2CertOld + node2CertNew
+	mockRegistry(callbacks1, ptds)
+	writeCertFiles(t, dir, node2CertNew, node2KeyNew, time.Now().Add(1*time.Minute))
+	plugin2.reloadLocalCert(ctx)
+
+	// The existing connection is still valid
+	plugin1.recheckPeers(ctx)
+	require.NotNil(t, getOutboundConn(plugin1, "node2"))
+	require.NoError(t, send())
+	<-received
+
+	// Retire the old certificate, and the connection using it is closed as soon as
+	// we are told about the registry update (well before the fallback recheck timer)
+	transportDetails2.Issuers = node2CertNew
+	mockRegistry(callbacks1, ptds)
+	_, err := plugin1.TransportDetailsChanged(ctx, &prototk.TransportDetailsChangedRequest{})
+	require.NoError(t, err)
+	for getOutboundConn(plugin1, "node2") != nil {
+		time.Sleep(1 * time.Millisecond)
+	}
+
+	// A new connection is verified against the new certificate
+	require.NoError(t, send())
+	<-received
+	newConn := getOutboundConn(plugin1, "node2")
+	require.NotNil(t, newConn)
+	newCerts, err := tlsverifier.GetCertListFromPEM(ctx, []byte(node2CertNew))
+	require.NoError(t, err)
+	assert.Equal(t, newCerts[0].Raw, newConn.verified.Load().cert.Raw)
+
+}
+
+func TestRecheckClosesInboundStream(t *testing.T) {
+
+	ctx := context.Background()
+
+	received := make(chan *prototk.Message, 1)
+	plugin1, plugin2, done := newSuccessfulVerifiedConnection(t, func(_, callbacks2 *testCallbacks) {
+		callbacks2.receiveMessage = func(ctx context.Context, rmr *prototk.ReceiveMessageRequest) (*prototk.ReceiveMessageResponse, error) {
+			received <- rmr.Message
+			return &prototk.ReceiveMessageResponse{}, nil
+		}
+	})
+	defer done()
+
+	_, err := plugin1.SendMessage(ctx, &prototk.SendMessageRequest{
+		Message: &prototk.Message{ReplyTo: "node1", Component: "to.you", Node: "node2"},
+	})
+	require.NoError(t, err)
+	<-received
+
+	// Node1 is no longer registered with the certificate it connected with
+	anotherCert, _ := buildTestCertificate(t, pkix.Name{CommonName: "node1"}, nil, nil)
+	callbacks2 := plugin2.callbacks.(*testCallbacks)
+	ptds := map[string]*PublishedTransportDetails{"node1": {Issuers: anotherCert}}
+	mockRegistry(callbacks2, ptds)
+
+	// A new stream on the existing connection is rejected
+	oc := getOutboundConn(plugin1, "node2")
+	stream, err := proto.NewPaladinGRPCTransportClient(oc.conn).ConnectSendStream(ctx)
+	require.NoError(t, err)
+	_, err = stream.CloseAndRecv()
+	assert.Regexp(t, "PD021105", err)
+
+	// The existing stream is closed
+	plugin2.recheckPeers(ctx)
+	for {
+		plugin2.connLock.L.Lock()
+		remaining := len(plugin2.inboundStreams)
+		plugin2.connLock.L.Unlock()
+		if remaining == 0 {
+			break
+		}
+		time.Sleep(1 * time.Millisecond)
+	}
+
+}
+
+func TestRecheckPeersLookupFail(t *testing.T) {
+
+	ctx := context.Background()
+
+	plugin, _, callbacks, done := newTestGRPCTransport(t, "", "", &Config{})
+	defer done()
+	mockRegistry(callbacks, map[string]*PublishedTransportDetails{})
+
+	oc := &outboundConn{nodeName: "node2"}
+	oc.verified.Store(&tlsVerifierAuthInfo{cert: &x509.Certificate{}})
+	plugin.outboundConnections["node2"] = oc
+	is := &inboundStream{nodeName: "node2", cert: &x509.Certificate{}, closed: make(chan struct{})}
+	plugin.inboundStreams[is] = true
+
+	// Lookup fails once, and we do not close the connections
+	plugin.recheckPeers(ctx)
+	assert.Equal(t, oc, plugin.outboundConnections["node2"])
+	select {
+	case <-is.closed:
+		assert.Fail(t, "inbound stream closed")
+	default:
+	}
+
+}
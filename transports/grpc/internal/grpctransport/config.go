@@ -18,6 +18,7 @@ package grpctransport
 import (
 	"github.com/kaleido-io/paladin/config/pkg/confutil"
 	"github.com/kaleido-io/paladin/config/pkg/pldconf"
+	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
 )
 
 type Config struct {
@@ -36,11 +37,23 @@ type Config struct {
 	// By default directCertVerification will expect the CN of the subject to be the exact registered node name.
 	// Optionally certSubjectMatcher can supply a regexp containing a SINGLE CAPTURE GROUP that can be used to extract the name from the subject string
 	CertSubjectMatcher *string `json:"certSubjectMatcher,omitempty"`
+	// How often to check tls.certFile and tls.keyFile (and additionalIssuersFile) for changes, so a new local certificate
+	// can be rolled out without a restart. Existing connections are unaffected, and new connections use the new certificate.
+	CertReloadInterval *string `json:"certReloadInterval,omitempty"`
+	// Connected peers are rechecked whenever the registry is updated, by re-resolving their registered transport details
+	// and re-verifying the certificate each connection was established with. Connections with certificates that no longer
+	// verify are closed. This is how often the same recheck is performed regardless, as a fallback.
+	PeerRecheckInterval *string `json:"peerRecheckInterval,omitempty"`
+	// Optional file containing PEM certificates to publish in the local transport details in addition to the
+	// local certificate, such as the next certificate for this node during a rotation window
+	AdditionalIssuersFile *string `json:"additionalIssuersFile,omitempty"`
 }
 
 var ConfigDefaults = &Config{
 	Address:                confutil.P("0.0.0.0"), // public connectivity
 	DirectCertVerification: confutil.P(true),      // with self-signed certificates
+	CertReloadInterval:     confutil.P("30s"),
+	PeerRecheckInterval:    confutil.P("1m"),
 }
 
 // This is the JSON structure that any node in the network must share to be connectable
@@ -52,5 +65,26 @@ type PublishedTransportDetails struct {
 	// - used in direct certificate validation mode only
 	// - can be the certificate itself for self-signed
 	// - must be the direct parent (not the root of a chain - for that use normal CA verification)
+	// - can contain multiple certificates, any of which are accepted, such as the current and next certificate during a rotation
 	Issuers string `json:"issuers,omitempty"`
 }
+
+// This is the JSON structure returned as the peer information for a node, describing the
+// outbound connection used to send to it, and any inbound streams it has connected to us
+type PeerInfo struct {
+	Outbound *OutboundConnInfo    `json:"outbound,omitempty"`
+	Inbound  []*InboundStreamInfo `json:"inbound,omitempty"`
+}
+
+type OutboundConnInfo struct {
+	Endpoint  string             `json:"endpoint,omitempty"`
+	State     string             `json:"state"` // "connecting" or "connected"
+	Connected *tktypes.Timestamp `json:"connected,omitempty"`
+	SentMsgs  uint64             `json:"sentMsgs"`
+}
+
+type InboundStreamInfo struct {
+	RemoteAddress string            `json:"remoteAddress"`
+	Established   tktypes.Timestamp `json:"established"`
+	ReceivedMsgs  uint64            `json:"receivedMsgs"`
+}
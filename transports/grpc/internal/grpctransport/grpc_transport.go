@@ -18,13 +18,16 @@ package grpctransport
 import (
 	"context"
 	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
 	"encoding/pem"
 	"fmt"
 	"net"
 	"regexp"
+	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 
 	"github.com/hyperledger/firefly-common/pkg/i18n"
 	"github.com/kaleido-io/paladin/config/pkg/confutil"
@@ -33,6 +36,7 @@ import (
 	"github.com/kaleido-io/paladin/toolkit/pkg/prototk"
 	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
 	"github.com/kaleido-io/paladin/toolkit/pkg/tlsconf"
+	"github.com/kaleido-io/paladin/toolkit/pkg/tlsverifier"
 	"github.com/kaleido-io/paladin/transports/grpc/internal/msgs"
 	"github.com/kaleido-io/paladin/transports/grpc/pkg/proto"
 	"google.golang.org/grpc"
@@ -48,6 +52,7 @@ type grpcTransport struct {
 	proto.UnimplementedPaladinGRPCTransportServer
 
 	bgCtx     context.Context
+	bgCancel  context.CancelFunc
 	callbacks plugintk.TransportCallbacks
 
 	name             string
@@ -56,11 +61,14 @@ type grpcTransport struct {
 	serverDone       chan struct{}
 	peerVerifier     *tlsVerifier
 	externalHostname string
-	localCertificate *tls.Certificate
+	localCert        atomic.Pointer[localCert]
+	certMonitorDone  chan struct{}
+	recheckPeersNow  chan struct{}
 
 	conf                Config
 	connLock            sync.Cond
 	outboundConnections map[string]*outboundConn
+	inboundStreams      map[*inboundStream]bool
 }
 
 type outboundConn struct {
@@ -69,7 +77,23 @@ type outboundConn struct {
 	sendLock   sync.Mutex
 	waiting    int
 	connError  error
+	conn       *grpc.ClientConn
 	stream     grpc.ClientStreamingClient[proto.Message, proto.Empty]
+	verified   atomic.Pointer[tlsVerifierAuthInfo]
+	endpoint   string
+	connected  tktypes.Timestamp
+	sentMsgs   atomic.Uint64
+}
+
+type inboundStream struct {
+	nodeName     string
+	remoteAddr   string
+	cert         *x509.Certificate
+	established  tktypes.Timestamp
+	receivedMsgs atomic.Uint64
+	closeOnce    sync.Once
+	closed       chan struct{}
+	closeErr     error
 }
 
 func NewPlugin(ctx context.Context) plugintk.PluginBase {
@@ -77,11 +101,15 @@ func NewPlugin(ctx context.Context) plugintk.PluginBase {
 }
 
 func NewGRPCTransport(callbacks plugintk.TransportCallbacks) plugintk.TransportAPI {
+	bgCtx, bgCancel := context.WithCancel(context.Background())
 	return &grpcTransport{
-		bgCtx:               context.Background(),
+		bgCtx:               bgCtx,
+		bgCancel:            bgCancel,
 		callbacks:           callbacks,
 		connLock:            *sync.NewCond(new(sync.Mutex)),
 		outboundConnections: make(map[string]*outboundConn),
+		inboundStreams:      make(map[*inboundStream]bool),
+		recheckPeersNow:     make(chan struct{}, 1),
 	}
 }
 
@@ -121,7 +149,12 @@ func (t *grpcTransport) ConfigureTransport(ctx context.Context, req *prototk.Con
 		return nil, err
 	}
 	baseTLSConfig := tlsDetail.TLSConfig
-	t.localCertificate = tlsDetail.Certificate
+	lc, err := t.loadLocalCert(ctx, tlsDetail.Certificate)
+	if err != nil {
+		return nil, err
+	}
+	t.localCert.Store(lc)
+	t.useLocalCert(baseTLSConfig)
 
 	directCertVerification := confutil.Bool(t.conf.DirectCertVerification, *ConfigDefaults.DirectCertVerification)
 	baseTLSConfig.ClientAuth = tls.RequireAndVerifyClientCert
@@ -142,11 +175,8 @@ func (t *grpcTransport) ConfigureTransport(ctx context.Context, req *prototk.Con
 	}
 
 	t.peerVerifier = &tlsVerifier{
-		tlsVerifierStatic: tlsVerifierStatic{
-			t:                      t,
-			directCertVerification: directCertVerification,
-			subjectMatchRegex:      subjectMatchRegex,
-		},
+		Verifier:      tlsverifier.NewVerifier(t.bgCtx, directCertVerification, subjectMatchRegex, t.getIssuers),
+		t:             t,
 		baseTLSConfig: baseTLSConfig,
 	}
 	t.grpcServer = grpc.NewServer(grpc.Creds(t.peerVerifier))
@@ -158,6 +188,15 @@ func (t *grpcTransport) ConfigureTransport(ctx context.Context, req *prototk.Con
 		go t.serve()
 	}
 
+	// Kick off the monitor that reloads our certificate, and rechecks the certificates of our peers
+	if t.certMonitorDone == nil {
+		t.certMonitorDone = make(chan struct{})
+		go t.certMonitor(
+			confutil.DurationMin(t.conf.CertReloadInterval, 0, *ConfigDefaults.CertReloadInterval),
+			confutil.DurationMin(t.conf.PeerRecheckInterval, 0, *ConfigDefaults.PeerRecheckInterval),
+		)
+	}
+
 	return &prototk.ConfigureTransportResponse{}, nil
 }
 
@@ -185,14 +224,49 @@ func (t *grpcTransport) ConnectSendStream(stream grpc.ClientStreamingServer[prot
 		return i18n.NewError(ctx, msgs.MsgAuthContextNotAvailable)
 	}
 
-	// Go into the long-lived receive loop until the client disconnects
+	// The TLS connection might have been established some time ago, and new streams can be created on it
+	// without a new handshake. So we check the certificate against the current registered details
+	// before accepting this stream.
 	ctx = log.WithLogField(log.WithLogField(ctx, "remote", ai.remoteAddr), "node", ai.verifiedNodeName)
+	transportDetails, err := t.getTransportDetails(ctx, ai.verifiedNodeName)
+	if err == nil {
+		err = t.peerVerifier.RecheckPeer(ctx, ai.verifiedNodeName, ai.cert, transportDetails.Issuers)
+	}
+	if err != nil {
+		log.L(ctx).Errorf("Rejecting message stream from %s: %s", ai.verifiedNodeName, err)
+		return err
+	}
+
+	// Go into the long-lived receive loop until the client disconnects, or we close the stream
+	// because the certificate of the peer is no longer valid
 	log.L(ctx).Infof("GRPC message stream established from node %s (authType=%s)", ai.verifiedNodeName, peer.AuthInfo.AuthType())
+	is := t.addInboundStream(ai)
+	defer t.removeInboundStream(is)
+	received := make(chan *proto.Message)
+	recvErr := make(chan error, 1)
+	go func() {
+		for {
+			msg, err := stream.Recv()
+			if err != nil {
+				recvErr <- err
+				return
+			}
+			select {
+			case received <- msg:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
 	for {
-		msg, err := stream.Recv()
-		if err != nil {
+		var msg *proto.Message
+		select {
+		case msg = <-received:
+		case err := <-recvErr:
 			log.L(ctx).Infof("GRPC message stream from %s closing (err=%v)", ai.verifiedNodeName, err)
 			return err
+		case <-is.closed:
+			return is.closeErr
 		}
 
 		log.L(ctx).Infof("GRPC received message id=%s cid=%v component=%s messageType=%s replyTo=%s from peer %s",
@@ -216,15 +290,44 @@ func (t *grpcTransport) ConnectSendStream(stream grpc.ClientStreamingServer[prot
 				MessageType:   msg.MessageType,
 				Payload:       msg.Payload,
 			},
+			FromNode: ai.verifiedNodeName,
 		})
 		if err != nil {
 			log.L(ctx).Errorf("Receive failed (err=%s): %s", err, tktypes.ProtoToJSON(msg))
 			return err
 		}
+		is.receivedMsgs.Add(1)
 
 	}
 }
 
+func (t *grpcTransport) addInboundStream(ai *tlsVerifierAuthInfo) *inboundStream {
+	t.connLock.L.Lock()
+	defer t.connLock.L.Unlock()
+	is := &inboundStream{
+		nodeName:    ai.verifiedNodeName,
+		remoteAddr:  ai.remoteAddr,
+		cert:        ai.cert,
+		established: tktypes.TimestampNow(),
+		closed:      make(chan struct{}),
+	}
+	t.inboundStreams[is] = true
+	return is
+}
+
+func (is *inboundStream) close(err error) {
+	is.closeOnce.Do(func() {
+		is.closeErr = err
+		close(is.closed)
+	})
+}
+
+func (t *grpcTransport) removeInboundStream(is *inboundStream) {
+	t.connLock.L.Lock()
+	defer t.connLock.L.Unlock()
+	delete(t.inboundStreams, is)
+}
+
 func (t *grpcTransport) getTransportDetails(ctx context.Context, node string) (transportDetails *PublishedTransportDetails, err error) {
 	gtdr, err := t.callbacks.GetTransportDetails(ctx, &prototk.GetTransportDetailsRequest{
 		Node: node,
@@ -242,6 +345,14 @@ func (t *grpcTransport) getTransportDetails(ctx context.Context, node string) (t
 	return transportDetails, nil
 }
 
+func (t *grpcTransport) getIssuers(ctx context.Context, node string) (string, error) {
+	transportDetails, err := t.getTransportDetails(ctx, node)
+	if err != nil {
+		return "", err
+	}
+	return transportDetails.Issuers, nil
+}
+
 func (t *grpcTransport) waitExistingOrNewConn(nodeName string) (bool, *outboundConn, error) {
 	t.connLock.L.Lock()
 	defer t.connLock.L.Unlock()
@@ -260,6 +371,19 @@ func (t *grpcTransport) waitExistingOrNewConn(nodeName string) (bool, *outboundC
 	return true, newConn, nil
 }
 
+// Closes the connection, which is safe to do concurrently with a send, and removes it
+// (if it has not already been replaced) so the next send reconnects
+func (t *grpcTransport) closeOutbound(oc *outboundConn) {
+	if oc.conn != nil {
+		_ = oc.conn.Close()
+	}
+	t.connLock.L.Lock()
+	defer t.connLock.L.Unlock()
+	if t.outboundConnections[oc.nodeName] == oc {
+		delete(t.outboundConnections, oc.nodeName)
+	}
+}
+
 func (t *grpcTransport) send(ctx context.Context, oc *outboundConn, message *proto.Message) (err error) {
 	oc.sendLock.Lock()
 	defer func() {
@@ -269,15 +393,16 @@ func (t *grpcTransport) send(ctx context.Context, oc *outboundConn, message *pro
 			_ = oc.stream.CloseSend()
 			// Drop the send lock before taking conn lock to remove from the connections
 			oc.sendLock.Unlock()
-			t.connLock.L.Lock()
-			defer t.connLock.L.Unlock()
-			delete(t.outboundConnections, oc.nodeName)
+			t.closeOutbound(oc)
 		} else {
 			// Just drop the lock and return
 			oc.sendLock.Unlock()
 		}
 	}()
 	err = oc.stream.Send(message)
+	if err == nil {
+		oc.sentMsgs.Add(1)
+	}
 	return
 }
 
@@ -293,6 +418,7 @@ func (t *grpcTransport) getConnection(ctx context.Context, nodeName string) (*ou
 	defer func() {
 		t.connLock.L.Lock()
 		oc.connecting = false
+		oc.connected = tktypes.TimestampNow()
 		if err != nil {
 			// copy our error to anyone queuing - everybody fails
 			oc.connError = err
@@ -310,15 +436,22 @@ func (t *grpcTransport) getConnection(ctx context.Context, nodeName string) (*ou
 	}
 
 	// Ok - try connecting
+	t.connLock.L.Lock()
+	oc.endpoint = transportDetails.Endpoint
+	t.connLock.L.Unlock()
 	log.L(ctx).Infof("GRPC connecting to new peer %s (endpoint=%s)", nodeName, transportDetails.Endpoint)
 	individualNodeVerifier := t.peerVerifier.Clone().(*tlsVerifier)
 	individualNodeVerifier.expectedNode = nodeName
-	conn, err := grpc.NewClient(transportDetails.Endpoint,
+	individualNodeVerifier.lastVerified = &oc.verified
+	oc.conn, err = grpc.NewClient(transportDetails.Endpoint,
 		grpc.WithTransportCredentials(individualNodeVerifier),
 	)
 	if err == nil {
-		client := proto.NewPaladinGRPCTransportClient(conn)
+		client := proto.NewPaladinGRPCTransportClient(oc.conn)
 		oc.stream, err = client.ConnectSendStream(ctx)
+		if err != nil {
+			_ = oc.conn.Close()
+		}
 	}
 	return oc, err
 }
@@ -350,14 +483,16 @@ func (t *grpcTransport) SendMessage(ctx context.Context, req *prototk.SendMessag
 
 func (t *grpcTransport) GetLocalDetails(ctx context.Context, req *prototk.GetLocalDetailsRequest) (*prototk.GetLocalDetailsResponse, error) {
 
-	certList := t.localCertificate.Certificate
+	lc := t.localCert.Load()
 	issuersText := new(strings.Builder)
-	for _, cert := range certList {
+	for _, cert := range lc.certificate.Certificate {
 		_ = pem.Encode(issuersText, &pem.Block{
 			Type:  "CERTIFICATE",
 			Bytes: cert,
 		})
 	}
+	// During a rotation we publish the next certificate (or its issuer) alongside the current one
+	issuersText.WriteString(lc.additionalIssuers)
 
 	localDetails := &PublishedTransportDetails{
 		Endpoint: fmt.Sprintf("dns:///%s:%d", t.externalHostname, *t.conf.Port),
@@ -370,3 +505,37 @@ func (t *grpcTransport) GetLocalDetails(ctx context.Context, req *prototk.GetLoc
 	}, nil
 
 }
+
+func (t *grpcTransport) GetPeerInfo(ctx context.Context, req *prototk.GetPeerInfoRequest) (*prototk.GetPeerInfoResponse, error) {
+	t.connLock.L.Lock()
+	defer t.connLock.L.Unlock()
+
+	var peerInfo PeerInfo
+	if oc := t.outboundConnections[req.Node]; oc != nil {
+		peerInfo.Outbound = &OutboundConnInfo{
+			Endpoint: oc.endpoint,
+			State:    "connecting",
+			SentMsgs: oc.sentMsgs.Load(),
+		}
+		if !oc.connecting {
+			peerInfo.Outbound.State = "connected"
+			peerInfo.Outbound.Connected = &oc.connected
+		}
+	}
+	for is := range t.inboundStreams {
+		if is.nodeName == req.Node {
+			peerInfo.Inbound = append(peerInfo.Inbound, &InboundStreamInfo{
+				RemoteAddress: is.remoteAddr,
+				Established:   is.established,
+				ReceivedMsgs:  is.receivedMsgs.Load(),
+			})
+		}
+	}
+	if peerInfo.Outbound == nil && peerInfo.Inbound == nil {
+		return &prototk.GetPeerInfoResponse{}, nil
+	}
+	sort.Slice(peerInfo.Inbound, func(i, j int) bool { return peerInfo.Inbound[i].Established < peerInfo.Inbound[j].Established })
+	return &prototk.GetPeerInfoResponse{
+		PeerInfoJson: tktypes.JSONString(&peerInfo).String(),
+	}, nil
+}
@@ -17,6 +17,7 @@ package grpctransport
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net"
 	"testing"
@@ -168,6 +169,87 @@ func TestBadReplyTo(t *testing.T) {
 
 }
 
+func TestGetPeerInfo(t *testing.T) {
+
+	ctx := context.Background()
+
+	received := make(chan *prototk.Message, 1)
+	plugin1, plugin2, done := newSuccessfulVerifiedConnection(t, func(_, callbacks2 *testCallbacks) {
+		callbacks2.receiveMessage = func(ctx context.Context, rmr *prototk.ReceiveMessageRequest) (*prototk.ReceiveMessageResponse, error) {
+			assert.Equal(t, "node1", rmr.FromNode)
+			received <- rmr.Message
+			return &prototk.ReceiveMessageResponse{}, nil
+		}
+	})
+	defer done()
+
+	// Nothing to report before we connect
+	res, err := plugin1.GetPeerInfo(ctx, &prototk.GetPeerInfoRequest{Node: "node2"})
+	require.NoError(t, err)
+	assert.Empty(t, res.PeerInfoJson)
+
+	_, err = plugin1.SendMessage(ctx, &prototk.SendMessageRequest{
+		Message: &prototk.Message{
+			ReplyTo:   "node1",
+			Component: "to.you",
+			Node:      "node2",
+		},
+	})
+	require.NoError(t, err)
+	<-received
+
+	res, err = plugin1.GetPeerInfo(ctx, &prototk.GetPeerInfoRequest{Node: "node2"})
+	require.NoError(t, err)
+	var outboundInfo PeerInfo
+	err = json.Unmarshal([]byte(res.PeerInfoJson), &outboundInfo)
+	require.NoError(t, err)
+	require.NotNil(t, outboundInfo.Outbound)
+	assert.Equal(t, "dns:///"+plugin2.listener.Addr().String(), outboundInfo.Outbound.Endpoint)
+	assert.Equal(t, "connected", outboundInfo.Outbound.State)
+	assert.NotNil(t, outboundInfo.Outbound.Connected)
+	assert.Equal(t, uint64(1), outboundInfo.Outbound.SentMsgs)
+	assert.Empty(t, outboundInfo.Inbound)
+
+	// The receive count is updated after delivery
+	var inboundInfo PeerInfo
+	for inboundInfo.Inbound == nil || inboundInfo.Inbound[0].ReceivedMsgs == 0 {
+		res, err = plugin2.GetPeerInfo(ctx, &prototk.GetPeerInfoRequest{Node: "node1"})
+		require.NoError(t, err)
+		err = json.Unmarshal([]byte(res.PeerInfoJson), &inboundInfo)
+		require.NoError(t, err)
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Nil(t, inboundInfo.Outbound)
+	require.Len(t, inboundInfo.Inbound, 1)
+	assert.NotEmpty(t, inboundInfo.Inbound[0].RemoteAddress)
+	assert.NotZero(t, inboundInfo.Inbound[0].Established)
+	assert.Equal(t, uint64(1), inboundInfo.Inbound[0].ReceivedMsgs)
+
+}
+
+func TestGetPeerInfoConnecting(t *testing.T) {
+
+	ctx := context.Background()
+
+	plugin, _, _, done := newTestGRPCTransport(t, "", "", &Config{})
+	defer done()
+
+	plugin.outboundConnections["node2"] = &outboundConn{nodeName: "node2", connecting: true}
+	plugin.inboundStreams[&inboundStream{nodeName: "node2", established: 2}] = true
+	plugin.inboundStreams[&inboundStream{nodeName: "node2", established: 1}] = true
+
+	res, err := plugin.GetPeerInfo(ctx, &prototk.GetPeerInfoRequest{Node: "node2"})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"outbound": {"state": "connecting", "sentMsgs": 0},
+		"inbound": [
+			{"remoteAddress": "", "established": "1970-01-01T00:00:00.000000001Z", "receivedMsgs": 0},
+			{"remoteAddress": "", "established": "1970-01-01T00:00:00.000000002Z", "receivedMsgs": 0}
+		]
+	}`, res.PeerInfoJson)
+
+}
+
 func TestConnectFail(t *testing.T) {
 
 	ctx := context.Background()
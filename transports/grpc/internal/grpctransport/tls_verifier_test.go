@@ -35,6 +35,7 @@ import (
 	"github.com/kaleido-io/paladin/config/pkg/pldconf"
 	"github.com/kaleido-io/paladin/toolkit/pkg/prototk"
 	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
+	"github.com/kaleido-io/paladin/toolkit/pkg/tlsverifier"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -139,6 +140,8 @@ func newTestGRPCTransport(t *testing.T, nodeCert, nodeKey string, conf *Config)
 		}
 		transport.grpcServer.Stop()
 		<-transport.serverDone
+		transport.bgCancel()
+		<-transport.certMonitorDone
 	}
 }
 
@@ -267,7 +270,7 @@ func TestGRPCTransport_CACertVerificationWithSubjectRegex_OK(t *testing.T) {
 	ctx := context.Background()
 
 	caCert, caKeyPEM := buildTestCertificate(t, pkix.Name{CommonName: "ca"}, nil, nil)
-	cas, err := getCertListFromPEM(ctx, []byte(caCert))
+	cas, err := tlsverifier.GetCertListFromPEM(ctx, []byte(caCert))
 	assert.NoError(t, err)
 	caKey := getRSAKeyFromPEM(t, caKeyPEM)
 
@@ -322,7 +325,7 @@ func TestGRPCTransport_CAServerWrongCA(t *testing.T) {
 	ctx := context.Background()
 
 	caCert, caKeyPEM := buildTestCertificate(t, pkix.Name{CommonName: "ca"}, nil, nil)
-	cas, err := getCertListFromPEM(ctx, []byte(caCert))
+	cas, err := tlsverifier.GetCertListFromPEM(ctx, []byte(caCert))
 	assert.NoError(t, err)
 	caKey := getRSAKeyFromPEM(t, caKeyPEM)
 
@@ -362,7 +365,7 @@ func TestGRPCTransport_CAClientWrongCA(t *testing.T) {
 	ctx := context.Background()
 
 	caCert, caKeyPEM := buildTestCertificate(t, pkix.Name{CommonName: "ca"}, nil, nil)
-	cas, err := getCertListFromPEM(ctx, []byte(caCert))
+	cas, err := tlsverifier.GetCertListFromPEM(ctx, []byte(caCert))
 	assert.NoError(t, err)
 	caKey := getRSAKeyFromPEM(t, caKeyPEM)
 
@@ -424,7 +427,7 @@ func TestGRPCTransport_DirectCertVerification_WrongIssuerServer(t *testing.T) {
 			Node:      "node2",
 		},
 	})
-	assert.Regexp(t, "PD030007", err)
+	assert.Regexp(t, "PD021102", err)
 
 }
 
@@ -483,7 +486,7 @@ func TestGRPCTransport_DirectCertVerification_BadIssuersServer(t *testing.T) {
 			Node:      "node2",
 		},
 	})
-	assert.Regexp(t, "PD030012", err)
+	assert.Regexp(t, "PD021104", err)
 
 }
 
@@ -512,7 +515,7 @@ func TestGRPCTransport_SubjectRegexpMismatch(t *testing.T) {
 			Node:      "node2",
 		},
 	})
-	assert.Regexp(t, "PD030008", err)
+	assert.Regexp(t, "PD021101", err)
 
 }
 
@@ -539,7 +542,7 @@ func TestGRPCTransport_ClientWrongNode(t *testing.T) {
 			Node:      "node3",
 		},
 	})
-	assert.Regexp(t, "PD030011", err)
+	assert.Regexp(t, "PD021103", err)
 
 }
 
@@ -599,7 +602,7 @@ func TestGRPCTransport_BadTransportIssuerPEM(t *testing.T) {
 			Node:      "node2",
 		},
 	})
-	assert.Regexp(t, "PD030012", err)
+	assert.Regexp(t, "PD021104", err)
 
 }
 
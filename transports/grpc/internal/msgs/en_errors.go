@@ -37,14 +37,10 @@ var (
 	MsgInvalidTransportConfig               = ffe("PD030001", "Invalid transport configuration")
 	MsgConfIncompatibleWithDirectCertVerify = ffe("PD030002", "When directCertVerification is enabled, TLS and clientAuth must be enabled, with no additional CA configuration or insecureSkipHostVerify")
 	MsgInvalidSubjectRegexp                 = ffe("PD030003", "subjectMatchRegex is invalid")
-	MsgVerifierRequiresOneCert              = ffe("PD030004", "certificate verifier expected exactly one certificate from peer certs=%d")
-	MsgSubjectRegexpMismatch                = ffe("PD030005", "subjectMatchRegex did not match the subject in the certificate")
 	MsgPeerTransportDetailsInvalid          = ffe("PD030006", "published peer transport details for node '%s' are invalid")
-	MsgPeerCertificateIssuerInvalid         = ffe("PD030007", "peer '%s' did not provide a certificate signed an expected issuer received=%s issuers=%v")
 	MsgTLSNegotiationFailed                 = ffe("PD030008", "TLS negotiation did not result in a verified peer node name")
 	MsgAuthContextNotAvailable              = ffe("PD030009", "server failed to retrieve the auth context")
 	MsgInvalidReplyToNode                   = ffe("PD030010", "replyTo node does not match sending node")
-	MsgConnectionToWrongNode                = ffe("PD030011", "the TLS identity of the node '%s' does not match the expected node '%s'")
-	MsgPEMCertificateInvalid                = ffe("PD030012", "invalid PEM encoded x509 certificate")
 	MsgErrorNoTargetNode                    = ffe("PD030013", "request to send message but no target node specified")
+	MsgAdditionalIssuersInvalid             = ffe("PD030015", "additionalIssuersFile '%s' does not contain valid PEM certificates")
 )
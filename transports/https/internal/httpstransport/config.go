@@ -0,0 +1,95 @@
+/*
+ * Copyright © 2024 Kaleido, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package httpstransport
+
+import (
+	"github.com/kaleido-io/paladin/config/pkg/confutil"
+	"github.com/kaleido-io/paladin/config/pkg/pldconf"
+	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
+)
+
+type Config struct {
+	// optional remote hostname to return in local transport details
+	ExternalHostname *string `json:"externalHostname"`
+	// TLS configuration details
+	TLS pldconf.TLSConfig `json:"tls"`
+	// address to listen on
+	Address *string `json:"address"`
+	// port to listen on
+	Port *int `json:"port"`
+	// If true (default) a network can be built by publishing self-signed certs to a registry without a common CA.
+	// This disables the default certificate verification chain, and instead performs a direct comparison
+	// of the certificate against the registered certificate for the extracted node name.
+	DirectCertVerification *bool `json:"directCertVerification,omitempty"`
+	// By default directCertVerification will expect the CN of the subject to be the exact registered node name.
+	// Optionally certSubjectMatcher can supply a regexp containing a SINGLE CAPTURE GROUP that can be used to extract the name from the subject string
+	CertSubjectMatcher *string `json:"certSubjectMatcher,omitempty"`
+	// If true (default) messages are sent to each peer over a persistent WebSocket.
+	// Set to false to send each message as a separate HTTPS POST, for proxies that do not support WebSockets.
+	// Inbound messages are accepted over either, regardless of this setting.
+	WebSocket *bool `json:"webSocket,omitempty"`
+	// Timeout for connecting to a peer, and for each HTTPS POST
+	RequestTimeout *string `json:"requestTimeout,omitempty"`
+	// Interval for sending pings on WebSockets, to keep them open through proxies. An inbound WebSocket is closed
+	// if the peer does not respond within this interval plus the requestTimeout. Set to 0 to disable
+	PingInterval *string `json:"pingInterval,omitempty"`
+	// The maximum size of a single message received from a peer
+	MaxMessageSize *string `json:"maxMessageSize,omitempty"`
+}
+
+var ConfigDefaults = &Config{
+	Address:                confutil.P("0.0.0.0"), // public connectivity
+	DirectCertVerification: confutil.P(true),      // with self-signed certificates
+	WebSocket:              confutil.P(true),
+	RequestTimeout:         confutil.P("30s"),
+	PingInterval:           confutil.P("30s"),
+	MaxMessageSize:         confutil.P("10Mb"),
+}
+
+// This is the JSON structure that any node in the network must share to be connectable
+// by this plugin. We require the local node's registered information to be available at configuration
+// time otherwise we cannot start up.
+type PublishedTransportDetails struct {
+	Endpoint string `json:"endpoint"` // the https:// base URL that other nodes can use to connect to this node
+	// A node specific PEM certificate/certificate-set to use to validate the certificate provided by a node
+	// - used in direct certificate validation mode only
+	// - can be the certificate itself for self-signed
+	// - must be the direct parent (not the root of a chain - for that use normal CA verification)
+	Issuers string `json:"issuers,omitempty"`
+}
+
+// This is the JSON structure returned as the peer information for a node, describing the
+// outbound connection used to send to it, and any inbound WebSockets it has connected to us
+type PeerInfo struct {
+	Outbound *OutboundConnInfo       `json:"outbound,omitempty"`
+	Inbound  []*InboundWebSocketInfo `json:"inbound,omitempty"`
+	// count of messages received from the node over HTTPS POST
+	ReceivedPosts uint64 `json:"receivedPosts,omitempty"`
+}
+
+type OutboundConnInfo struct {
+	Endpoint  string             `json:"endpoint,omitempty"`
+	WebSocket bool               `json:"webSocket"`
+	State     string             `json:"state"` // "connecting" or "connected"
+	Connected *tktypes.Timestamp `json:"connected,omitempty"`
+	SentMsgs  uint64             `json:"sentMsgs"`
+}
+
+type InboundWebSocketInfo struct {
+	RemoteAddress string            `json:"remoteAddress"`
+	Established   tktypes.Timestamp `json:"established"`
+	ReceivedMsgs  uint64            `json:"receivedMsgs"`
+}
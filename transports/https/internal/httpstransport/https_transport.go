@@ -0,0 +1,635 @@
+/*
+ * Copyright © 2024 Kaleido, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package httpstransport
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/kaleido-io/paladin/config/pkg/confutil"
+	"github.com/kaleido-io/paladin/toolkit/pkg/log"
+	"github.com/kaleido-io/paladin/toolkit/pkg/plugintk"
+	"github.com/kaleido-io/paladin/toolkit/pkg/prototk"
+	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
+	"github.com/kaleido-io/paladin/toolkit/pkg/tlsconf"
+	"github.com/kaleido-io/paladin/toolkit/pkg/tlsverifier"
+	"github.com/kaleido-io/paladin/transports/https/internal/msgs"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+const (
+	messagePath   = "/message" // each message is POSTed as a protojson encoded prototk.Message
+	webSocketPath = "/ws"      // each message is a protojson encoded prototk.Message in a text frame
+)
+
+type httpsTransport struct {
+	bgCtx     context.Context
+	callbacks plugintk.TransportCallbacks
+
+	name             string
+	listener         net.Listener
+	server           *http.Server
+	serverDone       chan struct{}
+	peerVerifier     *tlsverifier.Verifier
+	baseTLSConfig    *tls.Config
+	externalHostname string
+	localCertificate *tls.Certificate
+	useWebSocket     bool
+	requestTimeout   time.Duration
+	pingInterval     time.Duration
+	maxMessageSize   int64
+	upgrader         *websocket.Upgrader
+
+	conf                Config
+	connLock            sync.Cond
+	outboundConnections map[string]*outboundConn
+	inboundWebSockets   map[*inboundWebSocket]bool
+	receivedPosts       map[string]uint64
+}
+
+type outboundConn struct {
+	nodeName   string
+	connecting bool
+	sendLock   sync.Mutex
+	waiting    int
+	connError  error
+	endpoint   string
+	client     *http.Client    // when sending with HTTPS POST
+	ws         *websocket.Conn // when sending over a WebSocket
+	closeOnce  sync.Once
+	closed     chan struct{}
+	connected  tktypes.Timestamp
+	sentMsgs   atomic.Uint64
+}
+
+type inboundWebSocket struct {
+	nodeName     string
+	remoteAddr   string
+	established  tktypes.Timestamp
+	receivedMsgs atomic.Uint64
+}
+
+func NewPlugin(ctx context.Context) plugintk.PluginBase {
+	return plugintk.NewTransport(NewHTTPSTransport)
+}
+
+func NewHTTPSTransport(callbacks plugintk.TransportCallbacks) plugintk.TransportAPI {
+	return &httpsTransport{
+		bgCtx:               context.Background(),
+		callbacks:           callbacks,
+		connLock:            *sync.NewCond(new(sync.Mutex)),
+		outboundConnections: make(map[string]*outboundConn),
+		inboundWebSockets:   make(map[*inboundWebSocket]bool),
+		receivedPosts:       make(map[string]uint64),
+	}
+}
+
+func (t *httpsTransport) ConfigureTransport(ctx context.Context, req *prototk.ConfigureTransportRequest) (*prototk.ConfigureTransportResponse, error) {
+	// Hold the connlock while setting our state (as we'll read it when creating new conns)
+	t.connLock.L.Lock()
+	defer t.connLock.L.Unlock()
+
+	t.name = req.Name
+
+	err := json.Unmarshal([]byte(req.ConfigJson), &t.conf)
+	if err != nil {
+		return nil, i18n.WrapError(ctx, err, msgs.MsgInvalidTransportConfig)
+	}
+
+	listenAddrNoPort := confutil.StringOrEmpty(t.conf.Address, "")
+	if t.conf.Port == nil || listenAddrNoPort == "" {
+		return nil, i18n.NewError(ctx, msgs.MsgListenerPortAndAddressRequired)
+	}
+	listenAddr := fmt.Sprintf("%s:%d", listenAddrNoPort, *t.conf.Port)
+
+	t.externalHostname = confutil.StringNotEmpty(t.conf.ExternalHostname, listenAddrNoPort)
+	t.useWebSocket = confutil.Bool(t.conf.WebSocket, *ConfigDefaults.WebSocket)
+	t.requestTimeout = confutil.DurationMin(t.conf.RequestTimeout, 0, *ConfigDefaults.RequestTimeout)
+	t.pingInterval = confutil.DurationMin(t.conf.PingInterval, 0, *ConfigDefaults.PingInterval)
+	t.maxMessageSize = confutil.ByteSize(t.conf.MaxMessageSize, 1024, *ConfigDefaults.MaxMessageSize)
+	t.upgrader = &websocket.Upgrader{HandshakeTimeout: t.requestTimeout}
+
+	var subjectMatchRegex *regexp.Regexp
+	certSubjectMatcher := confutil.StringOrEmpty(t.conf.CertSubjectMatcher, "")
+	if certSubjectMatcher != "" {
+		if subjectMatchRegex, err = regexp.Compile(*t.conf.CertSubjectMatcher); err != nil {
+			return nil, i18n.WrapError(ctx, err, msgs.MsgInvalidSubjectRegexp, *t.conf.CertSubjectMatcher)
+		}
+	}
+
+	// We only support mutual-TLS in this transport (with direct trust of certificates via registry, or use of a CA)
+	t.conf.TLS.Enabled = true
+	t.conf.TLS.ClientAuth = true // Note if this is unset the ClientCAs will not be configured
+	tlsDetail, err := tlsconf.BuildTLSConfigExt(ctx, &t.conf.TLS, tlsconf.ServerType)
+	if err != nil {
+		return nil, err
+	}
+	baseTLSConfig := tlsDetail.TLSConfig
+	t.localCertificate = tlsDetail.Certificate
+
+	directCertVerification := confutil.Bool(t.conf.DirectCertVerification, *ConfigDefaults.DirectCertVerification)
+	baseTLSConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	if directCertVerification {
+		// Check the tls default settings haven't been set with conflicting config
+		if t.conf.TLS.CAFile != "" || t.conf.TLS.CA != "" || t.conf.TLS.InsecureSkipHostVerify || len(t.conf.TLS.RequiredDNAttributes) > 0 {
+			return nil, i18n.NewError(ctx, msgs.MsgConfIncompatibleWithDirectCertVerify)
+		}
+		// Set InsecureSkipVerify and RequireAnyClientCert to skip the default
+		// validation we are replacing. This will not disable VerifyConnection.
+		baseTLSConfig.InsecureSkipVerify = true
+		baseTLSConfig.ClientAuth = tls.RequireAnyClientCert
+	}
+
+	t.listener, err = net.Listen("tcp", listenAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	t.baseTLSConfig = baseTLSConfig
+	t.peerVerifier = tlsverifier.NewVerifier(t.bgCtx, directCertVerification, subjectMatchRegex, t.getIssuers)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST "+messagePath, t.handlePost)
+	mux.HandleFunc("GET "+webSocketPath, t.handleWebSocket)
+	t.server = &http.Server{
+		Handler:           mux,
+		TLSConfig:         t.peerVerifier.TLSConfig(t.baseTLSConfig, "", nil),
+		ReadHeaderTimeout: t.requestTimeout,
+	}
+
+	// Kick off the HTTPS listener
+	if t.serverDone == nil {
+		t.serverDone = make(chan struct{})
+		go t.serve()
+	}
+
+	return &prototk.ConfigureTransportResponse{}, nil
+}
+
+func (t *httpsTransport) serve() {
+	defer close(t.serverDone)
+
+	log.L(t.bgCtx).Infof("HTTPS server for plugin %s starting on %s", t.name, t.listener.Addr())
+	err := t.server.Serve(tls.NewListener(t.listener, t.server.TLSConfig))
+	log.L(t.bgCtx).Infof("HTTPS server for plugin %s stopped (err=%v)", t.name, err)
+}
+
+// The TLS handshake verified the certificate of the peer against the registry,
+// so we just need to extract the node name again for each request
+func (t *httpsTransport) verifiedNode(ctx context.Context, r *http.Request) (string, error) {
+	if r.TLS == nil {
+		return "", i18n.NewError(ctx, msgs.MsgNoVerifiedPeer)
+	}
+	node, _, err := t.peerVerifier.PeerNodeName(ctx, r.TLS)
+	if err != nil {
+		return "", i18n.WrapError(ctx, err, msgs.MsgNoVerifiedPeer)
+	}
+	return node, nil
+}
+
+func (t *httpsTransport) writeError(ctx context.Context, w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	if ffe, ok := err.(i18n.FFError); ok {
+		status = ffe.HTTPStatus()
+	}
+	log.L(ctx).Errorf("Returning %d: %s", status, err)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+}
+
+// Delivers a message received from the peer to our local Paladin server
+func (t *httpsTransport) receive(ctx context.Context, node string, data []byte) error {
+	var msg prototk.Message
+	if err := protojson.Unmarshal(data, &msg); err != nil {
+		return i18n.WrapError(ctx, err, msgs.MsgInvalidMessage)
+	}
+
+	log.L(ctx).Infof("HTTPS received message id=%s cid=%v component=%s messageType=%s replyTo=%s from peer %s",
+		msg.MessageId, msg.CorrelationId, msg.Component, msg.MessageType, msg.ReplyTo, node)
+
+	// Check the message is from the node we expect.
+	// Note the destination node is checked by Paladin - just just have to verify the sender.
+	if msg.ReplyTo != node {
+		log.L(ctx).Errorf("Invalid replyTo: %s", msg.ReplyTo)
+		return i18n.NewError(ctx, msgs.MsgInvalidReplyToNode)
+	}
+
+	_, err := t.callbacks.ReceiveMessage(ctx, &prototk.ReceiveMessageRequest{Message: &msg, FromNode: node})
+	if err != nil {
+		log.L(ctx).Errorf("Receive failed (err=%s): %s", err, tktypes.ProtoToJSON(&msg))
+		return err
+	}
+	return nil
+}
+
+func (t *httpsTransport) handlePost(w http.ResponseWriter, r *http.Request) {
+	ctx := log.WithLogField(r.Context(), "remote", r.RemoteAddr)
+	node, err := t.verifiedNode(ctx, r)
+	if err == nil {
+		var data []byte
+		data, err = io.ReadAll(http.MaxBytesReader(w, r.Body, t.maxMessageSize))
+		if err != nil {
+			err = i18n.WrapError(ctx, err, msgs.MsgInvalidMessage)
+		} else {
+			err = t.receive(ctx, node, data)
+		}
+	}
+	if err != nil {
+		t.writeError(ctx, w, err)
+		return
+	}
+	t.connLock.L.Lock()
+	t.receivedPosts[node]++
+	t.connLock.L.Unlock()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (t *httpsTransport) handleWebSocket(w http.ResponseWriter, r *http.Request) {
+	ctx := log.WithLogField(r.Context(), "remote", r.RemoteAddr)
+	node, err := t.verifiedNode(ctx, r)
+	if err != nil {
+		t.writeError(ctx, w, err)
+		return
+	}
+	conn, err := t.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// The upgrader has already written the error response
+		log.L(ctx).Errorf("WebSocket upgrade failed from node %s: %s", node, err)
+		return
+	}
+	defer conn.Close()
+	conn.SetReadLimit(t.maxMessageSize)
+
+	// A peer that stops responding is disconnected, as we ping it and require a pong
+	// (or a message) within the read deadline
+	extendDeadline := func() error { return nil }
+	if t.pingInterval > 0 {
+		extendDeadline = func() error { return conn.SetReadDeadline(time.Now().Add(t.pingInterval + t.requestTimeout)) }
+		conn.SetPongHandler(func(string) error { return extendDeadline() })
+		closed := make(chan struct{})
+		defer close(closed)
+		go t.pingWebSocket(conn, node, closed, func() { _ = conn.Close() })
+	}
+
+	// Go into the long-lived receive loop until the client disconnects
+	ctx = log.WithLogField(ctx, "node", node)
+	log.L(ctx).Infof("WebSocket message stream established from node %s", node)
+	iws := t.addInboundWebSocket(node, r.RemoteAddr)
+	defer t.removeInboundWebSocket(iws)
+	for {
+		var data []byte
+		err := extendDeadline()
+		if err == nil {
+			_, data, err = conn.ReadMessage()
+		}
+		if err != nil {
+			log.L(ctx).Infof("WebSocket message stream from %s closing (err=%v)", node, err)
+			return
+		}
+		if err := t.receive(ctx, node, data); err != nil {
+			// Close the stream, so the sender knows to reconnect
+			_ = conn.WriteControl(websocket.CloseMessage,
+				websocket.FormatCloseMessage(websocket.CloseInternalServerErr, err.Error()),
+				time.Now().Add(t.requestTimeout))
+			return
+		}
+		iws.receivedMsgs.Add(1)
+	}
+}
+
+func (t *httpsTransport) addInboundWebSocket(node, remoteAddr string) *inboundWebSocket {
+	t.connLock.L.Lock()
+	defer t.connLock.L.Unlock()
+	iws := &inboundWebSocket{
+		nodeName:    node,
+		remoteAddr:  remoteAddr,
+		established: tktypes.TimestampNow(),
+	}
+	t.inboundWebSockets[iws] = true
+	return iws
+}
+
+func (t *httpsTransport) removeInboundWebSocket(iws *inboundWebSocket) {
+	t.connLock.L.Lock()
+	defer t.connLock.L.Unlock()
+	delete(t.inboundWebSockets, iws)
+}
+
+func (t *httpsTransport) getTransportDetails(ctx context.Context, node string) (transportDetails *PublishedTransportDetails, err error) {
+	gtdr, err := t.callbacks.GetTransportDetails(ctx, &prototk.GetTransportDetailsRequest{
+		Node: node,
+	})
+	if err != nil {
+		log.L(ctx).Errorf("lookup failed for node %s: %s", node, err)
+		return nil, err
+	}
+
+	// Parse the details
+	if err = json.Unmarshal([]byte(gtdr.TransportDetails), &transportDetails); err != nil {
+		return nil, i18n.WrapError(ctx, err, msgs.MsgPeerTransportDetailsInvalid, node)
+	}
+
+	return transportDetails, nil
+}
+
+func (t *httpsTransport) getIssuers(ctx context.Context, node string) (string, error) {
+	transportDetails, err := t.getTransportDetails(ctx, node)
+	if err != nil {
+		return "", err
+	}
+	return transportDetails.Issuers, nil
+}
+
+func (t *httpsTransport) waitExistingOrNewConn(nodeName string) (bool, *outboundConn, error) {
+	t.connLock.L.Lock()
+	defer t.connLock.L.Unlock()
+	existing := t.outboundConnections[nodeName]
+	if existing != nil {
+		// Multiple routines might try to connect concurrently, so we have a condition
+		existing.waiting++
+		for existing.connecting {
+			t.connLock.Wait()
+		}
+		return false, existing, existing.connError
+	}
+	// We need to create the connection - put the placeholder in the map
+	newConn := &outboundConn{nodeName: nodeName, connecting: true, closed: make(chan struct{})}
+	t.outboundConnections[nodeName] = newConn
+	return true, newConn, nil
+}
+
+// Closes the connection and removes it (if it has not already been replaced), so the next send reconnects
+func (t *httpsTransport) closeConnection(oc *outboundConn) {
+	oc.closeOnce.Do(func() {
+		close(oc.closed)
+		if oc.ws != nil {
+			_ = oc.ws.Close()
+		}
+		if oc.client != nil {
+			oc.client.CloseIdleConnections()
+		}
+	})
+	t.connLock.L.Lock()
+	defer t.connLock.L.Unlock()
+	if t.outboundConnections[oc.nodeName] == oc {
+		delete(t.outboundConnections, oc.nodeName)
+	}
+}
+
+func (t *httpsTransport) post(ctx context.Context, oc *outboundConn, data []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, oc.endpoint+messagePath, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	res, err := oc.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(res.Body, 4096))
+		return i18n.NewError(ctx, msgs.MsgSendFailed, oc.nodeName, res.StatusCode, body)
+	}
+	return nil
+}
+
+func (t *httpsTransport) send(ctx context.Context, oc *outboundConn, message *prototk.Message) (err error) {
+	data, err := protojson.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	oc.sendLock.Lock()
+	defer func() {
+		// Drop the send lock before taking conn lock to remove from the connections
+		oc.sendLock.Unlock()
+		if err != nil {
+			log.L(ctx).Errorf("closing connection to %s due to send err: %s", oc.nodeName, err)
+			t.closeConnection(oc)
+		}
+	}()
+	if oc.ws != nil {
+		err = oc.ws.WriteMessage(websocket.TextMessage, data)
+	} else {
+		err = t.post(ctx, oc, data)
+	}
+	if err == nil {
+		oc.sentMsgs.Add(1)
+	}
+	return
+}
+
+// We do not receive any messages on an outbound WebSocket, but we must read to process
+// control frames, and to find out when the server closes the connection
+func (t *httpsTransport) readOutbound(oc *outboundConn) {
+	for {
+		if _, _, err := oc.ws.ReadMessage(); err != nil {
+			log.L(t.bgCtx).Infof("WebSocket to %s closed: %s", oc.nodeName, err)
+			t.closeConnection(oc)
+			return
+		}
+	}
+}
+
+// Keeps idle WebSockets alive through proxies that time out inactive connections,
+// and prompts the peer for the pongs that extend the read deadline of an inbound WebSocket
+func (t *httpsTransport) pingWebSocket(ws *websocket.Conn, node string, closed <-chan struct{}, onFailure func()) {
+	ticker := time.NewTicker(t.pingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := ws.WriteControl(websocket.PingMessage, nil, time.Now().Add(t.requestTimeout)); err != nil {
+				log.L(t.bgCtx).Infof("WebSocket ping to %s failed: %s", node, err)
+				onFailure()
+				return
+			}
+		case <-closed:
+			return
+		}
+	}
+}
+
+func (t *httpsTransport) getConnection(ctx context.Context, nodeName string) (*outboundConn, error) {
+
+	isNew, oc, err := t.waitExistingOrNewConn(nodeName)
+	if !isNew || err != nil {
+		return oc, err
+	}
+
+	// We must ensure we complete the newConn (for good or bad)
+	// and notify everyone waiting to check status before we return
+	defer func() {
+		t.connLock.L.Lock()
+		oc.connecting = false
+		oc.connected = tktypes.TimestampNow()
+		if err != nil {
+			// copy our error to anyone queuing - everybody fails
+			oc.connError = err
+			// remove this entry, so the next one will try again
+			delete(t.outboundConnections, nodeName)
+		}
+		t.connLock.Broadcast()
+		t.connLock.L.Unlock()
+	}()
+
+	// We need to get the connection details
+	transportDetails, err := t.getTransportDetails(ctx, nodeName)
+	if err != nil {
+		return nil, err
+	}
+	endpoint, err := url.Parse(transportDetails.Endpoint)
+	if err != nil || endpoint.Scheme != "https" {
+		return nil, i18n.WrapError(ctx, err, msgs.MsgInvalidEndpoint, transportDetails.Endpoint, nodeName)
+	}
+	t.connLock.L.Lock()
+	oc.endpoint = strings.TrimSuffix(endpoint.String(), "/")
+	t.connLock.L.Unlock()
+
+	// Ok - try connecting
+	// On the client side we connect expecting to find a particular node on the other side
+	tlsConfig := t.peerVerifier.TLSConfig(t.baseTLSConfig, nodeName, nil)
+	if !t.useWebSocket {
+		// Each send is a separate POST, using a keep-alive connection pool that verifies the peer on each new connection.
+		// Note that we use HTTP/1.1 as the transport does not enable HTTP/2 when supplied a custom TLS config.
+		log.L(ctx).Infof("HTTPS sending to new peer %s (endpoint=%s)", nodeName, oc.endpoint)
+		oc.client = &http.Client{
+			Transport: &http.Transport{TLSClientConfig: tlsConfig},
+			Timeout:   t.requestTimeout,
+		}
+		return oc, nil
+	}
+
+	log.L(ctx).Infof("WebSocket connecting to new peer %s (endpoint=%s)", nodeName, oc.endpoint)
+	endpoint.Scheme = "wss"
+	endpoint.Path = strings.TrimSuffix(endpoint.Path, "/") + webSocketPath
+	dialer := &websocket.Dialer{
+		TLSClientConfig:  tlsConfig,
+		HandshakeTimeout: t.requestTimeout,
+	}
+	var res *http.Response
+	oc.ws, res, err = dialer.DialContext(ctx, endpoint.String(), nil)
+	if res != nil {
+		_ = res.Body.Close()
+	}
+	if err != nil {
+		return nil, err
+	}
+	go t.readOutbound(oc)
+	if t.pingInterval > 0 {
+		go t.pingWebSocket(oc.ws, nodeName, oc.closed, func() { t.closeConnection(oc) })
+	}
+	return oc, nil
+}
+
+func (t *httpsTransport) SendMessage(ctx context.Context, req *prototk.SendMessageRequest) (*prototk.SendMessageResponse, error) {
+	msg := req.Message
+	if req.Message.Node == "" {
+		return nil, i18n.NewError(ctx, msgs.MsgErrorNoTargetNode)
+	}
+	oc, err := t.getConnection(ctx, msg.Node)
+	if err == nil {
+		log.L(ctx).Infof("HTTPS sending message id=%s cid=%v component=%s messageType=%s replyTo=%s to peer %s",
+			msg.MessageId, msg.CorrelationId, msg.Component, msg.MessageType, msg.ReplyTo, msg.Node)
+		err = t.send(ctx, oc, msg)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &prototk.SendMessageResponse{}, nil
+}
+
+func (t *httpsTransport) GetLocalDetails(ctx context.Context, req *prototk.GetLocalDetailsRequest) (*prototk.GetLocalDetailsResponse, error) {
+
+	certList := t.localCertificate.Certificate
+	issuersText := new(strings.Builder)
+	for _, cert := range certList {
+		_ = pem.Encode(issuersText, &pem.Block{
+			Type:  "CERTIFICATE",
+			Bytes: cert,
+		})
+	}
+
+	localDetails := &PublishedTransportDetails{
+		Endpoint: fmt.Sprintf("https://%s:%d", t.externalHostname, *t.conf.Port),
+		Issuers:  issuersText.String(),
+	}
+	jsonDetails, _ := json.Marshal(&localDetails)
+
+	return &prototk.GetLocalDetailsResponse{
+		TransportDetails: string(jsonDetails),
+	}, nil
+
+}
+
+func (t *httpsTransport) GetPeerInfo(ctx context.Context, req *prototk.GetPeerInfoRequest) (*prototk.GetPeerInfoResponse, error) {
+	t.connLock.L.Lock()
+	defer t.connLock.L.Unlock()
+
+	var peerInfo PeerInfo
+	if oc := t.outboundConnections[req.Node]; oc != nil {
+		peerInfo.Outbound = &OutboundConnInfo{
+			Endpoint:  oc.endpoint,
+			WebSocket: t.useWebSocket,
+			State:     "connecting",
+			SentMsgs:  oc.sentMsgs.Load(),
+		}
+		if !oc.connecting {
+			peerInfo.Outbound.State = "connected"
+			peerInfo.Outbound.Connected = &oc.connected
+		}
+	}
+	for iws := range t.inboundWebSockets {
+		if iws.nodeName == req.Node {
+			peerInfo.Inbound = append(peerInfo.Inbound, &InboundWebSocketInfo{
+				RemoteAddress: iws.remoteAddr,
+				Established:   iws.established,
+				ReceivedMsgs:  iws.receivedMsgs.Load(),
+			})
+		}
+	}
+	peerInfo.ReceivedPosts = t.receivedPosts[req.Node]
+	if peerInfo.Outbound == nil && peerInfo.Inbound == nil && peerInfo.ReceivedPosts == 0 {
+		return &prototk.GetPeerInfoResponse{}, nil
+	}
+	sort.Slice(peerInfo.Inbound, func(i, j int) bool { return peerInfo.Inbound[i].Established < peerInfo.Inbound[j].Established })
+	return &prototk.GetPeerInfoResponse{
+		PeerInfoJson: tktypes.JSONString(&peerInfo).String(),
+	}, nil
+}
+
+// Each new connection is verified against the latest transport details, but established connections are not rechecked
+func (t *httpsTransport) TransportDetailsChanged(ctx context.Context, req *prototk.TransportDetailsChangedRequest) (*prototk.TransportDetailsChangedResponse, error) {
+	return &prototk.TransportDetailsChangedResponse{}, nil
+}
@@ -0,0 +1,1005 @@
+/*
+ * Copyright © 2024 Kaleido, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package httpstransport
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/kaleido-io/paladin/config/pkg/confutil"
+	"github.com/kaleido-io/paladin/config/pkg/pldconf"
+	"github.com/kaleido-io/paladin/toolkit/pkg/prototk"
+	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
+	"github.com/kaleido-io/paladin/toolkit/pkg/tlsverifier"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testCallbacks struct {
+	getTransportDetails func(context.Context, *prototk.GetTransportDetailsRequest) (*prototk.GetTransportDetailsResponse, error)
+	receiveMessage      func(context.Context, *prototk.ReceiveMessageRequest) (*prototk.ReceiveMessageResponse, error)
+}
+
+func (tc *testCallbacks) GetTransportDetails(ctx context.Context, req *prototk.GetTransportDetailsRequest) (*prototk.GetTransportDetailsResponse, error) {
+	return tc.getTransportDetails(ctx, req)
+}
+
+func (tc *testCallbacks) ReceiveMessage(ctx context.Context, req *prototk.ReceiveMessageRequest) (*prototk.ReceiveMessageResponse, error) {
+	return tc.receiveMessage(ctx, req)
+}
+
+func receiveTo(received chan *prototk.Message) func(*testCallbacks, *testCallbacks) {
+	return func(_, callbacks2 *testCallbacks) {
+		callbacks2.receiveMessage = func(ctx context.Context, rmr *prototk.ReceiveMessageRequest) (*prototk.ReceiveMessageResponse, error) {
+			received <- rmr.Message
+			return &prototk.ReceiveMessageResponse{}, nil
+		}
+	}
+}
+
+func sendToNode2(ctx context.Context, plugin *httpsTransport) error {
+	_, err := plugin.SendMessage(ctx, &prototk.SendMessageRequest{
+		Message: &prototk.Message{
+			ReplyTo:   "node1",
+			Component: "to.you",
+			Node:      "node2",
+		},
+	})
+	return err
+}
+
+func getOutboundConn(plugin *httpsTransport, node string) *outboundConn {
+	plugin.connLock.L.Lock()
+	defer plugin.connLock.L.Unlock()
+	return plugin.outboundConnections[node]
+}
+
+func TestPluginLifecycle(t *testing.T) {
+	pb := NewPlugin(context.Background())
+	assert.NotNil(t, pb)
+}
+
+func TestBadConfigJSON(t *testing.T) {
+
+	callbacks := &testCallbacks{}
+	transport := NewHTTPSTransport(callbacks).(*httpsTransport)
+	_, err := transport.ConfigureTransport(transport.bgCtx, &prototk.ConfigureTransportRequest{
+		Name:       "https",
+		ConfigJson: `{!!!!`,
+	})
+	assert.Regexp(t, "PD070001", err)
+
+}
+
+func TestMissingListenerPort(t *testing.T) {
+
+	callbacks := &testCallbacks{}
+	transport := NewHTTPSTransport(callbacks).(*httpsTransport)
+	_, err := transport.ConfigureTransport(transport.bgCtx, &prototk.ConfigureTransportRequest{
+		Name:       "https",
+		ConfigJson: `{}`,
+	})
+	assert.Regexp(t, "PD070000", err)
+
+}
+
+func TestBadCertSubjectMatcher(t *testing.T) {
+
+	callbacks := &testCallbacks{}
+	transport := NewHTTPSTransport(callbacks).(*httpsTransport)
+	_, err := transport.ConfigureTransport(transport.bgCtx, &prototk.ConfigureTransportRequest{
+		Name:       "https",
+		ConfigJson: `{"address": "127.0.0.1", "port": 0, "certSubjectMatcher": "[[[[[[[badness"}`,
+	})
+	assert.Regexp(t, "PD070003", err)
+
+}
+
+func TestBadTLSConf(t *testing.T) {
+
+	callbacks := &testCallbacks{}
+	transport := NewHTTPSTransport(callbacks).(*httpsTransport)
+	_, err := transport.ConfigureTransport(transport.bgCtx, &prototk.ConfigureTransportRequest{
+		Name:       "https",
+		ConfigJson: `{"address": "127.0.0.1", "port": 0, "tls": { "caFile": "` + t.TempDir() + `" }}`,
+	})
+	assert.Regexp(t, "PD020401", err)
+
+}
+
+func TestBadDirectCertVerificationConf(t *testing.T) {
+
+	callbacks := &testCallbacks{}
+	transport := NewHTTPSTransport(callbacks).(*httpsTransport)
+	_, err := transport.ConfigureTransport(transport.bgCtx, &prototk.ConfigureTransportRequest{
+		Name:       "https",
+		ConfigJson: `{"address": "127.0.0.1", "port": 0, "tls": { "requiredDNAttributes": {"cn":"anything"} }}`,
+	})
+	assert.Regexp(t, "PD070002", err)
+
+}
+
+func TestBadListenerConf(t *testing.T) {
+
+	callbacks := &testCallbacks{}
+	transport := NewHTTPSTransport(callbacks).(*httpsTransport)
+	_, err := transport.ConfigureTransport(transport.bgCtx, &prototk.ConfigureTransportRequest{
+		Name:       "https",
+		ConfigJson: `{"address": "::::::::", "port": 0}`,
+	})
+	assert.Regexp(t, "listen", err)
+}
+
+func TestPostSendOK(t *testing.T) {
+
+	ctx := context.Background()
+
+	received := make(chan *prototk.Message, 1)
+	plugin1, plugin2, done := newSuccessfulVerifiedConnectionConf(t, &Config{WebSocket: confutil.P(false)}, receiveTo(received))
+	defer done()
+
+	for i := 0; i < 3; i++ {
+		err := sendToNode2(ctx, plugin1)
+		require.NoError(t, err)
+		assert.Equal(t, "node1", (<-received).ReplyTo)
+	}
+
+	res, err := plugin1.GetPeerInfo(ctx, &prototk.GetPeerInfoRequest{Node: "node2"})
+	require.NoError(t, err)
+	var outboundInfo PeerInfo
+	err = json.Unmarshal([]byte(res.PeerInfoJson), &outboundInfo)
+	require.NoError(t, err)
+	require.NotNil(t, outboundInfo.Outbound)
+	assert.Equal(t, "https://"+plugin2.listener.Addr().String(), outboundInfo.Outbound.Endpoint)
+	assert.False(t, outboundInfo.Outbound.WebSocket)
+	assert.Equal(t, uint64(3), outboundInfo.Outbound.SentMsgs)
+
+	res, err = plugin2.GetPeerInfo(ctx, &prototk.GetPeerInfoRequest{Node: "node1"})
+	require.NoError(t, err)
+	var inboundInfo PeerInfo
+	err = json.Unmarshal([]byte(res.PeerInfoJson), &inboundInfo)
+	require.NoError(t, err)
+	assert.Nil(t, inboundInfo.Outbound)
+	assert.Empty(t, inboundInfo.Inbound)
+	assert.Equal(t, uint64(3), inboundInfo.ReceivedPosts)
+
+}
+
+func TestPostReceiveFail(t *testing.T) {
+
+	ctx := context.Background()
+
+	plugin1, _, done := newSuccessfulVerifiedConnectionConf(t, &Config{WebSocket: confutil.P(false)}, func(_, callbacks2 *testCallbacks) {
+		callbacks2.receiveMessage = func(ctx context.Context, rmr *prototk.ReceiveMessageRequest) (*prototk.ReceiveMessageResponse, error) {
+			return nil, fmt.Errorf("pop")
+		}
+	})
+	defer done()
+
+	err := sendToNode2(ctx, plugin1)
+	assert.Regexp(t, "PD070009.*500.*pop", err)
+
+	// The failed connection is discarded
+	plugin1.connLock.L.Lock()
+	defer plugin1.connLock.L.Unlock()
+	assert.Empty(t, plugin1.outboundConnections)
+
+}
+
+func TestPostBadReplyTo(t *testing.T) {
+
+	ctx := context.Background()
+
+	plugin1, _, done := newSuccessfulVerifiedConnectionConf(t, &Config{WebSocket: confutil.P(false)})
+	defer done()
+
+	_, err := plugin1.SendMessage(ctx, &prototk.SendMessageRequest{
+		Message: &prototk.Message{
+			ReplyTo:   "not.mine",
+			Component: "to.you",
+			Node:      "node2",
+		},
+	})
+	assert.Regexp(t, "PD070009.*403.*PD070006", err)
+
+}
+
+func TestPostTooLarge(t *testing.T) {
+
+	ctx := context.Background()
+
+	plugin1, _, done := newSuccessfulVerifiedConnectionConf(t, &Config{
+		WebSocket:      confutil.P(false),
+		MaxMessageSize: confutil.P("1Kb"),
+	})
+	defer done()
+
+	_, err := plugin1.SendMessage(ctx, &prototk.SendMessageRequest{
+		Message: &prototk.Message{
+			ReplyTo:   "node1",
+			Component: "to.you",
+			Node:      "node2",
+			Payload:   []byte(strings.Repeat("x", 2048)),
+		},
+	})
+	assert.Regexp(t, "PD070009.*400.*PD070008", err)
+
+}
+
+func TestPostConnectFail(t *testing.T) {
+
+	ctx := context.Background()
+
+	plugin1, plugin2, done := newSuccessfulVerifiedConnectionConf(t, &Config{WebSocket: confutil.P(false)})
+	defer done()
+
+	_ = plugin2.server.Close()
+
+	err := sendToNode2(ctx, plugin1)
+	assert.Regexp(t, "connection refused", err)
+
+}
+
+func TestWebSocketReceiveFail(t *testing.T) {
+
+	ctx := context.Background()
+
+	plugin1, _, done := newSuccessfulVerifiedConnection(t, func(_, callbacks2 *testCallbacks) {
+		callbacks2.receiveMessage = func(ctx context.Context, rmr *prototk.ReceiveMessageRequest) (*prototk.ReceiveMessageResponse, error) {
+			return nil, fmt.Errorf("pop")
+		}
+	})
+	defer done()
+
+	// The send is asynchronous, but the receiver closes the WebSocket
+	err := sendToNode2(ctx, plugin1)
+	require.NoError(t, err)
+
+	// ... so the sender discards the connection, to reconnect on the next send
+	for getOutboundConn(plugin1, "node2") != nil {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+}
+
+func TestWebSocketSendAfterClose(t *testing.T) {
+
+	ctx := context.Background()
+
+	received := make(chan *prototk.Message, 1)
+	plugin1, _, done := newSuccessfulVerifiedConnection(t, receiveTo(received))
+	defer done()
+
+	err := sendToNode2(ctx, plugin1)
+	require.NoError(t, err)
+	<-received
+
+	// Close the underlying connection, without removing it from the map
+	oc := getOutboundConn(plugin1, "node2")
+	_ = oc.ws.UnderlyingConn().Close()
+
+	err = plugin1.send(ctx, oc, &prototk.Message{ReplyTo: "node1", Node: "node2"})
+	assert.Error(t, err)
+
+	// Next one reconnects
+	err = sendToNode2(ctx, plugin1)
+	require.NoError(t, err)
+	<-received
+	assert.NotSame(t, oc, getOutboundConn(plugin1, "node2"))
+
+}
+
+func TestWebSocketPing(t *testing.T) {
+
+	ctx := context.Background()
+
+	received := make(chan *prototk.Message, 1)
+	plugin1, _, done := newSuccessfulVerifiedConnectionConf(t, &Config{PingInterval: confutil.P("1ms")}, receiveTo(received))
+	defer done()
+
+	err := sendToNode2(ctx, plugin1)
+	require.NoError(t, err)
+	<-received
+
+	// Let some pings flow, then check we clean up once the connection is closed underneath the WebSocket
+	time.Sleep(20 * time.Millisecond)
+	oc := getOutboundConn(plugin1, "node2")
+	_ = oc.ws.UnderlyingConn().Close()
+	<-oc.closed
+
+}
+
+func inboundWebSocketCount(plugin *httpsTransport) int {
+	plugin.connLock.L.Lock()
+	defer plugin.connLock.L.Unlock()
+	return len(plugin.inboundWebSockets)
+}
+
+func TestWebSocketInboundKeptAliveByPongs(t *testing.T) {
+
+	ctx := context.Background()
+
+	received := make(chan *prototk.Message, 1)
+	plugin1, plugin2, done := newSuccessfulVerifiedConnectionConf(t, &Config{
+		PingInterval:   confutil.P("10ms"),
+		RequestTimeout: confutil.P("200ms"),
+	}, receiveTo(received))
+	defer done()
+
+	err := sendToNode2(ctx, plugin1)
+	require.NoError(t, err)
+	<-received
+
+	// The outbound WebSocket answers the pings from node2 for longer than the read deadline
+	time.Sleep(500 * time.Millisecond)
+	assert.Equal(t, 1, inboundWebSocketCount(plugin2))
+	err = sendToNode2(ctx, plugin1)
+	require.NoError(t, err)
+	<-received
+
+}
+
+func TestWebSocketInboundPeerNotResponding(t *testing.T) {
+
+	plugin1, plugin2, done := newSuccessfulVerifiedConnectionConf(t, &Config{
+		PingInterval:   confutil.P("10ms"),
+		RequestTimeout: confutil.P("200ms"),
+	})
+	defer done()
+
+	// Connect as node1, but never read, so the pings from node2 are not answered
+	dialer := &websocket.Dialer{TLSClientConfig: plugin1.peerVerifier.TLSConfig(plugin1.baseTLSConfig, "node2", nil)}
+	ws, res, err := dialer.Dial("wss://"+plugin2.listener.Addr().String()+webSocketPath, nil)
+	require.NoError(t, err)
+	_ = res.Body.Close()
+	defer ws.Close()
+
+	require.Eventually(t, func() bool { return inboundWebSocketCount(plugin2) == 1 }, 2*time.Second, 5*time.Millisecond)
+	require.Eventually(t, func() bool { return inboundWebSocketCount(plugin2) == 0 }, 2*time.Second, 5*time.Millisecond)
+
+}
+
+func TestGetPeerInfo(t *testing.T) {
+
+	ctx := context.Background()
+
+	received := make(chan *prototk.Message, 1)
+	plugin1, plugin2, done := newSuccessfulVerifiedConnection(t, receiveTo(received))
+	defer done()
+
+	// Nothing to report before we connect
+	res, err := plugin1.GetPeerInfo(ctx, &prototk.GetPeerInfoRequest{Node: "node2"})
+	require.NoError(t, err)
+	assert.Empty(t, res.PeerInfoJson)
+
+	err = sendToNode2(ctx, plugin1)
+	require.NoError(t, err)
+	<-received
+
+	res, err = plugin1.GetPeerInfo(ctx, &prototk.GetPeerInfoRequest{Node: "node2"})
+	require.NoError(t, err)
+	var outboundInfo PeerInfo
+	err = json.Unmarshal([]byte(res.PeerInfoJson), &outboundInfo)
+	require.NoError(t, err)
+	require.NotNil(t, outboundInfo.Outbound)
+	assert.Equal(t, "https://"+plugin2.listener.Addr().String(), outboundInfo.Outbound.Endpoint)
+	assert.True(t, outboundInfo.Outbound.WebSocket)
+	assert.Equal(t, "connected", outboundInfo.Outbound.State)
+	assert.NotNil(t, outboundInfo.Outbound.Connected)
+	assert.Equal(t, uint64(1), outboundInfo.Outbound.SentMsgs)
+	assert.Empty(t, outboundInfo.Inbound)
+
+	// The receive count is updated after delivery
+	var inboundInfo PeerInfo
+	for inboundInfo.Inbound == nil || inboundInfo.Inbound[0].ReceivedMsgs == 0 {
+		res, err = plugin2.GetPeerInfo(ctx, &prototk.GetPeerInfoRequest{Node: "node1"})
+		require.NoError(t, err)
+		err = json.Unmarshal([]byte(res.PeerInfoJson), &inboundInfo)
+		require.NoError(t, err)
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Nil(t, inboundInfo.Outbound)
+	require.Len(t, inboundInfo.Inbound, 1)
+	assert.NotEmpty(t, inboundInfo.Inbound[0].RemoteAddress)
+	assert.NotZero(t, inboundInfo.Inbound[0].Established)
+	assert.Equal(t, uint64(1), inboundInfo.Inbound[0].ReceivedMsgs)
+	assert.Zero(t, inboundInfo.ReceivedPosts)
+
+}
+
+func TestGetPeerInfoConnecting(t *testing.T) {
+
+	ctx := context.Background()
+
+	plugin, _, _, done := newTestHTTPSTransport(t, "", "", &Config{})
+	defer done()
+
+	plugin.outboundConnections["node2"] = &outboundConn{nodeName: "node2", connecting: true}
+	plugin.inboundWebSockets[&inboundWebSocket{nodeName: "node2", established: 2}] = true
+	plugin.inboundWebSockets[&inboundWebSocket{nodeName: "node2", established: 1}] = true
+
+	res, err := plugin.GetPeerInfo(ctx, &prototk.GetPeerInfoRequest{Node: "node2"})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"outbound": {"state": "connecting", "webSocket": true, "sentMsgs": 0},
+		"inbound": [
+			{"remoteAddress": "", "established": "1970-01-01T00:00:00.000000001Z", "receivedMsgs": 0},
+			{"remoteAddress": "", "established": "1970-01-01T00:00:00.000000002Z", "receivedMsgs": 0}
+		]
+	}`, res.PeerInfoJson)
+	delete(plugin.outboundConnections, "node2")
+
+}
+
+func TestConnectFail(t *testing.T) {
+
+	ctx := context.Background()
+
+	plugin1, plugin2, done := newSuccessfulVerifiedConnection(t)
+	defer done()
+
+	_ = plugin2.server.Close()
+
+	err := sendToNode2(ctx, plugin1)
+	assert.Regexp(t, "connection refused", err)
+
+}
+
+func TestConnectBadTransport(t *testing.T) {
+
+	ctx := context.Background()
+
+	plugin1, _, done := newSuccessfulVerifiedConnection(t, func(callbacks1, _ *testCallbacks) {
+		callbacks1.getTransportDetails = func(ctx context.Context, gtdr *prototk.GetTransportDetailsRequest) (*prototk.GetTransportDetailsResponse, error) {
+			return &prototk.GetTransportDetailsResponse{
+				TransportDetails: `{"endpoint": "WRONG:::::::"}`,
+			}, nil
+		}
+	})
+	defer done()
+
+	err := sendToNode2(ctx, plugin1)
+	assert.Regexp(t, "PD070010.*WRONG", err)
+
+}
+
+func TestConnectNotHTTPS(t *testing.T) {
+
+	ctx := context.Background()
+
+	plugin1, _, done := newSuccessfulVerifiedConnection(t, func(callbacks1, _ *testCallbacks) {
+		callbacks1.getTransportDetails = func(ctx context.Context, gtdr *prototk.GetTransportDetailsRequest) (*prototk.GetTransportDetailsResponse, error) {
+			return &prototk.GetTransportDetailsResponse{
+				TransportDetails: `{"endpoint": "http://localhost:12345"}`,
+			}, nil
+		}
+	})
+	defer done()
+
+	err := sendToNode2(ctx, plugin1)
+	assert.Regexp(t, "PD070010", err)
+
+}
+
+func TestSendNoNode(t *testing.T) {
+
+	ctx := context.Background()
+
+	plugin1, _, done := newSuccessfulVerifiedConnection(t)
+	defer done()
+
+	_, err := plugin1.SendMessage(ctx, &prototk.SendMessageRequest{
+		Message: &prototk.Message{
+			ReplyTo:   "node1",
+			Component: "someComponent",
+		},
+	})
+	assert.Regexp(t, "PD070007", err)
+
+}
+
+func TestHandlersRejectNoTLS(t *testing.T) {
+
+	plugin, _, _, done := newTestHTTPSTransport(t, "", "", &Config{})
+	defer done()
+
+	for _, handler := range []http.HandlerFunc{plugin.handlePost, plugin.handleWebSocket} {
+		res := httptest.NewRecorder()
+		handler(res, httptest.NewRequest(http.MethodPost, messagePath, strings.NewReader(`{}`)))
+		assert.Equal(t, http.StatusUnauthorized, res.Code)
+		assert.Regexp(t, "PD070005", res.Body.String())
+	}
+
+}
+
+func TestHandlersRejectNoPeerCert(t *testing.T) {
+
+	plugin, _, _, done := newTestHTTPSTransport(t, "", "", &Config{})
+	defer done()
+
+	res := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, messagePath, strings.NewReader(`{}`))
+	req.TLS = &tls.ConnectionState{}
+	plugin.handlePost(res, req)
+	assert.Equal(t, http.StatusUnauthorized, res.Code)
+	assert.Regexp(t, "PD070005.*PD021100", res.Body.String())
+
+}
+
+func TestHandleWebSocketBadUpgrade(t *testing.T) {
+
+	plugin, _, _, done := newTestHTTPSTransport(t, "", "", &Config{})
+	defer done()
+
+	res := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, webSocketPath, nil)
+	req.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{{Subject: pkix.Name{CommonName: "node1"}}}}
+	plugin.handleWebSocket(res, req)
+	assert.Equal(t, http.StatusBadRequest, res.Code)
+
+}
+
+func TestSendBadMessage(t *testing.T) {
+
+	ctx := context.Background()
+
+	plugin1, _, done := newSuccessfulVerifiedConnection(t)
+	defer done()
+
+	_, err := plugin1.SendMessage(ctx, &prototk.SendMessageRequest{
+		Message: &prototk.Message{
+			ReplyTo:   "node1",
+			Component: "\xff not UTF-8",
+			Node:      "node2",
+		},
+	})
+	assert.Regexp(t, "UTF-8", err)
+
+}
+
+func TestReceiveBadMessage(t *testing.T) {
+
+	plugin, _, _, done := newTestHTTPSTransport(t, "", "", &Config{})
+	defer done()
+
+	err := plugin.receive(context.Background(), "node1", []byte(`{!!!`))
+	assert.Regexp(t, "PD070008", err)
+
+}
+
+func TestWaitNewConn(t *testing.T) {
+
+	plugin, _, _, done := newTestHTTPSTransport(t, "", "", &Config{})
+	defer done()
+
+	isNew, oc, err := plugin.waitExistingOrNewConn("node1")
+	assert.True(t, isNew)
+	assert.Nil(t, err)
+
+	bgError := make(chan error)
+	go func() {
+		_, _, err := plugin.waitExistingOrNewConn("node1")
+		bgError <- err
+	}()
+
+	for {
+		plugin.connLock.L.Lock()
+		waiting := oc.waiting
+		plugin.connLock.L.Unlock()
+		if waiting > 0 {
+			break
+		}
+		time.Sleep(1 * time.Millisecond)
+	}
+
+	plugin.connLock.L.Lock()
+	oc.connecting = false
+	oc.connError = fmt.Errorf("pop")
+	delete(plugin.outboundConnections, "node1")
+	plugin.connLock.Broadcast()
+	plugin.connLock.L.Unlock()
+
+	assert.Regexp(t, "pop", <-bgError)
+
+}
+
+func getRSAKeyFromPEM(t *testing.T, pemBytes string) *rsa.PrivateKey {
+	block, _ := pem.Decode([]byte(pemBytes))
+	assert.NotNil(t, block)
+	assert.Equal(t, "RSA PRIVATE KEY", block.Type)
+	privateKey, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+	assert.NoError(t, err)
+	return privateKey
+}
+
+func buildTestCertificate(t *testing.T, subject pkix.Name, ca *x509.Certificate, caKey *rsa.PrivateKey) (string, string) {
+	// Create an X509 certificate pair
+	privatekey, _ := rsa.GenerateKey(rand.Reader, 1024 /* smallish key to make the test faster */)
+	publickey := &privatekey.PublicKey
+	var privateKeyBytes []byte = x509.MarshalPKCS1PrivateKey(privatekey)
+	privateKeyBlock := &pem.Block{Type: "RSA PRIVATE KEY", Bytes: privateKeyBytes}
+	privateKeyPEM := &strings.Builder{}
+	err := pem.Encode(privateKeyPEM, privateKeyBlock)
+	require.NoError(t, err)
+	serialNumber, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	x509Template := &x509.Certificate{
+		SerialNumber:          serialNumber,
+		Subject:               subject,
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(100 * time.Second),
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageServerAuth},
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IPAddresses:           []net.IP{net.IPv4(127, 0, 0, 1)},
+		DNSNames:              []string{"127.0.0.1", "localhost"},
+	}
+	require.NoError(t, err)
+	if ca == nil {
+		ca = x509Template
+		caKey = privatekey
+		x509Template.IsCA = true
+		x509Template.KeyUsage |= x509.KeyUsageCertSign
+	}
+	derBytes, err := x509.CreateCertificate(rand.Reader, x509Template, ca, publickey, caKey)
+	require.NoError(t, err)
+	publicKeyPEM := &strings.Builder{}
+	err = pem.Encode(publicKeyPEM, &pem.Block{Type: "CERTIFICATE", Bytes: derBytes})
+	require.NoError(t, err)
+	return publicKeyPEM.String(), privateKeyPEM.String()
+}
+
+func newTestHTTPSTransport(t *testing.T, nodeCert, nodeKey string, conf *Config) (*httpsTransport, *PublishedTransportDetails, *testCallbacks, func()) {
+	// Grab a localhost port to use and put that in config
+	portGrabber, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	port := portGrabber.Addr().(*net.TCPAddr).Port
+	err = portGrabber.Close()
+	assert.NoError(t, err)
+	conf.Port = &port
+	conf.Address = confutil.P("127.0.0.1")
+
+	// Put the certs in the config
+	conf.TLS.Cert = nodeCert
+	conf.TLS.Key = nodeKey
+
+	// Serialize the config
+	jsonConf, err := json.Marshal(conf)
+	assert.NoError(t, err)
+
+	//  construct the plugin
+	callbacks := &testCallbacks{}
+	transport := NewHTTPSTransport(callbacks).(*httpsTransport)
+	res, err := transport.ConfigureTransport(transport.bgCtx, &prototk.ConfigureTransportRequest{
+		Name:       "https",
+		ConfigJson: string(jsonConf),
+	})
+	assert.NoError(t, err)
+	assert.NotNil(t, res)
+
+	// Build the transport details for this plugin
+	transportDetails := &PublishedTransportDetails{
+		Endpoint: "https://" + transport.listener.Addr().String(),
+		Issuers:  nodeCert, // self-signed
+	}
+
+	// Wait until the socket is up
+	startTime := time.Now()
+	for {
+		c, err := net.Dial("tcp", transport.listener.Addr().String())
+		if err == nil {
+			c.Close()
+			break
+		}
+		if time.Since(startTime) > 2*time.Second {
+			require.Failf(t, "server took too long to start: %s", err.Error())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	return transport, transportDetails, callbacks, func() {
+		panicked := recover()
+		if panicked != nil {
+			panic(panicked)
+		}
+		transport.connLock.L.Lock()
+		outbound := make([]*outboundConn, 0, len(transport.outboundConnections))
+		for _, oc := range transport.outboundConnections {
+			outbound = append(outbound, oc)
+		}
+		transport.connLock.L.Unlock()
+		for _, oc := range outbound {
+			transport.closeConnection(oc)
+		}
+		_ = transport.server.Close()
+		<-transport.serverDone
+	}
+}
+
+func mockRegistry(cb *testCallbacks, ptds map[string]*PublishedTransportDetails) {
+	reg := make(map[string]string)
+	for node, ptd := range ptds {
+		reg[node] = tktypes.JSONString(ptd).String()
+	}
+	cb.getTransportDetails = func(ctx context.Context, gtdr *prototk.GetTransportDetailsRequest) (*prototk.GetTransportDetailsResponse, error) {
+		res := reg[gtdr.Node]
+		if res == "" {
+			return nil, fmt.Errorf("not found")
+		}
+		return &prototk.GetTransportDetailsResponse{
+			TransportDetails: res,
+		}, nil
+	}
+}
+
+func newSuccessfulVerifiedConnection(t *testing.T, setup ...func(callbacks1, callbacks2 *testCallbacks)) (plugin1, plugin2 *httpsTransport, done func()) {
+	return newSuccessfulVerifiedConnectionConf(t, &Config{}, setup...)
+}
+
+func newSuccessfulVerifiedConnectionConf(t *testing.T, conf *Config, setup ...func(callbacks1, callbacks2 *testCallbacks)) (plugin1, plugin2 *httpsTransport, done func()) {
+	// the default config is direct cert verification
+	conf1, conf2 := *conf, *conf
+	node1Cert, node1Key := buildTestCertificate(t, pkix.Name{CommonName: "node1"}, nil, nil)
+	plugin1, transportDetails1, callbacks1, done1 := newTestHTTPSTransport(t, node1Cert, node1Key, &conf1)
+
+	node2Cert, node2Key := buildTestCertificate(t, pkix.Name{CommonName: "node2"}, nil, nil)
+	plugin2, transportDetails2, callbacks2, done2 := newTestHTTPSTransport(t, node2Cert, node2Key, &conf2)
+
+	// Register nodes
+	ptds := map[string]*PublishedTransportDetails{"node1": transportDetails1, "node2": transportDetails2}
+	mockRegistry(callbacks1, ptds)
+	mockRegistry(callbacks2, ptds)
+
+	for _, fn := range setup {
+		fn(callbacks1, callbacks2)
+	}
+
+	return plugin1, plugin2, func() {
+		done1()
+		done2()
+	}
+}
+
+func TestHTTPSTransport_DirectCertVerification_OK(t *testing.T) {
+	ctx := context.Background()
+
+	received := make(chan *prototk.Message)
+	plugin1, _, done := newSuccessfulVerifiedConnection(t, func(_, callbacks2 *testCallbacks) {
+		callbacks2.receiveMessage = func(ctx context.Context, rmr *prototk.ReceiveMessageRequest) (*prototk.ReceiveMessageResponse, error) {
+			assert.Equal(t, "node1", rmr.FromNode)
+			received <- rmr.Message
+			return &prototk.ReceiveMessageResponse{}, nil
+		}
+	})
+	defer done()
+
+	// Connect and send from plugin1 to plugin2
+	sendRes, err := plugin1.SendMessage(ctx, &prototk.SendMessageRequest{
+		Message: &prototk.Message{
+			ReplyTo:   "node1",
+			Component: "to.you",
+			Node:      "node2",
+		},
+	})
+	assert.NoError(t, err)
+	assert.NotNil(t, sendRes)
+
+	if err == nil {
+		<-received
+	}
+
+	details, err := plugin1.GetLocalDetails(ctx, &prototk.GetLocalDetailsRequest{})
+	require.NoError(t, err)
+	var pubDetails PublishedTransportDetails
+	err = json.Unmarshal([]byte(details.TransportDetails), &pubDetails)
+	require.NoError(t, err)
+	require.Contains(t, pubDetails.Issuers, "CERTIFICATE")
+
+}
+
+func TestHTTPSTransport_CACertVerificationWithSubjectRegex_OK(t *testing.T) {
+
+	ctx := context.Background()
+
+	caCert, caKeyPEM := buildTestCertificate(t, pkix.Name{CommonName: "ca"}, nil, nil)
+	cas, err := tlsverifier.GetCertListFromPEM(ctx, []byte(caCert))
+	assert.NoError(t, err)
+	caKey := getRSAKeyFromPEM(t, caKeyPEM)
+
+	node1Cert, node1Key := buildTestCertificate(t, pkix.Name{CommonName: "node1"}, cas[0], caKey)
+	plugin1, transportDetails1, callbacks1, done1 := newTestHTTPSTransport(t, node1Cert, node1Key, &Config{
+		TLS:                    pldconf.TLSConfig{CA: caCert},
+		DirectCertVerification: confutil.P(false),
+		CertSubjectMatcher:     confutil.P(`^.*CN=([0-9A-Za-z._-]+).*$`),
+	})
+	defer done1()
+	transportDetails1.Issuers = "" // to ensure we're not falling back to cert verification
+
+	node2Cert, node2Key := buildTestCertificate(t, pkix.Name{CommonName: "node2"}, cas[0], caKey)
+	_, transportDetails2, callbacks2, done2 := newTestHTTPSTransport(t, node2Cert, node2Key, &Config{
+		TLS:                    pldconf.TLSConfig{CA: caCert},
+		DirectCertVerification: confutil.P(false),
+		CertSubjectMatcher:     confutil.P(`^.*CN=([0-9A-Za-z._-]+).*$`),
+	})
+	defer done2()
+	transportDetails1.Issuers = "" // to ensure we're not falling back to cert verification
+
+	received := make(chan *prototk.Message)
+	callbacks2.receiveMessage = func(ctx context.Context, rmr *prototk.ReceiveMessageRequest) (*prototk.ReceiveMessageResponse, error) {
+		received <- rmr.Message
+		return &prototk.ReceiveMessageResponse{}, nil
+	}
+
+	// Register nodes
+	ptds := map[string]*PublishedTransportDetails{"node1": transportDetails1, "node2": transportDetails2}
+	mockRegistry(callbacks1, ptds)
+	mockRegistry(callbacks2, ptds)
+
+	// Connect and send from plugin1 to plugin2
+	sendRes, err := plugin1.SendMessage(ctx, &prototk.SendMessageRequest{
+		Message: &prototk.Message{
+			ReplyTo:   "node1",
+			Component: "to.you",
+			Node:      "node2",
+		},
+	})
+	assert.NoError(t, err)
+	assert.NotNil(t, sendRes)
+
+	if err == nil {
+		<-received
+	}
+
+}
+
+func TestHTTPSTransport_DirectCertVerification_WrongIssuerServer(t *testing.T) {
+
+	ctx := context.Background()
+
+	// In this test we try a certificate with the right subject, but not the same CA key
+	anotherCert, _ := buildTestCertificate(t, pkix.Name{CommonName: "node1"}, nil, nil)
+
+	node1Cert, node1Key := buildTestCertificate(t, pkix.Name{CommonName: "node1"}, nil, nil)
+	plugin1, transportDetails1, callbacks1, done1 := newTestHTTPSTransport(t, node1Cert, node1Key, &Config{})
+	defer done1()
+
+	node2Cert, node2Key := buildTestCertificate(t, pkix.Name{CommonName: "node2"}, nil, nil)
+	_, transportDetails2, callbacks2, done2 := newTestHTTPSTransport(t, node2Cert, node2Key, &Config{})
+	defer done2()
+	transportDetails2.Issuers = anotherCert
+
+	ptds := map[string]*PublishedTransportDetails{"node1": transportDetails1, "node2": transportDetails2}
+	mockRegistry(callbacks1, ptds)
+	mockRegistry(callbacks2, ptds)
+
+	_, err := plugin1.SendMessage(ctx, &prototk.SendMessageRequest{
+		Message: &prototk.Message{
+			ReplyTo:   "node1",
+			Component: "to.you",
+			Node:      "node2",
+		},
+	})
+	assert.Regexp(t, "PD021102", err)
+
+}
+
+func TestHTTPSTransport_ClientWrongNode(t *testing.T) {
+
+	ctx := context.Background()
+
+	node1Cert, node1Key := buildTestCertificate(t, pkix.Name{CommonName: "node1"}, nil, nil)
+	plugin1, transportDetails1, callbacks1, done1 := newTestHTTPSTransport(t, node1Cert, node1Key, &Config{})
+	defer done1()
+
+	node2Cert, node2Key := buildTestCertificate(t, pkix.Name{CommonName: "node2"}, nil, nil)
+	_, transportDetails2, callbacks2, done2 := newTestHTTPSTransport(t, node2Cert, node2Key, &Config{})
+	defer done2()
+
+	ptds := map[string]*PublishedTransportDetails{"node1": transportDetails1, "node3": transportDetails2}
+	mockRegistry(callbacks1, ptds)
+	mockRegistry(callbacks2, ptds)
+
+	_, err := plugin1.SendMessage(ctx, &prototk.SendMessageRequest{
+		Message: &prototk.Message{
+			ReplyTo:   "node1",
+			Component: "to.you",
+			Node:      "node3",
+		},
+	})
+	assert.Regexp(t, "PD021103", err)
+
+}
+
+func TestHTTPSTransport_BadTransportDetails(t *testing.T) {
+
+	ctx := context.Background()
+
+	node1Cert, node1Key := buildTestCertificate(t, pkix.Name{CommonName: "node1"}, nil, nil)
+	plugin1, transportDetails1, callbacks1, done1 := newTestHTTPSTransport(t, node1Cert, node1Key, &Config{})
+	defer done1()
+
+	node2Cert, node2Key := buildTestCertificate(t, pkix.Name{CommonName: "node2"}, nil, nil)
+	_, transportDetails2, callbacks2, done2 := newTestHTTPSTransport(t, node2Cert, node2Key, &Config{})
+	defer done2()
+
+	callbacks1.getTransportDetails = func(ctx context.Context, gtdr *prototk.GetTransportDetailsRequest) (*prototk.GetTransportDetailsResponse, error) {
+		return &prototk.GetTransportDetailsResponse{
+			TransportDetails: `{!!! not JSON`,
+		}, nil
+	}
+	ptds := map[string]*PublishedTransportDetails{"node1": transportDetails1, "node2": transportDetails2}
+	mockRegistry(callbacks2, ptds)
+
+	_, err := plugin1.SendMessage(ctx, &prototk.SendMessageRequest{
+		Message: &prototk.Message{
+			ReplyTo:   "node1",
+			Component: "to.you",
+			Node:      "node2",
+		},
+	})
+	assert.Regexp(t, "PD070004", err)
+
+}
+
+func TestHTTPSTransport_NodeUnknownToClient(t *testing.T) {
+
+	ctx := context.Background()
+
+	node1Cert, node1Key := buildTestCertificate(t, pkix.Name{CommonName: "node1"}, nil, nil)
+	plugin1, transportDetails1, callbacks1, done1 := newTestHTTPSTransport(t, node1Cert, node1Key, &Config{})
+	defer done1()
+
+	node2Cert, node2Key := buildTestCertificate(t, pkix.Name{CommonName: "node2"}, nil, nil)
+	_, transportDetails2, callbacks2, done2 := newTestHTTPSTransport(t, node2Cert, node2Key, &Config{})
+	defer done2()
+
+	ptds := map[string]*PublishedTransportDetails{"node1": transportDetails1, "node2": transportDetails2}
+	mockRegistry(callbacks1, ptds)
+	mockRegistry(callbacks2, ptds)
+
+	_, err := plugin1.SendMessage(ctx, &prototk.SendMessageRequest{
+		Message: &prototk.Message{
+			ReplyTo:   "node1",
+			Component: "to.you",
+			Node:      "node3",
+		},
+	})
+	assert.Regexp(t, "not found", err)
+
+}
+
+func TestTransportDetailsChanged(t *testing.T) {
+
+	transport := NewHTTPSTransport(&testCallbacks{}).(*httpsTransport)
+	res, err := transport.TransportDetailsChanged(context.Background(), &prototk.TransportDetailsChangedRequest{})
+	require.NoError(t, err)
+	assert.NotNil(t, res)
+
+}
@@ -0,0 +1,47 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package msgs
+
+import (
+	"sync"
+
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"golang.org/x/text/language"
+)
+
+var registered sync.Once
+var ffe = func(key, translation string, statusHint ...int) i18n.ErrorMessageKey {
+	registered.Do(func() {
+		i18n.RegisterPrefix("PD07", "Paladin HTTPS Transport")
+	})
+	return i18n.FFE(language.AmericanEnglish, key, translation, statusHint...)
+}
+
+var (
+	// Generic PD0700XX
+	MsgListenerPortAndAddressRequired       = ffe("PD070000", "port and address for listener are required")
+	MsgInvalidTransportConfig               = ffe("PD070001", "Invalid transport configuration")
+	MsgConfIncompatibleWithDirectCertVerify = ffe("PD070002", "When directCertVerification is enabled, TLS and clientAuth must be enabled, with no additional CA configuration or insecureSkipHostVerify")
+	MsgInvalidSubjectRegexp                 = ffe("PD070003", "subjectMatchRegex is invalid")
+	MsgPeerTransportDetailsInvalid          = ffe("PD070004", "published peer transport details for node '%s' are invalid")
+	MsgNoVerifiedPeer                       = ffe("PD070005", "request did not present a verified peer certificate", 401)
+	MsgInvalidReplyToNode                   = ffe("PD070006", "replyTo node does not match sending node", 403)
+	MsgErrorNoTargetNode                    = ffe("PD070007", "request to send message but no target node specified")
+	MsgInvalidMessage                       = ffe("PD070008", "invalid message received from peer", 400)
+	MsgSendFailed                           = ffe("PD070009", "peer '%s' rejected message with status %d: %s")
+	MsgInvalidEndpoint                      = ffe("PD070010", "published endpoint '%s' for node '%s' is not a valid https URL")
+)